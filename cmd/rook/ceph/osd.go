@@ -25,6 +25,7 @@ import (
 	"k8s.io/client-go/kubernetes"
 
 	"github.com/pkg/errors"
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
 	"github.com/rook/rook/cmd/rook/rook"
 	osddaemon "github.com/rook/rook/pkg/daemon/ceph/osd"
 	"github.com/rook/rook/pkg/operator/ceph/cluster/mon"
@@ -285,7 +286,15 @@ func getLocation(clientset kubernetes.Interface) (string, string, error) {
 
 	rootLabel := os.Getenv(oposd.CrushRootVarName)
 
-	loc, topologyAffinity, err := oposd.GetLocationWithNode(clientset, os.Getenv(k8sutil.NodeNameEnvVar), rootLabel, hostNameLabel)
+	// the node labels mapped to bucket types by the crush topology of the cluster
+	var crushTopology []cephv1.CrushTopologyLabel
+	if topology := os.Getenv(oposd.CrushTopologyVarName); topology != "" {
+		if err := json.Unmarshal([]byte(topology), &crushTopology); err != nil {
+			return "", "", errors.Wrap(err, "failed to unmarshal the crush topology")
+		}
+	}
+
+	loc, topologyAffinity, err := oposd.GetLocationWithNode(clientset, os.Getenv(k8sutil.NodeNameEnvVar), rootLabel, hostNameLabel, crushTopology)
 	if err != nil {
 		return "", "", err
 	}
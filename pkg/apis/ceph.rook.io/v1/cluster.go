@@ -19,6 +19,7 @@ package v1
 import (
 	"reflect"
 	"strconv"
+	"strings"
 
 	"github.com/pkg/errors"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -33,14 +34,44 @@ func (c *ClusterSpec) IsStretchCluster() bool {
 	return c.Mon.StretchCluster != nil && len(c.Mon.StretchCluster.Zones) > 0
 }
 
+// CheckSeverity returns the severity of a Ceph health check once the severity override from the
+// spec is applied
+func (h *CephClusterHealthCheckSpec) CheckSeverity(code, severity string) string {
+	if policy, ok := h.Checks[code]; ok && policy.Severity != "" {
+		return policy.Severity
+	}
+	return severity
+}
+
+// Validate checks the single sign-on settings of the dashboard. The dashboard only supports SAML 2.0,
+// so OpenID Connect is rejected explicitly rather than as an unknown type.
+func (s *DashboardSSOSpec) Validate() error {
+	if s == nil || !s.Enabled {
+		return nil
+	}
+	switch strings.ToLower(string(s.Type)) {
+	case string(DashboardSSOSAML2):
+		if s.SAML2 == nil || s.SAML2.BaseURL == "" || s.SAML2.IdPMetadata == "" {
+			return errors.New("the saml2 base URL and IdP metadata must be specified")
+		}
+		return nil
+	case "oidc", "openid", "oauth2":
+		return errors.Errorf("dashboard single sign-on type %q is not supported by the ceph dashboard, only %q is supported", s.Type, DashboardSSOSAML2)
+	}
+	return errors.Errorf("unknown dashboard single sign-on type %q", s.Type)
+}
+
 func (c *CephCluster) ValidateCreate() error {
 	logger.Infof("validate create cephcluster %q", c.ObjectMeta.Name)
 	//If external mode enabled, then check if other fields are empty
 	if c.Spec.External.Enable {
-		if c.Spec.Mon != (MonSpec{}) || c.Spec.Dashboard != (DashboardSpec{}) || !reflect.DeepEqual(c.Spec.Monitoring, (MonitoringSpec{})) || c.Spec.DisruptionManagement != (DisruptionManagementSpec{}) || len(c.Spec.Mgr.Modules) > 0 || len(c.Spec.Network.Provider) > 0 || len(c.Spec.Network.Selectors) > 0 {
+		if c.Spec.Mon != (MonSpec{}) || !reflect.DeepEqual(c.Spec.Dashboard, DashboardSpec{}) || !reflect.DeepEqual(c.Spec.Monitoring, (MonitoringSpec{})) || c.Spec.DisruptionManagement != (DisruptionManagementSpec{}) || len(c.Spec.Mgr.Modules) > 0 || len(c.Spec.Network.Provider) > 0 || len(c.Spec.Network.Selectors) > 0 {
 			return errors.New("invalid create : external mode enabled cannot have mon,dashboard,monitoring,network,disruptionManagement,storage fields in CR")
 		}
 	}
+	if err := c.Spec.Dashboard.SSO.Validate(); err != nil {
+		return errors.Wrap(err, "invalid create")
+	}
 	return nil
 }
 
@@ -73,6 +104,10 @@ func validateUpdatedCephCluster(updatedCephCluster *CephCluster, found *CephClus
 		}
 	}
 
+	if err := updatedCephCluster.Spec.Dashboard.SSO.Validate(); err != nil {
+		return errors.Wrap(err, "invalid update")
+	}
+
 	return nil
 }
 
@@ -88,3 +88,36 @@ func TestCephClusterValidateUpdate(t *testing.T) {
 	err = uc.ValidateUpdate(c)
 	assert.Error(t, err)
 }
+
+func TestCheckSeverity(t *testing.T) {
+	h := CephClusterHealthCheckSpec{}
+	assert.Equal(t, "HEALTH_WARN", h.CheckSeverity("POOL_NO_REDUNDANCY", "HEALTH_WARN"))
+
+	h.Checks = map[string]CephHealthCheckPolicySpec{
+		"POOL_NO_REDUNDANCY": {Severity: "HEALTH_OK"},
+		"MON_DISK_LOW":       {Severity: "HEALTH_ERR"},
+	}
+	assert.Equal(t, "HEALTH_OK", h.CheckSeverity("POOL_NO_REDUNDANCY", "HEALTH_WARN"))
+	assert.Equal(t, "HEALTH_ERR", h.CheckSeverity("MON_DISK_LOW", "HEALTH_WARN"))
+	assert.Equal(t, "HEALTH_ERR", h.CheckSeverity("OSD_FULL", "HEALTH_ERR"))
+}
+
+func TestDashboardSSOValidate(t *testing.T) {
+	var sso *DashboardSSOSpec
+	assert.NoError(t, sso.Validate())
+	sso = &DashboardSSOSpec{Type: "oidc"}
+	assert.NoError(t, sso.Validate())
+
+	sso.Enabled = true
+	err := sso.Validate()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "not supported by the ceph dashboard")
+
+	sso.Type = DashboardSSOSAML2
+	assert.Error(t, sso.Validate())
+	sso.SAML2 = &DashboardSAML2Spec{BaseURL: "https://dashboard", IdPMetadata: "https://idp/metadata"}
+	assert.NoError(t, sso.Validate())
+
+	sso.Type = "ldap"
+	assert.Error(t, sso.Validate())
+}
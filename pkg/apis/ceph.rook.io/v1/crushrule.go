@@ -0,0 +1,106 @@
+/*
+Copyright 2021 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"regexp"
+
+	"github.com/pkg/errors"
+)
+
+// the names of the crush buckets, types and device classes
+var crushNameRegex = regexp.MustCompile(`^[A-Za-z0-9_.-]+$`)
+
+func (c *CephCrushRule) GetStatusConditions() *[]Condition {
+	if c.Status == nil {
+		c.Status = &CrushRuleStatus{}
+	}
+	return &c.Status.Conditions
+}
+
+// GetType returns the type of the rule, replicated by default
+func (c *CrushRuleSpec) GetType() CrushRuleType {
+	if c.Type == "" {
+		return CrushRuleTypeReplicated
+	}
+	return c.Type
+}
+
+// GetMode returns the mode of a choose or chooseleaf step of the rule
+func (c *CrushRuleSpec) GetMode(step CrushRuleStep) string {
+	if step.Mode != "" {
+		return step.Mode
+	}
+	if c.GetType() == CrushRuleTypeErasure {
+		return "indep"
+	}
+	return "firstn"
+}
+
+// Validate checks that the steps of the rule take a bucket, choose buckets and emit them. The buckets,
+// types and device classes are validated against the crush map when the rule is compiled.
+func (c *CrushRuleSpec) Validate() error {
+	switch c.GetType() {
+	case CrushRuleTypeReplicated, CrushRuleTypeErasure:
+	default:
+		return errors.Errorf("invalid rule type %q", c.Type)
+	}
+	if len(c.Steps) == 0 {
+		return errors.New("the rule has no steps")
+	}
+
+	taken := false
+	for i, step := range c.Steps {
+		switch step.Op {
+		case CrushRuleStepTake:
+			if !crushNameRegex.MatchString(step.Item) {
+				return errors.Errorf("step %d: invalid bucket %q to take", i, step.Item)
+			}
+			if step.DeviceClass != "" && !crushNameRegex.MatchString(step.DeviceClass) {
+				return errors.Errorf("step %d: invalid device class %q", i, step.DeviceClass)
+			}
+			if step.Type != "" || step.Mode != "" || step.Count != 0 {
+				return errors.Errorf("step %d: a take step only has an item and a device class", i)
+			}
+			taken = true
+		case CrushRuleStepChoose, CrushRuleStepChooseLeaf:
+			if !taken {
+				return errors.Errorf("step %d: a bucket must be taken before choosing", i)
+			}
+			if !crushNameRegex.MatchString(step.Type) {
+				return errors.Errorf("step %d: invalid bucket type %q to choose", i, step.Type)
+			}
+			if step.Item != "" || step.DeviceClass != "" {
+				return errors.Errorf("step %d: a %s step only has a type, a mode and a count", i, step.Op)
+			}
+		case CrushRuleStepEmit:
+			if !taken {
+				return errors.Errorf("step %d: a bucket must be taken before emitting", i)
+			}
+			if step != (CrushRuleStep{Op: CrushRuleStepEmit}) {
+				return errors.Errorf("step %d: an emit step has no settings", i)
+			}
+			taken = false
+		default:
+			return errors.Errorf("step %d: invalid operation %q", i, step.Op)
+		}
+	}
+	if taken {
+		return errors.New("the rule must end with an emit step")
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+/*
+Copyright 2021 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCrushRuleSpecValidate(t *testing.T) {
+	// 2 copies in room a and 1 copy in room b
+	spec := CrushRuleSpec{Steps: []CrushRuleStep{
+		{Op: CrushRuleStepTake, Item: "room-a", DeviceClass: "ssd"},
+		{Op: CrushRuleStepChooseLeaf, Count: 2, Type: "host"},
+		{Op: CrushRuleStepEmit},
+		{Op: CrushRuleStepTake, Item: "room-b"},
+		{Op: CrushRuleStepChooseLeaf, Count: -2, Type: "host"},
+		{Op: CrushRuleStepEmit},
+	}}
+	assert.NoError(t, spec.Validate())
+	assert.Equal(t, CrushRuleTypeReplicated, spec.GetType())
+	assert.Equal(t, "firstn", spec.GetMode(spec.Steps[1]))
+	spec.Type = CrushRuleTypeErasure
+	assert.Equal(t, "indep", spec.GetMode(spec.Steps[1]))
+	spec.Steps[1].Mode = "firstn"
+	assert.Equal(t, "firstn", spec.GetMode(spec.Steps[1]))
+
+	invalid := map[string][]CrushRuleStep{
+		"no steps":          {},
+		"choose first":      {{Op: CrushRuleStepChoose, Type: "host"}, {Op: CrushRuleStepEmit}},
+		"no emit":           {{Op: CrushRuleStepTake, Item: "default"}, {Op: CrushRuleStepChooseLeaf, Type: "host"}},
+		"no item":           {{Op: CrushRuleStepTake}, {Op: CrushRuleStepEmit}},
+		"no type":           {{Op: CrushRuleStepTake, Item: "default"}, {Op: CrushRuleStepChooseLeaf}, {Op: CrushRuleStepEmit}},
+		"invalid item":      {{Op: CrushRuleStepTake, Item: "default host"}, {Op: CrushRuleStepEmit}},
+		"take with type":    {{Op: CrushRuleStepTake, Item: "default", Type: "host"}, {Op: CrushRuleStepEmit}},
+		"choose with class": {{Op: CrushRuleStepTake, Item: "default"}, {Op: CrushRuleStepChoose, Type: "host", DeviceClass: "ssd"}, {Op: CrushRuleStepEmit}},
+		"emit with count":   {{Op: CrushRuleStepTake, Item: "default"}, {Op: CrushRuleStepEmit, Count: 1}},
+		"invalid op":        {{Op: "set"}},
+	}
+	for name, steps := range invalid {
+		spec := CrushRuleSpec{Steps: steps}
+		assert.Error(t, spec.Validate(), name)
+	}
+}
@@ -0,0 +1,97 @@
+/*
+Copyright 2021 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+func (c *CephFilesystemSubVolumeGroup) GetStatusConditions() *[]Condition {
+	if c.Status == nil {
+		c.Status = &CephFilesystemSubVolumeGroupStatus{}
+	}
+	return &c.Status.Conditions
+}
+
+// Validate checks that at most one pinning policy is set and that the random pinning is a probability
+func (p *CephFilesystemSubVolumeGroupSpecPinning) Validate() error {
+	set := 0
+	for _, isSet := range []bool{p.Export != nil, p.Distributed != nil, p.Random != nil} {
+		if isSet {
+			set++
+		}
+	}
+	if set > 1 {
+		return errors.New("only one of export, distributed or random pinning can be set")
+	}
+	if p.Random != nil && (*p.Random < 0 || *p.Random > 1) {
+		return errors.Errorf("random pinning must be between 0 and 1, got %v", *p.Random)
+	}
+	return nil
+}
+
+// IsSet returns whether a pinning policy is set for the subvolume group
+func (p *CephFilesystemSubVolumeGroupSpecPinning) IsSet() bool {
+	return p.Export != nil || p.Distributed != nil || p.Random != nil
+}
+
+// IsEnabled returns whether the snapshot schedules of the filesystem are managed by the operator
+func (s *FSSnapshotSchedulingSpec) IsEnabled() bool {
+	return s != nil && s.Enabled
+}
+
+// Validate checks that each schedule targets either a path or a subvolume, and that each path or
+// subvolume has a single schedule since the retention policy is set per path
+func (s *FSSnapshotSchedulingSpec) Validate() error {
+	targets := map[string]bool{}
+	for _, schedule := range s.Schedules {
+		if schedule.Path != "" && schedule.Subvolume != "" {
+			return errors.Errorf("only one of path %q or subvolume %q can be set", schedule.Path, schedule.Subvolume)
+		}
+		if schedule.Path != "" && !strings.HasPrefix(schedule.Path, "/") {
+			return errors.Errorf("path %q must be absolute", schedule.Path)
+		}
+		if schedule.SubvolumeGroup != "" && schedule.Subvolume == "" {
+			return errors.Errorf("subvolume group %q is set without a subvolume", schedule.SubvolumeGroup)
+		}
+		if len(schedule.Intervals) == 0 {
+			return errors.Errorf("no interval set for the snapshot schedule of %q", schedule.Target())
+		}
+		if targets[schedule.Target()] {
+			return errors.Errorf("more than one snapshot schedule for %q", schedule.Target())
+		}
+		targets[schedule.Target()] = true
+	}
+	return nil
+}
+
+// Target returns a description of the path or the subvolume of the schedule
+func (s *FSSnapshotScheduleSpec) Target() string {
+	if s.Subvolume != "" {
+		group := s.SubvolumeGroup
+		if group == "" {
+			group = "_nogroup"
+		}
+		return "subvolume " + group + "/" + s.Subvolume
+	}
+	if s.Path == "" {
+		return "/"
+	}
+	return s.Path
+}
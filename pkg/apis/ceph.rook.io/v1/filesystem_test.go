@@ -0,0 +1,67 @@
+/*
+Copyright 2021 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSubVolumeGroupPinningValidate(t *testing.T) {
+	one := 1
+	random := 0.5
+	assert.NoError(t, (&CephFilesystemSubVolumeGroupSpecPinning{}).Validate())
+	assert.NoError(t, (&CephFilesystemSubVolumeGroupSpecPinning{Export: &one}).Validate())
+	assert.NoError(t, (&CephFilesystemSubVolumeGroupSpecPinning{Random: &random}).Validate())
+	assert.Error(t, (&CephFilesystemSubVolumeGroupSpecPinning{Export: &one, Distributed: &one}).Validate())
+	assert.Error(t, (&CephFilesystemSubVolumeGroupSpecPinning{Distributed: &one, Random: &random}).Validate())
+	random = 1.5
+	assert.Error(t, (&CephFilesystemSubVolumeGroupSpecPinning{Random: &random}).Validate())
+}
+
+func TestSnapshotSchedulingValidate(t *testing.T) {
+	hourly := []FSSnapshotIntervalSpec{{Interval: "1h"}}
+	var nilSpec *FSSnapshotSchedulingSpec
+	assert.False(t, nilSpec.IsEnabled())
+
+	spec := &FSSnapshotSchedulingSpec{Enabled: true, Schedules: []FSSnapshotScheduleSpec{
+		{Intervals: hourly},
+		{Path: "/data", Intervals: hourly, Retention: "24h"},
+		{Subvolume: "vol", SubvolumeGroup: "csi", Intervals: hourly},
+		{Subvolume: "vol", Intervals: hourly},
+	}}
+	assert.True(t, spec.IsEnabled())
+	assert.NoError(t, spec.Validate())
+	assert.Equal(t, "/", spec.Schedules[0].Target())
+	assert.Equal(t, "subvolume csi/vol", spec.Schedules[2].Target())
+	assert.Equal(t, "subvolume _nogroup/vol", spec.Schedules[3].Target())
+
+	// a single schedule per path
+	spec.Schedules = append(spec.Schedules, FSSnapshotScheduleSpec{Path: "/data", Intervals: hourly})
+	assert.Error(t, spec.Validate())
+
+	for _, invalid := range []FSSnapshotScheduleSpec{
+		{Path: "/data", Subvolume: "vol", Intervals: hourly},
+		{Path: "data", Intervals: hourly},
+		{SubvolumeGroup: "csi", Intervals: hourly},
+		{Path: "/data"},
+	} {
+		spec.Schedules = []FSSnapshotScheduleSpec{invalid}
+		assert.Error(t, spec.Validate(), invalid)
+	}
+}
@@ -0,0 +1,44 @@
+/*
+Copyright 2021 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"net"
+
+	"github.com/pkg/errors"
+)
+
+// Validate checks that the fence has clients and valid addresses
+func (f *NetworkFenceSpec) Validate() error {
+	if f.NodeName == "" && len(f.CIDRs) == 0 {
+		return errors.New("either nodeName or cidrs must be set")
+	}
+	for _, cidr := range f.CIDRs {
+		if net.ParseIP(cidr) != nil {
+			continue
+		}
+		if _, _, err := net.ParseCIDR(cidr); err != nil {
+			return errors.Errorf("fenced client %q is not an address or a CIDR", cidr)
+		}
+	}
+	return nil
+}
+
+// IsFenced returns whether the clients of the fence are blocklisted
+func (f *NetworkFenceSpec) IsFenced() bool {
+	return f.FenceState != FenceStateUnfenced
+}
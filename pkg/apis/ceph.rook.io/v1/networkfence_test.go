@@ -0,0 +1,40 @@
+/*
+Copyright 2021 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNetworkFenceSpecValidate(t *testing.T) {
+	spec := NetworkFenceSpec{}
+	assert.Error(t, spec.Validate())
+	assert.True(t, spec.IsFenced())
+
+	spec.NodeName = "node-1"
+	assert.NoError(t, spec.Validate())
+
+	spec.CIDRs = []string{"10.0.0.1", "192.168.0.0/16", "2001:db8::/32"}
+	assert.NoError(t, spec.Validate())
+	spec.CIDRs = []string{"10.0.0.0/33"}
+	assert.Error(t, spec.Validate())
+
+	spec.FenceState = FenceStateUnfenced
+	assert.False(t, spec.IsFenced())
+}
@@ -0,0 +1,43 @@
+/*
+Copyright 2021 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"net"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Validate checks that the export has a single source and valid client addresses
+func (e *NFSExportSpec) Validate() error {
+	if (e.CephFS == nil) == (e.RGW == nil) {
+		return errors.New("exactly one of cephfs or rgw must be set")
+	}
+	if !strings.HasPrefix(e.PseudoPath, "/") {
+		return errors.Errorf("pseudo path %q must be absolute", e.PseudoPath)
+	}
+	for _, client := range e.AllowedClients {
+		if net.ParseIP(client) != nil {
+			continue
+		}
+		if _, _, err := net.ParseCIDR(client); err != nil {
+			return errors.Errorf("allowed client %q is not an address or a CIDR", client)
+		}
+	}
+	return nil
+}
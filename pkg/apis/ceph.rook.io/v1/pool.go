@@ -46,6 +46,15 @@ func (p *PoolSpec) IsCompressionEnabled() bool {
 	return p.CompressionMode != ""
 }
 
+// GetPgpNum returns the number of placement groups used for placement, which defaults to the number
+// of placement groups
+func (p *PlacementGroupSpec) GetPgpNum() int {
+	if p.PgpNum == 0 {
+		return p.PgNum
+	}
+	return p.PgpNum
+}
+
 func (p *ReplicatedSpec) IsTargetRatioEnabled() bool {
 	return p.TargetSizeRatio != 0
 }
@@ -83,9 +92,72 @@ func validatePoolSpec(ps PoolSpec) error {
 			return errors.New("invalid create: erasurecoded.codingchunks needs minimum value of 1")
 		}
 	}
+
+	if err := ps.ValidatePlacementGroups(); err != nil {
+		return errors.Wrap(err, "invalid placement groups")
+	}
+
+	if ps.Migration != nil {
+		if ps.Migration.TargetPool == "" {
+			return errors.New("invalid migration: the target pool must be set")
+		}
+		if err := validatePoolSpec(ps.Migration.TargetPoolSpec(ps)); err != nil {
+			return errors.Wrap(err, "invalid migration target pool")
+		}
+	}
+	return nil
+}
+
+// ValidatePlacementGroups validates the placement group settings of the pool
+func (p *PoolSpec) ValidatePlacementGroups() error {
+	pgs := p.PlacementGroups
+	if pgs == nil {
+		return nil
+	}
+	// the autoscaler changes the number of placement groups when it is on
+	if pgs.PgNum > 0 && (pgs.AutoscaleMode == "" || pgs.AutoscaleMode == "on") {
+		return errors.New("the autoscale mode must be off or warn when pgNum is set")
+	}
+	if pgs.PgpNum > 0 && pgs.PgNum == 0 {
+		return errors.New("pgpNum requires pgNum")
+	}
+	if pgs.PgpNum > pgs.PgNum {
+		return errors.Errorf("pgpNum %d cannot be greater than pgNum %d", pgs.PgpNum, pgs.PgNum)
+	}
+	if pgs.PgNumMax > 0 && pgs.PgNumMin > pgs.PgNumMax {
+		return errors.Errorf("pgNumMin %d cannot be greater than pgNumMax %d", pgs.PgNumMin, pgs.PgNumMax)
+	}
 	return nil
 }
 
+// TargetPoolSpec returns the spec of the target pool of the migration, which has the crush settings
+// of the pool unless overridden
+func (m *PoolMigrationSpec) TargetPoolSpec(source PoolSpec) PoolSpec {
+	target := PoolSpec{
+		FailureDomain:   source.FailureDomain,
+		CrushRoot:       source.CrushRoot,
+		DeviceClass:     source.DeviceClass,
+		CompressionMode: source.CompressionMode,
+		Replicated:      m.Replicated,
+		ErasureCoded:    m.ErasureCoded,
+	}
+	if m.FailureDomain != "" {
+		target.FailureDomain = m.FailureDomain
+	}
+	if m.DeviceClass != "" {
+		target.DeviceClass = m.DeviceClass
+	}
+	return target
+}
+
+// GetMaxConcurrentImages returns the number of images migrated at the same time
+func (m *PoolMigrationSpec) GetMaxConcurrentImages() int {
+	if m.MaxConcurrentImages <= 0 {
+		return 1
+	}
+	return m.MaxConcurrentImages
+}
+
 func (p *CephBlockPool) ValidateUpdate(old runtime.Object) error {
 	logger.Info("validate update cephblockpool")
 	ocbp := old.(*CephBlockPool)
@@ -104,6 +176,10 @@ func (p *CephBlockPool) ValidateUpdate(old runtime.Object) error {
 			return errors.New("invalid update: erasurecoded field is set already in previous object. cannot be changed to use replicated")
 		}
 	}
+
+	if p.Spec.Migration != nil && ocbp.Spec.Migration != nil && p.Spec.Migration.TargetPool != ocbp.Spec.Migration.TargetPool {
+		return errors.New("invalid update: the target pool of the migration cannot be changed")
+	}
 	return nil
 }
 
@@ -115,3 +191,17 @@ func (p *CephBlockPool) ValidateDelete() error {
 func (p *MirroringSpec) SnapshotSchedulesEnabled() bool {
 	return len(p.SnapshotSchedules) > 0
 }
+
+func (p *CephBlockPool) GetStatusConditions() *[]Condition {
+	if p.Status == nil {
+		p.Status = &CephBlockPoolStatus{}
+	}
+	return &p.Status.Conditions
+}
+
+func (c *CephBlockPoolRadosNamespace) GetStatusConditions() *[]Condition {
+	if c.Status == nil {
+		c.Status = &CephBlockPoolRadosNamespaceStatus{}
+	}
+	return &c.Status.Conditions
+}
@@ -59,6 +59,71 @@ func TestCephBlockPoolValidateUpdate(t *testing.T) {
 	assert.Error(t, err)
 }
 
+func TestPoolMigrationSpec(t *testing.T) {
+	p := &CephBlockPool{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "replicapool",
+		},
+		Spec: PoolSpec{
+			FailureDomain: "host",
+			DeviceClass:   "hdd",
+			Replicated:    ReplicatedSpec{Size: 3},
+			Mirroring:     MirroringSpec{Enabled: true},
+			Migration: &PoolMigrationSpec{
+				TargetPool:   "ecpool",
+				DeviceClass:  "ssd",
+				ErasureCoded: ErasureCodedSpec{DataChunks: 4, CodingChunks: 2},
+			},
+		},
+	}
+	assert.NoError(t, validatePoolSpec(p.Spec))
+	target := p.Spec.Migration.TargetPoolSpec(p.Spec)
+	assert.Equal(t, "host", target.FailureDomain)
+	assert.Equal(t, "ssd", target.DeviceClass)
+	assert.True(t, target.IsErasureCoded())
+	assert.False(t, target.Mirroring.Enabled)
+	assert.Equal(t, 1, p.Spec.Migration.GetMaxConcurrentImages())
+
+	// the target pool layout must be valid
+	p.Spec.Migration.Replicated.Size = 3
+	assert.Error(t, validatePoolSpec(p.Spec))
+	p.Spec.Migration.Replicated.Size = 0
+	p.Spec.Migration.TargetPool = ""
+	assert.Error(t, validatePoolSpec(p.Spec))
+
+	// the target pool cannot be changed
+	p.Spec.Migration.TargetPool = "ecpool"
+	up := p.DeepCopy()
+	up.Spec.Migration.MaxConcurrentImages = 4
+	assert.NoError(t, up.ValidateUpdate(p))
+	up.Spec.Migration.TargetPool = "ecpool2"
+	assert.Error(t, up.ValidateUpdate(p))
+}
+
+func TestValidatePlacementGroups(t *testing.T) {
+	p := PoolSpec{Replicated: ReplicatedSpec{Size: 3}}
+	assert.NoError(t, p.ValidatePlacementGroups())
+
+	p.PlacementGroups = &PlacementGroupSpec{PgNumMin: 8, PgNumMax: 64, AutoscaleMode: "on"}
+	assert.NoError(t, validatePoolSpec(p))
+
+	// the autoscaler would change the number of placement groups
+	p.PlacementGroups.PgNum = 32
+	assert.Error(t, validatePoolSpec(p))
+	p.PlacementGroups.AutoscaleMode = "warn"
+	assert.NoError(t, validatePoolSpec(p))
+	assert.Equal(t, 32, p.PlacementGroups.GetPgpNum())
+
+	p.PlacementGroups.PgpNum = 64
+	assert.Error(t, p.ValidatePlacementGroups())
+	p.PlacementGroups.PgpNum = 16
+	assert.NoError(t, p.ValidatePlacementGroups())
+	assert.Equal(t, 16, p.PlacementGroups.GetPgpNum())
+
+	p.PlacementGroups.PgNumMin = 128
+	assert.Error(t, p.ValidatePlacementGroups())
+}
+
 func TestMirroringSpec_SnapshotSchedulesEnabled(t *testing.T) {
 	type fields struct {
 		Enabled           bool
@@ -0,0 +1,46 @@
+/*
+Copyright 2021 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"github.com/pkg/errors"
+)
+
+// Validate checks that the failover selects images and has a valid role
+func (f *RBDFailoverSpec) Validate() error {
+	if f.Pool == "" {
+		return errors.New("missing pool")
+	}
+	if len(f.Images) == 0 && f.PVCSelector == nil {
+		return errors.New("either images or pvcSelector must be set")
+	}
+	switch f.Role {
+	case RBDImageRolePrimary:
+	case RBDImageRoleSecondary:
+		if f.Force {
+			return errors.New("force can only be set to promote the images")
+		}
+	default:
+		return errors.Errorf("invalid role %q, must be %q or %q", f.Role, RBDImageRolePrimary, RBDImageRoleSecondary)
+	}
+	return nil
+}
+
+// IsPrimary returns whether the images are promoted in this cluster
+func (f *RBDFailoverSpec) IsPrimary() bool {
+	return f.Role == RBDImageRolePrimary
+}
@@ -0,0 +1,48 @@
+/*
+Copyright 2021 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestRBDFailoverValidate(t *testing.T) {
+	spec := RBDFailoverSpec{Pool: "replicapool", Images: []string{"csi-vol-1"}, Role: RBDImageRolePrimary, Force: true}
+	assert.NoError(t, spec.Validate())
+	assert.True(t, spec.IsPrimary())
+
+	spec.Images = nil
+	assert.Error(t, spec.Validate())
+	spec.PVCSelector = &metav1.LabelSelector{MatchLabels: map[string]string{"app": "db"}}
+	assert.NoError(t, spec.Validate())
+
+	// only promotions can be forced
+	spec.Role = RBDImageRoleSecondary
+	assert.Error(t, spec.Validate())
+	spec.Force = false
+	assert.NoError(t, spec.Validate())
+	assert.False(t, spec.IsPrimary())
+
+	spec.Role = "unknown"
+	assert.Error(t, spec.Validate())
+	spec.Role = RBDImageRolePrimary
+	spec.Pool = ""
+	assert.Error(t, spec.Validate())
+}
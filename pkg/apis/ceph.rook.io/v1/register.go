@@ -56,6 +56,10 @@ func addKnownTypes(scheme *runtime.Scheme) error {
 	scheme.AddKnownTypes(SchemeGroupVersion,
 		&CephClient{},
 		&CephClientList{},
+		&CephBlockPoolRadosNamespace{},
+		&CephBlockPoolRadosNamespaceList{},
+		&CephFilesystemSubVolumeGroup{},
+		&CephFilesystemSubVolumeGroupList{},
 		&CephCluster{},
 		&CephClusterList{},
 		&CephBlockPool{},
@@ -64,6 +68,14 @@ func addKnownTypes(scheme *runtime.Scheme) error {
 		&CephFilesystemList{},
 		&CephNFS{},
 		&CephNFSList{},
+		&CephNFSExport{},
+		&CephNFSExportList{},
+		&CephNetworkFence{},
+		&CephNetworkFenceList{},
+		&CephRBDFailover{},
+		&CephRBDFailoverList{},
+		&CephCrushRule{},
+		&CephCrushRuleList{},
 		&CephObjectStore{},
 		&CephObjectStoreList{},
 		&CephObjectStoreUser{},
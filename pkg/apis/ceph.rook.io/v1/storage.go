@@ -15,6 +15,16 @@ limitations under the License.
 */
 package v1
 
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// the bucket types of the default crush map, ordered from lowest to highest
+var defaultCrushTypes = []string{"osd", "host", "chassis", "rack", "row", "pdu", "pod", "room", "datacenter", "zone", "region", "root"}
+
 // AnyUseAllDevices gets whether to use all devices
 func (s *StorageScopeSpec) AnyUseAllDevices() bool {
 	if s.Selection.GetUseAllDevices() {
@@ -168,3 +178,67 @@ func (s *StorageScopeSpec) IsOnPVCEncrypted() bool {
 
 	return false
 }
+
+// IsCustomType returns whether the type of the bucket is not a type of the default crush map
+func (l *CrushTopologyLabel) IsCustomType() bool {
+	for _, crushType := range defaultCrushTypes {
+		if l.Type == crushType {
+			return false
+		}
+	}
+	return true
+}
+
+// GetAbove returns the type right below the custom type in the crush hierarchy
+func (l *CrushTopologyLabel) GetAbove() string {
+	if l.Above == "" {
+		return "host"
+	}
+	return l.Above
+}
+
+// ValidateCrushTopology checks that the node labels map to distinct bucket types above the hosts. A
+// custom type must be above host, a type of the default crush map or a custom type mapped before it.
+func (s *StorageScopeSpec) ValidateCrushTopology() error {
+	labels := map[string]bool{}
+	types := map[string]bool{"host": true}
+	for _, crushType := range defaultCrushTypes {
+		types[crushType] = true
+	}
+	mapped := map[string]bool{}
+
+	for _, topology := range s.CrushTopology {
+		if errs := validation.IsQualifiedName(topology.Label); len(errs) > 0 {
+			return errors.Errorf("invalid crush topology label %q. %s", topology.Label, strings.Join(errs, ", "))
+		}
+		if labels[topology.Label] {
+			return errors.Errorf("crush topology label %q is mapped more than once", topology.Label)
+		}
+		labels[topology.Label] = true
+
+		if !crushNameRegex.MatchString(topology.Type) {
+			return errors.Errorf("invalid crush topology type %q", topology.Type)
+		}
+		// the osds and the hosts are placed by rook, and the root is the crush root of the osds
+		if topology.Type == "osd" || topology.Type == "host" || topology.Type == "root" {
+			return errors.Errorf("crush topology label %q cannot be mapped to the %q type", topology.Label, topology.Type)
+		}
+		if mapped[topology.Type] {
+			return errors.Errorf("crush topology type %q is mapped more than once", topology.Type)
+		}
+		mapped[topology.Type] = true
+
+		if !topology.IsCustomType() {
+			if topology.Above != "" {
+				return errors.Errorf("crush topology type %q of the default crush map cannot be moved above %q", topology.Type, topology.Above)
+			}
+			continue
+		}
+		above := topology.GetAbove()
+		if !types[above] || above == "osd" || above == "root" {
+			return errors.Errorf("invalid type %q below the custom crush topology type %q", above, topology.Type)
+		}
+		types[topology.Type] = true
+	}
+	return nil
+}
@@ -287,3 +287,36 @@ func TestIsOnPVCEncrypted(t *testing.T) {
 	}
 	assert.True(t, s.IsOnPVCEncrypted())
 }
+
+func TestValidateCrushTopology(t *testing.T) {
+	s := &StorageScopeSpec{}
+	assert.NoError(t, s.ValidateCrushTopology())
+
+	s.CrushTopology = []CrushTopologyLabel{
+		{Label: "example.com/rack", Type: "rack"},
+		{Label: "example.com/building", Type: "building", Above: "room"},
+		{Label: "example.com/campus", Type: "campus", Above: "building"},
+		{Label: "example.com/shelf", Type: "shelf"},
+	}
+	assert.NoError(t, s.ValidateCrushTopology())
+	assert.False(t, s.CrushTopology[0].IsCustomType())
+	assert.True(t, s.CrushTopology[1].IsCustomType())
+	assert.Equal(t, "room", s.CrushTopology[1].GetAbove())
+	assert.Equal(t, "host", s.CrushTopology[3].GetAbove())
+
+	invalid := map[string][]CrushTopologyLabel{
+		"invalid label":         {{Label: "example.com/", Type: "rack"}},
+		"duplicated label":      {{Label: "rack", Type: "rack"}, {Label: "rack", Type: "row"}},
+		"invalid type":          {{Label: "rack", Type: "rack a"}},
+		"host type":             {{Label: "node", Type: "host"}},
+		"root type":             {{Label: "root", Type: "root"}},
+		"duplicated type":       {{Label: "rack", Type: "rack"}, {Label: "rack2", Type: "rack"}},
+		"default type moved":    {{Label: "rack", Type: "rack", Above: "room"}},
+		"unknown type below":    {{Label: "building", Type: "building", Above: "campus"}, {Label: "campus", Type: "campus"}},
+		"custom type above osd": {{Label: "disk", Type: "disk", Above: "osd"}},
+	}
+	for name, crushTopology := range invalid {
+		s.CrushTopology = crushTopology
+		assert.Error(t, s.ValidateCrushTopology(), name)
+	}
+}
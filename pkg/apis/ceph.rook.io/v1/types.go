@@ -62,6 +62,39 @@ type CephClusterHealthCheckSpec struct {
 	// LivenessProbe allows to change the livenessprobe configuration for a given daemon
 	// +optional
 	LivenessProbe map[KeyType]*ProbeSpec `json:"livenessProbe,omitempty"`
+	// Mute is the list of Ceph health checks to mute
+	// +optional
+	Mute []CephHealthMuteSpec `json:"mute,omitempty"`
+	// Checks overrides how the operator handles Ceph health checks, keyed by health check code
+	// +optional
+	Checks map[string]CephHealthCheckPolicySpec `json:"checks,omitempty"`
+}
+
+// CephHealthMuteSpec represents a Ceph health check muted with "ceph health mute"
+type CephHealthMuteSpec struct {
+	// Code is the code of the health check, such as "AUTH_INSECURE_GLOBAL_ID_RECLAIM_ALLOWED"
+	Code string `json:"code"`
+	// TTL is how long the health check stays muted. If not set, the health check is muted until
+	// it is removed from the list.
+	// +optional
+	TTL *metav1.Duration `json:"ttl,omitempty"`
+	// Sticky keeps the health check muted even if it clears and is raised again later
+	// +optional
+	Sticky bool `json:"sticky,omitempty"`
+}
+
+// CephHealthCheckPolicySpec overrides how the operator handles a Ceph health check
+type CephHealthCheckPolicySpec struct {
+	// Severity overrides the severity of the health check. A HEALTH_ERR check blocks the reconcile of
+	// the other Ceph resources and emits a Kubernetes warning event on the CephCluster when it is
+	// raised. A HEALTH_OK check is ignored.
+	// +kubebuilder:validation:Enum=HEALTH_OK;HEALTH_WARN;HEALTH_ERR
+	// +optional
+	Severity string `json:"severity,omitempty"`
+	// Event emits a Kubernetes warning event on the CephCluster when the health check is raised with
+	// the HEALTH_WARN severity. The HEALTH_ERR checks always emit an event.
+	// +optional
+	Event bool `json:"event,omitempty"`
 }
 
 // DaemonHealthSpec is a daemon health check
@@ -157,6 +190,11 @@ type ClusterSpec struct {
 	// +optional
 	WaitTimeoutForHealthyOSDInMinutes time.Duration `json:"waitTimeoutForHealthyOSDInMinutes,omitempty"`
 
+	// UpgradeStrategy controls how a new Ceph image is rolled out to the daemons
+	// +optional
+	// +nullable
+	UpgradeStrategy UpgradeStrategySpec `json:"upgradeStrategy,omitempty"`
+
 	// A spec for configuring disruption management.
 	// +nullable
 	// +optional
@@ -217,6 +255,60 @@ type ClusterSpec struct {
 	// +optional
 	// +nullable
 	LogCollector LogCollectorSpec `json:"logCollector,omitempty"`
+
+	// CSI represents the settings of the ceph-csi drivers for the cluster
+	// +optional
+	// +nullable
+	CSI CSIDriverSpec `json:"csi,omitempty"`
+}
+
+// CSIDriverSpec represents the settings of the ceph-csi drivers for a cluster
+type CSIDriverSpec struct {
+	// DriverNamePrefix deploys dedicated instances of the csi drivers for the cluster, named
+	// "<driverNamePrefix>rbd.csi.ceph.com" and "<driverNamePrefix>cephfs.csi.ceph.com". The cluster
+	// is served by the shared instances of the operator if not set.
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-.a-z0-9]*[a-z0-9])?\.$`
+	// +optional
+	DriverNamePrefix string `json:"driverNamePrefix,omitempty"`
+
+	// ForceCephFSKernelClient mounts the CephFS volumes with the kernel client instead of
+	// ceph-fuse in the dedicated instances. The operator setting is used if not set.
+	// +optional
+	ForceCephFSKernelClient *bool `json:"forceCephFSKernelClient,omitempty"`
+
+	// EnableHostNetwork runs the plugin pods of the dedicated instances on the host network. The
+	// operator setting is used if not set.
+	// +optional
+	EnableHostNetwork *bool `json:"enableHostNetwork,omitempty"`
+
+	// ProvisionerReplicas is the number of provisioner pods of the dedicated instances. The
+	// operator setting is used if not set.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	ProvisionerReplicas *int32 `json:"provisionerReplicas,omitempty"`
+
+	// MetricsPortOffset is added to the metrics ports of the dedicated instances, so that they do
+	// not conflict with the ports of the other instances on the host network
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=1000
+	// +optional
+	MetricsPortOffset int32 `json:"metricsPortOffset,omitempty"`
+
+	// EncryptionKMS are the KMS configurations of the encrypted RBD volumes. A configuration is
+	// selected with the encryptionKMSID parameter of the storage class.
+	// +optional
+	EncryptionKMS []CSIEncryptionKMSSpec `json:"encryptionKMS,omitempty"`
+}
+
+// CSIEncryptionKMSSpec represents a KMS configuration of the encrypted RBD volumes
+type CSIEncryptionKMSSpec struct {
+	// Name is the KMS ID of the configuration, used as encryptionKMSID in the storage classes.
+	// The name must be unique across the clusters.
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`
+	Name string `json:"name"`
+
+	// The connection details of the KMS, with the same settings as the KMS of the OSD encryption
+	KeyManagementServiceSpec `json:",inline"`
 }
 
 // LogCollectorSpec is the logging spec
@@ -259,8 +351,73 @@ type CephVersionSpec struct {
 	// Whether to allow unsupported versions (do not set to true in production)
 	// +optional
 	AllowUnsupported bool `json:"allowUnsupported,omitempty"`
+
+	// RevertToLastKnownGood replaces the image with the last image that was successfully applied
+	// to the cluster. The revert is refused if it would downgrade the daemons to another Ceph
+	// release. The operator resets this setting once the image is reverted.
+	// +optional
+	RevertToLastKnownGood bool `json:"revertToLastKnownGood,omitempty"`
+}
+
+// UpgradeStrategySpec represents the settings that control how a Ceph version upgrade is rolled out.
+// Daemon types are always upgraded in the order mon, mgr, osd, mds, rgw.
+type UpgradeStrategySpec struct {
+	// Paused stops the upgrade before the next daemon type is rolled out. Set it back to false to
+	// resume the upgrade.
+	// +optional
+	Paused bool `json:"paused,omitempty"`
+
+	// PauseAfter is the list of daemon types after which the upgrade pauses. The upgrade resumes
+	// when the daemon type it is paused after is removed from the list.
+	// +optional
+	PauseAfter []UpgradeDaemonType `json:"pauseAfter,omitempty"`
+
+	// PauseOnHealthError pauses the upgrade while Ceph reports HEALTH_ERR. The upgrade resumes
+	// automatically once the cluster is no longer in error.
+	// +optional
+	PauseOnHealthError bool `json:"pauseOnHealthError,omitempty"`
+
+	// OSDFailureDomain limits OSD upgrades to a single CRUSH bucket of this type at a time, for
+	// example "host", "rack" or "zone". OSDs in different buckets of this type are never restarted
+	// in parallel.
+	// +optional
+	OSDFailureDomain string `json:"osdFailureDomain,omitempty"`
+
+	// Canary upgrades a few OSDs first and waits for them to soak before upgrading the others
+	// +optional
+	// +nullable
+	Canary *UpgradeCanarySpec `json:"canary,omitempty"`
+}
+
+// UpgradeCanarySpec represents the canary OSDs upgraded before the rest of the OSDs
+type UpgradeCanarySpec struct {
+	// OSDCount is the number of OSDs upgraded before the others
+	// +kubebuilder:validation:Minimum=1
+	OSDCount int `json:"osdCount"`
+
+	// SoakTime is the time the canary OSDs must run the new version, with a healthy cluster,
+	// before the rest of the OSDs are upgraded
+	// +optional
+	// +nullable
+	SoakTime *metav1.Duration `json:"soakTime,omitempty"`
 }
 
+// UpgradeDaemonType is a daemon type the upgrade strategy can pause after
+// +kubebuilder:validation:Enum=mon;mgr;osd;mds;rgw
+type UpgradeDaemonType string
+
+// UpgradePhase is the phase of a Ceph version upgrade
+type UpgradePhase string
+
+const (
+	// UpgradePhaseProgressing means the new Ceph version is being rolled out
+	UpgradePhaseProgressing UpgradePhase = "Progressing"
+	// UpgradePhasePaused means the rollout of the new Ceph version is paused
+	UpgradePhasePaused UpgradePhase = "Paused"
+	// UpgradePhaseCompleted means all the daemons run the new Ceph version
+	UpgradePhaseCompleted UpgradePhase = "Completed"
+)
+
 // DashboardSpec represents the settings for the Ceph dashboard
 type DashboardSpec struct {
 	// Enabled determines whether to enable the dashboard
@@ -277,6 +434,88 @@ type DashboardSpec struct {
 	// SSL determines whether SSL should be used
 	// +optional
 	SSL bool `json:"ssl,omitempty"`
+	// Users are the dashboard users to create in addition to the admin user
+	// +optional
+	Users []DashboardUserSpec `json:"users,omitempty"`
+	// Roles are the custom dashboard roles that can be granted to the users
+	// +optional
+	Roles []DashboardRoleSpec `json:"roles,omitempty"`
+	// SSO is the single sign-on configuration of the dashboard
+	// +optional
+	SSO *DashboardSSOSpec `json:"sso,omitempty"`
+}
+
+// DashboardUserSpec represents a user of the dashboard
+type DashboardUserSpec struct {
+	// Name is the name the user logs in with
+	Name string `json:"name"`
+	// PasswordSecretName is the name of a Secret in the cluster namespace with the password of the
+	// user in the "password" key
+	PasswordSecretName string `json:"passwordSecretName"`
+	// Roles are the roles of the user, either built-in roles such as "read-only" or
+	// "block-manager", or custom roles from the dashboard roles
+	// +optional
+	Roles []string `json:"roles,omitempty"`
+	// FullName is the full name of the user
+	// +optional
+	FullName string `json:"fullName,omitempty"`
+	// Email is the email address of the user
+	// +optional
+	Email string `json:"email,omitempty"`
+}
+
+// DashboardRoleSpec represents a custom role of the dashboard
+type DashboardRoleSpec struct {
+	// Name is the name of the role
+	Name string `json:"name"`
+	// Description is the description of the role
+	// +optional
+	Description string `json:"description,omitempty"`
+	// Scopes are the permissions granted by the role for each dashboard security scope, such as
+	// "pool", "rbd-image" or "cephfs"
+	Scopes map[string]DashboardPermissions `json:"scopes"`
+}
+
+// DashboardPermissions is a list of permissions on a dashboard security scope
+type DashboardPermissions []DashboardPermission
+
+// DashboardPermission is a permission on a dashboard security scope
+// +kubebuilder:validation:Enum=read;create;update;delete
+type DashboardPermission string
+
+// DashboardSSOType is the single sign-on protocol of the dashboard
+// +kubebuilder:validation:Enum=saml2
+type DashboardSSOType string
+
+const (
+	// DashboardSSOSAML2 uses a SAML 2.0 identity provider
+	DashboardSSOSAML2 DashboardSSOType = "saml2"
+)
+
+// DashboardSSOSpec represents the single sign-on configuration of the dashboard
+type DashboardSSOSpec struct {
+	// Enabled determines whether single sign-on is enabled
+	// +optional
+	Enabled bool `json:"enabled,omitempty"`
+	// Type is the single sign-on protocol
+	Type DashboardSSOType `json:"type"`
+	// SAML2 is the configuration of the SAML 2.0 identity provider
+	// +optional
+	SAML2 *DashboardSAML2Spec `json:"saml2,omitempty"`
+}
+
+// DashboardSAML2Spec represents the configuration of a SAML 2.0 identity provider
+type DashboardSAML2Spec struct {
+	// BaseURL is the URL the dashboard is reached at by the users
+	BaseURL string `json:"baseURL"`
+	// IdPMetadata is the URL of the metadata of the identity provider
+	IdPMetadata string `json:"idpMetadata"`
+	// UsernameAttribute is the attribute of the identity provider response used as the user name
+	// +optional
+	UsernameAttribute string `json:"usernameAttribute,omitempty"`
+	// EntityID is the entity ID of the identity provider, needed if the metadata has several IdPs
+	// +optional
+	EntityID string `json:"entityID,omitempty"`
 }
 
 // MonitoringSpec represents the settings for Prometheus based Ceph monitoring
@@ -312,6 +551,58 @@ type ClusterStatus struct {
 	CephStatus  *CephStatus     `json:"ceph,omitempty"`
 	CephStorage *CephStorage    `json:"storage,omitempty"`
 	CephVersion *ClusterVersion `json:"version,omitempty"`
+	// +optional
+	UpgradeStatus *UpgradeStatus `json:"upgradeStatus,omitempty"`
+	// ImageHistory lists the last Ceph images that were successfully applied to the cluster, the
+	// most recent one last
+	// +optional
+	ImageHistory []CephImageHistory `json:"imageHistory,omitempty"`
+}
+
+// CephImageHistory represents a Ceph image that was successfully applied to the cluster
+type CephImageHistory struct {
+	// Image is the Ceph container image
+	Image string `json:"image"`
+	// Version is the Ceph version of the image
+	Version string `json:"version"`
+	// AppliedTime is the time at which the cluster was first successfully reconciled with the image
+	// +optional
+	AppliedTime string `json:"appliedTime,omitempty"`
+}
+
+// UpgradeStatus represents the progress of a Ceph version upgrade
+type UpgradeStatus struct {
+	// Phase is the current phase of the upgrade
+	// +optional
+	Phase UpgradePhase `json:"phase,omitempty"`
+	// Message gives details about the current phase, such as why the upgrade is paused
+	// +optional
+	Message string `json:"message,omitempty"`
+	// Image is the Ceph image being rolled out
+	// +optional
+	Image string `json:"image,omitempty"`
+	// Version is the Ceph version being rolled out
+	// +optional
+	Version string `json:"version,omitempty"`
+	// PausedAfter is the daemon type after which the upgrade is paused
+	// +optional
+	PausedAfter string `json:"pausedAfter,omitempty"`
+	// Daemons is the upgrade progress of each daemon type
+	// +optional
+	Daemons []DaemonUpgradeStatus `json:"daemons,omitempty"`
+	// LastUpdated is the last time the upgrade status was updated
+	// +optional
+	LastUpdated string `json:"lastUpdated,omitempty"`
+}
+
+// DaemonUpgradeStatus represents the upgrade progress of a daemon type
+type DaemonUpgradeStatus struct {
+	// DaemonType is the type of the daemons, such as mon or osd
+	DaemonType string `json:"daemonType"`
+	// Upgraded is the number of daemons running the new Ceph version
+	Upgraded int `json:"upgraded"`
+	// Total is the number of daemons of this type
+	Total int `json:"total"`
 }
 
 // CephDaemonsVersions show the current ceph version for different ceph daemons
@@ -352,6 +643,24 @@ type CephStatus struct {
 	Capacity       Capacity                     `json:"capacity,omitempty"`
 	// +optional
 	Versions *CephDaemonsVersions `json:"versions,omitempty"`
+	// +optional
+	Mgr *MgrStatus `json:"mgr,omitempty"`
+}
+
+// MgrStatus represents the state of the ceph managers
+type MgrStatus struct {
+	// Active is the name of the active mgr
+	// +optional
+	Active string `json:"active,omitempty"`
+	// Available is whether the active mgr is available
+	// +optional
+	Available bool `json:"available,omitempty"`
+	// Standbys are the names of the standby mgrs
+	// +optional
+	Standbys []string `json:"standbys,omitempty"`
+	// Modules are the names of the enabled mgr modules
+	// +optional
+	Modules []string `json:"modules,omitempty"`
 }
 
 // Capacity is the capacity information of a Ceph Cluster
@@ -534,6 +843,11 @@ type Module struct {
 	// Enabled determines whether a module should be enabled or not
 	// +optional
 	Enabled bool `json:"enabled,omitempty"`
+	// Settings are the module options applied with "ceph config set mgr mgr/<module>/<key> <value>".
+	// A setting removed from this list is reset to its default value.
+	// +optional
+	// +nullable
+	Settings map[string]string `json:"settings,omitempty"`
 }
 
 // ExternalSpec represents the options supported by an external cluster
@@ -636,6 +950,95 @@ type PoolSpec struct {
 	// +optional
 	// +nullable
 	Quotas QuotaSpec `json:"quotas,omitempty"`
+
+	// The name of the crush rule of the pool, such as a CephCrushRule of the cluster. The crush rule
+	// replaces the failure domain, crush root and device class settings.
+	// +optional
+	CrushRule string `json:"crushRule,omitempty"`
+
+	// The placement group settings of the pool. A change of the number of placement groups of an existing
+	// pool is applied gradually.
+	// +optional
+	// +nullable
+	PlacementGroups *PlacementGroupSpec `json:"placementGroups,omitempty"`
+
+	// The migration of the rbd images to a pool with another layout, only supported by the CephBlockPool
+	// +optional
+	// +nullable
+	Migration *PoolMigrationSpec `json:"migration,omitempty"`
+}
+
+// PlacementGroupSpec represents the placement group settings of a pool
+type PlacementGroupSpec struct {
+	// PgNum is the number of placement groups of the pool. The autoscaler must be off or in warn mode.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	PgNum int `json:"pgNum,omitempty"`
+
+	// PgpNum is the number of placement groups used for the placement of the data, defaults to pgNum
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	PgpNum int `json:"pgpNum,omitempty"`
+
+	// PgNumMin is the minimum number of placement groups set by the autoscaler
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	PgNumMin int `json:"pgNumMin,omitempty"`
+
+	// PgNumMax is the maximum number of placement groups set by the autoscaler
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	PgNumMax int `json:"pgNumMax,omitempty"`
+
+	// Bulk marks the pool as expected to hold most of the data, the autoscaler then starts it with
+	// many placement groups
+	// +optional
+	// +nullable
+	Bulk *bool `json:"bulk,omitempty"`
+
+	// AutoscaleMode is the mode of the placement group autoscaler for the pool (options are: on, off, warn)
+	// +kubebuilder:validation:Enum=on;off;warn;""
+	// +optional
+	AutoscaleMode string `json:"autoscaleMode,omitempty"`
+}
+
+// PoolMigrationSpec represents the migration of the data of the rbd images of a pool to a target pool
+// with another layout. The migration of an image only starts when its clients are stopped.
+type PoolMigrationSpec struct {
+	// TargetPool is the name of the pool created with the target layout, where the data of the images
+	// is moved
+	TargetPool string `json:"targetPool"`
+
+	// The failure domain of the target pool, defaults to the failure domain of the pool
+	// +optional
+	FailureDomain string `json:"failureDomain,omitempty"`
+
+	// The device class of the target pool, defaults to the device class of the pool
+	// +optional
+	DeviceClass string `json:"deviceClass,omitempty"`
+
+	// The replication settings of the target pool
+	// +optional
+	Replicated ReplicatedSpec `json:"replicated,omitempty"`
+
+	// The erasure code settings of the target pool
+	// +optional
+	ErasureCoded ErasureCodedSpec `json:"erasureCoded,omitempty"`
+
+	// MaxConcurrentImages is the number of images migrated at the same time, defaults to 1
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	MaxConcurrentImages int `json:"maxConcurrentImages,omitempty"`
+
+	// Paused stops migrating more images, the images being migrated complete their migration
+	// +optional
+	Paused bool `json:"paused,omitempty"`
+
+	// ScaleDownImages are the images in use, as <pool>/<image> or <pool>/<namespace>/<image>, whose
+	// deployments and statefulsets are scaled down by the operator to prepare their migration. They are
+	// scaled up again once the migration is prepared.
+	// +optional
+	ScaleDownImages []string `json:"scaleDownImages,omitempty"`
 }
 
 // MirrorHealthCheckSpec represents the health specification of a Ceph Storage Pool mirror
@@ -643,6 +1046,64 @@ type MirrorHealthCheckSpec struct {
 	// +optional
 	// +nullable
 	Mirror HealthCheckSpec `json:"mirror,omitempty"`
+	// Usage is the check of the usage of the capacity and of the quotas of the pools
+	// +optional
+	// +nullable
+	Usage PoolUsageCheckSpec `json:"usage,omitempty"`
+}
+
+// PoolUsageCheckSpec represents the check of the usage of the capacity and of the quotas of the pools
+type PoolUsageCheckSpec struct {
+	// +optional
+	Disabled bool `json:"disabled,omitempty"`
+	// Interval is the interval of the check of the usage of the pools, defaults to 60s
+	// +optional
+	Interval *metav1.Duration `json:"interval,omitempty"`
+	// NearFullPercent is the percentage of the capacity of a pool above which a warning event is
+	// emitted, defaults to 85
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=100
+	// +optional
+	NearFullPercent int `json:"nearFullPercent,omitempty"`
+	// QuotaPercent is the percentage of the quotas of a pool above which a warning event is emitted,
+	// defaults to 90
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=100
+	// +optional
+	QuotaPercent int `json:"quotaPercent,omitempty"`
+}
+
+// PoolUsageStatus is the usage of the capacity and of the quotas of a pool
+type PoolUsageStatus struct {
+	// Name is the name of the pool
+	Name string `json:"name"`
+	// StoredBytes is the size of the data stored in the pool, without the replicas or coding chunks
+	// +optional
+	StoredBytes uint64 `json:"storedBytes"`
+	// Objects is the number of objects of the pool
+	// +optional
+	Objects uint64 `json:"objects"`
+	// PercentUsed is the percentage of the capacity of the pool that is used
+	// +optional
+	PercentUsed float64 `json:"percentUsed"`
+	// MaxAvailBytes is the size of the data that can still be stored in the pool
+	// +optional
+	MaxAvailBytes uint64 `json:"maxAvailBytes"`
+	// QuotaMaxBytes is the quota of the pool in bytes, zero when the pool has no quota
+	// +optional
+	QuotaMaxBytes uint64 `json:"quotaMaxBytes,omitempty"`
+	// QuotaBytesPercent is the percentage of the quota in bytes that is used
+	// +optional
+	QuotaBytesPercent float64 `json:"quotaBytesPercent,omitempty"`
+	// QuotaMaxObjects is the quota of the pool in objects, zero when the pool has no quota
+	// +optional
+	QuotaMaxObjects uint64 `json:"quotaMaxObjects,omitempty"`
+	// QuotaObjectsPercent is the percentage of the quota in objects that is used
+	// +optional
+	QuotaObjectsPercent float64 `json:"quotaObjectsPercent,omitempty"`
+	// LastChecked is the last time the usage was checked
+	// +optional
+	LastChecked string `json:"lastChecked,omitempty"`
 }
 
 // CephBlockPoolStatus represents the mirroring status of Ceph Storage Pool
@@ -658,6 +1119,54 @@ type CephBlockPoolStatus struct {
 	// +optional
 	// +nullable
 	Info map[string]string `json:"info,omitempty"`
+	// +optional
+	Migration *PoolMigrationStatus `json:"migration,omitempty"`
+	// Usage is the usage of the capacity and of the quotas of the pool
+	// +optional
+	Usage *PoolUsageStatus `json:"usage,omitempty"`
+	// +optional
+	Conditions []Condition `json:"conditions,omitempty"`
+}
+
+// PoolMigrationStatus is the status of the migration of the rbd images of a pool
+type PoolMigrationStatus struct {
+	// Phase is Progressing until the data of all the images is in the target pool, then Ready
+	// +optional
+	Phase ConditionType `json:"phase,omitempty"`
+	// TargetPool is the pool where the data of the images is moved
+	// +optional
+	TargetPool string `json:"targetPool,omitempty"`
+	// TotalImages is the number of images to migrate
+	// +optional
+	TotalImages int `json:"totalImages"`
+	// MigratedImages is the number of images whose data is in the target pool
+	// +optional
+	MigratedImages int `json:"migratedImages"`
+	// BlockedImages is the number of images whose migration cannot start because they are in use
+	// +optional
+	BlockedImages int `json:"blockedImages"`
+	// Images is the progress of the images that are not migrated yet
+	// +optional
+	Images []PoolMigrationImageStatus `json:"images,omitempty"`
+	// LastChecked is the last time the migration was checked
+	// +optional
+	LastChecked string `json:"lastChecked,omitempty"`
+}
+
+// PoolMigrationImageStatus is the migration progress of an rbd image
+type PoolMigrationImageStatus struct {
+	// Name is the image spec of the image, <pool>/<image>
+	Name string `json:"name"`
+	// State is the migration state of the image as reported by 'rbd status', or pending if the
+	// migration did not start
+	// +optional
+	State string `json:"state,omitempty"`
+	// Description is the description of the migration state
+	// +optional
+	Description string `json:"description,omitempty"`
+	// Message reports why the migration of the image did not start or failed
+	// +optional
+	Message string `json:"message,omitempty"`
 }
 
 // MirroringStatusSpec is the status of the pool mirroring
@@ -982,6 +1491,11 @@ type FilesystemSpec struct {
 	// +optional
 	Mirroring *FSMirroringSpec `json:"mirroring,omitempty"`
 
+	// The snapshot schedules of the paths and subvolumes of the filesystem
+	// +nullable
+	// +optional
+	SnapshotScheduling *FSSnapshotSchedulingSpec `json:"snapshotScheduling,omitempty"`
+
 	// The mirroring statusCheck
 	// +kubebuilder:pruning:PreserveUnknownFields
 	StatusCheck MirrorHealthCheckSpec `json:"statusCheck,omitempty"`
@@ -1061,6 +1575,56 @@ type SnapshotScheduleRetentionSpec struct {
 	Duration string `json:"duration,omitempty"`
 }
 
+// FSSnapshotSchedulingSpec represents the snapshot schedules of a filesystem
+type FSSnapshotSchedulingSpec struct {
+	// Enabled whether the snapshot schedules of the filesystem are managed by the operator. When
+	// enabled, the schedules and retention policies that are not in the spec are removed.
+	// +optional
+	Enabled bool `json:"enabled,omitempty"`
+
+	// Schedules are the snapshot schedules of the paths and subvolumes of the filesystem
+	// +optional
+	Schedules []FSSnapshotScheduleSpec `json:"schedules,omitempty"`
+}
+
+// FSSnapshotScheduleSpec represents the snapshot schedule of a path or a subvolume
+type FSSnapshotScheduleSpec struct {
+	// Path is the absolute path of the directory to snapshot. The root of the filesystem is used if
+	// neither the path nor the subvolume are set.
+	// +optional
+	Path string `json:"path,omitempty"`
+
+	// Subvolume is the name of the subvolume to snapshot
+	// +optional
+	Subvolume string `json:"subvolume,omitempty"`
+
+	// SubvolumeGroup is the group of the subvolume, the default group is used if not set
+	// +optional
+	SubvolumeGroup string `json:"subvolumeGroup,omitempty"`
+
+	// Intervals are the periodicities of the snapshots
+	// +kubebuilder:validation:MinItems=1
+	Intervals []FSSnapshotIntervalSpec `json:"intervals"`
+
+	// Retention is the retention policy of the snapshots, as count and period pairs. For example,
+	// "24h7d" keeps 24 hourly and 7 daily snapshots.
+	// +kubebuilder:validation:Pattern=`^([0-9]+[hdwmyn])+$`
+	// +optional
+	Retention string `json:"retention,omitempty"`
+}
+
+// FSSnapshotIntervalSpec represents the periodicity of the snapshots of a path
+type FSSnapshotIntervalSpec struct {
+	// Interval is the periodicity of the snapshots in hours, days or weeks, for example "1h"
+	// +kubebuilder:validation:Pattern=`^[0-9]+[hdw]$`
+	Interval string `json:"interval"`
+
+	// StartTime is when the first snapshot is taken, for example "2021-07-01T00:00:00". The
+	// snapshots start at midnight if not set.
+	// +optional
+	StartTime string `json:"startTime,omitempty"`
+}
+
 // CephFilesystemStatus represents the status of a Ceph Filesystem
 type CephFilesystemStatus struct {
 	// +optional
@@ -1074,6 +1638,9 @@ type CephFilesystemStatus struct {
 	// MirroringStatus is the filesystem mirroring status
 	// +optional
 	MirroringStatus *FilesystemMirroringInfoSpec `json:"mirroringStatus,omitempty"`
+	// PoolUsage is the usage of the capacity and of the quotas of the metadata and data pools
+	// +optional
+	PoolUsage []PoolUsageStatus `json:"poolUsage,omitempty"`
 }
 
 // FilesystemMirroringInfo is the status of the pool mirroring
@@ -1127,6 +1694,24 @@ type FilesystemSnapshotSchedulesSpec struct {
 	Schedule string `json:"schedule,omitempty"`
 	// +optional
 	Retention FilesystemSnapshotScheduleStatusRetention `json:"retention,omitempty"`
+	// RetentionCounts is the number of snapshots kept by period, for example {"h": 24}
+	// +optional
+	RetentionCounts map[string]int `json:"retentionCounts,omitempty"`
+	// Start is when the snapshot schedule starts
+	// +optional
+	Start string `json:"start,omitempty"`
+	// Last is when the last snapshot was taken
+	// +optional
+	Last string `json:"last,omitempty"`
+	// Next is when the next snapshot will be taken
+	// +optional
+	Next string `json:"next,omitempty"`
+	// Active is whether the snapshot schedule is active
+	// +optional
+	Active bool `json:"active,omitempty"`
+	// Details contains the error of a schedule of the spec that is not configured
+	// +optional
+	Details string `json:"details,omitempty"`
 }
 
 // FilesystemSnapshotScheduleStatusRetention is the retention specification for a filesystem snapshot schedule
@@ -1284,6 +1869,10 @@ type BucketHealthCheckSpec struct {
 	Bucket HealthCheckSpec `json:"bucket,omitempty"`
 	// +optional
 	LivenessProbe *ProbeSpec `json:"livenessProbe,omitempty"`
+	// Usage is the check of the usage of the capacity and of the quotas of the pools
+	// +optional
+	// +nullable
+	Usage PoolUsageCheckSpec `json:"usage,omitempty"`
 }
 
 // HealthCheckSpec represents the health check of an object store bucket
@@ -1382,6 +1971,9 @@ type ObjectStoreStatus struct {
 	// +nullable
 	Info       map[string]string `json:"info,omitempty"`
 	Conditions []Condition       `json:"conditions,omitempty"`
+	// PoolUsage is the usage of the capacity and of the quotas of the metadata and data pools
+	// +optional
+	PoolUsage []PoolUsageStatus `json:"poolUsage,omitempty"`
 }
 
 // BucketStatus represents the status of a bucket
@@ -1623,12 +2215,113 @@ type NFSGaneshaSpec struct {
 
 	// Server is the Ganesha Server specification
 	Server GaneshaServerSpec `json:"server"`
-}
 
-// GaneshaRADOSSpec represents the specification of a Ganesha RADOS object
-type GaneshaRADOSSpec struct {
-	// Pool is the RADOS pool where NFS client recovery data is stored.
-	Pool string `json:"pool"`
+	// Ingress gives each active Ganesha server a stable virtual IP
+	// +nullable
+	// +optional
+	Ingress *NFSIngressSpec `json:"ingress,omitempty"`
+
+	// Security represents the Kerberos and identity mapping settings of the Ganesha servers
+	// +nullable
+	// +optional
+	Security *NFSSecuritySpec `json:"security,omitempty"`
+}
+
+// NFSSecuritySpec represents the security settings of the Ganesha servers of a CephNFS
+type NFSSecuritySpec struct {
+	// Kerberos enables the krb5, krb5i and krb5p security flavors on the Ganesha servers
+	// +optional
+	Kerberos *NFSKerberosSpec `json:"kerberos,omitempty"`
+
+	// IDMapping configures how the NFSv4 user and group names are mapped to ids
+	// +optional
+	IDMapping *NFSIDMappingSpec `json:"idMapping,omitempty"`
+}
+
+// NFSKerberosSpec represents the Kerberos settings of the Ganesha servers
+type NFSKerberosSpec struct {
+	// PrincipalName is the service name of the Kerberos principal of the Ganesha servers. The
+	// principal is "<principalName>/<hostname>@<realm>". "nfs" is used if not set.
+	// +optional
+	PrincipalName string `json:"principalName,omitempty"`
+
+	// Realm is the Kerberos realm of the principal
+	// +kubebuilder:validation:MinLength=1
+	Realm string `json:"realm"`
+
+	// KeytabSecretName is the name of the Secret with the keytab of the principal in the
+	// "krb5.keytab" key
+	// +kubebuilder:validation:MinLength=1
+	KeytabSecretName string `json:"keytabSecretName"`
+
+	// ConfigMapName is the name of the ConfigMap with the Kerberos configuration in the "krb5.conf"
+	// key. If not set, the KDCs of the realm are looked up in the DNS.
+	// +optional
+	ConfigMapName string `json:"configMapName,omitempty"`
+
+	// SecurityFlavors are the security flavors of the exports that do not set their own. krb5,
+	// krb5i and krb5p are used if not set.
+	// +optional
+	SecurityFlavors []NFSSecurityFlavor `json:"securityFlavors,omitempty"`
+}
+
+// NFSIDMappingSpec represents the NFSv4 id mapping settings of the Ganesha servers
+type NFSIDMappingSpec struct {
+	// Domain is the NFSv4 id mapping domain. The lowercase Kerberos realm is used if not set.
+	// +optional
+	Domain string `json:"domain,omitempty"`
+
+	// SSSD runs an SSSD sidecar resolving the users and groups, for example from LDAP
+	// +optional
+	SSSD *SSSDSpec `json:"sssd,omitempty"`
+}
+
+// SSSDSpec represents the SSSD sidecar of the Ganesha servers
+type SSSDSpec struct {
+	// Image is the container image of the SSSD sidecar
+	// +kubebuilder:validation:MinLength=1
+	Image string `json:"image"`
+
+	// ConfigMapName is the name of the ConfigMap with the SSSD configuration in the "sssd.conf" key
+	// +kubebuilder:validation:MinLength=1
+	ConfigMapName string `json:"configMapName"`
+
+	// Resources set resource requests and limits of the SSSD sidecar
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// +nullable
+	// +optional
+	Resources v1.ResourceRequirements `json:"resources,omitempty"`
+}
+
+// NFSIngressSpec represents the Services with the stable virtual IPs of the active Ganesha servers of
+// a CephNFS
+type NFSIngressSpec struct {
+	// Enabled creates a Service with a stable virtual IP for each active Ganesha server. The virtual IP
+	// follows the pod of the server when it is restarted.
+	// +optional
+	Enabled bool `json:"enabled,omitempty"`
+
+	// ServiceType is the type of the ingress Services. LoadBalancer is used if not set.
+	// +kubebuilder:validation:Enum=ClusterIP;LoadBalancer
+	// +optional
+	ServiceType v1.ServiceType `json:"serviceType,omitempty"`
+
+	// LoadBalancerIPs are the virtual IPs requested from the load balancer, keyed by the id of the
+	// Ganesha server such as "a"
+	// +optional
+	LoadBalancerIPs map[string]string `json:"loadBalancerIPs,omitempty"`
+
+	// Annotations are added to the ingress Services, for example to configure the load balancer
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// +nullable
+	// +optional
+	Annotations Annotations `json:"annotations,omitempty"`
+}
+
+// GaneshaRADOSSpec represents the specification of a Ganesha RADOS object
+type GaneshaRADOSSpec struct {
+	// Pool is the RADOS pool where NFS client recovery data is stored.
+	Pool string `json:"pool"`
 
 	// Namespace is the RADOS namespace where NFS client recovery data is stored.
 	Namespace string `json:"namespace"`
@@ -1672,6 +2365,422 @@ type GaneshaServerSpec struct {
 	LogLevel string `json:"logLevel,omitempty"`
 }
 
+// CephNFSExport represents an export of a CephNFS
+// +genclient
+// +genclient:noStatus
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +kubebuilder:printcolumn:name="Phase",type=string,JSONPath=`.status.phase`
+// +kubebuilder:printcolumn:name="NFS",type=string,JSONPath=`.spec.nfsName`
+// +kubebuilder:printcolumn:name="PseudoPath",type=string,JSONPath=`.spec.pseudoPath`
+// +kubebuilder:printcolumn:name="Active",type=boolean,JSONPath=`.status.active`
+// +kubebuilder:subresource:status
+type CephNFSExport struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata"`
+	Spec              NFSExportSpec `json:"spec"`
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// +optional
+	Status *NFSExportStatus `json:"status,omitempty"`
+}
+
+// CephNFSExportList represents a list of Ceph NFS exports
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+type CephNFSExportList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+	Items           []CephNFSExport `json:"items"`
+}
+
+// NFSExportSpec represents the spec of an export of a CephNFS. Either cephfs or rgw must be set.
+type NFSExportSpec struct {
+	// NFSName is the name of the CephNFS CR serving the export
+	// +kubebuilder:validation:MinLength=1
+	NFSName string `json:"nfsName"`
+
+	// CephFS exports a path of a CephFS filesystem
+	// +optional
+	CephFS *NFSExportCephFSSpec `json:"cephfs,omitempty"`
+
+	// RGW exports a bucket of an object store
+	// +optional
+	RGW *NFSExportRGWSpec `json:"rgw,omitempty"`
+
+	// PseudoPath is the path of the export in the NFSv4 pseudo filesystem
+	// +kubebuilder:validation:Pattern=`^/`
+	PseudoPath string `json:"pseudoPath"`
+
+	// AccessType is the access type of the export
+	// +kubebuilder:validation:Enum=RW;RO;None
+	// +optional
+	AccessType NFSExportAccessType `json:"accessType,omitempty"`
+
+	// Squash is the user id squashing of the export. Root squashing is used if not set.
+	// +kubebuilder:validation:Enum=None;Root;All;RootId
+	// +optional
+	Squash NFSExportSquash `json:"squash,omitempty"`
+
+	// AllowedClients are the addresses or CIDRs of the clients allowed to access the export. All
+	// the clients are allowed if not set.
+	// +optional
+	AllowedClients []string `json:"allowedClients,omitempty"`
+
+	// SecurityFlavors are the RPC security flavors allowed for the export
+	// +optional
+	SecurityFlavors []NFSSecurityFlavor `json:"securityFlavors,omitempty"`
+}
+
+// NFSExportCephFSSpec represents the CephFS source of an NFS export
+type NFSExportCephFSSpec struct {
+	// FilesystemName is the name of the CephFilesystem CR of the exported path
+	// +kubebuilder:validation:MinLength=1
+	FilesystemName string `json:"filesystemName"`
+
+	// Path is the exported path in the filesystem
+	// +kubebuilder:validation:Pattern=`^/`
+	// +optional
+	Path string `json:"path,omitempty"`
+}
+
+// NFSExportRGWSpec represents the object store bucket source of an NFS export
+type NFSExportRGWSpec struct {
+	// Bucket is the exported bucket
+	// +kubebuilder:validation:MinLength=1
+	Bucket string `json:"bucket"`
+
+	// UserID is the object store user accessing the bucket
+	// +kubebuilder:validation:MinLength=1
+	UserID string `json:"userID"`
+
+	// UserSecretName is the name of the secret with the AccessKey and SecretKey of the user, as
+	// created for a CephObjectStoreUser
+	// +kubebuilder:validation:MinLength=1
+	UserSecretName string `json:"userSecretName"`
+}
+
+// NFSExportAccessType is the access type of an NFS export
+type NFSExportAccessType string
+
+const (
+	// NFSExportAccessReadWrite allows reading and writing in the export
+	NFSExportAccessReadWrite NFSExportAccessType = "RW"
+	// NFSExportAccessReadOnly only allows reading the export
+	NFSExportAccessReadOnly NFSExportAccessType = "RO"
+	// NFSExportAccessNone denies the access to the export
+	NFSExportAccessNone NFSExportAccessType = "None"
+)
+
+// NFSExportSquash is the user id squashing of an NFS export
+type NFSExportSquash string
+
+const (
+	// NFSExportSquashNone does not squash the user ids
+	NFSExportSquashNone NFSExportSquash = "None"
+	// NFSExportSquashRoot squashes the root user
+	NFSExportSquashRoot NFSExportSquash = "Root"
+	// NFSExportSquashAll squashes all the user ids
+	NFSExportSquashAll NFSExportSquash = "All"
+	// NFSExportSquashRootID squashes the uid and gid of the root user
+	NFSExportSquashRootID NFSExportSquash = "RootId"
+)
+
+// NFSSecurityFlavor is an RPC security flavor of an NFS export
+// +kubebuilder:validation:Enum=sys;krb5;krb5i;krb5p;none
+type NFSSecurityFlavor string
+
+// NFSExportStatus represents the status of an NFS export
+type NFSExportStatus struct {
+	// +optional
+	Phase ConditionType `json:"phase,omitempty"`
+	// ExportID is the ganesha id of the export
+	// +optional
+	ExportID int `json:"exportID,omitempty"`
+	// Active is true when the export is served by all the ganesha servers
+	// +optional
+	Active bool `json:"active"`
+	// ActiveServers are the ganesha servers serving the export
+	// +optional
+	ActiveServers []string `json:"activeServers,omitempty"`
+}
+
+// CephNetworkFence represents the fencing of the ceph clients of a network or of a failed node
+// +genclient
+// +genclient:noStatus
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +kubebuilder:printcolumn:name="Phase",type=string,JSONPath=`.status.phase`
+// +kubebuilder:printcolumn:name="Node",type=string,JSONPath=`.spec.nodeName`
+// +kubebuilder:printcolumn:name="State",type=string,JSONPath=`.spec.fenceState`
+// +kubebuilder:subresource:status
+type CephNetworkFence struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata"`
+	Spec              NetworkFenceSpec `json:"spec"`
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// +optional
+	Status *NetworkFenceStatus `json:"status,omitempty"`
+}
+
+// CephNetworkFenceList represents a list of Ceph network fences
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+type CephNetworkFenceList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+	Items           []CephNetworkFence `json:"items"`
+}
+
+// FenceState is the state of a network fence
+type FenceState string
+
+const (
+	// FenceStateFenced blocklists the clients of the fence
+	FenceStateFenced FenceState = "Fenced"
+	// FenceStateUnfenced removes the clients of the fence from the blocklist
+	FenceStateUnfenced FenceState = "Unfenced"
+)
+
+// NetworkFenceSpec represents the spec of a network fence. Either nodeName or cidrs must be set.
+type NetworkFenceSpec struct {
+	// NodeName fences the clients of a node: the IPs of the node and the clients watching the rbd
+	// images attached to the node
+	// +optional
+	NodeName string `json:"nodeName,omitempty"`
+
+	// CIDRs are the IPs or CIDR ranges of the fenced clients. Ranges require ceph quincy.
+	// +optional
+	CIDRs []string `json:"cidrs,omitempty"`
+
+	// FenceState is the state of the fence: Fenced (default) or Unfenced
+	// +kubebuilder:validation:Enum=Fenced;Unfenced
+	// +kubebuilder:default=Fenced
+	// +optional
+	FenceState FenceState `json:"fenceState,omitempty"`
+}
+
+// NetworkFenceStatus represents the status of a network fence
+type NetworkFenceStatus struct {
+	// +optional
+	Phase ConditionType `json:"phase,omitempty"`
+	// FencedAddresses are the blocklisted IPs and CIDR ranges
+	// +optional
+	FencedAddresses []string `json:"fencedAddresses,omitempty"`
+	// NodeAddresses are the addresses of the clients of the node found while it was fenced
+	// +optional
+	NodeAddresses []string `json:"nodeAddresses,omitempty"`
+}
+
+// CephRBDFailover represents the promotion or demotion of the mirrored rbd images of an application
+// in this cluster, for the failover to a peer cluster
+// +genclient
+// +genclient:noStatus
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +kubebuilder:printcolumn:name="Phase",type=string,JSONPath=`.status.phase`
+// +kubebuilder:printcolumn:name="Pool",type=string,JSONPath=`.spec.pool`
+// +kubebuilder:printcolumn:name="Role",type=string,JSONPath=`.spec.role`
+// +kubebuilder:printcolumn:name="Force",type=boolean,JSONPath=`.spec.force`
+// +kubebuilder:subresource:status
+type CephRBDFailover struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata"`
+	Spec              RBDFailoverSpec `json:"spec"`
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// +optional
+	Status *RBDFailoverStatus `json:"status,omitempty"`
+}
+
+// CephRBDFailoverList represents a list of Ceph rbd failovers
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+type CephRBDFailoverList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+	Items           []CephRBDFailover `json:"items"`
+}
+
+// RBDImageRole is the mirroring role of an rbd image in this cluster
+type RBDImageRole string
+
+const (
+	// RBDImageRolePrimary promotes the images in this cluster
+	RBDImageRolePrimary RBDImageRole = "primary"
+	// RBDImageRoleSecondary demotes the images in this cluster
+	RBDImageRoleSecondary RBDImageRole = "secondary"
+)
+
+// RBDFailoverSpec represents the spec of an rbd failover. The images are selected by name or by the
+// labels of their pvcs.
+type RBDFailoverSpec struct {
+	// Pool is the name of the mirrored CephBlockPool of the images
+	Pool string `json:"pool"`
+
+	// RadosNamespace is the rados namespace of the images, for the images selected by name
+	// +optional
+	RadosNamespace string `json:"radosNamespace,omitempty"`
+
+	// Images are the names of the rbd images
+	// +optional
+	Images []string `json:"images,omitempty"`
+
+	// PVCSelector selects the images of the bound pvcs with the labels
+	// +optional
+	// +nullable
+	PVCSelector *metav1.LabelSelector `json:"pvcSelector,omitempty"`
+
+	// PVCNamespace is the namespace of the selected pvcs, the pvcs of all the namespaces are selected
+	// if not set
+	// +optional
+	PVCNamespace string `json:"pvcNamespace,omitempty"`
+
+	// Role is the role of the images in this cluster: primary promotes them and secondary demotes them
+	// +kubebuilder:validation:Enum=primary;secondary
+	Role RBDImageRole `json:"role"`
+
+	// Force promotes the images even if the peer cluster did not demote them, for an unplanned
+	// failover when the peer cluster is not available
+	// +optional
+	Force bool `json:"force,omitempty"`
+}
+
+// RBDFailoverStatus represents the status of an rbd failover
+type RBDFailoverStatus struct {
+	// +optional
+	Phase ConditionType `json:"phase,omitempty"`
+	// Images is the status of each image of the failover
+	// +optional
+	Images []RBDFailoverImageStatus `json:"images,omitempty"`
+}
+
+// RBDFailoverImageStatus represents the mirroring status of an image of an rbd failover
+type RBDFailoverImageStatus struct {
+	// Name is the name of the image, prefixed by its rados namespace if any
+	Name string `json:"name"`
+	// PVC is the namespaced name of the pvc of the image
+	// +optional
+	PVC string `json:"pvc,omitempty"`
+	// Primary is whether the image is primary in this cluster
+	Primary bool `json:"primary"`
+	// State is the mirroring state of the image, as reported by 'rbd mirror image status'
+	// +optional
+	State string `json:"state,omitempty"`
+	// Description is the description of the mirroring state of the image
+	// +optional
+	Description string `json:"description,omitempty"`
+	// LastUpdate is when the mirroring state was last updated
+	// +optional
+	LastUpdate string `json:"lastUpdate,omitempty"`
+	// ResyncRequested is whether a resync of the image in split-brain was requested. The resync is not
+	// requested again until the mirroring state of the image changes.
+	// +optional
+	ResyncRequested bool `json:"resyncRequested,omitempty"`
+	// Message reports why the image did not reach its role yet
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// CephCrushRule represents a crush rule of the cluster built from a list of steps, which pools can
+// reference instead of their failure domain and device class
+// +genclient
+// +genclient:noStatus
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +kubebuilder:printcolumn:name="Phase",type=string,JSONPath=`.status.phase`
+// +kubebuilder:printcolumn:name="Type",type=string,JSONPath=`.spec.type`
+// +kubebuilder:printcolumn:name="RuleID",type=integer,JSONPath=`.status.ruleID`
+// +kubebuilder:subresource:status
+type CephCrushRule struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata"`
+	Spec              CrushRuleSpec `json:"spec"`
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// +optional
+	Status *CrushRuleStatus `json:"status,omitempty"`
+}
+
+// CephCrushRuleList represents a list of Ceph crush rules
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+type CephCrushRuleList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+	Items           []CephCrushRule `json:"items"`
+}
+
+// CrushRuleType is the type of the pools using a crush rule
+type CrushRuleType string
+
+const (
+	// CrushRuleTypeReplicated is the type of the rules of the replicated pools
+	CrushRuleTypeReplicated CrushRuleType = "replicated"
+	// CrushRuleTypeErasure is the type of the rules of the erasure coded pools
+	CrushRuleTypeErasure CrushRuleType = "erasure"
+)
+
+// CrushRuleStepOp is the operation of a step of a crush rule
+type CrushRuleStepOp string
+
+const (
+	// CrushRuleStepTake starts the placement from a bucket of the crush map
+	CrushRuleStepTake CrushRuleStepOp = "take"
+	// CrushRuleStepChoose chooses buckets of a type under the current buckets
+	CrushRuleStepChoose CrushRuleStepOp = "choose"
+	// CrushRuleStepChooseLeaf chooses buckets of a type and an OSD under each of them
+	CrushRuleStepChooseLeaf CrushRuleStepOp = "chooseleaf"
+	// CrushRuleStepEmit outputs the chosen OSDs
+	CrushRuleStepEmit CrushRuleStepOp = "emit"
+)
+
+// CrushRuleSpec represents the spec of a crush rule. The name of the rule in the crush map is the
+// name of the CephCrushRule.
+type CrushRuleSpec struct {
+	// Type is the type of the pools using the rule, replicated or erasure
+	// +kubebuilder:validation:Enum=replicated;erasure
+	// +optional
+	Type CrushRuleType `json:"type,omitempty"`
+
+	// Steps are the steps of the rule, in order
+	// +kubebuilder:validation:MinItems=1
+	Steps []CrushRuleStep `json:"steps"`
+}
+
+// CrushRuleStep represents a step of a crush rule
+type CrushRuleStep struct {
+	// Op is the operation of the step: take, choose, chooseleaf or emit
+	// +kubebuilder:validation:Enum=take;choose;chooseleaf;emit
+	Op CrushRuleStepOp `json:"op"`
+
+	// Item is the name of the bucket where a take step starts the placement
+	// +optional
+	Item string `json:"item,omitempty"`
+
+	// DeviceClass restricts a take step to the OSDs of the device class
+	// +optional
+	DeviceClass string `json:"deviceClass,omitempty"`
+
+	// Mode is the mode of a choose or chooseleaf step: firstn for the replicated pools and indep for
+	// the erasure coded pools, defaults to the mode of the rule type
+	// +kubebuilder:validation:Enum=firstn;indep;""
+	// +optional
+	Mode string `json:"mode,omitempty"`
+
+	// Count is the number of buckets chosen by a choose or chooseleaf step. Zero chooses as many buckets
+	// as the size of the pool, and a negative count as many as the size of the pool minus the count.
+	// +optional
+	Count int `json:"count,omitempty"`
+
+	// Type is the type of the buckets chosen by a choose or chooseleaf step, such as host, rack or room
+	// +optional
+	Type string `json:"type,omitempty"`
+}
+
+// CrushRuleStatus represents the status of a crush rule
+type CrushRuleStatus struct {
+	// +optional
+	Phase ConditionType `json:"phase,omitempty"`
+	// RuleID is the id of the rule in the crush map
+	// +optional
+	RuleID *int `json:"ruleID,omitempty"`
+	// Message reports why the rule could not be applied to the crush map
+	// +optional
+	Message string `json:"message,omitempty"`
+	// +optional
+	Conditions []Condition `json:"conditions,omitempty"`
+}
+
 // NetworkSpec for Ceph includes backward compatibility code
 type NetworkSpec struct {
 	// Provider is what provides network connectivity to the cluster e.g. "host" or "multus"
@@ -1733,6 +2842,58 @@ type DisruptionManagementSpec struct {
 // +genclient:noStatus
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
 
+// CephBlockPoolRadosNamespace represents a RADOS namespace in a CephBlockPool to isolate the RBD
+// images of a tenant
+// +kubebuilder:printcolumn:name="Phase",type=string,JSONPath=`.status.phase`
+// +kubebuilder:printcolumn:name="BlockPool",type=string,JSONPath=`.spec.blockPoolName`
+// +kubebuilder:subresource:status
+type CephBlockPoolRadosNamespace struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata"`
+	// Spec represents the specification of a Ceph BlockPool Rados Namespace
+	Spec CephBlockPoolRadosNamespaceSpec `json:"spec"`
+	// Status represents the status of a CephBlockPool Rados Namespace
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// +optional
+	Status *CephBlockPoolRadosNamespaceStatus `json:"status,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// CephBlockPoolRadosNamespaceList represents a list of Ceph BlockPool Rados Namespace
+type CephBlockPoolRadosNamespaceList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+	Items           []CephBlockPoolRadosNamespace `json:"items"`
+}
+
+// CephBlockPoolRadosNamespaceSpec represents the specification of a CephBlockPool Rados Namespace
+type CephBlockPoolRadosNamespaceSpec struct {
+	// BlockPoolName is the name of the CephBlockPool CR where the namespace is created
+	// +kubebuilder:validation:MinLength=1
+	BlockPoolName string `json:"blockPoolName"`
+	// CreateClient creates a CephClient with the same name as the rados namespace, whose caps only
+	// allow access to the RBD images in the rados namespace
+	// +optional
+	CreateClient bool `json:"createClient,omitempty"`
+}
+
+// CephBlockPoolRadosNamespaceStatus represents the Status of Ceph BlockPool Rados Namespace
+type CephBlockPoolRadosNamespaceStatus struct {
+	// +optional
+	Phase ConditionType `json:"phase,omitempty"`
+	// Info contains the clusterID to use in the CSI storage classes of the rados namespace
+	// +optional
+	// +nullable
+	Info map[string]string `json:"info,omitempty"`
+	// +optional
+	Conditions []Condition `json:"conditions,omitempty"`
+}
+
+// +genclient
+// +genclient:noStatus
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
 // CephClient represents a Ceph Client
 // +kubebuilder:subresource:status
 type CephClient struct {
@@ -1761,6 +2922,55 @@ type ClientSpec struct {
 	Name string `json:"name,omitempty"`
 	// +kubebuilder:pruning:PreserveUnknownFields
 	Caps map[string]string `json:"caps"`
+	// SecretFormat is the format of the key in the Secret of the client in the namespace of the CephClient
+	// +kubebuilder:validation:Enum="";key;keyring;config
+	// +optional
+	SecretFormat ClientSecretFormat `json:"secretFormat,omitempty"`
+	// TargetSecrets are the Secrets with the key of the client to create in other namespaces
+	// +optional
+	TargetSecrets []ClientTargetSecret `json:"targetSecrets,omitempty"`
+	// KeyRotation is the rotation of the key of the client
+	// +optional
+	// +nullable
+	KeyRotation *ClientKeyRotationSpec `json:"keyRotation,omitempty"`
+}
+
+// ClientSecretFormat is the format of the key of a Ceph Client in a Secret
+type ClientSecretFormat string
+
+const (
+	// ClientSecretFormatKey stores the bare key under the name of the client
+	ClientSecretFormatKey ClientSecretFormat = "key"
+	// ClientSecretFormatKeyring stores a keyring file under "keyring"
+	ClientSecretFormatKeyring ClientSecretFormat = "keyring"
+	// ClientSecretFormatConfig stores a keyring file under "keyring" and the ceph.conf to connect to the
+	// cluster under "ceph.conf"
+	ClientSecretFormatConfig ClientSecretFormat = "config"
+)
+
+// ClientTargetSecret represents a Secret with the key of a Ceph Client in another namespace
+type ClientTargetSecret struct {
+	// Namespace is the namespace of the Secret
+	Namespace string `json:"namespace"`
+	// Name is the name of the Secret, "rook-ceph-client-<client name>" by default
+	// +optional
+	Name string `json:"name,omitempty"`
+	// Format is the format of the key in the Secret, the secretFormat of the client by default
+	// +kubebuilder:validation:Enum="";key;keyring;config
+	// +optional
+	Format ClientSecretFormat `json:"format,omitempty"`
+}
+
+// ClientKeyRotationSpec represents the rotation of the key of a Ceph Client, experimental and requires Ceph Quincy
+type ClientKeyRotationSpec struct {
+	// Period is the duration after which the key is rotated, the key is only rotated on demand when it is not set
+	// +optional
+	// +nullable
+	Period *metav1.Duration `json:"period,omitempty"`
+	// GracePeriod is how long the pending key is in the Secrets next to the current key before it is committed, 1h by default
+	// +optional
+	// +nullable
+	GracePeriod *metav1.Duration `json:"gracePeriod,omitempty"`
 }
 
 // CephClientStatus represents the Status of Ceph Client
@@ -1770,6 +2980,29 @@ type CephClientStatus struct {
 	// +optional
 	// +nullable
 	Info map[string]string `json:"info,omitempty"`
+	// TargetSecrets are the Secrets with the key of the client in other namespaces, as "namespace/name"
+	// +optional
+	TargetSecrets []string `json:"targetSecrets,omitempty"`
+	// KeyRotation is the status of the rotation of the key of the client
+	// +optional
+	// +nullable
+	KeyRotation *ClientKeyRotationStatus `json:"keyRotation,omitempty"`
+}
+
+// ClientKeyRotationStatus represents the status of the rotation of the key of a Ceph Client
+type ClientKeyRotationStatus struct {
+	// LastRotationTime is when the previous key was last replaced by a new key
+	// +optional
+	// +nullable
+	LastRotationTime *metav1.Time `json:"lastRotationTime,omitempty"`
+	// PendingSince is when the new key was stored in the Secrets, the previous key is replaced once the grace
+	// period elapsed
+	// +optional
+	// +nullable
+	PendingSince *metav1.Time `json:"pendingSince,omitempty"`
+	// Request is the value of the rotation annotation of the last rotation requested on demand
+	// +optional
+	Request string `json:"request,omitempty"`
 }
 
 // CleanupPolicySpec represents a Ceph Cluster cleanup policy
@@ -1886,6 +3119,92 @@ type MirroringPeerSpec struct {
 // +genclient:noStatus
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
 
+// CephFilesystemSubVolumeGroup represents a Ceph Filesystem SubVolumeGroup
+// +kubebuilder:printcolumn:name="Phase",type=string,JSONPath=`.status.phase`
+// +kubebuilder:printcolumn:name="Filesystem",type=string,JSONPath=`.spec.filesystemName`
+// +kubebuilder:subresource:status
+type CephFilesystemSubVolumeGroup struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata"`
+	// Spec represents the specification of a Ceph Filesystem SubVolumeGroup
+	Spec CephFilesystemSubVolumeGroupSpec `json:"spec"`
+	// Status represents the status of a CephFilesystem SubvolumeGroup
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// +optional
+	Status *CephFilesystemSubVolumeGroupStatus `json:"status,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// CephFilesystemSubVolumeGroupList represents a list of Ceph Filesystem SubVolumeGroups
+type CephFilesystemSubVolumeGroupList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+	Items           []CephFilesystemSubVolumeGroup `json:"items"`
+}
+
+// CephFilesystemSubVolumeGroupSpec represents the specification of a Ceph Filesystem SubVolumeGroup
+type CephFilesystemSubVolumeGroupSpec struct {
+	// FilesystemName is the name of the CephFilesystem CR where the subvolume group is created
+	// +kubebuilder:validation:MinLength=1
+	FilesystemName string `json:"filesystemName"`
+	// Quota is the maximum size of the subvolume group. Requires Ceph Quincy or newer.
+	// +optional
+	Quota *resource.Quantity `json:"quota,omitempty"`
+	// DataPoolName is the name of the data pool of the filesystem used for the layout of the
+	// subvolume group
+	// +optional
+	DataPoolName string `json:"dataPoolName,omitempty"`
+	// Pinning is the MDS pinning policy of the subvolume group. Only one policy can be set.
+	// The pinning of the subvolume group is not changed if no policy is set. Requires Ceph Pacific
+	// or newer.
+	// +optional
+	Pinning CephFilesystemSubVolumeGroupSpecPinning `json:"pinning,omitempty"`
+}
+
+// CephFilesystemSubVolumeGroupSpecPinning represents the pinning policy of a subvolume group
+// See https://docs.ceph.com/en/latest/cephfs/fs-volumes/#pinning-subvolumes-and-subvolume-groups
+type CephFilesystemSubVolumeGroupSpecPinning struct {
+	// Export pins the subvolume group to an MDS rank, or -1 to unpin it
+	// +kubebuilder:validation:Minimum=-1
+	// +kubebuilder:validation:Maximum=256
+	// +optional
+	// +nullable
+	Export *int `json:"export,omitempty"`
+	// Distributed distributes the subvolumes of the group across the active MDS ranks if 1, or
+	// disables the distribution if 0
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=1
+	// +optional
+	// +nullable
+	Distributed *int `json:"distributed,omitempty"`
+	// Random pins the directories of the subvolume group to random MDS ranks with the given
+	// probability, between 0 and 1
+	// +optional
+	// +nullable
+	Random *float64 `json:"random,omitempty"`
+}
+
+// CephFilesystemSubVolumeGroupStatus represents the Status of Ceph Filesystem SubVolumeGroup
+type CephFilesystemSubVolumeGroupStatus struct {
+	// +optional
+	Phase ConditionType `json:"phase,omitempty"`
+	// Info contains the clusterID to use in the CSI storage classes of the subvolume group
+	// +optional
+	// +nullable
+	Info map[string]string `json:"info,omitempty"`
+	// Quota is the last quota applied to the subvolume group in bytes, empty when the subvolume
+	// group has no quota
+	// +optional
+	Quota string `json:"quota,omitempty"`
+	// +optional
+	Conditions []Condition `json:"conditions,omitempty"`
+}
+
+// +genclient
+// +genclient:noStatus
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
 // CephFilesystemMirror is the Ceph Filesystem Mirror object definition
 // +kubebuilder:subresource:status
 type CephFilesystemMirror struct {
@@ -1958,6 +3277,23 @@ type StorageScopeSpec struct {
 	// +nullable
 	// +optional
 	StorageClassDeviceSets []StorageClassDeviceSet `json:"storageClassDeviceSets,omitempty"`
+	// CrushTopology maps node labels to the bucket types of the crush hierarchy of the OSDs, in addition
+	// to the topology labels recognized by Rook
+	// +nullable
+	// +optional
+	CrushTopology []CrushTopologyLabel `json:"crushTopology,omitempty"`
+}
+
+// CrushTopologyLabel maps a node label to a bucket type of the crush hierarchy
+type CrushTopologyLabel struct {
+	// Label is the key of the node label whose value is the name of the bucket of the node
+	Label string `json:"label"`
+	// Type is the type of the bucket, a type of the crush map such as rack, or a custom type that is
+	// added to the crush map
+	Type string `json:"type"`
+	// Above is the type right below a custom type in the crush hierarchy, host by default
+	// +optional
+	Above string `json:"above,omitempty"`
 }
 
 // Node is a storage nodes
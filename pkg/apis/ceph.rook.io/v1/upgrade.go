@@ -0,0 +1,47 @@
+/*
+Copyright 2021 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import "time"
+
+// PausesAfter returns true if the upgrade must pause once the given daemon type is upgraded
+func (s *UpgradeStrategySpec) PausesAfter(daemonType string) bool {
+	for _, d := range s.PauseAfter {
+		if string(d) == daemonType {
+			return true
+		}
+	}
+	return false
+}
+
+// HasCanary returns true if canary OSDs must be upgraded before the rest of the OSDs
+func (s *UpgradeStrategySpec) HasCanary() bool {
+	return s.Canary != nil && s.Canary.OSDCount > 0
+}
+
+// GetSoakTime returns the time the canary OSDs must run before the rest of the OSDs are upgraded
+func (c *UpgradeCanarySpec) GetSoakTime() time.Duration {
+	if c.SoakTime == nil {
+		return 0
+	}
+	return c.SoakTime.Duration
+}
+
+// IsInProgress returns true if an upgrade was started and is not completed yet
+func (s *UpgradeStatus) IsInProgress() bool {
+	return s != nil && s.Phase != "" && s.Phase != UpgradePhaseCompleted
+}
@@ -0,0 +1,61 @@
+/*
+Copyright 2021 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestUpgradeStrategySpec(t *testing.T) {
+	s := UpgradeStrategySpec{}
+	assert.False(t, s.PausesAfter("mon"))
+	assert.False(t, s.HasCanary())
+
+	s.PauseAfter = []UpgradeDaemonType{"mon", "osd"}
+	assert.True(t, s.PausesAfter("mon"))
+	assert.False(t, s.PausesAfter("mgr"))
+	assert.True(t, s.PausesAfter("osd"))
+
+	s.Canary = &UpgradeCanarySpec{}
+	assert.False(t, s.HasCanary())
+	assert.Equal(t, time.Duration(0), s.Canary.GetSoakTime())
+
+	s.Canary = &UpgradeCanarySpec{OSDCount: 2, SoakTime: &metav1.Duration{Duration: time.Hour}}
+	assert.True(t, s.HasCanary())
+	assert.Equal(t, time.Hour, s.Canary.GetSoakTime())
+}
+
+func TestUpgradeStatusIsInProgress(t *testing.T) {
+	var s *UpgradeStatus
+	assert.False(t, s.IsInProgress())
+
+	s = &UpgradeStatus{}
+	assert.False(t, s.IsInProgress())
+
+	s.Phase = UpgradePhaseProgressing
+	assert.True(t, s.IsInProgress())
+
+	s.Phase = UpgradePhasePaused
+	assert.True(t, s.IsInProgress())
+
+	s.Phase = UpgradePhaseCompleted
+	assert.False(t, s.IsInProgress())
+}
@@ -89,6 +89,7 @@ func (in *BucketHealthCheckSpec) DeepCopyInto(out *BucketHealthCheckSpec) {
 		*out = new(ProbeSpec)
 		(*in).DeepCopyInto(*out)
 	}
+	in.Usage.DeepCopyInto(&out.Usage)
 	return
 }
 
@@ -118,6 +119,61 @@ func (in *BucketStatus) DeepCopy() *BucketStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CSIDriverSpec) DeepCopyInto(out *CSIDriverSpec) {
+	*out = *in
+	if in.ForceCephFSKernelClient != nil {
+		in, out := &in.ForceCephFSKernelClient, &out.ForceCephFSKernelClient
+		*out = new(bool)
+		**out = **in
+	}
+	if in.EnableHostNetwork != nil {
+		in, out := &in.EnableHostNetwork, &out.EnableHostNetwork
+		*out = new(bool)
+		**out = **in
+	}
+	if in.ProvisionerReplicas != nil {
+		in, out := &in.ProvisionerReplicas, &out.ProvisionerReplicas
+		*out = new(int32)
+		**out = **in
+	}
+	if in.EncryptionKMS != nil {
+		in, out := &in.EncryptionKMS, &out.EncryptionKMS
+		*out = make([]CSIEncryptionKMSSpec, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CSIDriverSpec.
+func (in *CSIDriverSpec) DeepCopy() *CSIDriverSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(CSIDriverSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CSIEncryptionKMSSpec) DeepCopyInto(out *CSIEncryptionKMSSpec) {
+	*out = *in
+	in.KeyManagementServiceSpec.DeepCopyInto(&out.KeyManagementServiceSpec)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CSIEncryptionKMSSpec.
+func (in *CSIEncryptionKMSSpec) DeepCopy() *CSIEncryptionKMSSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(CSIEncryptionKMSSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Capacity) DeepCopyInto(out *Capacity) {
 	*out = *in
@@ -199,6 +255,117 @@ func (in *CephBlockPoolList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CephBlockPoolRadosNamespace) DeepCopyInto(out *CephBlockPoolRadosNamespace) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	out.Spec = in.Spec
+	if in.Status != nil {
+		in, out := &in.Status, &out.Status
+		*out = new(CephBlockPoolRadosNamespaceStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CephBlockPoolRadosNamespace.
+func (in *CephBlockPoolRadosNamespace) DeepCopy() *CephBlockPoolRadosNamespace {
+	if in == nil {
+		return nil
+	}
+	out := new(CephBlockPoolRadosNamespace)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *CephBlockPoolRadosNamespace) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CephBlockPoolRadosNamespaceList) DeepCopyInto(out *CephBlockPoolRadosNamespaceList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]CephBlockPoolRadosNamespace, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CephBlockPoolRadosNamespaceList.
+func (in *CephBlockPoolRadosNamespaceList) DeepCopy() *CephBlockPoolRadosNamespaceList {
+	if in == nil {
+		return nil
+	}
+	out := new(CephBlockPoolRadosNamespaceList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *CephBlockPoolRadosNamespaceList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CephBlockPoolRadosNamespaceSpec) DeepCopyInto(out *CephBlockPoolRadosNamespaceSpec) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CephBlockPoolRadosNamespaceSpec.
+func (in *CephBlockPoolRadosNamespaceSpec) DeepCopy() *CephBlockPoolRadosNamespaceSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(CephBlockPoolRadosNamespaceSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CephBlockPoolRadosNamespaceStatus) DeepCopyInto(out *CephBlockPoolRadosNamespaceStatus) {
+	*out = *in
+	if in.Info != nil {
+		in, out := &in.Info, &out.Info
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CephBlockPoolRadosNamespaceStatus.
+func (in *CephBlockPoolRadosNamespaceStatus) DeepCopy() *CephBlockPoolRadosNamespaceStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(CephBlockPoolRadosNamespaceStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CephBlockPoolStatus) DeepCopyInto(out *CephBlockPoolStatus) {
 	*out = *in
@@ -224,6 +391,23 @@ func (in *CephBlockPoolStatus) DeepCopyInto(out *CephBlockPoolStatus) {
 			(*out)[key] = val
 		}
 	}
+	if in.Migration != nil {
+		in, out := &in.Migration, &out.Migration
+		*out = new(PoolMigrationStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Usage != nil {
+		in, out := &in.Usage, &out.Usage
+		*out = new(PoolUsageStatus)
+		**out = **in
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	return
 }
 
@@ -312,6 +496,16 @@ func (in *CephClientStatus) DeepCopyInto(out *CephClientStatus) {
 			(*out)[key] = val
 		}
 	}
+	if in.TargetSecrets != nil {
+		in, out := &in.TargetSecrets, &out.TargetSecrets
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.KeyRotation != nil {
+		in, out := &in.KeyRotation, &out.KeyRotation
+		*out = new(ClientKeyRotationStatus)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -372,6 +566,20 @@ func (in *CephClusterHealthCheckSpec) DeepCopyInto(out *CephClusterHealthCheckSp
 			(*out)[key] = outVal
 		}
 	}
+	if in.Mute != nil {
+		in, out := &in.Mute, &out.Mute
+		*out = make([]CephHealthMuteSpec, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Checks != nil {
+		in, out := &in.Checks, &out.Checks
+		*out = make(map[string]CephHealthCheckPolicySpec, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
 	return
 }
 
@@ -418,6 +626,71 @@ func (in *CephClusterList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CephCrushRule) DeepCopyInto(out *CephCrushRule) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	if in.Status != nil {
+		in, out := &in.Status, &out.Status
+		*out = new(CrushRuleStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CephCrushRule.
+func (in *CephCrushRule) DeepCopy() *CephCrushRule {
+	if in == nil {
+		return nil
+	}
+	out := new(CephCrushRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *CephCrushRule) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CephCrushRuleList) DeepCopyInto(out *CephCrushRuleList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]CephCrushRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CephCrushRuleList.
+func (in *CephCrushRuleList) DeepCopy() *CephCrushRuleList {
+	if in == nil {
+		return nil
+	}
+	out := new(CephCrushRuleList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *CephCrushRuleList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CephDaemonsVersions) DeepCopyInto(out *CephDaemonsVersions) {
 	*out = *in
@@ -640,6 +913,11 @@ func (in *CephFilesystemStatus) DeepCopyInto(out *CephFilesystemStatus) {
 		*out = new(FilesystemMirroringInfoSpec)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.PoolUsage != nil {
+		in, out := &in.PoolUsage, &out.PoolUsage
+		*out = make([]PoolUsageStatus, len(*in))
+		copy(*out, *in)
+	}
 	return
 }
 
@@ -654,47 +932,31 @@ func (in *CephFilesystemStatus) DeepCopy() *CephFilesystemStatus {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *CephHealthMessage) DeepCopyInto(out *CephHealthMessage) {
-	*out = *in
-	return
-}
-
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CephHealthMessage.
-func (in *CephHealthMessage) DeepCopy() *CephHealthMessage {
-	if in == nil {
-		return nil
-	}
-	out := new(CephHealthMessage)
-	in.DeepCopyInto(out)
-	return out
-}
-
-// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *CephNFS) DeepCopyInto(out *CephNFS) {
+func (in *CephFilesystemSubVolumeGroup) DeepCopyInto(out *CephFilesystemSubVolumeGroup) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
 	if in.Status != nil {
 		in, out := &in.Status, &out.Status
-		*out = new(Status)
-		**out = **in
+		*out = new(CephFilesystemSubVolumeGroupStatus)
+		(*in).DeepCopyInto(*out)
 	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CephNFS.
-func (in *CephNFS) DeepCopy() *CephNFS {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CephFilesystemSubVolumeGroup.
+func (in *CephFilesystemSubVolumeGroup) DeepCopy() *CephFilesystemSubVolumeGroup {
 	if in == nil {
 		return nil
 	}
-	out := new(CephNFS)
+	out := new(CephFilesystemSubVolumeGroup)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *CephNFS) DeepCopyObject() runtime.Object {
+func (in *CephFilesystemSubVolumeGroup) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -702,13 +964,13 @@ func (in *CephNFS) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *CephNFSList) DeepCopyInto(out *CephNFSList) {
+func (in *CephFilesystemSubVolumeGroupList) DeepCopyInto(out *CephFilesystemSubVolumeGroupList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]CephNFS, len(*in))
+		*out = make([]CephFilesystemSubVolumeGroup, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
@@ -716,18 +978,18 @@ func (in *CephNFSList) DeepCopyInto(out *CephNFSList) {
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CephNFSList.
-func (in *CephNFSList) DeepCopy() *CephNFSList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CephFilesystemSubVolumeGroupList.
+func (in *CephFilesystemSubVolumeGroupList) DeepCopy() *CephFilesystemSubVolumeGroupList {
 	if in == nil {
 		return nil
 	}
-	out := new(CephNFSList)
+	out := new(CephFilesystemSubVolumeGroupList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *CephNFSList) DeepCopyObject() runtime.Object {
+func (in *CephFilesystemSubVolumeGroupList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -735,36 +997,383 @@ func (in *CephNFSList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *CephObjectRealm) DeepCopyInto(out *CephObjectRealm) {
+func (in *CephFilesystemSubVolumeGroupSpec) DeepCopyInto(out *CephFilesystemSubVolumeGroupSpec) {
 	*out = *in
-	out.TypeMeta = in.TypeMeta
-	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	out.Spec = in.Spec
-	if in.Status != nil {
-		in, out := &in.Status, &out.Status
-		*out = new(Status)
-		**out = **in
+	if in.Quota != nil {
+		in, out := &in.Quota, &out.Quota
+		x := (*in).DeepCopy()
+		*out = &x
 	}
+	in.Pinning.DeepCopyInto(&out.Pinning)
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CephObjectRealm.
-func (in *CephObjectRealm) DeepCopy() *CephObjectRealm {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CephFilesystemSubVolumeGroupSpec.
+func (in *CephFilesystemSubVolumeGroupSpec) DeepCopy() *CephFilesystemSubVolumeGroupSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(CephObjectRealm)
+	out := new(CephFilesystemSubVolumeGroupSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *CephObjectRealm) DeepCopyObject() runtime.Object {
-	if c := in.DeepCopy(); c != nil {
-		return c
-	}
-	return nil
-}
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CephFilesystemSubVolumeGroupSpecPinning) DeepCopyInto(out *CephFilesystemSubVolumeGroupSpecPinning) {
+	*out = *in
+	if in.Export != nil {
+		in, out := &in.Export, &out.Export
+		*out = new(int)
+		**out = **in
+	}
+	if in.Distributed != nil {
+		in, out := &in.Distributed, &out.Distributed
+		*out = new(int)
+		**out = **in
+	}
+	if in.Random != nil {
+		in, out := &in.Random, &out.Random
+		*out = new(float64)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CephFilesystemSubVolumeGroupSpecPinning.
+func (in *CephFilesystemSubVolumeGroupSpecPinning) DeepCopy() *CephFilesystemSubVolumeGroupSpecPinning {
+	if in == nil {
+		return nil
+	}
+	out := new(CephFilesystemSubVolumeGroupSpecPinning)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CephFilesystemSubVolumeGroupStatus) DeepCopyInto(out *CephFilesystemSubVolumeGroupStatus) {
+	*out = *in
+	if in.Info != nil {
+		in, out := &in.Info, &out.Info
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CephFilesystemSubVolumeGroupStatus.
+func (in *CephFilesystemSubVolumeGroupStatus) DeepCopy() *CephFilesystemSubVolumeGroupStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(CephFilesystemSubVolumeGroupStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CephHealthCheckPolicySpec) DeepCopyInto(out *CephHealthCheckPolicySpec) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CephHealthCheckPolicySpec.
+func (in *CephHealthCheckPolicySpec) DeepCopy() *CephHealthCheckPolicySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(CephHealthCheckPolicySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CephHealthMessage) DeepCopyInto(out *CephHealthMessage) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CephHealthMessage.
+func (in *CephHealthMessage) DeepCopy() *CephHealthMessage {
+	if in == nil {
+		return nil
+	}
+	out := new(CephHealthMessage)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CephHealthMuteSpec) DeepCopyInto(out *CephHealthMuteSpec) {
+	*out = *in
+	if in.TTL != nil {
+		in, out := &in.TTL, &out.TTL
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CephHealthMuteSpec.
+func (in *CephHealthMuteSpec) DeepCopy() *CephHealthMuteSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(CephHealthMuteSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CephImageHistory) DeepCopyInto(out *CephImageHistory) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CephImageHistory.
+func (in *CephImageHistory) DeepCopy() *CephImageHistory {
+	if in == nil {
+		return nil
+	}
+	out := new(CephImageHistory)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CephNFS) DeepCopyInto(out *CephNFS) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	if in.Status != nil {
+		in, out := &in.Status, &out.Status
+		*out = new(Status)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CephNFS.
+func (in *CephNFS) DeepCopy() *CephNFS {
+	if in == nil {
+		return nil
+	}
+	out := new(CephNFS)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *CephNFS) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CephNFSExport) DeepCopyInto(out *CephNFSExport) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	if in.Status != nil {
+		in, out := &in.Status, &out.Status
+		*out = new(NFSExportStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CephNFSExport.
+func (in *CephNFSExport) DeepCopy() *CephNFSExport {
+	if in == nil {
+		return nil
+	}
+	out := new(CephNFSExport)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *CephNFSExport) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CephNFSExportList) DeepCopyInto(out *CephNFSExportList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]CephNFSExport, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CephNFSExportList.
+func (in *CephNFSExportList) DeepCopy() *CephNFSExportList {
+	if in == nil {
+		return nil
+	}
+	out := new(CephNFSExportList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *CephNFSExportList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CephNFSList) DeepCopyInto(out *CephNFSList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]CephNFS, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CephNFSList.
+func (in *CephNFSList) DeepCopy() *CephNFSList {
+	if in == nil {
+		return nil
+	}
+	out := new(CephNFSList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *CephNFSList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CephNetworkFence) DeepCopyInto(out *CephNetworkFence) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	if in.Status != nil {
+		in, out := &in.Status, &out.Status
+		*out = new(NetworkFenceStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CephNetworkFence.
+func (in *CephNetworkFence) DeepCopy() *CephNetworkFence {
+	if in == nil {
+		return nil
+	}
+	out := new(CephNetworkFence)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *CephNetworkFence) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CephNetworkFenceList) DeepCopyInto(out *CephNetworkFenceList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]CephNetworkFence, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CephNetworkFenceList.
+func (in *CephNetworkFenceList) DeepCopy() *CephNetworkFenceList {
+	if in == nil {
+		return nil
+	}
+	out := new(CephNetworkFenceList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *CephNetworkFenceList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CephObjectRealm) DeepCopyInto(out *CephObjectRealm) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	out.Spec = in.Spec
+	if in.Status != nil {
+		in, out := &in.Status, &out.Status
+		*out = new(Status)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CephObjectRealm.
+func (in *CephObjectRealm) DeepCopy() *CephObjectRealm {
+	if in == nil {
+		return nil
+	}
+	out := new(CephObjectRealm)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *CephObjectRealm) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CephObjectRealmList) DeepCopyInto(out *CephObjectRealmList) {
@@ -1046,13 +1655,78 @@ func (in *CephObjectZoneList) DeepCopy() *CephObjectZoneList {
 	if in == nil {
 		return nil
 	}
-	out := new(CephObjectZoneList)
+	out := new(CephObjectZoneList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *CephObjectZoneList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CephRBDFailover) DeepCopyInto(out *CephRBDFailover) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	if in.Status != nil {
+		in, out := &in.Status, &out.Status
+		*out = new(RBDFailoverStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CephRBDFailover.
+func (in *CephRBDFailover) DeepCopy() *CephRBDFailover {
+	if in == nil {
+		return nil
+	}
+	out := new(CephRBDFailover)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *CephRBDFailover) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CephRBDFailoverList) DeepCopyInto(out *CephRBDFailoverList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]CephRBDFailover, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CephRBDFailoverList.
+func (in *CephRBDFailoverList) DeepCopy() *CephRBDFailoverList {
+	if in == nil {
+		return nil
+	}
+	out := new(CephRBDFailoverList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *CephObjectZoneList) DeepCopyObject() runtime.Object {
+func (in *CephRBDFailoverList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -1140,6 +1814,11 @@ func (in *CephStatus) DeepCopyInto(out *CephStatus) {
 		*out = new(CephDaemonsVersions)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Mgr != nil {
+		in, out := &in.Mgr, &out.Mgr
+		*out = new(MgrStatus)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -1207,6 +1886,56 @@ func (in *CleanupPolicySpec) DeepCopy() *CleanupPolicySpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClientKeyRotationSpec) DeepCopyInto(out *ClientKeyRotationSpec) {
+	*out = *in
+	if in.Period != nil {
+		in, out := &in.Period, &out.Period
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.GracePeriod != nil {
+		in, out := &in.GracePeriod, &out.GracePeriod
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClientKeyRotationSpec.
+func (in *ClientKeyRotationSpec) DeepCopy() *ClientKeyRotationSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ClientKeyRotationSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClientKeyRotationStatus) DeepCopyInto(out *ClientKeyRotationStatus) {
+	*out = *in
+	if in.LastRotationTime != nil {
+		in, out := &in.LastRotationTime, &out.LastRotationTime
+		*out = (*in).DeepCopy()
+	}
+	if in.PendingSince != nil {
+		in, out := &in.PendingSince, &out.PendingSince
+		*out = (*in).DeepCopy()
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClientKeyRotationStatus.
+func (in *ClientKeyRotationStatus) DeepCopy() *ClientKeyRotationStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ClientKeyRotationStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ClientSpec) DeepCopyInto(out *ClientSpec) {
 	*out = *in
@@ -1217,6 +1946,16 @@ func (in *ClientSpec) DeepCopyInto(out *ClientSpec) {
 			(*out)[key] = val
 		}
 	}
+	if in.TargetSecrets != nil {
+		in, out := &in.TargetSecrets, &out.TargetSecrets
+		*out = make([]ClientTargetSecret, len(*in))
+		copy(*out, *in)
+	}
+	if in.KeyRotation != nil {
+		in, out := &in.KeyRotation, &out.KeyRotation
+		*out = new(ClientKeyRotationSpec)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -1230,6 +1969,22 @@ func (in *ClientSpec) DeepCopy() *ClientSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClientTargetSecret) DeepCopyInto(out *ClientTargetSecret) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClientTargetSecret.
+func (in *ClientTargetSecret) DeepCopy() *ClientTargetSecret {
+	if in == nil {
+		return nil
+	}
+	out := new(ClientTargetSecret)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ClusterSpec) DeepCopyInto(out *ClusterSpec) {
 	*out = *in
@@ -1291,10 +2046,11 @@ func (in *ClusterSpec) DeepCopyInto(out *ClusterSpec) {
 			(*out)[key] = val
 		}
 	}
+	in.UpgradeStrategy.DeepCopyInto(&out.UpgradeStrategy)
 	out.DisruptionManagement = in.DisruptionManagement
 	in.Mon.DeepCopyInto(&out.Mon)
 	out.CrashCollector = in.CrashCollector
-	out.Dashboard = in.Dashboard
+	in.Dashboard.DeepCopyInto(&out.Dashboard)
 	in.Monitoring.DeepCopyInto(&out.Monitoring)
 	out.External = in.External
 	in.Mgr.DeepCopyInto(&out.Mgr)
@@ -1302,6 +2058,7 @@ func (in *ClusterSpec) DeepCopyInto(out *ClusterSpec) {
 	in.HealthCheck.DeepCopyInto(&out.HealthCheck)
 	in.Security.DeepCopyInto(&out.Security)
 	out.LogCollector = in.LogCollector
+	in.CSI.DeepCopyInto(&out.CSI)
 	return
 }
 
@@ -1340,6 +2097,16 @@ func (in *ClusterStatus) DeepCopyInto(out *ClusterStatus) {
 		*out = new(ClusterVersion)
 		**out = **in
 	}
+	if in.UpgradeStatus != nil {
+		in, out := &in.UpgradeStatus, &out.UpgradeStatus
+		*out = new(UpgradeStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ImageHistory != nil {
+		in, out := &in.ImageHistory, &out.ImageHistory
+		*out = make([]CephImageHistory, len(*in))
+		copy(*out, *in)
+	}
 	return
 }
 
@@ -1403,6 +2170,87 @@ func (in *CrashCollectorSpec) DeepCopy() *CrashCollectorSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CrushRuleSpec) DeepCopyInto(out *CrushRuleSpec) {
+	*out = *in
+	if in.Steps != nil {
+		in, out := &in.Steps, &out.Steps
+		*out = make([]CrushRuleStep, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CrushRuleSpec.
+func (in *CrushRuleSpec) DeepCopy() *CrushRuleSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(CrushRuleSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CrushRuleStatus) DeepCopyInto(out *CrushRuleStatus) {
+	*out = *in
+	if in.RuleID != nil {
+		in, out := &in.RuleID, &out.RuleID
+		*out = new(int)
+		**out = **in
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CrushRuleStatus.
+func (in *CrushRuleStatus) DeepCopy() *CrushRuleStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(CrushRuleStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CrushRuleStep) DeepCopyInto(out *CrushRuleStep) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CrushRuleStep.
+func (in *CrushRuleStep) DeepCopy() *CrushRuleStep {
+	if in == nil {
+		return nil
+	}
+	out := new(CrushRuleStep)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CrushTopologyLabel) DeepCopyInto(out *CrushTopologyLabel) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CrushTopologyLabel.
+func (in *CrushTopologyLabel) DeepCopy() *CrushTopologyLabel {
+	if in == nil {
+		return nil
+	}
+	out := new(CrushTopologyLabel)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DaemonHealthSpec) DeepCopyInto(out *DaemonHealthSpec) {
 	*out = *in
@@ -1422,9 +2270,132 @@ func (in *DaemonHealthSpec) DeepCopy() *DaemonHealthSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DaemonUpgradeStatus) DeepCopyInto(out *DaemonUpgradeStatus) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DaemonUpgradeStatus.
+func (in *DaemonUpgradeStatus) DeepCopy() *DaemonUpgradeStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(DaemonUpgradeStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in DashboardPermissions) DeepCopyInto(out *DashboardPermissions) {
+	{
+		in := &in
+		*out = make(DashboardPermissions, len(*in))
+		copy(*out, *in)
+		return
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DashboardPermissions.
+func (in DashboardPermissions) DeepCopy() DashboardPermissions {
+	if in == nil {
+		return nil
+	}
+	out := new(DashboardPermissions)
+	in.DeepCopyInto(out)
+	return *out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DashboardRoleSpec) DeepCopyInto(out *DashboardRoleSpec) {
+	*out = *in
+	if in.Scopes != nil {
+		in, out := &in.Scopes, &out.Scopes
+		*out = make(map[string]DashboardPermissions, len(*in))
+		for key, val := range *in {
+			var outVal []DashboardPermission
+			if val == nil {
+				(*out)[key] = nil
+			} else {
+				in, out := &val, &outVal
+				*out = make(DashboardPermissions, len(*in))
+				copy(*out, *in)
+			}
+			(*out)[key] = outVal
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DashboardRoleSpec.
+func (in *DashboardRoleSpec) DeepCopy() *DashboardRoleSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(DashboardRoleSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DashboardSAML2Spec) DeepCopyInto(out *DashboardSAML2Spec) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DashboardSAML2Spec.
+func (in *DashboardSAML2Spec) DeepCopy() *DashboardSAML2Spec {
+	if in == nil {
+		return nil
+	}
+	out := new(DashboardSAML2Spec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DashboardSSOSpec) DeepCopyInto(out *DashboardSSOSpec) {
+	*out = *in
+	if in.SAML2 != nil {
+		in, out := &in.SAML2, &out.SAML2
+		*out = new(DashboardSAML2Spec)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DashboardSSOSpec.
+func (in *DashboardSSOSpec) DeepCopy() *DashboardSSOSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(DashboardSSOSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DashboardSpec) DeepCopyInto(out *DashboardSpec) {
 	*out = *in
+	if in.Users != nil {
+		in, out := &in.Users, &out.Users
+		*out = make([]DashboardUserSpec, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Roles != nil {
+		in, out := &in.Roles, &out.Roles
+		*out = make([]DashboardRoleSpec, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.SSO != nil {
+		in, out := &in.SSO, &out.SSO
+		*out = new(DashboardSSOSpec)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -1438,6 +2409,27 @@ func (in *DashboardSpec) DeepCopy() *DashboardSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DashboardUserSpec) DeepCopyInto(out *DashboardUserSpec) {
+	*out = *in
+	if in.Roles != nil {
+		in, out := &in.Roles, &out.Roles
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DashboardUserSpec.
+func (in *DashboardUserSpec) DeepCopy() *DashboardUserSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(DashboardUserSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Device) DeepCopyInto(out *Device) {
 	*out = *in
@@ -1546,12 +2538,72 @@ func (in *FSMirroringSpec) DeepCopyInto(out *FSMirroringSpec) {
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FSMirroringSpec.
-func (in *FSMirroringSpec) DeepCopy() *FSMirroringSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FSMirroringSpec.
+func (in *FSMirroringSpec) DeepCopy() *FSMirroringSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(FSMirroringSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FSSnapshotIntervalSpec) DeepCopyInto(out *FSSnapshotIntervalSpec) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FSSnapshotIntervalSpec.
+func (in *FSSnapshotIntervalSpec) DeepCopy() *FSSnapshotIntervalSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(FSSnapshotIntervalSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FSSnapshotScheduleSpec) DeepCopyInto(out *FSSnapshotScheduleSpec) {
+	*out = *in
+	if in.Intervals != nil {
+		in, out := &in.Intervals, &out.Intervals
+		*out = make([]FSSnapshotIntervalSpec, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FSSnapshotScheduleSpec.
+func (in *FSSnapshotScheduleSpec) DeepCopy() *FSSnapshotScheduleSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(FSSnapshotScheduleSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FSSnapshotSchedulingSpec) DeepCopyInto(out *FSSnapshotSchedulingSpec) {
+	*out = *in
+	if in.Schedules != nil {
+		in, out := &in.Schedules, &out.Schedules
+		*out = make([]FSSnapshotScheduleSpec, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FSSnapshotSchedulingSpec.
+func (in *FSSnapshotSchedulingSpec) DeepCopy() *FSSnapshotSchedulingSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(FSMirroringSpec)
+	out := new(FSSnapshotSchedulingSpec)
 	in.DeepCopyInto(out)
 	return out
 }
@@ -1661,38 +2713,40 @@ func (in *FilesystemMirroringSpec) DeepCopy() *FilesystemMirroringSpec {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *FilesystemSnapshotScheduleStatusRetention) DeepCopyInto(out *FilesystemSnapshotScheduleStatusRetention) {
+func (in *FilesystemSnapshotScheduleStatusSpec) DeepCopyInto(out *FilesystemSnapshotScheduleStatusSpec) {
 	*out = *in
+	if in.SnapshotSchedules != nil {
+		in, out := &in.SnapshotSchedules, &out.SnapshotSchedules
+		*out = make([]FilesystemSnapshotSchedulesSpec, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FilesystemSnapshotScheduleStatusRetention.
-func (in *FilesystemSnapshotScheduleStatusRetention) DeepCopy() *FilesystemSnapshotScheduleStatusRetention {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FilesystemSnapshotScheduleStatusSpec.
+func (in *FilesystemSnapshotScheduleStatusSpec) DeepCopy() *FilesystemSnapshotScheduleStatusSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(FilesystemSnapshotScheduleStatusRetention)
+	out := new(FilesystemSnapshotScheduleStatusSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *FilesystemSnapshotScheduleStatusSpec) DeepCopyInto(out *FilesystemSnapshotScheduleStatusSpec) {
+func (in *FilesystemSnapshotScheduleStatusRetention) DeepCopyInto(out *FilesystemSnapshotScheduleStatusRetention) {
 	*out = *in
-	if in.SnapshotSchedules != nil {
-		in, out := &in.SnapshotSchedules, &out.SnapshotSchedules
-		*out = make([]FilesystemSnapshotSchedulesSpec, len(*in))
-		copy(*out, *in)
-	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FilesystemSnapshotScheduleStatusSpec.
-func (in *FilesystemSnapshotScheduleStatusSpec) DeepCopy() *FilesystemSnapshotScheduleStatusSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FilesystemSnapshotScheduleStatusRetention.
+func (in *FilesystemSnapshotScheduleStatusRetention) DeepCopy() *FilesystemSnapshotScheduleStatusRetention {
 	if in == nil {
 		return nil
 	}
-	out := new(FilesystemSnapshotScheduleStatusSpec)
+	out := new(FilesystemSnapshotScheduleStatusRetention)
 	in.DeepCopyInto(out)
 	return out
 }
@@ -1701,6 +2755,13 @@ func (in *FilesystemSnapshotScheduleStatusSpec) DeepCopy() *FilesystemSnapshotSc
 func (in *FilesystemSnapshotSchedulesSpec) DeepCopyInto(out *FilesystemSnapshotSchedulesSpec) {
 	*out = *in
 	out.Retention = in.Retention
+	if in.RetentionCounts != nil {
+		in, out := &in.RetentionCounts, &out.RetentionCounts
+		*out = make(map[string]int, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
 	return
 }
 
@@ -1731,6 +2792,11 @@ func (in *FilesystemSpec) DeepCopyInto(out *FilesystemSpec) {
 		*out = new(FSMirroringSpec)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.SnapshotScheduling != nil {
+		in, out := &in.SnapshotScheduling, &out.SnapshotScheduling
+		*out = new(FSSnapshotSchedulingSpec)
+		(*in).DeepCopyInto(*out)
+	}
 	in.StatusCheck.DeepCopyInto(&out.StatusCheck)
 	return
 }
@@ -2028,7 +3094,9 @@ func (in *MgrSpec) DeepCopyInto(out *MgrSpec) {
 	if in.Modules != nil {
 		in, out := &in.Modules, &out.Modules
 		*out = make([]Module, len(*in))
-		copy(*out, *in)
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
 	return
 }
@@ -2043,10 +3111,37 @@ func (in *MgrSpec) DeepCopy() *MgrSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MgrStatus) DeepCopyInto(out *MgrStatus) {
+	*out = *in
+	if in.Standbys != nil {
+		in, out := &in.Standbys, &out.Standbys
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Modules != nil {
+		in, out := &in.Modules, &out.Modules
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MgrStatus.
+func (in *MgrStatus) DeepCopy() *MgrStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(MgrStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *MirrorHealthCheckSpec) DeepCopyInto(out *MirrorHealthCheckSpec) {
 	*out = *in
 	in.Mirror.DeepCopyInto(&out.Mirror)
+	in.Usage.DeepCopyInto(&out.Usage)
 	return
 }
 
@@ -2118,112 +3213,363 @@ func (in *MirroringSpec) DeepCopyInto(out *MirroringSpec) {
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MirroringSpec.
-func (in *MirroringSpec) DeepCopy() *MirroringSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MirroringSpec.
+func (in *MirroringSpec) DeepCopy() *MirroringSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(MirroringSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MirroringStatusSpec) DeepCopyInto(out *MirroringStatusSpec) {
+	*out = *in
+	in.PoolMirroringStatus.DeepCopyInto(&out.PoolMirroringStatus)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MirroringStatusSpec.
+func (in *MirroringStatusSpec) DeepCopy() *MirroringStatusSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(MirroringStatusSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Module) DeepCopyInto(out *Module) {
+	*out = *in
+	if in.Settings != nil {
+		in, out := &in.Settings, &out.Settings
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Module.
+func (in *Module) DeepCopy() *Module {
+	if in == nil {
+		return nil
+	}
+	out := new(Module)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MonSpec) DeepCopyInto(out *MonSpec) {
+	*out = *in
+	if in.StretchCluster != nil {
+		in, out := &in.StretchCluster, &out.StretchCluster
+		*out = new(StretchClusterSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.VolumeClaimTemplate != nil {
+		in, out := &in.VolumeClaimTemplate, &out.VolumeClaimTemplate
+		*out = new(corev1.PersistentVolumeClaim)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MonSpec.
+func (in *MonSpec) DeepCopy() *MonSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(MonSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MonitoringSpec) DeepCopyInto(out *MonitoringSpec) {
+	*out = *in
+	if in.ExternalMgrEndpoints != nil {
+		in, out := &in.ExternalMgrEndpoints, &out.ExternalMgrEndpoints
+		*out = make([]corev1.EndpointAddress, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MonitoringSpec.
+func (in *MonitoringSpec) DeepCopy() *MonitoringSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(MonitoringSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NFSExportCephFSSpec) DeepCopyInto(out *NFSExportCephFSSpec) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NFSExportCephFSSpec.
+func (in *NFSExportCephFSSpec) DeepCopy() *NFSExportCephFSSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(NFSExportCephFSSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NFSExportRGWSpec) DeepCopyInto(out *NFSExportRGWSpec) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NFSExportRGWSpec.
+func (in *NFSExportRGWSpec) DeepCopy() *NFSExportRGWSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(NFSExportRGWSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NFSExportSpec) DeepCopyInto(out *NFSExportSpec) {
+	*out = *in
+	if in.CephFS != nil {
+		in, out := &in.CephFS, &out.CephFS
+		*out = new(NFSExportCephFSSpec)
+		**out = **in
+	}
+	if in.RGW != nil {
+		in, out := &in.RGW, &out.RGW
+		*out = new(NFSExportRGWSpec)
+		**out = **in
+	}
+	if in.AllowedClients != nil {
+		in, out := &in.AllowedClients, &out.AllowedClients
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.SecurityFlavors != nil {
+		in, out := &in.SecurityFlavors, &out.SecurityFlavors
+		*out = make([]NFSSecurityFlavor, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NFSExportSpec.
+func (in *NFSExportSpec) DeepCopy() *NFSExportSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(NFSExportSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NFSExportStatus) DeepCopyInto(out *NFSExportStatus) {
+	*out = *in
+	if in.ActiveServers != nil {
+		in, out := &in.ActiveServers, &out.ActiveServers
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NFSExportStatus.
+func (in *NFSExportStatus) DeepCopy() *NFSExportStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(NFSExportStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NFSGaneshaSpec) DeepCopyInto(out *NFSGaneshaSpec) {
+	*out = *in
+	out.RADOS = in.RADOS
+	in.Server.DeepCopyInto(&out.Server)
+	if in.Ingress != nil {
+		in, out := &in.Ingress, &out.Ingress
+		*out = new(NFSIngressSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Security != nil {
+		in, out := &in.Security, &out.Security
+		*out = new(NFSSecuritySpec)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NFSGaneshaSpec.
+func (in *NFSGaneshaSpec) DeepCopy() *NFSGaneshaSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(NFSGaneshaSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NFSIDMappingSpec) DeepCopyInto(out *NFSIDMappingSpec) {
+	*out = *in
+	if in.SSSD != nil {
+		in, out := &in.SSSD, &out.SSSD
+		*out = new(SSSDSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NFSIDMappingSpec.
+func (in *NFSIDMappingSpec) DeepCopy() *NFSIDMappingSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(MirroringSpec)
+	out := new(NFSIDMappingSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *MirroringStatusSpec) DeepCopyInto(out *MirroringStatusSpec) {
+func (in *NFSIngressSpec) DeepCopyInto(out *NFSIngressSpec) {
 	*out = *in
-	in.PoolMirroringStatus.DeepCopyInto(&out.PoolMirroringStatus)
+	if in.LoadBalancerIPs != nil {
+		in, out := &in.LoadBalancerIPs, &out.LoadBalancerIPs
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make(Annotations, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MirroringStatusSpec.
-func (in *MirroringStatusSpec) DeepCopy() *MirroringStatusSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NFSIngressSpec.
+func (in *NFSIngressSpec) DeepCopy() *NFSIngressSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(MirroringStatusSpec)
+	out := new(NFSIngressSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *Module) DeepCopyInto(out *Module) {
+func (in *NFSKerberosSpec) DeepCopyInto(out *NFSKerberosSpec) {
 	*out = *in
+	if in.SecurityFlavors != nil {
+		in, out := &in.SecurityFlavors, &out.SecurityFlavors
+		*out = make([]NFSSecurityFlavor, len(*in))
+		copy(*out, *in)
+	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Module.
-func (in *Module) DeepCopy() *Module {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NFSKerberosSpec.
+func (in *NFSKerberosSpec) DeepCopy() *NFSKerberosSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(Module)
+	out := new(NFSKerberosSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *MonSpec) DeepCopyInto(out *MonSpec) {
+func (in *NFSSecuritySpec) DeepCopyInto(out *NFSSecuritySpec) {
 	*out = *in
-	if in.StretchCluster != nil {
-		in, out := &in.StretchCluster, &out.StretchCluster
-		*out = new(StretchClusterSpec)
+	if in.Kerberos != nil {
+		in, out := &in.Kerberos, &out.Kerberos
+		*out = new(NFSKerberosSpec)
 		(*in).DeepCopyInto(*out)
 	}
-	if in.VolumeClaimTemplate != nil {
-		in, out := &in.VolumeClaimTemplate, &out.VolumeClaimTemplate
-		*out = new(corev1.PersistentVolumeClaim)
+	if in.IDMapping != nil {
+		in, out := &in.IDMapping, &out.IDMapping
+		*out = new(NFSIDMappingSpec)
 		(*in).DeepCopyInto(*out)
 	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MonSpec.
-func (in *MonSpec) DeepCopy() *MonSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NFSSecuritySpec.
+func (in *NFSSecuritySpec) DeepCopy() *NFSSecuritySpec {
 	if in == nil {
 		return nil
 	}
-	out := new(MonSpec)
+	out := new(NFSSecuritySpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *MonitoringSpec) DeepCopyInto(out *MonitoringSpec) {
+func (in *NetworkFenceSpec) DeepCopyInto(out *NetworkFenceSpec) {
 	*out = *in
-	if in.ExternalMgrEndpoints != nil {
-		in, out := &in.ExternalMgrEndpoints, &out.ExternalMgrEndpoints
-		*out = make([]corev1.EndpointAddress, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
+	if in.CIDRs != nil {
+		in, out := &in.CIDRs, &out.CIDRs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
 	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MonitoringSpec.
-func (in *MonitoringSpec) DeepCopy() *MonitoringSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NetworkFenceSpec.
+func (in *NetworkFenceSpec) DeepCopy() *NetworkFenceSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(MonitoringSpec)
+	out := new(NetworkFenceSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *NFSGaneshaSpec) DeepCopyInto(out *NFSGaneshaSpec) {
+func (in *NetworkFenceStatus) DeepCopyInto(out *NetworkFenceStatus) {
 	*out = *in
-	out.RADOS = in.RADOS
-	in.Server.DeepCopyInto(&out.Server)
+	if in.FencedAddresses != nil {
+		in, out := &in.FencedAddresses, &out.FencedAddresses
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.NodeAddresses != nil {
+		in, out := &in.NodeAddresses, &out.NodeAddresses
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NFSGaneshaSpec.
-func (in *NFSGaneshaSpec) DeepCopy() *NFSGaneshaSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NetworkFenceStatus.
+func (in *NetworkFenceStatus) DeepCopy() *NetworkFenceStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(NFSGaneshaSpec)
+	out := new(NetworkFenceStatus)
 	in.DeepCopyInto(out)
 	return out
 }
@@ -2363,6 +3709,11 @@ func (in *ObjectStoreStatus) DeepCopyInto(out *ObjectStoreStatus) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.PoolUsage != nil {
+		in, out := &in.PoolUsage, &out.PoolUsage
+		*out = make([]PoolUsageStatus, len(*in))
+		copy(*out, *in)
+	}
 	return
 }
 
@@ -2599,6 +3950,27 @@ func (in *Placement) DeepCopy() *Placement {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PlacementGroupSpec) DeepCopyInto(out *PlacementGroupSpec) {
+	*out = *in
+	if in.Bulk != nil {
+		in, out := &in.Bulk, &out.Bulk
+		*out = new(bool)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PlacementGroupSpec.
+func (in *PlacementGroupSpec) DeepCopy() *PlacementGroupSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PlacementGroupSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in PlacementSpec) DeepCopyInto(out *PlacementSpec) {
 	{
@@ -2621,6 +3993,66 @@ func (in PlacementSpec) DeepCopy() PlacementSpec {
 	return *out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PoolMigrationImageStatus) DeepCopyInto(out *PoolMigrationImageStatus) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PoolMigrationImageStatus.
+func (in *PoolMigrationImageStatus) DeepCopy() *PoolMigrationImageStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(PoolMigrationImageStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PoolMigrationSpec) DeepCopyInto(out *PoolMigrationSpec) {
+	*out = *in
+	in.Replicated.DeepCopyInto(&out.Replicated)
+	out.ErasureCoded = in.ErasureCoded
+	if in.ScaleDownImages != nil {
+		in, out := &in.ScaleDownImages, &out.ScaleDownImages
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PoolMigrationSpec.
+func (in *PoolMigrationSpec) DeepCopy() *PoolMigrationSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PoolMigrationSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PoolMigrationStatus) DeepCopyInto(out *PoolMigrationStatus) {
+	*out = *in
+	if in.Images != nil {
+		in, out := &in.Images, &out.Images
+		*out = make([]PoolMigrationImageStatus, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PoolMigrationStatus.
+func (in *PoolMigrationStatus) DeepCopy() *PoolMigrationStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(PoolMigrationStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PoolMirroringInfo) DeepCopyInto(out *PoolMirroringInfo) {
 	*out = *in
@@ -2695,6 +4127,16 @@ func (in *PoolSpec) DeepCopyInto(out *PoolSpec) {
 	in.Mirroring.DeepCopyInto(&out.Mirroring)
 	in.StatusCheck.DeepCopyInto(&out.StatusCheck)
 	in.Quotas.DeepCopyInto(&out.Quotas)
+	if in.PlacementGroups != nil {
+		in, out := &in.PlacementGroups, &out.PlacementGroups
+		*out = new(PlacementGroupSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Migration != nil {
+		in, out := &in.Migration, &out.Migration
+		*out = new(PoolMigrationSpec)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -2708,6 +4150,43 @@ func (in *PoolSpec) DeepCopy() *PoolSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PoolUsageCheckSpec) DeepCopyInto(out *PoolUsageCheckSpec) {
+	*out = *in
+	if in.Interval != nil {
+		in, out := &in.Interval, &out.Interval
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PoolUsageCheckSpec.
+func (in *PoolUsageCheckSpec) DeepCopy() *PoolUsageCheckSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PoolUsageCheckSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PoolUsageStatus) DeepCopyInto(out *PoolUsageStatus) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PoolUsageStatus.
+func (in *PoolUsageStatus) DeepCopy() *PoolUsageStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(PoolUsageStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in PriorityClassNamesSpec) DeepCopyInto(out *PriorityClassNamesSpec) {
 	{
@@ -2798,6 +4277,69 @@ func (in *QuotaSpec) DeepCopy() *QuotaSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RBDFailoverImageStatus) DeepCopyInto(out *RBDFailoverImageStatus) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RBDFailoverImageStatus.
+func (in *RBDFailoverImageStatus) DeepCopy() *RBDFailoverImageStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(RBDFailoverImageStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RBDFailoverSpec) DeepCopyInto(out *RBDFailoverSpec) {
+	*out = *in
+	if in.Images != nil {
+		in, out := &in.Images, &out.Images
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.PVCSelector != nil {
+		in, out := &in.PVCSelector, &out.PVCSelector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RBDFailoverSpec.
+func (in *RBDFailoverSpec) DeepCopy() *RBDFailoverSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(RBDFailoverSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RBDFailoverStatus) DeepCopyInto(out *RBDFailoverStatus) {
+	*out = *in
+	if in.Images != nil {
+		in, out := &in.Images, &out.Images
+		*out = make([]RBDFailoverImageStatus, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RBDFailoverStatus.
+func (in *RBDFailoverStatus) DeepCopy() *RBDFailoverStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(RBDFailoverStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RBDMirroringSpec) DeepCopyInto(out *RBDMirroringSpec) {
 	*out = *in
@@ -2897,6 +4439,23 @@ func (in ResourceSpec) DeepCopy() ResourceSpec {
 	return *out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SSSDSpec) DeepCopyInto(out *SSSDSpec) {
+	*out = *in
+	in.Resources.DeepCopyInto(&out.Resources)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SSSDSpec.
+func (in *SSSDSpec) DeepCopy() *SSSDSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(SSSDSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SanitizeDisksSpec) DeepCopyInto(out *SanitizeDisksSpec) {
 	*out = *in
@@ -3151,6 +4710,11 @@ func (in *StorageScopeSpec) DeepCopyInto(out *StorageScopeSpec) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.CrushTopology != nil {
+		in, out := &in.CrushTopology, &out.CrushTopology
+		*out = make([]CrushTopologyLabel, len(*in))
+		copy(*out, *in)
+	}
 	return
 }
 
@@ -3208,6 +4772,74 @@ func (in *StretchClusterZoneSpec) DeepCopy() *StretchClusterZoneSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *UpgradeCanarySpec) DeepCopyInto(out *UpgradeCanarySpec) {
+	*out = *in
+	if in.SoakTime != nil {
+		in, out := &in.SoakTime, &out.SoakTime
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UpgradeCanarySpec.
+func (in *UpgradeCanarySpec) DeepCopy() *UpgradeCanarySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(UpgradeCanarySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *UpgradeStatus) DeepCopyInto(out *UpgradeStatus) {
+	*out = *in
+	if in.Daemons != nil {
+		in, out := &in.Daemons, &out.Daemons
+		*out = make([]DaemonUpgradeStatus, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UpgradeStatus.
+func (in *UpgradeStatus) DeepCopy() *UpgradeStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(UpgradeStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *UpgradeStrategySpec) DeepCopyInto(out *UpgradeStrategySpec) {
+	*out = *in
+	if in.PauseAfter != nil {
+		in, out := &in.PauseAfter, &out.PauseAfter
+		*out = make([]UpgradeDaemonType, len(*in))
+		copy(*out, *in)
+	}
+	if in.Canary != nil {
+		in, out := &in.Canary, &out.Canary
+		*out = new(UpgradeCanarySpec)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UpgradeStrategySpec.
+func (in *UpgradeStrategySpec) DeepCopy() *UpgradeStrategySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(UpgradeStrategySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ZoneSpec) DeepCopyInto(out *ZoneSpec) {
 	*out = *in
@@ -27,16 +27,22 @@ import (
 type CephV1Interface interface {
 	RESTClient() rest.Interface
 	CephBlockPoolsGetter
+	CephBlockPoolRadosNamespacesGetter
 	CephClientsGetter
 	CephClustersGetter
+	CephCrushRulesGetter
 	CephFilesystemsGetter
 	CephFilesystemMirrorsGetter
+	CephFilesystemSubVolumeGroupsGetter
 	CephNFSesGetter
+	CephNFSExportsGetter
+	CephNetworkFencesGetter
 	CephObjectRealmsGetter
 	CephObjectStoresGetter
 	CephObjectStoreUsersGetter
 	CephObjectZonesGetter
 	CephObjectZoneGroupsGetter
+	CephRBDFailoversGetter
 	CephRBDMirrorsGetter
 }
 
@@ -49,6 +55,10 @@ func (c *CephV1Client) CephBlockPools(namespace string) CephBlockPoolInterface {
 	return newCephBlockPools(c, namespace)
 }
 
+func (c *CephV1Client) CephBlockPoolRadosNamespaces(namespace string) CephBlockPoolRadosNamespaceInterface {
+	return newCephBlockPoolRadosNamespaces(c, namespace)
+}
+
 func (c *CephV1Client) CephClients(namespace string) CephClientInterface {
 	return newCephClients(c, namespace)
 }
@@ -57,6 +67,10 @@ func (c *CephV1Client) CephClusters(namespace string) CephClusterInterface {
 	return newCephClusters(c, namespace)
 }
 
+func (c *CephV1Client) CephCrushRules(namespace string) CephCrushRuleInterface {
+	return newCephCrushRules(c, namespace)
+}
+
 func (c *CephV1Client) CephFilesystems(namespace string) CephFilesystemInterface {
 	return newCephFilesystems(c, namespace)
 }
@@ -65,10 +79,22 @@ func (c *CephV1Client) CephFilesystemMirrors(namespace string) CephFilesystemMir
 	return newCephFilesystemMirrors(c, namespace)
 }
 
+func (c *CephV1Client) CephFilesystemSubVolumeGroups(namespace string) CephFilesystemSubVolumeGroupInterface {
+	return newCephFilesystemSubVolumeGroups(c, namespace)
+}
+
 func (c *CephV1Client) CephNFSes(namespace string) CephNFSInterface {
 	return newCephNFSes(c, namespace)
 }
 
+func (c *CephV1Client) CephNFSExports(namespace string) CephNFSExportInterface {
+	return newCephNFSExports(c, namespace)
+}
+
+func (c *CephV1Client) CephNetworkFences(namespace string) CephNetworkFenceInterface {
+	return newCephNetworkFences(c, namespace)
+}
+
 func (c *CephV1Client) CephObjectRealms(namespace string) CephObjectRealmInterface {
 	return newCephObjectRealms(c, namespace)
 }
@@ -89,6 +115,10 @@ func (c *CephV1Client) CephObjectZoneGroups(namespace string) CephObjectZoneGrou
 	return newCephObjectZoneGroups(c, namespace)
 }
 
+func (c *CephV1Client) CephRBDFailovers(namespace string) CephRBDFailoverInterface {
+	return newCephRBDFailovers(c, namespace)
+}
+
 func (c *CephV1Client) CephRBDMirrors(namespace string) CephRBDMirrorInterface {
 	return newCephRBDMirrors(c, namespace)
 }
@@ -32,6 +32,10 @@ func (c *FakeCephV1) CephBlockPools(namespace string) v1.CephBlockPoolInterface
 	return &FakeCephBlockPools{c, namespace}
 }
 
+func (c *FakeCephV1) CephBlockPoolRadosNamespaces(namespace string) v1.CephBlockPoolRadosNamespaceInterface {
+	return &FakeCephBlockPoolRadosNamespaces{c, namespace}
+}
+
 func (c *FakeCephV1) CephClients(namespace string) v1.CephClientInterface {
 	return &FakeCephClients{c, namespace}
 }
@@ -40,6 +44,10 @@ func (c *FakeCephV1) CephClusters(namespace string) v1.CephClusterInterface {
 	return &FakeCephClusters{c, namespace}
 }
 
+func (c *FakeCephV1) CephCrushRules(namespace string) v1.CephCrushRuleInterface {
+	return &FakeCephCrushRules{c, namespace}
+}
+
 func (c *FakeCephV1) CephFilesystems(namespace string) v1.CephFilesystemInterface {
 	return &FakeCephFilesystems{c, namespace}
 }
@@ -48,10 +56,22 @@ func (c *FakeCephV1) CephFilesystemMirrors(namespace string) v1.CephFilesystemMi
 	return &FakeCephFilesystemMirrors{c, namespace}
 }
 
+func (c *FakeCephV1) CephFilesystemSubVolumeGroups(namespace string) v1.CephFilesystemSubVolumeGroupInterface {
+	return &FakeCephFilesystemSubVolumeGroups{c, namespace}
+}
+
 func (c *FakeCephV1) CephNFSes(namespace string) v1.CephNFSInterface {
 	return &FakeCephNFSes{c, namespace}
 }
 
+func (c *FakeCephV1) CephNFSExports(namespace string) v1.CephNFSExportInterface {
+	return &FakeCephNFSExports{c, namespace}
+}
+
+func (c *FakeCephV1) CephNetworkFences(namespace string) v1.CephNetworkFenceInterface {
+	return &FakeCephNetworkFences{c, namespace}
+}
+
 func (c *FakeCephV1) CephObjectRealms(namespace string) v1.CephObjectRealmInterface {
 	return &FakeCephObjectRealms{c, namespace}
 }
@@ -72,6 +92,10 @@ func (c *FakeCephV1) CephObjectZoneGroups(namespace string) v1.CephObjectZoneGro
 	return &FakeCephObjectZoneGroups{c, namespace}
 }
 
+func (c *FakeCephV1) CephRBDFailovers(namespace string) v1.CephRBDFailoverInterface {
+	return &FakeCephRBDFailovers{c, namespace}
+}
+
 func (c *FakeCephV1) CephRBDMirrors(namespace string) v1.CephRBDMirrorInterface {
 	return &FakeCephRBDMirrors{c, namespace}
 }
@@ -20,16 +20,26 @@ package v1
 
 type CephBlockPoolExpansion interface{}
 
+type CephBlockPoolRadosNamespaceExpansion interface{}
+
 type CephClientExpansion interface{}
 
 type CephClusterExpansion interface{}
 
+type CephCrushRuleExpansion interface{}
+
 type CephFilesystemExpansion interface{}
 
 type CephFilesystemMirrorExpansion interface{}
 
+type CephFilesystemSubVolumeGroupExpansion interface{}
+
 type CephNFSExpansion interface{}
 
+type CephNFSExportExpansion interface{}
+
+type CephNetworkFenceExpansion interface{}
+
 type CephObjectRealmExpansion interface{}
 
 type CephObjectStoreExpansion interface{}
@@ -40,4 +50,6 @@ type CephObjectZoneExpansion interface{}
 
 type CephObjectZoneGroupExpansion interface{}
 
+type CephRBDFailoverExpansion interface{}
+
 type CephRBDMirrorExpansion interface{}
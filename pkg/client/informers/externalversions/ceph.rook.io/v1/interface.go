@@ -26,16 +26,26 @@ import (
 type Interface interface {
 	// CephBlockPools returns a CephBlockPoolInformer.
 	CephBlockPools() CephBlockPoolInformer
+	// CephBlockPoolRadosNamespaces returns a CephBlockPoolRadosNamespaceInformer.
+	CephBlockPoolRadosNamespaces() CephBlockPoolRadosNamespaceInformer
 	// CephClients returns a CephClientInformer.
 	CephClients() CephClientInformer
 	// CephClusters returns a CephClusterInformer.
 	CephClusters() CephClusterInformer
+	// CephCrushRules returns a CephCrushRuleInformer.
+	CephCrushRules() CephCrushRuleInformer
 	// CephFilesystems returns a CephFilesystemInformer.
 	CephFilesystems() CephFilesystemInformer
 	// CephFilesystemMirrors returns a CephFilesystemMirrorInformer.
 	CephFilesystemMirrors() CephFilesystemMirrorInformer
+	// CephFilesystemSubVolumeGroups returns a CephFilesystemSubVolumeGroupInformer.
+	CephFilesystemSubVolumeGroups() CephFilesystemSubVolumeGroupInformer
 	// CephNFSes returns a CephNFSInformer.
 	CephNFSes() CephNFSInformer
+	// CephNFSExports returns a CephNFSExportInformer.
+	CephNFSExports() CephNFSExportInformer
+	// CephNetworkFences returns a CephNetworkFenceInformer.
+	CephNetworkFences() CephNetworkFenceInformer
 	// CephObjectRealms returns a CephObjectRealmInformer.
 	CephObjectRealms() CephObjectRealmInformer
 	// CephObjectStores returns a CephObjectStoreInformer.
@@ -46,6 +56,8 @@ type Interface interface {
 	CephObjectZones() CephObjectZoneInformer
 	// CephObjectZoneGroups returns a CephObjectZoneGroupInformer.
 	CephObjectZoneGroups() CephObjectZoneGroupInformer
+	// CephRBDFailovers returns a CephRBDFailoverInformer.
+	CephRBDFailovers() CephRBDFailoverInformer
 	// CephRBDMirrors returns a CephRBDMirrorInformer.
 	CephRBDMirrors() CephRBDMirrorInformer
 }
@@ -66,6 +78,11 @@ func (v *version) CephBlockPools() CephBlockPoolInformer {
 	return &cephBlockPoolInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
 }
 
+// CephBlockPoolRadosNamespaces returns a CephBlockPoolRadosNamespaceInformer.
+func (v *version) CephBlockPoolRadosNamespaces() CephBlockPoolRadosNamespaceInformer {
+	return &cephBlockPoolRadosNamespaceInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
+}
+
 // CephClients returns a CephClientInformer.
 func (v *version) CephClients() CephClientInformer {
 	return &cephClientInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
@@ -76,6 +93,11 @@ func (v *version) CephClusters() CephClusterInformer {
 	return &cephClusterInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
 }
 
+// CephCrushRules returns a CephCrushRuleInformer.
+func (v *version) CephCrushRules() CephCrushRuleInformer {
+	return &cephCrushRuleInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
+}
+
 // CephFilesystems returns a CephFilesystemInformer.
 func (v *version) CephFilesystems() CephFilesystemInformer {
 	return &cephFilesystemInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
@@ -86,11 +108,26 @@ func (v *version) CephFilesystemMirrors() CephFilesystemMirrorInformer {
 	return &cephFilesystemMirrorInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
 }
 
+// CephFilesystemSubVolumeGroups returns a CephFilesystemSubVolumeGroupInformer.
+func (v *version) CephFilesystemSubVolumeGroups() CephFilesystemSubVolumeGroupInformer {
+	return &cephFilesystemSubVolumeGroupInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
+}
+
 // CephNFSes returns a CephNFSInformer.
 func (v *version) CephNFSes() CephNFSInformer {
 	return &cephNFSInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
 }
 
+// CephNFSExports returns a CephNFSExportInformer.
+func (v *version) CephNFSExports() CephNFSExportInformer {
+	return &cephNFSExportInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
+}
+
+// CephNetworkFences returns a CephNetworkFenceInformer.
+func (v *version) CephNetworkFences() CephNetworkFenceInformer {
+	return &cephNetworkFenceInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
+}
+
 // CephObjectRealms returns a CephObjectRealmInformer.
 func (v *version) CephObjectRealms() CephObjectRealmInformer {
 	return &cephObjectRealmInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
@@ -116,6 +153,11 @@ func (v *version) CephObjectZoneGroups() CephObjectZoneGroupInformer {
 	return &cephObjectZoneGroupInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
 }
 
+// CephRBDFailovers returns a CephRBDFailoverInformer.
+func (v *version) CephRBDFailovers() CephRBDFailoverInformer {
+	return &cephRBDFailoverInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
+}
+
 // CephRBDMirrors returns a CephRBDMirrorInformer.
 func (v *version) CephRBDMirrors() CephRBDMirrorInformer {
 	return &cephRBDMirrorInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
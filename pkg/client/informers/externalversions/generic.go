@@ -55,16 +55,26 @@ func (f *sharedInformerFactory) ForResource(resource schema.GroupVersionResource
 	// Group=ceph.rook.io, Version=v1
 	case v1.SchemeGroupVersion.WithResource("cephblockpools"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Ceph().V1().CephBlockPools().Informer()}, nil
+	case v1.SchemeGroupVersion.WithResource("cephblockpoolradosnamespaces"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Ceph().V1().CephBlockPoolRadosNamespaces().Informer()}, nil
 	case v1.SchemeGroupVersion.WithResource("cephclients"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Ceph().V1().CephClients().Informer()}, nil
 	case v1.SchemeGroupVersion.WithResource("cephclusters"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Ceph().V1().CephClusters().Informer()}, nil
+	case v1.SchemeGroupVersion.WithResource("cephcrushrules"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Ceph().V1().CephCrushRules().Informer()}, nil
 	case v1.SchemeGroupVersion.WithResource("cephfilesystems"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Ceph().V1().CephFilesystems().Informer()}, nil
 	case v1.SchemeGroupVersion.WithResource("cephfilesystemmirrors"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Ceph().V1().CephFilesystemMirrors().Informer()}, nil
+	case v1.SchemeGroupVersion.WithResource("cephfilesystemsubvolumegroups"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Ceph().V1().CephFilesystemSubVolumeGroups().Informer()}, nil
 	case v1.SchemeGroupVersion.WithResource("cephnfses"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Ceph().V1().CephNFSes().Informer()}, nil
+	case v1.SchemeGroupVersion.WithResource("cephnfsexports"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Ceph().V1().CephNFSExports().Informer()}, nil
+	case v1.SchemeGroupVersion.WithResource("cephnetworkfences"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Ceph().V1().CephNetworkFences().Informer()}, nil
 	case v1.SchemeGroupVersion.WithResource("cephobjectrealms"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Ceph().V1().CephObjectRealms().Informer()}, nil
 	case v1.SchemeGroupVersion.WithResource("cephobjectstores"):
@@ -75,6 +85,8 @@ func (f *sharedInformerFactory) ForResource(resource schema.GroupVersionResource
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Ceph().V1().CephObjectZones().Informer()}, nil
 	case v1.SchemeGroupVersion.WithResource("cephobjectzonegroups"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Ceph().V1().CephObjectZoneGroups().Informer()}, nil
+	case v1.SchemeGroupVersion.WithResource("cephrbdfailovers"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Ceph().V1().CephRBDFailovers().Informer()}, nil
 	case v1.SchemeGroupVersion.WithResource("cephrbdmirrors"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Ceph().V1().CephRBDMirrors().Informer()}, nil
 
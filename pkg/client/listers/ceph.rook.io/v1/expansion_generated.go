@@ -26,6 +26,14 @@ type CephBlockPoolListerExpansion interface{}
 // CephBlockPoolNamespaceLister.
 type CephBlockPoolNamespaceListerExpansion interface{}
 
+// CephBlockPoolRadosNamespaceListerExpansion allows custom methods to be added to
+// CephBlockPoolRadosNamespaceLister.
+type CephBlockPoolRadosNamespaceListerExpansion interface{}
+
+// CephBlockPoolRadosNamespaceNamespaceListerExpansion allows custom methods to be added to
+// CephBlockPoolRadosNamespaceNamespaceLister.
+type CephBlockPoolRadosNamespaceNamespaceListerExpansion interface{}
+
 // CephClientListerExpansion allows custom methods to be added to
 // CephClientLister.
 type CephClientListerExpansion interface{}
@@ -42,6 +50,14 @@ type CephClusterListerExpansion interface{}
 // CephClusterNamespaceLister.
 type CephClusterNamespaceListerExpansion interface{}
 
+// CephCrushRuleListerExpansion allows custom methods to be added to
+// CephCrushRuleLister.
+type CephCrushRuleListerExpansion interface{}
+
+// CephCrushRuleNamespaceListerExpansion allows custom methods to be added to
+// CephCrushRuleNamespaceLister.
+type CephCrushRuleNamespaceListerExpansion interface{}
+
 // CephFilesystemListerExpansion allows custom methods to be added to
 // CephFilesystemLister.
 type CephFilesystemListerExpansion interface{}
@@ -58,6 +74,14 @@ type CephFilesystemMirrorListerExpansion interface{}
 // CephFilesystemMirrorNamespaceLister.
 type CephFilesystemMirrorNamespaceListerExpansion interface{}
 
+// CephFilesystemSubVolumeGroupListerExpansion allows custom methods to be added to
+// CephFilesystemSubVolumeGroupLister.
+type CephFilesystemSubVolumeGroupListerExpansion interface{}
+
+// CephFilesystemSubVolumeGroupNamespaceListerExpansion allows custom methods to be added to
+// CephFilesystemSubVolumeGroupNamespaceLister.
+type CephFilesystemSubVolumeGroupNamespaceListerExpansion interface{}
+
 // CephNFSListerExpansion allows custom methods to be added to
 // CephNFSLister.
 type CephNFSListerExpansion interface{}
@@ -66,6 +90,22 @@ type CephNFSListerExpansion interface{}
 // CephNFSNamespaceLister.
 type CephNFSNamespaceListerExpansion interface{}
 
+// CephNFSExportListerExpansion allows custom methods to be added to
+// CephNFSExportLister.
+type CephNFSExportListerExpansion interface{}
+
+// CephNFSExportNamespaceListerExpansion allows custom methods to be added to
+// CephNFSExportNamespaceLister.
+type CephNFSExportNamespaceListerExpansion interface{}
+
+// CephNetworkFenceListerExpansion allows custom methods to be added to
+// CephNetworkFenceLister.
+type CephNetworkFenceListerExpansion interface{}
+
+// CephNetworkFenceNamespaceListerExpansion allows custom methods to be added to
+// CephNetworkFenceNamespaceLister.
+type CephNetworkFenceNamespaceListerExpansion interface{}
+
 // CephObjectRealmListerExpansion allows custom methods to be added to
 // CephObjectRealmLister.
 type CephObjectRealmListerExpansion interface{}
@@ -106,6 +146,14 @@ type CephObjectZoneGroupListerExpansion interface{}
 // CephObjectZoneGroupNamespaceLister.
 type CephObjectZoneGroupNamespaceListerExpansion interface{}
 
+// CephRBDFailoverListerExpansion allows custom methods to be added to
+// CephRBDFailoverLister.
+type CephRBDFailoverListerExpansion interface{}
+
+// CephRBDFailoverNamespaceListerExpansion allows custom methods to be added to
+// CephRBDFailoverNamespaceLister.
+type CephRBDFailoverNamespaceListerExpansion interface{}
+
 // CephRBDMirrorListerExpansion allows custom methods to be added to
 // CephRBDMirrorLister.
 type CephRBDMirrorListerExpansion interface{}
@@ -0,0 +1,86 @@
+/*
+Copyright 2021 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	"github.com/rook/rook/pkg/clusterd"
+)
+
+// CephAdmin runs the pool operations of a Ceph cluster. The controllers use it instead of the functions
+// of this package so their tests can run against the in-memory cluster of the client test package.
+type CephAdmin interface {
+	// ListPools returns the names and ids of the pools
+	ListPools() ([]CephStoragePoolSummary, error)
+	// GetPoolDetails returns the details of a pool, or an error if the pool does not exist
+	GetPoolDetails(name string) (CephStoragePoolDetails, error)
+	// CreatePool creates a pool, or updates the pool if it already exists, from the pool spec
+	CreatePool(clusterSpec *cephv1.ClusterSpec, name string, pool cephv1.PoolSpec, appName string) error
+	// CreateReplicatedPool creates a replicated pool with the given number of placement groups
+	CreateReplicatedPool(clusterSpec *cephv1.ClusterSpec, name string, pool cephv1.PoolSpec, pgCount, appName string) error
+	// CreateECPool creates an erasure coded pool with an existing erasure code profile
+	CreateECPool(name, ecProfileName string, pool cephv1.PoolSpec, pgCount, appName string, enableECOverwrite bool) error
+	// DeletePool deletes a pool that has no rbd images
+	DeletePool(name string) error
+	// SetPoolProperty sets a property of a pool
+	SetPoolProperty(name, propName, propVal string) error
+	// SetPoolReplicatedSize sets the number of replicas of a pool
+	SetPoolReplicatedSize(name, size string) error
+}
+
+// cephAdmin runs the pool operations with the functions of this package
+type cephAdmin struct {
+	context     *clusterd.Context
+	clusterInfo *ClusterInfo
+}
+
+// NewCephAdmin returns the admin of the cluster that runs the ceph commands
+func NewCephAdmin(context *clusterd.Context, clusterInfo *ClusterInfo) CephAdmin {
+	return &cephAdmin{context: context, clusterInfo: clusterInfo}
+}
+
+func (a *cephAdmin) ListPools() ([]CephStoragePoolSummary, error) {
+	return ListPoolSummaries(a.context, a.clusterInfo)
+}
+
+func (a *cephAdmin) GetPoolDetails(name string) (CephStoragePoolDetails, error) {
+	return GetPoolDetails(a.context, a.clusterInfo, name)
+}
+
+func (a *cephAdmin) CreatePool(clusterSpec *cephv1.ClusterSpec, name string, pool cephv1.PoolSpec, appName string) error {
+	return CreatePoolWithProfile(a.context, a.clusterInfo, clusterSpec, name, pool, appName)
+}
+
+func (a *cephAdmin) CreateReplicatedPool(clusterSpec *cephv1.ClusterSpec, name string, pool cephv1.PoolSpec, pgCount, appName string) error {
+	return CreateReplicatedPoolForApp(a.context, a.clusterInfo, clusterSpec, name, pool, pgCount, appName)
+}
+
+func (a *cephAdmin) CreateECPool(name, ecProfileName string, pool cephv1.PoolSpec, pgCount, appName string, enableECOverwrite bool) error {
+	return CreateECPoolForApp(a.context, a.clusterInfo, name, ecProfileName, pool, pgCount, appName, enableECOverwrite)
+}
+
+func (a *cephAdmin) DeletePool(name string) error {
+	return DeletePool(a.context, a.clusterInfo, name)
+}
+
+func (a *cephAdmin) SetPoolProperty(name, propName, propVal string) error {
+	return SetPoolProperty(a.context, a.clusterInfo, name, propName, propVal)
+}
+
+func (a *cephAdmin) SetPoolReplicatedSize(name, size string) error {
+	return SetPoolReplicatedSizeProperty(a.context, a.clusterInfo, name, size)
+}
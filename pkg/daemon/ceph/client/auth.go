@@ -104,6 +104,50 @@ func AuthGetCaps(context *clusterd.Context, clusterInfo *ClusterInfo, name strin
 	return caps, err
 }
 
+// AuthGetKeys gets the key and the pending key of the given user. The pending key is empty when the user
+// has no pending key.
+func AuthGetKeys(context *clusterd.Context, clusterInfo *ClusterInfo, name string) (string, string, error) {
+	logger.Infof("getting ceph auth keys %q", name)
+	args := []string{"auth", "get", name}
+	buf, err := NewCephCommand(context, clusterInfo, args).Run()
+	if err != nil {
+		return "", "", errors.Wrapf(err, "failed to get keys for %s", name)
+	}
+
+	return parseAuthKeys(buf)
+}
+
+// AuthGetOrCreatePendingKey gets or creates the pending key of the given user. Both the key and the pending
+// key are valid until the pending key is committed.
+func AuthGetOrCreatePendingKey(context *clusterd.Context, clusterInfo *ClusterInfo, name string) (string, error) {
+	logger.Infof("getting or creating ceph auth pending key %q", name)
+	args := []string{"auth", "get-or-create-pending", name}
+	buf, err := NewCephCommand(context, clusterInfo, args).Run()
+	if err != nil {
+		return "", errors.Wrapf(err, "failed get-or-create-pending %s", name)
+	}
+
+	_, pendingKey, err := parseAuthKeys(buf)
+	if err != nil {
+		return "", err
+	}
+	if pendingKey == "" {
+		return "", errors.Errorf("no pending key for %s", name)
+	}
+	return pendingKey, nil
+}
+
+// AuthCommitPendingKey replaces the key of the given user with its pending key
+func AuthCommitPendingKey(context *clusterd.Context, clusterInfo *ClusterInfo, name string) error {
+	logger.Infof("committing ceph auth pending key %q", name)
+	args := []string{"auth", "commit-pending", name}
+	_, err := NewCephCommand(context, clusterInfo, args).Run()
+	if err != nil {
+		return errors.Wrapf(err, "failed to commit pending key for %s", name)
+	}
+	return nil
+}
+
 // AuthDelete will delete the given user.
 func AuthDelete(context *clusterd.Context, clusterInfo *ClusterInfo, name string) error {
 	logger.Infof("deleting ceph auth %q", name)
@@ -122,3 +166,17 @@ func parseAuthKey(buf []byte) (string, error) {
 	}
 	return resp["key"].(string), nil
 }
+
+func parseAuthKeys(buf []byte) (string, string, error) {
+	var entities []struct {
+		Key        string `json:"key"`
+		PendingKey string `json:"pending_key"`
+	}
+	if err := json.Unmarshal(buf, &entities); err != nil {
+		return "", "", errors.Wrap(err, "failed to unmarshal get keys response")
+	}
+	if len(entities) == 0 {
+		return "", "", errors.New("no entity in get keys response")
+	}
+	return entities[0].Key, entities[0].PendingKey, nil
+}
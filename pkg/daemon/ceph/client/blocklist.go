@@ -0,0 +1,139 @@
+/*
+Copyright 2021 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"encoding/json"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/rook/rook/pkg/clusterd"
+)
+
+// ImageWatcher is a client watching an rbd image
+type ImageWatcher struct {
+	Address string `json:"address"`
+	Client  uint64 `json:"client"`
+	Cookie  uint64 `json:"cookie"`
+}
+
+type imageStatus struct {
+	Watchers  []ImageWatcher  `json:"watchers"`
+	Migration *ImageMigration `json:"migration"`
+}
+
+// blocklistCommand returns the osd command managing the blocklist, which was renamed in pacific
+func blocklistCommand(clusterInfo *ClusterInfo) string {
+	if clusterInfo.CephVersion.IsAtLeastPacific() {
+		return "blocklist"
+	}
+	return "blacklist"
+}
+
+// BlocklistAdd blocklists the clients of an address for the expire duration. The address is either an
+// IP, which blocklists all the clients of the IP, or a CIDR range, which requires ceph quincy.
+func BlocklistAdd(context *clusterd.Context, clusterInfo *ClusterInfo, address string, expire time.Duration) error {
+	args, err := blocklistArgs(clusterInfo, "add", address)
+	if err != nil {
+		return err
+	}
+	args = append(args, strconv.Itoa(int(expire.Seconds())))
+	buf, err := NewCephCommand(context, clusterInfo, args).Run()
+	if err != nil {
+		return errors.Wrapf(err, "failed to blocklist %q. %s", address, string(buf))
+	}
+
+	logger.Infof("blocklisted the clients of %q", address)
+	return nil
+}
+
+// BlocklistRemove removes an address added with BlocklistAdd from the blocklist
+func BlocklistRemove(context *clusterd.Context, clusterInfo *ClusterInfo, address string) error {
+	args, err := blocklistArgs(clusterInfo, "rm", address)
+	if err != nil {
+		return err
+	}
+	buf, err := NewCephCommand(context, clusterInfo, args).Run()
+	if err != nil {
+		return errors.Wrapf(err, "failed to remove %q from the blocklist. %s", address, string(buf))
+	}
+
+	logger.Infof("removed %q from the blocklist", address)
+	return nil
+}
+
+func blocklistArgs(clusterInfo *ClusterInfo, action, address string) ([]string, error) {
+	if !strings.Contains(address, "/") {
+		if net.ParseIP(address) == nil {
+			return nil, errors.Errorf("invalid blocklist address %q", address)
+		}
+		return []string{"osd", blocklistCommand(clusterInfo), action, address}, nil
+	}
+
+	if _, _, err := net.ParseCIDR(address); err != nil {
+		return nil, errors.Wrapf(err, "invalid blocklist range %q", address)
+	}
+	if !clusterInfo.CephVersion.IsAtLeastQuincy() {
+		return nil, errors.Errorf("failed to blocklist range %q. blocklisting ranges requires ceph quincy", address)
+	}
+	return []string{"osd", "blocklist", "range", action, address}, nil
+}
+
+// GetImageWatchers returns the clients watching an rbd image. The image is in the pool if the rados
+// namespace is empty.
+func GetImageWatchers(context *clusterd.Context, clusterInfo *ClusterInfo, poolName, namespace, imageName string) ([]ImageWatcher, error) {
+	status, err := getImageStatus(context, clusterInfo, getImageSpecInNamespace(imageName, poolName, namespace))
+	if err != nil {
+		return nil, err
+	}
+	return status.Watchers, nil
+}
+
+func getImageStatus(context *clusterd.Context, clusterInfo *ClusterInfo, imageSpec string) (*imageStatus, error) {
+	cmd := NewRBDCommand(context, clusterInfo, []string{"status", imageSpec})
+	cmd.JsonOutput = true
+	buf, err := cmd.Run()
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get the status of image %q. %s", imageSpec, string(buf))
+	}
+
+	var status imageStatus
+	if err := json.Unmarshal(buf, &status); err != nil {
+		return nil, errors.Wrapf(err, "failed to unmarshal the status of image %q. %s", imageSpec, string(buf))
+	}
+	return &status, nil
+}
+
+// IP returns the IP of the address of the watcher, with the format <ip>:<port>/<nonce>
+func (w ImageWatcher) IP() string {
+	address := w.Address
+	if i := strings.LastIndex(address, "/"); i >= 0 {
+		address = address[:i]
+	}
+	// the address may have a type prefix, e.g. v1:
+	if i := strings.Index(address, ":"); i >= 0 && strings.HasPrefix(address, "v") {
+		address = address[i+1:]
+	}
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return address
+	}
+	return host
+}
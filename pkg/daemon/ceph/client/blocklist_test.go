@@ -0,0 +1,88 @@
+/*
+Copyright 2021 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/rook/rook/pkg/clusterd"
+	cephver "github.com/rook/rook/pkg/operator/ceph/version"
+	exectest "github.com/rook/rook/pkg/util/exec/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBlocklist(t *testing.T) {
+	executor := &exectest.MockExecutor{}
+	context := &clusterd.Context{Executor: executor}
+	clusterInfo := AdminClusterInfo("mycluster")
+	clusterInfo.CephVersion = cephver.Pacific
+	var lastArgs []string
+	executor.MockExecuteCommandWithOutput = func(command string, args ...string) (string, error) {
+		logger.Infof("Command: %s %v", command, args)
+		if args[0] == "osd" {
+			lastArgs = args[:len(args)-2]
+			return "", nil
+		}
+		return "", errors.Errorf("unexpected ceph command %q", args)
+	}
+
+	err := BlocklistAdd(context, clusterInfo, "10.0.0.5", time.Hour)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"osd", "blocklist", "add", "10.0.0.5", "3600"}, lastArgs[:5])
+
+	err = BlocklistRemove(context, clusterInfo, "10.0.0.5")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"osd", "blocklist", "rm", "10.0.0.5"}, lastArgs[:4])
+
+	// ranges require quincy
+	err = BlocklistAdd(context, clusterInfo, "10.0.0.0/24", time.Hour)
+	assert.Error(t, err)
+	clusterInfo.CephVersion = cephver.Quincy
+	err = BlocklistAdd(context, clusterInfo, "10.0.0.0/24", time.Hour)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"osd", "blocklist", "range", "add", "10.0.0.0/24", "3600"}, lastArgs[:6])
+
+	// the blocklist was named blacklist before pacific
+	clusterInfo.CephVersion = cephver.Octopus
+	err = BlocklistAdd(context, clusterInfo, "10.0.0.5", time.Hour)
+	assert.NoError(t, err)
+	assert.Equal(t, "blacklist", lastArgs[1])
+
+	err = BlocklistAdd(context, clusterInfo, "not-an-ip", time.Hour)
+	assert.Error(t, err)
+}
+
+func TestGetImageWatchers(t *testing.T) {
+	executor := &exectest.MockExecutor{}
+	context := &clusterd.Context{Executor: executor}
+	executor.MockExecuteCommandWithOutput = func(command string, args ...string) (string, error) {
+		logger.Infof("Command: %s %v", command, args)
+		if command == "rbd" && args[0] == "status" {
+			assert.Equal(t, "replicapool/ns/csi-vol-1", args[1])
+			return `{"watchers":[{"address":"10.0.0.5:0/3519486571","client":4123,"cookie":18446462598732840961},{"address":"[fd00::5]:0/12","client":4124,"cookie":1}]}`, nil
+		}
+		return "", errors.Errorf("unexpected rbd command %q", args)
+	}
+
+	watchers, err := GetImageWatchers(context, AdminClusterInfo("mycluster"), "replicapool", "ns", "csi-vol-1")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(watchers))
+	assert.Equal(t, "10.0.0.5", watchers[0].IP())
+	assert.Equal(t, "fd00::5", watchers[1].IP())
+}
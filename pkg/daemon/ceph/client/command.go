@@ -97,6 +97,9 @@ type CephToolCommand struct {
 	timeout         time.Duration
 	JsonOutput      bool
 	RemoteExecution bool
+	// LongRunning is whether the command runs for much longer than the timeout of the commands, such as
+	// "rbd migration execute". It does not take one of the slots of the concurrent commands of the cluster.
+	LongRunning bool
 }
 
 func newCephToolCommand(tool string, context *clusterd.Context, clusterInfo *ClusterInfo, args []string) *CephToolCommand {
@@ -131,6 +134,28 @@ func (c *CephToolCommand) run() ([]byte, error) {
 		return nil, c.clusterInfo.Context.Err()
 	}
 
+	// Limit the concurrent commands of the cluster and fail fast while the mons do not answer
+	guard := getCommandGuard(c.clusterInfo.Namespace)
+	release, err := guard.acquire(c)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	// Record the caller, duration and exit code of the command
+	audit := exec.StartCommandAudit(c.RemoteExecution, c.tool, c.args...)
+	output, err := c.execute()
+	audit.Finish(string(output), err)
+	guard.record(c, audit.Result == exec.CommandResultTimeout)
+	return output, err
+}
+
+func (c *CephToolCommand) execute() ([]byte, error) {
+	// Run the ceph commands with the connection to the monitors when the operator is built with librados
+	if output, ok, err := c.runMonCommand(); ok {
+		return output, err
+	}
+
 	// Initialize the command and args
 	command := c.tool
 	args := c.args
@@ -194,7 +219,9 @@ func (c *CephToolCommand) RunWithTimeout(timeout time.Duration) ([]byte, error)
 // configured its arguments. It is future work to integrate this case into the
 // generalization.
 func ExecuteRBDCommandWithTimeout(context *clusterd.Context, args []string) (string, error) {
+	audit := exec.StartCommandAudit(false, RBDTool, args...)
 	output, err := context.Executor.ExecuteCommandWithTimeout(exec.CephCommandsTimeout, RBDTool, args...)
+	audit.Finish(output, err)
 	return output, err
 }
 
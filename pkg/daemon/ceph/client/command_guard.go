@@ -0,0 +1,220 @@
+/*
+Copyright 2021 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/rook/rook/pkg/util/exec"
+)
+
+const maxCommandCircuitOpenDuration = 5 * time.Minute
+
+var (
+	// MaxConcurrentCommands is the max number of ceph commands that run at the same time for a cluster
+	MaxConcurrentCommands = 20
+	// CommandCircuitTimeouts is the number of consecutive timeouts of the ceph commands of a cluster after
+	// which its commands fail fast, the commands never fail fast when it is 0
+	CommandCircuitTimeouts = 3
+	// CommandCircuitOpenDuration is how long the commands fail fast before the quorum is probed. It doubles
+	// every time the probe fails, up to 5 minutes.
+	CommandCircuitOpenDuration = 30 * time.Second
+
+	// commandGuards are the guards of the commands of the clusters by namespace
+	commandGuards = struct {
+		sync.Mutex
+		guards map[string]*commandGuard
+	}{guards: map[string]*commandGuard{}}
+
+	// probeQuorum returns an error when the mons of the cluster are not in quorum
+	probeQuorum = probeMonQuorum
+)
+
+// CommandsUnavailableError is returned without running a ceph command when the commands of the cluster
+// fail fast, because the mons did not answer the last commands or too many commands are running. The
+// request should be retried after RetryAfter.
+type CommandsUnavailableError struct {
+	Namespace  string
+	Reason     string
+	RetryAfter time.Duration
+}
+
+func (e *CommandsUnavailableError) Error() string {
+	return fmt.Sprintf("ceph commands of cluster %q are unavailable, %s. retry after %s", e.Namespace, e.Reason, e.RetryAfter)
+}
+
+// IsCommandsUnavailable returns the CommandsUnavailableError of the error or of the errors it wraps
+func IsCommandsUnavailable(err error) (*CommandsUnavailableError, bool) {
+	var unavailable *CommandsUnavailableError
+	if errors.As(err, &unavailable) {
+		return unavailable, true
+	}
+	return nil, false
+}
+
+// commandGuard limits the concurrent commands of a cluster and opens a circuit after repeated timeouts,
+// so the callers fail fast instead of waiting for the timeout of every command while the mons are down
+type commandGuard struct {
+	mutex sync.Mutex
+	slots chan struct{}
+	// timeouts is the number of consecutive timeouts of the commands
+	timeouts int
+	// openUntil is when the quorum is probed again, it is zero when the circuit is closed
+	openUntil    time.Time
+	openDuration time.Duration
+	probing      bool
+}
+
+func getCommandGuard(namespace string) *commandGuard {
+	commandGuards.Lock()
+	defer commandGuards.Unlock()
+
+	guard, ok := commandGuards.guards[namespace]
+	if !ok {
+		guard = &commandGuard{}
+		commandGuards.guards[namespace] = guard
+	}
+	return guard
+}
+
+// ResetCommandGuard forgets the concurrent commands and the timeouts of the commands of the cluster
+func ResetCommandGuard(namespace string) {
+	commandGuards.Lock()
+	defer commandGuards.Unlock()
+	delete(commandGuards.guards, namespace)
+}
+
+// acquire waits until the command can run and returns the function that releases its slot, or returns a
+// CommandsUnavailableError when the command must fail fast. The long running commands do not take a slot
+// so they never delay the other commands, their callers limit how many of them run.
+func (g *commandGuard) acquire(c *CephToolCommand) (func(), error) {
+	if err := g.checkCircuit(c); err != nil {
+		return nil, err
+	}
+	if c.LongRunning {
+		return func() {}, nil
+	}
+
+	g.mutex.Lock()
+	if g.slots == nil || cap(g.slots) != MaxConcurrentCommands {
+		// the commands that already run release the slots of the previous limit
+		g.slots = make(chan struct{}, MaxConcurrentCommands)
+	}
+	slots := g.slots
+	g.mutex.Unlock()
+
+	timer := time.NewTimer(exec.CephCommandsTimeout)
+	defer timer.Stop()
+	select {
+	case slots <- struct{}{}:
+		return func() { <-slots }, nil
+	case <-c.clusterInfo.Context.Done():
+		return nil, c.clusterInfo.Context.Err()
+	case <-timer.C:
+		return nil, &CommandsUnavailableError{
+			Namespace:  c.clusterInfo.Namespace,
+			Reason:     fmt.Sprintf("%d commands are already running", cap(slots)),
+			RetryAfter: exec.CephCommandsTimeout,
+		}
+	}
+}
+
+// checkCircuit returns an error while the circuit is open. Once the circuit has been open long enough,
+// the quorum is probed by a single command and the circuit closes when the mons are in quorum.
+func (g *commandGuard) checkCircuit(c *CephToolCommand) error {
+	g.mutex.Lock()
+	if g.openUntil.IsZero() {
+		g.mutex.Unlock()
+		return nil
+	}
+	if wait := time.Until(g.openUntil); wait > 0 || g.probing {
+		g.mutex.Unlock()
+		if wait <= 0 {
+			wait = exec.CephCommandsTimeout
+		}
+		return g.unavailable(c, wait)
+	}
+	g.probing = true
+	g.mutex.Unlock()
+
+	err := probeQuorum(c)
+
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	g.probing = false
+	if err != nil {
+		g.openDuration *= 2
+		if g.openDuration > maxCommandCircuitOpenDuration {
+			g.openDuration = maxCommandCircuitOpenDuration
+		}
+		g.openUntil = time.Now().Add(g.openDuration)
+		logger.Warningf("ceph commands of cluster %q still fail fast for %s. %v", c.clusterInfo.Namespace, g.openDuration, err)
+		return g.unavailable(c, g.openDuration)
+	}
+	logger.Infof("mons of cluster %q are in quorum, running the ceph commands again", c.clusterInfo.Namespace)
+	g.timeouts = 0
+	g.openUntil = time.Time{}
+	g.openDuration = 0
+	return nil
+}
+
+func (g *commandGuard) unavailable(c *CephToolCommand, retryAfter time.Duration) error {
+	return &CommandsUnavailableError{
+		Namespace:  c.clusterInfo.Namespace,
+		Reason:     "the mons did not answer the last commands",
+		RetryAfter: retryAfter,
+	}
+}
+
+// record counts the consecutive timeouts of the commands and opens the circuit after too many timeouts
+func (g *commandGuard) record(c *CephToolCommand, timedOut bool) {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	if !timedOut {
+		g.timeouts = 0
+		return
+	}
+	g.timeouts++
+	if CommandCircuitTimeouts > 0 && g.timeouts >= CommandCircuitTimeouts && g.openUntil.IsZero() {
+		g.openDuration = CommandCircuitOpenDuration
+		g.openUntil = time.Now().Add(g.openDuration)
+		logger.Warningf("the last %d ceph commands of cluster %q timed out, the commands fail fast for %s", g.timeouts, c.clusterInfo.Namespace, g.openDuration)
+	}
+}
+
+// probeMonQuorum runs "quorum_status" without the guard and returns an error when the mons are not in quorum
+func probeMonQuorum(c *CephToolCommand) error {
+	cmd := NewCephCommand(c.context, c.clusterInfo, []string{"quorum_status"})
+	cmd.timeout = exec.CephCommandsTimeout
+	output, err := cmd.execute()
+	if err != nil {
+		return errors.Wrap(err, "failed to get the quorum status")
+	}
+	var status MonStatusResponse
+	if err := json.Unmarshal(output, &status); err != nil {
+		return errors.Wrap(err, "failed to unmarshal the quorum status")
+	}
+	if len(status.Quorum) == 0 {
+		return errors.New("no mons are in quorum")
+	}
+	return nil
+}
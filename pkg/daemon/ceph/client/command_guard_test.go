@@ -0,0 +1,210 @@
+/*
+Copyright 2021 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/rook/rook/pkg/clusterd"
+	"github.com/rook/rook/pkg/util/exec"
+	exectest "github.com/rook/rook/pkg/util/exec/test"
+	"github.com/stretchr/testify/assert"
+	kexec "k8s.io/utils/exec"
+)
+
+func TestCommandCircuitBreaker(t *testing.T) {
+	namespace := "guarded"
+	defer ResetCommandGuard(namespace)
+	defer func() { probeQuorum = probeMonQuorum }()
+
+	monsDown := true
+	commands := 0
+	executor := &exectest.MockExecutor{
+		MockExecuteCommandWithOutput: func(command string, args ...string) (string, error) {
+			commands++
+			if monsDown {
+				return "", errors.New("timeout waiting for the command ceph to return")
+			}
+			return "", nil
+		},
+	}
+	context := &clusterd.Context{Executor: executor}
+	clusterInfo := AdminClusterInfo(namespace)
+	probes := 0
+	probeQuorum = func(c *CephToolCommand) error {
+		probes++
+		if monsDown {
+			return errors.New("no mons are in quorum")
+		}
+		return nil
+	}
+
+	// the commands run until the circuit opens after 3 timeouts
+	for i := 0; i < 3; i++ {
+		_, err := NewCephCommand(context, clusterInfo, []string{"status"}).Run()
+		assert.Error(t, err)
+		_, ok := IsCommandsUnavailable(err)
+		assert.False(t, ok)
+	}
+	assert.Equal(t, 3, commands)
+
+	// the commands fail fast without running
+	_, err := NewCephCommand(context, clusterInfo, []string{"status"}).Run()
+	unavailable, ok := IsCommandsUnavailable(errors.Wrap(err, "failed to get status"))
+	assert.True(t, ok)
+	assert.Equal(t, namespace, unavailable.Namespace)
+	assert.True(t, unavailable.RetryAfter > 0 && unavailable.RetryAfter <= CommandCircuitOpenDuration)
+	assert.Equal(t, 3, commands)
+	assert.Equal(t, 0, probes)
+
+	// the quorum is probed once the circuit has been open long enough, the open duration doubles when the probe fails
+	guard := getCommandGuard(namespace)
+	guard.openUntil = time.Now().Add(-time.Second)
+	_, err = NewCephCommand(context, clusterInfo, []string{"status"}).Run()
+	unavailable, ok = IsCommandsUnavailable(err)
+	assert.True(t, ok)
+	assert.Equal(t, 2*CommandCircuitOpenDuration, unavailable.RetryAfter)
+	assert.Equal(t, 1, probes)
+	assert.Equal(t, 3, commands)
+
+	// the circuit closes when the mons are in quorum again
+	monsDown = false
+	guard.openUntil = time.Now().Add(-time.Second)
+	_, err = NewCephCommand(context, clusterInfo, []string{"status"}).Run()
+	assert.NoError(t, err)
+	assert.Equal(t, 2, probes)
+	assert.Equal(t, 4, commands)
+	assert.True(t, guard.openUntil.IsZero())
+	assert.Equal(t, 0, guard.timeouts)
+
+	// the other errors do not open the circuit
+	executor.MockExecuteCommandWithOutput = func(command string, args ...string) (string, error) {
+		return "", errors.New("failed")
+	}
+	for i := 0; i < 5; i++ {
+		_, err = NewCephCommand(context, clusterInfo, []string{"status"}).Run()
+		_, ok = IsCommandsUnavailable(err)
+		assert.False(t, ok)
+	}
+	assert.Equal(t, 0, guard.timeouts)
+}
+
+func TestCommandCircuitBreakerDisabled(t *testing.T) {
+	namespace := "unguarded"
+	defer ResetCommandGuard(namespace)
+	CommandCircuitTimeouts = 0
+	defer func() { CommandCircuitTimeouts = 3 }()
+
+	executor := &exectest.MockExecutor{
+		MockExecuteCommandWithOutput: func(command string, args ...string) (string, error) {
+			return "RADOS timed out (error connecting to the cluster)", errors.New("exit status 1")
+		},
+	}
+	context := &clusterd.Context{Executor: executor}
+	clusterInfo := AdminClusterInfo(namespace)
+	for i := 0; i < 5; i++ {
+		_, err := NewCephCommand(context, clusterInfo, []string{"status"}).Run()
+		_, ok := IsCommandsUnavailable(err)
+		assert.False(t, ok)
+	}
+	assert.Equal(t, 5, getCommandGuard(namespace).timeouts)
+}
+
+func TestMaxConcurrentCommands(t *testing.T) {
+	namespace := "busy"
+	defer ResetCommandGuard(namespace)
+	MaxConcurrentCommands = 2
+	defer func() { MaxConcurrentCommands = 20 }()
+	exec.CephCommandsTimeout = 10 * time.Millisecond
+	defer func() { exec.CephCommandsTimeout = 15 * time.Second }()
+
+	clusterInfo := AdminClusterInfo(namespace)
+	c := NewCephCommand(&clusterd.Context{}, clusterInfo, []string{"status"})
+	guard := getCommandGuard(namespace)
+	release1, err := guard.acquire(c)
+	assert.NoError(t, err)
+	release2, err := guard.acquire(c)
+	assert.NoError(t, err)
+
+	// the command waits for a slot until the timeout of the commands
+	_, err = guard.acquire(c)
+	unavailable, ok := IsCommandsUnavailable(err)
+	assert.True(t, ok)
+	assert.Contains(t, unavailable.Reason, "2 commands are already running")
+
+	release1()
+	release3, err := guard.acquire(c)
+	assert.NoError(t, err)
+	release2()
+	release3()
+}
+
+func TestLongRunningCommands(t *testing.T) {
+	namespace := "migrating"
+	defer ResetCommandGuard(namespace)
+	MaxConcurrentCommands = 1
+	defer func() { MaxConcurrentCommands = 20 }()
+	exec.CephCommandsTimeout = 10 * time.Millisecond
+	defer func() { exec.CephCommandsTimeout = 15 * time.Second }()
+
+	clusterInfo := AdminClusterInfo(namespace)
+	guard := getCommandGuard(namespace)
+	migration := NewRBDCommand(&clusterd.Context{}, clusterInfo, []string{"migration", "execute", "replicapool/image"})
+	migration.LongRunning = true
+	releaseMigration, err := guard.acquire(migration)
+	assert.NoError(t, err)
+
+	// the long running command does not take the slot of the other commands
+	release, err := guard.acquire(NewCephCommand(&clusterd.Context{}, clusterInfo, []string{"status"}))
+	assert.NoError(t, err)
+	release()
+	releaseMigration()
+}
+
+func TestCommandTimeouts(t *testing.T) {
+	namespace := "timeouts"
+	defer ResetCommandGuard(namespace)
+
+	output := ""
+	executor := &exectest.MockExecutor{
+		MockExecuteCommandWithOutput: func(command string, args ...string) (string, error) {
+			return output, &kexec.CodeExitError{Err: errors.New("exit status 1"), Code: 1}
+		},
+	}
+	context := &clusterd.Context{Executor: executor}
+	clusterInfo := AdminClusterInfo(namespace)
+	guard := getCommandGuard(namespace)
+
+	// the ceph CLI cannot connect to the mons
+	output = "[errno 110] RADOS timed out (error connecting to the cluster)"
+	_, err := NewCephCommand(context, clusterInfo, []string{"status"}).Run()
+	assert.Error(t, err)
+	assert.Equal(t, 1, guard.timeouts)
+
+	// the failures that only mention a timeout are not timeouts of the mons
+	output = "the snapshot timed out"
+	_, err = NewCephCommand(context, clusterInfo, []string{"status"}).Run()
+	assert.Error(t, err)
+	assert.Equal(t, 0, guard.timeouts)
+
+	output = "pool 'replicapool' does not exist"
+	_, err = NewCephCommand(context, clusterInfo, []string{"status"}).Run()
+	assert.Error(t, err)
+	assert.Equal(t, 0, guard.timeouts)
+}
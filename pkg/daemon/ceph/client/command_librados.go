@@ -0,0 +1,67 @@
+//go:build librados
+// +build librados
+
+/*
+Copyright 2021 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"fmt"
+	"path"
+	"strconv"
+
+	"github.com/ceph/go-ceph/rados"
+	"github.com/pkg/errors"
+	"github.com/rook/rook/pkg/util/exec"
+)
+
+func init() {
+	NewMonCommandConn = newRadosConn
+}
+
+// newRadosConn connects to the monitors with librados, with the same config and keyring as the ceph CLI
+func newRadosConn(clusterInfo *ClusterInfo, configDir string) (MonCommandConn, error) {
+	conn, err := rados.NewConnWithClusterAndUser(clusterInfo.Namespace, clusterInfo.CephCred.Username)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create rados connection")
+	}
+
+	if err := conn.ReadConfigFile(CephConfFilePath(configDir, clusterInfo.Namespace)); err != nil {
+		conn.Shutdown()
+		return nil, errors.Wrap(err, "failed to read the ceph config file")
+	}
+	keyringFile := fmt.Sprintf("%s.keyring", clusterInfo.CephCred.Username)
+	timeout := strconv.Itoa(int(exec.CephCommandsTimeout.Seconds()))
+	options := map[string]string{
+		"keyring": path.Join(configDir, clusterInfo.Namespace, keyringFile),
+		// same as the --connect-timeout of the ceph CLI
+		"client_mount_timeout": timeout,
+		"rados_mon_op_timeout": timeout,
+	}
+	for option, value := range options {
+		if err := conn.SetConfigOption(option, value); err != nil {
+			conn.Shutdown()
+			return nil, errors.Wrapf(err, "failed to set rados option %q", option)
+		}
+	}
+
+	if err := conn.Connect(); err != nil {
+		conn.Shutdown()
+		return nil, errors.Wrap(err, "failed to connect to the monitors")
+	}
+	return conn, nil
+}
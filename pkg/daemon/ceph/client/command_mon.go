@@ -0,0 +1,407 @@
+/*
+Copyright 2021 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/rook/rook/pkg/clusterd"
+	kexec "k8s.io/utils/exec"
+)
+
+// MonCommandConn is a persistent connection to the monitors of a cluster that runs the mon commands
+// without the ceph CLI. The mgr commands are forwarded to the mgr by the monitors.
+type MonCommandConn interface {
+	// MonCommand runs a command given as json, and returns its output buffer and status string
+	MonCommand(args []byte) ([]byte, string, error)
+	// Shutdown closes the connection
+	Shutdown()
+}
+
+// NewMonCommandConn connects to the monitors of a cluster with the config and keyring of the cluster in
+// the config dir. It is only set when the operator is built with the "librados" tag, otherwise all the
+// ceph commands run with the ceph CLI.
+var NewMonCommandConn func(clusterInfo *ClusterInfo, configDir string) (MonCommandConn, error)
+
+// monCommandConns are the connections to the monitors of the clusters, and the mon command descriptions
+// used to translate the arguments of the ceph CLI to mon commands
+var monCommandConns = struct {
+	sync.Mutex
+	conns map[string]*monCommandClient
+}{conns: map[string]*monCommandClient{}}
+
+type monCommandClient struct {
+	conn         MonCommandConn
+	descriptions []monCommandDescription
+}
+
+// monCommandDescription is the signature of a command returned by "get_command_descriptions"
+type monCommandDescription struct {
+	prefix []string
+	params []monCommandParam
+}
+
+type monCommandParam struct {
+	name     string
+	kind     string
+	choices  []string
+	multiple bool
+	required bool
+}
+
+func monCommandConnKey(clusterInfo *ClusterInfo) string {
+	return fmt.Sprintf("%s/%s", clusterInfo.Namespace, clusterInfo.CephCred.Username)
+}
+
+// getMonCommandClient returns the connection to the monitors of the cluster, and connects when there
+// is no connection yet
+func getMonCommandClient(context *clusterd.Context, clusterInfo *ClusterInfo) (*monCommandClient, error) {
+	monCommandConns.Lock()
+	defer monCommandConns.Unlock()
+
+	key := monCommandConnKey(clusterInfo)
+	if client, ok := monCommandConns.conns[key]; ok {
+		return client, nil
+	}
+
+	conn, err := NewMonCommandConn(clusterInfo, context.ConfigDir)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to connect to the monitors of cluster %q", clusterInfo.Namespace)
+	}
+	output, _, err := conn.MonCommand([]byte(`{"prefix": "get_command_descriptions"}`))
+	if err != nil {
+		conn.Shutdown()
+		return nil, errors.Wrapf(err, "failed to get the mon command descriptions of cluster %q", clusterInfo.Namespace)
+	}
+	descriptions, err := parseMonCommandDescriptions(output)
+	if err != nil {
+		conn.Shutdown()
+		return nil, err
+	}
+
+	client := &monCommandClient{conn: conn, descriptions: descriptions}
+	monCommandConns.conns[key] = client
+	logger.Infof("connected to the monitors of cluster %q with librados", clusterInfo.Namespace)
+	return client, nil
+}
+
+// closeMonCommandConn closes the connection to the monitors of the cluster, the next command reconnects.
+// The connection is only closed if it was not closed and replaced by another command yet.
+func closeMonCommandConn(clusterInfo *ClusterInfo, client *monCommandClient) {
+	if evictMonCommandConn(clusterInfo, client) {
+		client.conn.Shutdown()
+	}
+}
+
+// evictMonCommandConn removes the connection from the connections to the monitors, so the next command
+// reconnects. It returns false if the connection was already removed.
+func evictMonCommandConn(clusterInfo *ClusterInfo, client *monCommandClient) bool {
+	monCommandConns.Lock()
+	defer monCommandConns.Unlock()
+
+	key := monCommandConnKey(clusterInfo)
+	if cached, ok := monCommandConns.conns[key]; ok && cached == client {
+		delete(monCommandConns.conns, key)
+		return true
+	}
+	return false
+}
+
+// CloseMonCommandConns closes the connections to the monitors of the cluster in the namespace
+func CloseMonCommandConns(namespace string) {
+	monCommandConns.Lock()
+	defer monCommandConns.Unlock()
+
+	for key, client := range monCommandConns.conns {
+		if strings.HasPrefix(key, namespace+"/") {
+			client.conn.Shutdown()
+			delete(monCommandConns.conns, key)
+		}
+	}
+}
+
+// runMonCommand runs the ceph command with the connection to the monitors. It returns false when the
+// command cannot run with librados, so it must run with the ceph CLI.
+func (c *CephToolCommand) runMonCommand() ([]byte, bool, error) {
+	if NewMonCommandConn == nil || c.tool != CephTool || c.RemoteExecution || RunAllCephCommandsInToolboxPod != "" {
+		return nil, false, nil
+	}
+
+	client, err := getMonCommandClient(c.context, c.clusterInfo)
+	if err != nil {
+		logger.Debugf("running ceph command with the ceph CLI. %v", err)
+		return nil, false, nil
+	}
+
+	format := "plain"
+	if c.JsonOutput {
+		format = "json"
+	}
+	command, err := buildMonCommand(client.descriptions, c.args, format)
+	if err != nil {
+		logger.Debugf("running ceph command with the ceph CLI. %v", err)
+		return nil, false, nil
+	}
+
+	type result struct {
+		output []byte
+		status string
+		err    error
+	}
+	var res result
+	if c.timeout == 0 {
+		res.output, res.status, res.err = client.conn.MonCommand(command)
+	} else {
+		done := make(chan result, 1)
+		go func() {
+			output, status, err := client.conn.MonCommand(command)
+			done <- result{output, status, err}
+		}()
+		select {
+		case res = <-done:
+		case <-time.After(c.timeout):
+			// the connection may be stuck, the next command reconnects. The connection is closed once
+			// the command returns, which the rados_mon_op_timeout bounds, since it cannot be shut down
+			// while the command is running.
+			if evictMonCommandConn(c.clusterInfo, client) {
+				go func() {
+					<-done
+					client.conn.Shutdown()
+				}()
+			}
+			return nil, true, errors.Errorf("timeout waiting for the command %s to return", CephTool)
+		}
+	}
+
+	if res.err != nil {
+		// the error codes are the exit codes of the ceph CLI
+		code := 1
+		if coded, ok := res.err.(interface{ ErrorCode() int }); ok {
+			code = -coded.ErrorCode()
+		} else {
+			// the connection is lost, reconnect at the next command
+			closeMonCommandConn(c.clusterInfo, client)
+		}
+		output := fmt.Sprintf("%s. %s", strings.TrimSpace(string(res.output)), res.status)
+		return []byte(output), true, &kexec.CodeExitError{Err: errors.Errorf("%s. %v", res.status, res.err), Code: code}
+	}
+	return res.output, true, nil
+}
+
+// parseMonCommandDescriptions parses the output of "get_command_descriptions"
+func parseMonCommandDescriptions(output []byte) ([]monCommandDescription, error) {
+	var raw map[string]struct {
+		Sig []json.RawMessage `json:"sig"`
+	}
+	if err := json.Unmarshal(output, &raw); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal the mon command descriptions")
+	}
+
+	// the descriptions are sorted to always pick the same command when several commands match
+	names := []string{}
+	for name := range raw {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	descriptions := []monCommandDescription{}
+	for _, name := range names {
+		command := raw[name]
+		description := monCommandDescription{}
+		for _, word := range command.Sig {
+			var literal string
+			if err := json.Unmarshal(word, &literal); err == nil {
+				description.prefix = append(description.prefix, literal)
+				continue
+			}
+			var fields map[string]interface{}
+			if err := json.Unmarshal(word, &fields); err != nil {
+				return nil, errors.Wrapf(err, "failed to unmarshal the mon command argument %q", string(word))
+			}
+			param := monCommandParam{
+				name:     fmt.Sprint(fields["name"]),
+				kind:     fmt.Sprint(fields["type"]),
+				multiple: fmt.Sprint(fields["n"]) == "N",
+				required: fields["req"] == nil || fmt.Sprint(fields["req"]) == "true",
+			}
+			if choices, ok := fields["strings"]; ok {
+				param.choices = strings.Split(fmt.Sprint(choices), "|")
+			}
+			description.params = append(description.params, param)
+		}
+		descriptions = append(descriptions, description)
+	}
+	return descriptions, nil
+}
+
+// buildMonCommand translates the arguments of the ceph CLI to the json of the mon command with the
+// longest matching prefix
+func buildMonCommand(descriptions []monCommandDescription, args []string, format string) ([]byte, error) {
+	var best map[string]interface{}
+	bestPrefix := 0
+	for _, description := range descriptions {
+		if len(description.prefix) <= bestPrefix {
+			continue
+		}
+		if command, ok := description.match(args); ok {
+			best = command
+			bestPrefix = len(description.prefix)
+		}
+	}
+	if best == nil {
+		return nil, errors.Errorf("no mon command matches the arguments %v", args)
+	}
+
+	if _, ok := best["format"]; !ok {
+		best["format"] = format
+	}
+	return json.Marshal(best)
+}
+
+// match returns the mon command of the arguments when they match the signature of the command
+func (d *monCommandDescription) match(args []string) (map[string]interface{}, bool) {
+	if len(args) < len(d.prefix) {
+		return nil, false
+	}
+	for i, word := range d.prefix {
+		if args[i] != word {
+			return nil, false
+		}
+	}
+	command := map[string]interface{}{"prefix": strings.Join(d.prefix, " ")}
+
+	// the flags such as "--yes-i-really-mean-it" are passed by name
+	positional := []string{}
+	named := map[string]string{}
+	rest := args[len(d.prefix):]
+	for i := 0; i < len(rest); i++ {
+		arg := rest[i]
+		// the input and output files are options of the ceph CLI
+		if arg == "-i" || arg == "-o" || arg == "--in-file" || arg == "--out-file" {
+			return nil, false
+		}
+		if arg == "--format" && i+1 < len(rest) {
+			command["format"] = rest[i+1]
+			i++
+			continue
+		}
+		if strings.HasPrefix(arg, "--format=") {
+			command["format"] = strings.TrimPrefix(arg, "--format=")
+			continue
+		}
+		if !strings.HasPrefix(arg, "--") || d.isChoice(arg) {
+			positional = append(positional, arg)
+			continue
+		}
+		name, value := strings.TrimPrefix(arg, "--"), "true"
+		if i := strings.Index(name, "="); i >= 0 {
+			name, value = name[:i], name[i+1:]
+		}
+		named[strings.ReplaceAll(name, "-", "_")] = value
+	}
+
+	for _, param := range d.params {
+		if value, ok := named[param.name]; ok {
+			converted, ok := param.convert(value)
+			if !ok {
+				return nil, false
+			}
+			command[param.name] = converted
+			delete(named, param.name)
+			continue
+		}
+		if len(positional) == 0 {
+			if param.required {
+				return nil, false
+			}
+			continue
+		}
+
+		if param.multiple {
+			values := []interface{}{}
+			for _, arg := range positional {
+				converted, ok := param.convert(arg)
+				if !ok {
+					return nil, false
+				}
+				values = append(values, converted)
+			}
+			command[param.name] = values
+			positional = nil
+			continue
+		}
+
+		converted, ok := param.convert(positional[0])
+		if !ok {
+			if param.required {
+				return nil, false
+			}
+			continue
+		}
+		command[param.name] = converted
+		positional = positional[1:]
+	}
+
+	// all the arguments must be used by the command
+	if len(positional) > 0 || len(named) > 0 {
+		return nil, false
+	}
+	return command, true
+}
+
+// isChoice returns whether the argument is one of the choices of a parameter, such as the
+// "--yes-i-really-mean-it" choice of the older ceph versions
+func (d *monCommandDescription) isChoice(arg string) bool {
+	for _, param := range d.params {
+		for _, choice := range param.choices {
+			if param.kind == "CephChoices" && choice == arg {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// convert converts an argument to the type of the parameter
+func (p *monCommandParam) convert(arg string) (interface{}, bool) {
+	switch p.kind {
+	case "CephInt":
+		value, err := strconv.Atoi(arg)
+		return value, err == nil
+	case "CephFloat":
+		value, err := strconv.ParseFloat(arg, 64)
+		return value, err == nil
+	case "CephBool":
+		value, err := strconv.ParseBool(arg)
+		return value, err == nil
+	case "CephChoices":
+		for _, choice := range p.choices {
+			if choice == arg {
+				return arg, true
+			}
+		}
+		return nil, false
+	}
+	return arg, true
+}
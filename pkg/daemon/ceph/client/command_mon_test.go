@@ -0,0 +1,245 @@
+/*
+Copyright 2021 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"encoding/json"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/rook/rook/pkg/clusterd"
+	"github.com/rook/rook/pkg/util/exec"
+	exectest "github.com/rook/rook/pkg/util/exec/test"
+	"github.com/stretchr/testify/assert"
+)
+
+const monCommandDescriptions = `{
+"cmd001": {"sig": ["status"], "help": "show cluster status", "module": "mon", "perm": "r"},
+"cmd002": {"sig": ["osd", "pool", "ls", {"name": "detail", "type": "CephChoices", "strings": "detail", "req": "false"}], "module": "osd", "perm": "r"},
+"cmd003": {"sig": ["osd", "pool", "set", {"name": "pool", "type": "CephPoolname"}, {"name": "var", "type": "CephChoices", "strings": "size|min_size|pg_num"},
+	{"name": "val", "type": "CephString"}, {"name": "yes_i_really_mean_it", "type": "CephBool", "req": "false"}], "module": "osd", "perm": "rw"},
+"cmd004": {"sig": ["osd", "out", {"name": "ids", "type": "CephString", "n": "N"}], "module": "osd", "perm": "rw"},
+"cmd005": {"sig": ["osd", "pool", "get-quota", {"name": "pool", "type": "CephPoolname"}], "module": "osd", "perm": "r"},
+"cmd006": {"sig": ["osd", "pool", "delete", {"name": "pool", "type": "CephPoolname"}, {"name": "pool2", "type": "CephPoolname", "req": "false"},
+	{"name": "sure", "type": "CephChoices", "strings": "--yes-i-really-really-mean-it", "req": "false"}], "module": "osd", "perm": "rw"},
+"cmd007": {"sig": ["osd", "set", {"name": "key", "type": "CephChoices", "strings": "noout|noscrub"}], "module": "osd", "perm": "rw"},
+"cmd008": {"sig": ["osd", "pool", "set-quota", {"name": "pool", "type": "CephPoolname"}, {"name": "field", "type": "CephChoices", "strings": "max_objects|max_bytes"},
+	{"name": "val", "type": "CephInt"}], "module": "osd", "perm": "rw"}
+}`
+
+// mockMonCommandConn mocks the connection to the monitors
+type mockMonCommandConn struct {
+	MockMonCommand func(args []byte) ([]byte, string, error)
+	MockShutdown   func()
+}
+
+func (c *mockMonCommandConn) MonCommand(args []byte) ([]byte, string, error) {
+	if string(args) == `{"prefix": "get_command_descriptions"}` {
+		return []byte(monCommandDescriptions), "", nil
+	}
+	if c.MockMonCommand != nil {
+		return c.MockMonCommand(args)
+	}
+	return nil, "", nil
+}
+
+func (c *mockMonCommandConn) Shutdown() {
+	if c.MockShutdown != nil {
+		c.MockShutdown()
+	}
+}
+
+type mockRadosError int
+
+func (e mockRadosError) Error() string {
+	return "rados error"
+}
+
+func (e mockRadosError) ErrorCode() int {
+	return int(e)
+}
+
+// useMockMonCommandConn runs the ceph commands with the mock connection until the test completes
+func useMockMonCommandConn(t testing.TB, conn *mockMonCommandConn) {
+	NewMonCommandConn = func(clusterInfo *ClusterInfo, configDir string) (MonCommandConn, error) {
+		return conn, nil
+	}
+	t.Cleanup(func() {
+		NewMonCommandConn = nil
+		CloseMonCommandConns("mycluster")
+	})
+}
+
+func TestBuildMonCommand(t *testing.T) {
+	descriptions, err := parseMonCommandDescriptions([]byte(monCommandDescriptions))
+	assert.NoError(t, err)
+	assert.Equal(t, 8, len(descriptions))
+
+	build := func(args ...string) map[string]interface{} {
+		output, err := buildMonCommand(descriptions, args, "json")
+		if err != nil {
+			return nil
+		}
+		command := map[string]interface{}{}
+		assert.NoError(t, json.Unmarshal(output, &command))
+		return command
+	}
+
+	assert.Equal(t, map[string]interface{}{"prefix": "status", "format": "json"}, build("status"))
+	assert.Equal(t, map[string]interface{}{"prefix": "status", "format": "json-pretty"}, build("status", "--format", "json-pretty"))
+	assert.Equal(t, map[string]interface{}{"prefix": "osd pool ls", "format": "json"}, build("osd", "pool", "ls"))
+	assert.Equal(t, map[string]interface{}{"prefix": "osd pool ls", "detail": "detail", "format": "json"}, build("osd", "pool", "ls", "detail"))
+	assert.Equal(t, map[string]interface{}{"prefix": "osd pool set", "pool": "rbd", "var": "size", "val": "1", "yes_i_really_mean_it": true, "format": "json"},
+		build("osd", "pool", "set", "rbd", "size", "1", "--yes-i-really-mean-it"))
+	assert.Equal(t, map[string]interface{}{"prefix": "osd out", "ids": []interface{}{"1", "2"}, "format": "json"}, build("osd", "out", "1", "2"))
+	assert.Equal(t, map[string]interface{}{"prefix": "osd pool delete", "pool": "rbd", "pool2": "rbd", "sure": "--yes-i-really-really-mean-it", "format": "json"},
+		build("osd", "pool", "delete", "rbd", "rbd", "--yes-i-really-really-mean-it"))
+	assert.Equal(t, map[string]interface{}{"prefix": "osd pool set-quota", "pool": "rbd", "field": "max_objects", "val": float64(10), "format": "json"},
+		build("osd", "pool", "set-quota", "rbd", "max_objects", "10"))
+
+	// the arguments that do not match a command run with the ceph CLI
+	assert.Nil(t, build("tell", "osd.0", "bench"))
+	assert.Nil(t, build("osd", "pool", "get-quota"))
+	assert.Nil(t, build("osd", "pool", "ls", "detail", "extra"))
+	assert.Nil(t, build("osd", "set", "unknown"))
+	assert.Nil(t, build("osd", "pool", "set-quota", "rbd", "max_objects", "ten"))
+	assert.Nil(t, build("osd", "pool", "set", "rbd", "size", "1", "--unknown-flag"))
+	assert.Nil(t, build("osd", "setcrushmap", "-i", "/tmp/crushmap"))
+}
+
+func TestRunMonCommand(t *testing.T) {
+	executor := &exectest.MockExecutor{
+		MockExecuteCommandWithOutput: func(command string, args ...string) (string, error) {
+			return "cli", nil
+		},
+	}
+	context := &clusterd.Context{Executor: executor}
+	clusterInfo := AdminClusterInfo("mycluster")
+
+	// the commands run with the ceph CLI without librados
+	output, err := NewCephCommand(context, clusterInfo, []string{"status"}).Run()
+	assert.NoError(t, err)
+	assert.Equal(t, "cli", string(output))
+
+	var command []byte
+	conn := &mockMonCommandConn{
+		MockMonCommand: func(args []byte) ([]byte, string, error) {
+			command = args
+			if string(args) == `{"format":"json","pool":"missing","prefix":"osd pool get-quota"}` {
+				return nil, "pool 'missing' does not exist", mockRadosError(-int(syscall.ENOENT))
+			}
+			return []byte(`{"health":"HEALTH_OK"}`), "", nil
+		},
+	}
+	useMockMonCommandConn(t, conn)
+
+	output, err = NewCephCommand(context, clusterInfo, []string{"status"}).Run()
+	assert.NoError(t, err)
+	assert.Equal(t, `{"health":"HEALTH_OK"}`, string(output))
+	assert.Equal(t, `{"format":"json","prefix":"status"}`, string(command))
+
+	// the errors have the exit code of the ceph CLI
+	_, err = NewCephCommand(context, clusterInfo, []string{"osd", "pool", "get-quota", "missing"}).Run()
+	assert.Error(t, err)
+	code, ok := exec.ExitStatus(err)
+	assert.True(t, ok)
+	assert.Equal(t, int(syscall.ENOENT), code)
+
+	// the commands that are not mon commands run with the ceph CLI
+	output, err = NewCephCommand(context, clusterInfo, []string{"tell", "osd.0", "bench"}).Run()
+	assert.NoError(t, err)
+	assert.Equal(t, "cli", string(output))
+
+	// the rbd commands always run with the rbd CLI
+	output, err = NewRBDCommand(context, clusterInfo, []string{"ls"}).Run()
+	assert.NoError(t, err)
+	assert.Equal(t, "cli", string(output))
+
+	// the connection is closed when the connection to the monitors fails
+	conn.MockMonCommand = func(args []byte) ([]byte, string, error) {
+		return nil, "", errors.New("not connected")
+	}
+	_, err = NewCephCommand(context, clusterInfo, []string{"status"}).Run()
+	assert.Error(t, err)
+	assert.Empty(t, monCommandConns.conns)
+
+	// the connection is evicted when the command times out, and closed once the command returns
+	release := make(chan struct{})
+	shutdown := make(chan struct{})
+	conn.MockMonCommand = func(args []byte) ([]byte, string, error) {
+		<-release
+		return nil, "", nil
+	}
+	conn.MockShutdown = func() { close(shutdown) }
+	_, err = NewCephCommand(context, clusterInfo, []string{"status"}).RunWithTimeout(10 * time.Millisecond)
+	assert.Error(t, err)
+	assert.Empty(t, monCommandConns.conns)
+	select {
+	case <-shutdown:
+		t.Fatal("the connection was closed while the command is running")
+	default:
+	}
+	close(release)
+	select {
+	case <-shutdown:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the connection was not closed")
+	}
+	conn.MockShutdown = nil
+}
+
+func BenchmarkCephCommandExec(b *testing.B) {
+	executor := &exectest.MockExecutor{
+		MockExecuteCommandWithOutput: func(command string, args ...string) (string, error) {
+			return `{"health":"HEALTH_OK"}`, nil
+		},
+	}
+	context := &clusterd.Context{Executor: executor}
+	clusterInfo := AdminClusterInfo("mycluster")
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := NewCephCommand(context, clusterInfo, []string{"osd", "pool", "set", "rbd", "size", "3", "--yes-i-really-mean-it"}).Run(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkCephCommandLibrados(b *testing.B) {
+	executor := &exectest.MockExecutor{
+		MockExecuteCommandWithOutput: func(command string, args ...string) (string, error) {
+			b.Fatalf("unexpected ceph CLI command %v", args)
+			return "", nil
+		},
+	}
+	context := &clusterd.Context{Executor: executor}
+	clusterInfo := AdminClusterInfo("mycluster")
+	useMockMonCommandConn(b, &mockMonCommandConn{
+		MockMonCommand: func(args []byte) ([]byte, string, error) {
+			return []byte(`{"health":"HEALTH_OK"}`), "", nil
+		},
+	})
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := NewCephCommand(context, clusterInfo, []string{"osd", "pool", "set", "rbd", "size", "3", "--yes-i-really-mean-it"}).Run(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -28,6 +28,7 @@ import (
 	"github.com/rook/rook/pkg/util/exec"
 	exectest "github.com/rook/rook/pkg/util/exec/test"
 	"github.com/stretchr/testify/assert"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
 )
 
 func TestFinalizeCephCommandArgs(t *testing.T) {
@@ -158,3 +159,56 @@ func TestNewRBDCommand(t *testing.T) {
 	})
 
 }
+
+// commandCount returns the number of commands recorded in the metrics of the operator with the labels
+func commandCount(t *testing.T, labels map[string]string) uint64 {
+	families, err := metrics.Registry.Gather()
+	assert.NoError(t, err)
+	for _, family := range families {
+		if family.GetName() != "rook_ceph_command_duration_seconds" {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			matched := 0
+			for _, label := range metric.GetLabel() {
+				if labels[label.GetName()] == label.GetValue() {
+					matched++
+				}
+			}
+			if matched == len(labels) {
+				return metric.GetHistogram().GetSampleCount()
+			}
+		}
+	}
+	return 0
+}
+
+func TestCephCommandAudit(t *testing.T) {
+	// the test is not part of a controller
+	statusLabels := map[string]string{"command": CephTool, "prefix": "status", "caller": "unknown", "result": "0"}
+	timeoutLabels := map[string]string{"command": CephTool, "prefix": "osd pool create", "caller": "unknown", "result": exec.CommandResultTimeout}
+	rbdLabels := map[string]string{"command": RBDTool, "prefix": "pool stats", "caller": "unknown", "result": "0"}
+	statusCount, timeoutCount, rbdCount := commandCount(t, statusLabels), commandCount(t, timeoutLabels), commandCount(t, rbdLabels)
+
+	executor := &exectest.MockExecutor{
+		MockExecuteCommandWithOutput: func(command string, args ...string) (string, error) {
+			if args[0] == "osd" {
+				return "", errors.New("timeout waiting for the command ceph to return")
+			}
+			return "", nil
+		},
+	}
+	context := &clusterd.Context{Executor: executor}
+	clusterInfo := AdminClusterInfo("mycluster")
+
+	_, err := NewCephCommand(context, clusterInfo, []string{"status"}).Run()
+	assert.NoError(t, err)
+	_, err = NewCephCommand(context, clusterInfo, []string{"osd", "pool", "create", "replicapool"}).Run()
+	assert.Error(t, err)
+	_, err = NewRBDCommand(context, clusterInfo, []string{"pool", "stats", "replicapool"}).Run()
+	assert.NoError(t, err)
+
+	assert.Equal(t, statusCount+1, commandCount(t, statusLabels))
+	assert.Equal(t, timeoutCount+1, commandCount(t, timeoutLabels))
+	assert.Equal(t, rbdCount+1, commandCount(t, rbdLabels))
+}
@@ -20,6 +20,10 @@ import (
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 
@@ -32,6 +36,9 @@ const (
 	CrushRootConfigKey = "crushRoot"
 )
 
+// the types of the decompiled crush map
+var decompiledTypeRegex = regexp.MustCompile(`^type (-?\d+) (\S+)$`)
+
 // CrushMap is the go representation of a CRUSH map
 type CrushMap struct {
 	Devices []struct {
@@ -261,3 +268,149 @@ func buildDecompileCRUSHFileName(crushMapPath string) string {
 func buildCompileCRUSHFileName(crushMapPath string) string {
 	return fmt.Sprintf("%s.compiled", crushMapPath)
 }
+
+// MoveCrushBucket moves a bucket and its items to a location of the crush hierarchy such as
+// "root=default rack=rack1". The buckets of the location are created if they do not exist.
+func MoveCrushBucket(context *clusterd.Context, clusterInfo *ClusterInfo, name, location string) error {
+	args := append([]string{"osd", "crush", "move", name}, strings.Fields(location)...)
+	buf, err := NewCephCommand(context, clusterInfo, args).Run()
+	if err != nil {
+		return errors.Wrapf(err, "failed to move crush bucket %q to %q. %s", name, location, string(buf))
+	}
+
+	return nil
+}
+
+// AddCrushTypes adds the custom types of the crush topology to the crush map. Ceph places the buckets
+// of a location in the order of the ids of their types, so a custom type is inserted right above its
+// type and the ids of the types above it are incremented.
+func AddCrushTypes(context *clusterd.Context, clusterInfo *ClusterInfo, crushTopology []cephv1.CrushTopologyLabel) error {
+	custom := []cephv1.CrushTopologyLabel{}
+	for _, topology := range crushTopology {
+		if topology.IsCustomType() {
+			custom = append(custom, topology)
+		}
+	}
+	if len(custom) == 0 {
+		return nil
+	}
+
+	err := editCrushMap(context, clusterInfo, func(lines []string) ([]string, error) {
+		return addDecompiledCrushTypes(lines, custom)
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to add custom crush types")
+	}
+	return nil
+}
+
+type crushType struct {
+	id   int
+	name string
+}
+
+// addDecompiledCrushTypes inserts the missing custom types in the types of the decompiled crush map,
+// and returns no lines if all the types exist
+func addDecompiledCrushTypes(lines []string, custom []cephv1.CrushTopologyLabel) ([]string, error) {
+	first, last := -1, -1
+	types := []crushType{}
+	for i, line := range lines {
+		match := decompiledTypeRegex.FindStringSubmatch(line)
+		if match == nil {
+			continue
+		}
+		id, err := strconv.Atoi(match[1])
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid crush type %q", line)
+		}
+		if first < 0 {
+			first = i
+		}
+		last = i
+		types = append(types, crushType{id: id, name: match[2]})
+	}
+	sort.Slice(types, func(i, j int) bool { return types[i].id < types[j].id })
+
+	added := false
+	for _, topology := range custom {
+		below := -1
+		exists := false
+		for i, t := range types {
+			if t.name == topology.Type {
+				exists = true
+			}
+			if t.name == topology.GetAbove() {
+				below = i
+			}
+		}
+		if exists {
+			continue
+		}
+		if below < 0 {
+			return nil, errors.Errorf("crush type %q below the custom type %q not found", topology.GetAbove(), topology.Type)
+		}
+
+		logger.Infof("adding crush type %q above %q", topology.Type, topology.GetAbove())
+		id := types[below].id + 1
+		for i := below + 1; i < len(types); i++ {
+			types[i].id++
+		}
+		types = append(types[:below+1], append([]crushType{{id: id, name: topology.Type}}, types[below+1:]...)...)
+		added = true
+	}
+	if !added {
+		return nil, nil
+	}
+
+	typeLines := []string{}
+	for _, t := range types {
+		typeLines = append(typeLines, fmt.Sprintf("type %d %s", t.id, t.name))
+	}
+	return append(lines[:first], append(typeLines, lines[last+1:]...)...), nil
+}
+
+// editCrushMap decompiles the crush map and injects it again once compiled with the lines returned by
+// edit. The crush map is not injected if edit returns no lines.
+func editCrushMap(context *clusterd.Context, clusterInfo *ClusterInfo, edit func(lines []string) ([]string, error)) error {
+	compiledCRUSHMapFilePath, err := GetCompiledCrushMap(context, clusterInfo)
+	if err != nil {
+		return errors.Wrap(err, "failed to get crush map")
+	}
+	defer removeCrushMapFile(compiledCRUSHMapFilePath)
+
+	err = decompileCRUSHMap(context, compiledCRUSHMapFilePath)
+	if err != nil {
+		return errors.Wrap(err, "failed to decompile crush map")
+	}
+	decompiledCRUSHMapFilePath := buildDecompileCRUSHFileName(compiledCRUSHMapFilePath)
+	defer removeCrushMapFile(decompiledCRUSHMapFilePath)
+
+	decompiled, err := ioutil.ReadFile(filepath.Clean(decompiledCRUSHMapFilePath))
+	if err != nil {
+		return errors.Wrapf(err, "failed to read decompiled crush map %q", decompiledCRUSHMapFilePath)
+	}
+
+	lines, err := edit(strings.Split(string(decompiled), "\n"))
+	if err != nil || lines == nil {
+		return err
+	}
+
+	err = ioutil.WriteFile(decompiledCRUSHMapFilePath, []byte(strings.Join(lines, "\n")), 0600)
+	if err != nil {
+		return errors.Wrapf(err, "failed to write decompiled crush map %q", decompiledCRUSHMapFilePath)
+	}
+
+	err = compileCRUSHMap(context, decompiledCRUSHMapFilePath)
+	if err != nil {
+		return err
+	}
+	defer removeCrushMapFile(buildCompileCRUSHFileName(decompiledCRUSHMapFilePath))
+
+	return injectCRUSHMap(context, clusterInfo, buildCompileCRUSHFileName(decompiledCRUSHMapFilePath))
+}
+
+func removeCrushMapFile(path string) {
+	if err := os.Remove(path); err != nil {
+		logger.Errorf("failed to remove file %q. %v", path, err)
+	}
+}
@@ -18,12 +18,18 @@ package client
 
 import (
 	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
 
+	"github.com/pkg/errors"
 	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	"github.com/rook/rook/pkg/clusterd"
 )
 
 const (
 	crushReplicatedType      = 1
+	crushErasureType         = 3
 	ruleMinSizeDefault       = 1
 	ruleMaxSizeDefault       = 10
 	twoStepCRUSHRuleTemplate = `
@@ -56,6 +62,9 @@ rule %s {
 
 var (
 	stepEmit = &stepSpec{Operation: "emit"}
+
+	// the first line of a rule of a decompiled crush map
+	decompiledRuleRegex = regexp.MustCompile(`^rule (\S+) \{$`)
 )
 
 func buildTwoStepPlainCrushRule(crushMap CrushMap, ruleName string, pool cephv1.PoolSpec) string {
@@ -180,3 +189,142 @@ func checkIfRuleIDExists(rules []ruleSpec, ID int) bool {
 
 	return false
 }
+
+// ApplyCrushRule creates or updates the crush rule from its steps and returns the id of the rule. The
+// decompiled crush map is compiled again with the rule, which fails if the rule is invalid, before it
+// is injected.
+func ApplyCrushRule(context *clusterd.Context, clusterInfo *ClusterInfo, name string, spec cephv1.CrushRuleSpec) (int, error) {
+	ruleID := 0
+	err := editCrushMap(context, clusterInfo, func(lines []string) ([]string, error) {
+		var start, end int
+		start, end, ruleID = findDecompiledCrushRule(lines, name)
+		rule := buildCrushRule(name, ruleID, spec)
+		if start < 0 {
+			logger.Infof("creating crush rule %q", name)
+			return append(lines, rule), nil
+		}
+		if crushRuleContent(lines[start:end+1]) == crushRuleContent(strings.Split(rule, "\n")) {
+			logger.Debugf("crush rule %q is up to date", name)
+			return nil, nil
+		}
+		logger.Infof("updating crush rule %q", name)
+		return append(lines[:start], append([]string{rule}, lines[end+1:]...)...), nil
+	})
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to apply crush rule %q", name)
+	}
+
+	return ruleID, nil
+}
+
+// DeleteCrushRule removes a crush rule, which fails if a pool still uses the rule
+func DeleteCrushRule(context *clusterd.Context, clusterInfo *ClusterInfo, name string) error {
+	args := []string{"osd", "crush", "rule", "rm", name}
+	output, err := NewCephCommand(context, clusterInfo, args).Run()
+	if err != nil {
+		return errors.Wrapf(err, "failed to delete crush rule %q. %s", name, string(output))
+	}
+
+	return nil
+}
+
+func buildCrushRule(name string, ruleID int, spec cephv1.CrushRuleSpec) string {
+	rule := []string{
+		fmt.Sprintf("rule %s {", name),
+		fmt.Sprintf("        id %d", ruleID),
+		fmt.Sprintf("        type %s", spec.GetType()),
+		fmt.Sprintf("        min_size %d", ruleMinSizeDefault),
+		fmt.Sprintf("        max_size %d", ruleMaxSizeDefault),
+	}
+	if spec.GetType() == cephv1.CrushRuleTypeErasure {
+		// the same tries as the rules created by ceph for the erasure coded pools
+		rule = append(rule, "        step set_chooseleaf_tries 5", "        step set_choose_tries 100")
+	}
+
+	for _, step := range spec.Steps {
+		switch step.Op {
+		case cephv1.CrushRuleStepTake:
+			if step.DeviceClass != "" {
+				rule = append(rule, fmt.Sprintf("        step take %s class %s", step.Item, step.DeviceClass))
+			} else {
+				rule = append(rule, fmt.Sprintf("        step take %s", step.Item))
+			}
+		case cephv1.CrushRuleStepChoose, cephv1.CrushRuleStepChooseLeaf:
+			rule = append(rule, fmt.Sprintf("        step %s %s %d type %s", step.Op, spec.GetMode(step), step.Count, step.Type))
+		default:
+			rule = append(rule, fmt.Sprintf("        step %s", step.Op))
+		}
+	}
+
+	return strings.Join(append(rule, "}"), "\n")
+}
+
+// findDecompiledCrushRule returns the first and last lines of the rule in the decompiled crush map, or
+// -1 if the rule does not exist, and the id of the rule, or the next free id if the rule does not exist
+func findDecompiledCrushRule(lines []string, name string) (int, int, int) {
+	start, end, ruleID := -1, -1, -1
+	maxID := -1
+	inRule := false
+	for i, line := range lines {
+		line = strings.TrimSpace(line)
+		if match := decompiledRuleRegex.FindStringSubmatch(line); match != nil {
+			inRule = true
+			if match[1] == name {
+				start = i
+			}
+			continue
+		}
+		if !inRule {
+			continue
+		}
+		if strings.HasPrefix(line, "id ") {
+			id, err := strconv.Atoi(strings.TrimPrefix(line, "id "))
+			if err == nil {
+				if id > maxID {
+					maxID = id
+				}
+				if start >= 0 && end < 0 {
+					ruleID = id
+				}
+			}
+		}
+		if line == "}" {
+			inRule = false
+			if start >= 0 && end < 0 {
+				end = i
+			}
+		}
+	}
+
+	if start < 0 || end < 0 {
+		return -1, -1, maxID + 1
+	}
+	return start, end, ruleID
+}
+
+// crushRuleContent returns the type and the steps of a rule, which are the settings of the rule that
+// are used by ceph
+func crushRuleContent(lines []string) string {
+	content := []string{}
+	for _, line := range lines {
+		line = strings.Join(strings.Fields(line), " ")
+		if strings.HasPrefix(line, "type ") || strings.HasPrefix(line, "step ") {
+			content = append(content, line)
+		}
+	}
+	return strings.Join(content, "\n")
+}
+
+// GetCrushRuleType returns the type of a rule of the crush map, and whether the rule exists
+func GetCrushRuleType(crushMap CrushMap, name string) (cephv1.CrushRuleType, bool) {
+	for _, rule := range crushMap.Rules {
+		if rule.Name != name {
+			continue
+		}
+		if rule.Type == crushErasureType {
+			return cephv1.CrushRuleTypeErasure, true
+		}
+		return cephv1.CrushRuleTypeReplicated, true
+	}
+	return "", false
+}
@@ -18,6 +18,8 @@ package client
 
 import (
 	"encoding/json"
+	"io/ioutil"
+	"strings"
 	"testing"
 
 	"github.com/pkg/errors"
@@ -135,3 +137,136 @@ func Test_generateRuleID(t *testing.T) {
 		})
 	}
 }
+
+const testDecompiledCrushMap = `# begin crush map
+tunable choose_total_tries 50
+
+# types
+type 0 osd
+type 1 host
+type 11 root
+
+# buckets
+root default {
+	id -1		# do not change unnecessarily
+	alg straw2
+	hash 0	# rjenkins1
+}
+
+# rules
+rule replicated_rule {
+	id 0
+	type replicated
+	min_size 1
+	max_size 10
+	step take default
+	step chooseleaf firstn 0 type host
+	step emit
+}
+rule ec {
+	id 3
+	type erasure
+	min_size 3
+	max_size 3
+	step set_chooseleaf_tries 5
+	step set_choose_tries 100
+	step take default class hdd
+	step chooseleaf indep 0 type host
+	step emit
+}
+
+# end crush map
+`
+
+func TestApplyCrushRule(t *testing.T) {
+	compiled := ""
+	injected := false
+	executor := &exectest.MockExecutor{}
+	executor.MockExecuteCommandWithOutput = func(command string, args ...string) (string, error) {
+		logger.Infof("Command: %s %v", command, args)
+		if command == "crushtool" && args[0] == "--decompile" {
+			return "", ioutil.WriteFile(args[3], []byte(testDecompiledCrushMap), 0600)
+		}
+		if command == "crushtool" && args[0] == "--compile" {
+			content, err := ioutil.ReadFile(args[1])
+			compiled = string(content)
+			return "", err
+		}
+		if args[0] == "osd" && args[1] == "getcrushmap" {
+			return "", nil
+		}
+		if args[0] == "osd" && args[1] == "setcrushmap" {
+			injected = true
+			return "", nil
+		}
+		return "", errors.Errorf("unexpected ceph command '%v'", args)
+	}
+	context := &clusterd.Context{Executor: executor}
+
+	spec := cephv1.CrushRuleSpec{Steps: []cephv1.CrushRuleStep{
+		{Op: cephv1.CrushRuleStepTake, Item: "default", DeviceClass: "ssd"},
+		{Op: cephv1.CrushRuleStepChooseLeaf, Type: "host"},
+		{Op: cephv1.CrushRuleStepEmit},
+	}}
+
+	// a new rule is appended with the next id
+	id, err := ApplyCrushRule(context, AdminClusterInfo("mycluster"), "fast", spec)
+	assert.NoError(t, err)
+	assert.Equal(t, 4, id)
+	assert.True(t, injected)
+	assert.True(t, strings.HasPrefix(compiled, testDecompiledCrushMap))
+	assert.Contains(t, compiled, "rule fast {\n        id 4\n        type replicated\n")
+	assert.Contains(t, compiled, "        step take default class ssd\n        step chooseleaf firstn 0 type host\n        step emit\n}")
+
+	// the rule is not injected again if it did not change
+	injected = false
+	spec.Type = cephv1.CrushRuleTypeErasure
+	spec.Steps[0].DeviceClass = "hdd"
+	id, err = ApplyCrushRule(context, AdminClusterInfo("mycluster"), "ec", spec)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, id)
+	assert.False(t, injected)
+
+	// an existing rule is replaced and keeps its id
+	spec.Steps[1].Count = 2
+	id, err = ApplyCrushRule(context, AdminClusterInfo("mycluster"), "ec", spec)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, id)
+	assert.True(t, injected)
+	assert.Contains(t, compiled, "rule ec {\n        id 3\n        type erasure\n        min_size 1\n        max_size 10\n        step set_chooseleaf_tries 5\n")
+	assert.Contains(t, compiled, "step chooseleaf indep 2 type host\n        step emit\n}\n\n# end crush map")
+	assert.Equal(t, 1, strings.Count(compiled, "rule ec {"))
+	assert.Contains(t, compiled, "rule replicated_rule {")
+
+	// the invalid rules are not injected
+	injected = false
+	executor.MockExecuteCommandWithOutput = func(command string, args ...string) (string, error) {
+		if command == "crushtool" && args[0] == "--decompile" {
+			return "", ioutil.WriteFile(args[3], []byte(testDecompiledCrushMap), 0600)
+		}
+		if command == "crushtool" && args[0] == "--compile" {
+			return "in rule 'fast' item 'unknown' not defined", errors.New("exit status 1")
+		}
+		if args[0] == "osd" && args[1] == "setcrushmap" {
+			injected = true
+		}
+		return "", nil
+	}
+	spec.Steps[0].Item = "unknown"
+	_, err = ApplyCrushRule(context, AdminClusterInfo("mycluster"), "fast", spec)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "not defined")
+	assert.False(t, injected)
+}
+
+func TestGetCrushRuleType(t *testing.T) {
+	var crushMap CrushMap
+	err := json.Unmarshal([]byte(testCrushMap), &crushMap)
+	assert.NoError(t, err)
+
+	ruleType, found := GetCrushRuleType(crushMap, "replicated_ruleset")
+	assert.True(t, found)
+	assert.Equal(t, cephv1.CrushRuleTypeReplicated, ruleType)
+	_, found = GetCrushRuleType(crushMap, "unknown")
+	assert.False(t, found)
+}
@@ -17,9 +17,11 @@ package client
 
 import (
 	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/pkg/errors"
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
 	"github.com/rook/rook/pkg/clusterd"
 	exectest "github.com/rook/rook/pkg/util/exec/test"
 	"github.com/stretchr/testify/assert"
@@ -335,3 +337,63 @@ func TestBuildCompiledDecompileCRUSHFileName(t *testing.T) {
 	assert.Equal(t, "/tmp/06399022.decompiled", buildDecompileCRUSHFileName("/tmp/06399022"))
 	assert.Equal(t, "/tmp/06399022.compiled", buildCompileCRUSHFileName("/tmp/06399022"))
 }
+
+func TestAddDecompiledCrushTypes(t *testing.T) {
+	lines := strings.Split(`# types
+type 0 osd
+type 1 host
+type 2 rack
+type 3 room
+type 11 root
+
+# buckets
+host node1 {
+	id -2
+}`, "\n")
+
+	custom := []cephv1.CrushTopologyLabel{
+		{Label: "example.com/building", Type: "building", Above: "room"},
+		{Label: "example.com/campus", Type: "campus", Above: "building"},
+		{Label: "example.com/shelf", Type: "shelf"},
+	}
+	updated, err := addDecompiledCrushTypes(lines, custom)
+	assert.NoError(t, err)
+	assert.Equal(t, `# types
+type 0 osd
+type 1 host
+type 2 shelf
+type 3 rack
+type 4 room
+type 5 building
+type 6 campus
+type 14 root
+
+# buckets
+host node1 {
+	id -2
+}`, strings.Join(updated, "\n"))
+
+	// the crush map is not updated once the types exist
+	updated, err = addDecompiledCrushTypes(updated, custom)
+	assert.NoError(t, err)
+	assert.Nil(t, updated)
+
+	// the type below the custom type must exist
+	_, err = addDecompiledCrushTypes(lines, []cephv1.CrushTopologyLabel{{Label: "example.com/building", Type: "building", Above: "datacenter"}})
+	assert.Error(t, err)
+}
+
+func TestMoveCrushBucket(t *testing.T) {
+	executor := &exectest.MockExecutor{}
+	executor.MockExecuteCommandWithOutput = func(command string, args ...string) (string, error) {
+		logger.Infof("Command: %s %v", command, args)
+		if args[0] == "osd" && args[1] == "crush" && args[2] == "move" {
+			assert.Equal(t, []string{"node1", "root=default", "rack=rack1"}, args[3:6])
+			return "", nil
+		}
+		return "", errors.Errorf("unexpected ceph command '%v'", args)
+	}
+
+	err := MoveCrushBucket(&clusterd.Context{Executor: executor}, AdminClusterInfo("mycluster"), "node1", "root=default rack=rack1")
+	assert.NoError(t, err)
+}
@@ -146,6 +146,10 @@ func GetSnapshotScheduleStatus(context *clusterd.Context, clusterInfo *ClusterIn
 	// Run command
 	output, err := cmd.Run()
 	if err != nil {
+		// the command fails if no path of the filesystem has a schedule
+		if code, ok := exec.ExitStatus(err); ok && code == int(syscall.ENOENT) {
+			return []cephv1.FilesystemSnapshotSchedulesSpec{}, nil
+		}
 		return nil, errors.Wrapf(err, "failed to retrieve snapshot schedule status for ceph filesystem %q. %s", filesystem, output)
 	}
 
@@ -157,14 +161,82 @@ func GetSnapshotScheduleStatus(context *clusterd.Context, clusterInfo *ClusterIn
 
 	[{"fs": "myfs", "subvol": null, "path": "/", "rel_path": "/", "schedule": "24h", "retention": {"h": 24}, "start": "2021-07-01T00:00:00", "created": "2021-07-01T12:19:12", "first": null, "last": null, "last_pruned": null, "created_count": 0, "pruned_count": 0, "active": true},{"fs": "myfs", "subvol": null, "path": "/", "rel_path": "/", "schedule": "25h", "retention": {"h": 24}, "start": "2021-07-01T00:00:00", "created": "2021-07-01T12:31:25", "first": null, "last": null, "last_pruned": null, "created_count": 0, "pruned_count": 0, "active": true}]
 	*/
-	if err := json.Unmarshal([]byte(strings.ReplaceAll(string(output), "\n", "")), &filesystemSnapshotSchedulesStatusSpec); err != nil {
+	if strings.TrimSpace(string(output)) == "" {
+		return filesystemSnapshotSchedulesStatusSpec, nil
+	}
+	trimmed := []byte(strings.ReplaceAll(string(output), "\n", ""))
+	if err := json.Unmarshal(trimmed, &filesystemSnapshotSchedulesStatusSpec); err != nil {
 		return nil, errors.Wrap(err, "failed to unmarshal filesystem mirror snapshot schedule status response")
 	}
 
+	// The retention is reported as the count of snapshots by period, which is set in the
+	// retention counts since the retention field of the status has another layout
+	var retentions []struct {
+		Retention map[string]int `json:"retention"`
+	}
+	if err := json.Unmarshal(trimmed, &retentions); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal filesystem snapshot schedule retention")
+	}
+	for i := range filesystemSnapshotSchedulesStatusSpec {
+		filesystemSnapshotSchedulesStatusSpec[i].RetentionCounts = retentions[i].Retention
+	}
+
 	logger.Infof("successfully retrieved snapshot schedule status for ceph filesystem %q", filesystem)
 	return filesystemSnapshotSchedulesStatusSpec, nil
 }
 
+// RemoveSnapshotSchedule removes the snapshot schedule of a path with the given interval and start time
+func RemoveSnapshotSchedule(context *clusterd.Context, clusterInfo *ClusterInfo, path, interval, startTime, filesystem string) error {
+	logger.Infof("removing snapshot schedule every %q from ceph filesystem %q on path %q", interval, filesystem, path)
+
+	// Example command: "ceph fs snap-schedule remove / 4d fs=myfs2"
+	// All the schedules of the path are removed if the interval is empty
+	args := []string{"fs", "snap-schedule", "remove", path}
+	if interval != "" {
+		args = append(args, interval)
+		if startTime != "" {
+			args = append(args, startTime)
+		}
+	}
+	args = append(args, fmt.Sprintf("fs=%s", filesystem))
+	cmd := NewCephCommand(context, clusterInfo, args)
+	cmd.JsonOutput = false
+
+	output, err := cmd.Run()
+	if err != nil {
+		if code, ok := exec.ExitStatus(err); ok && code == int(syscall.ENOENT) {
+			logger.Debugf("snapshot schedule every %q does not exist for filesystem %q on path %q", interval, filesystem, path)
+			return nil
+		}
+		return errors.Wrapf(err, "failed to remove snapshot schedule every %q from ceph filesystem %q on path %q. %s", interval, filesystem, path, output)
+	}
+
+	logger.Infof("successfully removed snapshot schedule every %q from ceph filesystem %q on path %q", interval, filesystem, path)
+	return nil
+}
+
+// RemoveSnapshotScheduleRetention removes a count and period pair from the retention policy of a path
+func RemoveSnapshotScheduleRetention(context *clusterd.Context, clusterInfo *ClusterInfo, path, duration, filesystem string) error {
+	logger.Infof("removing snapshot schedule retention %s from ceph filesystem %q on path %q", duration, filesystem, path)
+
+	// Example command: "ceph fs snap-schedule retention remove / 24h fs=myfs2"
+	args := []string{"fs", "snap-schedule", "retention", "remove", path, duration, fmt.Sprintf("fs=%s", filesystem)}
+	cmd := NewCephCommand(context, clusterInfo, args)
+	cmd.JsonOutput = false
+
+	output, err := cmd.Run()
+	if err != nil {
+		if code, ok := exec.ExitStatus(err); ok && code == int(syscall.ENOENT) {
+			logger.Debugf("snapshot schedule retention %s does not exist for filesystem %q on path %q", duration, filesystem, path)
+			return nil
+		}
+		return errors.Wrapf(err, "failed to remove snapshot schedule retention %s from ceph filesystem %q on path %q. %s", duration, filesystem, path, output)
+	}
+
+	logger.Infof("successfully removed snapshot schedule retention %s from ceph filesystem %q on path %q", duration, filesystem, path)
+	return nil
+}
+
 // ImportFSMirrorBootstrapPeer add a mirror peer in the cephfs-mirror configuration
 func ImportFSMirrorBootstrapPeer(context *clusterd.Context, clusterInfo *ClusterInfo, fsName, token string) error {
 	logger.Infof("importing cephfs bootstrap peer token for filesystem %q", fsName)
@@ -159,3 +159,41 @@ func TestFSMirrorDaemonStatus(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, "myfs", s[0].Filesystems[0].Name)
 }
+
+func TestSnapshotSchedules(t *testing.T) {
+	executor := &exectest.MockExecutor{}
+	context := &clusterd.Context{Executor: executor}
+	var lastArgs []string
+	executor.MockExecuteCommandWithOutput = func(command string, args ...string) (string, error) {
+		if args[0] == "fs" && args[1] == "snap-schedule" {
+			lastArgs = args
+			if args[2] == "status" {
+				return "\n" + `[{"fs": "myfs", "subvol": null, "path": "/", "rel_path": "/", "schedule": "24h", "retention": {"h": 24, "d": 7}, "start": "2021-07-01T00:00:00", "created": "2021-07-01T12:19:12", "first": "2021-07-02T00:00:00", "last": "2021-07-03T00:00:00", "last_pruned": null, "created_count": 2, "pruned_count": 0, "active": true}]`, nil
+			}
+			return "", nil
+		}
+		return "", errors.New("unknown command")
+	}
+
+	status, err := GetSnapshotScheduleStatus(context, AdminClusterInfo("mycluster"), "myfs")
+	assert.NoError(t, err)
+	assert.Equal(t, "/", status[0].Path)
+	assert.Equal(t, "24h", status[0].Schedule)
+	assert.Equal(t, "2021-07-01T00:00:00", status[0].Start)
+	assert.Equal(t, map[string]int{"h": 24, "d": 7}, status[0].RetentionCounts)
+	assert.Equal(t, "2021-07-03T00:00:00", status[0].Last)
+	assert.True(t, status[0].Active)
+
+	err = RemoveSnapshotSchedule(context, AdminClusterInfo("mycluster"), "/", "24h", "2021-07-01T00:00:00", "myfs")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"remove", "/", "24h", "2021-07-01T00:00:00", "fs=myfs"}, lastArgs[2:7])
+
+	// all the schedules of the path
+	err = RemoveSnapshotSchedule(context, AdminClusterInfo("mycluster"), "/data", "", "", "myfs")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"remove", "/data", "fs=myfs"}, lastArgs[2:5])
+
+	err = RemoveSnapshotScheduleRetention(context, AdminClusterInfo("mycluster"), "/", "7d", "myfs")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"retention", "remove", "/", "7d", "fs=myfs"}, lastArgs[2:7])
+}
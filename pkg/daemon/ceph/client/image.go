@@ -42,12 +42,21 @@ type CephBlockImage struct {
 }
 
 func ListImages(context *clusterd.Context, clusterInfo *ClusterInfo, poolName string) ([]CephBlockImage, error) {
-	args := []string{"ls", "-l", poolName}
+	return listImages(context, clusterInfo, poolName)
+}
+
+// ListImagesInRadosNamespace lists the images in a rados namespace of a pool
+func ListImagesInRadosNamespace(context *clusterd.Context, clusterInfo *ClusterInfo, poolName, namespace string) ([]CephBlockImage, error) {
+	return listImages(context, clusterInfo, radosNamespaceSpec(poolName, namespace))
+}
+
+func listImages(context *clusterd.Context, clusterInfo *ClusterInfo, poolSpec string) ([]CephBlockImage, error) {
+	args := []string{"ls", "-l", poolSpec}
 	cmd := NewRBDCommand(context, clusterInfo, args)
 	cmd.JsonOutput = true
 	buf, err := cmd.Run()
 	if err != nil {
-		return nil, errors.Wrapf(err, "failed to list images for pool %s", poolName)
+		return nil, errors.Wrapf(err, "failed to list images for pool %s", poolSpec)
 	}
 
 	//The regex expression captures the json result at the end buf
@@ -196,3 +205,11 @@ func UnMapImage(context *clusterd.Context, clusterInfo *ClusterInfo, imageName,
 func getImageSpec(name, poolName string) string {
 	return fmt.Sprintf("%s/%s", poolName, name)
 }
+
+// getImageSpecInNamespace returns the spec of an image of a pool or of a rados namespace of the pool
+func getImageSpecInNamespace(name, poolName, namespace string) string {
+	if namespace != "" {
+		return getImageSpec(name, radosNamespaceSpec(poolName, namespace))
+	}
+	return getImageSpec(name, poolName)
+}
@@ -0,0 +1,145 @@
+/*
+Copyright 2021 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"encoding/json"
+	"regexp"
+
+	"github.com/pkg/errors"
+	"github.com/rook/rook/pkg/clusterd"
+)
+
+// ImageMigration is the live migration of an rbd image as reported by 'rbd status'
+type ImageMigration struct {
+	SourcePoolName   string `json:"source_pool_name"`
+	SourceImageName  string `json:"source_image_name"`
+	DestPoolName     string `json:"dest_pool_name"`
+	DestImageName    string `json:"dest_image_name"`
+	State            string `json:"state"`
+	StateDescription string `json:"state_description"`
+}
+
+const (
+	// ImageMigrationPrepared is the state of a migration waiting to be executed
+	ImageMigrationPrepared = "prepared"
+	// ImageMigrationExecuting is the state of a migration copying the blocks of the image
+	ImageMigrationExecuting = "executing"
+	// ImageMigrationExecuted is the state of a migration waiting to be committed
+	ImageMigrationExecuted = "executed"
+)
+
+// ListImageNames lists the names of the images of a pool, or of a rados namespace of the pool
+func ListImageNames(context *clusterd.Context, clusterInfo *ClusterInfo, poolName, namespace string) ([]string, error) {
+	poolSpec := poolName
+	if namespace != "" {
+		poolSpec = radosNamespaceSpec(poolName, namespace)
+	}
+	cmd := NewRBDCommand(context, clusterInfo, []string{"ls", poolSpec})
+	cmd.JsonOutput = true
+	buf, err := cmd.Run()
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to list images of %q. %s", poolSpec, string(buf))
+	}
+
+	// the librados logs may precede the json output, see listImages
+	res := regexp.MustCompile(`(?m)^\[(.*)\]`).FindString(string(buf))
+	if res == "" {
+		return []string{}, nil
+	}
+	var names []string
+	if err := json.Unmarshal([]byte(res), &names); err != nil {
+		return nil, errors.Wrapf(err, "failed to unmarshal the images of %q. %s", poolSpec, res)
+	}
+	return names, nil
+}
+
+// GetImageDataPool returns the data pool of an image, which is empty if the data is in the pool of
+// the image
+func GetImageDataPool(context *clusterd.Context, clusterInfo *ClusterInfo, poolName, namespace, imageName string) (string, error) {
+	imageSpec := getImageSpecInNamespace(imageName, poolName, namespace)
+	cmd := NewRBDCommand(context, clusterInfo, []string{"info", imageSpec})
+	cmd.JsonOutput = true
+	buf, err := cmd.Run()
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to retrieve info of image %q. %s", imageSpec, string(buf))
+	}
+
+	var info imageInfo
+	if err := json.Unmarshal(buf, &info); err != nil {
+		return "", errors.Wrapf(err, "failed to unmarshal info of image %q", imageSpec)
+	}
+	return info.DataPool, nil
+}
+
+// GetImageMigration returns the live migration of an image, or nil if the image is not migrating,
+// and the clients watching the image
+func GetImageMigration(context *clusterd.Context, clusterInfo *ClusterInfo, poolName, namespace, imageName string) (*ImageMigration, []ImageWatcher, error) {
+	status, err := getImageStatus(context, clusterInfo, getImageSpecInNamespace(imageName, poolName, namespace))
+	if err != nil {
+		return nil, nil, err
+	}
+	return status.Migration, status.Watchers, nil
+}
+
+// PrepareImageMigration prepares the live migration of an image to another data pool. The image keeps
+// its name, the clients of the image must be stopped.
+func PrepareImageMigration(context *clusterd.Context, clusterInfo *ClusterInfo, poolName, namespace, imageName, dataPool string) error {
+	imageSpec := getImageSpecInNamespace(imageName, poolName, namespace)
+	logger.Infof("preparing the migration of image %q to data pool %q", imageSpec, dataPool)
+	buf, err := NewRBDCommand(context, clusterInfo, []string{"migration", "prepare", imageSpec, "--data-pool", dataPool}).Run()
+	if err != nil {
+		return errors.Wrapf(err, "failed to prepare the migration of image %q. %s", imageSpec, string(buf))
+	}
+	return nil
+}
+
+// ExecuteImageMigration copies the blocks of a prepared image to its new data pool, which takes as
+// long as the copy of the image
+func ExecuteImageMigration(context *clusterd.Context, clusterInfo *ClusterInfo, poolName, namespace, imageName string) error {
+	imageSpec := getImageSpecInNamespace(imageName, poolName, namespace)
+	logger.Infof("executing the migration of image %q", imageSpec)
+	// the blocks of the image are copied for as long as needed, the pool controller limits the concurrent migrations
+	cmd := NewRBDCommand(context, clusterInfo, []string{"migration", "execute", imageSpec, "--no-progress"})
+	cmd.LongRunning = true
+	buf, err := cmd.Run()
+	if err != nil {
+		return errors.Wrapf(err, "failed to execute the migration of image %q. %s", imageSpec, string(buf))
+	}
+	logger.Infof("successfully executed the migration of image %q", imageSpec)
+	return nil
+}
+
+// CommitImageMigration commits the executed migration of an image, which removes the source image
+func CommitImageMigration(context *clusterd.Context, clusterInfo *ClusterInfo, poolName, namespace, imageName string) error {
+	imageSpec := getImageSpecInNamespace(imageName, poolName, namespace)
+	buf, err := NewRBDCommand(context, clusterInfo, []string{"migration", "commit", imageSpec}).Run()
+	if err != nil {
+		return errors.Wrapf(err, "failed to commit the migration of image %q. %s", imageSpec, string(buf))
+	}
+	logger.Infof("successfully migrated image %q", imageSpec)
+	return nil
+}
+
+// SetDefaultDataPool sets the data pool of the new images of a pool
+func SetDefaultDataPool(context *clusterd.Context, clusterInfo *ClusterInfo, poolName, dataPool string) error {
+	buf, err := NewRBDCommand(context, clusterInfo, []string{"config", "pool", "set", poolName, "rbd_default_data_pool", dataPool}).Run()
+	if err != nil {
+		return errors.Wrapf(err, "failed to set the default data pool of pool %q to %q. %s", poolName, dataPool, string(buf))
+	}
+	return nil
+}
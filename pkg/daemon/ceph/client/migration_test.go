@@ -0,0 +1,81 @@
+/*
+Copyright 2021 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/rook/rook/pkg/clusterd"
+	exectest "github.com/rook/rook/pkg/util/exec/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestImageMigration(t *testing.T) {
+	executor := &exectest.MockExecutor{}
+	context := &clusterd.Context{Executor: executor}
+	clusterInfo := AdminClusterInfo("mycluster")
+	var lastArgs []string
+	executor.MockExecuteCommandWithOutput = func(command string, args ...string) (string, error) {
+		logger.Infof("Command: %s %v", command, args)
+		if command != "rbd" {
+			return "", errors.Errorf("unexpected command %q", command)
+		}
+		lastArgs = args
+		switch args[0] {
+		case "ls":
+			return `2021-07-01 10:00:00.000 7f debug log
+["image-1","image-2"]`, nil
+		case "info":
+			return `{"name":"image-1","data_pool":"ecpool"}`, nil
+		case "status":
+			return `{"watchers":[],"migration":{"source_pool_name":"replicapool","source_image_name":"image-1","dest_pool_name":"replicapool","dest_image_name":"image-1","state":"executing","state_description":""}}`, nil
+		}
+		return "", nil
+	}
+
+	names, err := ListImageNames(context, clusterInfo, "replicapool", "ns")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"image-1", "image-2"}, names)
+	assert.Equal(t, "replicapool/ns", lastArgs[1])
+
+	dataPool, err := GetImageDataPool(context, clusterInfo, "replicapool", "", "image-1")
+	assert.NoError(t, err)
+	assert.Equal(t, "ecpool", dataPool)
+
+	migration, watchers, err := GetImageMigration(context, clusterInfo, "replicapool", "", "image-1")
+	assert.NoError(t, err)
+	assert.Empty(t, watchers)
+	assert.Equal(t, ImageMigrationExecuting, migration.State)
+
+	err = PrepareImageMigration(context, clusterInfo, "replicapool", "", "image-1", "ecpool")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"migration", "prepare", "replicapool/image-1", "--data-pool", "ecpool"}, lastArgs[:5])
+
+	err = SetDefaultDataPool(context, clusterInfo, "replicapool", "ecpool")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"config", "pool", "set", "replicapool", "rbd_default_data_pool", "ecpool"}, lastArgs[:6])
+
+	// an image that is not migrating
+	executor.MockExecuteCommandWithOutput = func(command string, args ...string) (string, error) {
+		return `{"watchers":[{"address":"10.0.0.5:0/3519486571","client":4123,"cookie":1}]}`, nil
+	}
+	migration, watchers, err = GetImageMigration(context, clusterInfo, "replicapool", "", "image-1")
+	assert.NoError(t, err)
+	assert.Nil(t, migration)
+	assert.Equal(t, 1, len(watchers))
+}
@@ -384,3 +384,102 @@ func CreateRBDMirrorBootstrapPeerWithoutPool(context *clusterd.Context, clusterI
 	// Return the base64 encoded token
 	return []byte(base64.StdEncoding.EncodeToString(decodedTokenBackToJSON)), nil
 }
+
+// ImageMirrorStatus is the mirroring status of an image as reported by 'rbd mirror image status'
+type ImageMirrorStatus struct {
+	Name        string `json:"name"`
+	GlobalID    string `json:"global_id"`
+	State       string `json:"state"`
+	Description string `json:"description"`
+	LastUpdate  string `json:"last_update"`
+}
+
+// imageInfo is the part of the output of 'rbd info' about mirroring and the data pool
+type imageInfo struct {
+	DataPool  string `json:"data_pool"`
+	Mirroring struct {
+		State   string `json:"state"`
+		Primary bool   `json:"primary"`
+	} `json:"mirroring"`
+}
+
+// GetImageMirrorStatus returns the mirroring status of an image
+func GetImageMirrorStatus(context *clusterd.Context, clusterInfo *ClusterInfo, poolName, namespace, imageName string) (*ImageMirrorStatus, error) {
+	imageSpec := getImageSpecInNamespace(imageName, poolName, namespace)
+	cmd := NewRBDCommand(context, clusterInfo, []string{"mirror", "image", "status", imageSpec})
+	cmd.JsonOutput = true
+	buf, err := cmd.Run()
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to retrieve mirroring status of image %q. %s", imageSpec, string(buf))
+	}
+
+	var status ImageMirrorStatus
+	if err := json.Unmarshal(buf, &status); err != nil {
+		return nil, errors.Wrapf(err, "failed to unmarshal mirroring status of image %q", imageSpec)
+	}
+	return &status, nil
+}
+
+// IsImagePrimary returns whether a mirrored image is primary in the cluster
+func IsImagePrimary(context *clusterd.Context, clusterInfo *ClusterInfo, poolName, namespace, imageName string) (bool, error) {
+	imageSpec := getImageSpecInNamespace(imageName, poolName, namespace)
+	cmd := NewRBDCommand(context, clusterInfo, []string{"info", imageSpec})
+	cmd.JsonOutput = true
+	buf, err := cmd.Run()
+	if err != nil {
+		return false, errors.Wrapf(err, "failed to retrieve info of image %q. %s", imageSpec, string(buf))
+	}
+
+	var info imageInfo
+	if err := json.Unmarshal(buf, &info); err != nil {
+		return false, errors.Wrapf(err, "failed to unmarshal info of image %q", imageSpec)
+	}
+	if info.Mirroring.State != "enabled" {
+		return false, errors.Errorf("mirroring is not enabled for image %q", imageSpec)
+	}
+	return info.Mirroring.Primary, nil
+}
+
+// PromoteImage promotes a mirrored image to primary. Without force, the promotion fails until the
+// peer cluster demoted the image and the demotion was synced.
+func PromoteImage(context *clusterd.Context, clusterInfo *ClusterInfo, poolName, namespace, imageName string, force bool) error {
+	imageSpec := getImageSpecInNamespace(imageName, poolName, namespace)
+	logger.Infof("promoting image %q to primary (force: %t)", imageSpec, force)
+	args := []string{"mirror", "image", "promote", imageSpec}
+	if force {
+		args = append(args, "--force")
+	}
+	buf, err := NewRBDCommand(context, clusterInfo, args).Run()
+	if err != nil {
+		return errors.Wrapf(err, "failed to promote image %q. %s", imageSpec, string(buf))
+	}
+
+	logger.Infof("successfully promoted image %q", imageSpec)
+	return nil
+}
+
+// DemoteImage demotes a mirrored image to non-primary
+func DemoteImage(context *clusterd.Context, clusterInfo *ClusterInfo, poolName, namespace, imageName string) error {
+	imageSpec := getImageSpecInNamespace(imageName, poolName, namespace)
+	logger.Infof("demoting image %q to non-primary", imageSpec)
+	buf, err := NewRBDCommand(context, clusterInfo, []string{"mirror", "image", "demote", imageSpec}).Run()
+	if err != nil {
+		return errors.Wrapf(err, "failed to demote image %q. %s", imageSpec, string(buf))
+	}
+
+	logger.Infof("successfully demoted image %q", imageSpec)
+	return nil
+}
+
+// ResyncImage flags a non-primary image to be resynced from the primary image of the peer cluster
+func ResyncImage(context *clusterd.Context, clusterInfo *ClusterInfo, poolName, namespace, imageName string) error {
+	imageSpec := getImageSpecInNamespace(imageName, poolName, namespace)
+	logger.Infof("resyncing image %q", imageSpec)
+	buf, err := NewRBDCommand(context, clusterInfo, []string{"mirror", "image", "resync", imageSpec}).Run()
+	if err != nil {
+		return errors.Wrapf(err, "failed to resync image %q. %s", imageSpec, string(buf))
+	}
+
+	logger.Infof("successfully flagged image %q for resync", imageSpec)
+	return nil
+}
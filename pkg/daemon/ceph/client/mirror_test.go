@@ -340,3 +340,52 @@ func TestRemoveClusterPeer(t *testing.T) {
 	err := removeClusterPeer(context, AdminClusterInfo("mycluster"), pool, peerUUID)
 	assert.NoError(t, err)
 }
+
+func TestImageMirroring(t *testing.T) {
+	executor := &exectest.MockExecutor{}
+	context := &clusterd.Context{Executor: executor}
+	var lastArgs []string
+	executor.MockExecuteCommandWithOutput = func(command string, args ...string) (string, error) {
+		if command != "rbd" {
+			return "", errors.Errorf("unexpected command %q", command)
+		}
+		lastArgs = args
+		if args[0] == "info" {
+			assert.Equal(t, "replicapool/ns/csi-vol-1", args[1])
+			return `{"name":"csi-vol-1","mirroring":{"mode":"snapshot","state":"enabled","global_id":"c4d4f9a2","primary":true}}`, nil
+		}
+		if args[0] == "mirror" && args[2] == "status" {
+			return `{"name":"csi-vol-1","global_id":"c4d4f9a2","state":"up+stopped","description":"local image is primary","last_update":"2021-07-01 12:00:00"}`, nil
+		}
+		return "", nil
+	}
+
+	primary, err := IsImagePrimary(context, AdminClusterInfo("mycluster"), "replicapool", "ns", "csi-vol-1")
+	assert.NoError(t, err)
+	assert.True(t, primary)
+
+	status, err := GetImageMirrorStatus(context, AdminClusterInfo("mycluster"), "replicapool", "", "csi-vol-1")
+	assert.NoError(t, err)
+	assert.Equal(t, "replicapool/csi-vol-1", lastArgs[3])
+	assert.Equal(t, "up+stopped", status.State)
+	assert.Equal(t, "local image is primary", status.Description)
+
+	err = PromoteImage(context, AdminClusterInfo("mycluster"), "replicapool", "", "csi-vol-1", true)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"mirror", "image", "promote", "replicapool/csi-vol-1", "--force"}, lastArgs[:5])
+
+	err = DemoteImage(context, AdminClusterInfo("mycluster"), "replicapool", "", "csi-vol-1")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"mirror", "image", "demote", "replicapool/csi-vol-1"}, lastArgs[:4])
+
+	err = ResyncImage(context, AdminClusterInfo("mycluster"), "replicapool", "", "csi-vol-1")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"mirror", "image", "resync", "replicapool/csi-vol-1"}, lastArgs[:4])
+
+	// mirroring disabled
+	executor.MockExecuteCommandWithOutput = func(command string, args ...string) (string, error) {
+		return `{"name":"csi-vol-1"}`, nil
+	}
+	_, err = IsImagePrimary(context, AdminClusterInfo("mycluster"), "replicapool", "", "csi-vol-1")
+	assert.Error(t, err)
+}
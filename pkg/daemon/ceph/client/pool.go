@@ -27,6 +27,7 @@ import (
 	"github.com/pkg/errors"
 	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
 	"github.com/rook/rook/pkg/clusterd"
+	cephver "github.com/rook/rook/pkg/operator/ceph/version"
 	"k8s.io/apimachinery/pkg/api/resource"
 )
 
@@ -35,8 +36,20 @@ const (
 	reallyConfirmFlag       = "--yes-i-really-really-mean-it"
 	targetSizeRatioProperty = "target_size_ratio"
 	compressionModeProperty = "compression_mode"
+	crushRuleProperty       = "crush_rule"
 	PgAutoscaleModeProperty = "pg_autoscale_mode"
 	PgAutoscaleModeOn       = "on"
+	pgNumProperty           = "pg_num"
+	pgpNumProperty          = "pgp_num"
+	pgNumMinProperty        = "pg_num_min"
+	pgNumMaxProperty        = "pg_num_max"
+	bulkProperty            = "bulk"
+)
+
+var (
+	// the versions of Ceph that support the pg_num_max and bulk properties of the pools
+	pgNumMaxVersion = cephver.Quincy
+	bulkVersion     = cephver.CephVersion{Major: 16, Minor: 2, Extra: 7}
 )
 
 type CephStoragePoolSummary struct {
@@ -57,12 +70,26 @@ type CephStoragePoolDetails struct {
 	RequireSafeReplicaSize bool    `json:"requireSafeReplicaSize,omitempty"`
 }
 
+// PoolPlacementGroups is the number of placement groups of a pool. The targets differ from the current
+// numbers while Ceph splits, merges or remaps the placement groups.
+type PoolPlacementGroups struct {
+	Name         string `json:"pool_name"`
+	PgNum        int    `json:"pg_num"`
+	PgpNum       int    `json:"pg_placement_num"`
+	PgNumTarget  int    `json:"pg_num_target"`
+	PgpNumTarget int    `json:"pg_placement_num_target"`
+}
+
 type CephStoragePoolStats struct {
 	Pools []struct {
 		Name  string `json:"name"`
 		ID    int    `json:"id"`
 		Stats struct {
+			Stored       float64 `json:"stored"`
 			BytesUsed    float64 `json:"bytes_used"`
+			PercentUsed  float64 `json:"percent_used"`
+			QuotaBytes   float64 `json:"quota_bytes"`
+			QuotaObjects float64 `json:"quota_objects"`
 			RawBytesUsed float64 `json:"raw_bytes_used"`
 			MaxAvail     float64 `json:"max_avail"`
 			Objects      float64 `json:"objects"`
@@ -155,9 +182,36 @@ func ParsePoolDetails(in []byte) (CephStoragePoolDetails, error) {
 	return poolDetails, nil
 }
 
+// GetPoolPlacementGroups gets the current and target number of placement groups of a pool
+func GetPoolPlacementGroups(context *clusterd.Context, clusterInfo *ClusterInfo, name string) (*PoolPlacementGroups, error) {
+	args := []string{"osd", "pool", "ls", "detail"}
+	output, err := NewCephCommand(context, clusterInfo, args).Run()
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to list pool details. %s", string(output))
+	}
+
+	var pools []PoolPlacementGroups
+	if err := json.Unmarshal(output, &pools); err != nil {
+		return nil, errors.Wrapf(err, "failed to unmarshal pool details. %s", string(output))
+	}
+	for i := range pools {
+		if pools[i].Name == name {
+			return &pools[i], nil
+		}
+	}
+	return nil, errors.Errorf("pool %q not found", name)
+}
+
 func CreatePoolWithProfile(context *clusterd.Context, clusterInfo *ClusterInfo, clusterSpec *cephv1.ClusterSpec, poolName string, pool cephv1.PoolSpec, appName string) error {
+	// a new pool is created with the number of placement groups of the spec, the number of placement
+	// groups of an existing pool is not changed
+	pgCount := DefaultPGCount
+	if pool.PlacementGroups != nil && pool.PlacementGroups.PgNum > 0 {
+		pgCount = strconv.Itoa(pool.PlacementGroups.PgNum)
+	}
+
 	if pool.IsReplicated() {
-		return CreateReplicatedPoolForApp(context, clusterInfo, clusterSpec, poolName, pool, DefaultPGCount, appName)
+		return CreateReplicatedPoolForApp(context, clusterInfo, clusterSpec, poolName, pool, pgCount, appName)
 	}
 
 	if !pool.IsErasureCoded() {
@@ -178,7 +232,7 @@ func CreatePoolWithProfile(context *clusterd.Context, clusterInfo *ClusterInfo,
 		poolName,
 		ecProfileName,
 		pool,
-		DefaultPGCount,
+		pgCount,
 		appName,
 		true /* enableECOverwrite */)
 }
@@ -242,6 +296,29 @@ func givePoolAppTag(context *clusterd.Context, clusterInfo *ClusterInfo, poolNam
 	return nil
 }
 
+// ValidatePlacementGroupsVersion validates that the running version of Ceph supports the placement
+// group settings of a pool
+func ValidatePlacementGroupsVersion(cephVersion cephver.CephVersion, pgs *cephv1.PlacementGroupSpec) error {
+	if pgs == nil {
+		return nil
+	}
+	if pgs.PgNumMax > 0 && !cephVersion.IsAtLeast(pgNumMaxVersion) {
+		return errors.Errorf("pgNumMax requires ceph %s or newer, the running version is %s", pgNumMaxVersion.String(), cephVersion.String())
+	}
+	if pgs.Bulk != nil && !cephVersion.IsAtLeast(bulkVersion) {
+		return errors.Errorf("bulk requires ceph %s or newer, the running version is %s", bulkVersion.String(), cephVersion.String())
+	}
+	return nil
+}
+
+// defaultPgAutoscaleMode returns the default autoscale mode of the pools of a version of Ceph
+func defaultPgAutoscaleMode(cephVersion cephver.CephVersion) string {
+	if cephVersion.IsAtLeastOctopus() {
+		return PgAutoscaleModeOn
+	}
+	return "warn"
+}
+
 func setCommonPoolProperties(context *clusterd.Context, clusterInfo *ClusterInfo, pool cephv1.PoolSpec, poolName, appName string) error {
 	if len(pool.Parameters) == 0 {
 		pool.Parameters = make(map[string]string)
@@ -255,6 +332,39 @@ func setCommonPoolProperties(context *clusterd.Context, clusterInfo *ClusterInfo
 		pool.Parameters[compressionModeProperty] = pool.CompressionMode
 	}
 
+	// the rule of an existing pool is updated when another rule is referenced
+	if pool.CrushRule != "" {
+		pool.Parameters[crushRuleProperty] = pool.CrushRule
+	}
+
+	// the typed placement group settings override the parameters, pg_num and pgp_num are changed
+	// gradually by the pool controller
+	if pgs := pool.PlacementGroups; pgs != nil {
+		if pgs.AutoscaleMode != "" {
+			pool.Parameters[PgAutoscaleModeProperty] = pgs.AutoscaleMode
+		} else if _, ok := pool.Parameters[PgAutoscaleModeProperty]; !ok {
+			// the mode is reset to the default of ceph when it is removed from the settings
+			pool.Parameters[PgAutoscaleModeProperty] = defaultPgAutoscaleMode(clusterInfo.CephVersion)
+		}
+		if pgs.PgNumMin > 0 {
+			pool.Parameters[pgNumMinProperty] = strconv.Itoa(pgs.PgNumMin)
+		}
+		if pgs.PgNumMax > 0 {
+			if clusterInfo.CephVersion.IsAtLeast(pgNumMaxVersion) {
+				pool.Parameters[pgNumMaxProperty] = strconv.Itoa(pgs.PgNumMax)
+			} else {
+				logger.Warningf("ignoring pgNumMax of pool %q, it requires ceph %s or newer", poolName, pgNumMaxVersion.String())
+			}
+		}
+		if pgs.Bulk != nil {
+			if clusterInfo.CephVersion.IsAtLeast(bulkVersion) {
+				pool.Parameters[bulkProperty] = strconv.FormatBool(*pgs.Bulk)
+			} else {
+				logger.Warningf("ignoring bulk of pool %q, it requires ceph %s or newer", poolName, bulkVersion.String())
+			}
+		}
+	}
+
 	// Apply properties
 	for propName, propValue := range pool.Parameters {
 		err := SetPoolProperty(context, clusterInfo, poolName, propName, propValue)
@@ -352,6 +462,9 @@ func GetErasureCodeProfileForPool(baseName string) string {
 
 func CreateECPoolForApp(context *clusterd.Context, clusterInfo *ClusterInfo, poolName, ecProfileName string, pool cephv1.PoolSpec, pgCount, appName string, enableECOverwrite bool) error {
 	args := []string{"osd", "pool", "create", poolName, pgCount, "erasure", ecProfileName}
+	if pool.CrushRule != "" {
+		args = append(args, pool.CrushRule)
+	}
 	output, err := NewCephCommand(context, clusterInfo, args).Run()
 	if err != nil {
 		return errors.Wrapf(err, "failed to create EC pool %s. %s", poolName, string(output))
@@ -374,7 +487,10 @@ func CreateECPoolForApp(context *clusterd.Context, clusterInfo *ClusterInfo, poo
 func CreateReplicatedPoolForApp(context *clusterd.Context, clusterInfo *ClusterInfo, clusterSpec *cephv1.ClusterSpec, poolName string, pool cephv1.PoolSpec, pgCount, appName string) error {
 	// The crush rule name is the same as the pool unless we have a stretch cluster.
 	crushRuleName := poolName
-	if clusterSpec.IsStretchCluster() {
+	if pool.CrushRule != "" {
+		// the rule is created from a CephCrushRule
+		crushRuleName = pool.CrushRule
+	} else if clusterSpec.IsStretchCluster() {
 		// A stretch cluster enforces using the same crush rule for all pools.
 		// The stretch cluster rule is created initially by the operator when the stretch cluster is configured
 		// so there is no need to create a new crush rule for the pools here.
@@ -586,6 +702,20 @@ func SetPoolProperty(context *clusterd.Context, clusterInfo *ClusterInfo, name,
 	return nil
 }
 
+// SetPoolPlacementGroups sets the number of placement groups of a pool, and the number of placement
+// groups used for placement when pgpNum is not zero
+func SetPoolPlacementGroups(context *clusterd.Context, clusterInfo *ClusterInfo, name string, pgNum, pgpNum int) error {
+	if err := SetPoolProperty(context, clusterInfo, name, pgNumProperty, strconv.Itoa(pgNum)); err != nil {
+		return err
+	}
+	if pgpNum > 0 {
+		if err := SetPoolProperty(context, clusterInfo, name, pgpNumProperty, strconv.Itoa(pgpNum)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // setPoolQuota sets quotas on a given pool
 func setPoolQuota(context *clusterd.Context, clusterInfo *ClusterInfo, poolName, quotaType, quotaVal string) error {
 	args := []string{"osd", "pool", "set-quota", poolName, quotaType, quotaVal}
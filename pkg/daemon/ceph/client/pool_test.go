@@ -24,6 +24,7 @@ import (
 	"github.com/pkg/errors"
 	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
 	"github.com/rook/rook/pkg/clusterd"
+	cephver "github.com/rook/rook/pkg/operator/ceph/version"
 	exectest "github.com/rook/rook/pkg/util/exec/test"
 	"github.com/stretchr/testify/assert"
 )
@@ -450,3 +451,96 @@ func hasCrushtool() bool {
 	_, err := exec.LookPath("crushtool")
 	return err == nil
 }
+
+func TestPoolPlacementGroups(t *testing.T) {
+	bulk := true
+	properties := map[string]string{}
+	created := ""
+	executor := &exectest.MockExecutor{}
+	context := &clusterd.Context{Executor: executor}
+	executor.MockExecuteCommandWithOutput = func(command string, args ...string) (string, error) {
+		logger.Infof("Command: %s %v", command, args)
+		if args[0] == "osd" && args[1] == "pool" {
+			switch args[2] {
+			case "create":
+				created = args[4]
+				return "", nil
+			case "set":
+				properties[args[4]] = args[5]
+				return "", nil
+			case "application":
+				return "", nil
+			case "ls":
+				return `[{"pool_name":"mypool","pg_num":32,"pg_placement_num":16,"pg_num_target":64,"pg_placement_num_target":16}]`, nil
+			}
+		}
+		if args[0] == "osd" && args[1] == "crush" {
+			return "", nil
+		}
+		return "", errors.Errorf("unexpected ceph command %q", args)
+	}
+
+	clusterInfo := AdminClusterInfo("mycluster")
+	clusterInfo.CephVersion = cephver.Quincy
+	p := cephv1.PoolSpec{
+		Replicated: cephv1.ReplicatedSpec{Size: 3},
+		Parameters: map[string]string{"pg_autoscale_mode": "on"},
+		PlacementGroups: &cephv1.PlacementGroupSpec{
+			PgNum: 32, PgNumMin: 8, PgNumMax: 128, Bulk: &bulk, AutoscaleMode: "warn",
+		},
+	}
+	err := CreatePoolWithProfile(context, clusterInfo, &cephv1.ClusterSpec{}, "mypool", p, "myapp")
+	assert.NoError(t, err)
+	assert.Equal(t, "32", created)
+	assert.Equal(t, "warn", properties["pg_autoscale_mode"])
+	assert.Equal(t, "8", properties["pg_num_min"])
+	assert.Equal(t, "128", properties["pg_num_max"])
+	assert.Equal(t, "true", properties["bulk"])
+	// pg_num is only set at creation
+	assert.Equal(t, "", properties["pg_num"])
+
+	pgs, err := GetPoolPlacementGroups(context, clusterInfo, "mypool")
+	assert.NoError(t, err)
+	assert.Equal(t, PoolPlacementGroups{Name: "mypool", PgNum: 32, PgpNum: 16, PgNumTarget: 64, PgpNumTarget: 16}, *pgs)
+	_, err = GetPoolPlacementGroups(context, clusterInfo, "other")
+	assert.Error(t, err)
+
+	assert.NoError(t, SetPoolPlacementGroups(context, clusterInfo, "mypool", 64, 0))
+	assert.Equal(t, "64", properties["pg_num"])
+	assert.Equal(t, "", properties["pgp_num"])
+	assert.NoError(t, SetPoolPlacementGroups(context, clusterInfo, "mypool", 64, 32))
+	assert.Equal(t, "32", properties["pgp_num"])
+
+	t.Run("autoscale mode removed", func(t *testing.T) {
+		properties = map[string]string{}
+		p := cephv1.PoolSpec{Replicated: cephv1.ReplicatedSpec{Size: 3}, PlacementGroups: &cephv1.PlacementGroupSpec{PgNumMin: 8}}
+		err := CreatePoolWithProfile(context, clusterInfo, &cephv1.ClusterSpec{}, "mypool", p, "myapp")
+		assert.NoError(t, err)
+		assert.Equal(t, "on", properties["pg_autoscale_mode"])
+	})
+
+	t.Run("unsupported settings", func(t *testing.T) {
+		properties = map[string]string{}
+		clusterInfo := AdminClusterInfo("mycluster")
+		clusterInfo.CephVersion = cephver.CephVersion{Major: 16, Minor: 2, Extra: 6}
+		p := cephv1.PoolSpec{Replicated: cephv1.ReplicatedSpec{Size: 3}, PlacementGroups: &cephv1.PlacementGroupSpec{PgNumMax: 128, Bulk: &bulk}}
+		err := CreatePoolWithProfile(context, clusterInfo, &cephv1.ClusterSpec{}, "mypool", p, "myapp")
+		assert.NoError(t, err)
+		assert.NotContains(t, properties, "pg_num_max")
+		assert.NotContains(t, properties, "bulk")
+	})
+}
+
+func TestValidatePlacementGroupsVersion(t *testing.T) {
+	bulk := true
+	assert.NoError(t, ValidatePlacementGroupsVersion(cephver.Octopus, nil))
+	assert.NoError(t, ValidatePlacementGroupsVersion(cephver.Octopus, &cephv1.PlacementGroupSpec{PgNumMin: 8, AutoscaleMode: "warn"}))
+
+	pgs := &cephv1.PlacementGroupSpec{Bulk: &bulk}
+	assert.Error(t, ValidatePlacementGroupsVersion(cephver.CephVersion{Major: 16, Minor: 2, Extra: 6}, pgs))
+	assert.NoError(t, ValidatePlacementGroupsVersion(cephver.CephVersion{Major: 16, Minor: 2, Extra: 7}, pgs))
+
+	pgs = &cephv1.PlacementGroupSpec{PgNumMax: 128}
+	assert.Error(t, ValidatePlacementGroupsVersion(cephver.Pacific, pgs))
+	assert.NoError(t, ValidatePlacementGroupsVersion(cephver.Quincy, pgs))
+}
@@ -0,0 +1,62 @@
+/*
+Copyright 2021 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"fmt"
+	"syscall"
+
+	"github.com/pkg/errors"
+	"github.com/rook/rook/pkg/clusterd"
+	"github.com/rook/rook/pkg/util/exec"
+)
+
+// CreateRadosNamespace creates a rados namespace in a pool. It succeeds if the namespace exists.
+func CreateRadosNamespace(context *clusterd.Context, clusterInfo *ClusterInfo, poolName, namespace string) error {
+	logger.Infof("creating rados namespace %q in pool %q", namespace, poolName)
+	args := []string{"namespace", "create", radosNamespaceSpec(poolName, namespace)}
+	buf, err := NewRBDCommand(context, clusterInfo, args).Run()
+	if err != nil {
+		if code, ok := exec.ExitStatus(err); ok && code == int(syscall.EEXIST) {
+			logger.Debugf("rados namespace %q already exists in pool %q", namespace, poolName)
+			return nil
+		}
+		return errors.Wrapf(err, "failed to create rados namespace %q in pool %q. %s", namespace, poolName, string(buf))
+	}
+
+	return nil
+}
+
+// DeleteRadosNamespace deletes a rados namespace from a pool. It succeeds if the namespace does not exist.
+func DeleteRadosNamespace(context *clusterd.Context, clusterInfo *ClusterInfo, poolName, namespace string) error {
+	logger.Infof("deleting rados namespace %q from pool %q", namespace, poolName)
+	args := []string{"namespace", "remove", radosNamespaceSpec(poolName, namespace)}
+	buf, err := NewRBDCommand(context, clusterInfo, args).Run()
+	if err != nil {
+		if code, ok := exec.ExitStatus(err); ok && code == int(syscall.ENOENT) {
+			logger.Debugf("rados namespace %q does not exist in pool %q", namespace, poolName)
+			return nil
+		}
+		return errors.Wrapf(err, "failed to delete rados namespace %q from pool %q. %s", namespace, poolName, string(buf))
+	}
+
+	return nil
+}
+
+func radosNamespaceSpec(poolName, namespace string) string {
+	return fmt.Sprintf("%s/%s", poolName, namespace)
+}
@@ -21,6 +21,7 @@ import (
 
 	"github.com/pkg/errors"
 	"github.com/rook/rook/pkg/clusterd"
+	"github.com/rook/rook/pkg/util/exec"
 )
 
 const (
@@ -64,6 +65,7 @@ type HealthStatus struct {
 type CheckMessage struct {
 	Severity string  `json:"severity"`
 	Summary  Summary `json:"summary"`
+	Muted    bool    `json:"muted"`
 }
 
 type Summary struct {
@@ -92,6 +94,7 @@ type MgrMap struct {
 	ActiveAddr string       `json:"active_addr"`
 	Available  bool         `json:"available"`
 	Standbys   []MgrStandby `json:"standbys"`
+	Modules    []string     `json:"modules"`
 }
 
 type MgrStandby struct {
@@ -171,7 +174,9 @@ func StatusWithUser(context *clusterd.Context, clusterInfo *ClusterInfo) (CephSt
 	args := []string{"status", "--format", "json"}
 	command, args := FinalizeCephCommandArgs("ceph", clusterInfo, args, context.ConfigDir)
 
+	audit := exec.StartCommandAudit(false, CephTool, "status")
 	buf, err := context.Executor.ExecuteCommandWithOutput(command, args...)
+	audit.Finish(buf, err)
 	if err != nil {
 		if buf != "" {
 			return CephStatus{}, errors.Wrapf(err, "failed to get status. %s", string(buf))
@@ -306,3 +311,32 @@ func isCephHealthy(status CephStatus) bool {
 
 	return false
 }
+
+// MuteHealthCheck mutes a health check. The health check is muted for the given time if the ttl
+// is not empty. A sticky mute is kept when the health check clears.
+func MuteHealthCheck(context *clusterd.Context, clusterInfo *ClusterInfo, code, ttl string, sticky bool) error {
+	args := []string{"health", "mute", code}
+	if ttl != "" {
+		args = append(args, ttl)
+	}
+	if sticky {
+		args = append(args, "--sticky")
+	}
+	buf, err := NewCephCommand(context, clusterInfo, args).Run()
+	if err != nil {
+		return errors.Wrapf(err, "failed to mute health check %q. %s", code, string(buf))
+	}
+
+	return nil
+}
+
+// UnmuteHealthCheck unmutes a health check
+func UnmuteHealthCheck(context *clusterd.Context, clusterInfo *ClusterInfo, code string) error {
+	args := []string{"health", "unmute", code}
+	buf, err := NewCephCommand(context, clusterInfo, args).Run()
+	if err != nil {
+		return errors.Wrapf(err, "failed to unmute health check %q. %s", code, string(buf))
+	}
+
+	return nil
+}
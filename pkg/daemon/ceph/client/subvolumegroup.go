@@ -0,0 +1,125 @@
+/*
+Copyright 2021 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"encoding/json"
+	"strings"
+	"syscall"
+
+	"github.com/pkg/errors"
+	"github.com/rook/rook/pkg/clusterd"
+	"github.com/rook/rook/pkg/util/exec"
+)
+
+// SubVolume is a representation of the json structure returned by 'ceph fs subvolume ls'
+type SubVolume struct {
+	Name string `json:"name"`
+}
+
+// CreateCephFSSubVolumeGroup creates a subvolume group in a filesystem. If the group exists, its
+// data pool layout is updated.
+func CreateCephFSSubVolumeGroup(context *clusterd.Context, clusterInfo *ClusterInfo, volName, groupName, dataPoolName string) error {
+	logger.Infof("creating cephfs %q subvolume group %q", volName, groupName)
+	args := []string{"fs", "subvolumegroup", "create", volName, groupName}
+	if dataPoolName != "" {
+		args = append(args, "--pool_layout", dataPoolName)
+	}
+	buf, err := NewCephCommand(context, clusterInfo, args).Run()
+	if err != nil {
+		return errors.Wrapf(err, "failed to create subvolume group %q in filesystem %q. %s", groupName, volName, string(buf))
+	}
+
+	logger.Infof("successfully created cephfs %q subvolume group %q", volName, groupName)
+	return nil
+}
+
+// ResizeCephFSSubVolumeGroup sets the quota of a subvolume group. The size is in bytes, or "infinite"
+// to remove the quota.
+func ResizeCephFSSubVolumeGroup(context *clusterd.Context, clusterInfo *ClusterInfo, volName, groupName, size string) error {
+	logger.Infof("resizing cephfs %q subvolume group %q to %q", volName, groupName, size)
+	args := []string{"fs", "subvolumegroup", "resize", volName, groupName, size}
+	buf, err := NewCephCommand(context, clusterInfo, args).Run()
+	if err != nil {
+		return errors.Wrapf(err, "failed to resize subvolume group %q in filesystem %q. %s", groupName, volName, string(buf))
+	}
+
+	return nil
+}
+
+// PinCephFSSubVolumeGroup sets the pinning policy of a subvolume group. The pin type is one of
+// "export", "distributed" or "random".
+func PinCephFSSubVolumeGroup(context *clusterd.Context, clusterInfo *ClusterInfo, volName, groupName, pinType, pinSetting string) error {
+	logger.Infof("pinning cephfs %q subvolume group %q with %s=%s", volName, groupName, pinType, pinSetting)
+	args := []string{"fs", "subvolumegroup", "pin", volName, groupName, pinType, pinSetting}
+	buf, err := NewCephCommand(context, clusterInfo, args).Run()
+	if err != nil {
+		return errors.Wrapf(err, "failed to pin subvolume group %q in filesystem %q. %s", groupName, volName, string(buf))
+	}
+
+	return nil
+}
+
+// ListCephFSSubVolumes lists the subvolumes of a subvolume group
+func ListCephFSSubVolumes(context *clusterd.Context, clusterInfo *ClusterInfo, volName, groupName string) ([]SubVolume, error) {
+	args := []string{"fs", "subvolume", "ls", volName, "--group_name", groupName}
+	buf, err := NewCephCommand(context, clusterInfo, args).Run()
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to list subvolumes in filesystem %q subvolume group %q. %s", volName, groupName, string(buf))
+	}
+
+	var subVolumes []SubVolume
+	if err := json.Unmarshal(buf, &subVolumes); err != nil {
+		return nil, errors.Wrapf(err, "failed to unmarshal subvolume list response %q", string(buf))
+	}
+
+	return subVolumes, nil
+}
+
+// GetCephFSSubVolumePath returns the path of a subvolume in the filesystem. The default group is used if
+// the group name is empty.
+func GetCephFSSubVolumePath(context *clusterd.Context, clusterInfo *ClusterInfo, volName, subVolName, groupName string) (string, error) {
+	args := []string{"fs", "subvolume", "getpath", volName, subVolName}
+	if groupName != "" {
+		args = append(args, "--group_name", groupName)
+	}
+	cmd := NewCephCommand(context, clusterInfo, args)
+	cmd.JsonOutput = false
+	buf, err := cmd.Run()
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to get the path of subvolume %q in filesystem %q. %s", subVolName, volName, string(buf))
+	}
+
+	return strings.TrimSpace(string(buf)), nil
+}
+
+// DeleteCephFSSubVolumeGroup deletes a subvolume group. It succeeds if the group does not exist.
+func DeleteCephFSSubVolumeGroup(context *clusterd.Context, clusterInfo *ClusterInfo, volName, groupName string) error {
+	logger.Infof("deleting cephfs %q subvolume group %q", volName, groupName)
+	args := []string{"fs", "subvolumegroup", "rm", volName, groupName}
+	buf, err := NewCephCommand(context, clusterInfo, args).Run()
+	if err != nil {
+		if code, ok := exec.ExitStatus(err); ok && code == int(syscall.ENOENT) {
+			logger.Debugf("cephfs %q subvolume group %q does not exist", volName, groupName)
+			return nil
+		}
+		return errors.Wrapf(err, "failed to delete subvolume group %q in filesystem %q. %s", groupName, volName, string(buf))
+	}
+
+	logger.Infof("successfully deleted cephfs %q subvolume group %q", volName, groupName)
+	return nil
+}
@@ -0,0 +1,59 @@
+/*
+Copyright 2021 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/rook/rook/pkg/clusterd"
+	exectest "github.com/rook/rook/pkg/util/exec/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCephFSSubVolumeGroup(t *testing.T) {
+	executor := &exectest.MockExecutor{}
+	context := &clusterd.Context{Executor: executor}
+	clusterInfo := AdminClusterInfo("mycluster")
+
+	executor.MockExecuteCommandWithOutput = func(command string, args ...string) (string, error) {
+		if args[0] == "fs" && args[1] == "subvolumegroup" && args[2] == "create" {
+			assert.Equal(t, []string{"myfs", "group-a", "--pool_layout", "myfs-data0"}, args[3:7])
+			return "", nil
+		}
+		if args[0] == "fs" && args[1] == "subvolume" && args[2] == "getpath" {
+			assert.Equal(t, []string{"myfs", "csi-vol-1", "--group_name", "group-a"}, args[3:7])
+			return "/volumes/group-a/csi-vol-1/3c7a1d21-8f4f-4b5e-a4e4-1c9f6b5e1f2a\n", nil
+		}
+		if args[0] == "fs" && args[1] == "subvolume" && args[2] == "ls" {
+			assert.Equal(t, []string{"myfs", "--group_name", "group-a"}, args[3:6])
+			return `[{"name":"csi-vol-1"},{"name":"csi-vol-2"}]`, nil
+		}
+		return "", errors.Errorf("unexpected ceph command %q", args)
+	}
+
+	err := CreateCephFSSubVolumeGroup(context, clusterInfo, "myfs", "group-a", "myfs-data0")
+	assert.NoError(t, err)
+
+	subVolumes, err := ListCephFSSubVolumes(context, clusterInfo, "myfs", "group-a")
+	assert.NoError(t, err)
+	assert.Equal(t, []SubVolume{{Name: "csi-vol-1"}, {Name: "csi-vol-2"}}, subVolumes)
+
+	path, err := GetCephFSSubVolumePath(context, clusterInfo, "myfs", "csi-vol-1", "group-a")
+	assert.NoError(t, err)
+	assert.Equal(t, "/volumes/group-a/csi-vol-1/3c7a1d21-8f4f-4b5e-a4e4-1c9f6b5e1f2a", path)
+}
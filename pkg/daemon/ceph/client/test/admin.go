@@ -0,0 +1,251 @@
+/*
+Copyright 2021 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package test
+
+import (
+	"sort"
+	"strconv"
+	"sync"
+
+	"github.com/pkg/errors"
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	"github.com/rook/rook/pkg/daemon/ceph/client"
+)
+
+// FakeCephAdmin is an in-memory Ceph cluster with pools that implements the client.CephAdmin interface
+// for unit tests
+type FakeCephAdmin struct {
+	mutex sync.Mutex
+
+	Pools map[string]*FakePool
+	// Errors are returned by the methods of the admin with the same name, such as "CreatePool"
+	Errors map[string]error
+
+	nextPoolID int
+}
+
+// FakePool is a pool of the fake cluster
+type FakePool struct {
+	ID                 int
+	Name               string
+	Application        string
+	Size               uint
+	ErasureCodeProfile string
+	PgCount            string
+	Properties         map[string]string
+	// Images is the number of rbd images in the pool, a pool with images cannot be deleted
+	Images int
+}
+
+var _ client.CephAdmin = &FakeCephAdmin{}
+
+// NewFakeCephAdmin returns a fake cluster without pools
+func NewFakeCephAdmin() *FakeCephAdmin {
+	return &FakeCephAdmin{
+		Pools:      map[string]*FakePool{},
+		Errors:     map[string]error{},
+		nextPoolID: 1,
+	}
+}
+
+// AddPool adds an existing pool to the fake cluster
+func (a *FakeCephAdmin) AddPool(name string, size uint) *FakePool {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	return a.addPool(name, size)
+}
+
+// PoolNames returns the sorted names of the pools of the fake cluster
+func (a *FakeCephAdmin) PoolNames() []string {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	names := []string{}
+	for name := range a.Pools {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (a *FakeCephAdmin) addPool(name string, size uint) *FakePool {
+	pool := &FakePool{ID: a.nextPoolID, Name: name, Size: size, PgCount: client.DefaultPGCount, Properties: map[string]string{}}
+	a.nextPoolID++
+	a.Pools[name] = pool
+	return pool
+}
+
+func (a *FakeCephAdmin) ListPools() ([]client.CephStoragePoolSummary, error) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	if err := a.Errors["ListPools"]; err != nil {
+		return nil, err
+	}
+
+	pools := []client.CephStoragePoolSummary{}
+	for _, pool := range a.Pools {
+		pools = append(pools, client.CephStoragePoolSummary{Name: pool.Name, Number: pool.ID})
+	}
+	sort.Slice(pools, func(i, j int) bool { return pools[i].Number < pools[j].Number })
+	return pools, nil
+}
+
+func (a *FakeCephAdmin) GetPoolDetails(name string) (client.CephStoragePoolDetails, error) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	if err := a.Errors["GetPoolDetails"]; err != nil {
+		return client.CephStoragePoolDetails{}, err
+	}
+
+	pool, ok := a.Pools[name]
+	if !ok {
+		return client.CephStoragePoolDetails{}, errors.Errorf("failed to get pool %s details. pool %q does not exist", name, name)
+	}
+	return client.CephStoragePoolDetails{
+		Name:               pool.Name,
+		Number:             pool.ID,
+		Size:               pool.Size,
+		ErasureCodeProfile: pool.ErasureCodeProfile,
+		CompressionMode:    pool.Properties["compression_mode"],
+	}, nil
+}
+
+func (a *FakeCephAdmin) CreatePool(clusterSpec *cephv1.ClusterSpec, name string, pool cephv1.PoolSpec, appName string) error {
+	if err := a.getError("CreatePool"); err != nil {
+		return err
+	}
+
+	pgCount := client.DefaultPGCount
+	if pool.PlacementGroups != nil && pool.PlacementGroups.PgNum > 0 {
+		pgCount = strconv.Itoa(pool.PlacementGroups.PgNum)
+	}
+	if pool.IsReplicated() {
+		return a.CreateReplicatedPool(clusterSpec, name, pool, pgCount, appName)
+	}
+	if !pool.IsErasureCoded() {
+		return errors.Errorf("pool %q type is not defined as replicated or erasure coded", name)
+	}
+	return a.CreateECPool(name, client.GetErasureCodeProfileForPool(name), pool, pgCount, appName, true)
+}
+
+func (a *FakeCephAdmin) CreateReplicatedPool(clusterSpec *cephv1.ClusterSpec, name string, pool cephv1.PoolSpec, pgCount, appName string) error {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	if err := a.Errors["CreateReplicatedPool"]; err != nil {
+		return err
+	}
+
+	p, ok := a.Pools[name]
+	if !ok {
+		p = a.addPool(name, pool.Replicated.Size)
+		p.PgCount = pgCount
+	}
+	p.Size = pool.Replicated.Size
+	a.setPoolSpec(p, pool, appName)
+	return nil
+}
+
+func (a *FakeCephAdmin) CreateECPool(name, ecProfileName string, pool cephv1.PoolSpec, pgCount, appName string, enableECOverwrite bool) error {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	if err := a.Errors["CreateECPool"]; err != nil {
+		return err
+	}
+
+	p, ok := a.Pools[name]
+	if !ok {
+		p = a.addPool(name, pool.ErasureCoded.DataChunks+pool.ErasureCoded.CodingChunks)
+		p.PgCount = pgCount
+		p.ErasureCodeProfile = ecProfileName
+	}
+	if enableECOverwrite {
+		p.Properties["allow_ec_overwrites"] = "true"
+	}
+	a.setPoolSpec(p, pool, appName)
+	return nil
+}
+
+func (a *FakeCephAdmin) setPoolSpec(p *FakePool, pool cephv1.PoolSpec, appName string) {
+	if appName == "" {
+		appName = "rbd"
+	}
+	p.Application = appName
+	for name, value := range pool.Parameters {
+		p.Properties[name] = value
+	}
+	if pool.CompressionMode != "" {
+		p.Properties["compression_mode"] = pool.CompressionMode
+	}
+}
+
+func (a *FakeCephAdmin) DeletePool(name string) error {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	if err := a.Errors["DeletePool"]; err != nil {
+		return err
+	}
+
+	pool, ok := a.Pools[name]
+	if !ok {
+		return errors.Errorf("failed to get pool %q details. pool does not exist", name)
+	}
+	if pool.Images > 0 {
+		return errors.Errorf("pool %q contains %d rbd images", name, pool.Images)
+	}
+	delete(a.Pools, name)
+	return nil
+}
+
+func (a *FakeCephAdmin) SetPoolProperty(name, propName, propVal string) error {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	if err := a.Errors["SetPoolProperty"]; err != nil {
+		return err
+	}
+
+	pool, ok := a.Pools[name]
+	if !ok {
+		return errors.Errorf("failed to set property %q of pool %q. pool does not exist", propName, name)
+	}
+	pool.Properties[propName] = propVal
+	return nil
+}
+
+func (a *FakeCephAdmin) SetPoolReplicatedSize(name, size string) error {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	if err := a.Errors["SetPoolReplicatedSize"]; err != nil {
+		return err
+	}
+
+	pool, ok := a.Pools[name]
+	if !ok {
+		return errors.Errorf("failed to set size of pool %q. pool does not exist", name)
+	}
+	replicas, err := strconv.ParseUint(size, 10, 32)
+	if err != nil {
+		return errors.Wrapf(err, "invalid size %q of pool %q", size, name)
+	}
+	pool.Size = uint(replicas)
+	return nil
+}
+
+func (a *FakeCephAdmin) getError(method string) error {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	return a.Errors[method]
+}
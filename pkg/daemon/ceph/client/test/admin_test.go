@@ -0,0 +1,59 @@
+/*
+Copyright 2021 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package test
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFakeCephAdmin(t *testing.T) {
+	admin := NewFakeCephAdmin()
+
+	replicated := cephv1.PoolSpec{Replicated: cephv1.ReplicatedSpec{Size: 3}}
+	assert.NoError(t, admin.CreatePool(&cephv1.ClusterSpec{}, "replicapool", replicated, ""))
+	ec := cephv1.PoolSpec{ErasureCoded: cephv1.ErasureCodedSpec{DataChunks: 2, CodingChunks: 1}}
+	assert.NoError(t, admin.CreatePool(&cephv1.ClusterSpec{}, "ecpool", ec, "rgw"))
+	assert.Error(t, admin.CreatePool(&cephv1.ClusterSpec{}, "invalid", cephv1.PoolSpec{}, ""))
+	assert.Equal(t, []string{"ecpool", "replicapool"}, admin.PoolNames())
+
+	details, err := admin.GetPoolDetails("ecpool")
+	assert.NoError(t, err)
+	assert.Equal(t, "ecpool_ecprofile", details.ErasureCodeProfile)
+	assert.Equal(t, "true", admin.Pools["ecpool"].Properties["allow_ec_overwrites"])
+	assert.Equal(t, "rgw", admin.Pools["ecpool"].Application)
+	_, err = admin.GetPoolDetails("missing")
+	assert.Error(t, err)
+
+	assert.NoError(t, admin.SetPoolReplicatedSize("replicapool", "2"))
+	assert.Equal(t, uint(2), admin.Pools["replicapool"].Size)
+
+	// a pool with images cannot be deleted
+	admin.Pools["replicapool"].Images = 1
+	assert.Error(t, admin.DeletePool("replicapool"))
+	admin.Pools["replicapool"].Images = 0
+	assert.NoError(t, admin.DeletePool("replicapool"))
+	assert.Error(t, admin.DeletePool("replicapool"))
+
+	// injected errors
+	admin.Errors["ListPools"] = errors.New("failed")
+	_, err = admin.ListPools()
+	assert.Error(t, err)
+}
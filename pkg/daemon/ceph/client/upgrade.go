@@ -440,3 +440,39 @@ func getRetryConfig(clusterInfo *ClusterInfo, daemonType string) (int, time.Dura
 
 	return defaultMaxRetries, defaultRetryDelay
 }
+
+// OSDFailureDomains returns the name of the CRUSH bucket of the given type that each OSD belongs
+// to. OSDs that are not under a bucket of this type are not part of the returned map.
+func OSDFailureDomains(tree OsdTree, bucketType string) map[int]string {
+	parents := map[int]int{}
+	names := map[int]string{}
+	types := map[int]string{}
+	for _, node := range tree.Nodes {
+		names[node.ID] = node.Name
+		types[node.ID] = node.Type
+		for _, child := range node.Children {
+			parents[child] = node.ID
+		}
+	}
+
+	domains := map[int]string{}
+	for _, node := range tree.Nodes {
+		if node.Type != "osd" {
+			continue
+		}
+		id := node.ID
+		for {
+			parent, ok := parents[id]
+			if !ok {
+				break
+			}
+			if types[parent] == bucketType {
+				domains[node.ID] = names[parent]
+				break
+			}
+			id = parent
+		}
+	}
+
+	return domains
+}
@@ -365,3 +365,36 @@ func TestOSDUpdateShouldCheckOkToStop(t *testing.T) {
 		assert.False(t, OSDUpdateShouldCheckOkToStop(context, clusterInfo))
 	})
 }
+
+func TestOSDFailureDomains(t *testing.T) {
+	treeRaw := []byte(`
+	{
+		"nodes": [
+			{"id": -1, "name": "default", "type": "root", "children": [-2, -3]},
+			{"id": -2, "name": "rack-a", "type": "rack", "children": [-4, -5]},
+			{"id": -3, "name": "rack-b", "type": "rack", "children": [-6]},
+			{"id": -4, "name": "node1", "type": "host", "children": [0, 1]},
+			{"id": -5, "name": "node2", "type": "host", "children": [2]},
+			{"id": -6, "name": "node3", "type": "host", "children": [3]},
+			{"id": 0, "name": "osd.0", "type": "osd"},
+			{"id": 1, "name": "osd.1", "type": "osd"},
+			{"id": 2, "name": "osd.2", "type": "osd"},
+			{"id": 3, "name": "osd.3", "type": "osd"}
+		],
+		"stray": [
+			{"id": 4, "name": "osd.4", "type": "osd"}
+		]
+	}`)
+	var tree OsdTree
+	err := json.Unmarshal(treeRaw, &tree)
+	assert.NoError(t, err)
+
+	domains := OSDFailureDomains(tree, "host")
+	assert.Equal(t, map[int]string{0: "node1", 1: "node1", 2: "node2", 3: "node3"}, domains)
+
+	domains = OSDFailureDomains(tree, "rack")
+	assert.Equal(t, map[int]string{0: "rack-a", 1: "rack-a", 2: "rack-a", 3: "rack-b"}, domains)
+
+	domains = OSDFailureDomains(tree, "zone")
+	assert.Empty(t, domains)
+}
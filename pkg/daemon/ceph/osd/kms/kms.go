@@ -148,8 +148,19 @@ func GetParam(kmsConfig map[string]string, param string) string {
 	return ""
 }
 
-// ValidateConnectionDetails validates mandatory KMS connection details
+// ValidateConnectionDetails validates mandatory KMS connection details and sets the KMS token as an
+// env variable of the process
 func ValidateConnectionDetails(clusterdContext *clusterd.Context, securitySpec *cephv1.SecuritySpec, ns string) error {
+	return validateConnectionDetails(clusterdContext, securitySpec, ns, true)
+}
+
+// ValidateConnectionDetailsWithoutTokenEnv validates mandatory KMS connection details without
+// changing the env of the process, so that the KMS of several clusters can be validated concurrently
+func ValidateConnectionDetailsWithoutTokenEnv(clusterdContext *clusterd.Context, securitySpec *cephv1.SecuritySpec, ns string) error {
+	return validateConnectionDetails(clusterdContext, securitySpec, ns, false)
+}
+
+func validateConnectionDetails(clusterdContext *clusterd.Context, securitySpec *cephv1.SecuritySpec, ns string, setTokenEnv bool) error {
 	ctx := context.TODO()
 	// A token must be specified
 	if !securitySpec.KeyManagementService.IsTokenAuthEnabled() {
@@ -160,6 +171,7 @@ func ValidateConnectionDetails(clusterdContext *clusterd.Context, securitySpec *
 	provider := GetParam(securitySpec.KeyManagementService.ConnectionDetails, Provider)
 
 	// Validate potential token Secret presence
+	var token []byte
 	if securitySpec.KeyManagementService.IsTokenAuthEnabled() {
 		kmsToken, err := clusterdContext.Clientset.CoreV1().Secrets(ns).Get(ctx, securitySpec.KeyManagementService.TokenSecretName, metav1.GetOptions{})
 		if err != nil {
@@ -167,17 +179,20 @@ func ValidateConnectionDetails(clusterdContext *clusterd.Context, securitySpec *
 		}
 
 		// Check for empty token
-		token, ok := kmsToken.Data[KMSTokenSecretNameKey]
+		var ok bool
+		token, ok = kmsToken.Data[KMSTokenSecretNameKey]
 		if !ok || len(token) == 0 {
 			return errors.Errorf("failed to read k8s kms secret %q key %q (not found or empty)", KMSTokenSecretNameKey, securitySpec.KeyManagementService.TokenSecretName)
 		}
 
 		switch provider {
 		case "vault":
-			// Set the env variable
-			err = os.Setenv(api.EnvVaultToken, string(token))
-			if err != nil {
-				return errors.Wrap(err, "failed to set vault kms token to an env var")
+			if setTokenEnv {
+				// Set the env variable
+				err = os.Setenv(api.EnvVaultToken, string(token))
+				if err != nil {
+					return errors.Wrap(err, "failed to set vault kms token to an env var")
+				}
 			}
 		}
 	}
@@ -202,7 +217,12 @@ func ValidateConnectionDetails(clusterdContext *clusterd.Context, securitySpec *
 		case VaultKVSecretEngineKey:
 			// Append Backend Version if not already present
 			if GetParam(securitySpec.KeyManagementService.ConnectionDetails, vault.VaultBackendKey) == "" {
-				backendVersion, err := BackendVersion(securitySpec.KeyManagementService.ConnectionDetails)
+				// the token is only passed to the vault client, it must not be stored in the connection details
+				backendConfig := map[string]string{api.EnvVaultToken: string(token)}
+				for key, value := range securitySpec.KeyManagementService.ConnectionDetails {
+					backendConfig[key] = value
+				}
+				backendVersion, err := BackendVersion(backendConfig)
 				if err != nil {
 					return errors.Wrap(err, "failed to get backend version")
 				}
@@ -145,6 +145,43 @@ func TestValidateConnectionDetails(t *testing.T) {
 		assert.Equal(t, securitySpec.KeyManagementService.ConnectionDetails["VAULT_BACKEND"], "v2")
 	})
 
+	t.Run("success - validate without changing the env", func(t *testing.T) {
+		os.Unsetenv("VAULT_TOKEN")
+		cluster := fakeVaultServer(t)
+		cluster.Start()
+		defer cluster.Cleanup()
+		core := cluster.Cores[0].Core
+		vault.TestWaitActive(t, core)
+		client := cluster.Cores[0].Client
+		// the token is passed to the client in the config
+		clientToken := ""
+		vaultClient = func(secretConfig map[string]string) (*api.Client, error) {
+			clientToken = secretConfig["VAULT_TOKEN"]
+			return client, nil
+		}
+		if err := client.Sys().Mount("rook-kv1/", &api.MountInput{Type: "kv"}); err != nil {
+			t.Fatal(err)
+		}
+		securitySpec := &cephv1.SecuritySpec{
+			KeyManagementService: cephv1.KeyManagementServiceSpec{
+				ConnectionDetails: map[string]string{
+					"VAULT_SECRET_ENGINE": "kv",
+					"KMS_PROVIDER":        "vault",
+					"VAULT_ADDR":          client.Address(),
+					"VAULT_BACKEND_PATH":  "rook-kv1",
+				},
+				TokenSecretName: "vault-token",
+			},
+		}
+		err = ValidateConnectionDetailsWithoutTokenEnv(context, securitySpec, ns)
+		assert.NoError(t, err, "")
+		assert.Equal(t, "v1", securitySpec.KeyManagementService.ConnectionDetails["VAULT_BACKEND"])
+		assert.Equal(t, "token", clientToken)
+		assert.NotContains(t, securitySpec.KeyManagementService.ConnectionDetails, "VAULT_TOKEN")
+		_, ok := os.LookupEnv("VAULT_TOKEN")
+		assert.False(t, ok)
+	})
+
 }
 
 func TestSetTokenToEnvVar(t *testing.T) {
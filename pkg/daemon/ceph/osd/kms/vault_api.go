@@ -61,7 +61,12 @@ func newVaultClient(secretConfig map[string]string) (*api.Client, error) {
 
 	// Set the token if provided, token should be set by ValidateConnectionDetails() if applicable
 	// api.NewClient() already looks up the token from the environment but we need to set it here and remove potential malformed tokens
-	client.SetToken(strings.TrimSuffix(os.Getenv(api.EnvVaultToken), "\n"))
+	// The token of the config has precedence, it is set when the env of the process must not be changed
+	token := secretConfig[api.EnvVaultToken]
+	if token == "" {
+		token = os.Getenv(api.EnvVaultToken)
+	}
+	client.SetToken(strings.TrimSuffix(token, "\n"))
 
 	// Set Vault address, was validated by ValidateConnectionDetails()
 	err = client.SetAddress(strings.TrimSuffix(secretConfig[api.EnvVaultAddress], "\n"))
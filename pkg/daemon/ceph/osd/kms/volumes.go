@@ -79,6 +79,12 @@ func VaultVolumeAndMount(config map[string]string) (v1.Volume, v1.VolumeMount) {
 	return v, m
 }
 
+// TLSSecretKeyAndFileName returns the key of the Secret of a TLS connection detail and the name of
+// the file of the Secret value when mounted
+func TLSSecretKeyAndFileName(tlsOption string) (string, string) {
+	return tlsSecretKeyToCheck(tlsOption), tlsSecretPath(tlsOption)
+}
+
 func tlsSecretPath(tlsOption string) string {
 	switch tlsOption {
 	case api.EnvVaultCACert:
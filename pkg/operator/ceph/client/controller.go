@@ -23,6 +23,7 @@ import (
 	"reflect"
 	"regexp"
 	"strings"
+	"time"
 
 	"github.com/pkg/errors"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -110,6 +111,25 @@ func add(mgr manager.Manager, r reconcile.Reconciler) error {
 		return err
 	}
 
+	// Watch the secrets in other namespaces, they are labeled with their client since they cannot be owned
+	err = c.Watch(&source.Kind{Type: &v1.Secret{TypeMeta: metav1.TypeMeta{Kind: "Secret", APIVersion: v1.SchemeGroupVersion.String()}}}, handler.EnqueueRequestsFromMapFunc(targetSecretClient), predicateTargetSecretDeleted())
+	if err != nil {
+		return err
+	}
+
+	// Build Handler function to return the list of ceph clients
+	// This is used by the watcher below
+	handlerFunc, err := opcontroller.ObjectToCRMapper(mgr.GetClient(), &cephv1.CephClientList{}, mgr.GetScheme())
+	if err != nil {
+		return err
+	}
+
+	// Watch for ConfigMap "rook-ceph-mon-endpoints" update and reconcile, which will update the mon_host of the secrets in the config format
+	err = c.Watch(&source.Kind{Type: &v1.ConfigMap{TypeMeta: metav1.TypeMeta{Kind: "ConfigMap", APIVersion: v1.SchemeGroupVersion.String()}}}, handler.EnqueueRequestsFromMapFunc(handlerFunc), mon.PredicateMonEndpointChanges())
+	if err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -120,6 +140,7 @@ func add(mgr manager.Manager, r reconcile.Reconciler) error {
 func (r *ReconcileCephClient) Reconcile(context context.Context, request reconcile.Request) (reconcile.Result, error) {
 	// workaround because the rook logging mechanism is not compatible with the controller-runtime logging interface
 	reconcileResponse, err := r.reconcile(request)
+	reconcileResponse, err = opcontroller.RequeueIfCephCommandsUnavailable(reconcileResponse, err)
 	if err != nil {
 		logger.Errorf("failed to reconcile %v", err)
 	}
@@ -148,7 +169,7 @@ func (r *ReconcileCephClient) reconcile(request reconcile.Request) (reconcile.Re
 
 	// The CR was just created, initializing status fields
 	if cephClient.Status == nil {
-		r.updateStatus(r.client, request.NamespacedName, cephv1.ConditionProgressing)
+		r.updateStatus(r.client, request.NamespacedName, cephv1.ConditionProgressing, nil)
 	}
 
 	// Make sure a CephCluster is present otherwise do nothing
@@ -205,26 +226,33 @@ func (r *ReconcileCephClient) reconcile(request reconcile.Request) (reconcile.Re
 	}
 
 	// Create or Update client
-	err = r.createOrUpdateClient(cephClient)
+	requeueAfter, err := r.createOrUpdateClient(cephClient)
 	if err != nil {
 		if strings.Contains(err.Error(), opcontroller.UninitializedCephConfigError) {
 			logger.Info(opcontroller.OperatorNotInitializedMessage)
 			return opcontroller.WaitForRequeueIfOperatorNotInitialized, nil
 		}
-		r.updateStatus(r.client, request.NamespacedName, cephv1.ConditionFailure)
+		r.updateStatus(r.client, request.NamespacedName, cephv1.ConditionFailure, nil)
 		return reconcile.Result{}, errors.Wrapf(err, "failed to create or update client %q", cephClient.Name)
 	}
 
 	// Success! Let's update the status
-	r.updateStatus(r.client, request.NamespacedName, cephv1.ConditionReady)
+	r.updateStatus(r.client, request.NamespacedName, cephv1.ConditionReady, cephClient.Status)
+
+	// Requeue when the key must be rotated or its rotation completed
+	if requeueAfter > 0 {
+		logger.Debugf("done reconciling, requeuing after %s to rotate the key", requeueAfter)
+		return reconcile.Result{RequeueAfter: requeueAfter}, nil
+	}
 
 	// Return and do not requeue
 	logger.Debug("done reconciling")
 	return reconcile.Result{}, nil
 }
 
-// Create the client
-func (r *ReconcileCephClient) createOrUpdateClient(cephClient *cephv1.CephClient) error {
+// Create the client, rotate its key and store the key in its Secrets. Returns how long to wait before the
+// rotation of the key must be reconciled again, or 0 when the key is not being rotated.
+func (r *ReconcileCephClient) createOrUpdateClient(cephClient *cephv1.CephClient) (time.Duration, error) {
 	logger.Infof("creating client %s in namespace %s", cephClient.Name, cephClient.Namespace)
 
 	// Generate the CephX details
@@ -235,31 +263,41 @@ func (r *ReconcileCephClient) createOrUpdateClient(cephClient *cephv1.CephClient
 	if err != nil {
 		key, err = cephclient.AuthGetOrCreateKey(r.context, r.clusterInfo, clientEntity, caps)
 		if err != nil {
-			return errors.Wrapf(err, "failed to create client %q", cephClient.Name)
+			return 0, errors.Wrapf(err, "failed to create client %q", cephClient.Name)
 		}
 	} else {
 		err = cephclient.AuthUpdateCaps(r.context, r.clusterInfo, clientEntity, caps)
 		if err != nil {
-			return errors.Wrapf(err, "client %q exists, failed to update client caps", cephClient.Name)
+			return 0, errors.Wrapf(err, "client %q exists, failed to update client caps", cephClient.Name)
 		}
 	}
 
+	// Rotate the key if a rotation is due, the Secrets get the pending key next to the current key until it is committed
+	key, pendingKey, requeueAfter, err := r.rotateKey(cephClient, clientEntity, key)
+	if err != nil {
+		return 0, err
+	}
+
+	// Store the key in the Secrets of the other namespaces
+	cephClient.Status.TargetSecrets, err = r.createOrUpdateTargetSecrets(cephClient, key, pendingKey)
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to create or update the secrets of client %q in other namespaces", cephClient.Name)
+	}
+
 	// Generate Kubernetes Secret
 	secret := &v1.Secret{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      generateCephUserSecretName(cephClient),
 			Namespace: cephClient.Namespace,
 		},
-		StringData: map[string]string{
-			cephClient.Name: key,
-		},
-		Type: k8sutil.RookType,
+		StringData: r.generateSecretData(cephClient, cephClient.Spec.SecretFormat, key, pendingKey),
+		Type:       k8sutil.RookType,
 	}
 
 	// Set CephClient owner ref to the Secret
 	err = controllerutil.SetControllerReference(cephClient, secret, r.scheme)
 	if err != nil {
-		return errors.Wrapf(err, "failed to set owner reference to ceph client secret %q", secret.Name)
+		return 0, errors.Wrapf(err, "failed to set owner reference to ceph client secret %q", secret.Name)
 	}
 
 	// Create or Update Kubernetes Secret
@@ -268,21 +306,21 @@ func (r *ReconcileCephClient) createOrUpdateClient(cephClient *cephv1.CephClient
 		if kerrors.IsNotFound(err) {
 			logger.Debugf("creating secret for %q", secret.Name)
 			if _, err := r.context.Clientset.CoreV1().Secrets(cephClient.Namespace).Create(r.clusterInfo.Context, secret, metav1.CreateOptions{}); err != nil {
-				return errors.Wrapf(err, "failed to create secret for %q", secret.Name)
+				return 0, errors.Wrapf(err, "failed to create secret for %q", secret.Name)
 			}
 			logger.Infof("created client %q", cephClient.Name)
-			return nil
+			return requeueAfter, nil
 		}
-		return errors.Wrapf(err, "failed to get secret for %q", secret.Name)
+		return 0, errors.Wrapf(err, "failed to get secret for %q", secret.Name)
 	}
 	logger.Debugf("updating secret for %s", secret.Name)
 	_, err = r.context.Clientset.CoreV1().Secrets(cephClient.Namespace).Update(r.clusterInfo.Context, secret, metav1.UpdateOptions{})
 	if err != nil {
-		return errors.Wrapf(err, "failed to update secret for %q", secret.Name)
+		return 0, errors.Wrapf(err, "failed to update secret for %q", secret.Name)
 	}
 
 	logger.Infof("updated client %q", cephClient.Name)
-	return nil
+	return requeueAfter, nil
 }
 
 // Delete the client
@@ -292,6 +330,11 @@ func (r *ReconcileCephClient) deleteClient(cephClient *cephv1.CephClient) error
 		return errors.Wrapf(err, "failed to delete client %q", cephClient.Name)
 	}
 
+	// The secret in the namespace of the client is deleted with its owner
+	if err := r.deleteTargetSecrets(cephClient, []string{}); err != nil {
+		return err
+	}
+
 	logger.Infof("deleted client %q", cephClient.Name)
 	return nil
 }
@@ -317,6 +360,32 @@ func ValidateClient(context *clusterd.Context, cephClient *cephv1.CephClient) er
 		}
 	}
 
+	// Validate the secrets in other namespaces
+	targets := map[string]bool{}
+	for _, target := range cephClient.Spec.TargetSecrets {
+		if target.Namespace == "" {
+			return errors.New("no namespace specified for target secret")
+		}
+		name := types.NamespacedName{Namespace: target.Namespace, Name: targetSecretName(cephClient, target)}
+		if name.Namespace == cephClient.Namespace && name.Name == generateCephUserSecretName(cephClient) {
+			return errors.Errorf("target secret %q is the secret of the client", name.String())
+		}
+		if targets[name.String()] {
+			return errors.Errorf("duplicate target secret %q", name.String())
+		}
+		targets[name.String()] = true
+	}
+
+	// Validate the key rotation
+	if cephClient.Spec.KeyRotation != nil {
+		if cephClient.Spec.KeyRotation.Period != nil && cephClient.Spec.KeyRotation.Period.Duration <= 0 {
+			return errors.New("key rotation period must be positive")
+		}
+		if cephClient.Spec.KeyRotation.GracePeriod != nil && cephClient.Spec.KeyRotation.GracePeriod.Duration < 0 {
+			return errors.New("key rotation grace period must not be negative")
+		}
+	}
+
 	return nil
 }
 
@@ -333,8 +402,9 @@ func generateClientName(name string) string {
 	return fmt.Sprintf("client.%s", name)
 }
 
-// updateStatus updates an object with a given status
-func (r *ReconcileCephClient) updateStatus(client client.Client, name types.NamespacedName, status cephv1.ConditionType) {
+// updateStatus updates an object with a given status, and with the target secrets and the key rotation of
+// the observed status if any
+func (r *ReconcileCephClient) updateStatus(client client.Client, name types.NamespacedName, status cephv1.ConditionType, observed *cephv1.CephClientStatus) {
 	cephClient := &cephv1.CephClient{}
 	if err := client.Get(r.opManagerContext, name, cephClient); err != nil {
 		if kerrors.IsNotFound(err) {
@@ -349,6 +419,10 @@ func (r *ReconcileCephClient) updateStatus(client client.Client, name types.Name
 	}
 
 	cephClient.Status.Phase = status
+	if observed != nil {
+		cephClient.Status.TargetSecrets = observed.TargetSecrets
+		cephClient.Status.KeyRotation = observed.KeyRotation
+	}
 	if cephClient.Status.Phase == cephv1.ConditionReady {
 		cephClient.Status.Info = generateStatusInfo(cephClient)
 	}
@@ -22,6 +22,7 @@ import (
 	"os"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/coreos/pkg/capnslog"
 	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
@@ -67,6 +68,34 @@ func TestValidateClient(t *testing.T) {
 	}
 	err = ValidateClient(context, &p)
 	assert.Nil(t, err)
+
+	// target secrets must specify a namespace
+	p.Spec.TargetSecrets = []cephv1.ClientTargetSecret{{Name: "ceph"}}
+	err = ValidateClient(context, &p)
+	assert.Error(t, err)
+
+	// target secrets must not replace the secret of the client
+	p.Spec.TargetSecrets = []cephv1.ClientTargetSecret{{Namespace: "myns"}}
+	err = ValidateClient(context, &p)
+	assert.Error(t, err)
+
+	// target secrets must be unique
+	p.Spec.TargetSecrets = []cephv1.ClientTargetSecret{{Namespace: "app1"}, {Namespace: "app1", Name: "rook-ceph-client-client1"}}
+	err = ValidateClient(context, &p)
+	assert.Error(t, err)
+
+	p.Spec.TargetSecrets = []cephv1.ClientTargetSecret{{Namespace: "app1"}, {Namespace: "myns", Name: "ceph"}}
+	err = ValidateClient(context, &p)
+	assert.NoError(t, err)
+
+	// the key rotation period must be positive
+	p.Spec.KeyRotation = &cephv1.ClientKeyRotationSpec{Period: &metav1.Duration{}}
+	err = ValidateClient(context, &p)
+	assert.Error(t, err)
+
+	p.Spec.KeyRotation = &cephv1.ClientKeyRotationSpec{Period: &metav1.Duration{Duration: time.Hour}, GracePeriod: &metav1.Duration{}}
+	err = ValidateClient(context, &p)
+	assert.NoError(t, err)
 }
 
 func TestGenerateClient(t *testing.T) {
@@ -0,0 +1,159 @@
+/*
+Copyright 2021 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	cephclient "github.com/rook/rook/pkg/daemon/ceph/client"
+	"github.com/rook/rook/pkg/operator/ceph/config"
+	"github.com/rook/rook/pkg/operator/ceph/version"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// rotateKeyAnnotation requests the rotation of the key of a client when its value changes
+	rotateKeyAnnotation = "ceph.rook.io/rotate-key"
+
+	defaultKeyRotationGracePeriod = time.Hour
+)
+
+var (
+	// keyRotationMinVersion is the first version with the pending keys
+	keyRotationMinVersion = version.Quincy
+
+	// now is the current time, a var for the tests
+	now = time.Now
+)
+
+// rotateKey rotates the key of the client when a rotation is due and returns the key and the pending key, if any,
+// to store in the Secrets with how long to wait before the rotation must be reconciled again, or 0 when there is
+// nothing to wait for.
+//
+// A rotation creates a pending key, which is stored in the Secrets next to the current key during the grace
+// period. Ceph commits the pending key as soon as a client authenticates with it, so the current key remains in
+// the Secrets until the pending key is committed, either by ceph or once the grace period elapsed.
+func (r *ReconcileCephClient) rotateKey(cephClient *cephv1.CephClient, clientEntity, key string) (string, string, time.Duration, error) {
+	if cephClient.Status == nil {
+		cephClient.Status = &cephv1.CephClientStatus{}
+	}
+	rotation := &cephv1.ClientKeyRotationStatus{}
+	if cephClient.Status.KeyRotation != nil {
+		rotation = cephClient.Status.KeyRotation
+	}
+	gracePeriod := keyRotationGracePeriod(cephClient)
+
+	// a rotation is in progress
+	if rotation.PendingSince != nil {
+		currentKey, pendingKey, err := cephclient.AuthGetKeys(r.context, r.clusterInfo, clientEntity)
+		if err != nil {
+			return "", "", 0, errors.Wrapf(err, "failed to get the pending key of client %q", cephClient.Name)
+		}
+		if pendingKey == "" {
+			// the pending key was already committed by ceph
+			logger.Infof("pending key of client %q was committed", cephClient.Name)
+			completeKeyRotation(rotation)
+			return currentKey, "", nextKeyRotation(cephClient), nil
+		}
+		if wait := gracePeriod - now().Sub(rotation.PendingSince.Time); wait > 0 {
+			logger.Debugf("waiting %s before committing the pending key of client %q", wait, cephClient.Name)
+			return currentKey, pendingKey, wait, nil
+		}
+		if err := cephclient.AuthCommitPendingKey(r.context, r.clusterInfo, clientEntity); err != nil {
+			return "", "", 0, errors.Wrapf(err, "failed to commit the pending key of client %q", cephClient.Name)
+		}
+		logger.Infof("rotated the key of client %q", cephClient.Name)
+		completeKeyRotation(rotation)
+		return pendingKey, "", nextKeyRotation(cephClient), nil
+	}
+
+	// the key is rotated when the rotation annotation changes or the period elapsed
+	request := cephClient.Annotations[rotateKeyAnnotation]
+	requested := request != "" && request != rotation.Request
+	if !requested {
+		if !isKeyRotatedPeriodically(cephClient) {
+			return key, "", 0, nil
+		}
+		if wait := nextKeyRotation(cephClient); wait > 0 {
+			return key, "", wait, nil
+		}
+	}
+
+	runningCephVersion, err := cephclient.LeastUptodateDaemonVersion(r.context, r.clusterInfo, config.MonType)
+	if err != nil {
+		return "", "", 0, errors.Wrap(err, "failed to retrieve current ceph version")
+	}
+	if !runningCephVersion.IsAtLeast(keyRotationMinVersion) {
+		return "", "", 0, errors.Errorf("ceph %q is required to rotate the key of client %q, current cluster runs %q", keyRotationMinVersion.String(), cephClient.Name, runningCephVersion.String())
+	}
+
+	pendingKey, err := cephclient.AuthGetOrCreatePendingKey(r.context, r.clusterInfo, clientEntity)
+	if err != nil {
+		return "", "", 0, errors.Wrapf(err, "failed to create the pending key of client %q", cephClient.Name)
+	}
+	if requested {
+		rotation.Request = request
+	}
+	cephClient.Status.KeyRotation = rotation
+	if gracePeriod <= 0 {
+		if err := cephclient.AuthCommitPendingKey(r.context, r.clusterInfo, clientEntity); err != nil {
+			return "", "", 0, errors.Wrapf(err, "failed to commit the pending key of client %q", cephClient.Name)
+		}
+		logger.Infof("rotated the key of client %q without grace period", cephClient.Name)
+		completeKeyRotation(rotation)
+		return pendingKey, "", nextKeyRotation(cephClient), nil
+	}
+	logger.Infof("rotating the key of client %q, the previous key remains valid for %s or until the pending key is used", cephClient.Name, gracePeriod)
+	rotation.PendingSince = &metav1.Time{Time: now()}
+	return key, pendingKey, gracePeriod, nil
+}
+
+func isKeyRotatedPeriodically(cephClient *cephv1.CephClient) bool {
+	return cephClient.Spec.KeyRotation != nil && cephClient.Spec.KeyRotation.Period != nil
+}
+
+// nextKeyRotation returns how long to wait before the next periodic rotation of the key of the client, or 0
+// when the key is not rotated periodically or the rotation is due
+func nextKeyRotation(cephClient *cephv1.CephClient) time.Duration {
+	if !isKeyRotatedPeriodically(cephClient) {
+		return 0
+	}
+	// the key is as old as the client until it is rotated
+	lastRotation := cephClient.CreationTimestamp.Time
+	if cephClient.Status != nil && cephClient.Status.KeyRotation != nil && cephClient.Status.KeyRotation.LastRotationTime != nil {
+		lastRotation = cephClient.Status.KeyRotation.LastRotationTime.Time
+	}
+	wait := cephClient.Spec.KeyRotation.Period.Duration - now().Sub(lastRotation)
+	if wait < 0 {
+		return 0
+	}
+	return wait
+}
+
+func completeKeyRotation(rotation *cephv1.ClientKeyRotationStatus) {
+	rotation.LastRotationTime = &metav1.Time{Time: now()}
+	rotation.PendingSince = nil
+}
+
+func keyRotationGracePeriod(cephClient *cephv1.CephClient) time.Duration {
+	if cephClient.Spec.KeyRotation == nil || cephClient.Spec.KeyRotation.GracePeriod == nil {
+		return defaultKeyRotationGracePeriod
+	}
+	return cephClient.Spec.KeyRotation.GracePeriod.Duration
+}
@@ -0,0 +1,193 @@
+/*
+Copyright 2021 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	"github.com/rook/rook/pkg/clusterd"
+	cephclient "github.com/rook/rook/pkg/daemon/ceph/client"
+	exectest "github.com/rook/rook/pkg/util/exec/test"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	currentKey = "AQCvzWBeIV9lFRAAninzm+8XFxbSfTiPwoX50g=="
+	pendingKey = "AQBnqVxhnBjFIxAA8WGn4DNOiiaX9nH2yzpEXw=="
+	quincy     = "17.2.0 (43e2e60a7559d3f46c9d53f1ca875fd499a1e35e) quincy"
+)
+
+// keyRotationCluster mocks the auth commands of a cluster with a client that may have a pending key
+type keyRotationCluster struct {
+	version    string
+	key        string
+	pendingKey string
+	commits    int
+}
+
+func (c *keyRotationCluster) executor() *exectest.MockExecutor {
+	return &exectest.MockExecutor{
+		MockExecuteCommandWithOutput: func(command string, args ...string) (string, error) {
+			if args[0] == "versions" {
+				return `{"mon":{"ceph version ` + c.version + ` (stable)":3}}`, nil
+			}
+			if args[0] != "auth" {
+				return "", nil
+			}
+			switch args[1] {
+			case "get-or-create-pending":
+				if c.pendingKey == "" {
+					c.pendingKey = pendingKey
+				}
+				return c.keys(), nil
+			case "get":
+				return c.keys(), nil
+			case "commit-pending":
+				c.key, c.pendingKey = c.pendingKey, ""
+				c.commits++
+			}
+			return "", nil
+		},
+	}
+}
+
+func (c *keyRotationCluster) keys() string {
+	if c.pendingKey == "" {
+		return `[{"entity":"client.my-client","key":"` + c.key + `","caps":{"mon":"allow r"}}]`
+	}
+	return `[{"entity":"client.my-client","key":"` + c.key + `","pending_key":"` + c.pendingKey + `","caps":{"mon":"allow r"}}]`
+}
+
+func newKeyRotationReconciler(cluster *keyRotationCluster) *ReconcileCephClient {
+	clusterInfo := cephclient.AdminClusterInfo("rook-ceph")
+	clusterInfo.Context = context.TODO()
+	return &ReconcileCephClient{
+		context:     &clusterd.Context{Executor: cluster.executor()},
+		clusterInfo: clusterInfo,
+	}
+}
+
+func TestRotateKeyOnDemand(t *testing.T) {
+	start := time.Now()
+	defer func() { now = time.Now }()
+	now = func() time.Time { return start }
+
+	cluster := &keyRotationCluster{version: quincy, key: currentKey}
+	r := newKeyRotationReconciler(cluster)
+	cephClient := &cephv1.CephClient{ObjectMeta: metav1.ObjectMeta{Name: "my-client", Namespace: "rook-ceph"}}
+
+	// the key is not rotated without a request
+	key, pending, requeueAfter, err := r.rotateKey(cephClient, "client.my-client", currentKey)
+	assert.NoError(t, err)
+	assert.Equal(t, currentKey, key)
+	assert.Empty(t, pending)
+	assert.Equal(t, time.Duration(0), requeueAfter)
+	assert.Nil(t, cephClient.Status.KeyRotation)
+
+	// the pending key is stored next to the current key as soon as the rotation is requested
+	cephClient.Annotations = map[string]string{rotateKeyAnnotation: "1"}
+	key, pending, requeueAfter, err = r.rotateKey(cephClient, "client.my-client", currentKey)
+	assert.NoError(t, err)
+	assert.Equal(t, currentKey, key)
+	assert.Equal(t, pendingKey, pending)
+	assert.Equal(t, defaultKeyRotationGracePeriod, requeueAfter)
+	assert.Equal(t, "1", cephClient.Status.KeyRotation.Request)
+	assert.Equal(t, start, cephClient.Status.KeyRotation.PendingSince.Time)
+	assert.Equal(t, 0, cluster.commits)
+
+	// the pending key is not committed during the grace period
+	now = func() time.Time { return start.Add(10 * time.Minute) }
+	key, pending, requeueAfter, err = r.rotateKey(cephClient, "client.my-client", currentKey)
+	assert.NoError(t, err)
+	assert.Equal(t, currentKey, key)
+	assert.Equal(t, pendingKey, pending)
+	assert.Equal(t, 50*time.Minute, requeueAfter)
+	assert.Equal(t, 0, cluster.commits)
+
+	// the pending key is committed once the grace period elapsed
+	now = func() time.Time { return start.Add(time.Hour) }
+	key, pending, requeueAfter, err = r.rotateKey(cephClient, "client.my-client", currentKey)
+	assert.NoError(t, err)
+	assert.Equal(t, pendingKey, key)
+	assert.Empty(t, pending)
+	assert.Equal(t, time.Duration(0), requeueAfter)
+	assert.Equal(t, 1, cluster.commits)
+	assert.Equal(t, pendingKey, cluster.key)
+	assert.Nil(t, cephClient.Status.KeyRotation.PendingSince)
+	assert.Equal(t, start.Add(time.Hour), cephClient.Status.KeyRotation.LastRotationTime.Time)
+
+	// the same request does not rotate the key again
+	key, pending, _, err = r.rotateKey(cephClient, "client.my-client", pendingKey)
+	assert.NoError(t, err)
+	assert.Equal(t, pendingKey, key)
+	assert.Empty(t, pending)
+	assert.Nil(t, cephClient.Status.KeyRotation.PendingSince)
+}
+
+func TestRotateKeyPeriodically(t *testing.T) {
+	start := time.Now()
+	defer func() { now = time.Now }()
+	now = func() time.Time { return start }
+
+	cluster := &keyRotationCluster{version: quincy, key: currentKey}
+	r := newKeyRotationReconciler(cluster)
+	cephClient := &cephv1.CephClient{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-client", Namespace: "rook-ceph", CreationTimestamp: metav1.Time{Time: start.Add(-time.Hour)}},
+		Spec: cephv1.ClientSpec{
+			KeyRotation: &cephv1.ClientKeyRotationSpec{
+				Period:      &metav1.Duration{Duration: 24 * time.Hour},
+				GracePeriod: &metav1.Duration{Duration: 0},
+			},
+		},
+	}
+
+	// the key is as old as the client
+	key, pending, requeueAfter, err := r.rotateKey(cephClient, "client.my-client", currentKey)
+	assert.NoError(t, err)
+	assert.Equal(t, currentKey, key)
+	assert.Empty(t, pending)
+	assert.Equal(t, 23*time.Hour, requeueAfter)
+
+	// the pending key is committed right away without grace period
+	now = func() time.Time { return start.Add(23 * time.Hour) }
+	key, pending, requeueAfter, err = r.rotateKey(cephClient, "client.my-client", currentKey)
+	assert.NoError(t, err)
+	assert.Equal(t, pendingKey, key)
+	assert.Empty(t, pending)
+	assert.Equal(t, 24*time.Hour, requeueAfter)
+	assert.Equal(t, 1, cluster.commits)
+	assert.Nil(t, cephClient.Status.KeyRotation.PendingSince)
+
+	// the rotation completes when ceph already committed the pending key
+	cephClient.Status.KeyRotation.PendingSince = &metav1.Time{Time: now()}
+	key, pending, _, err = r.rotateKey(cephClient, "client.my-client", pendingKey)
+	assert.NoError(t, err)
+	assert.Equal(t, pendingKey, key)
+	assert.Empty(t, pending)
+	assert.Nil(t, cephClient.Status.KeyRotation.PendingSince)
+	assert.Equal(t, 1, cluster.commits)
+
+	// the pending keys require quincy
+	cluster.version = "16.2.6 (ee28fb57e47e9f88813e24bbf4c14496ca299d31) pacific"
+	now = func() time.Time { return start.Add(72 * time.Hour) }
+	_, _, _, err = r.rotateKey(cephClient, "client.my-client", pendingKey)
+	assert.Error(t, err)
+}
@@ -0,0 +1,208 @@
+/*
+Copyright 2021 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	cephclient "github.com/rook/rook/pkg/daemon/ceph/client"
+	"github.com/rook/rook/pkg/operator/k8sutil"
+	v1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const (
+	// the Secrets in other namespaces cannot be owned by the CephClient, so they are labeled with the client
+	clientNameLabel      = "ceph.rook.io/client-name"
+	clientNamespaceLabel = "ceph.rook.io/client-namespace"
+
+	keyringSecretKey = "keyring"
+	configSecretKey  = "ceph.conf"
+	// the pending key of a rotation in progress is stored next to the key, under the same entry with this suffix
+	pendingSecretKeySuffix = ".pending"
+
+	keyringTemplate = `[%s]
+	key = %s
+`
+	configTemplate = `[global]
+fsid = %s
+mon_host = %s
+`
+)
+
+// generateSecretData returns the data of a Secret with the key of the client in the given format, and the pending
+// key of a rotation in progress when it is not empty
+func (r *ReconcileCephClient) generateSecretData(cephClient *cephv1.CephClient, format cephv1.ClientSecretFormat, key, pendingKey string) map[string]string {
+	clientName := generateClientName(cephClient.Name)
+	var data map[string]string
+	switch format {
+	case cephv1.ClientSecretFormatKeyring:
+		data = map[string]string{keyringSecretKey: fmt.Sprintf(keyringTemplate, clientName, key)}
+	case cephv1.ClientSecretFormatConfig:
+		_, monHosts := cephclient.PopulateMonHostMembers(r.clusterInfo.Monitors)
+		// the mons are a map, sort them so the Secret only changes when the mons change
+		sort.Strings(monHosts)
+		data = map[string]string{
+			keyringSecretKey: fmt.Sprintf(keyringTemplate, clientName, key),
+			configSecretKey:  fmt.Sprintf(configTemplate, r.clusterInfo.FSID, strings.Join(monHosts, ",")),
+		}
+	default:
+		data = map[string]string{cephClient.Name: key}
+	}
+	if pendingKey != "" {
+		if format == cephv1.ClientSecretFormatKeyring || format == cephv1.ClientSecretFormatConfig {
+			data[keyringSecretKey+pendingSecretKeySuffix] = fmt.Sprintf(keyringTemplate, clientName, pendingKey)
+		} else {
+			data[cephClient.Name+pendingSecretKeySuffix] = pendingKey
+		}
+	}
+	return data
+}
+
+// targetSecretName returns the name of a Secret of the client in another namespace
+func targetSecretName(cephClient *cephv1.CephClient, target cephv1.ClientTargetSecret) string {
+	if target.Name != "" {
+		return target.Name
+	}
+	return generateCephUserSecretName(cephClient)
+}
+
+func targetSecretLabels(cephClient *cephv1.CephClient) map[string]string {
+	return map[string]string{
+		clientNameLabel:      cephClient.Name,
+		clientNamespaceLabel: cephClient.Namespace,
+	}
+}
+
+// createOrUpdateTargetSecrets stores the key of the client, and its pending key if any, in the Secrets of the
+// other namespaces, deletes the Secrets that are no longer in the spec and returns the Secrets as "namespace/name"
+func (r *ReconcileCephClient) createOrUpdateTargetSecrets(cephClient *cephv1.CephClient, key, pendingKey string) ([]string, error) {
+	targets := []string{}
+	for _, target := range cephClient.Spec.TargetSecrets {
+		format := target.Format
+		if format == "" {
+			format = cephClient.Spec.SecretFormat
+		}
+		secret := &v1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      targetSecretName(cephClient, target),
+				Namespace: target.Namespace,
+				Labels:    targetSecretLabels(cephClient),
+			},
+			StringData: r.generateSecretData(cephClient, format, key, pendingKey),
+			Type:       k8sutil.RookType,
+		}
+
+		existing, err := r.context.Clientset.CoreV1().Secrets(target.Namespace).Get(r.clusterInfo.Context, secret.Name, metav1.GetOptions{})
+		if err != nil {
+			if !kerrors.IsNotFound(err) {
+				return nil, errors.Wrapf(err, "failed to get secret %q in namespace %q", secret.Name, target.Namespace)
+			}
+			logger.Debugf("creating secret %q in namespace %q", secret.Name, target.Namespace)
+			if _, err := r.context.Clientset.CoreV1().Secrets(target.Namespace).Create(r.clusterInfo.Context, secret, metav1.CreateOptions{}); err != nil {
+				return nil, errors.Wrapf(err, "failed to create secret %q in namespace %q", secret.Name, target.Namespace)
+			}
+		} else {
+			// never overwrite the Secrets that were not created for the client
+			if !isTargetSecretOf(existing, cephClient) {
+				return nil, errors.Errorf("secret %q in namespace %q already exists and does not belong to client %q", secret.Name, target.Namespace, cephClient.Name)
+			}
+			logger.Debugf("updating secret %q in namespace %q", secret.Name, target.Namespace)
+			if _, err := r.context.Clientset.CoreV1().Secrets(target.Namespace).Update(r.clusterInfo.Context, secret, metav1.UpdateOptions{}); err != nil {
+				return nil, errors.Wrapf(err, "failed to update secret %q in namespace %q", secret.Name, target.Namespace)
+			}
+		}
+		targets = append(targets, types.NamespacedName{Namespace: target.Namespace, Name: secret.Name}.String())
+	}
+	sort.Strings(targets)
+
+	if err := r.deleteTargetSecrets(cephClient, targets); err != nil {
+		return nil, err
+	}
+	return targets, nil
+}
+
+// deleteTargetSecrets deletes the Secrets of the client in other namespaces, except the ones to keep
+func (r *ReconcileCephClient) deleteTargetSecrets(cephClient *cephv1.CephClient, keep []string) error {
+	selector := metav1.LabelSelector{MatchLabels: targetSecretLabels(cephClient)}
+	secrets, err := r.context.Clientset.CoreV1().Secrets("").List(r.clusterInfo.Context, metav1.ListOptions{LabelSelector: metav1.FormatLabelSelector(&selector)})
+	if err != nil {
+		return errors.Wrapf(err, "failed to list the secrets of client %q", cephClient.Name)
+	}
+	for _, secret := range secrets.Items {
+		name := types.NamespacedName{Namespace: secret.Namespace, Name: secret.Name}.String()
+		if contains(keep, name) {
+			continue
+		}
+		logger.Infof("deleting secret %q of client %q", name, cephClient.Name)
+		err := r.context.Clientset.CoreV1().Secrets(secret.Namespace).Delete(r.clusterInfo.Context, secret.Name, metav1.DeleteOptions{})
+		if err != nil && !kerrors.IsNotFound(err) {
+			return errors.Wrapf(err, "failed to delete secret %q of client %q", name, cephClient.Name)
+		}
+	}
+	return nil
+}
+
+func isTargetSecretOf(secret *v1.Secret, cephClient *cephv1.CephClient) bool {
+	return secret.Labels[clientNameLabel] == cephClient.Name && secret.Labels[clientNamespaceLabel] == cephClient.Namespace
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+// targetSecretClient returns the request of the client of a Secret in another namespace
+func targetSecretClient(obj client.Object) []reconcile.Request {
+	labels := obj.GetLabels()
+	if labels[clientNameLabel] == "" || labels[clientNamespaceLabel] == "" {
+		return nil
+	}
+	return []reconcile.Request{{NamespacedName: types.NamespacedName{Name: labels[clientNameLabel], Namespace: labels[clientNamespaceLabel]}}}
+}
+
+// predicateTargetSecretDeleted reconciles the client when one of its Secrets in another namespace is deleted
+func predicateTargetSecretDeleted() predicate.Funcs {
+	return predicate.Funcs{
+		CreateFunc: func(e event.CreateEvent) bool {
+			return false
+		},
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			return false
+		},
+		DeleteFunc: func(e event.DeleteEvent) bool {
+			return len(targetSecretClient(e.Object)) > 0
+		},
+		GenericFunc: func(e event.GenericEvent) bool {
+			return false
+		},
+	}
+}
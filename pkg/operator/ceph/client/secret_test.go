@@ -0,0 +1,153 @@
+/*
+Copyright 2021 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"testing"
+
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	"github.com/rook/rook/pkg/clusterd"
+	cephclient "github.com/rook/rook/pkg/daemon/ceph/client"
+	testop "github.com/rook/rook/pkg/operator/test"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func newSecretReconciler(t *testing.T) *ReconcileCephClient {
+	clusterInfo := cephclient.AdminClusterInfo("rook-ceph")
+	clusterInfo.Context = context.TODO()
+	clusterInfo.FSID = "c47cac40-9bee-4d52-823b-ccd803ba5bfe"
+	clusterInfo.Monitors = map[string]*cephclient.MonInfo{
+		"b": {Name: "b", Endpoint: "10.0.0.2:6789"},
+		"a": {Name: "a", Endpoint: "10.0.0.1:6789"},
+	}
+	return &ReconcileCephClient{
+		context:     &clusterd.Context{Clientset: testop.New(t, 1)},
+		clusterInfo: clusterInfo,
+	}
+}
+
+func TestGenerateSecretData(t *testing.T) {
+	r := newSecretReconciler(t)
+	cephClient := &cephv1.CephClient{ObjectMeta: metav1.ObjectMeta{Name: "my-client", Namespace: "rook-ceph"}}
+
+	data := r.generateSecretData(cephClient, "", currentKey, "")
+	assert.Equal(t, map[string]string{"my-client": currentKey}, data)
+	data = r.generateSecretData(cephClient, cephv1.ClientSecretFormatKey, currentKey, "")
+	assert.Equal(t, map[string]string{"my-client": currentKey}, data)
+
+	keyring := "[client.my-client]\n\tkey = " + currentKey + "\n"
+	data = r.generateSecretData(cephClient, cephv1.ClientSecretFormatKeyring, currentKey, "")
+	assert.Equal(t, map[string]string{"keyring": keyring}, data)
+
+	data = r.generateSecretData(cephClient, cephv1.ClientSecretFormatConfig, currentKey, "")
+	assert.Equal(t, keyring, data["keyring"])
+	assert.Equal(t, `[global]
+fsid = c47cac40-9bee-4d52-823b-ccd803ba5bfe
+mon_host = [v2:10.0.0.1:3300,v1:10.0.0.1:6789],[v2:10.0.0.2:3300,v1:10.0.0.2:6789]
+`, data["ceph.conf"])
+
+	// the pending key of a rotation is stored next to the current key
+	data = r.generateSecretData(cephClient, "", currentKey, pendingKey)
+	assert.Equal(t, map[string]string{"my-client": currentKey, "my-client.pending": pendingKey}, data)
+	data = r.generateSecretData(cephClient, cephv1.ClientSecretFormatConfig, currentKey, pendingKey)
+	assert.Equal(t, keyring, data["keyring"])
+	assert.Equal(t, "[client.my-client]\n\tkey = "+pendingKey+"\n", data["keyring.pending"])
+	assert.Len(t, data, 3)
+}
+
+func TestCreateOrUpdateTargetSecrets(t *testing.T) {
+	ctx := context.TODO()
+	r := newSecretReconciler(t)
+	cephClient := &cephv1.CephClient{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-client", Namespace: "rook-ceph"},
+		Spec: cephv1.ClientSpec{
+			SecretFormat: cephv1.ClientSecretFormatKeyring,
+			TargetSecrets: []cephv1.ClientTargetSecret{
+				{Namespace: "app1"},
+				{Namespace: "app2", Name: "ceph", Format: cephv1.ClientSecretFormatConfig},
+			},
+		},
+	}
+
+	targets, err := r.createOrUpdateTargetSecrets(cephClient, currentKey, "")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"app1/rook-ceph-client-my-client", "app2/ceph"}, targets)
+	secret, err := r.context.Clientset.CoreV1().Secrets("app1").Get(ctx, "rook-ceph-client-my-client", metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, "my-client", secret.Labels[clientNameLabel])
+	assert.Equal(t, "rook-ceph", secret.Labels[clientNamespaceLabel])
+	assert.Contains(t, secret.StringData["keyring"], currentKey)
+	assert.Empty(t, secret.StringData["ceph.conf"])
+	secret, err = r.context.Clientset.CoreV1().Secrets("app2").Get(ctx, "ceph", metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.NotEmpty(t, secret.StringData["ceph.conf"])
+
+	// the secrets get the pending key next to the current key during a rotation
+	targets, err = r.createOrUpdateTargetSecrets(cephClient, currentKey, pendingKey)
+	assert.NoError(t, err)
+	assert.Len(t, targets, 2)
+	secret, err = r.context.Clientset.CoreV1().Secrets("app1").Get(ctx, "rook-ceph-client-my-client", metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.Contains(t, secret.StringData["keyring"], currentKey)
+	assert.Contains(t, secret.StringData["keyring.pending"], pendingKey)
+
+	// the secrets are updated with the new key once it is committed
+	targets, err = r.createOrUpdateTargetSecrets(cephClient, pendingKey, "")
+	assert.NoError(t, err)
+	assert.Len(t, targets, 2)
+	secret, err = r.context.Clientset.CoreV1().Secrets("app1").Get(ctx, "rook-ceph-client-my-client", metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.Contains(t, secret.StringData["keyring"], pendingKey)
+	assert.NotContains(t, secret.StringData, "keyring.pending")
+
+	// the secrets removed from the spec are deleted
+	cephClient.Spec.TargetSecrets = cephClient.Spec.TargetSecrets[1:]
+	targets, err = r.createOrUpdateTargetSecrets(cephClient, pendingKey, "")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"app2/ceph"}, targets)
+	_, err = r.context.Clientset.CoreV1().Secrets("app1").Get(ctx, "rook-ceph-client-my-client", metav1.GetOptions{})
+	assert.Error(t, err)
+
+	// the secrets of other clients are never overwritten
+	other := &v1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "app-secret", Namespace: "app3"}}
+	_, err = r.context.Clientset.CoreV1().Secrets("app3").Create(ctx, other, metav1.CreateOptions{})
+	assert.NoError(t, err)
+	cephClient.Spec.TargetSecrets = append(cephClient.Spec.TargetSecrets, cephv1.ClientTargetSecret{Namespace: "app3", Name: "app-secret"})
+	_, err = r.createOrUpdateTargetSecrets(cephClient, pendingKey, "")
+	assert.Error(t, err)
+
+	// all the secrets are deleted with the client
+	err = r.deleteTargetSecrets(cephClient, []string{})
+	assert.NoError(t, err)
+	_, err = r.context.Clientset.CoreV1().Secrets("app2").Get(ctx, "ceph", metav1.GetOptions{})
+	assert.Error(t, err)
+	_, err = r.context.Clientset.CoreV1().Secrets("app3").Get(ctx, "app-secret", metav1.GetOptions{})
+	assert.NoError(t, err)
+}
+
+func TestTargetSecretClient(t *testing.T) {
+	secret := &v1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "ceph", Namespace: "app1"}}
+	assert.Empty(t, targetSecretClient(secret))
+
+	secret.Labels = map[string]string{clientNameLabel: "my-client", clientNamespaceLabel: "rook-ceph"}
+	assert.Equal(t, []reconcile.Request{{NamespacedName: types.NamespacedName{Name: "my-client", Namespace: "rook-ceph"}}}, targetSecretClient(secret))
+}
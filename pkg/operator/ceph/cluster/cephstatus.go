@@ -21,13 +21,16 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/pkg/errors"
 	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
 	"github.com/rook/rook/pkg/clusterd"
 	cephclient "github.com/rook/rook/pkg/daemon/ceph/client"
+	"github.com/rook/rook/pkg/operator/ceph/cluster/mgr"
 	"github.com/rook/rook/pkg/operator/ceph/config"
 	opcontroller "github.com/rook/rook/pkg/operator/ceph/controller"
 	"github.com/rook/rook/pkg/operator/ceph/reporting"
@@ -36,6 +39,7 @@ import (
 	v1 "k8s.io/api/core/v1"
 	kerrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
@@ -51,6 +55,13 @@ type cephStatusChecker struct {
 	interval    *time.Duration
 	client      client.Client
 	isExternal  bool
+	recorder    record.EventRecorder
+	// dashboardAccessLock guards the reconfiguration of the dashboard access after a mgr failover,
+	// so a single goroutine configures it at a time
+	dashboardAccessLock    sync.Mutex
+	dashboardAccessRunning bool
+	// dashboardAccessPending is the spec to apply again when the mgr failed over during the configuration
+	dashboardAccessPending *cephv1.ClusterSpec
 }
 
 // newCephStatusChecker creates a new HealthChecker object
@@ -155,8 +166,9 @@ func (c *cephStatusChecker) configureHealthSettings(status cephclient.CephStatus
 		logger.Debugf("Health: %q, code: %q, message: %q", check.Severity, healthCode, check.Summary.Message)
 	}
 
-	// disable the insecure global id if there are no old clients
-	if _, ok := status.Health.Checks["AUTH_INSECURE_GLOBAL_ID_RECLAIM_ALLOWED"]; ok {
+	// disable the insecure global id if there are no old clients, unless the warning is muted to
+	// keep allowing the insecure global id
+	if check, ok := status.Health.Checks["AUTH_INSECURE_GLOBAL_ID_RECLAIM_ALLOWED"]; ok && !check.Muted {
 		if _, ok := status.Health.Checks["AUTH_INSECURE_GLOBAL_ID_RECLAIM"]; !ok {
 			logger.Info("Disabling the insecure global ID as no legacy clients are currently connected. If you still require the insecure connections, see the CVE to suppress the health warning and re-enable the insecure connections. https://docs.ceph.com/en/latest/security/CVE-2021-20288/")
 			monStore := config.GetMonStore(c.context, c.clusterInfo)
@@ -184,6 +196,14 @@ func (c *cephStatusChecker) updateCephStatus(status *cephclient.CephStatus, cond
 		return
 	}
 
+	var previousMgr *cephv1.MgrStatus
+	previousChecks := map[string]cephv1.CephHealthMessage{}
+	if cephCluster.Status.CephStatus != nil {
+		previousMgr = cephCluster.Status.CephStatus.Mgr
+		previousChecks = cephCluster.Status.CephStatus.Details
+	}
+	c.reportHealthEvents(cephCluster, previousChecks, status)
+
 	// Update with Ceph Status
 	cephCluster.Status.CephStatus = toCustomResourceStatus(cephCluster.Status, status)
 
@@ -194,6 +214,23 @@ func (c *cephStatusChecker) updateCephStatus(status *cephclient.CephStatus, cond
 	} else {
 		// Update status with Ceph versions
 		cephCluster.Status.CephStatus.Versions = versions
+		refreshUpgradeStatus(cephCluster.Status.UpgradeStatus, versions)
+	}
+
+	// the mgr map stores the active and standby mgrs and the enabled modules
+	mgrMap, err := cephclient.CephMgrMap(c.context, c.clusterInfo)
+	if err != nil {
+		logger.Errorf("failed to get mgr map. %v", err)
+		cephCluster.Status.CephStatus.Mgr = previousMgr
+	} else {
+		cephCluster.Status.CephStatus.Mgr = toMgrStatus(mgrMap)
+		if previousMgr != nil && previousMgr.Active != "" && previousMgr.Active != mgrMap.ActiveName {
+			logger.Infof("mgr failover detected, the active mgr changed from %q to %q", previousMgr.Active, mgrMap.ActiveName)
+			if cephCluster.Spec.Dashboard.Enabled && !c.isExternal {
+				// make sure the new active mgr serves the dashboard users and single sign-on from the spec
+				c.reconfigureDashboardAccess(cephCluster.Spec)
+			}
+		}
 	}
 
 	// Update condition
@@ -201,6 +238,34 @@ func (c *cephStatusChecker) updateCephStatus(status *cephclient.CephStatus, cond
 	opcontroller.UpdateClusterCondition(c.context, cephCluster, c.clusterInfo.NamespacedName(), condition, conditionStatus, reason, message, true)
 }
 
+// reportHealthEvents emits a warning event on the CephCluster for each health check raised since
+// the last status check
+func (c *cephStatusChecker) reportHealthEvents(cephCluster *cephv1.CephCluster, previousChecks map[string]cephv1.CephHealthMessage, status *cephclient.CephStatus) {
+	if c.recorder == nil {
+		return
+	}
+	for _, code := range healthChecksToReport(cephCluster.Spec.HealthCheck, previousChecks, status.Health.Checks) {
+		c.recorder.Event(cephCluster, v1.EventTypeWarning, code, status.Health.Checks[code].Summary.Message)
+	}
+}
+
+// healthChecksToReport returns the health checks that are new and not muted with the HEALTH_ERR
+// severity, or with the HEALTH_WARN severity when their event is enabled in the spec
+func healthChecksToReport(spec cephv1.CephClusterHealthCheckSpec, previousChecks map[string]cephv1.CephHealthMessage, checks map[string]cephclient.CheckMessage) []string {
+	codes := []string{}
+	for code, check := range checks {
+		if _, ok := previousChecks[code]; ok || check.Muted {
+			continue
+		}
+		severity := spec.CheckSeverity(code, check.Severity)
+		if severity == cephclient.CephHealthErr || (severity == cephclient.CephHealthWarn && spec.Checks[code].Event) {
+			codes = append(codes, code)
+		}
+	}
+	sort.Strings(codes)
+	return codes
+}
+
 // toCustomResourceStatus converts the ceph status to the struct expected for the CephCluster CR status
 func toCustomResourceStatus(currentStatus cephv1.ClusterStatus, newStatus *cephclient.CephStatus) *cephv1.CephStatus {
 	s := &cephv1.CephStatus{
@@ -236,6 +301,55 @@ func toCustomResourceStatus(currentStatus cephv1.ClusterStatus, newStatus *cephc
 	return s
 }
 
+// reconfigureDashboardAccess configures the dashboard access in the background after a mgr failover.
+// If the access is already being configured, it is configured again once done so that the last active
+// mgr is configured without starting a goroutine per failover.
+func (c *cephStatusChecker) reconfigureDashboardAccess(spec cephv1.ClusterSpec) {
+	c.dashboardAccessLock.Lock()
+	defer c.dashboardAccessLock.Unlock()
+	if c.dashboardAccessRunning {
+		c.dashboardAccessPending = &spec
+		return
+	}
+	c.dashboardAccessRunning = true
+	go c.configureDashboardAccess(spec)
+}
+
+func (c *cephStatusChecker) configureDashboardAccess(spec cephv1.ClusterSpec) {
+	for {
+		mgrs := mgr.New(c.context, c.clusterInfo, spec, "")
+		if err := mgrs.ConfigureDashboardAccess(); err != nil {
+			logger.Errorf("failed to configure dashboard access after mgr failover. %v", err)
+		}
+
+		c.dashboardAccessLock.Lock()
+		if c.dashboardAccessPending == nil {
+			c.dashboardAccessRunning = false
+			c.dashboardAccessLock.Unlock()
+			return
+		}
+		spec = *c.dashboardAccessPending
+		c.dashboardAccessPending = nil
+		c.dashboardAccessLock.Unlock()
+	}
+}
+
+// toMgrStatus converts the mgr map to the mgr status expected for the CephCluster CR status
+func toMgrStatus(mgrMap *cephclient.MgrMap) *cephv1.MgrStatus {
+	s := &cephv1.MgrStatus{
+		Active:    mgrMap.ActiveName,
+		Available: mgrMap.Available,
+		Modules:   append([]string{}, mgrMap.Modules...),
+	}
+	for _, standby := range mgrMap.Standbys {
+		s.Standbys = append(s.Standbys, standby.Name)
+	}
+	sort.Strings(s.Standbys)
+	sort.Strings(s.Modules)
+
+	return s
+}
+
 func formatTime(t time.Time) string {
 	return t.Format(time.RFC3339)
 }
@@ -127,6 +127,25 @@ func TestCephStatus(t *testing.T) {
 	assert.Equal(t, formatTime(time.Now().Add(-time.Minute).UTC()), formatTime(time.Now().Add(-time.Minute).UTC()))
 }
 
+func TestToMgrStatus(t *testing.T) {
+	mgrMap := &cephclient.MgrMap{
+		ActiveName: "b",
+		Available:  true,
+		Standbys:   []cephclient.MgrStandby{{Name: "c"}, {Name: "a"}},
+		Modules:    []string{"rook", "iostat", "pg_autoscaler"},
+	}
+	s := toMgrStatus(mgrMap)
+	assert.Equal(t, "b", s.Active)
+	assert.True(t, s.Available)
+	assert.Equal(t, []string{"a", "c"}, s.Standbys)
+	assert.Equal(t, []string{"iostat", "pg_autoscaler", "rook"}, s.Modules)
+
+	// no standby
+	s = toMgrStatus(&cephclient.MgrMap{ActiveName: "a"})
+	assert.Equal(t, "a", s.Active)
+	assert.Empty(t, s.Standbys)
+}
+
 func TestNewCephStatusChecker(t *testing.T) {
 	clusterInfo := cephclient.AdminClusterInfo("ns")
 	c := &clusterd.Context{}
@@ -143,9 +162,9 @@ func TestNewCephStatusChecker(t *testing.T) {
 		args args
 		want *cephStatusChecker
 	}{
-		{"default-interval", args{c, clusterInfo, &cephv1.ClusterSpec{}}, &cephStatusChecker{c, clusterInfo, &defaultStatusCheckInterval, c.Client, false}},
-		{"10s-interval", args{c, clusterInfo, &cephv1.ClusterSpec{HealthCheck: cephv1.CephClusterHealthCheckSpec{DaemonHealth: cephv1.DaemonHealthSpec{Status: cephv1.HealthCheckSpec{Interval: &metav1.Duration{Duration: time10s}}}}}}, &cephStatusChecker{c, clusterInfo, &time10s, c.Client, false}},
-		{"10s-interval-external", args{c, clusterInfo, &cephv1.ClusterSpec{External: cephv1.ExternalSpec{Enable: true}, HealthCheck: cephv1.CephClusterHealthCheckSpec{DaemonHealth: cephv1.DaemonHealthSpec{Status: cephv1.HealthCheckSpec{Interval: &metav1.Duration{Duration: time10s}}}}}}, &cephStatusChecker{c, clusterInfo, &time10s, c.Client, true}},
+		{"default-interval", args{c, clusterInfo, &cephv1.ClusterSpec{}}, &cephStatusChecker{context: c, clusterInfo: clusterInfo, interval: &defaultStatusCheckInterval, client: c.Client, isExternal: false}},
+		{"10s-interval", args{c, clusterInfo, &cephv1.ClusterSpec{HealthCheck: cephv1.CephClusterHealthCheckSpec{DaemonHealth: cephv1.DaemonHealthSpec{Status: cephv1.HealthCheckSpec{Interval: &metav1.Duration{Duration: time10s}}}}}}, &cephStatusChecker{context: c, clusterInfo: clusterInfo, interval: &time10s, client: c.Client, isExternal: false}},
+		{"10s-interval-external", args{c, clusterInfo, &cephv1.ClusterSpec{External: cephv1.ExternalSpec{Enable: true}, HealthCheck: cephv1.CephClusterHealthCheckSpec{DaemonHealth: cephv1.DaemonHealthSpec{Status: cephv1.HealthCheckSpec{Interval: &metav1.Duration{Duration: time10s}}}}}}, &cephStatusChecker{context: c, clusterInfo: clusterInfo, interval: &time10s, client: c.Client, isExternal: true}},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -216,6 +235,19 @@ func TestConfigureHealthSettings(t *testing.T) {
 			},
 		},
 	}
+	mutedInsecureGlobalIDStatus := cephclient.CephStatus{
+		Health: cephclient.HealthStatus{
+			Checks: map[string]cephclient.CheckMessage{
+				"AUTH_INSECURE_GLOBAL_ID_RECLAIM_ALLOWED": {
+					Severity: "HEALTH_WARN",
+					Summary: cephclient.Summary{
+						Message: "foo",
+					},
+					Muted: true,
+				},
+			},
+		},
+	}
 
 	type args struct {
 		status                     cephclient.CephStatus
@@ -229,6 +261,7 @@ func TestConfigureHealthSettings(t *testing.T) {
 		{"no-action-one-warning", args{noActionOneWarningStatus, false}},
 		{"disable-insecure-global-id", args{disableInsecureGlobalIDStatus, true}},
 		{"no-disable-insecure-global-id", args{noDisableInsecureGlobalIDStatus, false}},
+		{"muted-insecure-global-id", args{mutedInsecureGlobalIDStatus, false}},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -239,6 +272,36 @@ func TestConfigureHealthSettings(t *testing.T) {
 	}
 }
 
+func TestHealthChecksToReport(t *testing.T) {
+	previous := map[string]cephv1.CephHealthMessage{
+		"MON_DISK_LOW": {Severity: "HEALTH_WARN", Message: "mon a is low on available space"},
+	}
+	checks := map[string]cephclient.CheckMessage{
+		"MON_DISK_LOW":       {Severity: "HEALTH_WARN"},
+		"OSD_FULL":           {Severity: "HEALTH_ERR"},
+		"POOL_NO_REDUNDANCY": {Severity: "HEALTH_WARN"},
+		"OSDMAP_FLAGS":       {Severity: "HEALTH_WARN", Muted: true},
+	}
+
+	// only the new errors that are not muted are reported
+	spec := cephv1.CephClusterHealthCheckSpec{}
+	assert.Equal(t, []string{"OSD_FULL"}, healthChecksToReport(spec, previous, checks))
+
+	// the new warnings are reported when their event is enabled
+	spec.Checks = map[string]cephv1.CephHealthCheckPolicySpec{"POOL_NO_REDUNDANCY": {Event: true}, "OSDMAP_FLAGS": {Event: true}}
+	assert.Equal(t, []string{"OSD_FULL", "POOL_NO_REDUNDANCY"}, healthChecksToReport(spec, previous, checks))
+
+	// a warning whose severity is overridden to HEALTH_ERR is reported
+	spec.Checks = map[string]cephv1.CephHealthCheckPolicySpec{"POOL_NO_REDUNDANCY": {Severity: "HEALTH_ERR"}}
+	assert.Equal(t, []string{"OSD_FULL", "POOL_NO_REDUNDANCY"}, healthChecksToReport(spec, previous, checks))
+
+	// a check whose severity is overridden to HEALTH_OK is not reported
+	spec.Checks = map[string]cephv1.CephHealthCheckPolicySpec{"POOL_NO_REDUNDANCY": {Severity: "HEALTH_OK", Event: true}}
+	assert.Equal(t, []string{"OSD_FULL"}, healthChecksToReport(spec, previous, checks))
+
+	assert.Empty(t, healthChecksToReport(spec, previous, map[string]cephclient.CheckMessage{}))
+}
+
 func TestForceDeleteStuckRookPodsOnNotReadyNodes(t *testing.T) {
 	ctx := context.TODO()
 	clientset := optest.New(t, 1)
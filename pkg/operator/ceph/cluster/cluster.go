@@ -91,6 +91,11 @@ func (c *cluster) reconcileCephDaemons(rookImage string, cephVersion cephver.Cep
 		return errors.Wrap(err, "failed to populate config override config map")
 	}
 
+	// Do not start upgrading the daemons if the upgrade strategy holds the upgrade
+	if err := c.checkUpgradeGate(cephVersion, ""); err != nil {
+		return err
+	}
+
 	// Start the mon pods
 	controller.UpdateCondition(c.context, c.namespacedName, cephv1.ConditionProgressing, v1.ConditionTrue, cephv1.ClusterProgressingReason, "Configuring Ceph Mons")
 	clusterInfo, err := c.mons.Start(c.ClusterInfo, rookImage, cephVersion, *c.Spec)
@@ -119,6 +124,10 @@ func (c *cluster) reconcileCephDaemons(rookImage string, cephVersion cephver.Cep
 		return errors.Wrap(err, "failed to execute post actions after all the ceph monitors started")
 	}
 
+	if err := c.checkUpgradeGate(cephVersion, config.MonType); err != nil {
+		return err
+	}
+
 	// Start Ceph manager
 	controller.UpdateCondition(c.context, c.namespacedName, cephv1.ConditionProgressing, v1.ConditionTrue, cephv1.ClusterProgressingReason, "Configuring Ceph Mgr(s)")
 	mgrs := mgr.New(c.context, c.ClusterInfo, *c.Spec, rookImage)
@@ -127,14 +136,25 @@ func (c *cluster) reconcileCephDaemons(rookImage string, cephVersion cephver.Cep
 		return errors.Wrap(err, "failed to start ceph mgr")
 	}
 
+	if err := c.checkUpgradeGate(cephVersion, config.MgrType); err != nil {
+		return err
+	}
+
 	// Start the OSDs
 	controller.UpdateCondition(c.context, c.namespacedName, cephv1.ConditionProgressing, v1.ConditionTrue, cephv1.ClusterProgressingReason, "Configuring Ceph OSDs")
 	osds := osd.New(c.context, c.ClusterInfo, *c.Spec, rookImage)
 	err = osds.Start()
 	if err != nil {
+		if errors.Is(err, controller.ErrUpgradePaused) {
+			c.updateUpgradeStatus(cephVersion, cephv1.UpgradePhasePaused, err.Error(), "")
+		}
 		return errors.Wrap(err, "failed to start ceph osds")
 	}
 
+	if err := c.checkUpgradeGate(cephVersion, config.OsdType); err != nil {
+		return err
+	}
+
 	// If a stretch cluster, enable the arbiter after the OSDs are created with the CRUSH map
 	if c.Spec.IsStretchCluster() {
 		if err := c.mons.ConfigureArbiter(); err != nil {
@@ -147,6 +167,7 @@ func (c *cluster) reconcileCephDaemons(rookImage string, cephVersion cephver.Cep
 	// We should be done updating by now
 	if c.isUpgrade {
 		c.printOverallCephVersion()
+		c.updateUpgradeStatus(cephVersion, cephv1.UpgradePhaseProgressing, "", "")
 
 		// reset the isUpgrade flag
 		c.isUpgrade = false
@@ -193,6 +214,10 @@ func (c *ClusterController) initializeCluster(cluster *cluster) error {
 
 		err = c.configureLocalCephCluster(cluster)
 		if err != nil {
+			if errors.Is(err, controller.ErrUpgradePaused) {
+				controller.UpdateCondition(c.context, c.namespacedName, cephv1.ConditionProgressing, v1.ConditionTrue, cephv1.ClusterProgressingReason, err.Error())
+				return err
+			}
 			controller.UpdateCondition(c.context, c.namespacedName, cephv1.ConditionProgressing, v1.ConditionFalse, cephv1.ClusterProgressingReason, err.Error())
 			return errors.Wrap(err, "failed to configure local ceph cluster")
 		}
@@ -239,6 +264,9 @@ func (c *ClusterController) configureLocalCephCluster(cluster *cluster) error {
 		return errors.Wrap(err, "failed to create cluster")
 	}
 
+	// The cluster was reconciled with the image, it can now be used to revert a later image
+	c.updateCephImageHistory(cluster.Spec.CephVersion.Image, *cephVersion)
+
 	// Set the condition to the cluster object
 	controller.UpdateCondition(c.context, c.namespacedName, cephv1.ConditionReady, v1.ConditionTrue, cephv1.ClusterCreatedReason, "Cluster created successfully")
 	return nil
@@ -299,6 +327,16 @@ func preClusterStartValidation(cluster *cluster) error {
 		}
 	}
 
+	// Validate the single sign-on of the dashboard
+	if err := cluster.Spec.Dashboard.SSO.Validate(); err != nil {
+		return errors.Wrap(err, "failed to validate the dashboard single sign-on")
+	}
+
+	// Validate the node labels mapped to crush bucket types
+	if err := cluster.Spec.Storage.ValidateCrushTopology(); err != nil {
+		return errors.Wrap(err, "failed to validate the crush topology")
+	}
+
 	logger.Debug("cluster spec successfully validated")
 	return nil
 }
@@ -475,5 +513,10 @@ func (c *cluster) postMonStartupActions() error {
 		return errors.Wrap(err, "failed to create cluster rbd bootstrap peer token")
 	}
 
+	// Mute the health checks from the spec
+	if err := c.configureHealthMutes(); err != nil {
+		return errors.Wrap(err, "failed to mute health checks")
+	}
+
 	return nil
 }
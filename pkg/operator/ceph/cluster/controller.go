@@ -40,6 +40,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	apituntime "k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
@@ -88,6 +89,7 @@ type ClusterController struct {
 	client         client.Client
 	namespacedName types.NamespacedName
 	recorder       *k8sutil.EventReporter
+	eventRecorder  record.EventRecorder
 	OpManagerCtx   context.Context
 }
 
@@ -111,7 +113,8 @@ func newReconciler(mgr manager.Manager, ctx *clusterd.Context, clusterController
 	// add "rook-" prefix to the controller name to make sure it is clear to all reading the events
 	// that they are coming from Rook. The controller name already has context that it is for Ceph
 	// and from the cluster controller.
-	clusterController.recorder = k8sutil.NewEventReporter(mgr.GetEventRecorderFor("rook-" + controllerName))
+	clusterController.eventRecorder = mgr.GetEventRecorderFor("rook-" + controllerName)
+	clusterController.recorder = k8sutil.NewEventReporter(clusterController.eventRecorder)
 
 	return &ReconcileCephCluster{
 		client:            mgr.GetClient(),
@@ -215,6 +218,7 @@ func add(mgr manager.Manager, r reconcile.Reconciler, context *clusterd.Context)
 func (r *ReconcileCephCluster) Reconcile(context context.Context, request reconcile.Request) (reconcile.Result, error) {
 	// workaround because the rook logging mechanism is not compatible with the controller-runtime logging interface
 	reconcileResponse, cephCluster, err := r.reconcile(request)
+	reconcileResponse, err = opcontroller.RequeueIfCephCommandsUnavailable(reconcileResponse, err)
 
 	return reporting.ReportReconcileResult(logger, r.clusterController.recorder,
 		cephCluster, reconcileResponse, err)
@@ -253,9 +257,20 @@ func (r *ReconcileCephCluster) reconcile(request reconcile.Request) (reconcile.R
 		return r.reconcileDelete(cephCluster)
 	}
 
+	// Revert to the last known good image if requested, the cluster is then reconciled with it
+	if cephCluster.Spec.CephVersion.RevertToLastKnownGood {
+		if err := r.clusterController.revertToLastKnownGoodImage(cephCluster); err != nil {
+			return reconcile.Result{}, cephCluster, err
+		}
+	}
+
 	// Do reconcile here!
 	ownerInfo := k8sutil.NewOwnerInfo(cephCluster, r.scheme)
 	if err := r.clusterController.reconcileCephCluster(cephCluster, ownerInfo); err != nil {
+		if errors.Is(err, opcontroller.ErrUpgradePaused) {
+			logger.Infof("ceph upgrade of cluster %q is paused. %v", cephCluster.Name, err)
+			return opcontroller.WaitForRequeueIfUpgradePaused, cephCluster, nil
+		}
 		return reconcile.Result{}, cephCluster, errors.Wrapf(err, "failed to reconcile cluster %q", cephCluster.Name)
 	}
 
@@ -402,6 +417,10 @@ func (c *ClusterController) requestClusterDelete(cluster *cephv1.CephCluster) (r
 		}
 	}
 
+	// close the connections to the monitors of the cluster and forget the timeouts of its commands
+	cephclient.CloseMonCommandConns(cluster.Namespace)
+	cephclient.ResetCommandGuard(cluster.Namespace)
+
 	if cluster, ok := c.clusterMap[cluster.Namespace]; ok {
 		delete(c.clusterMap, cluster.Namespace)
 	}
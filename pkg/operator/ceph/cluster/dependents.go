@@ -32,15 +32,18 @@ var (
 	// must use plural kinds
 	cephClusterDependentPluralKinds []string = []string{
 		"CephBlockPools",
+		"CephBlockPoolRadosNamespaces",
 		"CephRBDMirrors",
 		"CephFilesystems",
 		"CephFilesystemMirrors",
+		"CephFilesystemSubVolumeGroups",
 		"CephObjectStores",
 		"CephObjectStoreUsers",
 		"CephObjectZones",
 		"CephObjectZoneGroups",
 		"CephObjectRealms",
 		"CephNFSes",
+		"CephNFSExports",
 		"CephClients",
 	}
 )
@@ -190,35 +190,41 @@ func TestCephClusterDependents(t *testing.T) {
 	t.Run("All", func(t *testing.T) {
 		c = newClusterdCtx(
 			&cephv1.CephBlockPool{ObjectMeta: meta("pool-1")},
+			&cephv1.CephBlockPoolRadosNamespace{ObjectMeta: meta("radosnamespace-1")},
 			&cephv1.CephRBDMirror{ObjectMeta: meta("rbdmirror-1")},
 			&cephv1.CephRBDMirror{ObjectMeta: meta("rbdmirror-2")},
 			&cephv1.CephFilesystem{ObjectMeta: meta("filesystem-1")},
 			&cephv1.CephFilesystemMirror{ObjectMeta: meta("fsmirror-1")},
 			&cephv1.CephFilesystemMirror{ObjectMeta: meta("fsmirror-2")},
+			&cephv1.CephFilesystemSubVolumeGroup{ObjectMeta: meta("group-a")},
 			&cephv1.CephObjectStore{ObjectMeta: meta("objectstore-1")},
 			&cephv1.CephObjectStoreUser{ObjectMeta: meta("u1")},
 			&cephv1.CephObjectZone{ObjectMeta: meta("zone-1")},
 			&cephv1.CephObjectZoneGroup{ObjectMeta: meta("group-1")},
 			&cephv1.CephObjectRealm{ObjectMeta: meta("realm-1")},
 			&cephv1.CephNFS{ObjectMeta: meta("nfs-1")},
+			&cephv1.CephNFSExport{ObjectMeta: meta("share-1")},
 			&cephv1.CephClient{ObjectMeta: meta("client-1")},
 		)
 		deps, err := CephClusterDependents(c, ns)
 		assert.NoError(t, err)
 		assert.False(t, deps.Empty())
-		assert.ElementsMatch(t, []string{"CephBlockPools", "CephRBDMirrors", "CephFilesystems",
-			"CephFilesystemMirrors", "CephObjectStores", "CephObjectStoreUsers", "CephObjectZones",
-			"CephObjectZoneGroups", "CephObjectRealms", "CephNFSes", "CephClients"}, deps.PluralKinds())
+		assert.ElementsMatch(t, []string{"CephBlockPools", "CephBlockPoolRadosNamespaces", "CephRBDMirrors", "CephFilesystems",
+			"CephFilesystemMirrors", "CephFilesystemSubVolumeGroups", "CephObjectStores", "CephObjectStoreUsers", "CephObjectZones",
+			"CephObjectZoneGroups", "CephObjectRealms", "CephNFSes", "CephNFSExports", "CephClients"}, deps.PluralKinds())
 		assert.ElementsMatch(t, []string{"pool-1"}, deps.OfPluralKind("CephBlockPools"))
+		assert.ElementsMatch(t, []string{"radosnamespace-1"}, deps.OfPluralKind("CephBlockPoolRadosNamespaces"))
 		assert.ElementsMatch(t, []string{"rbdmirror-1", "rbdmirror-2"}, deps.OfPluralKind("CephRBDMirrors"))
 		assert.ElementsMatch(t, []string{"filesystem-1"}, deps.OfPluralKind("CephFilesystems"))
 		assert.ElementsMatch(t, []string{"fsmirror-1", "fsmirror-2"}, deps.OfPluralKind("CephFilesystemMirrors"))
+		assert.ElementsMatch(t, []string{"group-a"}, deps.OfPluralKind("CephFilesystemSubVolumeGroups"))
 		assert.ElementsMatch(t, []string{"objectstore-1"}, deps.OfPluralKind("CephObjectStores"))
 		assert.ElementsMatch(t, []string{"u1"}, deps.OfPluralKind("CephObjectStoreUsers"))
 		assert.ElementsMatch(t, []string{"zone-1"}, deps.OfPluralKind("CephObjectZones"))
 		assert.ElementsMatch(t, []string{"group-1"}, deps.OfPluralKind("CephObjectZoneGroups"))
 		assert.ElementsMatch(t, []string{"realm-1"}, deps.OfPluralKind("CephObjectRealms"))
 		assert.ElementsMatch(t, []string{"nfs-1"}, deps.OfPluralKind("CephNFSes"))
+		assert.ElementsMatch(t, []string{"share-1"}, deps.OfPluralKind("CephNFSExports"))
 		assert.ElementsMatch(t, []string{"client-1"}, deps.OfPluralKind("CephClients"))
 
 		t.Run("and no dependencies in another namespace", func(t *testing.T) {
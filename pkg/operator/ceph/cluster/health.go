@@ -0,0 +1,114 @@
+/*
+Copyright 2021 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/pkg/errors"
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	"github.com/rook/rook/pkg/daemon/ceph/client"
+	"github.com/rook/rook/pkg/operator/k8sutil"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+const (
+	// the health checks muted by Rook are stored in this configmap so they are muted again only when
+	// their settings change and are unmuted when they are removed from the spec
+	healthMuteStoreName = "rook-ceph-health-mutes"
+	healthMuteKey       = "mutes"
+)
+
+// configureHealthMutes mutes the health checks from the spec. A health check is muted again only
+// when its settings change, so the TTL of the mute is not reset at each reconcile.
+func (c *cluster) configureHealthMutes() error {
+	kv := k8sutil.NewConfigMapKVStore(c.Namespace, c.context.Clientset, c.ownerInfo)
+	value, err := kv.GetValue(healthMuteStoreName, healthMuteKey)
+	if err != nil && !kerrors.IsNotFound(err) {
+		return errors.Wrap(err, "failed to get the health checks muted previously")
+	}
+	applied := map[string]cephv1.CephHealthMuteSpec{}
+	if value != "" {
+		if err := json.Unmarshal([]byte(value), &applied); err != nil {
+			return errors.Wrap(err, "failed to parse the health checks muted previously")
+		}
+	}
+
+	toMute, toUnmute := healthMuteChanges(applied, c.Spec.HealthCheck.Mute)
+	if len(toMute) == 0 && len(toUnmute) == 0 {
+		return nil
+	}
+
+	for _, code := range toUnmute {
+		logger.Infof("unmuting health check %q removed from the spec", code)
+		if err := client.UnmuteHealthCheck(c.context, c.ClusterInfo, code); err != nil {
+			return err
+		}
+		delete(applied, code)
+	}
+	for _, mute := range toMute {
+		logger.Infof("muting health check %q", mute.Code)
+		if err := client.MuteHealthCheck(c.context, c.ClusterInfo, mute.Code, healthMuteTTL(mute), mute.Sticky); err != nil {
+			return err
+		}
+		applied[mute.Code] = mute
+	}
+
+	out, err := json.Marshal(applied)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal the muted health checks")
+	}
+	if err := kv.SetValue(healthMuteStoreName, healthMuteKey, string(out)); err != nil {
+		return errors.Wrap(err, "failed to store the muted health checks")
+	}
+
+	return nil
+}
+
+// healthMuteChanges returns the health checks to mute because they are new or their settings
+// changed, and the health checks to unmute because they were removed from the spec
+func healthMuteChanges(applied map[string]cephv1.CephHealthMuteSpec, desired []cephv1.CephHealthMuteSpec) ([]cephv1.CephHealthMuteSpec, []string) {
+	toMute := []cephv1.CephHealthMuteSpec{}
+	desiredCodes := map[string]bool{}
+	for _, mute := range desired {
+		desiredCodes[mute.Code] = true
+		if previous, ok := applied[mute.Code]; !ok || !reflect.DeepEqual(previous, mute) {
+			toMute = append(toMute, mute)
+		}
+	}
+
+	toUnmute := []string{}
+	for code := range applied {
+		if !desiredCodes[code] {
+			toUnmute = append(toUnmute, code)
+		}
+	}
+	sort.Strings(toUnmute)
+
+	return toMute, toUnmute
+}
+
+// healthMuteTTL returns the TTL of the mute in the format expected by ceph
+func healthMuteTTL(mute cephv1.CephHealthMuteSpec) string {
+	if mute.TTL == nil || mute.TTL.Duration <= 0 {
+		return ""
+	}
+	return fmt.Sprintf("%ds", int64(mute.TTL.Duration.Seconds()))
+}
@@ -0,0 +1,87 @@
+/*
+Copyright 2021 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	"github.com/rook/rook/pkg/clusterd"
+	cephclient "github.com/rook/rook/pkg/daemon/ceph/client"
+	testop "github.com/rook/rook/pkg/operator/test"
+	exectest "github.com/rook/rook/pkg/util/exec/test"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestConfigureHealthMutes(t *testing.T) {
+	commands := []string{}
+	executor := &exectest.MockExecutor{
+		MockExecuteCommandWithOutput: func(command string, args ...string) (string, error) {
+			logger.Infof("Command: %s %v", command, args)
+			if args[0] == "health" {
+				for i, arg := range args {
+					// ignore the connection flags
+					if strings.HasPrefix(arg, "--connect-timeout") {
+						commands = append(commands, strings.Join(args[:i], " "))
+						break
+					}
+				}
+			}
+			return "", nil
+		},
+	}
+	clusterInfo := cephclient.AdminClusterInfo("ns")
+	c := &cluster{
+		Namespace:   "ns",
+		ClusterInfo: clusterInfo,
+		context:     &clusterd.Context{Clientset: testop.New(t, 1), Executor: executor},
+		ownerInfo:   cephclient.NewMinimumOwnerInfoWithOwnerRef(),
+		Spec: &cephv1.ClusterSpec{HealthCheck: cephv1.CephClusterHealthCheckSpec{
+			Mute: []cephv1.CephHealthMuteSpec{
+				{Code: "AUTH_INSECURE_GLOBAL_ID_RECLAIM_ALLOWED", TTL: &metav1.Duration{Duration: time.Hour}},
+				{Code: "POOL_NO_REDUNDANCY", Sticky: true},
+			},
+		}},
+	}
+
+	assert.NoError(t, c.configureHealthMutes())
+	assert.Equal(t, []string{
+		"health mute AUTH_INSECURE_GLOBAL_ID_RECLAIM_ALLOWED 3600s",
+		"health mute POOL_NO_REDUNDANCY --sticky",
+	}, commands)
+
+	// the mutes are not applied again if they did not change, to keep their TTL
+	commands = []string{}
+	assert.NoError(t, c.configureHealthMutes())
+	assert.Empty(t, commands)
+
+	// a changed mute is applied again and a removed mute is unmuted
+	c.Spec.HealthCheck.Mute = []cephv1.CephHealthMuteSpec{{Code: "POOL_NO_REDUNDANCY"}}
+	assert.NoError(t, c.configureHealthMutes())
+	assert.Equal(t, []string{
+		"health unmute AUTH_INSECURE_GLOBAL_ID_RECLAIM_ALLOWED",
+		"health mute POOL_NO_REDUNDANCY",
+	}, commands)
+}
+
+func TestHealthMuteTTL(t *testing.T) {
+	assert.Equal(t, "", healthMuteTTL(cephv1.CephHealthMuteSpec{Code: "OSDMAP_FLAGS"}))
+	assert.Equal(t, "90s", healthMuteTTL(cephv1.CephHealthMuteSpec{Code: "OSDMAP_FLAGS", TTL: &metav1.Duration{Duration: 90 * time.Second}}))
+}
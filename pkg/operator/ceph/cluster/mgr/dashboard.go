@@ -105,9 +105,12 @@ func (c *Cluster) configureDashboardModules() error {
 	}
 	if hasChanged {
 		logger.Info("dashboard config has changed. restarting the dashboard module")
-		return c.restartDashboard()
+		if err := c.restartDashboard(); err != nil {
+			return err
+		}
 	}
-	return nil
+
+	return c.ConfigureDashboardAccess()
 }
 
 func (c *Cluster) configureDashboardModuleSettings(daemonID string) (bool, error) {
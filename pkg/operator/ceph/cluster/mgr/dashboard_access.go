@@ -0,0 +1,454 @@
+/*
+Copyright 2021 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mgr
+
+import (
+	"encoding/json"
+	"os"
+	"sort"
+	"strconv"
+	"sync"
+
+	"github.com/pkg/errors"
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	"github.com/rook/rook/pkg/daemon/ceph/client"
+	"github.com/rook/rook/pkg/operator/k8sutil"
+	"github.com/rook/rook/pkg/util"
+	"github.com/rook/rook/pkg/util/exec"
+	v1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// the dashboard users and roles created by Rook are stored in this configmap so they can be
+	// deleted when they are removed from the spec
+	dashboardAccessStoreName = "rook-ceph-dashboard-access"
+	dashboardUsersKey        = "users"
+	dashboardRolesKey        = "roles"
+	dashboardSSOKey          = "sso"
+	// the versions of the password secrets applied by Rook, so the passwords are only set again when
+	// their secret changes and the passwords changed in the dashboard are kept
+	dashboardPasswordsKey = "passwordSecretVersions"
+)
+
+// dashboardAccessMutex serializes the configuration of the dashboard access by the mgr reconcile
+// and after a mgr failover
+var dashboardAccessMutex sync.Mutex
+
+// dashboardRole is the definition of a role returned by "ceph dashboard ac-role-show <role>"
+type dashboardRole struct {
+	Name              string              `json:"name"`
+	Description       string              `json:"description"`
+	ScopesPermissions map[string][]string `json:"scopes_permissions"`
+}
+
+// ConfigureDashboardAccess creates the dashboard roles and users from the spec, deletes the ones
+// Rook created that are no longer in the spec and configures the dashboard single sign-on
+func (c *Cluster) ConfigureDashboardAccess() error {
+	dashboardAccessMutex.Lock()
+	defer dashboardAccessMutex.Unlock()
+
+	kv := k8sutil.NewConfigMapKVStore(c.clusterInfo.Namespace, c.context.Clientset, c.clusterInfo.OwnerInfo)
+	store, err := kv.GetStore(dashboardAccessStoreName)
+	if err != nil && !kerrors.IsNotFound(err) {
+		return errors.Wrap(err, "failed to get the dashboard users and roles created previously")
+	}
+
+	createdRoles, err := unmarshalNames(store[dashboardRolesKey])
+	if err != nil {
+		return errors.Wrap(err, "failed to parse the dashboard roles created previously")
+	}
+	createdUsers, err := unmarshalNames(store[dashboardUsersKey])
+	if err != nil {
+		return errors.Wrap(err, "failed to parse the dashboard users created previously")
+	}
+	passwordVersions := map[string]string{}
+	if store[dashboardPasswordsKey] != "" {
+		if err := json.Unmarshal([]byte(store[dashboardPasswordsKey]), &passwordVersions); err != nil {
+			return errors.Wrap(err, "failed to parse the dashboard passwords set previously")
+		}
+	}
+
+	// roles must exist before they are granted to users, and users must be deleted before the
+	// roles they are granted
+	if err := c.configureDashboardRoles(); err != nil {
+		return errors.Wrap(err, "failed to configure dashboard roles")
+	}
+	err = c.configureDashboardUsers(createdUsers, passwordVersions)
+	// store the passwords that were set even if another user failed
+	if len(passwordVersions) > 0 || store[dashboardPasswordsKey] != "" {
+		// marshalling a map of strings cannot fail
+		out, _ := json.Marshal(passwordVersions)
+		if err := kv.SetValue(dashboardAccessStoreName, dashboardPasswordsKey, string(out)); err != nil {
+			return errors.Wrap(err, "failed to store the dashboard passwords")
+		}
+	}
+	if err != nil {
+		return errors.Wrap(err, "failed to configure dashboard users")
+	}
+	if err := c.deleteRemovedDashboardRoles(createdRoles); err != nil {
+		return errors.Wrap(err, "failed to delete dashboard roles")
+	}
+	if err := kv.SetValue(dashboardAccessStoreName, dashboardRolesKey, marshalNames(dashboardRoleNames(c.spec.Dashboard.Roles))); err != nil {
+		return errors.Wrap(err, "failed to store the dashboard roles")
+	}
+	if err := kv.SetValue(dashboardAccessStoreName, dashboardUsersKey, marshalNames(dashboardUserNames(c.spec.Dashboard.Users))); err != nil {
+		return errors.Wrap(err, "failed to store the dashboard users")
+	}
+
+	ssoEnabled, err := c.configureDashboardSSO(store[dashboardSSOKey] == "true")
+	if err != nil {
+		return errors.Wrap(err, "failed to configure dashboard single sign-on")
+	}
+	if err := kv.SetValue(dashboardAccessStoreName, dashboardSSOKey, strconv.FormatBool(ssoEnabled)); err != nil {
+		return errors.Wrap(err, "failed to store the dashboard single sign-on state")
+	}
+
+	return nil
+}
+
+func (c *Cluster) configureDashboardRoles() error {
+	if len(c.spec.Dashboard.Roles) == 0 {
+		return nil
+	}
+
+	existing, err := c.listDashboardNames("ac-role-show")
+	if err != nil {
+		return err
+	}
+
+	for _, role := range c.spec.Dashboard.Roles {
+		if !contains(existing, role.Name) {
+			logger.Infof("creating dashboard role %q", role.Name)
+			args := []string{"dashboard", "ac-role-create", role.Name}
+			if role.Description != "" {
+				args = append(args, role.Description)
+			}
+			if _, err := c.runDashboardCommand(args...); err != nil {
+				return errors.Wrapf(err, "failed to create dashboard role %q", role.Name)
+			}
+		}
+
+		output, err := c.runDashboardCommand("dashboard", "ac-role-show", role.Name)
+		if err != nil {
+			return errors.Wrapf(err, "failed to get dashboard role %q", role.Name)
+		}
+		var current dashboardRole
+		if err := json.Unmarshal(output, &current); err != nil {
+			return errors.Wrapf(err, "failed to unmarshal dashboard role %q", role.Name)
+		}
+
+		toSet, toDelete := dashboardScopeChanges(current.ScopesPermissions, role.Scopes)
+		for _, scope := range toDelete {
+			if _, err := c.runDashboardCommand("dashboard", "ac-role-del-scope-perms", role.Name, scope); err != nil {
+				return errors.Wrapf(err, "failed to remove scope %q from dashboard role %q", scope, role.Name)
+			}
+		}
+		for _, scope := range toSet {
+			args := []string{"dashboard", "ac-role-add-scope-perms", role.Name, scope}
+			for _, permission := range role.Scopes[scope] {
+				args = append(args, string(permission))
+			}
+			if _, err := c.runDashboardCommand(args...); err != nil {
+				return errors.Wrapf(err, "failed to set scope %q of dashboard role %q", scope, role.Name)
+			}
+		}
+	}
+
+	return nil
+}
+
+func (c *Cluster) deleteRemovedDashboardRoles(createdRoles []string) error {
+	desired := dashboardRoleNames(c.spec.Dashboard.Roles)
+	for _, role := range createdRoles {
+		if contains(desired, role) {
+			continue
+		}
+		logger.Infof("deleting dashboard role %q removed from the spec", role)
+		if _, err := c.runDashboardCommand("dashboard", "ac-role-delete", role); err != nil {
+			return errors.Wrapf(err, "failed to delete dashboard role %q", role)
+		}
+	}
+	return nil
+}
+
+// configureDashboardUsers creates and updates the users of the spec and deletes the users Rook created that
+// are no longer in the spec. The versions of the password secrets that are set are updated in passwordVersions.
+func (c *Cluster) configureDashboardUsers(createdUsers []string, passwordVersions map[string]string) error {
+	if len(c.spec.Dashboard.Users) > 0 && !FileBasedPasswordSupported(c.clusterInfo) {
+		return errors.Errorf("dashboard users are not supported with ceph version %q", c.clusterInfo.CephVersion.String())
+	}
+
+	existing := []string{}
+	if len(c.spec.Dashboard.Users) > 0 {
+		var err error
+		existing, err = c.listDashboardNames("ac-user-show")
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, user := range c.spec.Dashboard.Users {
+		if err := validateDashboardUser(user); err != nil {
+			return err
+		}
+		if err := c.configureDashboardUser(user, contains(existing, user.Name), passwordVersions); err != nil {
+			return errors.Wrapf(err, "failed to configure dashboard user %q", user.Name)
+		}
+	}
+
+	desired := dashboardUserNames(c.spec.Dashboard.Users)
+	for _, user := range createdUsers {
+		if contains(desired, user) {
+			continue
+		}
+		logger.Infof("deleting dashboard user %q removed from the spec", user)
+		if _, err := c.runDashboardCommand("dashboard", "ac-user-delete", user); err != nil {
+			return errors.Wrapf(err, "failed to delete dashboard user %q", user)
+		}
+		delete(passwordVersions, user)
+	}
+
+	return nil
+}
+
+func (c *Cluster) configureDashboardUser(user cephv1.DashboardUserSpec, exists bool, passwordVersions map[string]string) error {
+	secret, err := c.context.Clientset.CoreV1().Secrets(c.clusterInfo.Namespace).Get(c.clusterInfo.Context, user.PasswordSecretName, metav1.GetOptions{})
+	if err != nil {
+		return errors.Wrapf(err, "failed to get password secret %q", user.PasswordSecretName)
+	}
+	password, err := decodeSecret(secret)
+	if err != nil {
+		return errors.Wrapf(err, "failed to read password secret %q", user.PasswordSecretName)
+	}
+
+	// The password is only set when the user is created or its secret changed, so the password changed
+	// by the user in the dashboard is kept
+	secretVersion := dashboardPasswordSecretVersion(secret)
+	if !exists || passwordVersions[user.Name] != secretVersion {
+		if err := c.setDashboardUserPassword(user, exists, password); err != nil {
+			return err
+		}
+		passwordVersions[user.Name] = secretVersion
+	}
+
+	args := append([]string{"dashboard", "ac-user-set-roles", user.Name}, user.Roles...)
+	if _, err := c.runDashboardCommand(args...); err != nil {
+		return errors.Wrap(err, "failed to set roles")
+	}
+
+	if user.FullName != "" || user.Email != "" {
+		if _, err := c.runDashboardCommand("dashboard", "ac-user-set-info", user.Name, user.FullName, user.Email); err != nil {
+			return errors.Wrap(err, "failed to set info")
+		}
+	}
+
+	return nil
+}
+
+// setDashboardUserPassword creates the user with the password, or sets the password of the existing user
+func (c *Cluster) setDashboardUserPassword(user cephv1.DashboardUserSpec, exists bool, password string) error {
+	// Write the password to a file so it is not passed on the command line
+	file, err := util.CreateTempFile(password)
+	if err != nil {
+		return errors.Wrap(err, "failed to create a temporary dashboard password file")
+	}
+	defer func() {
+		if err := os.Remove(file.Name()); err != nil {
+			logger.Errorf("failed to clean up dashboard password file %q. %v", file.Name(), err)
+		}
+	}()
+
+	if exists {
+		logger.Infof("setting the password of dashboard user %q", user.Name)
+		if _, err := c.runDashboardCommand("dashboard", "ac-user-set-password", user.Name, "-i", file.Name(), "--force-password"); err != nil {
+			return errors.Wrap(err, "failed to set password")
+		}
+		return nil
+	}
+	logger.Infof("creating dashboard user %q", user.Name)
+	if _, err := c.runDashboardCommand("dashboard", "ac-user-create", user.Name, "-i", file.Name(), "--force-password"); err != nil {
+		return errors.Wrap(err, "failed to create user")
+	}
+	return nil
+}
+
+// dashboardPasswordSecretVersion returns the version of a password secret, which changes when the
+// secret is updated or recreated
+func dashboardPasswordSecretVersion(secret *v1.Secret) string {
+	return string(secret.UID) + "/" + secret.ResourceVersion
+}
+
+// configureDashboardSSO sets up and enables the single sign-on from the spec. If the single sign-on
+// was enabled by Rook and is no longer in the spec, it is disabled. Returns whether it is enabled.
+func (c *Cluster) configureDashboardSSO(enabledByRook bool) (bool, error) {
+	sso := c.spec.Dashboard.SSO
+	if sso == nil || !sso.Enabled {
+		if enabledByRook {
+			logger.Info("disabling dashboard single sign-on")
+			if _, err := c.runDashboardCommand("dashboard", "sso", "disable"); err != nil {
+				return true, errors.Wrap(err, "failed to disable single sign-on")
+			}
+		}
+		return false, nil
+	}
+
+	args, err := dashboardSSOSetupArgs(sso)
+	if err != nil {
+		return enabledByRook, err
+	}
+	if _, err := c.runDashboardCommand(args...); err != nil {
+		return enabledByRook, errors.Wrapf(err, "failed to set up %q single sign-on", sso.Type)
+	}
+	if _, err := c.runDashboardCommand("dashboard", "sso", "enable", string(sso.Type)); err != nil {
+		return enabledByRook, errors.Wrapf(err, "failed to enable %q single sign-on", sso.Type)
+	}
+
+	logger.Infof("dashboard %q single sign-on is enabled", sso.Type)
+	return true, nil
+}
+
+// dashboardSSOSetupArgs returns the command to set up the single sign-on
+func dashboardSSOSetupArgs(sso *cephv1.DashboardSSOSpec) ([]string, error) {
+	if err := sso.Validate(); err != nil {
+		return nil, err
+	}
+	switch sso.Type {
+	case cephv1.DashboardSSOSAML2:
+		args := []string{"dashboard", "sso", "setup", "saml2", sso.SAML2.BaseURL, sso.SAML2.IdPMetadata}
+		usernameAttribute := sso.SAML2.UsernameAttribute
+		if usernameAttribute == "" && sso.SAML2.EntityID != "" {
+			// the arguments are positional, use the default attribute to pass the entity ID
+			usernameAttribute = "uid"
+		}
+		if usernameAttribute != "" {
+			args = append(args, usernameAttribute)
+		}
+		if sso.SAML2.EntityID != "" {
+			args = append(args, sso.SAML2.EntityID)
+		}
+		return args, nil
+	}
+
+	return nil, errors.Errorf("unknown single sign-on type %q", sso.Type)
+}
+
+// dashboardScopeChanges returns the scopes whose permissions must be set and the scopes that must
+// be removed from a role
+func dashboardScopeChanges(current map[string][]string, desired map[string]cephv1.DashboardPermissions) (toSet, toDelete []string) {
+	for scope, permissions := range desired {
+		currentPermissions, ok := current[scope]
+		if !ok || !samePermissions(currentPermissions, permissions) {
+			toSet = append(toSet, scope)
+		}
+	}
+	for scope := range current {
+		if _, ok := desired[scope]; !ok {
+			toDelete = append(toDelete, scope)
+		}
+	}
+	sort.Strings(toSet)
+	sort.Strings(toDelete)
+	return toSet, toDelete
+}
+
+func samePermissions(current []string, desired []cephv1.DashboardPermission) bool {
+	if len(current) != len(desired) {
+		return false
+	}
+	for _, permission := range desired {
+		if !contains(current, string(permission)) {
+			return false
+		}
+	}
+	return true
+}
+
+func validateDashboardUser(user cephv1.DashboardUserSpec) error {
+	if user.Name == "" {
+		return errors.New("name not specified for the dashboard user")
+	}
+	if user.Name == dashboardUsername {
+		return errors.Errorf("dashboard user %q is reserved for the admin user managed by rook", dashboardUsername)
+	}
+	if user.PasswordSecretName == "" {
+		return errors.Errorf("password secret not specified for dashboard user %q", user.Name)
+	}
+	return nil
+}
+
+// listDashboardNames returns the names listed by a dashboard "show" command without arguments
+func (c *Cluster) listDashboardNames(command string) ([]string, error) {
+	output, err := c.runDashboardCommand("dashboard", command)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to run dashboard %q", command)
+	}
+	var names []string
+	if err := json.Unmarshal(output, &names); err != nil {
+		return nil, errors.Wrapf(err, "failed to unmarshal dashboard %q output", command)
+	}
+	return names, nil
+}
+
+// runDashboardCommand runs a dashboard command, retrying while the dashboard module is not ready
+func (c *Cluster) runDashboardCommand(args ...string) ([]byte, error) {
+	return client.ExecuteCephCommandWithRetry(func() (string, []byte, error) {
+		output, err := client.NewCephCommand(c.context, c.clusterInfo, args).RunWithTimeout(exec.CephCommandsTimeout)
+		return args[1], output, err
+	}, c.exitCode, 5, invalidArgErrorCode, dashboardInitWaitTime)
+}
+
+func dashboardUserNames(users []cephv1.DashboardUserSpec) []string {
+	names := []string{}
+	for _, user := range users {
+		names = append(names, user.Name)
+	}
+	return names
+}
+
+func dashboardRoleNames(roles []cephv1.DashboardRoleSpec) []string {
+	names := []string{}
+	for _, role := range roles {
+		names = append(names, role.Name)
+	}
+	return names
+}
+
+func marshalNames(names []string) string {
+	// marshalling a list of strings cannot fail
+	out, _ := json.Marshal(names)
+	return string(out)
+}
+
+func unmarshalNames(value string) ([]string, error) {
+	names := []string{}
+	if value == "" {
+		return names, nil
+	}
+	err := json.Unmarshal([]byte(value), &names)
+	return names, err
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
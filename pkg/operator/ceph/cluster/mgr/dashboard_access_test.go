@@ -0,0 +1,211 @@
+/*
+Copyright 2021 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mgr
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	"github.com/rook/rook/pkg/clusterd"
+	cephclient "github.com/rook/rook/pkg/daemon/ceph/client"
+	cephver "github.com/rook/rook/pkg/operator/ceph/version"
+	"github.com/rook/rook/pkg/operator/test"
+	exectest "github.com/rook/rook/pkg/util/exec/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// hasCommandPrefix returns whether a command starts with the prefix, the password files are temporary files
+func hasCommandPrefix(commands []string, prefix string) bool {
+	for _, command := range commands {
+		if strings.HasPrefix(command, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func TestConfigureDashboardAccess(t *testing.T) {
+	ctx := context.TODO()
+	clientset := test.New(t, 3)
+	commands := []string{}
+	users := `["admin"]`
+	mockFN := func(command string, args ...string) (string, error) {
+		for i, arg := range args {
+			// ignore the connection flags
+			if strings.HasPrefix(arg, "--connect-timeout") {
+				commands = append(commands, strings.Join(args[:i], " "))
+				break
+			}
+		}
+		if args[0] == "dashboard" {
+			switch {
+			case args[1] == "ac-role-show" && args[2] == "--connect-timeout=15":
+				return `["administrator","read-only"]`, nil
+			case args[1] == "ac-role-show":
+				return `{"name":"storage","description":"","scopes_permissions":{"pool":["read"],"iscsi":["read"]}}`, nil
+			case args[1] == "ac-user-show":
+				return users, nil
+			case args[1] == "ac-user-create":
+				users = `["admin","alice"]`
+			}
+		}
+		return "", nil
+	}
+	executor := &exectest.MockExecutor{
+		MockExecuteCommandWithOutput: mockFN,
+		MockExecuteCommandWithTimeout: func(timeout time.Duration, command string, arg ...string) (string, error) {
+			return mockFN(command, arg...)
+		},
+	}
+	clusterInfo := &cephclient.ClusterInfo{
+		Namespace:   "myns",
+		CephVersion: cephver.Pacific,
+		OwnerInfo:   cephclient.NewMinimumOwnerInfoWithOwnerRef(),
+		Context:     ctx,
+	}
+	c := &Cluster{clusterInfo: clusterInfo, context: &clusterd.Context{Clientset: clientset, Executor: executor}}
+	c.exitCode = func(err error) (int, bool) { return 0, false }
+	dashboardInitWaitTime = 0
+
+	secret := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "alice-password", Namespace: "myns"},
+		Data:       map[string][]byte{passwordKeyName: []byte("secret")},
+	}
+	_, err := clientset.CoreV1().Secrets("myns").Create(ctx, secret, metav1.CreateOptions{})
+	require.NoError(t, err)
+
+	c.spec.Dashboard = cephv1.DashboardSpec{
+		Enabled: true,
+		Roles: []cephv1.DashboardRoleSpec{
+			{Name: "storage", Scopes: map[string]cephv1.DashboardPermissions{"pool": {"read", "update"}}},
+		},
+		Users: []cephv1.DashboardUserSpec{
+			{Name: "alice", PasswordSecretName: "alice-password", Roles: []string{"storage"}, Email: "alice@example.com"},
+		},
+		SSO: &cephv1.DashboardSSOSpec{
+			Enabled: true,
+			Type:    cephv1.DashboardSSOSAML2,
+			SAML2:   &cephv1.DashboardSAML2Spec{BaseURL: "https://dashboard", IdPMetadata: "https://idp/metadata"},
+		},
+	}
+	err = c.ConfigureDashboardAccess()
+	assert.NoError(t, err)
+	assert.Contains(t, commands, "dashboard ac-role-create storage")
+	assert.Contains(t, commands, "dashboard ac-role-del-scope-perms storage iscsi")
+	assert.Contains(t, commands, "dashboard ac-role-add-scope-perms storage pool read update")
+	assert.Contains(t, commands, "dashboard ac-user-show")
+	assert.Contains(t, commands, "dashboard ac-user-set-roles alice storage")
+	assert.Contains(t, commands, "dashboard ac-user-set-info alice  alice@example.com")
+	assert.Contains(t, commands, "dashboard sso setup saml2 https://dashboard https://idp/metadata")
+	assert.Contains(t, commands, "dashboard sso enable saml2")
+	assert.True(t, hasCommandPrefix(commands, "dashboard ac-user-create alice -i"))
+
+	// the password is not set again while the secret does not change
+	commands = []string{}
+	err = c.ConfigureDashboardAccess()
+	assert.NoError(t, err)
+	assert.False(t, hasCommandPrefix(commands, "dashboard ac-user-create alice -i"))
+	assert.False(t, hasCommandPrefix(commands, "dashboard ac-user-set-password alice -i"))
+
+	// only the version of the secret is stored
+	store, err := clientset.CoreV1().ConfigMaps("myns").Get(ctx, dashboardAccessStoreName, metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.NotContains(t, store.Data[dashboardPasswordsKey], "secret")
+
+	// the password is set when the secret changes, the fake clientset does not set the resource version
+	secret.Data[passwordKeyName] = []byte("new-secret")
+	secret.ResourceVersion = "2"
+	_, err = clientset.CoreV1().Secrets("myns").Update(ctx, secret, metav1.UpdateOptions{})
+	require.NoError(t, err)
+	commands = []string{}
+	err = c.ConfigureDashboardAccess()
+	assert.NoError(t, err)
+	assert.True(t, hasCommandPrefix(commands, "dashboard ac-user-set-password alice -i"))
+
+	// the user and role created by rook are deleted once removed from the spec
+	commands = []string{}
+	c.spec.Dashboard = cephv1.DashboardSpec{Enabled: true}
+	err = c.ConfigureDashboardAccess()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		"dashboard ac-user-delete alice",
+		"dashboard ac-role-delete storage",
+		"dashboard sso disable",
+	}, commands)
+
+	// nothing is run when rook did not configure any access
+	commands = []string{}
+	err = c.ConfigureDashboardAccess()
+	assert.NoError(t, err)
+	assert.Empty(t, commands)
+}
+
+func TestDashboardScopeChanges(t *testing.T) {
+	current := map[string][]string{"pool": {"read"}, "rbd-image": {"read", "create"}, "iscsi": {"read"}}
+	desired := map[string]cephv1.DashboardPermissions{
+		"pool":      {"read", "update"},
+		"rbd-image": {"create", "read"},
+		"hosts":     {"read"},
+	}
+	toSet, toDelete := dashboardScopeChanges(current, desired)
+	assert.Equal(t, []string{"hosts", "pool"}, toSet)
+	assert.Equal(t, []string{"iscsi"}, toDelete)
+
+	toSet, toDelete = dashboardScopeChanges(map[string][]string{"pool": {"read"}}, map[string]cephv1.DashboardPermissions{"pool": {"read"}})
+	assert.Empty(t, toSet)
+	assert.Empty(t, toDelete)
+}
+
+func TestDashboardSSOSetupArgs(t *testing.T) {
+	sso := &cephv1.DashboardSSOSpec{Enabled: true, Type: cephv1.DashboardSSOSAML2}
+	_, err := dashboardSSOSetupArgs(sso)
+	assert.Error(t, err)
+
+	sso.SAML2 = &cephv1.DashboardSAML2Spec{BaseURL: "https://dashboard", IdPMetadata: "https://idp/metadata"}
+	args, err := dashboardSSOSetupArgs(sso)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"dashboard", "sso", "setup", "saml2", "https://dashboard", "https://idp/metadata"}, args)
+
+	// the default username attribute is passed with the entity ID
+	sso.SAML2.EntityID = "rook"
+	args, err = dashboardSSOSetupArgs(sso)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"dashboard", "sso", "setup", "saml2", "https://dashboard", "https://idp/metadata", "uid", "rook"}, args)
+
+	sso.SAML2.UsernameAttribute = "email"
+	args, err = dashboardSSOSetupArgs(sso)
+	assert.NoError(t, err)
+	assert.Equal(t, "email", args[6])
+
+	// only saml2 is supported
+	_, err = dashboardSSOSetupArgs(&cephv1.DashboardSSOSpec{Enabled: true, Type: "oidc"})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "not supported by the ceph dashboard")
+}
+
+func TestValidateDashboardUser(t *testing.T) {
+	assert.NoError(t, validateDashboardUser(cephv1.DashboardUserSpec{Name: "alice", PasswordSecretName: "alice-password"}))
+	assert.Error(t, validateDashboardUser(cephv1.DashboardUserSpec{PasswordSecretName: "alice-password"}))
+	assert.Error(t, validateDashboardUser(cephv1.DashboardUserSpec{Name: "alice"}))
+	assert.Error(t, validateDashboardUser(cephv1.DashboardUserSpec{Name: dashboardUsername, PasswordSecretName: "password"}))
+}
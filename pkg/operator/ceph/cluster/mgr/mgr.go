@@ -18,6 +18,7 @@ limitations under the License.
 package mgr
 
 import (
+	"encoding/json"
 	"fmt"
 	"path"
 	"strconv"
@@ -57,12 +58,17 @@ const (
 	PgautoscalerModuleName = "pg_autoscaler"
 	balancerModuleName     = "balancer"
 	balancerModuleMode     = "upmap"
+	balancerModeSetting    = "mode"
 	monitoringPath         = "/etc/ceph-monitoring/"
 	serviceMonitorFile     = "service-monitor.yaml"
 	// minimum amount of memory in MB to run the pod
 	cephMgrPodMinimumMemory uint64 = 512
 	// DefaultMetricsPort prometheus exporter port
 	DefaultMetricsPort uint16 = 9283
+	// the settings of the mgr modules applied by Rook are stored in this configmap so they can be
+	// removed when they are removed from the spec
+	mgrModuleSettingsStoreName = "rook-ceph-mgr-module-settings"
+	mgrModuleSettingsKey       = "settings"
 )
 
 // Cluster represents the Rook and environment configuration settings needed to set up Ceph mgrs.
@@ -392,8 +398,12 @@ func (c *Cluster) configureMgrModules() error {
 
 		if module.Enabled {
 			if module.Name == balancerModuleName {
-				// Configure balancer module mode
-				err := cephclient.ConfigureBalancerModule(c.context, c.clusterInfo, balancerModuleMode)
+				// Configure balancer module mode, the mode from the settings has precedence
+				mode := balancerModuleMode
+				if m, ok := module.Settings[balancerModeSetting]; ok {
+					mode = m
+				}
+				err := cephclient.ConfigureBalancerModule(c.context, c.clusterInfo, mode)
 				if err != nil {
 					return errors.Wrapf(err, "failed to configure module %q", module.Name)
 				}
@@ -427,9 +437,85 @@ func (c *Cluster) configureMgrModules() error {
 		}
 	}
 
+	return c.configureMgrModuleSettings()
+}
+
+// configureMgrModuleSettings applies the settings of the enabled modules from the spec. The
+// settings applied previously that are no longer in the spec are removed from the mon configuration
+// database so they go back to their default value.
+func (c *Cluster) configureMgrModuleSettings() error {
+	kv := k8sutil.NewConfigMapKVStore(c.clusterInfo.Namespace, c.context.Clientset, c.clusterInfo.OwnerInfo)
+	applied := map[string]map[string]string{}
+	value, err := kv.GetValue(mgrModuleSettingsStoreName, mgrModuleSettingsKey)
+	if err != nil && !kerrors.IsNotFound(err) {
+		return errors.Wrap(err, "failed to get the mgr module settings previously applied")
+	}
+	if err == nil {
+		if err := json.Unmarshal([]byte(value), &applied); err != nil {
+			return errors.Wrap(err, "failed to unmarshal the mgr module settings previously applied")
+		}
+	}
+
+	desired := desiredModuleSettings(c.spec.Mgr.Modules)
+	monStore := config.GetMonStore(c.context, c.clusterInfo)
+	for module, settings := range applied {
+		for key := range settings {
+			if _, ok := desired[module][key]; ok {
+				continue
+			}
+			if err := monStore.Delete("mgr", moduleSettingOption(module, key)); err != nil {
+				return errors.Wrapf(err, "failed to remove setting %q of mgr module %q", key, module)
+			}
+		}
+	}
+	for module, settings := range desired {
+		for key, value := range settings {
+			if err := monStore.Set("mgr", moduleSettingOption(module, key), value); err != nil {
+				return errors.Wrapf(err, "failed to apply setting %q of mgr module %q", key, module)
+			}
+		}
+	}
+
+	if len(applied) == 0 && len(desired) == 0 {
+		return nil
+	}
+	desiredValue, err := json.Marshal(desired)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal the mgr module settings")
+	}
+	if err := kv.SetValue(mgrModuleSettingsStoreName, mgrModuleSettingsKey, string(desiredValue)); err != nil {
+		return errors.Wrap(err, "failed to store the applied mgr module settings")
+	}
+
 	return nil
 }
 
+// desiredModuleSettings returns the settings of the enabled modules, by module name
+func desiredModuleSettings(modules []cephv1.Module) map[string]map[string]string {
+	desired := map[string]map[string]string{}
+	for _, module := range modules {
+		if !module.Enabled || len(module.Settings) == 0 {
+			continue
+		}
+		settings := map[string]string{}
+		for key, value := range module.Settings {
+			if module.Name == balancerModuleName && key == balancerModeSetting {
+				// the balancer mode is set when configuring the balancer module
+				continue
+			}
+			settings[key] = value
+		}
+		if len(settings) > 0 {
+			desired[module.Name] = settings
+		}
+	}
+	return desired
+}
+
+func moduleSettingOption(module, key string) string {
+	return fmt.Sprintf("mgr/%s/%s", module, key)
+}
+
 func (c *Cluster) moduleMeetsMinVersion(name string) (*cephver.CephVersion, bool) {
 	minVersions := map[string]cephver.CephVersion{
 		// Put the modules here, example:
@@ -253,6 +253,7 @@ func TestConfigureModules(t *testing.T) {
 	modulesEnabled := 0
 	modulesDisabled := 0
 	configSettings := map[string]string{}
+	mgrSettings := map[string]string{}
 	lastModuleConfigured := ""
 	executor := &exectest.MockExecutor{
 		MockExecuteCommandWithOutput: func(command string, args ...string) (string, error) {
@@ -270,6 +271,12 @@ func TestConfigureModules(t *testing.T) {
 				if args[0] == "config" && args[1] == "set" && args[2] == "global" {
 					configSettings[args[3]] = args[4]
 				}
+				if args[0] == "config" && args[1] == "set" && args[2] == "mgr" {
+					mgrSettings[args[3]] = args[4]
+				}
+				if args[0] == "config" && args[1] == "rm" && args[2] == "mgr" {
+					delete(mgrSettings, args[3])
+				}
 			}
 			return "", nil //return "{\"key\":\"mysecurekey\"}", nil
 		},
@@ -321,6 +328,38 @@ func TestConfigureModules(t *testing.T) {
 	assert.Equal(t, 1, modulesDisabled)
 	assert.Equal(t, "pg_autoscaler", lastModuleConfigured)
 	assert.Equal(t, 0, len(configSettings))
+
+	// module settings are applied
+	c.clusterInfo.OwnerInfo = cephclient.NewMinimumOwnerInfo(t)
+	c.spec.Mgr.Modules = []cephv1.Module{
+		{Name: "telemetry", Enabled: true, Settings: map[string]string{"channel_ident": "true", "contact": "admin"}},
+		{Name: "balancer", Enabled: true, Settings: map[string]string{"mode": "crush-compat"}},
+	}
+	assert.NoError(t, c.configureMgrModules())
+	assert.Equal(t, map[string]string{"mgr/telemetry/channel_ident": "true", "mgr/telemetry/contact": "admin"}, mgrSettings)
+
+	// a setting removed from the spec is removed from the mon configuration database
+	delete(c.spec.Mgr.Modules[0].Settings, "contact")
+	assert.NoError(t, c.configureMgrModules())
+	assert.Equal(t, map[string]string{"mgr/telemetry/channel_ident": "true"}, mgrSettings)
+
+	// the settings of a disabled module are removed
+	c.spec.Mgr.Modules[0].Enabled = false
+	assert.NoError(t, c.configureMgrModules())
+	assert.Equal(t, 0, len(mgrSettings))
+}
+
+func TestDesiredModuleSettings(t *testing.T) {
+	modules := []cephv1.Module{
+		{Name: "telemetry", Enabled: true, Settings: map[string]string{"contact": "admin"}},
+		{Name: "balancer", Enabled: true, Settings: map[string]string{"mode": "crush-compat", "sleep_interval": "120"}},
+		{Name: "disabled", Enabled: false, Settings: map[string]string{"foo": "bar"}},
+		{Name: "nosettings", Enabled: true},
+	}
+	assert.Equal(t, map[string]map[string]string{
+		"telemetry": {"contact": "admin"},
+		"balancer":  {"sleep_interval": "120"},
+	}, desiredModuleSettings(modules))
 }
 
 func TestMgrDaemons(t *testing.T) {
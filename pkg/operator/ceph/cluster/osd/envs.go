@@ -48,6 +48,7 @@ const (
 	CrushDeviceClassVarName             = "ROOK_OSD_CRUSH_DEVICE_CLASS"
 	CrushInitialWeightVarName           = "ROOK_OSD_CRUSH_INITIAL_WEIGHT"
 	CrushRootVarName                    = "ROOK_CRUSHMAP_ROOT"
+	CrushTopologyVarName                = "ROOK_CRUSHMAP_TOPOLOGY"
 	tcmallocMaxTotalThreadCacheBytesEnv = "TCMALLOC_MAX_TOTAL_THREAD_CACHE_BYTES"
 )
 
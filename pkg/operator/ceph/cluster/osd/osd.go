@@ -78,6 +78,8 @@ type Cluster struct {
 	ValidStorage cephv1.StorageScopeSpec // valid subset of `Storage`, computed at runtime
 	kv           *k8sutil.ConfigMapKVStore
 	deviceSets   []deviceSet
+	// the crush locations of the hosts moved in the crush map since the OSDs were created
+	crushLocations map[string]string
 }
 
 // New creates an instance of the OSD manager
@@ -190,6 +192,9 @@ func (c *Cluster) Start() error {
 	}
 	logger.Infof("wait timeout for healthy OSDs during upgrade or restart is %q", c.clusterInfo.OsdUpgradeTimeout)
 
+	// move the hosts whose topology labels changed before the OSDs are updated with their new location
+	c.updateCrushTopology()
+
 	// prepare for updating existing OSDs
 	updateQueue, deployments, err := c.getOSDUpdateInfo(errs)
 	if err != nil {
@@ -197,6 +202,10 @@ func (c *Cluster) Start() error {
 	}
 	logger.Debugf("%d of %d OSD Deployments need updated", updateQueue.Len(), deployments.Len())
 	updateConfig := c.newUpdateConfig(config, updateQueue, deployments)
+	upgradeHeldMessage, err := c.applyUpgradeStrategy(updateConfig)
+	if err != nil {
+		return errors.Wrapf(err, "failed to apply the upgrade strategy to the OSDs in namespace %q", namespace)
+	}
 
 	// prepare for creating new OSDs
 	statusConfigMaps := sets.NewString()
@@ -232,6 +241,10 @@ func (c *Cluster) Start() error {
 	// for example, if the storage spec changed from or a node failed in a previous failed reconcile
 	c.deleteAllStatusConfigMaps()
 
+	if upgradeHeldMessage != "" {
+		return errors.Wrap(controller.ErrUpgradePaused, upgradeHeldMessage)
+	}
+
 	// The following block is used to apply any command(s) required by an upgrade
 	c.applyUpgradeOSDFunctionality()
 
@@ -533,8 +546,13 @@ func (c *Cluster) getOSDInfo(d *appsv1.Deployment) (OSDInfo, error) {
 		}
 	}
 
+	// the host of the OSD was moved to the location of the current node labels
+	if location, ok := c.crushLocations[crushLocationHost(osd.Location)]; ok && locationFound {
+		osd.Location = location
+	}
+
 	if !locationFound {
-		location, _, err := getLocationFromPod(c.context.Clientset, d, cephclient.GetCrushRootFromSpec(&c.spec))
+		location, _, err := getLocationFromPod(c.context.Clientset, d, cephclient.GetCrushRootFromSpec(&c.spec), c.spec.Storage.CrushTopology)
 		if err != nil {
 			logger.Errorf("failed to get location. %v", err)
 		} else {
@@ -595,7 +613,7 @@ func getBlockPathFromActivateInitContainer(d *appsv1.Deployment) (string, error)
 	return "", errors.Errorf("failed to find activate init container")
 }
 
-func getLocationFromPod(clientset kubernetes.Interface, d *appsv1.Deployment, crushRoot string) (string, string, error) {
+func getLocationFromPod(clientset kubernetes.Interface, d *appsv1.Deployment, crushRoot string, crushTopology []cephv1.CrushTopologyLabel) (string, string, error) {
 	ctx := context.TODO()
 	pods, err := clientset.CoreV1().Pods(d.Namespace).List(ctx, metav1.ListOptions{LabelSelector: fmt.Sprintf("%s=%s", OsdIdLabelKey, d.Labels[OsdIdLabelKey])})
 	if err != nil || len(pods.Items) == 0 {
@@ -613,7 +631,7 @@ func getLocationFromPod(clientset kubernetes.Interface, d *appsv1.Deployment, cr
 			hostName = pvcName
 		}
 	}
-	return GetLocationWithNode(clientset, nodeName, crushRoot, hostName)
+	return GetLocationWithNode(clientset, nodeName, crushRoot, hostName, crushTopology)
 }
 
 func getTopologyFromNode(clientset kubernetes.Interface, d *appsv1.Deployment, osd OSDInfo) (string, error) {
@@ -641,7 +659,7 @@ func getTopologyFromNode(clientset kubernetes.Interface, d *appsv1.Deployment, o
 	if err != nil {
 		return "", errors.Wrap(err, "failed to get the node for topology affinity")
 	}
-	_, topologyAffinity := ExtractOSDTopologyFromLabels(node.Labels)
+	_, topologyAffinity := ExtractOSDTopologyFromLabels(node.Labels, nil)
 	logger.Infof("found osd %d topology affinity at %q", osd.ID, topologyAffinity)
 	return topologyAffinity, nil
 }
@@ -650,7 +668,8 @@ func getTopologyFromNode(clientset kubernetes.Interface, d *appsv1.Deployment, o
 //  location: The CRUSH properties for the OSD to apply
 //  topologyAffinity: The label to be applied to the OSD daemon to guarantee it will start in the same
 //		topology as the OSD prepare job.
-func GetLocationWithNode(clientset kubernetes.Interface, nodeName string, crushRoot, crushHostname string) (string, string, error) {
+// The node labels of the crush topology are added to the location with their bucket type.
+func GetLocationWithNode(clientset kubernetes.Interface, nodeName string, crushRoot, crushHostname string, crushTopology []cephv1.CrushTopologyLabel) (string, string, error) {
 	node, err := getNode(clientset, nodeName)
 	if err != nil {
 		return "", "", errors.Wrap(err, "could not get the node for topology labels")
@@ -671,7 +690,7 @@ func GetLocationWithNode(clientset kubernetes.Interface, nodeName string, crushR
 	locArgs := []string{fmt.Sprintf("root=%s", crushRoot), fmt.Sprintf("host=%s", hostName)}
 
 	nodeLabels := node.GetLabels()
-	topologyAffinity := updateLocationWithNodeLabels(&locArgs, nodeLabels)
+	topologyAffinity := updateLocationWithNodeLabels(&locArgs, nodeLabels, crushTopology)
 
 	loc := strings.Join(locArgs, " ")
 	logger.Infof("CRUSH location=%s", loc)
@@ -700,8 +719,8 @@ func getNode(clientset kubernetes.Interface, nodeName string) (*corev1.Node, err
 	return node, nil
 }
 
-func updateLocationWithNodeLabels(location *[]string, nodeLabels map[string]string) string {
-	topology, topologyAffinity := ExtractOSDTopologyFromLabels(nodeLabels)
+func updateLocationWithNodeLabels(location *[]string, nodeLabels map[string]string, crushTopology []cephv1.CrushTopologyLabel) string {
+	topology, topologyAffinity := ExtractOSDTopologyFromLabels(nodeLabels, crushTopology)
 
 	keys := make([]string, 0, len(topology))
 	for k := range topology {
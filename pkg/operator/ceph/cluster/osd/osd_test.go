@@ -549,7 +549,7 @@ func TestDetectCrushLocation(t *testing.T) {
 	nodeLabels := map[string]string{}
 
 	// no change to the location if there are no labels
-	updateLocationWithNodeLabels(&location, nodeLabels)
+	updateLocationWithNodeLabels(&location, nodeLabels, nil)
 	assert.Equal(t, 1, len(location))
 	assert.Equal(t, "host=foo", location[0])
 
@@ -559,7 +559,7 @@ func TestDetectCrushLocation(t *testing.T) {
 		"invalid.topology.rook.io/rack": "r1",
 		"topology.rook.io/zone":         "z1",
 	}
-	updateLocationWithNodeLabels(&location, nodeLabels)
+	updateLocationWithNodeLabels(&location, nodeLabels, nil)
 	assert.Equal(t, 1, len(location))
 	assert.Equal(t, "host=foo", location[0])
 
@@ -578,7 +578,7 @@ func TestDetectCrushLocation(t *testing.T) {
 		"row=row1",
 		"zone=zone1",
 	}
-	updateLocationWithNodeLabels(&location, nodeLabels)
+	updateLocationWithNodeLabels(&location, nodeLabels, nil)
 
 	assert.Equal(t, 5, len(location))
 	for i, locString := range location {
@@ -227,6 +227,15 @@ func (c *Cluster) provisionOSDContainer(osdProps osdProperties, copyBinariesMoun
 	envVars = append(envVars, crushDeviceClassEnvVar(osdProps.storeConfig.DeviceClass))
 	envVars = append(envVars, crushInitialWeightEnvVar(osdProps.storeConfig.InitialWeight))
 
+	// the crush location of the new osds has the buckets of the node labels of the crush topology
+	if len(c.spec.Storage.CrushTopology) > 0 {
+		crushTopology, err := json.Marshal(c.spec.Storage.CrushTopology)
+		if err != nil {
+			return v1.Container{}, errors.Wrap(err, "failed to marshal the crush topology")
+		}
+		envVars = append(envVars, v1.EnvVar{Name: CrushTopologyVarName, Value: string(crushTopology)})
+	}
+
 	if osdProps.metadataDevice != "" {
 		envVars = append(envVars, metadataDeviceEnvVar(osdProps.metadataDevice))
 	}
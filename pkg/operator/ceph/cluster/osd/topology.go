@@ -21,9 +21,14 @@ package osd
 
 import (
 	"fmt"
+	"sort"
+	"strings"
 
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
 	"github.com/rook/rook/pkg/daemon/ceph/client"
+	"github.com/rook/rook/pkg/operator/k8sutil"
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 var (
@@ -44,9 +49,16 @@ const (
 	topologyLabelPrefix = "topology.rook.io/"
 )
 
-// ExtractTopologyFromLabels extracts rook topology from labels and returns a map from topology type to value
-func ExtractOSDTopologyFromLabels(labels map[string]string) (map[string]string, string) {
+// ExtractTopologyFromLabels extracts rook topology from labels and returns a map from topology type to value.
+// The labels of the crush topology are mapped to their bucket type, and override the well-known labels of
+// the same type. The topology affinity only comes from the well-known labels.
+func ExtractOSDTopologyFromLabels(labels map[string]string, crushTopology []cephv1.CrushTopologyLabel) (map[string]string, string) {
 	topology, topologyAffinity := extractTopologyFromLabels(labels)
+	for _, crushTopologyLabel := range crushTopology {
+		if value, ok := labels[crushTopologyLabel.Label]; ok {
+			topology[crushTopologyLabel.Type] = value
+		}
+	}
 
 	// Ensure the topology names are normalized for CRUSH
 	for name, value := range topology {
@@ -116,3 +128,102 @@ func extractTopologyFromLabels(labels map[string]string) (map[string]string, str
 func formatTopologyAffinity(label, value string) string {
 	return fmt.Sprintf("%s=%s", label, value)
 }
+
+// updateCrushTopology adds the custom types of the crush topology to the crush map, and moves the hosts
+// of the OSDs whose node labels changed since the OSDs were created. Ceph only checks the host of an OSD
+// when it starts, so the host bucket is moved with all its OSDs. The new locations are applied to the
+// OSD deployments when they are updated.
+func (c *Cluster) updateCrushTopology() {
+	c.crushLocations = map[string]string{}
+	if err := client.AddCrushTypes(c.context, c.clusterInfo, c.spec.Storage.CrushTopology); err != nil {
+		logger.Errorf("failed to add the types of the crush topology to the crush map. %v", err)
+		return
+	}
+
+	listOpts := metav1.ListOptions{LabelSelector: fmt.Sprintf("%s=%s", k8sutil.AppAttr, AppName)}
+	deps, err := c.context.Clientset.AppsV1().Deployments(c.clusterInfo.Namespace).List(c.clusterInfo.Context, listOpts)
+	if err != nil {
+		logger.Errorf("failed to list the osd deployments to update their crush location. %v", err)
+		return
+	}
+
+	for i := range deps.Items {
+		d := &deps.Items[i]
+		// the hosts of the portable OSDs are placed again when the OSDs move to another node
+		if d.Labels[portableKey] == "true" {
+			continue
+		}
+		location := getCrushLocationFromArgs(d.Spec.Template.Spec.Containers[0].Args)
+		host := crushLocationHost(location)
+		if host == "" {
+			continue
+		}
+		if _, ok := c.crushLocations[host]; ok {
+			continue
+		}
+
+		pods, err := c.context.Clientset.CoreV1().Pods(d.Namespace).List(c.clusterInfo.Context, metav1.ListOptions{LabelSelector: fmt.Sprintf("%s=%s", OsdIdLabelKey, d.Labels[OsdIdLabelKey])})
+		if err != nil || len(pods.Items) == 0 || pods.Items[0].Spec.NodeName == "" {
+			logger.Debugf("skipping crush location of osd deployment %q, its pod is not scheduled. %v", d.Name, err)
+			continue
+		}
+		root := crushLocationValue(location, "root")
+		if root == "" {
+			root = client.GetCrushRootFromSpec(&c.spec)
+		}
+		desired, _, err := GetLocationWithNode(c.context.Clientset, pods.Items[0].Spec.NodeName, root, host, c.spec.Storage.CrushTopology)
+		if err != nil {
+			logger.Errorf("failed to get the crush location of osd deployment %q. %v", d.Name, err)
+			continue
+		}
+		if sameCrushLocation(location, desired) {
+			continue
+		}
+
+		logger.Infof("moving crush host %q from %q to %q after the node labels changed", host, location, desired)
+		parent := []string{}
+		for _, field := range strings.Fields(desired) {
+			if !strings.HasPrefix(field, "host=") {
+				parent = append(parent, field)
+			}
+		}
+		if err := client.MoveCrushBucket(c.context, c.clusterInfo, host, strings.Join(parent, " ")); err != nil {
+			logger.Errorf("failed to move crush host %q. %v", host, err)
+			continue
+		}
+		c.crushLocations[host] = desired
+	}
+}
+
+// getCrushLocationFromArgs returns the crush location of the args of an OSD container
+func getCrushLocationFromArgs(args []string) string {
+	locationPrefix := "--crush-location="
+	for _, a := range args {
+		if strings.HasPrefix(a, locationPrefix) {
+			return a[len(locationPrefix):]
+		}
+	}
+	return ""
+}
+
+// crushLocationHost returns the host of a crush location such as "root=default host=node1"
+func crushLocationHost(location string) string {
+	return crushLocationValue(location, "host")
+}
+
+func crushLocationValue(location, crushType string) string {
+	for _, field := range strings.Fields(location) {
+		if strings.HasPrefix(field, crushType+"=") {
+			return strings.TrimPrefix(field, crushType+"=")
+		}
+	}
+	return ""
+}
+
+// sameCrushLocation returns whether the locations have the same buckets in any order
+func sameCrushLocation(a, b string) bool {
+	aFields, bFields := strings.Fields(a), strings.Fields(b)
+	sort.Strings(aFields)
+	sort.Strings(bFields)
+	return strings.Join(aFields, " ") == strings.Join(bFields, " ")
+}
@@ -20,10 +20,19 @@ limitations under the License.
 package osd
 
 import (
+	"context"
 	"testing"
 
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	"github.com/rook/rook/pkg/clusterd"
+	cephclient "github.com/rook/rook/pkg/daemon/ceph/client"
+	"github.com/rook/rook/pkg/operator/k8sutil"
+	exectest "github.com/rook/rook/pkg/util/exec/test"
 	"github.com/stretchr/testify/assert"
+	apps "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
 )
 
 func TestOrderedCRUSHLabels(t *testing.T) {
@@ -49,7 +58,7 @@ func TestCleanTopologyLabels(t *testing.T) {
 		"topology.rook.io/row":              "r.row",
 		"topology.rook.io/datacenter":       "d.datacenter",
 	}
-	topology, affinity := ExtractOSDTopologyFromLabels(nodeLabels)
+	topology, affinity := ExtractOSDTopologyFromLabels(nodeLabels, nil)
 	assert.Equal(t, 6, len(topology))
 	assert.Equal(t, "r-region", topology["region"])
 	assert.Equal(t, "z-zone", topology["zone"])
@@ -135,3 +144,86 @@ func TestTopologyLabels(t *testing.T) {
 	assert.Equal(t, 0, len(topology))
 	assert.Equal(t, "", affinity)
 }
+
+func TestCrushTopologyLabels(t *testing.T) {
+	nodeLabels := map[string]string{
+		corev1.LabelZoneFailureDomainStable: "zone1",
+		"topology.rook.io/rack":             "rack1",
+		"example.com/rack":                  "rack.2",
+		"example.com/enclosure":             "enclosure1",
+	}
+	crushTopology := []cephv1.CrushTopologyLabel{
+		{Label: "example.com/rack", Type: "rack"},
+		{Label: "example.com/enclosure", Type: "enclosure"},
+		{Label: "example.com/missing", Type: "pdu"},
+	}
+	topology, affinity := ExtractOSDTopologyFromLabels(nodeLabels, crushTopology)
+	assert.Equal(t, map[string]string{"zone": "zone1", "rack": "rack-2", "enclosure": "enclosure1"}, topology)
+	// the affinity comes from the well-known labels
+	assert.Equal(t, "topology.rook.io/rack=rack1", affinity)
+
+	location := []string{"root=default", "host=node1"}
+	updateLocationWithNodeLabels(&location, nodeLabels, crushTopology)
+	assert.Equal(t, []string{"root=default", "host=node1", "enclosure=enclosure1", "rack=rack-2", "zone=zone1"}, location)
+}
+
+func TestUpdateCrushTopology(t *testing.T) {
+	ctx := context.TODO()
+	clientset := fake.NewSimpleClientset()
+	moves := [][]string{}
+	executor := &exectest.MockExecutor{
+		MockExecuteCommandWithOutput: func(command string, args ...string) (string, error) {
+			if args[0] == "osd" && args[1] == "crush" && args[2] == "move" {
+				moves = append(moves, args[3:6])
+				return "", nil
+			}
+			return "", nil
+		},
+	}
+	c := &Cluster{
+		context:     &clusterd.Context{Clientset: clientset, Executor: executor},
+		clusterInfo: &cephclient.ClusterInfo{Namespace: "ns", Context: ctx},
+	}
+	c.spec.Storage.CrushTopology = []cephv1.CrushTopologyLabel{{Label: "example.com/rack", Type: "rack"}}
+
+	addOSD := func(id, nodeName, location string) {
+		d := &apps.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "osd" + id, Labels: map[string]string{k8sutil.AppAttr: AppName, OsdIdLabelKey: id}},
+			Spec: apps.DeploymentSpec{Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+				Containers: []corev1.Container{{Args: []string{"--crush-location=" + location}}},
+			}}},
+		}
+		_, err := clientset.AppsV1().Deployments("ns").Create(ctx, d, metav1.CreateOptions{})
+		assert.NoError(t, err)
+		pod := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "osd" + id, Labels: map[string]string{OsdIdLabelKey: id}},
+			Spec:       corev1.PodSpec{NodeName: nodeName},
+		}
+		_, err = clientset.CoreV1().Pods("ns").Create(ctx, pod, metav1.CreateOptions{})
+		assert.NoError(t, err)
+	}
+	addNode := func(name string, labels map[string]string) {
+		node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels}}
+		_, err := clientset.CoreV1().Nodes().Create(ctx, node, metav1.CreateOptions{})
+		assert.NoError(t, err)
+	}
+
+	// the rack label of node1 changed, the labels of node2 did not
+	addNode("node1", map[string]string{"example.com/rack": "rack2"})
+	addNode("node2", map[string]string{"example.com/rack": "rack1"})
+	addOSD("0", "node1", "root=default host=node1 rack=rack1")
+	addOSD("1", "node1", "root=default host=node1 rack=rack1")
+	addOSD("2", "node2", "root=default rack=rack1 host=node2")
+
+	c.updateCrushTopology()
+	assert.Equal(t, [][]string{{"node1", "root=default", "rack=rack2"}}, moves)
+	assert.Equal(t, map[string]string{"node1": "root=default host=node1 rack=rack2"}, c.crushLocations)
+
+}
+
+func TestSameCrushLocation(t *testing.T) {
+	assert.True(t, sameCrushLocation("root=default host=a rack=b", "rack=b root=default host=a"))
+	assert.False(t, sameCrushLocation("root=default host=a rack=b", "root=default host=a"))
+	assert.Equal(t, "a", crushLocationHost("root=default host=a rack=b"))
+	assert.Equal(t, "", crushLocationHost("root=default"))
+}
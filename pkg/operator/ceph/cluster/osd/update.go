@@ -47,6 +47,7 @@ type updateConfig struct {
 	queue            *updateQueue   // these OSDs need updated
 	numUpdatesNeeded int            // the number of OSDs that needed updating
 	deployments      *existenceList // these OSDs have existing deployments
+	failureDomains   map[int]string // if set, only OSDs in the same failure domain are updated together
 }
 
 func (c *Cluster) newUpdateConfig(
@@ -60,6 +61,7 @@ func (c *Cluster) newUpdateConfig(
 		queue,
 		queue.Len(),
 		deployments,
+		nil,
 	}
 }
 
@@ -97,6 +99,10 @@ func (c *updateConfig) updateExistingOSDs(errs *provisionErrors) {
 		}
 	}
 
+	if c.failureDomains != nil {
+		osdIDs = osdsInSameFailureDomain(osdIDQuery, osdIDs, c.failureDomains)
+	}
+
 	logger.Debugf("updating OSDs: %v", osdIDs)
 
 	updatedDeployments := make([]*appsv1.Deployment, 0, len(osdIDs))
@@ -0,0 +1,188 @@
+/*
+Copyright 2021 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package osd
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	cephclient "github.com/rook/rook/pkg/daemon/ceph/client"
+	"github.com/rook/rook/pkg/operator/ceph/controller"
+	"github.com/rook/rook/pkg/operator/k8sutil"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var (
+	// allow unit tests to override these values
+	isCephHealthyFunc = cephclient.IsCephHealthy
+	hostTreeFunc      = cephclient.HostTree
+)
+
+// applyUpgradeStrategy restricts which OSDs are updated when the upgrade strategy of the cluster
+// uses canary OSDs or upgrades the OSDs one failure domain at a time. The OSDs that must not be
+// upgraded yet are removed from the update queue and a message explaining why is returned.
+func (c *Cluster) applyUpgradeStrategy(updateConfig *updateConfig) (string, error) {
+	strategy := c.spec.UpgradeStrategy
+	if !strategy.HasCanary() && strategy.OSDFailureDomain == "" {
+		return "", nil
+	}
+
+	pending, upgraded, err := c.getOSDsByVersion(updateConfig.queue)
+	if err != nil {
+		return "", err
+	}
+	if len(pending) == 0 {
+		return "", nil
+	}
+
+	if strategy.OSDFailureDomain != "" {
+		tree, err := hostTreeFunc(c.context, c.clusterInfo)
+		if err != nil {
+			return "", errors.Wrap(err, "failed to get the osd tree to upgrade the OSDs by failure domain")
+		}
+		updateConfig.failureDomains = cephclient.OSDFailureDomains(tree, strategy.OSDFailureDomain)
+		sortByFailureDomain(pending, updateConfig.failureDomains)
+		// update the pending OSDs first so they are upgraded in failure domain order
+		updateConfig.queue.Remove(pending)
+		updateConfig.queue.q = append(append([]int{}, pending...), updateConfig.queue.q...)
+	}
+
+	if !strategy.HasCanary() {
+		return "", nil
+	}
+
+	startTimes, err := c.getOSDStartTimes(upgraded)
+	if err != nil {
+		return "", err
+	}
+	held, message := canaryOSDsToHold(pending, startTimes, strategy.Canary, time.Now())
+	if len(held) == 0 && len(startTimes) >= strategy.Canary.OSDCount && !isCephHealthyFunc(c.context, c.clusterInfo) {
+		held = pending
+		message = "waiting for ceph to be healthy before upgrading the remaining OSDs after the canary OSDs"
+	}
+	if len(held) > 0 {
+		logger.Infof("not upgrading OSDs %v. %s", held, message)
+		updateConfig.queue.Remove(held)
+	}
+
+	return message, nil
+}
+
+// getOSDsByVersion returns the IDs of the queued OSDs that are not running the desired ceph version
+// yet and the IDs of the OSDs that have already been upgraded
+func (c *Cluster) getOSDsByVersion(queue *updateQueue) (pending, upgraded []int, err error) {
+	listOpts := metav1.ListOptions{LabelSelector: fmt.Sprintf("%s=%s", k8sutil.AppAttr, AppName)}
+	deps, err := c.context.Clientset.AppsV1().Deployments(c.clusterInfo.Namespace).List(c.clusterInfo.Context, listOpts)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to list OSD deployments to check their ceph version")
+	}
+
+	desiredVersion := controller.GetCephVersionLabel(c.clusterInfo.CephVersion)
+	for i := range deps.Items {
+		id, err := getOSDID(&deps.Items[i])
+		if err != nil {
+			continue
+		}
+		if deps.Items[i].Labels[controller.CephVersionLabelKey] == desiredVersion {
+			upgraded = append(upgraded, id)
+		} else if queue.Exists(id) {
+			pending = append(pending, id)
+		}
+	}
+	sort.Ints(pending)
+
+	return pending, upgraded, nil
+}
+
+// getOSDStartTimes returns the time at which the pods of the given OSDs started
+func (c *Cluster) getOSDStartTimes(osdIDs []int) ([]time.Time, error) {
+	if len(osdIDs) == 0 {
+		return []time.Time{}, nil
+	}
+
+	listOpts := metav1.ListOptions{LabelSelector: fmt.Sprintf("%s=%s", k8sutil.AppAttr, AppName)}
+	pods, err := c.context.Clientset.CoreV1().Pods(c.clusterInfo.Namespace).List(c.clusterInfo.Context, listOpts)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list OSD pods to check the canary OSDs")
+	}
+
+	wanted := map[string]bool{}
+	for _, id := range osdIDs {
+		wanted[strconv.Itoa(id)] = true
+	}
+
+	startTimes := []time.Time{}
+	for _, pod := range pods.Items {
+		if !wanted[pod.Labels[OsdIdLabelKey]] {
+			continue
+		}
+		// a pod that has not started yet is considered to start now
+		startTime := time.Now()
+		if pod.Status.StartTime != nil {
+			startTime = pod.Status.StartTime.Time
+		}
+		startTimes = append(startTimes, startTime)
+	}
+
+	return startTimes, nil
+}
+
+// canaryOSDsToHold returns the pending OSDs that must not be upgraded yet. Until the number of
+// canary OSDs has been upgraded, only enough OSDs to reach it are allowed. Then, all the pending
+// OSDs are held until the canary OSDs have been running for the soak time.
+func canaryOSDsToHold(pending []int, startTimes []time.Time, canary *cephv1.UpgradeCanarySpec, now time.Time) ([]int, string) {
+	upgraded := len(startTimes)
+	if upgraded < canary.OSDCount {
+		allowed := canary.OSDCount - upgraded
+		if len(pending) <= allowed {
+			return []int{}, ""
+		}
+		return pending[allowed:], fmt.Sprintf("waiting for %d canary OSDs to be upgraded", canary.OSDCount)
+	}
+
+	sorted := append([]time.Time{}, startTimes...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Before(sorted[j]) })
+	soakEnd := sorted[canary.OSDCount-1].Add(canary.GetSoakTime())
+	if now.Before(soakEnd) {
+		return pending, fmt.Sprintf("waiting for the canary OSDs to soak until %s", soakEnd.UTC().Format(time.RFC3339))
+	}
+
+	return []int{}, ""
+}
+
+// sortByFailureDomain sorts the OSDs by the name of their failure domain
+func sortByFailureDomain(osdIDs []int, failureDomains map[int]string) {
+	sort.SliceStable(osdIDs, func(i, j int) bool {
+		return failureDomains[osdIDs[i]] < failureDomains[osdIDs[j]]
+	})
+}
+
+// osdsInSameFailureDomain filters the OSDs that are in the same failure domain as the given OSD
+func osdsInSameFailureDomain(osdID int, osdIDs []int, failureDomains map[int]string) []int {
+	domain := failureDomains[osdID]
+	filtered := []int{}
+	for _, id := range osdIDs {
+		if failureDomains[id] == domain {
+			filtered = append(filtered, id)
+		}
+	}
+	return filtered
+}
@@ -0,0 +1,80 @@
+/*
+Copyright 2021 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package osd
+
+import (
+	"testing"
+	"time"
+
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestCanaryOSDsToHold(t *testing.T) {
+	now := time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+	canary := &cephv1.UpgradeCanarySpec{OSDCount: 2, SoakTime: &metav1.Duration{Duration: 10 * time.Minute}}
+	pending := []int{1, 2, 3, 4}
+
+	t.Run("no canary upgraded yet", func(t *testing.T) {
+		held, message := canaryOSDsToHold(pending, []time.Time{}, canary, now)
+		assert.Equal(t, []int{3, 4}, held)
+		assert.Contains(t, message, "2 canary OSDs")
+	})
+
+	t.Run("one canary upgraded", func(t *testing.T) {
+		held, _ := canaryOSDsToHold(pending[1:], []time.Time{now}, canary, now)
+		assert.Equal(t, []int{3, 4}, held)
+	})
+
+	t.Run("not enough pending OSDs to hold", func(t *testing.T) {
+		held, message := canaryOSDsToHold([]int{4}, []time.Time{now}, canary, now)
+		assert.Empty(t, held)
+		assert.Empty(t, message)
+	})
+
+	t.Run("canaries soaking", func(t *testing.T) {
+		startTimes := []time.Time{now.Add(-5 * time.Minute), now.Add(-20 * time.Minute)}
+		held, message := canaryOSDsToHold(pending[2:], startTimes, canary, now)
+		assert.Equal(t, []int{3, 4}, held)
+		assert.Contains(t, message, "2021-06-01T12:05:00Z")
+	})
+
+	t.Run("canaries soaked", func(t *testing.T) {
+		startTimes := []time.Time{now.Add(-15 * time.Minute), now.Add(-20 * time.Minute), now}
+		held, message := canaryOSDsToHold(pending[2:], startTimes, canary, now)
+		assert.Empty(t, held)
+		assert.Empty(t, message)
+	})
+
+	t.Run("no soak time", func(t *testing.T) {
+		held, _ := canaryOSDsToHold(pending[2:], []time.Time{now, now}, &cephv1.UpgradeCanarySpec{OSDCount: 2}, now)
+		assert.Empty(t, held)
+	})
+}
+
+func TestUpgradeByFailureDomain(t *testing.T) {
+	failureDomains := map[int]string{0: "zone-b", 1: "zone-a", 2: "zone-b", 3: "zone-a", 4: "zone-c"}
+
+	osdIDs := []int{0, 1, 2, 3, 4}
+	sortByFailureDomain(osdIDs, failureDomains)
+	assert.Equal(t, []int{1, 3, 0, 2, 4}, osdIDs)
+
+	assert.Equal(t, []int{1, 3}, osdsInSameFailureDomain(1, []int{1, 0, 3, 4}, failureDomains))
+	assert.Equal(t, []int{4}, osdsInSameFailureDomain(4, []int{4, 2}, failureDomains))
+	assert.Equal(t, []int{5}, osdsInSameFailureDomain(5, []int{5, 2}, failureDomains))
+}
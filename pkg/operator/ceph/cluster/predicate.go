@@ -42,7 +42,7 @@ func predicateForNodeWatcher(client client.Client, context *clusterd.Context) pr
 
 		UpdateFunc: func(e event.UpdateEvent) bool {
 			clientCluster := newClientCluster(client, e.ObjectNew.GetNamespace(), context)
-			return clientCluster.onK8sNode(e.ObjectNew)
+			return clientCluster.onK8sNodeUpdate(e.ObjectOld, e.ObjectNew)
 		},
 
 		DeleteFunc: func(e event.DeleteEvent) bool {
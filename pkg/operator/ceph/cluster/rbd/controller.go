@@ -140,6 +140,7 @@ func add(mgr manager.Manager, r reconcile.Reconciler) error {
 func (r *ReconcileCephRBDMirror) Reconcile(context context.Context, request reconcile.Request) (reconcile.Result, error) {
 	// workaround because the rook logging mechanism is not compatible with the controller-runtime logging interface
 	reconcileResponse, err := r.reconcile(request)
+	reconcileResponse, err = opcontroller.RequeueIfCephCommandsUnavailable(reconcileResponse, err)
 	if err != nil {
 		r.updateStatus(r.client, request.NamespacedName, k8sutil.FailedStatus)
 		logger.Errorf("failed to reconcile %v", err)
@@ -0,0 +1,191 @@
+/*
+Copyright 2021 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	cephclient "github.com/rook/rook/pkg/daemon/ceph/client"
+	"github.com/rook/rook/pkg/operator/ceph/config"
+	opcontroller "github.com/rook/rook/pkg/operator/ceph/controller"
+	"github.com/rook/rook/pkg/operator/ceph/reporting"
+	cephver "github.com/rook/rook/pkg/operator/ceph/version"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// checkUpgradeGate is called before the upgrade starts and every time a daemon type has been
+// reconciled. During an upgrade, it reports the upgrade progress in the CephCluster status and
+// returns ErrUpgradePaused if the upgrade strategy requires the rollout to stop before the next
+// daemon type. An empty daemonType means no daemon type has been reconciled yet.
+func (c *cluster) checkUpgradeGate(cephVersion cephver.CephVersion, daemonType string) error {
+	if !c.isUpgrade {
+		return nil
+	}
+
+	strategy := c.Spec.UpgradeStrategy
+	phase := cephv1.UpgradePhaseProgressing
+	message := ""
+	pausedAfter := ""
+	switch {
+	case strategy.Paused:
+		message = "upgrade is paused, set upgradeStrategy.paused to false to resume"
+	case daemonType != "" && strategy.PausesAfter(daemonType):
+		pausedAfter = daemonType
+		message = fmt.Sprintf("upgrade is paused after the %q daemons, remove %q from upgradeStrategy.pauseAfter to resume", daemonType, daemonType)
+	case strategy.PauseOnHealthError && c.isCephHealthError():
+		message = "upgrade is paused while ceph health is HEALTH_ERR"
+	}
+	if message != "" {
+		phase = cephv1.UpgradePhasePaused
+	}
+
+	c.updateUpgradeStatus(cephVersion, phase, message, pausedAfter)
+
+	if phase == cephv1.UpgradePhasePaused {
+		logger.Infof("%s", message)
+		return errors.Wrap(opcontroller.ErrUpgradePaused, message)
+	}
+	return nil
+}
+
+// isCephHealthError returns true if ceph reports HEALTH_ERR. A failure to get the ceph status is
+// not treated as an error since the ok-to-stop checks will catch an unavailable cluster.
+func (c *cluster) isCephHealthError() bool {
+	status, err := cephclient.Status(c.context, c.ClusterInfo)
+	if err != nil {
+		logger.Warningf("failed to check ceph health for the upgrade. %v", err)
+		return false
+	}
+	return status.Health.Status == "HEALTH_ERR"
+}
+
+// updateUpgradeStatus reports the upgrade phase and the progress of each daemon type in the
+// CephCluster status
+func (c *cluster) updateUpgradeStatus(cephVersion cephver.CephVersion, phase cephv1.UpgradePhase, message, pausedAfter string) {
+	cephCluster, err := c.context.RookClientset.CephV1().CephClusters(c.namespacedName.Namespace).Get(c.ClusterInfo.Context, c.namespacedName.Name, metav1.GetOptions{})
+	if err != nil {
+		if kerrors.IsNotFound(err) {
+			logger.Debug("CephCluster resource not found. Ignoring since object must be deleted.")
+			return
+		}
+		logger.Errorf("failed to retrieve ceph cluster %q to update the upgrade status. %v", c.namespacedName.Name, err)
+		return
+	}
+
+	status := &cephv1.UpgradeStatus{
+		Phase:       phase,
+		Message:     message,
+		Image:       c.Spec.CephVersion.Image,
+		Version:     opcontroller.GetCephVersionLabel(cephVersion),
+		PausedAfter: pausedAfter,
+		LastUpdated: formatTime(time.Now().UTC()),
+	}
+
+	versions, err := cephclient.GetAllCephDaemonVersions(c.context, c.ClusterInfo)
+	if err != nil {
+		logger.Warningf("failed to get ceph daemons versions for the upgrade status. %v", err)
+		if cephCluster.Status.UpgradeStatus != nil {
+			status.Daemons = cephCluster.Status.UpgradeStatus.Daemons
+		}
+	} else {
+		status.Daemons = daemonUpgradeProgress(versions, cephVersion)
+		if phase == cephv1.UpgradePhaseProgressing && isUpgradeComplete(status.Daemons) {
+			status.Phase = cephv1.UpgradePhaseCompleted
+		}
+	}
+
+	cephCluster.Status.UpgradeStatus = status
+	if err := reporting.UpdateStatus(c.context.Client, cephCluster); err != nil {
+		logger.Errorf("failed to update cluster %q upgrade status. %v", c.namespacedName.Name, err)
+	}
+}
+
+// refreshUpgradeStatus updates the per daemon type progress of an upgrade in progress and marks
+// the upgrade as completed once all the daemons run the new version
+func refreshUpgradeStatus(status *cephv1.UpgradeStatus, versions *cephv1.CephDaemonsVersions) {
+	if !status.IsInProgress() {
+		return
+	}
+	targetVersion, err := opcontroller.ExtractCephVersionFromLabel(status.Version)
+	if err != nil {
+		logger.Warningf("failed to parse upgrade version %q. %v", status.Version, err)
+		return
+	}
+
+	status.Daemons = daemonUpgradeProgress(versions, *targetVersion)
+	status.LastUpdated = formatTime(time.Now().UTC())
+	if isUpgradeComplete(status.Daemons) {
+		status.Phase = cephv1.UpgradePhaseCompleted
+		status.Message = ""
+		status.PausedAfter = ""
+	}
+}
+
+// daemonUpgradeProgress returns, for each daemon type in upgrade order, how many daemons already
+// run the target version
+func daemonUpgradeProgress(versions *cephv1.CephDaemonsVersions, targetVersion cephver.CephVersion) []cephv1.DaemonUpgradeStatus {
+	progress := []cephv1.DaemonUpgradeStatus{}
+	for _, daemonType := range opcontroller.UpgradeDaemonOrder {
+		var daemonVersions map[string]int
+		switch daemonType {
+		case config.MonType:
+			daemonVersions = versions.Mon
+		case config.MgrType:
+			daemonVersions = versions.Mgr
+		case config.OsdType:
+			daemonVersions = versions.Osd
+		case config.MdsType:
+			daemonVersions = versions.Mds
+		case config.RgwType:
+			daemonVersions = versions.Rgw
+		}
+		if len(daemonVersions) == 0 {
+			continue
+		}
+
+		daemonProgress := cephv1.DaemonUpgradeStatus{DaemonType: daemonType}
+		for v, count := range daemonVersions {
+			daemonProgress.Total += count
+			version, err := cephver.ExtractCephVersion(v)
+			if err != nil {
+				logger.Warningf("failed to extract ceph version from %q. %v", v, err)
+				continue
+			}
+			// the target version may come from a version label, which has no commit ID
+			if version.Major == targetVersion.Major && version.Minor == targetVersion.Minor &&
+				version.Extra == targetVersion.Extra && version.Build == targetVersion.Build {
+				daemonProgress.Upgraded += count
+			}
+		}
+		progress = append(progress, daemonProgress)
+	}
+
+	return progress
+}
+
+func isUpgradeComplete(progress []cephv1.DaemonUpgradeStatus) bool {
+	for _, p := range progress {
+		if p.Upgraded != p.Total {
+			return false
+		}
+	}
+	return true
+}
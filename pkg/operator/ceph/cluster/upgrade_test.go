@@ -0,0 +1,75 @@
+/*
+Copyright 2021 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"testing"
+
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	cephver "github.com/rook/rook/pkg/operator/ceph/version"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	oldVersion = "ceph version 15.2.12 (ce065eabfa5ce81323b009786bdf5bb03127cbe1) octopus (stable)"
+	newVersion = "ceph version 16.2.4 (3cbe25cde3cfa028984618ad32de9edc4c1eaed0) pacific (stable)"
+)
+
+func TestDaemonUpgradeProgress(t *testing.T) {
+	target := cephver.CephVersion{Major: 16, Minor: 2, Extra: 4}
+	versions := &cephv1.CephDaemonsVersions{
+		Mon: map[string]int{newVersion: 3},
+		Mgr: map[string]int{newVersion: 1},
+		Osd: map[string]int{oldVersion: 4, newVersion: 2},
+		Rgw: map[string]int{oldVersion: 1},
+	}
+
+	progress := daemonUpgradeProgress(versions, target)
+	assert.Equal(t, []cephv1.DaemonUpgradeStatus{
+		{DaemonType: "mon", Upgraded: 3, Total: 3},
+		{DaemonType: "mgr", Upgraded: 1, Total: 1},
+		{DaemonType: "osd", Upgraded: 2, Total: 6},
+		{DaemonType: "rgw", Upgraded: 0, Total: 1},
+	}, progress)
+	assert.False(t, isUpgradeComplete(progress))
+	assert.True(t, isUpgradeComplete(progress[:2]))
+}
+
+func TestRefreshUpgradeStatus(t *testing.T) {
+	versions := &cephv1.CephDaemonsVersions{
+		Mon: map[string]int{newVersion: 3},
+		Osd: map[string]int{oldVersion: 1, newVersion: 2},
+	}
+
+	// nothing to do without an upgrade in progress
+	refreshUpgradeStatus(nil, versions)
+	status := &cephv1.UpgradeStatus{Phase: cephv1.UpgradePhaseCompleted, Version: "16.2.4-0"}
+	refreshUpgradeStatus(status, versions)
+	assert.Empty(t, status.Daemons)
+
+	status = &cephv1.UpgradeStatus{Phase: cephv1.UpgradePhasePaused, Version: "16.2.4-0", PausedAfter: "mon", Message: "paused"}
+	refreshUpgradeStatus(status, versions)
+	assert.Equal(t, cephv1.UpgradePhasePaused, status.Phase)
+	assert.Equal(t, 2, len(status.Daemons))
+	assert.Equal(t, 2, status.Daemons[1].Upgraded)
+
+	versions.Osd = map[string]int{newVersion: 3}
+	refreshUpgradeStatus(status, versions)
+	assert.Equal(t, cephv1.UpgradePhaseCompleted, status.Phase)
+	assert.Empty(t, status.PausedAfter)
+	assert.Empty(t, status.Message)
+}
@@ -18,14 +18,29 @@ limitations under the License.
 package cluster
 
 import (
+	"time"
+
 	"github.com/pkg/errors"
 	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
 	daemonclient "github.com/rook/rook/pkg/daemon/ceph/client"
 	"github.com/rook/rook/pkg/operator/ceph/cluster/mon"
 	"github.com/rook/rook/pkg/operator/ceph/controller"
+	"github.com/rook/rook/pkg/operator/ceph/reporting"
 	cephver "github.com/rook/rook/pkg/operator/ceph/version"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// maxImageHistory is the number of images kept in the image history of the cluster status
+	maxImageHistory = 10
 )
 
+// errCephDowngrade is returned when the image would downgrade the cluster to a previous Ceph
+// release. Once all the OSDs run a release, "require-osd-release" is set to that release and the
+// OSDs of a previous release can no longer start, so such a downgrade cannot be recovered from.
+var errCephDowngrade = errors.New("downgrading to a previous ceph release is not supported")
+
 func (c *ClusterController) detectAndValidateCephVersion(cluster *cluster) (*cephver.CephVersion, bool, error) {
 	version, err := controller.DetectCephVersion(
 		c.rookImage,
@@ -83,6 +98,18 @@ func diffImageSpecAndClusterRunningVersion(imageSpecVersion cephver.CephVersion,
 	}
 
 	if numberOfCephVersions > 1 {
+		// an upgrade did not complete, the image must not go back to a release older than any of
+		// the running daemons
+		for v := range runningVersions.Overall {
+			version, err := cephver.ExtractCephVersion(v)
+			if err != nil {
+				logger.Errorf("failed to extract ceph version. %v", err)
+				return false, err
+			}
+			if imageSpecVersion.Major < version.Major {
+				return true, errors.Wrapf(errCephDowngrade, "image spec version %s is from an older release than the running version %s", imageSpecVersion.String(), version.String())
+			}
+		}
 		// let's return immediately
 		logger.Warningf("it looks like we have more than one ceph version running. triggering upgrade. %+v:", runningVersions.Overall)
 		return true, nil
@@ -109,7 +136,11 @@ func diffImageSpecAndClusterRunningVersion(imageSpecVersion cephver.CephVersion,
 			}
 
 			if cephver.IsInferior(imageSpecVersion, clusterRunningVersion) {
-				return true, errors.Errorf("image spec version %s is lower than the running cluster version %s, downgrading is not supported", imageSpecVersion.String(), clusterRunningVersion.String())
+				if imageSpecVersion.Major != clusterRunningVersion.Major {
+					return true, errors.Wrapf(errCephDowngrade, "image spec version %s is lower than the running cluster version %s", imageSpecVersion.String(), clusterRunningVersion.String())
+				}
+				logger.Warningf("image spec version %s is lower than the running cluster version %s, rolling back within the %q release", imageSpecVersion.String(), clusterRunningVersion.String(), clusterRunningVersion.ReleaseName())
+				return true, nil
 			}
 		}
 	}
@@ -181,6 +212,9 @@ func (c *cluster) validateCephVersion(version *cephver.CephVersion) error {
 
 	runningVersions := *versions
 	differentImages, err := diffImageSpecAndClusterRunningVersion(*version, runningVersions)
+	if errors.Is(err, errCephDowngrade) {
+		return errors.Wrapf(err, "refusing to run image %q. set the image back to the running release or set revertToLastKnownGood to true", c.Spec.CephVersion.Image)
+	}
 	if err != nil {
 		logger.Errorf("failed to determine if we should upgrade or not. %v", err)
 		// we shouldn't block the orchestration if we can't determine the version of the image spec, we proceed anyway in best effort
@@ -207,3 +241,113 @@ func (c *cluster) validateCephVersion(version *cephver.CephVersion) error {
 
 	return nil
 }
+
+// updateCephImageHistory records the image in the history of the images successfully applied to
+// the cluster
+func (c *ClusterController) updateCephImageHistory(image string, cephVersion cephver.CephVersion) {
+	cephCluster, err := c.context.RookClientset.CephV1().CephClusters(c.namespacedName.Namespace).Get(c.OpManagerCtx, c.namespacedName.Name, metav1.GetOptions{})
+	if err != nil {
+		if kerrors.IsNotFound(err) {
+			logger.Debug("CephCluster resource not found. Ignoring since object must be deleted.")
+			return
+		}
+		logger.Errorf("failed to retrieve ceph cluster %q to update the image history. %v", c.namespacedName.Name, err)
+		return
+	}
+
+	history, added := addImageHistory(cephCluster.Status.ImageHistory, image, cephVersion, time.Now().UTC())
+	if !added {
+		return
+	}
+
+	cephCluster.Status.ImageHistory = history
+	if err := reporting.UpdateStatus(c.client, cephCluster); err != nil {
+		logger.Errorf("failed to update cluster %q image history. %v", c.namespacedName.Name, err)
+	}
+}
+
+// revertToLastKnownGoodImage sets the image of the cluster back to the last image that was
+// successfully applied, after validating the revert would not downgrade the running Ceph release
+func (c *ClusterController) revertToLastKnownGoodImage(cephCluster *cephv1.CephCluster) error {
+	lastKnownGood := lastKnownGoodImage(cephCluster.Status.ImageHistory, cephCluster.Spec.CephVersion.Image)
+	if lastKnownGood == nil {
+		return errors.New("failed to revert the ceph image. no other image was successfully applied to the cluster")
+	}
+
+	var runningVersions *cephv1.CephDaemonsVersions
+	if cephCluster.Status.CephStatus != nil {
+		runningVersions = cephCluster.Status.CephStatus.Versions
+	}
+	if err := validateImageRevert(*lastKnownGood, runningVersions); err != nil {
+		return errors.Wrapf(err, "failed to revert the ceph image to %q", lastKnownGood.Image)
+	}
+
+	logger.Infof("reverting ceph image of cluster %q from %q to the last known good image %q", cephCluster.Name, cephCluster.Spec.CephVersion.Image, lastKnownGood.Image)
+	cephCluster.Spec.CephVersion.Image = lastKnownGood.Image
+	cephCluster.Spec.CephVersion.RevertToLastKnownGood = false
+	if err := c.client.Update(c.OpManagerCtx, cephCluster); err != nil {
+		return errors.Wrapf(err, "failed to revert the ceph image to %q", lastKnownGood.Image)
+	}
+
+	return nil
+}
+
+// addImageHistory returns the image history with the image as the most recent entry and whether
+// the image was added. Only the last maxImageHistory images are kept.
+func addImageHistory(history []cephv1.CephImageHistory, image string, cephVersion cephver.CephVersion, now time.Time) ([]cephv1.CephImageHistory, bool) {
+	version := controller.GetCephVersionLabel(cephVersion)
+	if len(history) > 0 {
+		last := history[len(history)-1]
+		if last.Image == image && last.Version == version {
+			// the image is already the most recent one
+			return history, false
+		}
+	}
+
+	newHistory := append([]cephv1.CephImageHistory{}, history...)
+	newHistory = append(newHistory, cephv1.CephImageHistory{
+		Image:       image,
+		Version:     version,
+		AppliedTime: formatTime(now),
+	})
+	if len(newHistory) > maxImageHistory {
+		newHistory = newHistory[len(newHistory)-maxImageHistory:]
+	}
+
+	return newHistory, true
+}
+
+// lastKnownGoodImage returns the most recent image of the history that is not the current image
+func lastKnownGoodImage(history []cephv1.CephImageHistory, currentImage string) *cephv1.CephImageHistory {
+	for i := len(history) - 1; i >= 0; i-- {
+		if history[i].Image != currentImage {
+			return &history[i]
+		}
+	}
+	return nil
+}
+
+// validateImageRevert returns an error if reverting to the image would change the Ceph release of
+// any of the running daemons
+func validateImageRevert(image cephv1.CephImageHistory, runningVersions *cephv1.CephDaemonsVersions) error {
+	if runningVersions == nil || len(runningVersions.Overall) == 0 {
+		return errors.New("the versions of the running ceph daemons are unknown")
+	}
+
+	imageVersion, err := controller.ExtractCephVersionFromLabel(image.Version)
+	if err != nil {
+		return errors.Wrapf(err, "failed to extract ceph version of image %q", image.Image)
+	}
+
+	for v := range runningVersions.Overall {
+		runningVersion, err := cephver.ExtractCephVersion(v)
+		if err != nil {
+			return errors.Wrapf(err, "failed to extract running ceph version %q", v)
+		}
+		if runningVersion.Major != imageVersion.Major {
+			return errors.Wrapf(errCephDowngrade, "image version %s is not from the same release as the running version %s", imageVersion.String(), runningVersion.String())
+		}
+	}
+
+	return nil
+}
@@ -21,7 +21,9 @@ import (
 	"context"
 	"encoding/json"
 	"testing"
+	"time"
 
+	"github.com/pkg/errors"
 	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
 	"github.com/rook/rook/pkg/clusterd"
 	"github.com/rook/rook/pkg/daemon/ceph/client"
@@ -50,6 +52,7 @@ func TestDiffImageSpecAndClusterRunningVersion(t *testing.T) {
 	assert.False(t, m)
 
 	// 2nd test - more than 1 version means we should upgrade
+	fakeImageVersion = cephver.Quincy
 	fakeRunningVersions = []byte(`
 	{
 		"overall": {
@@ -65,6 +68,13 @@ func TestDiffImageSpecAndClusterRunningVersion(t *testing.T) {
 	assert.NoError(t, err)
 	assert.True(t, m)
 
+	// more than 1 version but the spec version is from an older release than one of them
+	fakeImageVersion = cephver.Pacific
+	m, err = diffImageSpecAndClusterRunningVersion(fakeImageVersion, dummyRunningVersions2)
+	assert.True(t, errors.Is(err, errCephDowngrade))
+	assert.True(t, m)
+	fakeImageVersion = cephver.Nautilus
+
 	// 3rd test - spec version is lower than running cluster? what's going on?
 	fakeRunningVersions = []byte(`
 		{
@@ -78,6 +88,13 @@ func TestDiffImageSpecAndClusterRunningVersion(t *testing.T) {
 
 	m, err = diffImageSpecAndClusterRunningVersion(fakeImageVersion, dummyRunningVersions3)
 	assert.Error(t, err)
+	assert.True(t, errors.Is(err, errCephDowngrade))
+	assert.True(t, m)
+
+	// spec version is lower than running cluster but in the same release --> we roll back
+	fakeImageVersion = cephver.CephVersion{Major: 15, Minor: 1, Extra: 1}
+	m, err = diffImageSpecAndClusterRunningVersion(fakeImageVersion, dummyRunningVersions3)
+	assert.NoError(t, err)
 	assert.True(t, m)
 
 	// 4 test - spec version is higher than running cluster --> we upgrade
@@ -148,6 +165,63 @@ func TestDiffImageSpecAndClusterRunningVersion(t *testing.T) {
 	assert.False(t, m)
 }
 
+func TestImageHistory(t *testing.T) {
+	now := time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+	octopus := cephver.CephVersion{Major: 15, Minor: 2, Extra: 13}
+	pacific := cephver.CephVersion{Major: 16, Minor: 2, Extra: 5}
+
+	history, added := addImageHistory(nil, "ceph:v15.2.13", octopus, now)
+	assert.True(t, added)
+	assert.Equal(t, []cephv1.CephImageHistory{{Image: "ceph:v15.2.13", Version: "15.2.13-0", AppliedTime: "2021-06-01T12:00:00Z"}}, history)
+
+	// the same image is not added twice in a row
+	history, added = addImageHistory(history, "ceph:v15.2.13", octopus, now)
+	assert.False(t, added)
+	assert.Equal(t, 1, len(history))
+
+	history, added = addImageHistory(history, "ceph:v16.2.5", pacific, now)
+	assert.True(t, added)
+	assert.Equal(t, 2, len(history))
+	assert.Equal(t, "ceph:v16.2.5", history[1].Image)
+
+	// the history is capped
+	for i := 0; i < maxImageHistory; i++ {
+		history, _ = addImageHistory(history, "ceph:v15.2.13", octopus, now)
+		history, _ = addImageHistory(history, "ceph:v16.2.5", pacific, now)
+	}
+	assert.Equal(t, maxImageHistory, len(history))
+	assert.Equal(t, "ceph:v16.2.5", history[maxImageHistory-1].Image)
+
+	// last known good image
+	assert.Nil(t, lastKnownGoodImage(nil, "ceph:v16.2.5"))
+	assert.Nil(t, lastKnownGoodImage(history[len(history)-1:], "ceph:v16.2.5"))
+	assert.Equal(t, "ceph:v16.2.5", lastKnownGoodImage(history, "ceph:v16.2.6").Image)
+	assert.Equal(t, "ceph:v15.2.13", lastKnownGoodImage(history, "ceph:v16.2.5").Image)
+}
+
+func TestValidateImageRevert(t *testing.T) {
+	image := cephv1.CephImageHistory{Image: "ceph:v16.2.5", Version: "16.2.5-0"}
+
+	// running versions are unknown
+	assert.Error(t, validateImageRevert(image, nil))
+	assert.Error(t, validateImageRevert(image, &cephv1.CephDaemonsVersions{}))
+
+	// patch level rollback
+	running := &cephv1.CephDaemonsVersions{Overall: map[string]int{
+		"ceph version 16.2.6 (ee28fb57e47e9f88813e24bbf4c14496ca299d31) pacific (stable)": 3,
+	}}
+	assert.NoError(t, validateImageRevert(image, running))
+
+	// some daemons already run the next release
+	running.Overall["ceph version 17.2.0 (3a54b2b6d167d4a2a19e003a705696d4fe619afc) quincy (stable)"] = 1
+	err := validateImageRevert(image, running)
+	assert.True(t, errors.Is(err, errCephDowngrade))
+
+	// invalid version
+	image.Version = "foo"
+	assert.Error(t, validateImageRevert(image, running))
+}
+
 func TestMinVersion(t *testing.T) {
 	c := testSpec(t)
 	c.Spec.CephVersion.AllowUnsupported = true
@@ -19,12 +19,14 @@ package cluster
 
 import (
 	"context"
+	"reflect"
 	"strings"
 
 	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
 	"github.com/rook/rook/pkg/clusterd"
 	cephclient "github.com/rook/rook/pkg/daemon/ceph/client"
 	discoverDaemon "github.com/rook/rook/pkg/daemon/discover"
+	"github.com/rook/rook/pkg/operator/ceph/cluster/osd"
 	opcontroller "github.com/rook/rook/pkg/operator/ceph/controller"
 	"github.com/rook/rook/pkg/operator/k8sutil"
 	v1 "k8s.io/api/core/v1"
@@ -130,6 +132,29 @@ func (c *clientCluster) onK8sNode(object runtime.Object) bool {
 	return false
 }
 
+// onK8sNodeUpdate is triggered when a node is updated in the Kubernetes cluster. The OSDs of the node
+// are moved in the CRUSH map when the topology labels of the node changed.
+func (c *clientCluster) onK8sNodeUpdate(oldObj, newObj runtime.Object) bool {
+	oldNode, ok := oldObj.(*v1.Node)
+	if !ok {
+		return false
+	}
+	newNode, ok := newObj.(*v1.Node)
+	if !ok {
+		return false
+	}
+
+	cluster := c.getCephCluster()
+	oldTopology, _ := osd.ExtractOSDTopologyFromLabels(oldNode.Labels, cluster.Spec.Storage.CrushTopology)
+	newTopology, _ := osd.ExtractOSDTopologyFromLabels(newNode.Labels, cluster.Spec.Storage.CrushTopology)
+	if !reflect.DeepEqual(oldTopology, newTopology) && cluster.Status.Phase == cephv1.ConditionReady {
+		logger.Infof("node watcher: topology labels of node %q changed in cluster %q, updating the CRUSH location of its OSDs", newNode.Name, cluster.Namespace)
+		return true
+	}
+
+	return c.onK8sNode(newNode)
+}
+
 // onDeviceCMUpdate is trigger when the hot plug config map is updated
 func (c *clientCluster) onDeviceCMUpdate(oldObj, newObj runtime.Object) bool {
 	oldCm, ok := oldObj.(*v1.ConfigMap)
@@ -144,6 +144,36 @@ func TestOnK8sNode(t *testing.T) {
 	assert.False(t, b)
 }
 
+func TestOnK8sNodeUpdate(t *testing.T) {
+	ns := "rook-ceph"
+	cephCluster := fakeCluster(ns)
+	cephCluster.Status.Phase = k8sutil.ReadyStatus
+	cephCluster.Spec.Storage.CrushTopology = []cephv1.CrushTopologyLabel{{Label: "example.com/rack", Type: "rack"}}
+	clientCluster := newClientCluster(getFakeClient(cephCluster), ns, &clusterd.Context{Executor: &exectest.MockExecutor{}})
+
+	oldNode := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-update", Labels: map[string]string{"example.com/rack": "rack1"}}}
+	newNode := oldNode.DeepCopy()
+	newNode.Labels["other"] = "label"
+
+	// the node will not be checked again
+	nodesCheckedForReconcile.Insert(oldNode.Name)
+	assert.False(t, clientCluster.onK8sNodeUpdate(oldNode, newNode))
+
+	// the label of the crush topology changed
+	newNode.Labels["example.com/rack"] = "rack2"
+	assert.True(t, clientCluster.onK8sNodeUpdate(oldNode, newNode))
+
+	// a well-known topology label was added
+	newNode = oldNode.DeepCopy()
+	newNode.Labels["topology.rook.io/row"] = "row1"
+	assert.True(t, clientCluster.onK8sNodeUpdate(oldNode, newNode))
+
+	// the cluster is not ready
+	cephCluster.Status.Phase = ""
+	clientCluster.client = getFakeClient(cephCluster)
+	assert.False(t, clientCluster.onK8sNodeUpdate(oldNode, newNode))
+}
+
 func TestOnDeviceCMUpdate(t *testing.T) {
 	// Set DEBUG logging
 	capnslog.SetGlobalLogLevel(capnslog.DEBUG)
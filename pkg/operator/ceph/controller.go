@@ -125,6 +125,8 @@ func (r *ReconcileConfig) reconcile(request reconcile.Request) (reconcile.Result
 	// Reconcile Ceph CLI timeout, since the clusterd context is passed to by pointer to all CRD
 	// controllers they will receive the update
 	opcontroller.SetCephCommandsTimeout(r.config.Parameters)
+	opcontroller.SetSlowCommandThreshold(r.config.Parameters)
+	opcontroller.SetCephCommandsGuard(r.config.Parameters)
 
 	// Reconcile Operator's logging level
 	err = reconcileOperatorLogLevel(opConfig.Data)
@@ -26,6 +26,7 @@ import (
 
 	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
 	"github.com/rook/rook/pkg/clusterd"
+	cephclient "github.com/rook/rook/pkg/daemon/ceph/client"
 	"github.com/rook/rook/pkg/operator/k8sutil"
 	"github.com/rook/rook/pkg/util/exec"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -94,15 +95,81 @@ func SetCephCommandsTimeout(data map[string]string) {
 	exec.CephCommandsTimeout = time.Duration(timeoutSeconds) * time.Second
 }
 
-// canIgnoreHealthErrStatusInReconcile determines whether a status of HEALTH_ERR in the CephCluster can be ignored safely.
-func canIgnoreHealthErrStatusInReconcile(cephCluster cephv1.CephCluster, controllerName string) bool {
-	// Get a list of all the keys causing the HEALTH_ERR status.
+// SetSlowCommandThreshold sets the duration after which the Ceph commands executed from Rook are logged as slow
+func SetSlowCommandThreshold(data map[string]string) {
+	strThresholdSeconds := k8sutil.GetValue(data, "ROOK_CEPH_SLOW_COMMAND_THRESHOLD_SECONDS", "5")
+	thresholdSeconds, err := strconv.Atoi(strThresholdSeconds)
+	if err != nil || thresholdSeconds < 1 {
+		logger.Warningf("ROOK_CEPH_SLOW_COMMAND_THRESHOLD_SECONDS is %q but it should be >= 1, set the default value 5", strThresholdSeconds)
+		thresholdSeconds = 5
+	}
+	exec.SlowCommandThreshold = time.Duration(thresholdSeconds) * time.Second
+}
+
+// SetCephCommandsGuard sets the max number of concurrent Ceph commands of a cluster and the number of
+// consecutive timeouts after which its Ceph commands fail fast until the mons are in quorum again
+func SetCephCommandsGuard(data map[string]string) {
+	strMaxConcurrent := k8sutil.GetValue(data, "ROOK_CEPH_COMMANDS_MAX_CONCURRENT", "20")
+	maxConcurrent, err := strconv.Atoi(strMaxConcurrent)
+	if err != nil || maxConcurrent < 1 {
+		logger.Warningf("ROOK_CEPH_COMMANDS_MAX_CONCURRENT is %q but it should be >= 1, set the default value 20", strMaxConcurrent)
+		maxConcurrent = 20
+	}
+	cephclient.MaxConcurrentCommands = maxConcurrent
+
+	strTimeouts := k8sutil.GetValue(data, "ROOK_CEPH_COMMANDS_CIRCUIT_BREAKER_TIMEOUTS", "3")
+	timeouts, err := strconv.Atoi(strTimeouts)
+	if err != nil || timeouts < 0 {
+		logger.Warningf("ROOK_CEPH_COMMANDS_CIRCUIT_BREAKER_TIMEOUTS is %q but it should be >= 0, set the default value 3", strTimeouts)
+		timeouts = 3
+	}
+	cephclient.CommandCircuitTimeouts = timeouts
+}
+
+// RequeueIfCephCommandsUnavailable requeues the request without an error after the delay of the error
+// when the Ceph commands of the cluster fail fast, so the controllers do not retry while the mons are down
+func RequeueIfCephCommandsUnavailable(result reconcile.Result, err error) (reconcile.Result, error) {
+	unavailable, ok := cephclient.IsCommandsUnavailable(err)
+	if !ok {
+		return result, err
+	}
+	logger.Infof("requeuing after %s. %v", unavailable.RetryAfter, err)
+	return reconcile.Result{Requeue: true, RequeueAfter: unavailable.RetryAfter}, nil
+}
+
+// healthErrChecks returns the health checks of the CephCluster status whose severity is HEALTH_ERR,
+// once the severity overrides of the health check spec are applied
+func healthErrChecks(cephCluster cephv1.CephCluster) []string {
 	var healthErrKeys = make([]string, 0)
 	for key, health := range cephCluster.Status.CephStatus.Details {
-		if health.Severity == "HEALTH_ERR" {
+		if cephCluster.Spec.HealthCheck.CheckSeverity(key, health.Severity) == "HEALTH_ERR" {
 			healthErrKeys = append(healthErrKeys, key)
 		}
 	}
+	return healthErrKeys
+}
+
+// hasOverriddenHealthErr returns whether the severity of a HEALTH_ERR health check of the CephCluster
+// status is overridden
+func hasOverriddenHealthErr(cephCluster cephv1.CephCluster) bool {
+	for key, health := range cephCluster.Status.CephStatus.Details {
+		if health.Severity == "HEALTH_ERR" && cephCluster.Spec.HealthCheck.CheckSeverity(key, health.Severity) != "HEALTH_ERR" {
+			return true
+		}
+	}
+	return false
+}
+
+// canIgnoreHealthErrStatusInReconcile determines whether a status of HEALTH_ERR in the CephCluster can be ignored safely.
+func canIgnoreHealthErrStatusInReconcile(cephCluster cephv1.CephCluster, controllerName string) bool {
+	// Get a list of all the keys causing the HEALTH_ERR status.
+	healthErrKeys := healthErrChecks(cephCluster)
+
+	// If the severity of all the health checks causing HEALTH_ERR is overridden, ignore it.
+	if len(healthErrKeys) == 0 && hasOverriddenHealthErr(cephCluster) {
+		logger.Debugf("%q: ignoring ceph status %q because the severity of its causes is overridden (full status is %+v)", controllerName, cephCluster.Status.CephStatus.Health, cephCluster.Status.CephStatus)
+		return true
+	}
 
 	// If there is only one cause for HEALTH_ERR and it's on the allowed list of errors, ignore it.
 	var allowedErrStatus = []string{"MDS_ALL_DOWN"}
@@ -138,6 +205,10 @@ func IsReadyToReconcile(c client.Client, clustercontext *clusterd.Context, names
 	// read the CR status of the cluster
 	if cephCluster.Status.CephStatus != nil {
 		var operatorDeploymentOk = cephCluster.Status.CephStatus.Health == "HEALTH_OK" || cephCluster.Status.CephStatus.Health == "HEALTH_WARN"
+		// a health check whose severity is overridden to HEALTH_ERR blocks the reconcile
+		if operatorDeploymentOk && len(healthErrChecks(cephCluster)) > 0 {
+			operatorDeploymentOk = false
+		}
 
 		if operatorDeploymentOk || canIgnoreHealthErrStatusInReconcile(cephCluster, controllerName) {
 			logger.Debugf("%q: ceph status is %q, operator is ready to run ceph command, reconciling", controllerName, cephCluster.Status.CephStatus.Health)
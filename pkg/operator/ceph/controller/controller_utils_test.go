@@ -20,9 +20,12 @@ import (
 	"testing"
 	"time"
 
+	"github.com/pkg/errors"
 	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	cephclient "github.com/rook/rook/pkg/daemon/ceph/client"
 	"github.com/rook/rook/pkg/util/exec"
 	"github.com/stretchr/testify/assert"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
 
 func CreateTestClusterFromStatusDetails(details map[string]cephv1.CephHealthMessage) cephv1.CephCluster {
@@ -71,6 +74,41 @@ func TestCanIgnoreHealthErrStatusInReconcile(t *testing.T) {
 		},
 	})
 	assert.False(t, canIgnoreHealthErrStatusInReconcile(cluster, "controller"))
+
+	// the severity of the health check is overridden
+	cluster.Spec.HealthCheck.Checks = map[string]cephv1.CephHealthCheckPolicySpec{"TEST_UNIGNORABLE": {Severity: "HEALTH_WARN"}}
+	assert.True(t, canIgnoreHealthErrStatusInReconcile(cluster, "controller"))
+
+	// an error getting the status is not ignored
+	cluster = CreateTestClusterFromStatusDetails(map[string]cephv1.CephHealthMessage{
+		"error": {
+			Severity: "Urgent",
+			Message:  "failed to get status",
+		},
+	})
+	cluster.Spec.HealthCheck.Checks = map[string]cephv1.CephHealthCheckPolicySpec{"TEST_UNIGNORABLE": {Severity: "HEALTH_WARN"}}
+	assert.False(t, canIgnoreHealthErrStatusInReconcile(cluster, "controller"))
+}
+
+func TestHealthErrChecks(t *testing.T) {
+	cluster := CreateTestClusterFromStatusDetails(map[string]cephv1.CephHealthMessage{
+		"MON_DISK_LOW": {
+			Severity: "HEALTH_WARN",
+			Message:  "mon a is low on available space",
+		},
+		"OSD_FULL": {
+			Severity: "HEALTH_ERR",
+			Message:  "1 full osd(s)",
+		},
+	})
+	assert.Equal(t, []string{"OSD_FULL"}, healthErrChecks(cluster))
+
+	// a warning whose severity is raised to HEALTH_ERR blocks the reconcile
+	cluster.Spec.HealthCheck.Checks = map[string]cephv1.CephHealthCheckPolicySpec{
+		"MON_DISK_LOW": {Severity: "HEALTH_ERR"},
+		"OSD_FULL":     {Severity: "HEALTH_WARN"},
+	}
+	assert.Equal(t, []string{"MON_DISK_LOW"}, healthErrChecks(cluster))
 }
 
 func TestSetCephCommandsTimeout(t *testing.T) {
@@ -85,3 +123,47 @@ func TestSetCephCommandsTimeout(t *testing.T) {
 	SetCephCommandsTimeout(map[string]string{"ROOK_CEPH_COMMANDS_TIMEOUT_SECONDS": "1"})
 	assert.Equal(t, 1*time.Second, exec.CephCommandsTimeout)
 }
+
+func TestSetSlowCommandThreshold(t *testing.T) {
+	SetSlowCommandThreshold(map[string]string{})
+	assert.Equal(t, 5*time.Second, exec.SlowCommandThreshold)
+
+	SetSlowCommandThreshold(map[string]string{"ROOK_CEPH_SLOW_COMMAND_THRESHOLD_SECONDS": "invalid"})
+	assert.Equal(t, 5*time.Second, exec.SlowCommandThreshold)
+
+	SetSlowCommandThreshold(map[string]string{"ROOK_CEPH_SLOW_COMMAND_THRESHOLD_SECONDS": "2"})
+	assert.Equal(t, 2*time.Second, exec.SlowCommandThreshold)
+	SetSlowCommandThreshold(map[string]string{})
+}
+
+func TestSetCephCommandsGuard(t *testing.T) {
+	SetCephCommandsGuard(map[string]string{})
+	assert.Equal(t, 20, cephclient.MaxConcurrentCommands)
+	assert.Equal(t, 3, cephclient.CommandCircuitTimeouts)
+
+	SetCephCommandsGuard(map[string]string{"ROOK_CEPH_COMMANDS_MAX_CONCURRENT": "0", "ROOK_CEPH_COMMANDS_CIRCUIT_BREAKER_TIMEOUTS": "invalid"})
+	assert.Equal(t, 20, cephclient.MaxConcurrentCommands)
+	assert.Equal(t, 3, cephclient.CommandCircuitTimeouts)
+
+	SetCephCommandsGuard(map[string]string{"ROOK_CEPH_COMMANDS_MAX_CONCURRENT": "5", "ROOK_CEPH_COMMANDS_CIRCUIT_BREAKER_TIMEOUTS": "0"})
+	assert.Equal(t, 5, cephclient.MaxConcurrentCommands)
+	assert.Equal(t, 0, cephclient.CommandCircuitTimeouts)
+	SetCephCommandsGuard(map[string]string{})
+}
+
+func TestRequeueIfCephCommandsUnavailable(t *testing.T) {
+	// other errors are returned as is
+	result, err := RequeueIfCephCommandsUnavailable(ImmediateRetryResult, errors.New("failed"))
+	assert.Error(t, err)
+	assert.Equal(t, ImmediateRetryResult, result)
+
+	result, err = RequeueIfCephCommandsUnavailable(reconcile.Result{}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, reconcile.Result{}, result)
+
+	// the request is requeued without an error when the commands fail fast
+	unavailable := &cephclient.CommandsUnavailableError{Namespace: "rook-ceph", Reason: "mons are down", RetryAfter: time.Minute}
+	result, err = RequeueIfCephCommandsUnavailable(ImmediateRetryResult, errors.Wrap(unavailable, "failed to create pool"))
+	assert.NoError(t, err)
+	assert.Equal(t, reconcile.Result{Requeue: true, RequeueAfter: time.Minute}, result)
+}
@@ -0,0 +1,248 @@
+/*
+Copyright 2021 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/pkg/errors"
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	"github.com/rook/rook/pkg/clusterd"
+	cephclient "github.com/rook/rook/pkg/daemon/ceph/client"
+	"github.com/rook/rook/pkg/operator/k8sutil"
+	v1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	defaultPoolUsageCheckInterval = 60 * time.Second
+	defaultNearFullPercent        = 85
+	defaultQuotaPercent           = 90
+
+	// PoolNearFullReason is the reason of the events of the pools whose usage crossed the near full threshold
+	PoolNearFullReason = "PoolNearFull"
+	// PoolQuotaNearFullReason is the reason of the events of the pools whose usage crossed the quota threshold
+	PoolQuotaNearFullReason = "PoolQuotaNearFull"
+)
+
+// PoolUsageChecker periodically reports the usage of the pools of a resource in its status, and emits
+// a warning event on the resource when the usage of a pool crosses a threshold
+type PoolUsageChecker struct {
+	context     *clusterd.Context
+	clusterInfo *cephclient.ClusterInfo
+	recorder    *k8sutil.EventReporter
+	object      client.Object
+	pools       []string
+	spec        cephv1.PoolUsageCheckSpec
+	// updateStatus saves the usage of the pools in the status of the resource
+	updateStatus func(usage []cephv1.PoolUsageStatus) error
+	// the usage of the last check, to only report the thresholds when they are crossed
+	previous map[string]cephv1.PoolUsageStatus
+}
+
+// PoolUsageCheckers are the running pool usage checkers of the resources of a controller
+type PoolUsageCheckers struct {
+	checkers map[string]*runningPoolUsageChecker
+}
+
+type runningPoolUsageChecker struct {
+	cancel context.CancelFunc
+	pools  []string
+	spec   cephv1.PoolUsageCheckSpec
+}
+
+type poolUsageEvent struct {
+	reason  string
+	message string
+}
+
+// NewPoolUsageChecker returns a checker of the usage of the pools of a resource
+func NewPoolUsageChecker(context *clusterd.Context, clusterInfo *cephclient.ClusterInfo, recorder *k8sutil.EventReporter, object client.Object,
+	pools []string, spec cephv1.PoolUsageCheckSpec, updateStatus func(usage []cephv1.PoolUsageStatus) error) *PoolUsageChecker {
+	return &PoolUsageChecker{
+		context:      context,
+		clusterInfo:  clusterInfo,
+		recorder:     recorder,
+		object:       object,
+		pools:        pools,
+		spec:         spec,
+		updateStatus: updateStatus,
+	}
+}
+
+// NewPoolUsageCheckers returns the pool usage checkers of the resources of a controller
+func NewPoolUsageCheckers() *PoolUsageCheckers {
+	return &PoolUsageCheckers{checkers: map[string]*runningPoolUsageChecker{}}
+}
+
+// Start runs the checker of a resource in the background until the resource is deleted. The checker is
+// restarted when the pools or the check settings changed, and stopped when the check is disabled.
+func (p *PoolUsageCheckers) Start(ctx context.Context, key string, checker *PoolUsageChecker) {
+	if checker.spec.Disabled {
+		p.Stop(key)
+		return
+	}
+	if running, ok := p.checkers[key]; ok {
+		if reflect.DeepEqual(running.pools, checker.pools) && reflect.DeepEqual(running.spec, checker.spec) {
+			return
+		}
+		p.Stop(key)
+	}
+
+	checkerCtx, cancel := context.WithCancel(ctx)
+	p.checkers[key] = &runningPoolUsageChecker{cancel: cancel, pools: checker.pools, spec: checker.spec}
+	go checker.CheckPoolUsage(checkerCtx)
+}
+
+// Stop stops the checker of a resource
+func (p *PoolUsageCheckers) Stop(key string) {
+	if running, ok := p.checkers[key]; ok {
+		running.cancel()
+		delete(p.checkers, key)
+	}
+}
+
+// CheckPoolUsage checks the usage of the pools until the context is cancelled
+func (c *PoolUsageChecker) CheckPoolUsage(ctx context.Context) {
+	interval := defaultPoolUsageCheckInterval
+	if c.spec.Interval != nil {
+		interval = c.spec.Interval.Duration
+	}
+
+	// check the usage immediately before starting the loop
+	if err := c.Check(); err != nil {
+		logger.Debugf("failed to check the pool usage of %q. %v", c.object.GetName(), err)
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			logger.Infof("stopping monitoring the pool usage of %q", c.object.GetName())
+			return
+
+		case <-time.After(interval):
+			logger.Debugf("checking the pool usage of %q", c.object.GetName())
+			if err := c.Check(); err != nil {
+				logger.Debugf("failed to check the pool usage of %q. %v", c.object.GetName(), err)
+			}
+		}
+	}
+}
+
+// Check updates the usage of the pools in the status and reports the thresholds crossed since the
+// last check
+func (c *PoolUsageChecker) Check() error {
+	stats, err := cephclient.GetPoolStats(c.context, c.clusterInfo)
+	if err != nil {
+		return errors.Wrap(err, "failed to get the pool stats")
+	}
+	usage := GetPoolUsage(stats, c.pools)
+	if err := c.updateStatus(usage); err != nil {
+		return errors.Wrap(err, "failed to update the pool usage status")
+	}
+
+	for _, event := range poolUsageEvents(c.spec, c.previous, usage) {
+		if c.recorder != nil {
+			c.recorder.ReportIfNotPresent(c.object, v1.EventTypeWarning, event.reason, event.message)
+		}
+	}
+	c.previous = map[string]cephv1.PoolUsageStatus{}
+	for _, pool := range usage {
+		c.previous[pool.Name] = pool
+	}
+	return nil
+}
+
+// GetPoolUsage returns the usage of the pools from the stats of the cluster, in the order of the pools.
+// The pools that do not exist yet are skipped.
+func GetPoolUsage(stats *cephclient.CephStoragePoolStats, pools []string) []cephv1.PoolUsageStatus {
+	lastChecked := time.Now().UTC().Format(time.RFC3339)
+	usage := []cephv1.PoolUsageStatus{}
+	for _, name := range pools {
+		for _, pool := range stats.Pools {
+			if pool.Name != name {
+				continue
+			}
+			status := cephv1.PoolUsageStatus{
+				Name:            name,
+				StoredBytes:     uint64(pool.Stats.Stored),
+				Objects:         uint64(pool.Stats.Objects),
+				PercentUsed:     roundPercent(pool.Stats.PercentUsed * 100),
+				MaxAvailBytes:   uint64(pool.Stats.MaxAvail),
+				QuotaMaxBytes:   uint64(pool.Stats.QuotaBytes),
+				QuotaMaxObjects: uint64(pool.Stats.QuotaObjects),
+				LastChecked:     lastChecked,
+			}
+			if pool.Stats.QuotaBytes > 0 {
+				status.QuotaBytesPercent = roundPercent(pool.Stats.Stored / pool.Stats.QuotaBytes * 100)
+			}
+			if pool.Stats.QuotaObjects > 0 {
+				status.QuotaObjectsPercent = roundPercent(pool.Stats.Objects / pool.Stats.QuotaObjects * 100)
+			}
+			usage = append(usage, status)
+		}
+	}
+	return usage
+}
+
+// poolUsageEvents returns the events of the pools whose usage crossed a threshold since the previous
+// check. The thresholds of all the pools are reported at the first check.
+func poolUsageEvents(spec cephv1.PoolUsageCheckSpec, previous map[string]cephv1.PoolUsageStatus, usage []cephv1.PoolUsageStatus) []poolUsageEvent {
+	nearFull := float64(defaultNearFullPercent)
+	if spec.NearFullPercent > 0 {
+		nearFull = float64(spec.NearFullPercent)
+	}
+	quota := float64(defaultQuotaPercent)
+	if spec.QuotaPercent > 0 {
+		quota = float64(spec.QuotaPercent)
+	}
+	crossed := func(previous, current, threshold float64) bool {
+		return current >= threshold && previous < threshold
+	}
+
+	events := []poolUsageEvent{}
+	for _, pool := range usage {
+		last := previous[pool.Name]
+		if crossed(last.PercentUsed, pool.PercentUsed, nearFull) {
+			events = append(events, poolUsageEvent{
+				reason:  PoolNearFullReason,
+				message: fmt.Sprintf("pool %q is %.1f%% full, %d bytes are available", pool.Name, pool.PercentUsed, pool.MaxAvailBytes),
+			})
+		}
+		if pool.QuotaMaxBytes > 0 && crossed(last.QuotaBytesPercent, pool.QuotaBytesPercent, quota) {
+			events = append(events, poolUsageEvent{
+				reason:  PoolQuotaNearFullReason,
+				message: fmt.Sprintf("pool %q uses %.1f%% of its quota of %d bytes", pool.Name, pool.QuotaBytesPercent, pool.QuotaMaxBytes),
+			})
+		}
+		if pool.QuotaMaxObjects > 0 && crossed(last.QuotaObjectsPercent, pool.QuotaObjectsPercent, quota) {
+			events = append(events, poolUsageEvent{
+				reason:  PoolQuotaNearFullReason,
+				message: fmt.Sprintf("pool %q uses %.1f%% of its quota of %d objects", pool.Name, pool.QuotaObjectsPercent, pool.QuotaMaxObjects),
+			})
+		}
+	}
+	return events
+}
+
+// roundPercent rounds a percentage to two decimals for the status
+func roundPercent(percent float64) float64 {
+	return float64(int64(percent*100+0.5)) / 100
+}
@@ -0,0 +1,139 @@
+/*
+Copyright 2021 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"testing"
+
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	"github.com/rook/rook/pkg/clusterd"
+	cephclient "github.com/rook/rook/pkg/daemon/ceph/client"
+	"github.com/rook/rook/pkg/operator/k8sutil"
+	exectest "github.com/rook/rook/pkg/util/exec/test"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+)
+
+const poolStatsOutput = `{"pools":[
+{"name":"replicapool","id":1,"stats":{"stored":900,"objects":9,"percent_used":0.8,"max_avail":1000,"quota_bytes":1000,"quota_objects":0}},
+{"name":"other","id":2,"stats":{"stored":10,"objects":1,"percent_used":0.1,"max_avail":1000,"quota_bytes":0,"quota_objects":0}}]}`
+
+func TestGetPoolUsage(t *testing.T) {
+	executor := &exectest.MockExecutor{
+		MockExecuteCommandWithOutput: func(command string, args ...string) (string, error) {
+			return poolStatsOutput, nil
+		},
+	}
+	stats, err := cephclient.GetPoolStats(&clusterd.Context{Executor: executor}, cephclient.AdminClusterInfo("mycluster"))
+	assert.NoError(t, err)
+
+	usage := GetPoolUsage(stats, []string{"replicapool", "missing"})
+	assert.Equal(t, 1, len(usage))
+	assert.Equal(t, "replicapool", usage[0].Name)
+	assert.Equal(t, uint64(900), usage[0].StoredBytes)
+	assert.Equal(t, uint64(9), usage[0].Objects)
+	assert.Equal(t, 80.0, usage[0].PercentUsed)
+	assert.Equal(t, uint64(1000), usage[0].MaxAvailBytes)
+	assert.Equal(t, uint64(1000), usage[0].QuotaMaxBytes)
+	assert.Equal(t, 90.0, usage[0].QuotaBytesPercent)
+	assert.Equal(t, 0.0, usage[0].QuotaObjectsPercent)
+	assert.NotEmpty(t, usage[0].LastChecked)
+}
+
+func TestPoolUsageEvents(t *testing.T) {
+	usage := []cephv1.PoolUsageStatus{
+		{Name: "a", PercentUsed: 86, QuotaMaxBytes: 100, QuotaBytesPercent: 50},
+		{Name: "b", PercentUsed: 10, QuotaMaxObjects: 10, QuotaObjectsPercent: 90},
+	}
+
+	// the thresholds are reported at the first check
+	events := poolUsageEvents(cephv1.PoolUsageCheckSpec{}, nil, usage)
+	assert.Equal(t, 2, len(events))
+	assert.Equal(t, PoolNearFullReason, events[0].reason)
+	assert.Contains(t, events[0].message, `pool "a" is 86.0% full`)
+	assert.Equal(t, PoolQuotaNearFullReason, events[1].reason)
+	assert.Contains(t, events[1].message, "of its quota of 10 objects")
+
+	// the thresholds are not reported again while the usage stays above them
+	previous := map[string]cephv1.PoolUsageStatus{"a": usage[0], "b": usage[1]}
+	assert.Empty(t, poolUsageEvents(cephv1.PoolUsageCheckSpec{}, previous, usage))
+
+	// configured thresholds
+	spec := cephv1.PoolUsageCheckSpec{NearFullPercent: 90, QuotaPercent: 50}
+	events = poolUsageEvents(spec, map[string]cephv1.PoolUsageStatus{"b": usage[1]}, usage)
+	assert.Equal(t, 1, len(events))
+	assert.Contains(t, events[0].message, "of its quota of 100 bytes")
+}
+
+func TestPoolUsageCheckerCheck(t *testing.T) {
+	executor := &exectest.MockExecutor{
+		MockExecuteCommandWithOutput: func(command string, args ...string) (string, error) {
+			return poolStatsOutput, nil
+		},
+	}
+	recorder := record.NewFakeRecorder(10)
+	pool := &cephv1.CephBlockPool{ObjectMeta: metav1.ObjectMeta{Name: "replicapool", Namespace: "ns"}}
+	var status []cephv1.PoolUsageStatus
+	checker := NewPoolUsageChecker(&clusterd.Context{Executor: executor}, cephclient.AdminClusterInfo("ns"), k8sutil.NewEventReporter(recorder),
+		pool, []string{"replicapool"}, cephv1.PoolUsageCheckSpec{NearFullPercent: 75},
+		func(usage []cephv1.PoolUsageStatus) error {
+			status = usage
+			return nil
+		})
+
+	assert.NoError(t, checker.Check())
+	assert.Equal(t, 1, len(status))
+	assert.Equal(t, 2, len(recorder.Events))
+
+	// the same usage is not reported again
+	assert.NoError(t, checker.Check())
+	assert.Equal(t, 2, len(recorder.Events))
+}
+
+func TestPoolUsageCheckers(t *testing.T) {
+	executor := &exectest.MockExecutor{
+		MockExecuteCommandWithOutput: func(command string, args ...string) (string, error) {
+			return poolStatsOutput, nil
+		},
+	}
+	ctx, cancel := context.WithCancel(context.TODO())
+	defer cancel()
+	pool := &cephv1.CephBlockPool{ObjectMeta: metav1.ObjectMeta{Name: "replicapool", Namespace: "ns"}}
+	newChecker := func(spec cephv1.PoolUsageCheckSpec) *PoolUsageChecker {
+		return NewPoolUsageChecker(&clusterd.Context{Executor: executor}, cephclient.AdminClusterInfo("ns"), nil,
+			pool, []string{"replicapool"}, spec, func(usage []cephv1.PoolUsageStatus) error { return nil })
+	}
+
+	checkers := NewPoolUsageCheckers()
+	checkers.Start(ctx, "ns/replicapool", newChecker(cephv1.PoolUsageCheckSpec{}))
+	running := checkers.checkers["ns/replicapool"]
+	assert.NotNil(t, running)
+
+	// the checker is not restarted when the settings did not change
+	checkers.Start(ctx, "ns/replicapool", newChecker(cephv1.PoolUsageCheckSpec{}))
+	assert.Equal(t, running, checkers.checkers["ns/replicapool"])
+
+	// the checker is restarted with the new settings
+	checkers.Start(ctx, "ns/replicapool", newChecker(cephv1.PoolUsageCheckSpec{NearFullPercent: 70}))
+	assert.NotEqual(t, running, checkers.checkers["ns/replicapool"])
+
+	// the checker is stopped when the check is disabled
+	checkers.Start(ctx, "ns/replicapool", newChecker(cephv1.PoolUsageCheckSpec{Disabled: true}))
+	assert.Empty(t, checkers.checkers)
+}
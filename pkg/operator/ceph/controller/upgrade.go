@@ -0,0 +1,69 @@
+/*
+Copyright 2021 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	"github.com/rook/rook/pkg/operator/ceph/config"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+var (
+	// ErrUpgradePaused is returned when the upgrade strategy requires the rollout of a new Ceph
+	// version to stop
+	ErrUpgradePaused = errors.New("ceph upgrade is paused")
+
+	// WaitForRequeueIfUpgradePaused waits for a paused upgrade to be resumed
+	WaitForRequeueIfUpgradePaused = reconcile.Result{Requeue: true, RequeueAfter: time.Minute}
+
+	// UpgradeDaemonOrder is the order in which the daemon types are upgraded
+	UpgradeDaemonOrder = []string{config.MonType, config.MgrType, config.OsdType, config.MdsType, config.RgwType}
+)
+
+// IsUpgradePausedBefore returns true, along with the reason, if the upgrade of the cluster is
+// paused before the given daemon type can be upgraded
+func IsUpgradePausedBefore(cephCluster *cephv1.CephCluster, daemonType string) (bool, string) {
+	status := cephCluster.Status.UpgradeStatus
+	if !status.IsInProgress() {
+		return false, ""
+	}
+
+	strategy := cephCluster.Spec.UpgradeStrategy
+	if strategy.Paused {
+		return true, "the upgrade is paused"
+	}
+
+	// the upgrade was paused by the cluster controller for a reason other than a pause point
+	if status.Phase == cephv1.UpgradePhasePaused && status.PausedAfter == "" {
+		return true, status.Message
+	}
+
+	for _, d := range UpgradeDaemonOrder {
+		if d == daemonType {
+			break
+		}
+		if strategy.PausesAfter(d) {
+			return true, fmt.Sprintf("the upgrade is paused after the %q daemons", d)
+		}
+	}
+
+	return false, ""
+}
@@ -0,0 +1,76 @@
+/*
+Copyright 2021 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"testing"
+
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsUpgradePausedBefore(t *testing.T) {
+	cephCluster := &cephv1.CephCluster{}
+
+	t.Run("no upgrade in progress", func(t *testing.T) {
+		cephCluster.Spec.UpgradeStrategy.Paused = true
+		paused, _ := IsUpgradePausedBefore(cephCluster, "mds")
+		assert.False(t, paused)
+
+		cephCluster.Status.UpgradeStatus = &cephv1.UpgradeStatus{Phase: cephv1.UpgradePhaseCompleted}
+		paused, _ = IsUpgradePausedBefore(cephCluster, "mds")
+		assert.False(t, paused)
+	})
+
+	t.Run("paused by the user", func(t *testing.T) {
+		cephCluster.Status.UpgradeStatus = &cephv1.UpgradeStatus{Phase: cephv1.UpgradePhaseProgressing}
+		paused, message := IsUpgradePausedBefore(cephCluster, "mds")
+		assert.True(t, paused)
+		assert.Equal(t, "the upgrade is paused", message)
+		cephCluster.Spec.UpgradeStrategy.Paused = false
+	})
+
+	t.Run("paused on health error", func(t *testing.T) {
+		cephCluster.Status.UpgradeStatus = &cephv1.UpgradeStatus{Phase: cephv1.UpgradePhasePaused, Message: "HEALTH_ERR"}
+		paused, message := IsUpgradePausedBefore(cephCluster, "rgw")
+		assert.True(t, paused)
+		assert.Equal(t, "HEALTH_ERR", message)
+	})
+
+	t.Run("pause points", func(t *testing.T) {
+		cephCluster.Status.UpgradeStatus = &cephv1.UpgradeStatus{Phase: cephv1.UpgradePhaseProgressing}
+		cephCluster.Spec.UpgradeStrategy.PauseAfter = []cephv1.UpgradeDaemonType{"osd", "mds"}
+
+		paused, _ := IsUpgradePausedBefore(cephCluster, "mgr")
+		assert.False(t, paused)
+		paused, _ = IsUpgradePausedBefore(cephCluster, "osd")
+		assert.False(t, paused)
+		paused, message := IsUpgradePausedBefore(cephCluster, "mds")
+		assert.True(t, paused)
+		assert.Contains(t, message, `"osd"`)
+		paused, message = IsUpgradePausedBefore(cephCluster, "rgw")
+		assert.True(t, paused)
+		assert.Contains(t, message, `"osd"`)
+
+		cephCluster.Spec.UpgradeStrategy.PauseAfter = []cephv1.UpgradeDaemonType{"mds"}
+		paused, _ = IsUpgradePausedBefore(cephCluster, "mds")
+		assert.False(t, paused)
+		paused, message = IsUpgradePausedBefore(cephCluster, "rgw")
+		assert.True(t, paused)
+		assert.Contains(t, message, `"mds"`)
+	})
+}
@@ -33,7 +33,10 @@ import (
 	"github.com/rook/rook/pkg/operator/ceph/disruption/machinelabel"
 	"github.com/rook/rook/pkg/operator/ceph/file"
 	"github.com/rook/rook/pkg/operator/ceph/file/mirror"
+	"github.com/rook/rook/pkg/operator/ceph/file/subvolumegroup"
+	"github.com/rook/rook/pkg/operator/ceph/networkfence"
 	"github.com/rook/rook/pkg/operator/ceph/nfs"
+	"github.com/rook/rook/pkg/operator/ceph/nfs/export"
 	"github.com/rook/rook/pkg/operator/ceph/object"
 	"github.com/rook/rook/pkg/operator/ceph/object/bucket"
 	"github.com/rook/rook/pkg/operator/ceph/object/realm"
@@ -41,6 +44,9 @@ import (
 	"github.com/rook/rook/pkg/operator/ceph/object/zone"
 	"github.com/rook/rook/pkg/operator/ceph/object/zonegroup"
 	"github.com/rook/rook/pkg/operator/ceph/pool"
+	"github.com/rook/rook/pkg/operator/ceph/pool/crushrule"
+	"github.com/rook/rook/pkg/operator/ceph/pool/failover"
+	"github.com/rook/rook/pkg/operator/ceph/pool/radosnamespace"
 	"k8s.io/apimachinery/pkg/runtime"
 
 	mapiv1 "github.com/openshift/cluster-api/pkg/apis/machine/v1beta1"
@@ -89,13 +95,19 @@ var MachineDisruptionBudgetAddToManagerFuncs = []func(manager.Manager, *controll
 var AddToManagerFuncs = []func(manager.Manager, *clusterd.Context, context.Context, opcontroller.OperatorConfig) error{
 	crash.Add,
 	pool.Add,
+	radosnamespace.Add,
+	failover.Add,
+	crushrule.Add,
 	objectuser.Add,
 	realm.Add,
 	zonegroup.Add,
 	zone.Add,
 	object.Add,
 	file.Add,
+	subvolumegroup.Add,
 	nfs.Add,
+	export.Add,
+	networkfence.Add,
 	rbd.Add,
 	client.Add,
 	mirror.Add,
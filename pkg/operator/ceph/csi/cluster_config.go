@@ -30,6 +30,7 @@ import (
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/util/retry"
 )
 
 var (
@@ -39,6 +40,15 @@ var (
 type csiClusterConfigEntry struct {
 	ClusterID string   `json:"clusterID"`
 	Monitors  []string `json:"monitors"`
+	// Namespace is the namespace of the CephCluster when the clusterID is not the namespace, so
+	// the monitors of the entry are updated with the monitors of the cluster
+	Namespace      string          `json:"namespace,omitempty"`
+	RadosNamespace string          `json:"radosNamespace,omitempty"`
+	CephFS         *csiCephFSEntry `json:"cephFS,omitempty"`
+}
+
+type csiCephFSEntry struct {
+	SubvolumeGroup string `json:"subvolumeGroup,omitempty"`
 }
 
 type csiClusterConfig []csiClusterConfigEntry
@@ -107,7 +117,9 @@ func updateCsiClusterConfig(
 			centry.Monitors = monEndpoints(mons)
 			found = true
 			cc[i] = centry
-			break
+		} else if centry.Namespace == clusterKey {
+			// the entries of the rados namespaces and subvolume groups of the cluster use the same monitors
+			cc[i].Monitors = monEndpoints(mons)
 		}
 	}
 	if !found {
@@ -154,7 +166,9 @@ func CreateCsiConfigMap(namespace string, clientset kubernetes.Interface, ownerI
 // and the clusterNamespace value is expected to match the clusterID
 // value that is provided to ceph-csi uses in the storage class.
 // The locker l is typically a mutex and is used to prevent the config
-// map from being updated for multiple clusters simultaneously.
+// map from being updated for multiple clusters simultaneously. The update
+// is retried if the config map is updated concurrently by the controllers
+// of the rados namespaces and subvolume groups.
 func SaveClusterConfig(
 	clientset kubernetes.Interface, clusterNamespace string,
 	clusterInfo *cephclient.ClusterInfo, l sync.Locker) error {
@@ -163,36 +177,164 @@ func SaveClusterConfig(
 	}
 	l.Lock()
 	defer l.Unlock()
+	return modifyCsiClusterConfig(clientset, clusterInfo.Context, func(curr string) (string, error) {
+		return updateCsiClusterConfig(curr, clusterNamespace, clusterInfo.Monitors)
+	})
+}
+
+// updateCsiRadosNamespaceConfig returns the csi cluster config with the entry of a rados
+// namespace added or updated
+func updateCsiRadosNamespaceConfig(curr, clusterID, clusterNamespace, radosNamespace string, mons map[string]*cephclient.MonInfo) (string, error) {
+	return updateCsiClusterConfigEntry(curr, csiClusterConfigEntry{
+		ClusterID:      clusterID,
+		Monitors:       monEndpoints(mons),
+		Namespace:      clusterNamespace,
+		RadosNamespace: radosNamespace,
+	})
+}
+
+// updateCsiSubvolumeGroupConfig returns the csi cluster config with the entry of a cephfs
+// subvolume group added or updated
+func updateCsiSubvolumeGroupConfig(curr, clusterID, clusterNamespace, subvolumeGroup string, mons map[string]*cephclient.MonInfo) (string, error) {
+	return updateCsiClusterConfigEntry(curr, csiClusterConfigEntry{
+		ClusterID: clusterID,
+		Monitors:  monEndpoints(mons),
+		Namespace: clusterNamespace,
+		CephFS:    &csiCephFSEntry{SubvolumeGroup: subvolumeGroup},
+	})
+}
+
+// updateCsiClusterConfigEntry returns the csi cluster config with the entry added, or replacing
+// the entry with the same clusterID
+func updateCsiClusterConfigEntry(curr string, entry csiClusterConfigEntry) (string, error) {
+	cc, err := parseCsiClusterConfig(curr)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to parse current csi cluster config")
+	}
+
+	found := false
+	for i := range cc {
+		if cc[i].ClusterID == entry.ClusterID {
+			cc[i] = entry
+			found = true
+			break
+		}
+	}
+	if !found {
+		cc = append(cc, entry)
+	}
+	return formatCsiClusterConfig(cc)
+}
+
+// removeCsiClusterConfigEntry returns the csi cluster config without the entry of the clusterID
+func removeCsiClusterConfigEntry(curr, clusterID string) (string, error) {
+	cc, err := parseCsiClusterConfig(curr)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to parse current csi cluster config")
+	}
+
+	updated := csiClusterConfig{}
+	for _, centry := range cc {
+		if centry.ClusterID != clusterID {
+			updated = append(updated, centry)
+		}
+	}
+	return formatCsiClusterConfig(updated)
+}
+
+// SaveRadosNamespaceConfig adds or updates the entry of a rados namespace in the config map used
+// to provide ceph-csi with the cluster configuration. The clusterID is the value to set in the
+// storage classes of the rados namespace.
+func SaveRadosNamespaceConfig(clientset kubernetes.Interface, clusterInfo *cephclient.ClusterInfo, clusterID, radosNamespace string) error {
+	return modifyCsiClusterConfig(clientset, clusterInfo.Context, func(curr string) (string, error) {
+		return updateCsiRadosNamespaceConfig(curr, clusterID, clusterInfo.Namespace, radosNamespace, clusterInfo.Monitors)
+	})
+}
+
+// SaveSubvolumeGroupConfig adds or updates the entry of a cephfs subvolume group in the config map
+// used to provide ceph-csi with the cluster configuration. The clusterID is the value to set in the
+// storage classes of the subvolume group.
+func SaveSubvolumeGroupConfig(clientset kubernetes.Interface, clusterInfo *cephclient.ClusterInfo, clusterID, subvolumeGroup string) error {
+	return modifyCsiClusterConfig(clientset, clusterInfo.Context, func(curr string) (string, error) {
+		return updateCsiSubvolumeGroupConfig(curr, clusterID, clusterInfo.Namespace, subvolumeGroup, clusterInfo.Monitors)
+	})
+}
+
+// DeleteClusterConfigEntry removes the entry of the clusterID from the config map used to provide
+// ceph-csi with the cluster configuration
+func DeleteClusterConfigEntry(clientset kubernetes.Interface, ctx context.Context, clusterID string) error {
+	return modifyCsiClusterConfig(clientset, ctx, func(curr string) (string, error) {
+		return removeCsiClusterConfigEntry(curr, clusterID)
+	})
+}
+
+// ClusterIDs returns the clusterIDs of the csi storage classes of the cluster in the namespace: the
+// namespace and the clusterIDs of its rados namespaces and subvolume groups
+func ClusterIDs(clientset kubernetes.Interface, ctx context.Context, clusterNamespace string) ([]string, error) {
+	clusterIDs := []string{clusterNamespace}
+	if !CSIEnabled() {
+		return clusterIDs, nil
+	}
 	// csi is deployed into the same namespace as the operator
 	csiNamespace := os.Getenv(k8sutil.PodNamespaceEnvVar)
 	if csiNamespace == "" {
-		return errors.Errorf("namespace value missing for %s", k8sutil.PodNamespaceEnvVar)
+		return nil, errors.Errorf("namespace value missing for %s", k8sutil.PodNamespaceEnvVar)
 	}
-	logger.Debugf("Using %+v for CSI ConfigMap Namespace", csiNamespace)
 
-	// fetch current ConfigMap contents
-	configMap, err := clientset.CoreV1().ConfigMaps(csiNamespace).Get(clusterInfo.Context,
-		ConfigName, metav1.GetOptions{})
+	configMap, err := clientset.CoreV1().ConfigMaps(csiNamespace).Get(ctx, ConfigName, metav1.GetOptions{})
 	if err != nil {
-		return errors.Wrap(err, "failed to fetch current csi config map")
+		if k8serrors.IsNotFound(err) {
+			return clusterIDs, nil
+		}
+		return nil, errors.Wrap(err, "failed to fetch current csi config map")
 	}
-
-	// update ConfigMap contents for current cluster
-	currData := configMap.Data[ConfigKey]
-	if currData == "" {
-		currData = "[]"
+	if configMap.Data[ConfigKey] == "" {
+		return clusterIDs, nil
 	}
-	newData, err := updateCsiClusterConfig(
-		currData, clusterNamespace, clusterInfo.Monitors)
+	cc, err := parseCsiClusterConfig(configMap.Data[ConfigKey])
 	if err != nil {
-		return errors.Wrap(err, "failed to update csi config map data")
+		return nil, err
+	}
+	for _, entry := range cc {
+		if entry.Namespace == clusterNamespace {
+			clusterIDs = append(clusterIDs, entry.ClusterID)
+		}
 	}
-	configMap.Data[ConfigKey] = newData
+	return clusterIDs, nil
+}
 
-	// update ConfigMap with new contents
-	if _, err := clientset.CoreV1().ConfigMaps(csiNamespace).Update(clusterInfo.Context, configMap, metav1.UpdateOptions{}); err != nil {
-		return errors.Wrapf(err, "failed to update csi config map")
+// modifyCsiClusterConfig updates the csi config map with the given function, retrying if the config
+// map is updated concurrently for another cluster
+func modifyCsiClusterConfig(clientset kubernetes.Interface, ctx context.Context, modify func(string) (string, error)) error {
+	if !CSIEnabled() {
+		return nil
+	}
+	// csi is deployed into the same namespace as the operator
+	csiNamespace := os.Getenv(k8sutil.PodNamespaceEnvVar)
+	if csiNamespace == "" {
+		return errors.Errorf("namespace value missing for %s", k8sutil.PodNamespaceEnvVar)
 	}
 
-	return nil
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		configMap, err := clientset.CoreV1().ConfigMaps(csiNamespace).Get(ctx, ConfigName, metav1.GetOptions{})
+		if err != nil {
+			return errors.Wrap(err, "failed to fetch current csi config map")
+		}
+
+		currData := configMap.Data[ConfigKey]
+		if currData == "" {
+			currData = "[]"
+		}
+		newData, err := modify(currData)
+		if err != nil {
+			return errors.Wrap(err, "failed to update csi config map data")
+		}
+		if configMap.Data == nil {
+			configMap.Data = map[string]string{}
+		}
+		configMap.Data[ConfigKey] = newData
+
+		_, err = clientset.CoreV1().ConfigMaps(csiNamespace).Update(ctx, configMap, metav1.UpdateOptions{})
+		return err
+	})
 }
@@ -17,10 +17,21 @@ limitations under the License.
 package csi
 
 import (
+	"context"
+	"os"
+	"sync"
 	"testing"
 
+	"github.com/pkg/errors"
 	cephclient "github.com/rook/rook/pkg/daemon/ceph/client"
+	"github.com/rook/rook/pkg/operator/k8sutil"
+	"github.com/rook/rook/pkg/operator/test"
 	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8stesting "k8s.io/client-go/testing"
 )
 
 func TestUpdateCsiClusterConfig(t *testing.T) {
@@ -87,3 +98,134 @@ func TestUpdateCsiClusterConfig(t *testing.T) {
 	_, err = updateCsiClusterConfig("qqq", "beta", mons2)
 	assert.Error(t, err)
 }
+
+func TestUpdateCsiRadosNamespaceConfig(t *testing.T) {
+	mons := map[string]*cephclient.MonInfo{
+		"foo": {Name: "foo", Endpoint: "1.2.3.4:5000"},
+	}
+	s, err := updateCsiClusterConfig("[]", "rook-ceph", mons)
+	assert.NoError(t, err)
+
+	// add the entry of a rados namespace
+	s, err = updateCsiRadosNamespaceConfig(s, "abcd", "rook-ceph", "tenant-a", mons)
+	assert.NoError(t, err)
+	assert.Equal(t,
+		`[{"clusterID":"rook-ceph","monitors":["1.2.3.4:5000"]},{"clusterID":"abcd","monitors":["1.2.3.4:5000"],"namespace":"rook-ceph","radosNamespace":"tenant-a"}]`, s)
+
+	// the mons of the cluster are propagated to its rados namespaces
+	mons["bar"] = &cephclient.MonInfo{Name: "bar", Endpoint: "10.11.12.13:5000"}
+	s, err = updateCsiClusterConfig(s, "rook-ceph", mons)
+	assert.NoError(t, err)
+	cc, err := parseCsiClusterConfig(s)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(cc))
+	assert.ElementsMatch(t, []string{"1.2.3.4:5000", "10.11.12.13:5000"}, cc[1].Monitors)
+	assert.Equal(t, "tenant-a", cc[1].RadosNamespace)
+
+	// updating the entry does not duplicate it
+	s, err = updateCsiRadosNamespaceConfig(s, "abcd", "rook-ceph", "tenant-a", mons)
+	assert.NoError(t, err)
+	cc, err = parseCsiClusterConfig(s)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(cc))
+
+	// remove the entry of the rados namespace
+	s, err = removeCsiClusterConfigEntry(s, "abcd")
+	assert.NoError(t, err)
+	cc, err = parseCsiClusterConfig(s)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(cc))
+	assert.Equal(t, "rook-ceph", cc[0].ClusterID)
+}
+
+func TestUpdateCsiSubvolumeGroupConfig(t *testing.T) {
+	mons := map[string]*cephclient.MonInfo{
+		"foo": {Name: "foo", Endpoint: "1.2.3.4:5000"},
+	}
+	s, err := updateCsiSubvolumeGroupConfig("[]", "abcd", "rook-ceph", "group-a", mons)
+	assert.NoError(t, err)
+	assert.Equal(t,
+		`[{"clusterID":"abcd","monitors":["1.2.3.4:5000"],"namespace":"rook-ceph","cephFS":{"subvolumeGroup":"group-a"}}]`, s)
+
+	// the mons of the cluster are propagated to its subvolume groups
+	mons["bar"] = &cephclient.MonInfo{Name: "bar", Endpoint: "10.11.12.13:5000"}
+	s, err = updateCsiClusterConfig(s, "rook-ceph", mons)
+	assert.NoError(t, err)
+	cc, err := parseCsiClusterConfig(s)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(cc))
+	assert.ElementsMatch(t, []string{"1.2.3.4:5000", "10.11.12.13:5000"}, cc[0].Monitors)
+	assert.Equal(t, "group-a", cc[0].CephFS.SubvolumeGroup)
+}
+
+func TestClusterIDs(t *testing.T) {
+	ctx := context.TODO()
+	clientset := test.New(t, 1)
+	os.Setenv(k8sutil.PodNamespaceEnvVar, "rook-ceph-operator")
+	defer os.Unsetenv(k8sutil.PodNamespaceEnvVar)
+	enableRBD := EnableRBD
+	EnableRBD = true
+	defer func() { EnableRBD = enableRBD }()
+
+	// the config map is not created yet
+	clusterIDs, err := ClusterIDs(clientset, ctx, "rook-ceph")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"rook-ceph"}, clusterIDs)
+
+	cm := &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: ConfigName, Namespace: "rook-ceph-operator"},
+		Data: map[string]string{
+			ConfigKey: `[{"clusterID":"rook-ceph","monitors":[]},{"clusterID":"abcd","monitors":[],"namespace":"rook-ceph","radosNamespace":"ns"},{"clusterID":"efgh","monitors":[],"namespace":"other"}]`,
+		},
+	}
+	_, err = clientset.CoreV1().ConfigMaps("rook-ceph-operator").Create(ctx, cm, metav1.CreateOptions{})
+	assert.NoError(t, err)
+	clusterIDs, err = ClusterIDs(clientset, ctx, "rook-ceph")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"rook-ceph", "abcd"}, clusterIDs)
+}
+
+func TestSaveClusterConfig(t *testing.T) {
+	ctx := context.TODO()
+	clientset := test.New(t, 1)
+	os.Setenv(k8sutil.PodNamespaceEnvVar, "rook-ceph-operator")
+	defer os.Unsetenv(k8sutil.PodNamespaceEnvVar)
+	enableRBD := EnableRBD
+	EnableRBD = true
+	defer func() { EnableRBD = enableRBD }()
+
+	cm := &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: ConfigName, Namespace: "rook-ceph-operator"},
+		Data: map[string]string{
+			ConfigKey: `[{"clusterID":"abcd","monitors":["1.2.3.4:6789"],"namespace":"rook-ceph","radosNamespace":"ns"}]`,
+		},
+	}
+	_, err := clientset.CoreV1().ConfigMaps("rook-ceph-operator").Create(ctx, cm, metav1.CreateOptions{})
+	assert.NoError(t, err)
+
+	// the config map is updated concurrently by the controller of a rados namespace
+	conflicts := 0
+	clientset.PrependReactor("update", "configmaps", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		if conflicts > 0 {
+			return false, nil, nil
+		}
+		conflicts++
+		return true, nil, kerrors.NewConflict(v1.Resource("configmaps"), ConfigName, errors.New("modified"))
+	})
+
+	clusterInfo := cephclient.AdminClusterInfo("rook-ceph")
+	clusterInfo.Monitors = map[string]*cephclient.MonInfo{"a": {Name: "a", Endpoint: "5.6.7.8:6789"}}
+	err = SaveClusterConfig(clientset, "rook-ceph", clusterInfo, &sync.Mutex{})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, conflicts)
+
+	cm, err = clientset.CoreV1().ConfigMaps("rook-ceph-operator").Get(ctx, ConfigName, metav1.GetOptions{})
+	assert.NoError(t, err)
+	cc, err := parseCsiClusterConfig(cm.Data[ConfigKey])
+	assert.NoError(t, err)
+	assert.Len(t, cc, 2)
+	assert.Equal(t, "abcd", cc[0].ClusterID)
+	assert.Equal(t, []string{"5.6.7.8:6789"}, cc[0].Monitors)
+	assert.Equal(t, "rook-ceph", cc[1].ClusterID)
+	assert.Equal(t, []string{"5.6.7.8:6789"}, cc[1].Monitors)
+}
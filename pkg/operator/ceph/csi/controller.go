@@ -36,6 +36,7 @@ import (
 	v1 "k8s.io/api/core/v1"
 	kerrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 )
 
 const (
@@ -135,9 +136,10 @@ func (r *ReconcileCSI) reconcile(request reconcile.Request) (reconcile.Result, e
 		}
 	}
 
-	// Fetch the operator's configmap
+	// Fetch the operator's configmap, the reconcile may be triggered by a CephCluster
 	opConfig := &v1.ConfigMap{}
-	err = r.client.Get(r.opManagerContext, request.NamespacedName, opConfig)
+	opConfigName := types.NamespacedName{Name: opcontroller.OperatorSettingConfigMapName, Namespace: r.opConfig.OperatorNamespace}
+	err = r.client.Get(r.opManagerContext, opConfigName, opConfig)
 	if err != nil {
 		if kerrors.IsNotFound(err) {
 			logger.Debug("operator's configmap resource not found. will use default value or env var.")
@@ -178,7 +180,12 @@ func (r *ReconcileCSI) reconcile(request reconcile.Request) (reconcile.Result, e
 		return opcontroller.ImmediateRetryResult, errors.Wrap(err, "failed to create pool ID mapping config map")
 	}
 
-	err = r.validateAndConfigureDrivers(serverVersion, ownerInfo)
+	err = r.reconcileEncryptionKMS(cephClusters.Items, ownerInfo)
+	if err != nil {
+		return opcontroller.ImmediateRetryResult, errors.Wrap(err, "failed to configure csi encryption kms")
+	}
+
+	err = r.validateAndConfigureDrivers(serverVersion, ownerInfo, cephClusters.Items)
 	if err != nil {
 		return opcontroller.ImmediateRetryResult, errors.Wrap(err, "failed configure ceph csi")
 	}
@@ -155,4 +155,67 @@ func TestCephCSIController(t *testing.T) {
 		assert.NoError(t, err)
 		assert.Equal(t, 2, len(ds.Items), ds)
 	})
+
+	t.Run("dedicated csi driver instance", func(t *testing.T) {
+		fakeClientSet := test.New(t, 1)
+		test.SetFakeKubernetesVersion(fakeClientSet, "v1.21.0")
+		c := &clusterd.Context{
+			Clientset:     fakeClientSet,
+			RookClientset: rookclient.NewSimpleClientset(),
+		}
+		_, err := c.Clientset.CoreV1().Pods(namespace).Create(ctx, test.FakeOperatorPod(namespace), metav1.CreateOptions{})
+		assert.NoError(t, err)
+		_, err = c.Clientset.AppsV1().ReplicaSets(namespace).Create(context.TODO(), test.FakeReplicaSet(namespace), metav1.CreateOptions{})
+		assert.NoError(t, err)
+		cephCluster := &cephv1.CephCluster{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "other",
+				Namespace: "other",
+			},
+			Spec: cephv1.ClusterSpec{
+				CSI: cephv1.CSIDriverSpec{DriverNamePrefix: "other.", MetricsPortOffset: 100},
+			},
+		}
+		s := runtime.NewScheme()
+		assert.NoError(t, v1.AddToScheme(s))
+		assert.NoError(t, cephv1.AddToScheme(s))
+
+		cl := fake.NewClientBuilder().WithScheme(s).WithRuntimeObjects(cephCluster).Build()
+		c.Client = cl
+		r := &ReconcileCSI{
+			client:           cl,
+			context:          c,
+			opManagerContext: ctx,
+			opConfig: controller.OperatorConfig{
+				OperatorNamespace: namespace,
+				Image:             "rook",
+				ServiceAccount:    "foo",
+			},
+		}
+
+		res, err := r.Reconcile(ctx, req)
+		assert.NoError(t, err)
+		assert.False(t, res.Requeue)
+
+		ds, err := c.Clientset.AppsV1().DaemonSets(namespace).List(ctx, metav1.ListOptions{})
+		assert.NoError(t, err)
+		assert.Equal(t, 4, len(ds.Items), ds)
+		rbdPlugin, err := c.Clientset.AppsV1().DaemonSets(namespace).Get(ctx, "csi-rbdplugin-other", metav1.GetOptions{})
+		assert.NoError(t, err)
+		assert.Equal(t, "other.rbd.csi.ceph.com", rbdPlugin.Annotations[csiDriverNameAnnotation])
+		_, err = c.Clientset.AppsV1().Deployments(namespace).Get(ctx, "csi-cephfsplugin-provisioner-other", metav1.GetOptions{})
+		assert.NoError(t, err)
+
+		// the instance is removed with the driver name prefix
+		cephCluster.Spec.CSI.DriverNamePrefix = ""
+		err = cl.Update(ctx, cephCluster)
+		assert.NoError(t, err)
+		res, err = r.Reconcile(ctx, req)
+		assert.NoError(t, err)
+		assert.False(t, res.Requeue)
+
+		ds, err = c.Clientset.AppsV1().DaemonSets(namespace).List(ctx, metav1.ListOptions{})
+		assert.NoError(t, err)
+		assert.Equal(t, 2, len(ds.Items), ds)
+	})
 }
@@ -20,11 +20,12 @@ import (
 	"strconv"
 
 	"github.com/pkg/errors"
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
 	"github.com/rook/rook/pkg/operator/k8sutil"
 	"k8s.io/apimachinery/pkg/version"
 )
 
-func (r *ReconcileCSI) validateAndConfigureDrivers(serverVersion *version.Info, ownerInfo *k8sutil.OwnerInfo) error {
+func (r *ReconcileCSI) validateAndConfigureDrivers(serverVersion *version.Info, ownerInfo *k8sutil.OwnerInfo, cephClusters []cephv1.CephCluster) error {
 	var (
 		v   *CephCSIVersion
 		err error
@@ -49,7 +50,7 @@ func (r *ReconcileCSI) validateAndConfigureDrivers(serverVersion *version.Info,
 	if CSIEnabled() {
 		maxRetries := 3
 		for i := 0; i < maxRetries; i++ {
-			if err = r.startDrivers(serverVersion, ownerInfo, v); err != nil {
+			if err = r.startDrivers(serverVersion, ownerInfo, v, cephClusters); err != nil {
 				logger.Errorf("failed to start Ceph csi drivers, will retry starting csi drivers %d more times. %v", maxRetries-i-1, err)
 			} else {
 				break
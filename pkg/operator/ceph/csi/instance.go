@@ -0,0 +1,186 @@
+/*
+Copyright 2021 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package csi
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	"github.com/rook/rook/pkg/operator/k8sutil"
+	apps "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/version"
+)
+
+const (
+	// the label of the resources of the dedicated driver instance of a cluster, set to the
+	// namespace of the cluster
+	csiClusterLabel = "ceph.rook.io/csi-cluster"
+
+	// the annotation with the driver name of the plugin daemonset of a dedicated driver instance
+	csiDriverNameAnnotation = "ceph.rook.io/csi-driver-name"
+)
+
+// driverInstanceName returns the name of a resource of a driver instance
+func driverInstanceName(name, instance string) string {
+	if instance == "" {
+		return name
+	}
+	return fmt.Sprintf("%s-%s", name, instance)
+}
+
+// applyDriverInstance names the resources of a driver after the dedicated instance of a cluster so
+// that they run next to the resources of the shared instance
+func applyDriverInstance(instance, driverName string, plugin *apps.DaemonSet, provisioner *apps.Deployment, service *corev1.Service) {
+	if instance == "" {
+		return
+	}
+
+	plugin.Name = driverInstanceName(plugin.Name, instance)
+	plugin.Spec.Selector.MatchLabels["app"] = plugin.Name
+	plugin.Spec.Template.Labels["app"] = plugin.Name
+	plugin.Spec.Template.Labels["contains"] = driverInstanceName(plugin.Spec.Template.Labels["contains"], instance)
+	setDriverInstanceLabel(&plugin.ObjectMeta, instance)
+	if plugin.Annotations == nil {
+		plugin.Annotations = map[string]string{}
+	}
+	plugin.Annotations[csiDriverNameAnnotation] = driverName
+
+	provisioner.Name = driverInstanceName(provisioner.Name, instance)
+	provisioner.Spec.Selector.MatchLabels["app"] = provisioner.Name
+	provisioner.Spec.Template.Labels["app"] = provisioner.Name
+	provisioner.Spec.Template.Labels["contains"] = driverInstanceName(provisioner.Spec.Template.Labels["contains"], instance)
+	setDriverInstanceLabel(&provisioner.ObjectMeta, instance)
+
+	service.Name = driverInstanceName(service.Name, instance)
+	service.Spec.Selector["contains"] = driverInstanceName(service.Spec.Selector["contains"], instance)
+	setDriverInstanceLabel(&service.ObjectMeta, instance)
+}
+
+func setDriverInstanceLabel(objectMeta *metav1.ObjectMeta, instance string) {
+	if objectMeta.Labels == nil {
+		objectMeta.Labels = map[string]string{}
+	}
+	objectMeta.Labels[csiClusterLabel] = instance
+}
+
+// clusterDriverTemplateParam returns the template parameters of the dedicated driver instance of a
+// cluster from the parameters of the shared instance
+func clusterDriverTemplateParam(tp templateParam, spec cephv1.CSIDriverSpec) templateParam {
+	clusterTP := tp
+	clusterTP.DriverNamePrefix = spec.DriverNamePrefix
+	if spec.ForceCephFSKernelClient != nil {
+		clusterTP.ForceCephFSKernelClient = strconv.FormatBool(*spec.ForceCephFSKernelClient)
+	}
+	if spec.EnableHostNetwork != nil {
+		clusterTP.EnableCSIHostNetwork = *spec.EnableHostNetwork
+	}
+	if spec.ProvisionerReplicas != nil {
+		clusterTP.ProvisionerReplicas = *spec.ProvisionerReplicas
+	}
+	offset := uint16(spec.MetricsPortOffset)
+	clusterTP.CephFSGRPCMetricsPort += offset
+	clusterTP.CephFSLivenessMetricsPort += offset
+	clusterTP.RBDGRPCMetricsPort += offset
+	clusterTP.RBDLivenessMetricsPort += offset
+
+	return clusterTP
+}
+
+// hostPorts returns the metrics ports of a driver instance bound on the hosts
+func hostPorts(tp templateParam) []uint16 {
+	if !tp.EnableCSIHostNetwork {
+		return []uint16{}
+	}
+	return []uint16{tp.CephFSGRPCMetricsPort, tp.CephFSLivenessMetricsPort, tp.RBDGRPCMetricsPort, tp.RBDLivenessMetricsPort}
+}
+
+// startClusterDriverInstances starts the dedicated driver instances of the clusters with a driver
+// name prefix, and removes the instances of the clusters without one
+func (r *ReconcileCSI) startClusterDriverInstances(ver *version.Info, tp templateParam, ownerInfo *k8sutil.OwnerInfo, cephClusters []cephv1.CephCluster) error {
+	instances := map[string]bool{}
+	driverNamePrefixes := map[string]string{tp.DriverNamePrefix: "the shared csi driver instance"}
+	ports := map[uint16]string{}
+	for _, port := range hostPorts(tp) {
+		ports[port] = "the shared csi driver instance"
+	}
+
+	for _, cluster := range cephClusters {
+		spec := cluster.Spec.CSI
+		if spec.DriverNamePrefix == "" {
+			if spec.ForceCephFSKernelClient != nil || spec.EnableHostNetwork != nil || spec.ProvisionerReplicas != nil || spec.MetricsPortOffset != 0 {
+				logger.Warningf("ignoring the csi settings of cephcluster %q in namespace %q. the settings only apply to dedicated driver instances with a driverNamePrefix", cluster.Name, cluster.Namespace)
+			}
+			continue
+		}
+
+		owner := fmt.Sprintf("the csi driver instance of cephcluster %q in namespace %q", cluster.Name, cluster.Namespace)
+		if other, ok := driverNamePrefixes[spec.DriverNamePrefix]; ok {
+			return errors.Errorf("failed to start %s. driver name prefix %q is already used by %s", owner, spec.DriverNamePrefix, other)
+		}
+		driverNamePrefixes[spec.DriverNamePrefix] = owner
+
+		clusterTP := clusterDriverTemplateParam(tp, spec)
+		for _, port := range hostPorts(clusterTP) {
+			if other, ok := ports[port]; ok {
+				return errors.Errorf("failed to start %s. host port %d is already used by %s, set a different metricsPortOffset", owner, port, other)
+			}
+			ports[port] = owner
+		}
+
+		logger.Infof("starting %s with driver name prefix %q", owner, spec.DriverNamePrefix)
+		if err := r.startDriverInstance(clusterTP, ownerInfo, cluster.Namespace); err != nil {
+			return errors.Wrapf(err, "failed to start %s", owner)
+		}
+		instances[cluster.Namespace] = true
+	}
+
+	r.stopClusterDriverInstances(ver, instances)
+
+	return nil
+}
+
+// stopClusterDriverInstances removes the dedicated driver instances that are not in the running
+// instances, or whose driver is disabled
+func (r *ReconcileCSI) stopClusterDriverInstances(ver *version.Info, instances map[string]bool) {
+	daemonsets, err := r.context.Clientset.AppsV1().DaemonSets(r.opConfig.OperatorNamespace).List(r.opManagerContext, metav1.ListOptions{LabelSelector: csiClusterLabel})
+	if err != nil {
+		logger.Errorf("failed to list the csi driver instances of the clusters. %v", err)
+		return
+	}
+
+	for _, ds := range daemonsets.Items {
+		instance := ds.Labels[csiClusterLabel]
+		plugin := strings.TrimSuffix(ds.Name, "-"+instance)
+		enabled := (plugin == csiRBDPlugin && EnableRBD) || (plugin == csiCephFSPlugin && EnableCephFS)
+		if instances[instance] && enabled {
+			continue
+		}
+
+		// the provisioner and metrics service of an instance are named after its plugin
+		provisioner := driverInstanceName(plugin+"-provisioner", instance)
+		service := driverInstanceName(plugin+"-metrics", instance)
+		logger.Infof("removing the csi driver instance %q of the cluster in namespace %q", ds.Name, instance)
+		if !r.deleteCSIDriverResources(ver, ds.Name, provisioner, service, ds.Annotations[csiDriverNameAnnotation]) {
+			logger.Errorf("failed to remove the csi driver instance %q", ds.Name)
+		}
+	}
+}
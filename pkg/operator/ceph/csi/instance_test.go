@@ -0,0 +1,110 @@
+/*
+Copyright 2021 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package csi
+
+import (
+	"testing"
+
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	"github.com/stretchr/testify/assert"
+	apps "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestClusterDriverTemplateParam(t *testing.T) {
+	tp := templateParam{
+		Param: Param{
+			EnableCSIHostNetwork:      true,
+			ForceCephFSKernelClient:   "true",
+			ProvisionerReplicas:       2,
+			CephFSGRPCMetricsPort:     DefaultCephFSGRPCMerticsPort,
+			CephFSLivenessMetricsPort: DefaultCephFSLivenessMerticsPort,
+			RBDGRPCMetricsPort:        DefaultRBDGRPCMerticsPort,
+			RBDLivenessMetricsPort:    DefaultRBDLivenessMerticsPort,
+		},
+	}
+	tp.DriverNamePrefix = "rook-ceph."
+
+	// the settings of the shared instance are kept when not overridden
+	clusterTP := clusterDriverTemplateParam(tp, cephv1.CSIDriverSpec{DriverNamePrefix: "fast."})
+	assert.Equal(t, "fast.", clusterTP.DriverNamePrefix)
+	assert.Equal(t, "true", clusterTP.ForceCephFSKernelClient)
+	assert.True(t, clusterTP.EnableCSIHostNetwork)
+	assert.Equal(t, int32(2), clusterTP.ProvisionerReplicas)
+	assert.Equal(t, tp.RBDGRPCMetricsPort, clusterTP.RBDGRPCMetricsPort)
+
+	fuse := false
+	replicas := int32(1)
+	clusterTP = clusterDriverTemplateParam(tp, cephv1.CSIDriverSpec{
+		DriverNamePrefix:        "fast.",
+		ForceCephFSKernelClient: &fuse,
+		EnableHostNetwork:       &fuse,
+		ProvisionerReplicas:     &replicas,
+		MetricsPortOffset:       100,
+	})
+	assert.Equal(t, "false", clusterTP.ForceCephFSKernelClient)
+	assert.False(t, clusterTP.EnableCSIHostNetwork)
+	assert.Empty(t, hostPorts(clusterTP))
+	assert.Equal(t, int32(1), clusterTP.ProvisionerReplicas)
+	assert.Equal(t, tp.RBDGRPCMetricsPort+100, clusterTP.RBDGRPCMetricsPort)
+	assert.Equal(t, tp.CephFSLivenessMetricsPort+100, clusterTP.CephFSLivenessMetricsPort)
+	// the shared instance is unchanged
+	assert.Equal(t, "rook-ceph.", tp.DriverNamePrefix)
+}
+
+func TestApplyDriverInstance(t *testing.T) {
+	labels := func(contains string) map[string]string {
+		return map[string]string{"app": "csi-rbdplugin", "contains": contains}
+	}
+	plugin := &apps.DaemonSet{
+		ObjectMeta: metav1.ObjectMeta{Name: csiRBDPlugin},
+		Spec: apps.DaemonSetSpec{
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": csiRBDPlugin}},
+			Template: corev1.PodTemplateSpec{ObjectMeta: metav1.ObjectMeta{Labels: labels("csi-rbdplugin-metrics")}},
+		},
+	}
+	provisioner := &apps.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: csiRBDProvisioner},
+		Spec: apps.DeploymentSpec{
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": csiRBDProvisioner}},
+			Template: corev1.PodTemplateSpec{ObjectMeta: metav1.ObjectMeta{Labels: labels("csi-rbdplugin-metrics")}},
+		},
+	}
+	service := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "csi-rbdplugin-metrics"},
+		Spec:       corev1.ServiceSpec{Selector: map[string]string{"contains": "csi-rbdplugin-metrics"}},
+	}
+
+	// the resources of the shared instance are not changed
+	applyDriverInstance("", "rook-ceph.rbd.csi.ceph.com", plugin, provisioner, service)
+	assert.Equal(t, csiRBDPlugin, plugin.Name)
+	assert.Empty(t, plugin.Annotations)
+
+	applyDriverInstance("other", "other.rbd.csi.ceph.com", plugin, provisioner, service)
+	assert.Equal(t, "csi-rbdplugin-other", plugin.Name)
+	assert.Equal(t, "csi-rbdplugin-other", plugin.Spec.Selector.MatchLabels["app"])
+	assert.Equal(t, "csi-rbdplugin-other", plugin.Spec.Template.Labels["app"])
+	assert.Equal(t, "other", plugin.Labels[csiClusterLabel])
+	assert.Equal(t, "other.rbd.csi.ceph.com", plugin.Annotations[csiDriverNameAnnotation])
+	assert.Equal(t, "csi-rbdplugin-provisioner-other", provisioner.Name)
+	assert.Equal(t, "csi-rbdplugin-provisioner-other", provisioner.Spec.Template.Labels["app"])
+	assert.Equal(t, "csi-rbdplugin-metrics-other", provisioner.Spec.Template.Labels["contains"])
+	assert.Equal(t, "csi-rbdplugin-metrics-other", service.Name)
+	assert.Equal(t, "csi-rbdplugin-metrics-other", service.Spec.Selector["contains"])
+	assert.Equal(t, "other", service.Labels[csiClusterLabel])
+}
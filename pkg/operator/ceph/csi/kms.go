@@ -0,0 +1,296 @@
+/*
+Copyright 2021 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package csi
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/vault/api"
+	"github.com/libopenstorage/secrets/vault"
+	"github.com/pkg/errors"
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	"github.com/rook/rook/pkg/daemon/ceph/osd/kms"
+	"github.com/rook/rook/pkg/operator/k8sutil"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// KMSConfigMapName is the name of the ConfigMap with the KMS configurations read by ceph-csi
+	KMSConfigMapName = "csi-kms-connection-details"
+
+	// the annotation listing the KMS configurations of the ConfigMap managed by the operator, so
+	// that the configurations added by hand are kept
+	kmsManagedAnnotation = "ceph.rook.io/managed-kms"
+
+	// the ceph-csi KMS type of the vault configurations authenticated with a token
+	csiKMSTypeVaultTokens = "vaulttokens"
+
+	// the label of the copies of the TLS secrets with their KMS configuration
+	csiKMSLabel = "ceph.rook.io/csi-kms"
+)
+
+// csiKMSConfig is a KMS configuration in the format of ceph-csi
+type csiKMSConfig struct {
+	EncryptionKMSType            string `json:"encryptionKMSType"`
+	VaultAddress                 string `json:"vaultAddress"`
+	VaultBackend                 string `json:"vaultBackend,omitempty"`
+	VaultBackendPath             string `json:"vaultBackendPath,omitempty"`
+	VaultNamespace               string `json:"vaultNamespace,omitempty"`
+	VaultTLSServerName           string `json:"vaultTLSServerName,omitempty"`
+	VaultCAVerify                string `json:"vaultCAVerify,omitempty"`
+	VaultCAFromSecret            string `json:"vaultCAFromSecret,omitempty"`
+	VaultClientCertFromSecret    string `json:"vaultClientCertFromSecret,omitempty"`
+	VaultClientCertKeyFromSecret string `json:"vaultClientCertKeyFromSecret,omitempty"`
+	TenantTokenName              string `json:"tenantTokenName,omitempty"`
+}
+
+// reconcileEncryptionKMS renders the KMS configurations of the CephClusters in the KMS ConfigMap of
+// ceph-csi and copies their TLS secrets to the operator namespace, where ceph-csi reads them. An
+// invalid configuration is skipped and its previous configuration is kept, so that it does not
+// prevent the other configurations and the drivers from being configured.
+func (r *ReconcileCSI) reconcileEncryptionKMS(cephClusters []cephv1.CephCluster, ownerInfo *k8sutil.OwnerInfo) error {
+	configs := map[string]string{}
+	skipped := map[string]bool{}
+	tlsSecretNames := map[string]bool{}
+	for _, cluster := range cephClusters {
+		for _, spec := range cluster.Spec.CSI.EncryptionKMS {
+			if _, ok := configs[spec.Name]; ok || skipped[spec.Name] {
+				logger.Errorf("skipping duplicate csi encryption kms %q of cephcluster %q", spec.Name, cluster.Name)
+				continue
+			}
+
+			config, tlsSecrets, err := r.renderEncryptionKMS(spec, cluster.Namespace, ownerInfo)
+			if err != nil {
+				logger.Errorf("skipping csi encryption kms %q of cephcluster %q, its previous configuration is kept. %v", spec.Name, cluster.Name, err)
+				skipped[spec.Name] = true
+				continue
+			}
+			configs[spec.Name] = config
+			for _, secretName := range tlsSecrets {
+				tlsSecretNames[secretName] = true
+			}
+		}
+	}
+
+	if err := r.saveKMSConfigMap(configs, skipped, ownerInfo); err != nil {
+		return errors.Wrap(err, "failed to save csi kms configmap")
+	}
+	if err := r.cleanupKMSTLSSecrets(tlsSecretNames, skipped); err != nil {
+		return errors.Wrap(err, "failed to clean up the tls secrets of csi encryption kms")
+	}
+
+	return nil
+}
+
+// renderEncryptionKMS validates a KMS configuration, copies its TLS secrets and returns its ceph-csi
+// configuration with the copied secrets by TLS option
+func (r *ReconcileCSI) renderEncryptionKMS(spec cephv1.CSIEncryptionKMSSpec, namespace string, ownerInfo *k8sutil.OwnerInfo) (string, map[string]string, error) {
+	// work on a copy since the validation adds the detected settings to the connection details
+	kmsSpec := *spec.KeyManagementServiceSpec.DeepCopy()
+	if kmsSpec.ConnectionDetails == nil {
+		kmsSpec.ConnectionDetails = map[string]string{}
+	}
+	// the env of the operator is shared by all the clusters, the token is only passed to the vault client
+	err := kms.ValidateConnectionDetailsWithoutTokenEnv(r.context, &cephv1.SecuritySpec{KeyManagementService: kmsSpec}, namespace)
+	if err != nil {
+		return "", nil, errors.Wrap(err, "failed to validate the kms connection details")
+	}
+
+	tlsSecrets, err := r.copyKMSTLSSecrets(spec.Name, kmsSpec, namespace, ownerInfo)
+	if err != nil {
+		return "", nil, errors.Wrap(err, "failed to copy the tls secrets")
+	}
+
+	config, err := formatCSIKMSConfig(kmsSpec, tlsSecrets)
+	if err != nil {
+		return "", nil, errors.Wrap(err, "failed to format the configuration")
+	}
+	return config, tlsSecrets, nil
+}
+
+// formatCSIKMSConfig returns the ceph-csi configuration of the KMS connection details
+func formatCSIKMSConfig(kmsSpec cephv1.KeyManagementServiceSpec, tlsSecrets map[string]string) (string, error) {
+	details := kmsSpec.ConnectionDetails
+	if provider := kms.GetParam(details, kms.Provider); provider != "vault" {
+		return "", errors.Errorf("kms provider %q is not supported by ceph-csi", provider)
+	}
+	if engine := kms.GetParam(details, kms.VaultSecretEngineKey); engine != "" && engine != kms.VaultKVSecretEngineKey {
+		return "", errors.Errorf("vault secret engine %q is not supported by ceph-csi", engine)
+	}
+
+	config := csiKMSConfig{
+		EncryptionKMSType:            csiKMSTypeVaultTokens,
+		VaultAddress:                 kms.GetParam(details, api.EnvVaultAddress),
+		VaultBackendPath:             kms.GetParam(details, vault.VaultBackendPathKey),
+		VaultNamespace:               kms.GetParam(details, api.EnvVaultNamespace),
+		VaultTLSServerName:           kms.GetParam(details, api.EnvVaultTLSServerName),
+		VaultCAFromSecret:            tlsSecrets[api.EnvVaultCACert],
+		VaultClientCertFromSecret:    tlsSecrets[api.EnvVaultClientCert],
+		VaultClientCertKeyFromSecret: tlsSecrets[api.EnvVaultClientKey],
+		TenantTokenName:              kmsSpec.TokenSecretName,
+	}
+	// ceph-csi names the kv versions after the vault secret engines
+	if backend := kms.GetParam(details, vault.VaultBackendKey); backend != "" {
+		config.VaultBackend = "kv-" + backend
+	}
+	if strings.EqualFold(kms.GetParam(details, api.EnvVaultSkipVerify), "true") {
+		config.VaultCAVerify = "false"
+	}
+
+	b, err := json.Marshal(config)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to marshal csi kms config")
+	}
+	return string(b), nil
+}
+
+// csiKMSTLSSecretName returns the name of the copy of a TLS secret of a KMS in the operator namespace
+func csiKMSTLSSecretName(kmsID, fileName string) string {
+	return fmt.Sprintf("rook-csi-kms-%s-%s", kmsID, strings.ReplaceAll(fileName, ".", "-"))
+}
+
+// copyKMSTLSSecrets copies the TLS secrets of a KMS from the cluster namespace to the operator
+// namespace. The copies keep the "cert" and "key" keys that ceph-csi reads. It returns the copied
+// secrets by TLS option.
+func (r *ReconcileCSI) copyKMSTLSSecrets(kmsID string, kmsSpec cephv1.KeyManagementServiceSpec, namespace string, ownerInfo *k8sutil.OwnerInfo) (map[string]string, error) {
+	tlsSecrets := map[string]string{}
+
+	for _, tlsOption := range cephv1.VaultTLSConnectionDetails {
+		secretName := kms.GetParam(kmsSpec.ConnectionDetails, tlsOption)
+		if secretName == "" {
+			continue
+		}
+		secret, err := r.context.Clientset.CoreV1().Secrets(namespace).Get(r.opManagerContext, secretName, metav1.GetOptions{})
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to get tls secret %q", secretName)
+		}
+
+		key, fileName := kms.TLSSecretKeyAndFileName(tlsOption)
+		secretCopy := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      csiKMSTLSSecretName(kmsID, fileName),
+				Namespace: r.opConfig.OperatorNamespace,
+				Labels:    map[string]string{csiKMSLabel: kmsID},
+			},
+			Data: map[string][]byte{key: secret.Data[key]},
+			Type: k8sutil.RookType,
+		}
+		err = ownerInfo.SetControllerReference(secretCopy)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to set owner reference to tls secret %q", secretCopy.Name)
+		}
+		if _, err = k8sutil.CreateOrUpdateSecret(r.context.Clientset, secretCopy); err != nil {
+			return nil, errors.Wrapf(err, "failed to copy tls secret %q", secretName)
+		}
+
+		tlsSecrets[tlsOption] = secretCopy.Name
+	}
+
+	return tlsSecrets, nil
+}
+
+// cleanupKMSTLSSecrets deletes the copies of the TLS secrets that are not used anymore, the copies of
+// the skipped KMS configurations are kept
+func (r *ReconcileCSI) cleanupKMSTLSSecrets(tlsSecretNames map[string]bool, skipped map[string]bool) error {
+	secrets, err := r.context.Clientset.CoreV1().Secrets(r.opConfig.OperatorNamespace).List(r.opManagerContext, metav1.ListOptions{LabelSelector: csiKMSLabel})
+	if err != nil {
+		return errors.Wrap(err, "failed to list the tls secrets")
+	}
+
+	for _, secret := range secrets.Items {
+		kmsID := secret.Labels[csiKMSLabel]
+		if tlsSecretNames[secret.Name] || skipped[kmsID] {
+			continue
+		}
+		logger.Infof("deleting tls secret %q of csi encryption kms %q", secret.Name, kmsID)
+		err := r.context.Clientset.CoreV1().Secrets(secret.Namespace).Delete(r.opManagerContext, secret.Name, metav1.DeleteOptions{})
+		if err != nil && !kerrors.IsNotFound(err) {
+			return errors.Wrapf(err, "failed to delete tls secret %q", secret.Name)
+		}
+	}
+
+	return nil
+}
+
+// saveKMSConfigMap saves the KMS configurations in the ceph-csi KMS ConfigMap. The configurations
+// previously saved by the operator that are not declared anymore are removed, the ones that were
+// skipped are kept.
+func (r *ReconcileCSI) saveKMSConfigMap(configs map[string]string, skipped map[string]bool, ownerInfo *k8sutil.OwnerInfo) error {
+	configMaps := r.context.Clientset.CoreV1().ConfigMaps(r.opConfig.OperatorNamespace)
+	configMap, err := configMaps.Get(r.opManagerContext, KMSConfigMapName, metav1.GetOptions{})
+	if err != nil {
+		if !kerrors.IsNotFound(err) {
+			return errors.Wrapf(err, "failed to get configmap %q", KMSConfigMapName)
+		}
+		if len(configs) == 0 {
+			return nil
+		}
+		configMap = &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      KMSConfigMapName,
+				Namespace: r.opConfig.OperatorNamespace,
+			},
+		}
+		err = ownerInfo.SetControllerReference(configMap)
+		if err != nil {
+			return errors.Wrapf(err, "failed to set owner reference to configmap %q", KMSConfigMapName)
+		}
+		configMap, err = configMaps.Create(r.opManagerContext, configMap, metav1.CreateOptions{})
+		if err != nil {
+			return errors.Wrapf(err, "failed to create configmap %q", KMSConfigMapName)
+		}
+	}
+
+	if configMap.Data == nil {
+		configMap.Data = map[string]string{}
+	}
+	if configMap.Annotations == nil {
+		configMap.Annotations = map[string]string{}
+	}
+	kmsIDs := []string{}
+	if managed := configMap.Annotations[kmsManagedAnnotation]; managed != "" {
+		for _, kmsID := range strings.Split(managed, ",") {
+			if skipped[kmsID] {
+				kmsIDs = append(kmsIDs, kmsID)
+				continue
+			}
+			delete(configMap.Data, kmsID)
+		}
+	}
+
+	for kmsID, config := range configs {
+		if _, ok := configMap.Data[kmsID]; ok {
+			return errors.Errorf("csi encryption kms %q is already configured in configmap %q", kmsID, KMSConfigMapName)
+		}
+		configMap.Data[kmsID] = config
+		kmsIDs = append(kmsIDs, kmsID)
+	}
+	sort.Strings(kmsIDs)
+	configMap.Annotations[kmsManagedAnnotation] = strings.Join(kmsIDs, ",")
+
+	if _, err := configMaps.Update(r.opManagerContext, configMap, metav1.UpdateOptions{}); err != nil {
+		return errors.Wrapf(err, "failed to update configmap %q", KMSConfigMapName)
+	}
+	logger.Infof("saved csi encryption kms configurations %v", kmsIDs)
+
+	return nil
+}
@@ -0,0 +1,164 @@
+/*
+Copyright 2021 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package csi
+
+import (
+	"context"
+	"testing"
+
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	"github.com/rook/rook/pkg/clusterd"
+	"github.com/rook/rook/pkg/operator/ceph/controller"
+	"github.com/rook/rook/pkg/operator/k8sutil"
+	"github.com/rook/rook/pkg/operator/test"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestFormatCSIKMSConfig(t *testing.T) {
+	kmsSpec := cephv1.KeyManagementServiceSpec{
+		ConnectionDetails: map[string]string{
+			"KMS_PROVIDER":       "vault",
+			"VAULT_ADDR":         "https://vault.default.svc:8200",
+			"VAULT_BACKEND_PATH": "rook",
+			"VAULT_BACKEND":      "v2",
+			"VAULT_SKIP_VERIFY":  "true",
+		},
+		TokenSecretName: "rook-vault-token",
+	}
+	config, err := formatCSIKMSConfig(kmsSpec, map[string]string{"VAULT_CACERT": "rook-csi-kms-vault-vault-ca"})
+	assert.NoError(t, err)
+	assert.Equal(t, `{"encryptionKMSType":"vaulttokens","vaultAddress":"https://vault.default.svc:8200","vaultBackend":"kv-v2","vaultBackendPath":"rook","vaultCAVerify":"false","vaultCAFromSecret":"rook-csi-kms-vault-vault-ca","tenantTokenName":"rook-vault-token"}`, config)
+
+	// the transit engine is not supported by ceph-csi
+	kmsSpec.ConnectionDetails["VAULT_SECRET_ENGINE"] = "transit"
+	_, err = formatCSIKMSConfig(kmsSpec, nil)
+	assert.Error(t, err)
+}
+
+func TestReconcileEncryptionKMS(t *testing.T) {
+	ctx := context.TODO()
+	namespace := "rook-ceph"
+	clientset := test.New(t, 1)
+	r := &ReconcileCSI{
+		context:          &clusterd.Context{Clientset: clientset},
+		opManagerContext: ctx,
+		opConfig:         controller.OperatorConfig{OperatorNamespace: "rook-ceph-operator"},
+	}
+	ownerInfo := k8sutil.NewOwnerInfoWithOwnerRef(nil, r.opConfig.OperatorNamespace)
+
+	for name, data := range map[string]map[string][]byte{
+		"vault-token": {"token": []byte("token")},
+		"vault-ca":    {"cert": []byte("ca")},
+	} {
+		secret := &v1.Secret{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace}, Data: data}
+		_, err := clientset.CoreV1().Secrets(namespace).Create(ctx, secret, metav1.CreateOptions{})
+		assert.NoError(t, err)
+	}
+	// a configuration added by hand
+	cm := &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: KMSConfigMapName, Namespace: r.opConfig.OperatorNamespace},
+		Data:       map[string]string{"manual": "{}"},
+	}
+	_, err := clientset.CoreV1().ConfigMaps(r.opConfig.OperatorNamespace).Create(ctx, cm, metav1.CreateOptions{})
+	assert.NoError(t, err)
+
+	cluster := cephv1.CephCluster{ObjectMeta: metav1.ObjectMeta{Name: "my-cluster", Namespace: namespace}}
+	cluster.Spec.CSI.EncryptionKMS = []cephv1.CSIEncryptionKMSSpec{
+		{
+			Name: "vault-1",
+			KeyManagementServiceSpec: cephv1.KeyManagementServiceSpec{
+				ConnectionDetails: map[string]string{
+					"KMS_PROVIDER":  "vault",
+					"VAULT_ADDR":    "https://vault.default.svc:8200",
+					"VAULT_BACKEND": "v1",
+					"VAULT_CACERT":  "vault-ca",
+				},
+				TokenSecretName: "vault-token",
+			},
+		},
+	}
+
+	t.Run("configurations are saved", func(t *testing.T) {
+		err := r.reconcileEncryptionKMS([]cephv1.CephCluster{cluster}, ownerInfo)
+		assert.NoError(t, err)
+
+		cm, err := clientset.CoreV1().ConfigMaps(r.opConfig.OperatorNamespace).Get(ctx, KMSConfigMapName, metav1.GetOptions{})
+		assert.NoError(t, err)
+		assert.Equal(t, "{}", cm.Data["manual"])
+		assert.Contains(t, cm.Data["vault-1"], `"vaultCAFromSecret":"rook-csi-kms-vault-1-vault-ca"`)
+		assert.Equal(t, "vault-1", cm.Annotations[kmsManagedAnnotation])
+
+		// the tls secret is copied to the operator namespace with the key read by ceph-csi
+		secret, err := clientset.CoreV1().Secrets(r.opConfig.OperatorNamespace).Get(ctx, "rook-csi-kms-vault-1-vault-ca", metav1.GetOptions{})
+		assert.NoError(t, err)
+		assert.Equal(t, []byte("ca"), secret.Data["cert"])
+	})
+
+	t.Run("duplicate configurations", func(t *testing.T) {
+		other := *cluster.DeepCopy()
+		other.Name = "other-cluster"
+		other.Spec.CSI.EncryptionKMS[0].ConnectionDetails["VAULT_ADDR"] = "https://other.default.svc:8200"
+		err := r.reconcileEncryptionKMS([]cephv1.CephCluster{cluster, other}, ownerInfo)
+		assert.NoError(t, err)
+
+		// the first configuration is kept
+		cm, err := clientset.CoreV1().ConfigMaps(r.opConfig.OperatorNamespace).Get(ctx, KMSConfigMapName, metav1.GetOptions{})
+		assert.NoError(t, err)
+		assert.Contains(t, cm.Data["vault-1"], "https://vault.default.svc:8200")
+	})
+
+	t.Run("invalid configurations are skipped", func(t *testing.T) {
+		invalid := *cluster.DeepCopy()
+		invalid.Spec.CSI.EncryptionKMS[0].TokenSecretName = "missing"
+		valid := cephv1.CephCluster{ObjectMeta: metav1.ObjectMeta{Name: "valid-cluster", Namespace: namespace}}
+		valid.Spec.CSI.EncryptionKMS = []cephv1.CSIEncryptionKMSSpec{*cluster.Spec.CSI.EncryptionKMS[0].DeepCopy()}
+		valid.Spec.CSI.EncryptionKMS[0].Name = "vault-2"
+		delete(valid.Spec.CSI.EncryptionKMS[0].ConnectionDetails, "VAULT_CACERT")
+		err := r.reconcileEncryptionKMS([]cephv1.CephCluster{invalid, valid}, ownerInfo)
+		assert.NoError(t, err)
+
+		// the previous configuration of the invalid kms and its tls secret are kept
+		cm, err := clientset.CoreV1().ConfigMaps(r.opConfig.OperatorNamespace).Get(ctx, KMSConfigMapName, metav1.GetOptions{})
+		assert.NoError(t, err)
+		assert.Contains(t, cm.Data["vault-1"], `"vaultCAFromSecret":"rook-csi-kms-vault-1-vault-ca"`)
+		assert.NotEmpty(t, cm.Data["vault-2"])
+		assert.Equal(t, "vault-1,vault-2", cm.Annotations[kmsManagedAnnotation])
+		_, err = clientset.CoreV1().Secrets(r.opConfig.OperatorNamespace).Get(ctx, "rook-csi-kms-vault-1-vault-ca", metav1.GetOptions{})
+		assert.NoError(t, err)
+	})
+
+	t.Run("unused tls secrets are deleted", func(t *testing.T) {
+		noTLS := *cluster.DeepCopy()
+		delete(noTLS.Spec.CSI.EncryptionKMS[0].ConnectionDetails, "VAULT_CACERT")
+		err := r.reconcileEncryptionKMS([]cephv1.CephCluster{noTLS}, ownerInfo)
+		assert.NoError(t, err)
+
+		_, err = clientset.CoreV1().Secrets(r.opConfig.OperatorNamespace).Get(ctx, "rook-csi-kms-vault-1-vault-ca", metav1.GetOptions{})
+		assert.Error(t, err)
+	})
+
+	t.Run("removed configurations", func(t *testing.T) {
+		err := r.reconcileEncryptionKMS([]cephv1.CephCluster{}, ownerInfo)
+		assert.NoError(t, err)
+
+		cm, err := clientset.CoreV1().ConfigMaps(r.opConfig.OperatorNamespace).Get(ctx, KMSConfigMapName, metav1.GetOptions{})
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]string{"manual": "{}"}, cm.Data)
+	})
+}
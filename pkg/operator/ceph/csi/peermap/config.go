@@ -352,7 +352,9 @@ func decodePeerToken(token string) (*cephclient.PeerToken, error) {
 }
 
 func getPeerPoolDetails(ctx *clusterd.Context, args ...string) (cephclient.CephStoragePoolDetails, error) {
+	audit := exec.StartCommandAudit(false, "ceph", args...)
 	peerPoolDetails, err := ctx.Executor.ExecuteCommandWithTimeout(exec.CephCommandsTimeout, "ceph", args...)
+	audit.Finish(peerPoolDetails, err)
 	if err != nil {
 		return cephclient.CephStoragePoolDetails{}, errors.Wrap(err, "failed to get pool details from peer cluster")
 	}
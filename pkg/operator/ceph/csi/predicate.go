@@ -65,10 +65,26 @@ func predicateController() predicate.Funcs {
 				}
 			}
 
+			// If the csi settings of a Ceph Cluster change we want to reconcile the csi driver
+			if old, ok := e.ObjectOld.(*cephv1.CephCluster); ok {
+				if new, ok := e.ObjectNew.(*cephv1.CephCluster); ok {
+					diff := cmp.Diff(old.Spec.CSI, new.Spec.CSI)
+					if diff != "" {
+						logger.Infof("ceph csi settings of cephcluster %q changed", new.Name)
+						logger.Debugf("ceph csi settings diff:\n %s", diff)
+						return true
+					}
+				}
+			}
+
 			return false
 		},
 
 		DeleteFunc: func(e event.DeleteEvent) bool {
+			// the dedicated driver instance of a deleted cluster is removed
+			if cluster, ok := e.Object.(*cephv1.CephCluster); ok {
+				return cluster.Spec.CSI.DriverNamePrefix != ""
+			}
 			return false
 		},
 
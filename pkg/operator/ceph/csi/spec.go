@@ -24,6 +24,7 @@ import (
 	"strings"
 	"time"
 
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
 	"github.com/rook/rook/pkg/operator/k8sutil"
 	"github.com/rook/rook/pkg/operator/k8sutil/cmdreporter"
 
@@ -123,6 +124,9 @@ var (
 )
 
 const (
+	// RBDDriverNameSuffix is the suffix of the names of the rbd drivers, including the dedicated driver instances
+	RBDDriverNameSuffix = "rbd.csi.ceph.com"
+
 	KubeMinMajor                = "1"
 	kubeMinVerForSnapshot       = "17"
 	kubeMinVerForV1csiDriver    = "18"
@@ -203,13 +207,8 @@ func validateCSIParam() error {
 	return nil
 }
 
-func (r *ReconcileCSI) startDrivers(ver *version.Info, ownerInfo *k8sutil.OwnerInfo, v *CephCSIVersion) error {
-	var (
-		err                                                   error
-		rbdPlugin, cephfsPlugin                               *apps.DaemonSet
-		rbdProvisionerDeployment, cephfsProvisionerDeployment *apps.Deployment
-		rbdService, cephfsService                             *corev1.Service
-	)
+func (r *ReconcileCSI) startDrivers(ver *version.Info, ownerInfo *k8sutil.OwnerInfo, v *CephCSIVersion, cephClusters []cephv1.CephCluster) error {
+	var err error
 
 	tp := templateParam{
 		Param:     CSIParam,
@@ -349,6 +348,31 @@ func (r *ReconcileCSI) startDrivers(ver *version.Info, ownerInfo *k8sutil.OwnerI
 		logger.Errorf("failed to get nodes. Defaulting the number of replicas of provisioner pods to %d. %v", tp.ProvisionerReplicas, err)
 	}
 
+	if err = r.startDriverInstance(tp, ownerInfo, ""); err != nil {
+		return err
+	}
+
+	// start the dedicated driver instances of the clusters
+	if err = r.startClusterDriverInstances(ver, tp, ownerInfo, cephClusters); err != nil {
+		return errors.Wrap(err, "failed to start the csi driver instances of the clusters")
+	}
+
+	return nil
+}
+
+// startDriverInstance starts an instance of the csi drivers. The shared instance of the operator
+// has no instance name, the dedicated instance of a cluster is named after the cluster namespace.
+func (r *ReconcileCSI) startDriverInstance(tp templateParam, ownerInfo *k8sutil.OwnerInfo, instance string) error {
+	var (
+		err                                                   error
+		rbdPlugin, cephfsPlugin                               *apps.DaemonSet
+		rbdProvisionerDeployment, cephfsProvisionerDeployment *apps.Deployment
+		rbdService, cephfsService                             *corev1.Service
+	)
+
+	rbdDriverName := tp.DriverNamePrefix + "rbd.csi.ceph.com"
+	cephFSDriverName := tp.DriverNamePrefix + "cephfs.csi.ceph.com"
+
 	if EnableRBD {
 		rbdPlugin, err = templateToDaemonSet("rbdplugin", RBDPluginTemplatePath, tp)
 		if err != nil {
@@ -365,6 +389,7 @@ func (r *ReconcileCSI) startDrivers(ver *version.Info, ownerInfo *k8sutil.OwnerI
 			return errors.Wrap(err, "failed to load rbd plugin service template")
 		}
 		rbdService.Namespace = r.opConfig.OperatorNamespace
+		applyDriverInstance(instance, rbdDriverName, rbdPlugin, rbdProvisionerDeployment, rbdService)
 	}
 	if EnableCephFS {
 		cephfsPlugin, err = templateToDaemonSet("cephfsplugin", CephFSPluginTemplatePath, tp)
@@ -382,6 +407,7 @@ func (r *ReconcileCSI) startDrivers(ver *version.Info, ownerInfo *k8sutil.OwnerI
 			return errors.Wrap(err, "failed to load cephfs plugin service template")
 		}
 		cephfsService.Namespace = r.opConfig.OperatorNamespace
+		applyDriverInstance(instance, cephFSDriverName, cephfsPlugin, cephfsProvisionerDeployment, cephfsService)
 	}
 
 	// get common provisioner tolerations and node affinity
@@ -410,7 +436,7 @@ func (r *ReconcileCSI) startDrivers(ver *version.Info, ownerInfo *k8sutil.OwnerI
 		if multusApplied {
 			rbdPlugin.Spec.Template.Spec.HostNetwork = false
 		}
-		err = k8sutil.CreateDaemonSet(csiRBDPlugin, r.opConfig.OperatorNamespace, r.context.Clientset, rbdPlugin)
+		err = k8sutil.CreateDaemonSet(rbdPlugin.Name, r.opConfig.OperatorNamespace, r.context.Clientset, rbdPlugin)
 		if err != nil {
 			return errors.Wrapf(err, "failed to start rbdplugin daemonset %q", rbdPlugin.Name)
 		}
@@ -429,7 +455,7 @@ func (r *ReconcileCSI) startDrivers(ver *version.Info, ownerInfo *k8sutil.OwnerI
 		if err != nil {
 			return errors.Wrapf(err, "failed to set owner reference to rbd provisioner deployment %q", rbdProvisionerDeployment.Name)
 		}
-		antiAffinity := GetPodAntiAffinity("app", csiRBDProvisioner)
+		antiAffinity := GetPodAntiAffinity("app", driverInstanceName(csiRBDProvisioner, instance))
 		rbdProvisionerDeployment.Spec.Template.Spec.Affinity.PodAntiAffinity = &antiAffinity
 		rbdProvisionerDeployment.Spec.Strategy = apps.DeploymentStrategy{
 			Type: apps.RecreateDeploymentStrategyType,
@@ -478,7 +504,7 @@ func (r *ReconcileCSI) startDrivers(ver *version.Info, ownerInfo *k8sutil.OwnerI
 		if multusApplied {
 			cephfsPlugin.Spec.Template.Spec.HostNetwork = false
 		}
-		err = k8sutil.CreateDaemonSet(csiCephFSPlugin, r.opConfig.OperatorNamespace, r.context.Clientset, cephfsPlugin)
+		err = k8sutil.CreateDaemonSet(cephfsPlugin.Name, r.opConfig.OperatorNamespace, r.context.Clientset, cephfsPlugin)
 		if err != nil {
 			return errors.Wrapf(err, "failed to start cephfs plugin daemonset %q", cephfsPlugin.Name)
 		}
@@ -498,7 +524,7 @@ func (r *ReconcileCSI) startDrivers(ver *version.Info, ownerInfo *k8sutil.OwnerI
 		if err != nil {
 			return errors.Wrapf(err, "failed to set owner reference to cephfs provisioner deployment %q", cephfsProvisionerDeployment.Name)
 		}
-		antiAffinity := GetPodAntiAffinity("app", csiCephFSProvisioner)
+		antiAffinity := GetPodAntiAffinity("app", driverInstanceName(csiCephFSProvisioner, instance))
 		cephfsProvisionerDeployment.Spec.Template.Spec.Affinity.PodAntiAffinity = &antiAffinity
 		cephfsProvisionerDeployment.Spec.Strategy = apps.DeploymentStrategy{
 			Type: apps.RecreateDeploymentStrategyType,
@@ -527,15 +553,15 @@ func (r *ReconcileCSI) startDrivers(ver *version.Info, ownerInfo *k8sutil.OwnerI
 	}
 
 	if EnableRBD {
-		err = csiDriverobj.createCSIDriverInfo(r.opManagerContext, r.context.Clientset, RBDDriverName, k8sutil.GetValue(r.opConfig.Parameters, "CSI_RBD_FSGROUPPOLICY", string(k8scsi.ReadWriteOnceWithFSTypeFSGroupPolicy)))
+		err = csiDriverobj.createCSIDriverInfo(r.opManagerContext, r.context.Clientset, rbdDriverName, k8sutil.GetValue(r.opConfig.Parameters, "CSI_RBD_FSGROUPPOLICY", string(k8scsi.ReadWriteOnceWithFSTypeFSGroupPolicy)))
 		if err != nil {
-			return errors.Wrapf(err, "failed to create CSI driver object for %q", RBDDriverName)
+			return errors.Wrapf(err, "failed to create CSI driver object for %q", rbdDriverName)
 		}
 	}
 	if EnableCephFS {
-		err = csiDriverobj.createCSIDriverInfo(r.opManagerContext, r.context.Clientset, CephFSDriverName, k8sutil.GetValue(r.opConfig.Parameters, "CSI_CEPHFS_FSGROUPPOLICY", string(k8scsi.ReadWriteOnceWithFSTypeFSGroupPolicy)))
+		err = csiDriverobj.createCSIDriverInfo(r.opManagerContext, r.context.Clientset, cephFSDriverName, k8sutil.GetValue(r.opConfig.Parameters, "CSI_CEPHFS_FSGROUPPOLICY", string(k8scsi.ReadWriteOnceWithFSTypeFSGroupPolicy)))
 		if err != nil {
-			return errors.Wrapf(err, "failed to create CSI driver object for %q", CephFSDriverName)
+			return errors.Wrapf(err, "failed to create CSI driver object for %q", cephFSDriverName)
 		}
 	}
 
@@ -543,6 +569,9 @@ func (r *ReconcileCSI) startDrivers(ver *version.Info, ownerInfo *k8sutil.OwnerI
 }
 
 func (r *ReconcileCSI) stopDrivers(ver *version.Info) {
+	// the dedicated driver instances of the clusters are removed with the disabled drivers
+	r.stopClusterDriverInstances(ver, map[string]bool{})
+
 	if !EnableRBD {
 		logger.Info("CSI Ceph RBD driver disabled")
 		succeeded := r.deleteCSIDriverResources(ver, csiRBDPlugin, csiRBDProvisioner, "csi-rbdplugin-metrics", RBDDriverName)
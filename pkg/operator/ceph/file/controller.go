@@ -77,8 +77,12 @@ type ReconcileCephFilesystem struct {
 	cephClusterSpec  *cephv1.ClusterSpec
 	clusterInfo      *cephclient.ClusterInfo
 	fsContexts       map[string]*fsHealth
+	usageCheckers    *opcontroller.PoolUsageCheckers
+	recorder         *k8sutil.EventReporter
 	opManagerContext context.Context
 	opConfig         opcontroller.OperatorConfig
+	// newCephAdmin returns the admin that creates the pools, the tests replace it with a fake cluster
+	newCephAdmin func(context *clusterd.Context, clusterInfo *cephclient.ClusterInfo) cephclient.CephAdmin
 }
 
 type fsHealth struct {
@@ -100,8 +104,11 @@ func newReconciler(mgr manager.Manager, context *clusterd.Context, opManagerCont
 		scheme:           mgr.GetScheme(),
 		context:          context,
 		fsContexts:       make(map[string]*fsHealth),
+		usageCheckers:    opcontroller.NewPoolUsageCheckers(),
+		recorder:         k8sutil.NewEventReporter(mgr.GetEventRecorderFor("rook-" + controllerName)),
 		opManagerContext: opManagerContext,
 		opConfig:         opConfig,
+		newCephAdmin:     cephclient.NewCephAdmin,
 	}
 }
 
@@ -153,6 +160,7 @@ func add(mgr manager.Manager, r reconcile.Reconciler) error {
 func (r *ReconcileCephFilesystem) Reconcile(context context.Context, request reconcile.Request) (reconcile.Result, error) {
 	// workaround because the rook logging mechanism is not compatible with the controller-runtime logging interface
 	reconcileResponse, err := r.reconcile(request)
+	reconcileResponse, err = opcontroller.RequeueIfCephCommandsUnavailable(reconcileResponse, err)
 	if err != nil {
 		logger.Errorf("failed to reconcile %v", err)
 	}
@@ -248,6 +256,7 @@ func (r *ReconcileCephFilesystem) reconcile(request reconcile.Request) (reconcil
 			// Remove ceph fs from the map
 			delete(r.fsContexts, fsChannelKeyName(cephFilesystem))
 		}
+		r.usageCheckers.Stop(fsChannelKeyName(cephFilesystem))
 
 		// Remove finalizer
 		err = opcontroller.RemoveFinalizer(r.client, cephFilesystem)
@@ -287,6 +296,12 @@ func (r *ReconcileCephFilesystem) reconcile(request reconcile.Request) (reconcil
 			opcontroller.ErrorCephUpgradingRequeue(desiredCephVersion, runningCephVersion)
 	}
 
+	// The upgrade strategy may hold the upgrade before the mds daemons are rolled out
+	if paused, message := opcontroller.IsUpgradePausedBefore(&cephCluster, config.MdsType); paused {
+		logger.Infof("waiting to reconcile filesystem %q. %s", cephFilesystem.Name, message)
+		return opcontroller.WaitForRequeueIfUpgradePaused, nil
+	}
+
 	// validate the filesystem settings
 	if err := validateFilesystem(r.context, r.clusterInfo, r.cephClusterSpec, cephFilesystem); err != nil {
 		if strings.Contains(err.Error(), opcontroller.UninitializedCephConfigError) {
@@ -304,6 +319,15 @@ func (r *ReconcileCephFilesystem) reconcile(request reconcile.Request) (reconcil
 		return reconcileResponse, err
 	}
 
+	// Run go routine check for the usage of the metadata and data pools
+	fs := newFS(cephFilesystem.Name, cephFilesystem.Namespace)
+	pools := append([]string{generateMetaDataPoolName(fs)}, generateDataPoolNames(fs, cephFilesystem.Spec)...)
+	usageChecker := opcontroller.NewPoolUsageChecker(r.context, r.clusterInfo, r.recorder, cephFilesystem, pools,
+		cephFilesystem.Spec.StatusCheck.Usage, func(usage []cephv1.PoolUsageStatus) error {
+			return r.updatePoolUsageStatus(r.client, request.NamespacedName, usage)
+		})
+	r.usageCheckers.Start(r.opManagerContext, fsChannelKeyName(cephFilesystem), usageChecker)
+
 	statusUpdated := false
 
 	// Enable mirroring if needed
@@ -342,18 +366,24 @@ func (r *ReconcileCephFilesystem) reconcile(request reconcile.Request) (reconcil
 
 				// Run go routine check for mirroring status
 				if !cephFilesystem.Spec.StatusCheck.Mirror.Disabled {
-					// Start monitoring cephfs-mirror status
-					if r.fsContexts[fsChannelKeyName(cephFilesystem)].started {
-						logger.Debug("ceph filesystem mirror status monitoring go routine already running!")
-					} else {
-						checker := newMirrorChecker(r.context, r.client, r.clusterInfo, request.NamespacedName, &cephFilesystem.Spec, cephFilesystem.Name)
-						go checker.checkMirroring(r.fsContexts[fsChannelKeyName(cephFilesystem)].internalCtx)
-						r.fsContexts[fsChannelKeyName(cephFilesystem)].started = true
-					}
+					r.startStatusChecker(cephFilesystem, request.NamespacedName)
 				}
 			}
 		}
 	}
+
+	// Reconcile the snapshot schedules if they are managed
+	if cephFilesystem.Spec.SnapshotScheduling.IsEnabled() {
+		logger.Info("reconciling filesystem snapshot schedules")
+		err = r.reconcileSnapshotSchedules(cephFilesystem)
+		if err != nil {
+			r.updateStatus(r.client, request.NamespacedName, cephv1.ConditionFailure, nil)
+			return opcontroller.ImmediateRetryResult, errors.Wrapf(err, "failed to configure snapshot schedules for filesystem %q", cephFilesystem.Name)
+		}
+
+		// The status checker reports the last and next snapshots of the schedules
+		r.startStatusChecker(cephFilesystem, request.NamespacedName)
+	}
 	if !statusUpdated {
 		// Set Ready status, we are done reconciling
 		r.updateStatus(r.client, request.NamespacedName, cephv1.ConditionReady, nil)
@@ -364,6 +394,18 @@ func (r *ReconcileCephFilesystem) reconcile(request reconcile.Request) (reconcil
 	return reconcile.Result{}, nil
 }
 
+// startStatusChecker starts monitoring the mirroring and snapshot schedule status of the filesystem.
+// The same checker reports both status, so it is started once for the mirroring or the schedules.
+func (r *ReconcileCephFilesystem) startStatusChecker(cephFilesystem *cephv1.CephFilesystem, namespacedName types.NamespacedName) {
+	if r.fsContexts[fsChannelKeyName(cephFilesystem)].started {
+		logger.Debug("ceph filesystem status monitoring go routine already running!")
+		return
+	}
+	checker := newStatusChecker(r.context, r.client, r.clusterInfo, namespacedName, &cephFilesystem.Spec, cephFilesystem.Name)
+	go checker.checkStatus(r.fsContexts[fsChannelKeyName(cephFilesystem)].internalCtx)
+	r.fsContexts[fsChannelKeyName(cephFilesystem)].started = true
+}
+
 func (r *ReconcileCephFilesystem) reconcileCreateFilesystem(cephFilesystem *cephv1.CephFilesystem) (reconcile.Result, error) {
 	if r.cephClusterSpec.External.Enable {
 		_, err := opcontroller.ValidateCephVersionsBetweenLocalAndExternalClusters(r.context, r.clusterInfo)
@@ -384,7 +426,7 @@ func (r *ReconcileCephFilesystem) reconcileCreateFilesystem(cephFilesystem *ceph
 	}
 
 	ownerInfo := k8sutil.NewOwnerInfo(cephFilesystem, r.scheme)
-	err := createFilesystem(r.context, r.clusterInfo, *cephFilesystem, r.cephClusterSpec, ownerInfo, r.cephClusterSpec.DataDirHostPath)
+	err := createFilesystem(r.context, r.clusterInfo, r.newCephAdmin(r.context, r.clusterInfo), *cephFilesystem, r.cephClusterSpec, ownerInfo, r.cephClusterSpec.DataDirHostPath)
 	if err != nil {
 		return reconcile.Result{}, errors.Wrapf(err, "failed to create filesystem %q", cephFilesystem.Name)
 	}
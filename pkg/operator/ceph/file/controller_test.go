@@ -28,6 +28,7 @@ import (
 	"github.com/rook/rook/pkg/client/clientset/versioned/scheme"
 	"github.com/rook/rook/pkg/clusterd"
 	"github.com/rook/rook/pkg/daemon/ceph/client"
+	opcontroller "github.com/rook/rook/pkg/operator/ceph/controller"
 	"github.com/rook/rook/pkg/operator/ceph/version"
 	"github.com/rook/rook/pkg/operator/k8sutil"
 	"github.com/rook/rook/pkg/operator/test"
@@ -204,7 +205,7 @@ func TestCephFilesystemController(t *testing.T) {
 	// Create a fake client to mock API calls.
 	cl := fake.NewClientBuilder().WithScheme(s).WithRuntimeObjects(object...).Build()
 	// Create a ReconcileCephFilesystem object with the scheme and fake client.
-	r := &ReconcileCephFilesystem{client: cl, scheme: s, context: c, fsContexts: make(map[string]*fsHealth), opManagerContext: context.TODO()}
+	r := &ReconcileCephFilesystem{client: cl, scheme: s, context: c, fsContexts: make(map[string]*fsHealth), usageCheckers: opcontroller.NewPoolUsageCheckers(), opManagerContext: context.TODO(), newCephAdmin: client.NewCephAdmin}
 
 	// Mock request to simulate Reconcile() being called on an event for a
 	// watched resource .
@@ -238,7 +239,7 @@ func TestCephFilesystemController(t *testing.T) {
 		// Create a fake client to mock API calls.
 		cl = fake.NewClientBuilder().WithScheme(s).WithRuntimeObjects(object...).Build()
 		// Create a ReconcileCephFilesystem object with the scheme and fake client.
-		r = &ReconcileCephFilesystem{client: cl, scheme: s, context: c, opManagerContext: context.TODO()}
+		r = &ReconcileCephFilesystem{client: cl, scheme: s, context: c, usageCheckers: opcontroller.NewPoolUsageCheckers(), opManagerContext: context.TODO(), newCephAdmin: client.NewCephAdmin}
 		res, err := r.Reconcile(ctx, req)
 		assert.NoError(t, err)
 		assert.True(t, res.Requeue)
@@ -290,7 +291,7 @@ func TestCephFilesystemController(t *testing.T) {
 		c.Executor = executor
 
 		// Create a ReconcileCephFilesystem object with the scheme and fake client.
-		r = &ReconcileCephFilesystem{client: cl, scheme: s, context: c, fsContexts: make(map[string]*fsHealth), opManagerContext: context.TODO()}
+		r = &ReconcileCephFilesystem{client: cl, scheme: s, context: c, fsContexts: make(map[string]*fsHealth), usageCheckers: opcontroller.NewPoolUsageCheckers(), opManagerContext: context.TODO(), newCephAdmin: client.NewCephAdmin}
 
 		res, err := r.Reconcile(ctx, req)
 		assert.NoError(t, err)
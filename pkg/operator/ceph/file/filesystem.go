@@ -44,6 +44,7 @@ type Filesystem struct {
 func createFilesystem(
 	context *clusterd.Context,
 	clusterInfo *cephclient.ClusterInfo,
+	admin cephclient.CephAdmin,
 	fs cephv1.CephFilesystem,
 	clusterSpec *cephv1.ClusterSpec,
 	ownerInfo *k8sutil.OwnerInfo,
@@ -57,7 +58,7 @@ func createFilesystem(
 
 	if len(fs.Spec.DataPools) != 0 {
 		f := newFS(fs.Name, fs.Namespace)
-		if err := f.doFilesystemCreate(context, clusterInfo, clusterSpec, fs.Spec); err != nil {
+		if err := f.doFilesystemCreate(context, clusterInfo, admin, clusterSpec, fs.Spec); err != nil {
 			return errors.Wrapf(err, "failed to create filesystem %q", fs.Name)
 		}
 	}
@@ -126,6 +127,14 @@ func validateFilesystem(context *clusterd.Context, clusterInfo *cephclient.Clust
 	if f.Spec.MetadataServer.ActiveCount < 1 {
 		return errors.New("MetadataServer.ActiveCount must be at least 1")
 	}
+	if f.Spec.SnapshotScheduling.IsEnabled() {
+		if !clusterInfo.CephVersion.IsAtLeastPacific() {
+			return errors.New("snapshot scheduling requires ceph pacific or newer")
+		}
+		if err := f.Spec.SnapshotScheduling.Validate(); err != nil {
+			return errors.Wrap(err, "invalid snapshot scheduling")
+		}
+	}
 	// No data pool means that we expect the fs to exist already
 	if len(f.Spec.DataPools) == 0 {
 		return nil
@@ -152,10 +161,10 @@ func newFS(name, namespace string) *Filesystem {
 }
 
 // SetPoolSize function sets the sizes for MetadataPool and dataPool
-func SetPoolSize(f *Filesystem, context *clusterd.Context, clusterInfo *cephclient.ClusterInfo, clusterSpec *cephv1.ClusterSpec, spec cephv1.FilesystemSpec) error {
+func SetPoolSize(f *Filesystem, admin cephclient.CephAdmin, clusterSpec *cephv1.ClusterSpec, spec cephv1.FilesystemSpec) error {
 	// generating the metadata pool's name
 	metadataPoolName := generateMetaDataPoolName(f)
-	err := cephclient.CreatePoolWithProfile(context, clusterInfo, clusterSpec, metadataPoolName, spec.MetadataPool, "")
+	err := admin.CreatePool(clusterSpec, metadataPoolName, spec.MetadataPool, "")
 	if err != nil {
 		return errors.Wrapf(err, "failed to update metadata pool %q", metadataPoolName)
 	}
@@ -163,7 +172,7 @@ func SetPoolSize(f *Filesystem, context *clusterd.Context, clusterInfo *cephclie
 	dataPoolNames := generateDataPoolNames(f, spec)
 	for i, pool := range spec.DataPools {
 		poolName := dataPoolNames[i]
-		err := cephclient.CreatePoolWithProfile(context, clusterInfo, clusterSpec, poolName, pool, "")
+		err := admin.CreatePool(clusterSpec, poolName, pool, "")
 		if err != nil {
 			return errors.Wrapf(err, "failed to update datapool  %q", poolName)
 		}
@@ -172,7 +181,7 @@ func SetPoolSize(f *Filesystem, context *clusterd.Context, clusterInfo *cephclie
 }
 
 // updateFilesystem ensures that a filesystem which already exists matches the provided spec.
-func (f *Filesystem) updateFilesystem(context *clusterd.Context, clusterInfo *cephclient.ClusterInfo, clusterSpec *cephv1.ClusterSpec, spec cephv1.FilesystemSpec) error {
+func (f *Filesystem) updateFilesystem(context *clusterd.Context, clusterInfo *cephclient.ClusterInfo, admin cephclient.CephAdmin, clusterSpec *cephv1.ClusterSpec, spec cephv1.FilesystemSpec) error {
 	// Even if the fs already exists, the num active mdses may have changed
 	if err := cephclient.SetNumMDSRanks(context, clusterInfo, f.Name, spec.MetadataServer.ActiveCount); err != nil {
 		logger.Errorf(
@@ -183,7 +192,7 @@ func (f *Filesystem) updateFilesystem(context *clusterd.Context, clusterInfo *ce
 		)
 	}
 
-	if err := SetPoolSize(f, context, clusterInfo, clusterSpec, spec); err != nil {
+	if err := SetPoolSize(f, admin, clusterSpec, spec); err != nil {
 		return errors.Wrap(err, "failed to set pools size")
 	}
 
@@ -197,12 +206,12 @@ func (f *Filesystem) updateFilesystem(context *clusterd.Context, clusterInfo *ce
 }
 
 // doFilesystemCreate starts the Ceph file daemons and creates the filesystem in Ceph.
-func (f *Filesystem) doFilesystemCreate(context *clusterd.Context, clusterInfo *cephclient.ClusterInfo, clusterSpec *cephv1.ClusterSpec, spec cephv1.FilesystemSpec) error {
+func (f *Filesystem) doFilesystemCreate(context *clusterd.Context, clusterInfo *cephclient.ClusterInfo, admin cephclient.CephAdmin, clusterSpec *cephv1.ClusterSpec, spec cephv1.FilesystemSpec) error {
 
 	_, err := cephclient.GetFilesystem(context, clusterInfo, f.Name)
 	if err == nil {
 		logger.Infof("filesystem %q already exists", f.Name)
-		return f.updateFilesystem(context, clusterInfo, clusterSpec, spec)
+		return f.updateFilesystem(context, clusterInfo, admin, clusterSpec, spec)
 	}
 	if len(spec.DataPools) == 0 {
 		return errors.New("at least one data pool must be specified")
@@ -218,7 +227,7 @@ func (f *Filesystem) doFilesystemCreate(context *clusterd.Context, clusterInfo *
 		return errors.New("multiple filesystems are only supported as of ceph pacific")
 	}
 
-	poolNames, err := cephclient.GetPoolNamesByID(context, clusterInfo)
+	pools, err := admin.ListPools()
 	if err != nil {
 		return errors.Wrap(err, "failed to get pool names")
 	}
@@ -227,13 +236,13 @@ func (f *Filesystem) doFilesystemCreate(context *clusterd.Context, clusterInfo *
 
 	// Make easy to locate a pool by name and avoid repeated searches
 	reversedPoolMap := make(map[string]int)
-	for key, value := range poolNames {
-		reversedPoolMap[value] = key
+	for _, pool := range pools {
+		reversedPoolMap[pool.Name] = pool.Number
 	}
 
 	metadataPoolName := generateMetaDataPoolName(f)
 	if _, poolFound := reversedPoolMap[metadataPoolName]; !poolFound {
-		err = cephclient.CreatePoolWithProfile(context, clusterInfo, clusterSpec, metadataPoolName, spec.MetadataPool, "")
+		err = admin.CreatePool(clusterSpec, metadataPoolName, spec.MetadataPool, "")
 		if err != nil {
 			return errors.Wrapf(err, "failed to create metadata pool %q", metadataPoolName)
 		}
@@ -243,13 +252,13 @@ func (f *Filesystem) doFilesystemCreate(context *clusterd.Context, clusterInfo *
 	for i, pool := range spec.DataPools {
 		poolName := dataPoolNames[i]
 		if _, poolFound := reversedPoolMap[poolName]; !poolFound {
-			err = cephclient.CreatePoolWithProfile(context, clusterInfo, clusterSpec, poolName, pool, "")
+			err = admin.CreatePool(clusterSpec, poolName, pool, "")
 			if err != nil {
 				return errors.Wrapf(err, "failed to create data pool %q", poolName)
 			}
 			if pool.IsErasureCoded() {
 				// An erasure coded data pool used for a filesystem must allow overwrites
-				if err := cephclient.SetPoolProperty(context, clusterInfo, poolName, "allow_ec_overwrites", "true"); err != nil {
+				if err := admin.SetPoolProperty(poolName, "allow_ec_overwrites", "true"); err != nil {
 					logger.Warningf("failed to set ec pool property. %v", err)
 				}
 			}
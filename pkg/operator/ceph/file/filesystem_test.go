@@ -74,6 +74,15 @@ func TestValidateSpec(t *testing.T) {
 
 	// valid!
 	assert.Nil(t, validateFilesystem(context, clusterInfo, clusterSpec, fs))
+
+	// snapshot scheduling requires pacific
+	fs.Spec.SnapshotScheduling = &cephv1.FSSnapshotSchedulingSpec{Enabled: true, Schedules: []cephv1.FSSnapshotScheduleSpec{{Path: "data"}}}
+	clusterInfo.CephVersion = version.Octopus
+	assert.Error(t, validateFilesystem(context, clusterInfo, clusterSpec, fs))
+	clusterInfo.CephVersion = version.Pacific
+	assert.Error(t, validateFilesystem(context, clusterInfo, clusterSpec, fs))
+	fs.Spec.SnapshotScheduling.Schedules[0] = cephv1.FSSnapshotScheduleSpec{Path: "/data", Intervals: []cephv1.FSSnapshotIntervalSpec{{Interval: "1h"}}}
+	assert.NoError(t, validateFilesystem(context, clusterInfo, clusterSpec, fs))
 }
 
 func isBasePoolOperation(fsName, command string, args []string) bool {
@@ -293,7 +302,7 @@ func TestCreateFilesystem(t *testing.T) {
 
 	t.Run("start basic filesystem", func(t *testing.T) {
 		// start a basic cluster
-		err := createFilesystem(context, clusterInfo, fs, &cephv1.ClusterSpec{}, ownerInfo, "/var/lib/rook/")
+		err := createFilesystem(context, clusterInfo, cephclient.NewCephAdmin(context, clusterInfo), fs, &cephv1.ClusterSpec{}, ownerInfo, "/var/lib/rook/")
 		assert.Nil(t, err)
 		validateStart(ctx, t, context, fs)
 		assert.ElementsMatch(t, []string{}, testopk8s.DeploymentNamesUpdated(deploymentsUpdated))
@@ -301,7 +310,7 @@ func TestCreateFilesystem(t *testing.T) {
 	})
 
 	t.Run("start again should no-op", func(t *testing.T) {
-		err := createFilesystem(context, clusterInfo, fs, &cephv1.ClusterSpec{}, ownerInfo, "/var/lib/rook/")
+		err := createFilesystem(context, clusterInfo, cephclient.NewCephAdmin(context, clusterInfo), fs, &cephv1.ClusterSpec{}, ownerInfo, "/var/lib/rook/")
 		assert.Nil(t, err)
 		validateStart(ctx, t, context, fs)
 		assert.ElementsMatch(t, []string{fmt.Sprintf("rook-ceph-mds-%s-a", fsName), fmt.Sprintf("rook-ceph-mds-%s-b", fsName)}, testopk8s.DeploymentNamesUpdated(deploymentsUpdated))
@@ -314,7 +323,7 @@ func TestCreateFilesystem(t *testing.T) {
 			ConfigDir: configDir,
 			Clientset: clientset}
 		fs.Spec.DataPools = append(fs.Spec.DataPools, cephv1.PoolSpec{Replicated: cephv1.ReplicatedSpec{Size: 1, RequireSafeReplicaSize: false}})
-		err := createFilesystem(context, clusterInfo, fs, &cephv1.ClusterSpec{}, ownerInfo, "/var/lib/rook/")
+		err := createFilesystem(context, clusterInfo, cephclient.NewCephAdmin(context, clusterInfo), fs, &cephv1.ClusterSpec{}, ownerInfo, "/var/lib/rook/")
 		assert.Nil(t, err)
 		validateStart(ctx, t, context, fs)
 		assert.ElementsMatch(t, []string{fmt.Sprintf("rook-ceph-mds-%s-a", fsName), fmt.Sprintf("rook-ceph-mds-%s-b", fsName)}, testopk8s.DeploymentNamesUpdated(deploymentsUpdated))
@@ -331,14 +340,14 @@ func TestCreateFilesystem(t *testing.T) {
 		}
 
 		// Create another filesystem which should fail
-		err := createFilesystem(context, clusterInfo, fs, &cephv1.ClusterSpec{}, &k8sutil.OwnerInfo{}, "/var/lib/rook/")
+		err := createFilesystem(context, clusterInfo, cephclient.NewCephAdmin(context, clusterInfo), fs, &cephv1.ClusterSpec{}, &k8sutil.OwnerInfo{}, "/var/lib/rook/")
 		assert.Error(t, err)
 		assert.Equal(t, fmt.Sprintf("failed to create filesystem %q: multiple filesystems are only supported as of ceph pacific", fsName), err.Error())
 	})
 
 	t.Run("multi filesystem creation now works since ceph version is pacific", func(t *testing.T) {
 		clusterInfo.CephVersion = version.Pacific
-		err := createFilesystem(context, clusterInfo, fs, &cephv1.ClusterSpec{}, ownerInfo, "/var/lib/rook/")
+		err := createFilesystem(context, clusterInfo, cephclient.NewCephAdmin(context, clusterInfo), fs, &cephv1.ClusterSpec{}, ownerInfo, "/var/lib/rook/")
 		assert.NoError(t, err)
 	})
 }
@@ -364,7 +373,7 @@ func TestUpgradeFilesystem(t *testing.T) {
 
 	// start a basic cluster for upgrade
 	ownerInfo := cephclient.NewMinimumOwnerInfoWithOwnerRef()
-	err := createFilesystem(context, clusterInfo, fs, &cephv1.ClusterSpec{}, ownerInfo, "/var/lib/rook/")
+	err := createFilesystem(context, clusterInfo, cephclient.NewCephAdmin(context, clusterInfo), fs, &cephv1.ClusterSpec{}, ownerInfo, "/var/lib/rook/")
 	assert.NoError(t, err)
 	validateStart(ctx, t, context, fs)
 	assert.ElementsMatch(t, []string{}, testopk8s.DeploymentNamesUpdated(deploymentsUpdated))
@@ -377,7 +386,7 @@ func TestUpgradeFilesystem(t *testing.T) {
 		ConfigDir: configDir,
 		Clientset: clientset,
 	}
-	err = createFilesystem(context, clusterInfo, fs, &cephv1.ClusterSpec{}, ownerInfo, "/var/lib/rook/")
+	err = createFilesystem(context, clusterInfo, cephclient.NewCephAdmin(context, clusterInfo), fs, &cephv1.ClusterSpec{}, ownerInfo, "/var/lib/rook/")
 	assert.NoError(t, err)
 
 	// test fail standby daemon failed
@@ -459,7 +468,7 @@ func TestUpgradeFilesystem(t *testing.T) {
 		ConfigDir: configDir,
 		Clientset: clientset,
 	}
-	err = createFilesystem(context, clusterInfo, fs, &cephv1.ClusterSpec{}, ownerInfo, "/var/lib/rook/")
+	err = createFilesystem(context, clusterInfo, cephclient.NewCephAdmin(context, clusterInfo), fs, &cephv1.ClusterSpec{}, ownerInfo, "/var/lib/rook/")
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "fail mds failed")
 }
@@ -496,12 +505,12 @@ func TestCreateNopoolFilesystem(t *testing.T) {
 
 	// start a basic cluster
 	ownerInfo := cephclient.NewMinimumOwnerInfoWithOwnerRef()
-	err := createFilesystem(context, clusterInfo, fs, &cephv1.ClusterSpec{}, ownerInfo, "/var/lib/rook/")
+	err := createFilesystem(context, clusterInfo, cephclient.NewCephAdmin(context, clusterInfo), fs, &cephv1.ClusterSpec{}, ownerInfo, "/var/lib/rook/")
 	assert.Nil(t, err)
 	validateStart(ctx, t, context, fs)
 
 	// starting again should be a no-op
-	err = createFilesystem(context, clusterInfo, fs, &cephv1.ClusterSpec{}, ownerInfo, "/var/lib/rook/")
+	err = createFilesystem(context, clusterInfo, cephclient.NewCephAdmin(context, clusterInfo), fs, &cephv1.ClusterSpec{}, ownerInfo, "/var/lib/rook/")
 	assert.Nil(t, err)
 	validateStart(ctx, t, context, fs)
 }
@@ -524,3 +533,66 @@ func validateStart(ctx context.Context, t *testing.T, context *clusterd.Context,
 	assert.Nil(t, err)
 	assert.Equal(t, fmt.Sprintf("rook-ceph-mds-%s-b", fs.Name), r.Name)
 }
+
+func TestCreateFilesystemPoolsInFakeCluster(t *testing.T) {
+	fsCreated := false
+	dataPoolsAdded := []string{}
+	executor := &exectest.MockExecutor{
+		MockExecuteCommandWithOutput: func(command string, args ...string) (string, error) {
+			if contains(args, "fs") && contains(args, "get") {
+				if !fsCreated {
+					return "", errors.New("fs doesn't exist")
+				}
+				return `{"mdsmap":{"fs_name":"myfs"}}`, nil
+			} else if contains(args, "fs") && contains(args, "ls") {
+				return "[]", nil
+			} else if reflect.DeepEqual(args[0:5], []string{"fs", "new", "myfs", "myfs-metadata", "myfs-data0"}) {
+				fsCreated = true
+				return "", nil
+			} else if contains(args, "fs") && contains(args, "add_data_pool") {
+				dataPoolsAdded = append(dataPoolsAdded, args[3])
+				return "", nil
+			} else if contains(args, "set") && contains(args, "max_mds") {
+				return "", nil
+			}
+			assert.Fail(t, fmt.Sprintf("Unexpected command %q %q", command, args))
+			return "", nil
+		},
+	}
+	context := &clusterd.Context{Executor: executor}
+	clusterInfo := cephclient.AdminClusterInfo("ns")
+	clusterInfo.CephVersion = version.Octopus
+	admin := clienttest.NewFakeCephAdmin()
+	// the metadata pool already exists and is reused
+	admin.AddPool("myfs-metadata", 3)
+
+	fs := fsTest("myfs")
+	fs.Spec.DataPools[0] = cephv1.PoolSpec{ErasureCoded: cephv1.ErasureCodedSpec{DataChunks: 2, CodingChunks: 1}}
+	f := newFS(fs.Name, fs.Namespace)
+	err := f.doFilesystemCreate(context, clusterInfo, admin, &cephv1.ClusterSpec{}, fs.Spec)
+	assert.NoError(t, err)
+	assert.True(t, fsCreated)
+	assert.Equal(t, []string{"myfs-data0", "myfs-metadata"}, admin.PoolNames())
+	assert.Equal(t, uint(3), admin.Pools["myfs-metadata"].Size)
+	assert.Equal(t, "myfs-data0_ecprofile", admin.Pools["myfs-data0"].ErasureCodeProfile)
+	assert.Equal(t, "true", admin.Pools["myfs-data0"].Properties["allow_ec_overwrites"])
+
+	// adding a data pool creates it and adds it to the filesystem
+	fs.Spec.DataPools = append(fs.Spec.DataPools, cephv1.PoolSpec{Replicated: cephv1.ReplicatedSpec{Size: 2}})
+	err = f.doFilesystemCreate(context, clusterInfo, admin, &cephv1.ClusterSpec{}, fs.Spec)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"myfs-data0", "myfs-data1", "myfs-metadata"}, admin.PoolNames())
+	assert.Equal(t, uint(2), admin.Pools["myfs-data1"].Size)
+	assert.Equal(t, []string{"myfs-data0", "myfs-data1"}, dataPoolsAdded)
+	// the metadata pool is updated to the spec
+	assert.Equal(t, uint(1), admin.Pools["myfs-metadata"].Size)
+
+	// the pools are not created when the cluster fails
+	fsCreated = false
+	admin = clienttest.NewFakeCephAdmin()
+	admin.Errors["CreatePool"] = errors.New("failed to create pool")
+	err = f.doFilesystemCreate(context, clusterInfo, admin, &cephv1.ClusterSpec{}, fs.Spec)
+	assert.Error(t, err)
+	assert.False(t, fsCreated)
+	assert.Empty(t, admin.PoolNames())
+}
@@ -21,9 +21,11 @@ import (
 	"context"
 	"time"
 
+	"github.com/pkg/errors"
 	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
 	"github.com/rook/rook/pkg/clusterd"
 	cephclient "github.com/rook/rook/pkg/daemon/ceph/client"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
@@ -32,7 +34,10 @@ const (
 	defaultHealthCheckInterval = 1 * time.Minute
 )
 
-type mirrorChecker struct {
+// statusChecker periodically reports the status of the filesystem that is not checked by the
+// reconcile. It is shared by the mirroring and the snapshot schedules of the filesystem so that a
+// single goroutine refreshes the status of both at the interval of the mirroring status check.
+type statusChecker struct {
 	context        *clusterd.Context
 	interval       time.Duration
 	client         client.Client
@@ -40,11 +45,13 @@ type mirrorChecker struct {
 	namespacedName types.NamespacedName
 	fsSpec         *cephv1.FilesystemSpec
 	fsName         string
+	// mirroring is whether the mirroring status was reported by the last check
+	mirroring bool
 }
 
-// newMirrorChecker creates a new HealthChecker
-func newMirrorChecker(context *clusterd.Context, client client.Client, clusterInfo *cephclient.ClusterInfo, namespacedName types.NamespacedName, fsSpec *cephv1.FilesystemSpec, fsName string) *mirrorChecker {
-	c := &mirrorChecker{
+// newStatusChecker creates a new status checker of the mirroring and snapshot schedules
+func newStatusChecker(context *clusterd.Context, client client.Client, clusterInfo *cephclient.ClusterInfo, namespacedName types.NamespacedName, fsSpec *cephv1.FilesystemSpec, fsName string) *statusChecker {
+	c := &statusChecker{
 		context:        context,
 		interval:       defaultHealthCheckInterval,
 		clusterInfo:    clusterInfo,
@@ -52,62 +59,84 @@ func newMirrorChecker(context *clusterd.Context, client client.Client, clusterIn
 		client:         client,
 		fsSpec:         fsSpec,
 		fsName:         fsName,
+		mirroring:      fsSpec.Mirroring != nil && fsSpec.Mirroring.Enabled && !fsSpec.StatusCheck.Mirror.Disabled,
 	}
 
 	// allow overriding the check interval
 	checkInterval := fsSpec.StatusCheck.Mirror.Interval
 	if checkInterval != nil {
-		logger.Infof("filesystem %q mirroring status check interval is %q", namespacedName.Name, checkInterval)
+		logger.Infof("filesystem %q mirroring and snapshot schedules status check interval is %q", namespacedName.Name, checkInterval)
 		c.interval = checkInterval.Duration
 	}
 
 	return c
 }
 
-// checkMirroring periodically checks the health of the cluster
-func (c *mirrorChecker) checkMirroring(context context.Context) {
-	// check the mirroring health immediately before starting the loop
-	err := c.checkMirroringHealth()
+// checkStatus periodically checks the mirroring and snapshot schedules of the filesystem
+func (c *statusChecker) checkStatus(context context.Context) {
+	// check the status immediately before starting the loop
+	err := c.checkStatusHealth()
 	if err != nil {
-		c.updateStatusMirroring(nil, nil, err.Error())
-		logger.Debugf("failed to check filesystem mirroring status %q. %v", c.namespacedName.Name, err)
+		c.updateCheckedStatus(nil, nil, err.Error())
+		logger.Debugf("failed to check filesystem %q status. %v", c.namespacedName.Name, err)
 	}
 
 	for {
 		select {
 		case <-context.Done():
-			logger.Infof("stopping monitoring filesystem mirroring status %q", c.namespacedName.Name)
+			logger.Infof("stopping monitoring filesystem %q status", c.namespacedName.Name)
 			return
 
 		case <-time.After(c.interval):
-			logger.Debugf("checking filesystem mirroring status %q", c.namespacedName.Name)
-			err := c.checkMirroringHealth()
+			logger.Debugf("checking filesystem %q status", c.namespacedName.Name)
+			err := c.checkStatusHealth()
 			if err != nil {
-				c.updateStatusMirroring(nil, nil, err.Error())
-				logger.Debugf("failed to check filesystem %q mirroring status. %v", c.namespacedName.Name, err)
+				c.updateCheckedStatus(nil, nil, err.Error())
+				logger.Debugf("failed to check filesystem %q status. %v", c.namespacedName.Name, err)
 			}
 		}
 	}
 }
 
-func (c *mirrorChecker) checkMirroringHealth() error {
-	mirrorStatus, err := cephclient.GetFSMirrorDaemonStatus(c.context, c.clusterInfo, c.fsName)
-	if err != nil {
-		c.updateStatusMirroring(nil, nil, err.Error())
-		return err
+func (c *statusChecker) checkStatusHealth() error {
+	// The checker runs as long as the filesystem exists, so the latest spec is checked
+	fs := &cephv1.CephFilesystem{}
+	if err := c.client.Get(c.clusterInfo.Context, c.namespacedName, fs); err != nil {
+		if kerrors.IsNotFound(err) {
+			return nil
+		}
+		return errors.Wrapf(err, "failed to get ceph filesystem %q", c.namespacedName.Name)
+	}
+	c.mirroring = fs.Spec.Mirroring != nil && fs.Spec.Mirroring.Enabled && !fs.Spec.StatusCheck.Mirror.Disabled
+
+	var mirrorStatus []cephv1.FilesystemMirroringInfo
+	var err error
+	if c.mirroring {
+		mirrorStatus, err = cephclient.GetFSMirrorDaemonStatus(c.context, c.clusterInfo, c.fsName)
+		if err != nil {
+			return err
+		}
 	}
 
 	var snapSchedStatus []cephv1.FilesystemSnapshotSchedulesSpec
-	if c.fsSpec.Mirroring.SnapShotScheduleEnabled() {
+	if (c.mirroring && fs.Spec.Mirroring.SnapShotScheduleEnabled()) || fs.Spec.SnapshotScheduling.IsEnabled() {
 		snapSchedStatus, err = cephclient.GetSnapshotScheduleStatus(c.context, c.clusterInfo, c.fsName)
 		if err != nil {
-			c.updateStatusMirroring(nil, nil, err.Error())
 			return err
 		}
+		setNextSnapshotTimes(snapSchedStatus, time.Now().UTC())
+	}
+	if fs.Spec.SnapshotScheduling.IsEnabled() {
+		// report the schedules of the spec that are skipped since their subvolume is not found
+		_, skipped, err := desiredSnapshotSchedules(c.context, c.clusterInfo, fs)
+		if err != nil {
+			return err
+		}
+		snapSchedStatus = append(snapSchedStatus, skipped...)
 	}
 
 	// On success
-	c.updateStatusMirroring(mirrorStatus, snapSchedStatus, "")
+	c.updateCheckedStatus(mirrorStatus, snapSchedStatus, "")
 
 	return nil
 }
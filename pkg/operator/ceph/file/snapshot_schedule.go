@@ -0,0 +1,308 @@
+/*
+Copyright 2021 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package file
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/pkg/errors"
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	"github.com/rook/rook/pkg/clusterd"
+	cephclient "github.com/rook/rook/pkg/daemon/ceph/client"
+	"github.com/rook/rook/pkg/util/exec"
+)
+
+// the layout of the times reported by the snap_schedule mgr module
+const snapScheduleTimeLayout = "2006-01-02T15:04:05"
+
+var (
+	retentionRegex = regexp.MustCompile(`([0-9]+)([a-zA-Z])`)
+	intervalRegex  = regexp.MustCompile(`^([0-9]+)([hdw])$`)
+)
+
+// desiredSnapshotSchedule is the desired snapshot schedule of a path of the filesystem
+type desiredSnapshotSchedule struct {
+	intervals []cephv1.FSSnapshotIntervalSpec
+	// the retention is not managed when nil
+	retention map[string]int
+}
+
+// reconcileSnapshotSchedules adds the snapshot schedules and retention policies of the spec that are
+// missing in the filesystem, and removes the ones that are not in the spec anymore
+func (r *ReconcileCephFilesystem) reconcileSnapshotSchedules(cephFilesystem *cephv1.CephFilesystem) error {
+	err := cephclient.MgrEnableModule(r.context, r.clusterInfo, "snap_schedule", false)
+	if err != nil {
+		return errors.Wrap(err, "failed to enable snap_schedule mgr module")
+	}
+
+	desired, skipped, err := desiredSnapshotSchedules(r.context, r.clusterInfo, cephFilesystem)
+	if err != nil {
+		return err
+	}
+	for _, schedule := range skipped {
+		logger.Warningf("skipping snapshot schedule of subvolume %q of filesystem %q. %s", schedule.Subvol, cephFilesystem.Name, schedule.Details)
+	}
+
+	current, err := cephclient.GetSnapshotScheduleStatus(r.context, r.clusterInfo, cephFilesystem.Name)
+	if err != nil {
+		return err
+	}
+
+	return applySnapshotSchedules(r.context, r.clusterInfo, cephFilesystem.Name, desired, current)
+}
+
+// desiredSnapshotSchedules returns the snapshot schedules of the spec by path. The schedules of the
+// mirroring settings are kept so they are not removed. The schedules of the subvolumes that do not
+// exist are skipped and returned with the error in their details, so the other schedules are still
+// applied. The other errors are returned so the schedules of an unresolved path are not removed.
+func desiredSnapshotSchedules(context *clusterd.Context, clusterInfo *cephclient.ClusterInfo, cephFilesystem *cephv1.CephFilesystem) (map[string]*desiredSnapshotSchedule, []cephv1.FilesystemSnapshotSchedulesSpec, error) {
+	desired := map[string]*desiredSnapshotSchedule{}
+	skipped := []cephv1.FilesystemSnapshotSchedulesSpec{}
+	mirroredRetention := map[string]bool{}
+	if cephFilesystem.Spec.Mirroring != nil && cephFilesystem.Spec.Mirroring.Enabled {
+		for _, snap := range cephFilesystem.Spec.Mirroring.SnapshotSchedules {
+			p := snapshotSchedulePath(snap.Path)
+			if desired[p] == nil {
+				desired[p] = &desiredSnapshotSchedule{}
+			}
+			desired[p].intervals = append(desired[p].intervals, cephv1.FSSnapshotIntervalSpec{Interval: snap.Interval, StartTime: snap.StartTime})
+		}
+		for _, retention := range cephFilesystem.Spec.Mirroring.SnapshotRetention {
+			mirroredRetention[snapshotSchedulePath(retention.Path)] = true
+		}
+	}
+
+	for _, schedule := range cephFilesystem.Spec.SnapshotScheduling.Schedules {
+		p := snapshotSchedulePath(schedule.Path)
+		if schedule.Subvolume != "" {
+			subVolPath, err := cephclient.GetCephFSSubVolumePath(context, clusterInfo, cephFilesystem.Name, schedule.Subvolume, schedule.SubvolumeGroup)
+			if err != nil {
+				// the schedules of a path that could not be resolved must not be removed as drift
+				if code, ok := exec.ExitStatus(errors.Cause(err)); !ok || code != int(syscall.ENOENT) {
+					return nil, nil, err
+				}
+				for _, interval := range schedule.Intervals {
+					skipped = append(skipped, cephv1.FilesystemSnapshotSchedulesSpec{
+						Fs:       cephFilesystem.Name,
+						Subvol:   schedule.Subvolume,
+						Schedule: interval.Interval,
+						Start:    interval.StartTime,
+						Details:  err.Error(),
+					})
+				}
+				continue
+			}
+			// the snapshots of a subvolume are taken in the parent directory of its data path
+			p = path.Dir(subVolPath)
+		}
+		if desired[p] == nil {
+			desired[p] = &desiredSnapshotSchedule{}
+		}
+		desired[p].intervals = append(desired[p].intervals, schedule.Intervals...)
+
+		// the retention of the mirroring settings is added by the mirroring reconcile
+		if !mirroredRetention[p] {
+			retention, err := parseRetention(schedule.Retention)
+			if err != nil {
+				return nil, nil, errors.Wrapf(err, "invalid snapshot retention of %q", schedule.Target())
+			}
+			desired[p].retention = retention
+		}
+	}
+
+	return desired, skipped, nil
+}
+
+// applySnapshotSchedules adds and removes the snapshot schedules and retention policies of the
+// filesystem until they match the desired ones
+func applySnapshotSchedules(context *clusterd.Context, clusterInfo *cephclient.ClusterInfo, fsName string, desired map[string]*desiredSnapshotSchedule, current []cephv1.FilesystemSnapshotSchedulesSpec) error {
+	currentByPath := map[string][]cephv1.FilesystemSnapshotSchedulesSpec{}
+	for _, schedule := range current {
+		currentByPath[schedule.Path] = append(currentByPath[schedule.Path], schedule)
+	}
+
+	// remove all the schedules of the paths that are not in the spec
+	for p := range currentByPath {
+		if _, ok := desired[p]; !ok {
+			if err := cephclient.RemoveSnapshotSchedule(context, clusterInfo, p, "", "", fsName); err != nil {
+				return err
+			}
+		}
+	}
+
+	// sort the paths to apply the changes in a stable order
+	paths := make([]string, 0, len(desired))
+	for p := range desired {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+
+	for _, p := range paths {
+		schedule := desired[p]
+		matched := map[int]bool{}
+		for _, existing := range currentByPath[p] {
+			i := matchingInterval(schedule.intervals, existing)
+			if i >= 0 && !matched[i] {
+				matched[i] = true
+				continue
+			}
+			if err := cephclient.RemoveSnapshotSchedule(context, clusterInfo, p, existing.Schedule, existing.Start, fsName); err != nil {
+				return err
+			}
+		}
+		for i, interval := range schedule.intervals {
+			if matched[i] {
+				continue
+			}
+			if err := cephclient.AddSnapshotSchedule(context, clusterInfo, p, interval.Interval, interval.StartTime, fsName); err != nil {
+				return err
+			}
+		}
+
+		if schedule.retention == nil {
+			continue
+		}
+		currentRetention := map[string]int{}
+		if len(currentByPath[p]) > 0 && currentByPath[p][0].RetentionCounts != nil {
+			currentRetention = currentByPath[p][0].RetentionCounts
+		}
+		for _, period := range sortedPeriods(currentRetention) {
+			count := currentRetention[period]
+			if schedule.retention[period] != count {
+				if err := cephclient.RemoveSnapshotScheduleRetention(context, clusterInfo, p, fmt.Sprintf("%d%s", count, period), fsName); err != nil {
+					return err
+				}
+			}
+		}
+		for _, period := range sortedPeriods(schedule.retention) {
+			count := schedule.retention[period]
+			if currentRetention[period] != count {
+				if err := cephclient.AddSnapshotScheduleRetention(context, clusterInfo, p, fmt.Sprintf("%d%s", count, period), fsName); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// matchingInterval returns the index of the desired interval of an existing schedule, or -1
+func matchingInterval(intervals []cephv1.FSSnapshotIntervalSpec, existing cephv1.FilesystemSnapshotSchedulesSpec) int {
+	for i, interval := range intervals {
+		if interval.Interval != existing.Schedule {
+			continue
+		}
+		// the snap_schedule module picks the start time if it is not set
+		if interval.StartTime == "" || sameSnapshotTime(interval.StartTime, existing.Start) {
+			return i
+		}
+	}
+	return -1
+}
+
+// sameSnapshotTime returns whether the start time of the spec is the start time reported by the
+// snap_schedule module. A start time without a date only matches the time of the day.
+func sameSnapshotTime(spec, reported string) bool {
+	reportedTime, err := time.Parse(snapScheduleTimeLayout, reported)
+	if err != nil {
+		return spec == reported
+	}
+	for _, layout := range []string{snapScheduleTimeLayout, "2006-01-02T15:04", "2006-01-02"} {
+		if specTime, err := time.Parse(layout, spec); err == nil {
+			return specTime.Equal(reportedTime)
+		}
+	}
+	for _, layout := range []string{"15:04:05", "15:04"} {
+		if specTime, err := time.Parse(layout, spec); err == nil {
+			return specTime.Hour() == reportedTime.Hour() && specTime.Minute() == reportedTime.Minute() && specTime.Second() == reportedTime.Second()
+		}
+	}
+	return spec == reported
+}
+
+func snapshotSchedulePath(p string) string {
+	if p == "" {
+		return "/"
+	}
+	return p
+}
+
+// parseRetention parses a retention policy such as "24h7d" into the counts by period
+func parseRetention(retention string) (map[string]int, error) {
+	counts := map[string]int{}
+	for _, match := range retentionRegex.FindAllStringSubmatch(retention, -1) {
+		count, err := strconv.Atoi(match[1])
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to parse retention %q", retention)
+		}
+		counts[match[2]] = count
+	}
+	return counts, nil
+}
+
+func sortedPeriods(retention map[string]int) []string {
+	periods := make([]string, 0, len(retention))
+	for period := range retention {
+		periods = append(periods, period)
+	}
+	sort.Strings(periods)
+	return periods
+}
+
+// nextSnapshotTime returns when the next snapshot of a schedule is taken after the given time
+func nextSnapshotTime(interval, start string, now time.Time) (time.Time, error) {
+	match := intervalRegex.FindStringSubmatch(interval)
+	if match == nil {
+		return time.Time{}, errors.Errorf("unsupported snapshot interval %q", interval)
+	}
+	count, err := strconv.Atoi(match[1])
+	if err != nil || count == 0 {
+		return time.Time{}, errors.Errorf("invalid snapshot interval %q", interval)
+	}
+	period := map[string]time.Duration{"h": time.Hour, "d": 24 * time.Hour, "w": 7 * 24 * time.Hour}[match[2]] * time.Duration(count)
+
+	startTime, err := time.Parse(snapScheduleTimeLayout, start)
+	if err != nil {
+		return time.Time{}, errors.Wrapf(err, "failed to parse snapshot schedule start %q", start)
+	}
+	if now.Before(startTime) {
+		return startTime, nil
+	}
+	return startTime.Add((now.Sub(startTime)/period + 1) * period), nil
+}
+
+// setNextSnapshotTimes sets when the next snapshot of each schedule is taken
+func setNextSnapshotTimes(schedules []cephv1.FilesystemSnapshotSchedulesSpec, now time.Time) {
+	for i := range schedules {
+		if !schedules[i].Active {
+			continue
+		}
+		next, err := nextSnapshotTime(schedules[i].Schedule, schedules[i].Start, now)
+		if err != nil {
+			logger.Debugf("failed to compute the next snapshot of path %q. %v", schedules[i].Path, err)
+			continue
+		}
+		schedules[i].Next = next.Format(snapScheduleTimeLayout)
+	}
+}
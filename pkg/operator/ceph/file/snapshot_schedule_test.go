@@ -0,0 +1,186 @@
+/*
+Copyright 2021 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package file
+
+import (
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	"github.com/rook/rook/pkg/clusterd"
+	cephclient "github.com/rook/rook/pkg/daemon/ceph/client"
+	exectest "github.com/rook/rook/pkg/util/exec/test"
+	"github.com/stretchr/testify/assert"
+	kexec "k8s.io/utils/exec"
+)
+
+func TestApplySnapshotSchedules(t *testing.T) {
+	executor := &exectest.MockExecutor{}
+	context := &clusterd.Context{Executor: executor}
+	clusterInfo := cephclient.AdminClusterInfo("mycluster")
+	commands := []string{}
+	executor.MockExecuteCommandWithOutput = func(command string, args ...string) (string, error) {
+		if args[0] == "fs" && args[1] == "subvolume" && args[2] == "getpath" {
+			switch args[4] {
+			case "missing":
+				return "", &kexec.CodeExitError{Err: errors.New("subvolume not found"), Code: int(syscall.ENOENT)}
+			case "unreachable":
+				return "", &kexec.CodeExitError{Err: errors.New("timed out"), Code: int(syscall.ETIMEDOUT)}
+			}
+			return "/volumes/csi/vol-1/3c7a1d21-8f4f-4b5e-a4e4-1c9f6b5e1f2a", nil
+		}
+		if args[0] == "fs" && args[1] == "snap-schedule" {
+			// keep the arguments before fs=myfs
+			for i, arg := range args {
+				if arg == "fs=myfs" {
+					commands = append(commands, strings.Join(args[2:i], " "))
+				}
+			}
+			return "", nil
+		}
+		return "", errors.Errorf("unexpected ceph command %q", args)
+	}
+
+	fs := &cephv1.CephFilesystem{}
+	fs.Name = "myfs"
+	fs.Spec.SnapshotScheduling = &cephv1.FSSnapshotSchedulingSpec{Enabled: true, Schedules: []cephv1.FSSnapshotScheduleSpec{
+		{Intervals: []cephv1.FSSnapshotIntervalSpec{{Interval: "1h"}, {Interval: "1d", StartTime: "2021-07-01T02:00:00"}}, Retention: "24h7d"},
+		{Subvolume: "vol-1", SubvolumeGroup: "csi", Intervals: []cephv1.FSSnapshotIntervalSpec{{Interval: "1w"}}},
+		{Subvolume: "missing", SubvolumeGroup: "csi", Intervals: []cephv1.FSSnapshotIntervalSpec{{Interval: "1d"}}},
+	}}
+	desired, skipped, err := desiredSnapshotSchedules(context, clusterInfo, fs)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(desired))
+	// the schedule of the missing subvolume is skipped and reported
+	assert.Equal(t, 1, len(skipped))
+	assert.Equal(t, "missing", skipped[0].Subvol)
+	assert.Equal(t, "1d", skipped[0].Schedule)
+	assert.Contains(t, skipped[0].Details, "subvolume not found")
+	assert.Equal(t, map[string]int{"h": 24, "d": 7}, desired["/"].retention)
+	assert.Equal(t, 1, len(desired["/volumes/csi/vol-1"].intervals))
+
+	// the schedules are not applied when the path of a subvolume is unknown for another reason
+	unreachable := fs.DeepCopy()
+	unreachable.Spec.SnapshotScheduling.Schedules = append(unreachable.Spec.SnapshotScheduling.Schedules,
+		cephv1.FSSnapshotScheduleSpec{Subvolume: "unreachable", SubvolumeGroup: "csi", Intervals: []cephv1.FSSnapshotIntervalSpec{{Interval: "1d"}}})
+	_, _, err = desiredSnapshotSchedules(context, clusterInfo, unreachable)
+	assert.Error(t, err)
+
+	t.Run("create", func(t *testing.T) {
+		commands = []string{}
+		err := applySnapshotSchedules(context, clusterInfo, "myfs", desired, []cephv1.FilesystemSnapshotSchedulesSpec{})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{
+			"add / 1h",
+			"add / 1d 2021-07-01T02:00:00",
+			"retention add / 7d",
+			"retention add / 24h",
+			"add /volumes/csi/vol-1 1w",
+		}, commands)
+	})
+
+	t.Run("no drift", func(t *testing.T) {
+		commands = []string{}
+		current := []cephv1.FilesystemSnapshotSchedulesSpec{
+			{Path: "/", Schedule: "1h", Start: "2021-07-01T00:00:00", RetentionCounts: map[string]int{"h": 24, "d": 7}},
+			{Path: "/", Schedule: "1d", Start: "2021-07-01T02:00:00", RetentionCounts: map[string]int{"h": 24, "d": 7}},
+			{Path: "/volumes/csi/vol-1", Schedule: "1w", Start: "2021-07-01T00:00:00", RetentionCounts: map[string]int{}},
+		}
+		err := applySnapshotSchedules(context, clusterInfo, "myfs", desired, current)
+		assert.NoError(t, err)
+		assert.Empty(t, commands)
+	})
+
+	t.Run("drift", func(t *testing.T) {
+		commands = []string{}
+		current := []cephv1.FilesystemSnapshotSchedulesSpec{
+			{Path: "/", Schedule: "1h", Start: "2021-07-01T00:00:00", RetentionCounts: map[string]int{"h": 12, "w": 4}},
+			{Path: "/", Schedule: "1d", Start: "2021-07-01T00:00:00", RetentionCounts: map[string]int{"h": 12, "w": 4}},
+			{Path: "/volumes/csi/vol-1", Schedule: "1w", Start: "2021-07-01T00:00:00", RetentionCounts: map[string]int{"n": 10}},
+			{Path: "/old", Schedule: "1h", Start: "2021-07-01T00:00:00"},
+		}
+		err := applySnapshotSchedules(context, clusterInfo, "myfs", desired, current)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{
+			"remove /old",
+			"remove / 1d 2021-07-01T00:00:00",
+			"add / 1d 2021-07-01T02:00:00",
+			"retention remove / 12h",
+			"retention remove / 4w",
+			"retention add / 7d",
+			"retention add / 24h",
+			"retention remove /volumes/csi/vol-1 10n",
+		}, commands)
+	})
+}
+
+func TestDesiredSnapshotSchedulesWithMirroring(t *testing.T) {
+	fs := &cephv1.CephFilesystem{}
+	fs.Spec.Mirroring = &cephv1.FSMirroringSpec{
+		Enabled:           true,
+		SnapshotSchedules: []cephv1.SnapshotScheduleSpec{{Path: "/", Interval: "24h"}},
+		SnapshotRetention: []cephv1.SnapshotScheduleRetentionSpec{{Path: "/", Duration: "d 1"}},
+	}
+	fs.Spec.SnapshotScheduling = &cephv1.FSSnapshotSchedulingSpec{Enabled: true, Schedules: []cephv1.FSSnapshotScheduleSpec{
+		{Intervals: []cephv1.FSSnapshotIntervalSpec{{Interval: "1h"}}, Retention: "24h"},
+	}}
+
+	desired, skipped, err := desiredSnapshotSchedules(&clusterd.Context{}, cephclient.AdminClusterInfo("mycluster"), fs)
+	assert.NoError(t, err)
+	assert.Empty(t, skipped)
+	assert.Equal(t, []cephv1.FSSnapshotIntervalSpec{{Interval: "24h"}, {Interval: "1h"}}, desired["/"].intervals)
+	// the retention is managed by the mirroring settings
+	assert.Nil(t, desired["/"].retention)
+}
+
+func TestNextSnapshotTime(t *testing.T) {
+	now := time.Date(2021, 7, 3, 10, 30, 0, 0, time.UTC)
+
+	next, err := nextSnapshotTime("1h", "2021-07-01T00:00:00", now)
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2021, 7, 3, 11, 0, 0, 0, time.UTC), next)
+
+	next, err = nextSnapshotTime("2d", "2021-07-01T00:00:00", now)
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2021, 7, 5, 0, 0, 0, 0, time.UTC), next)
+
+	// the schedule did not start yet
+	next, err = nextSnapshotTime("1w", "2021-08-01T00:00:00", now)
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2021, 8, 1, 0, 0, 0, 0, time.UTC), next)
+
+	_, err = nextSnapshotTime("1M", "2021-07-01T00:00:00", now)
+	assert.Error(t, err)
+	_, err = nextSnapshotTime("1h", "", now)
+	assert.Error(t, err)
+
+	schedules := []cephv1.FilesystemSnapshotSchedulesSpec{{Schedule: "1h", Start: "2021-07-01T00:00:00", Active: true}, {Schedule: "1h", Start: "2021-07-01T00:00:00"}}
+	setNextSnapshotTimes(schedules, now)
+	assert.Equal(t, "2021-07-03T11:00:00", schedules[0].Next)
+	assert.Equal(t, "", schedules[1].Next)
+}
+
+func TestSameSnapshotTime(t *testing.T) {
+	assert.True(t, sameSnapshotTime("2021-07-01T02:00:00", "2021-07-01T02:00:00"))
+	assert.True(t, sameSnapshotTime("2021-07-01T02:00", "2021-07-01T02:00:00"))
+	assert.True(t, sameSnapshotTime("11:55", "2021-07-01T11:55:00"))
+	assert.False(t, sameSnapshotTime("11:55", "2021-07-01T11:00:00"))
+	assert.False(t, sameSnapshotTime("2021-07-02T02:00:00", "2021-07-01T02:00:00"))
+}
@@ -20,6 +20,7 @@ package file
 import (
 	"time"
 
+	"github.com/pkg/errors"
 	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
 	"github.com/rook/rook/pkg/operator/ceph/reporting"
 	kerrors "k8s.io/apimachinery/pkg/api/errors"
@@ -53,15 +54,38 @@ func (r *ReconcileCephFilesystem) updateStatus(client client.Client, namespacedN
 	logger.Debugf("filesystem %q status updated to %q", fs.Name, status)
 }
 
-// updateStatusBucket updates an object with a given status
-func (c *mirrorChecker) updateStatusMirroring(mirrorStatus []cephv1.FilesystemMirroringInfo, snapSchedStatus []cephv1.FilesystemSnapshotSchedulesSpec, details string) {
+// updatePoolUsageStatus updates the usage of the pools of a fs CR
+func (r *ReconcileCephFilesystem) updatePoolUsageStatus(client client.Client, namespacedName types.NamespacedName, usage []cephv1.PoolUsageStatus) error {
+	fs := &cephv1.CephFilesystem{}
+	err := client.Get(r.opManagerContext, namespacedName, fs)
+	if err != nil {
+		if kerrors.IsNotFound(err) {
+			logger.Debug("CephFilesystem resource not found. Ignoring since object must be deleted.")
+			return nil
+		}
+		return errors.Wrapf(err, "failed to retrieve filesystem %q to update the pool usage status", namespacedName)
+	}
+
+	if fs.Status == nil {
+		fs.Status = &cephv1.CephFilesystemStatus{}
+	}
+	fs.Status.PoolUsage = usage
+	if err := reporting.UpdateStatus(client, fs); err != nil {
+		return errors.Wrapf(err, "failed to set filesystem %q pool usage status", fs.Name)
+	}
+	logger.Debugf("filesystem %q pool usage status updated", fs.Name)
+	return nil
+}
+
+// updateCheckedStatus updates the mirroring and snapshot schedule status of a fs CR
+func (c *statusChecker) updateCheckedStatus(mirrorStatus []cephv1.FilesystemMirroringInfo, snapSchedStatus []cephv1.FilesystemSnapshotSchedulesSpec, details string) {
 	fs := &cephv1.CephFilesystem{}
 	if err := c.client.Get(c.clusterInfo.Context, c.namespacedName, fs); err != nil {
 		if kerrors.IsNotFound(err) {
 			logger.Debug("CephFilesystem resource not found. Ignoring since object must be deleted.")
 			return
 		}
-		logger.Warningf("failed to retrieve ceph filesystem %q to update mirroring status. %v", c.namespacedName.Name, err)
+		logger.Warningf("failed to retrieve ceph filesystem %q to update its status. %v", c.namespacedName.Name, err)
 		return
 	}
 	if fs.Status == nil {
@@ -69,39 +93,42 @@ func (c *mirrorChecker) updateStatusMirroring(mirrorStatus []cephv1.FilesystemMi
 	}
 
 	// Update the CephFilesystem CR status field
-	fs.Status = toCustomResourceStatus(fs.Status, mirrorStatus, snapSchedStatus, details)
+	fs.Status = toCustomResourceStatus(fs.Status, c.mirroring, mirrorStatus, snapSchedStatus, details)
 	if err := reporting.UpdateStatus(c.client, fs); err != nil {
-		logger.Errorf("failed to set ceph filesystem %q mirroring status. %v", c.namespacedName.Name, err)
+		logger.Errorf("failed to set ceph filesystem %q mirroring and snapshot schedule status. %v", c.namespacedName.Name, err)
 		return
 	}
 
-	logger.Debugf("ceph filesystem %q mirroring status updated", c.namespacedName.Name)
+	logger.Debugf("ceph filesystem %q mirroring and snapshot schedule status updated", c.namespacedName.Name)
 }
 
-func toCustomResourceStatus(currentStatus *cephv1.CephFilesystemStatus, mirrorStatus []cephv1.FilesystemMirroringInfo, snapSchedStatus []cephv1.FilesystemSnapshotSchedulesSpec, details string) *cephv1.CephFilesystemStatus {
-	mirrorStatusSpec := &cephv1.FilesystemMirroringInfoSpec{}
+// toCustomResourceStatus returns the status of the filesystem with the checked status. The mirroring
+// status is only reported when the mirroring is checked.
+func toCustomResourceStatus(currentStatus *cephv1.CephFilesystemStatus, mirroring bool, mirrorStatus []cephv1.FilesystemMirroringInfo, snapSchedStatus []cephv1.FilesystemSnapshotSchedulesSpec, details string) *cephv1.CephFilesystemStatus {
+	var mirrorStatusSpec *cephv1.FilesystemMirroringInfoSpec
 	mirrorSnapScheduleStatusSpec := &cephv1.FilesystemSnapshotScheduleStatusSpec{}
 	now := time.Now().UTC().Format(time.RFC3339)
 
 	// MIRROR
-	if len(mirrorStatus) != 0 {
-		mirrorStatusSpec.LastChecked = now
-		mirrorStatusSpec.FilesystemMirroringAllInfo = mirrorStatus
-	}
+	if mirroring {
+		mirrorStatusSpec = &cephv1.FilesystemMirroringInfoSpec{}
+		if len(mirrorStatus) != 0 {
+			mirrorStatusSpec.LastChecked = now
+			mirrorStatusSpec.FilesystemMirroringAllInfo = mirrorStatus
+		}
 
-	// Always display the details, typically an error
-	mirrorStatusSpec.Details = details
+		// Always display the details, typically an error
+		mirrorStatusSpec.Details = details
 
-	if currentStatus != nil {
 		if currentStatus.MirroringStatus != nil {
 			mirrorStatusSpec.LastChanged = currentStatus.MirroringStatus.LastChanged
 		}
-		if currentStatus.SnapshotScheduleStatus != nil {
-			mirrorStatusSpec.LastChanged = currentStatus.SnapshotScheduleStatus.LastChanged
-		}
 	}
 
 	// SNAP SCHEDULE
+	if currentStatus.SnapshotScheduleStatus != nil {
+		mirrorSnapScheduleStatusSpec.LastChanged = currentStatus.SnapshotScheduleStatus.LastChanged
+	}
 	if len(snapSchedStatus) != 0 {
 		mirrorSnapScheduleStatusSpec.LastChecked = now
 		mirrorSnapScheduleStatusSpec.SnapshotSchedules = snapSchedStatus
@@ -109,5 +136,5 @@ func toCustomResourceStatus(currentStatus *cephv1.CephFilesystemStatus, mirrorSt
 	// Always display the details, typically an error
 	mirrorSnapScheduleStatusSpec.Details = details
 
-	return &cephv1.CephFilesystemStatus{MirroringStatus: mirrorStatusSpec, SnapshotScheduleStatus: mirrorSnapScheduleStatusSpec, Phase: currentStatus.Phase, Info: currentStatus.Info}
+	return &cephv1.CephFilesystemStatus{MirroringStatus: mirrorStatusSpec, SnapshotScheduleStatus: mirrorSnapScheduleStatusSpec, Phase: currentStatus.Phase, Info: currentStatus.Info, PoolUsage: currentStatus.PoolUsage}
 }
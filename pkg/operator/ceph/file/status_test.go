@@ -0,0 +1,51 @@
+/*
+Copyright 2021 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package file
+
+import (
+	"testing"
+
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestToCustomResourceStatus(t *testing.T) {
+	current := &cephv1.CephFilesystemStatus{Phase: cephv1.ConditionReady}
+	schedules := []cephv1.FilesystemSnapshotSchedulesSpec{{Fs: "myfs", Path: "/", Schedule: "1h"}}
+
+	t.Run("snapshot schedules without mirroring", func(t *testing.T) {
+		status := toCustomResourceStatus(current, false, nil, schedules, "")
+		assert.Nil(t, status.MirroringStatus)
+		assert.Equal(t, schedules, status.SnapshotScheduleStatus.SnapshotSchedules)
+		assert.NotEmpty(t, status.SnapshotScheduleStatus.LastChecked)
+		assert.Equal(t, cephv1.ConditionReady, status.Phase)
+	})
+
+	t.Run("mirroring", func(t *testing.T) {
+		mirrorStatus := []cephv1.FilesystemMirroringInfo{{DaemonID: 1}}
+		status := toCustomResourceStatus(current, true, mirrorStatus, schedules, "")
+		assert.Equal(t, mirrorStatus, status.MirroringStatus.FilesystemMirroringAllInfo)
+		assert.Equal(t, schedules, status.SnapshotScheduleStatus.SnapshotSchedules)
+	})
+
+	t.Run("error", func(t *testing.T) {
+		status := toCustomResourceStatus(current, true, nil, nil, "failed")
+		assert.Equal(t, "failed", status.MirroringStatus.Details)
+		assert.Equal(t, "failed", status.SnapshotScheduleStatus.Details)
+		assert.Empty(t, status.SnapshotScheduleStatus.SnapshotSchedules)
+	})
+}
@@ -0,0 +1,423 @@
+/*
+Copyright 2021 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package subvolumegroup to manage the subvolume groups of a CephFS filesystem.
+package subvolumegroup
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/coreos/pkg/capnslog"
+	"github.com/pkg/errors"
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	"github.com/rook/rook/pkg/clusterd"
+	cephclient "github.com/rook/rook/pkg/daemon/ceph/client"
+	"github.com/rook/rook/pkg/operator/ceph/cluster/mon"
+	"github.com/rook/rook/pkg/operator/ceph/config"
+	opcontroller "github.com/rook/rook/pkg/operator/ceph/controller"
+	"github.com/rook/rook/pkg/operator/ceph/csi"
+	"github.com/rook/rook/pkg/operator/ceph/reporting"
+	"github.com/rook/rook/pkg/operator/k8sutil"
+	"github.com/rook/rook/pkg/util/dependents"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+const (
+	controllerName = "ceph-fs-subvolumegroup-controller"
+	// the key of the status info holding the clusterID to set in the csi storage classes
+	clusterIDKey = "clusterID"
+)
+
+var logger = capnslog.NewPackageLogger("github.com/rook/rook", controllerName)
+
+var cephFilesystemSubVolumeGroupKind = reflect.TypeOf(cephv1.CephFilesystemSubVolumeGroup{}).Name()
+
+// Sets the type meta for the controller main object
+var controllerTypeMeta = metav1.TypeMeta{
+	Kind:       cephFilesystemSubVolumeGroupKind,
+	APIVersion: fmt.Sprintf("%s/%s", cephv1.CustomResourceGroup, cephv1.Version),
+}
+
+// ReconcileCephFilesystemSubVolumeGroup reconciles a CephFilesystemSubVolumeGroup object
+type ReconcileCephFilesystemSubVolumeGroup struct {
+	client           client.Client
+	scheme           *runtime.Scheme
+	context          *clusterd.Context
+	clusterInfo      *cephclient.ClusterInfo
+	recorder         *k8sutil.EventReporter
+	opManagerContext context.Context
+}
+
+// Add creates a new CephFilesystemSubVolumeGroup Controller and adds it to the Manager. The Manager
+// will set fields on the Controller and Start it when the Manager is Started.
+func Add(mgr manager.Manager, context *clusterd.Context, opManagerContext context.Context, opConfig opcontroller.OperatorConfig) error {
+	return add(mgr, newReconciler(mgr, context, opManagerContext))
+}
+
+// newReconciler returns a new reconcile.Reconciler
+func newReconciler(mgr manager.Manager, context *clusterd.Context, opManagerContext context.Context) reconcile.Reconciler {
+	return &ReconcileCephFilesystemSubVolumeGroup{
+		client:           mgr.GetClient(),
+		scheme:           mgr.GetScheme(),
+		context:          context,
+		recorder:         k8sutil.NewEventReporter(mgr.GetEventRecorderFor("rook-" + controllerName)),
+		opManagerContext: opManagerContext,
+	}
+}
+
+func add(mgr manager.Manager, r reconcile.Reconciler) error {
+	// Create a new controller
+	c, err := controller.New(controllerName, mgr, controller.Options{Reconciler: r})
+	if err != nil {
+		return err
+	}
+	logger.Info("successfully started")
+
+	// Watch for changes on the CephFilesystemSubVolumeGroup CRD object
+	err = c.Watch(&source.Kind{Type: &cephv1.CephFilesystemSubVolumeGroup{TypeMeta: controllerTypeMeta}}, &handler.EnqueueRequestForObject{}, opcontroller.WatchControllerPredicate())
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Reconcile reads that state of the cluster for a CephFilesystemSubVolumeGroup object and makes
+// changes based on the state read and what is in the CephFilesystemSubVolumeGroup.Spec
+// The Controller will requeue the Request to be processed again if the returned error is non-nil or
+// Result.Requeue is true, otherwise upon completion it will remove the work from the queue.
+func (r *ReconcileCephFilesystemSubVolumeGroup) Reconcile(context context.Context, request reconcile.Request) (reconcile.Result, error) {
+	// workaround because the rook logging mechanism is not compatible with the controller-runtime logging interface
+	reconcileResponse, err := r.reconcile(request)
+	reconcileResponse, err = opcontroller.RequeueIfCephCommandsUnavailable(reconcileResponse, err)
+	if err != nil {
+		logger.Errorf("failed to reconcile %v", err)
+	}
+
+	return reconcileResponse, err
+}
+
+func (r *ReconcileCephFilesystemSubVolumeGroup) reconcile(request reconcile.Request) (reconcile.Result, error) {
+	// Fetch the CephFilesystemSubVolumeGroup instance
+	cephFilesystemSubVolumeGroup := &cephv1.CephFilesystemSubVolumeGroup{}
+	err := r.client.Get(r.opManagerContext, request.NamespacedName, cephFilesystemSubVolumeGroup)
+	if err != nil {
+		if kerrors.IsNotFound(err) {
+			logger.Debug("cephFilesystemSubVolumeGroup resource not found. Ignoring since object must be deleted.")
+			return reconcile.Result{}, nil
+		}
+		// Error reading the object - requeue the request.
+		return reconcile.Result{}, errors.Wrap(err, "failed to get cephFilesystemSubVolumeGroup")
+	}
+
+	// Set a finalizer so we can do cleanup before the object goes away
+	err = opcontroller.AddFinalizerIfNotPresent(r.client, cephFilesystemSubVolumeGroup)
+	if err != nil {
+		return reconcile.Result{}, errors.Wrap(err, "failed to add finalizer")
+	}
+
+	// The CR was just created, initializing status fields
+	if cephFilesystemSubVolumeGroup.Status == nil {
+		r.updateStatus(request.NamespacedName, cephv1.ConditionProgressing, nil)
+	}
+
+	// Make sure a CephCluster is present otherwise do nothing
+	cephCluster, isReadyToReconcile, cephClusterExists, reconcileResponse := opcontroller.IsReadyToReconcile(r.client, r.context, request.NamespacedName, controllerName)
+	if !isReadyToReconcile {
+		// This handles the case where the Ceph Cluster is gone and we want to delete that CR
+		// We skip the deleteSubVolumeGroup() function since everything is gone already
+		//
+		// Also, only remove the finalizer if the CephCluster is gone
+		// If not, we should wait for it to be ready
+		// This handles the case where the operator is not ready to accept Ceph command but the cluster exists
+		if !cephFilesystemSubVolumeGroup.GetDeletionTimestamp().IsZero() && !cephClusterExists {
+			// Remove finalizer
+			err = opcontroller.RemoveFinalizer(r.client, cephFilesystemSubVolumeGroup)
+			if err != nil {
+				return opcontroller.ImmediateRetryResult, errors.Wrap(err, "failed to remove finalizer")
+			}
+
+			// Return and do not requeue. Successful deletion.
+			return reconcile.Result{}, nil
+		}
+		return reconcileResponse, nil
+	}
+
+	// Populate clusterInfo during each reconcile
+	r.clusterInfo, _, _, err = mon.LoadClusterInfo(r.context, r.opManagerContext, request.NamespacedName.Namespace)
+	if err != nil {
+		return reconcile.Result{}, errors.Wrap(err, "failed to populate cluster info")
+	}
+	r.clusterInfo.Context = r.opManagerContext
+	r.clusterInfo.NetworkSpec = cephCluster.Spec.Network
+
+	// The filesystem must exist before its subvolume groups are created
+	cephFilesystem := &cephv1.CephFilesystem{}
+	cephFilesystemName := types.NamespacedName{Name: cephFilesystemSubVolumeGroup.Spec.FilesystemName, Namespace: request.Namespace}
+	err = r.client.Get(r.opManagerContext, cephFilesystemName, cephFilesystem)
+	if err != nil {
+		if kerrors.IsNotFound(err) {
+			// the subvolume group was deleted along with its filesystem, there is nothing left to clean up
+			if !cephFilesystemSubVolumeGroup.GetDeletionTimestamp().IsZero() {
+				if err := r.deleteCsiConfig(cephFilesystemSubVolumeGroup); err != nil {
+					return reconcile.Result{}, err
+				}
+				err = opcontroller.RemoveFinalizer(r.client, cephFilesystemSubVolumeGroup)
+				if err != nil {
+					return reconcile.Result{}, errors.Wrap(err, "failed to remove finalizer")
+				}
+				return reconcile.Result{}, nil
+			}
+			logger.Infof("waiting for the CephFilesystem %q of the subvolume group %q to be created", cephFilesystemName.String(), request.NamespacedName.String())
+			r.updateStatus(request.NamespacedName, cephv1.ConditionFailure, nil)
+			return opcontroller.WaitForRequeueIfCephClusterNotReady, nil
+		}
+		return reconcile.Result{}, errors.Wrapf(err, "failed to get CephFilesystem %q", cephFilesystemName.String())
+	}
+
+	// DELETE: the CR was deleted
+	if !cephFilesystemSubVolumeGroup.GetDeletionTimestamp().IsZero() {
+		deps, err := cephFilesystemSubVolumeGroupDependents(r.context, r.clusterInfo, cephFilesystemSubVolumeGroup)
+		if err != nil {
+			return reconcile.Result{}, err
+		}
+		if !deps.Empty() {
+			err := reporting.ReportDeletionBlockedDueToDependents(logger, r.client, cephFilesystemSubVolumeGroup, deps)
+			return opcontroller.WaitForRequeueIfFinalizerBlocked, err
+		}
+		reporting.ReportDeletionNotBlockedDueToDependents(logger, r.client, r.recorder, cephFilesystemSubVolumeGroup)
+
+		err = r.deleteSubVolumeGroup(cephFilesystemSubVolumeGroup)
+		if err != nil {
+			return reconcile.Result{}, errors.Wrapf(err, "failed to delete ceph filesystem subvolume group %q", cephFilesystemSubVolumeGroup.Name)
+		}
+
+		// Remove finalizer
+		err = opcontroller.RemoveFinalizer(r.client, cephFilesystemSubVolumeGroup)
+		if err != nil {
+			return reconcile.Result{}, errors.Wrap(err, "failed to remove finalizer")
+		}
+
+		// Return and do not requeue. Successful deletion.
+		return reconcile.Result{}, nil
+	}
+
+	// validate the subvolume group settings
+	err = cephFilesystemSubVolumeGroup.Spec.Pinning.Validate()
+	if err != nil {
+		r.updateStatus(request.NamespacedName, cephv1.ConditionFailure, nil)
+		return reconcile.Result{}, errors.Wrapf(err, "invalid ceph filesystem subvolume group %q", cephFilesystemSubVolumeGroup.Name)
+	}
+
+	// The quota and pinning of the subvolume group depend on the ceph version
+	runningCephVersion, err := cephclient.LeastUptodateDaemonVersion(r.context, r.clusterInfo, config.MonType)
+	if err != nil {
+		if strings.Contains(err.Error(), opcontroller.UninitializedCephConfigError) {
+			logger.Info(opcontroller.OperatorNotInitializedMessage)
+			return opcontroller.WaitForRequeueIfOperatorNotInitialized, nil
+		}
+		return reconcile.Result{}, errors.Wrapf(err, "failed to retrieve current ceph %q version", config.MonType)
+	}
+	r.clusterInfo.CephVersion = runningCephVersion
+
+	// Create or Update the subvolume group
+	err = r.createOrUpdateSubVolumeGroup(cephFilesystemSubVolumeGroup)
+	if err != nil {
+		if strings.Contains(err.Error(), opcontroller.UninitializedCephConfigError) {
+			logger.Info(opcontroller.OperatorNotInitializedMessage)
+			return opcontroller.WaitForRequeueIfOperatorNotInitialized, nil
+		}
+		r.updateStatus(request.NamespacedName, cephv1.ConditionFailure, nil)
+		return reconcile.Result{}, errors.Wrapf(err, "failed to create or update ceph filesystem subvolume group %q", cephFilesystemSubVolumeGroup.Name)
+	}
+
+	// Configure the csi driver with the clusterID of the subvolume group
+	clusterID := buildClusterID(cephFilesystemSubVolumeGroup)
+	err = csi.SaveSubvolumeGroupConfig(r.context.Clientset, r.clusterInfo, clusterID, cephFilesystemSubVolumeGroup.Name)
+	if err != nil {
+		r.updateStatus(request.NamespacedName, cephv1.ConditionFailure, nil)
+		return reconcile.Result{}, errors.Wrapf(err, "failed to save the csi config of ceph filesystem subvolume group %q", cephFilesystemSubVolumeGroup.Name)
+	}
+
+	// Success! Let's update the status
+	r.updateStatus(request.NamespacedName, cephv1.ConditionReady, map[string]string{clusterIDKey: clusterID})
+
+	// Return and do not requeue
+	logger.Debug("done reconciling")
+	return reconcile.Result{}, nil
+}
+
+// Create the subvolume group and apply its quota and pinning
+func (r *ReconcileCephFilesystemSubVolumeGroup) createOrUpdateSubVolumeGroup(cephFilesystemSubVolumeGroup *cephv1.CephFilesystemSubVolumeGroup) error {
+	logger.Infof("creating ceph filesystem subvolume group %q in namespace %q", cephFilesystemSubVolumeGroup.Name, cephFilesystemSubVolumeGroup.Namespace)
+	spec := cephFilesystemSubVolumeGroup.Spec
+
+	err := cephclient.CreateCephFSSubVolumeGroup(r.context, r.clusterInfo, spec.FilesystemName, cephFilesystemSubVolumeGroup.Name, spec.DataPoolName)
+	if err != nil {
+		return errors.Wrapf(err, "failed to create ceph filesystem subvolume group %q", cephFilesystemSubVolumeGroup.Name)
+	}
+
+	// the subvolume group is only resized when the quota changed since it was last applied
+	quota := ""
+	if spec.Quota != nil {
+		quota = strconv.FormatInt(spec.Quota.Value(), 10)
+	}
+	appliedQuota := ""
+	if cephFilesystemSubVolumeGroup.Status != nil {
+		appliedQuota = cephFilesystemSubVolumeGroup.Status.Quota
+	}
+	if quota != appliedQuota {
+		if !r.clusterInfo.CephVersion.IsAtLeastQuincy() {
+			return errors.Errorf("the quota of ceph filesystem subvolume group %q requires ceph quincy or newer, the running version is %q", cephFilesystemSubVolumeGroup.Name, r.clusterInfo.CephVersion.String())
+		}
+		size := quota
+		if size == "" {
+			// the quota was removed from the spec
+			size = "infinite"
+		}
+		err = cephclient.ResizeCephFSSubVolumeGroup(r.context, r.clusterInfo, spec.FilesystemName, cephFilesystemSubVolumeGroup.Name, size)
+		if err != nil {
+			return errors.Wrapf(err, "failed to set the quota of ceph filesystem subvolume group %q", cephFilesystemSubVolumeGroup.Name)
+		}
+		if err := r.updateQuotaStatus(types.NamespacedName{Name: cephFilesystemSubVolumeGroup.Name, Namespace: cephFilesystemSubVolumeGroup.Namespace}, quota); err != nil {
+			return err
+		}
+	}
+
+	// the pinning is only applied when set so that a pin set by an admin is not overridden
+	if spec.Pinning.IsSet() {
+		if !r.clusterInfo.CephVersion.IsAtLeastPacific() {
+			return errors.Errorf("the pinning of ceph filesystem subvolume group %q requires ceph pacific or newer, the running version is %q", cephFilesystemSubVolumeGroup.Name, r.clusterInfo.CephVersion.String())
+		}
+		pinType, pinSetting := pinningArgs(spec.Pinning)
+		err = cephclient.PinCephFSSubVolumeGroup(r.context, r.clusterInfo, spec.FilesystemName, cephFilesystemSubVolumeGroup.Name, pinType, pinSetting)
+		if err != nil {
+			return errors.Wrapf(err, "failed to pin ceph filesystem subvolume group %q", cephFilesystemSubVolumeGroup.Name)
+		}
+	}
+
+	return nil
+}
+
+// Delete the subvolume group
+func (r *ReconcileCephFilesystemSubVolumeGroup) deleteSubVolumeGroup(cephFilesystemSubVolumeGroup *cephv1.CephFilesystemSubVolumeGroup) error {
+	logger.Infof("deleting ceph filesystem subvolume group object %q", cephFilesystemSubVolumeGroup.Name)
+	if err := r.deleteCsiConfig(cephFilesystemSubVolumeGroup); err != nil {
+		return err
+	}
+	if err := cephclient.DeleteCephFSSubVolumeGroup(r.context, r.clusterInfo, cephFilesystemSubVolumeGroup.Spec.FilesystemName, cephFilesystemSubVolumeGroup.Name); err != nil {
+		return errors.Wrapf(err, "failed to delete ceph filesystem subvolume group %q", cephFilesystemSubVolumeGroup.Name)
+	}
+
+	logger.Infof("deleted ceph filesystem subvolume group %q", cephFilesystemSubVolumeGroup.Name)
+	return nil
+}
+
+func (r *ReconcileCephFilesystemSubVolumeGroup) deleteCsiConfig(cephFilesystemSubVolumeGroup *cephv1.CephFilesystemSubVolumeGroup) error {
+	err := csi.DeleteClusterConfigEntry(r.context.Clientset, r.opManagerContext, buildClusterID(cephFilesystemSubVolumeGroup))
+	if err != nil {
+		return errors.Wrapf(err, "failed to remove the csi config of ceph filesystem subvolume group %q", cephFilesystemSubVolumeGroup.Name)
+	}
+	return nil
+}
+
+// cephFilesystemSubVolumeGroupDependents returns the subvolumes that prevent the subvolume group from being deleted
+func cephFilesystemSubVolumeGroupDependents(context *clusterd.Context, clusterInfo *cephclient.ClusterInfo, cephFilesystemSubVolumeGroup *cephv1.CephFilesystemSubVolumeGroup) (*dependents.DependentList, error) {
+	deps := dependents.NewDependentList()
+
+	subVolumes, err := cephclient.ListCephFSSubVolumes(context, clusterInfo, cephFilesystemSubVolumeGroup.Spec.FilesystemName, cephFilesystemSubVolumeGroup.Name)
+	if err != nil {
+		return deps, errors.Wrapf(err, "failed to list the subvolumes of ceph filesystem subvolume group %q", cephFilesystemSubVolumeGroup.Name)
+	}
+	for _, subVolume := range subVolumes {
+		deps.Add("filesystem subvolumes", subVolume.Name)
+	}
+
+	return deps, nil
+}
+
+// buildClusterID returns the clusterID to set in the csi storage classes of the subvolume group
+func buildClusterID(cephFilesystemSubVolumeGroup *cephv1.CephFilesystemSubVolumeGroup) string {
+	return k8sutil.Hash(fmt.Sprintf("%s-%s-file-%s", cephFilesystemSubVolumeGroup.Namespace, cephFilesystemSubVolumeGroup.Spec.FilesystemName, cephFilesystemSubVolumeGroup.Name))
+}
+
+// pinningArgs returns the pin type and setting of the pinning policy of the subvolume group, which
+// must be set
+func pinningArgs(pinning cephv1.CephFilesystemSubVolumeGroupSpecPinning) (string, string) {
+	switch {
+	case pinning.Export != nil:
+		return "export", strconv.Itoa(*pinning.Export)
+	case pinning.Random != nil:
+		return "random", strconv.FormatFloat(*pinning.Random, 'f', -1, 64)
+	}
+	return "distributed", strconv.Itoa(*pinning.Distributed)
+}
+
+// updateStatus updates an object with a given status
+func (r *ReconcileCephFilesystemSubVolumeGroup) updateStatus(name types.NamespacedName, status cephv1.ConditionType, info map[string]string) {
+	cephFilesystemSubVolumeGroup := &cephv1.CephFilesystemSubVolumeGroup{}
+	if err := r.client.Get(r.opManagerContext, name, cephFilesystemSubVolumeGroup); err != nil {
+		if kerrors.IsNotFound(err) {
+			logger.Debug("CephFilesystemSubVolumeGroup resource not found. Ignoring since object must be deleted.")
+			return
+		}
+		logger.Warningf("failed to retrieve ceph filesystem subvolume group %q to update status to %q. %v", name, status, err)
+		return
+	}
+	if cephFilesystemSubVolumeGroup.Status == nil {
+		cephFilesystemSubVolumeGroup.Status = &cephv1.CephFilesystemSubVolumeGroupStatus{}
+	}
+
+	cephFilesystemSubVolumeGroup.Status.Phase = status
+	if info != nil {
+		cephFilesystemSubVolumeGroup.Status.Info = info
+	}
+	if err := reporting.UpdateStatus(r.client, cephFilesystemSubVolumeGroup); err != nil {
+		logger.Errorf("failed to set ceph filesystem subvolume group %q status to %q. %v", name, status, err)
+		return
+	}
+	logger.Debugf("ceph filesystem subvolume group %q status updated to %q", name, status)
+}
+
+// updateQuotaStatus records the quota applied to the subvolume group
+func (r *ReconcileCephFilesystemSubVolumeGroup) updateQuotaStatus(name types.NamespacedName, quota string) error {
+	cephFilesystemSubVolumeGroup := &cephv1.CephFilesystemSubVolumeGroup{}
+	if err := r.client.Get(r.opManagerContext, name, cephFilesystemSubVolumeGroup); err != nil {
+		return errors.Wrapf(err, "failed to retrieve ceph filesystem subvolume group %q to update its quota status", name)
+	}
+	if cephFilesystemSubVolumeGroup.Status == nil {
+		cephFilesystemSubVolumeGroup.Status = &cephv1.CephFilesystemSubVolumeGroupStatus{}
+	}
+
+	cephFilesystemSubVolumeGroup.Status.Quota = quota
+	if err := reporting.UpdateStatus(r.client, cephFilesystemSubVolumeGroup); err != nil {
+		return errors.Wrapf(err, "failed to set the quota status of ceph filesystem subvolume group %q", name)
+	}
+	return nil
+}
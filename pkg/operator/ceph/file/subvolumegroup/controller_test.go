@@ -0,0 +1,269 @@
+/*
+Copyright 2021 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package subvolumegroup
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	rookclient "github.com/rook/rook/pkg/client/clientset/versioned/fake"
+	"github.com/rook/rook/pkg/client/clientset/versioned/scheme"
+	"github.com/rook/rook/pkg/clusterd"
+	opcontroller "github.com/rook/rook/pkg/operator/ceph/controller"
+	"github.com/rook/rook/pkg/operator/k8sutil"
+	testop "github.com/rook/rook/pkg/operator/test"
+	exectest "github.com/rook/rook/pkg/util/exec/test"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func TestCephFilesystemSubVolumeGroupController(t *testing.T) {
+	ctx := context.TODO()
+	var (
+		name      = "group-a"
+		namespace = "rook-ceph"
+	)
+
+	quota := resource.MustParse("10Gi")
+	cephFilesystemSubVolumeGroup := &cephv1.CephFilesystemSubVolumeGroup{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       name,
+			Namespace:  namespace,
+			UID:        types.UID("c47cac40-9bee-4d52-823b-ccd803ba5bfe"),
+			Finalizers: []string{"cephfilesystemsubvolumegroup.ceph.rook.io"},
+		},
+		Spec: cephv1.CephFilesystemSubVolumeGroupSpec{
+			FilesystemName: "myfs",
+			Quota:          &quota,
+		},
+		Status: &cephv1.CephFilesystemSubVolumeGroupStatus{Phase: ""},
+	}
+	cephCluster := &cephv1.CephCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      namespace,
+			Namespace: namespace,
+		},
+		Status: cephv1.ClusterStatus{
+			Phase: cephv1.ConditionReady,
+			CephVersion: &cephv1.ClusterVersion{
+				Version: "17.2.0-0",
+			},
+			CephStatus: &cephv1.CephStatus{
+				Health: "HEALTH_OK",
+			},
+		},
+	}
+	cephFilesystem := &cephv1.CephFilesystem{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "myfs",
+			Namespace: namespace,
+		},
+	}
+
+	fsCommands := []string{}
+	subVolumes := `[]`
+	monVersion := "ceph version 17.2.0 (43e2e60a7559d3f46c9d53f1ca875fd499a1e35e) quincy (stable)"
+	executor := &exectest.MockExecutor{
+		MockExecuteCommandWithOutput: func(command string, args ...string) (string, error) {
+			if args[0] == "status" {
+				return `{"fsid":"c47cac40-9bee-4d52-823b-ccd803ba5bfe","health":{"checks":{},"status":"HEALTH_OK"},"pgmap":{"num_pgs":100,"pgs_by_state":[{"state_name":"active+clean","count":100}]}}`, nil
+			}
+			if args[0] == "versions" {
+				return fmt.Sprintf(`{"mon":{%q:3}}`, monVersion), nil
+			}
+			if args[0] == "fs" {
+				for i, arg := range args {
+					// ignore the connection flags
+					if strings.HasPrefix(arg, "--connect-timeout") {
+						fsCommands = append(fsCommands, strings.Join(args[:i], " "))
+						break
+					}
+				}
+				if args[1] == "subvolume" && args[2] == "ls" {
+					return subVolumes, nil
+				}
+			}
+			return "", nil
+		},
+	}
+	c := &clusterd.Context{
+		Executor:      executor,
+		Clientset:     testop.New(t, 1),
+		RookClientset: rookclient.NewSimpleClientset(),
+	}
+
+	// Mock clusterInfo
+	secret := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "rook-ceph-mon",
+			Namespace: namespace,
+		},
+		Data: map[string][]byte{
+			"fsid":         []byte(name),
+			"mon-secret":   []byte("monsecret"),
+			"admin-secret": []byte("adminsecret"),
+		},
+		Type: k8sutil.RookType,
+	}
+	_, err := c.Clientset.CoreV1().Secrets(namespace).Create(ctx, secret, metav1.CreateOptions{})
+	assert.NoError(t, err)
+
+	s := scheme.Scheme
+	s.AddKnownTypes(cephv1.SchemeGroupVersion, &cephv1.CephFilesystemSubVolumeGroup{}, &cephv1.CephFilesystemSubVolumeGroupList{},
+		&cephv1.CephCluster{}, &cephv1.CephClusterList{}, &cephv1.CephFilesystem{}, &cephv1.CephFilesystemList{})
+
+	newReconcile := func(objects ...runtime.Object) *ReconcileCephFilesystemSubVolumeGroup {
+		cl := fake.NewClientBuilder().WithScheme(s).WithRuntimeObjects(objects...).Build()
+		c.Client = cl
+		return &ReconcileCephFilesystemSubVolumeGroup{
+			client:           cl,
+			scheme:           s,
+			context:          c,
+			recorder:         k8sutil.NewEventReporter(record.NewFakeRecorder(5)),
+			opManagerContext: ctx,
+		}
+	}
+	req := reconcile.Request{NamespacedName: types.NamespacedName{Name: name, Namespace: namespace}}
+
+	t.Run("waiting for the filesystem", func(t *testing.T) {
+		r := newReconcile(cephFilesystemSubVolumeGroup, cephCluster)
+		res, err := r.Reconcile(ctx, req)
+		assert.NoError(t, err)
+		assert.True(t, res.Requeue)
+		assert.Empty(t, fsCommands)
+	})
+
+	t.Run("success", func(t *testing.T) {
+		r := newReconcile(cephFilesystemSubVolumeGroup, cephCluster, cephFilesystem)
+		res, err := r.Reconcile(ctx, req)
+		assert.NoError(t, err)
+		assert.False(t, res.Requeue)
+		assert.Equal(t, []string{
+			"fs subvolumegroup create myfs group-a",
+			"fs subvolumegroup resize myfs group-a 10737418240",
+		}, fsCommands)
+
+		err = r.client.Get(ctx, req.NamespacedName, cephFilesystemSubVolumeGroup)
+		assert.NoError(t, err)
+		assert.Equal(t, cephv1.ConditionReady, cephFilesystemSubVolumeGroup.Status.Phase)
+		assert.Equal(t, buildClusterID(cephFilesystemSubVolumeGroup), cephFilesystemSubVolumeGroup.Status.Info["clusterID"])
+		assert.Equal(t, "10737418240", cephFilesystemSubVolumeGroup.Status.Quota)
+	})
+
+	t.Run("quota unchanged", func(t *testing.T) {
+		fsCommands = []string{}
+		r := newReconcile(cephFilesystemSubVolumeGroup, cephCluster, cephFilesystem)
+		_, err := r.Reconcile(ctx, req)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{
+			"fs subvolumegroup create myfs group-a",
+		}, fsCommands)
+	})
+
+	t.Run("pinning", func(t *testing.T) {
+		fsCommands = []string{}
+		export := 1
+		cephFilesystemSubVolumeGroup.Spec.Pinning = cephv1.CephFilesystemSubVolumeGroupSpecPinning{Export: &export}
+		r := newReconcile(cephFilesystemSubVolumeGroup, cephCluster, cephFilesystem)
+		_, err := r.Reconcile(ctx, req)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{
+			"fs subvolumegroup create myfs group-a",
+			"fs subvolumegroup pin myfs group-a export 1",
+		}, fsCommands)
+	})
+
+	t.Run("pinning and quota require a newer ceph version", func(t *testing.T) {
+		monVersion = "ceph version 15.2.13 (c44bc49e7a57a87d84dfff2a077a2058aa2172e2) octopus (stable)"
+		defer func() { monVersion = "ceph version 17.2.0 (43e2e60a7559d3f46c9d53f1ca875fd499a1e35e) quincy (stable)" }()
+		fsCommands = []string{}
+		r := newReconcile(cephFilesystemSubVolumeGroup, cephCluster, cephFilesystem)
+		_, err := r.Reconcile(ctx, req)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "requires ceph pacific or newer")
+		assert.Equal(t, []string{"fs subvolumegroup create myfs group-a"}, fsCommands)
+
+		fsCommands = []string{}
+		cephFilesystemSubVolumeGroup.Spec.Pinning = cephv1.CephFilesystemSubVolumeGroupSpecPinning{}
+		r = newReconcile(cephFilesystemSubVolumeGroup, cephCluster, cephFilesystem)
+		_, err = r.Reconcile(ctx, req)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"fs subvolumegroup create myfs group-a"}, fsCommands)
+	})
+
+	t.Run("quota removed", func(t *testing.T) {
+		fsCommands = []string{}
+		cephFilesystemSubVolumeGroup.Spec.Quota = nil
+		r := newReconcile(cephFilesystemSubVolumeGroup, cephCluster, cephFilesystem)
+		_, err := r.Reconcile(ctx, req)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{
+			"fs subvolumegroup create myfs group-a",
+			"fs subvolumegroup resize myfs group-a infinite",
+		}, fsCommands)
+
+		// the status is read in a new object since the decoding does not clear the fields removed from the status
+		updated := &cephv1.CephFilesystemSubVolumeGroup{}
+		err = r.client.Get(ctx, req.NamespacedName, updated)
+		assert.NoError(t, err)
+		assert.Empty(t, updated.Status.Quota)
+	})
+
+	t.Run("deletion blocked by subvolumes", func(t *testing.T) {
+		fsCommands = []string{}
+		subVolumes = `[{"name":"csi-vol-1"}]`
+		now := metav1.Now()
+		cephFilesystemSubVolumeGroup.DeletionTimestamp = &now
+		r := newReconcile(cephFilesystemSubVolumeGroup, cephCluster, cephFilesystem)
+		res, err := r.Reconcile(ctx, req)
+		assert.Error(t, err)
+		assert.Equal(t, opcontroller.WaitForRequeueIfFinalizerBlocked, res)
+		assert.Equal(t, []string{"fs subvolume ls myfs --group_name group-a"}, fsCommands)
+	})
+
+	t.Run("deletion", func(t *testing.T) {
+		fsCommands = []string{}
+		subVolumes = `[]`
+		r := newReconcile(cephFilesystemSubVolumeGroup, cephCluster, cephFilesystem)
+		res, err := r.Reconcile(ctx, req)
+		assert.NoError(t, err)
+		assert.False(t, res.Requeue)
+		assert.Equal(t, []string{"fs subvolume ls myfs --group_name group-a", "fs subvolumegroup rm myfs group-a"}, fsCommands)
+	})
+}
+
+func TestPinningArgs(t *testing.T) {
+	export := 2
+	distributed := 0
+	random := 0.25
+	pinType, pinSetting := pinningArgs(cephv1.CephFilesystemSubVolumeGroupSpecPinning{Distributed: &distributed})
+	assert.Equal(t, []string{"distributed", "0"}, []string{pinType, pinSetting})
+	pinType, pinSetting = pinningArgs(cephv1.CephFilesystemSubVolumeGroupSpecPinning{Export: &export})
+	assert.Equal(t, []string{"export", "2"}, []string{pinType, pinSetting})
+	pinType, pinSetting = pinningArgs(cephv1.CephFilesystemSubVolumeGroupSpecPinning{Random: &random})
+	assert.Equal(t, []string{"random", "0.25"}, []string{pinType, pinSetting})
+}
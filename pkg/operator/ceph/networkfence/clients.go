@@ -0,0 +1,112 @@
+/*
+Copyright 2021 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package networkfence
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	cephclient "github.com/rook/rook/pkg/daemon/ceph/client"
+	"github.com/rook/rook/pkg/operator/ceph/csi"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// fenceAddresses returns the addresses of the clients of the fence. The addresses of the clients of
+// the node are saved in the status, since the watches of the clients expire once they are fenced.
+func (r *ReconcileCephNetworkFence) fenceAddresses(cephNetworkFence *cephv1.CephNetworkFence) ([]string, error) {
+	addresses := sets.NewString(cephNetworkFence.Spec.CIDRs...)
+	if cephNetworkFence.Spec.NodeName == "" {
+		return addresses.List(), nil
+	}
+
+	nodeAddresses, err := r.nodeClientAddresses(cephNetworkFence.Spec.NodeName)
+	if err != nil {
+		return nil, err
+	}
+	nodeAddresses.Insert(cephNetworkFence.Status.NodeAddresses...)
+	cephNetworkFence.Status.NodeAddresses = nodeAddresses.List()
+
+	return addresses.Union(nodeAddresses).List(), nil
+}
+
+// nodeClientAddresses returns the IPs of the node and the IPs of the clients watching the rbd images
+// of the cluster attached to the node
+func (r *ReconcileCephNetworkFence) nodeClientAddresses(nodeName string) (sets.String, error) {
+	addresses := sets.NewString()
+	otherNodes := sets.NewString()
+	nodes, err := r.context.Clientset.CoreV1().Nodes().List(r.opManagerContext, metav1.ListOptions{})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list nodes")
+	}
+	for _, node := range nodes.Items {
+		for _, address := range node.Status.Addresses {
+			if address.Type != v1.NodeInternalIP && address.Type != v1.NodeExternalIP {
+				continue
+			}
+			if node.Name == nodeName {
+				addresses.Insert(address.Address)
+			} else {
+				otherNodes.Insert(address.Address)
+			}
+		}
+	}
+
+	clusterIDs, err := csi.ClusterIDs(r.context.Clientset, r.opManagerContext, r.clusterInfo.Namespace)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get the csi cluster ids of the cluster")
+	}
+
+	attachments, err := r.context.Clientset.StorageV1().VolumeAttachments().List(r.opManagerContext, metav1.ListOptions{})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list volume attachments")
+	}
+	for _, attachment := range attachments.Items {
+		if attachment.Spec.NodeName != nodeName || !strings.HasSuffix(attachment.Spec.Attacher, csi.RBDDriverNameSuffix) || attachment.Spec.Source.PersistentVolumeName == nil {
+			continue
+		}
+		pvName := *attachment.Spec.Source.PersistentVolumeName
+		pv, err := r.context.Clientset.CoreV1().PersistentVolumes().Get(r.opManagerContext, pvName, metav1.GetOptions{})
+		if err != nil {
+			logger.Warningf("failed to get pv %q attached to node %q. %v", pvName, nodeName, err)
+			continue
+		}
+		if pv.Spec.CSI == nil || !sets.NewString(clusterIDs...).Has(pv.Spec.CSI.VolumeAttributes["clusterID"]) {
+			continue
+		}
+
+		attributes := pv.Spec.CSI.VolumeAttributes
+		watchers, err := cephclient.GetImageWatchers(r.context, r.clusterInfo, attributes["pool"], attributes["radosNamespace"], attributes["imageName"])
+		if err != nil {
+			logger.Warningf("failed to get the clients of pv %q attached to node %q. %v", pvName, nodeName, err)
+			continue
+		}
+		for _, watcher := range watchers {
+			ip := watcher.IP()
+			// the image may already be mapped on another node
+			if otherNodes.Has(ip) {
+				logger.Warningf("not fencing client %q of pv %q, the address belongs to another node", watcher.Address, pvName)
+				continue
+			}
+			addresses.Insert(ip)
+		}
+	}
+
+	return addresses, nil
+}
@@ -0,0 +1,300 @@
+/*
+Copyright 2021 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package networkfence to fence the ceph clients of a network or of a failed node.
+package networkfence
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/coreos/pkg/capnslog"
+	"github.com/pkg/errors"
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	"github.com/rook/rook/pkg/clusterd"
+	cephclient "github.com/rook/rook/pkg/daemon/ceph/client"
+	"github.com/rook/rook/pkg/operator/ceph/cluster/mon"
+	"github.com/rook/rook/pkg/operator/ceph/config"
+	opcontroller "github.com/rook/rook/pkg/operator/ceph/controller"
+	"github.com/rook/rook/pkg/operator/ceph/reporting"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+const (
+	controllerName = "ceph-network-fence-controller"
+
+	// the clients stay blocklisted until they are unfenced
+	blocklistExpiration = 5 * 365 * 24 * time.Hour
+)
+
+var logger = capnslog.NewPackageLogger("github.com/rook/rook", controllerName)
+
+var cephNetworkFenceKind = reflect.TypeOf(cephv1.CephNetworkFence{}).Name()
+
+// Sets the type meta for the controller main object
+var controllerTypeMeta = metav1.TypeMeta{
+	Kind:       cephNetworkFenceKind,
+	APIVersion: fmt.Sprintf("%s/%s", cephv1.CustomResourceGroup, cephv1.Version),
+}
+
+// ReconcileCephNetworkFence reconciles a CephNetworkFence object
+type ReconcileCephNetworkFence struct {
+	client           client.Client
+	scheme           *runtime.Scheme
+	context          *clusterd.Context
+	clusterInfo      *cephclient.ClusterInfo
+	opManagerContext context.Context
+}
+
+// Add creates a new CephNetworkFence Controller and the controller fencing the out-of-service nodes,
+// and adds them to the Manager. The Manager will set fields on the Controllers and Start them when
+// the Manager is Started.
+func Add(mgr manager.Manager, context *clusterd.Context, opManagerContext context.Context, opConfig opcontroller.OperatorConfig) error {
+	if err := add(mgr, newReconciler(mgr, context, opManagerContext)); err != nil {
+		return err
+	}
+	return addNodeFencer(mgr, newNodeFencer(mgr, opManagerContext))
+}
+
+// newReconciler returns a new reconcile.Reconciler
+func newReconciler(mgr manager.Manager, context *clusterd.Context, opManagerContext context.Context) reconcile.Reconciler {
+	return &ReconcileCephNetworkFence{
+		client:           mgr.GetClient(),
+		scheme:           mgr.GetScheme(),
+		context:          context,
+		opManagerContext: opManagerContext,
+	}
+}
+
+func add(mgr manager.Manager, r reconcile.Reconciler) error {
+	// Create a new controller
+	c, err := controller.New(controllerName, mgr, controller.Options{Reconciler: r})
+	if err != nil {
+		return err
+	}
+	logger.Info("successfully started")
+
+	// Watch for changes on the CephNetworkFence CRD object
+	err = c.Watch(&source.Kind{Type: &cephv1.CephNetworkFence{TypeMeta: controllerTypeMeta}}, &handler.EnqueueRequestForObject{}, opcontroller.WatchControllerPredicate())
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Reconcile reads that state of the cluster for a CephNetworkFence object and makes changes based on
+// the state read and what is in the CephNetworkFence.Spec
+// The Controller will requeue the Request to be processed again if the returned error is non-nil or
+// Result.Requeue is true, otherwise upon completion it will remove the work from the queue.
+func (r *ReconcileCephNetworkFence) Reconcile(context context.Context, request reconcile.Request) (reconcile.Result, error) {
+	// workaround because the rook logging mechanism is not compatible with the controller-runtime logging interface
+	reconcileResponse, err := r.reconcile(request)
+	reconcileResponse, err = opcontroller.RequeueIfCephCommandsUnavailable(reconcileResponse, err)
+	if err != nil {
+		logger.Errorf("failed to reconcile %v", err)
+	}
+
+	return reconcileResponse, err
+}
+
+func (r *ReconcileCephNetworkFence) reconcile(request reconcile.Request) (reconcile.Result, error) {
+	// Fetch the CephNetworkFence instance
+	cephNetworkFence := &cephv1.CephNetworkFence{}
+	err := r.client.Get(r.opManagerContext, request.NamespacedName, cephNetworkFence)
+	if err != nil {
+		if kerrors.IsNotFound(err) {
+			logger.Debug("cephNetworkFence resource not found. Ignoring since object must be deleted.")
+			return reconcile.Result{}, nil
+		}
+		// Error reading the object - requeue the request.
+		return reconcile.Result{}, errors.Wrap(err, "failed to get cephNetworkFence")
+	}
+
+	// Set a finalizer so we can do cleanup before the object goes away
+	err = opcontroller.AddFinalizerIfNotPresent(r.client, cephNetworkFence)
+	if err != nil {
+		return reconcile.Result{}, errors.Wrap(err, "failed to add finalizer")
+	}
+
+	// The CR was just created, initializing status fields
+	if cephNetworkFence.Status == nil {
+		cephNetworkFence.Status = &cephv1.NetworkFenceStatus{}
+		r.updateStatus(request.NamespacedName, cephv1.ConditionProgressing, cephNetworkFence.Status)
+	}
+
+	// Make sure a CephCluster is present otherwise do nothing
+	cephCluster, isReadyToReconcile, cephClusterExists, reconcileResponse := opcontroller.IsReadyToReconcile(r.client, r.context, request.NamespacedName, controllerName)
+	if !isReadyToReconcile {
+		// This handles the case where the Ceph Cluster is gone and we want to delete that CR
+		// We skip the unfence() function since everything is gone already
+		//
+		// Also, only remove the finalizer if the CephCluster is gone
+		// If not, we should wait for it to be ready
+		// This handles the case where the operator is not ready to accept Ceph command but the cluster exists
+		if !cephNetworkFence.GetDeletionTimestamp().IsZero() && !cephClusterExists {
+			// Remove finalizer
+			err = opcontroller.RemoveFinalizer(r.client, cephNetworkFence)
+			if err != nil {
+				return opcontroller.ImmediateRetryResult, errors.Wrap(err, "failed to remove finalizer")
+			}
+
+			// Return and do not requeue. Successful deletion.
+			return reconcile.Result{}, nil
+		}
+		return reconcileResponse, nil
+	}
+
+	// Populate clusterInfo during each reconcile
+	r.clusterInfo, _, _, err = mon.LoadClusterInfo(r.context, r.opManagerContext, request.NamespacedName.Namespace)
+	if err != nil {
+		return reconcile.Result{}, errors.Wrap(err, "failed to populate cluster info")
+	}
+	r.clusterInfo.Context = r.opManagerContext
+	r.clusterInfo.NetworkSpec = cephCluster.Spec.Network
+
+	// The blocklist commands depend on the ceph version
+	runningCephVersion, err := cephclient.LeastUptodateDaemonVersion(r.context, r.clusterInfo, config.MonType)
+	if err != nil {
+		if strings.Contains(err.Error(), opcontroller.UninitializedCephConfigError) {
+			logger.Info(opcontroller.OperatorNotInitializedMessage)
+			return opcontroller.WaitForRequeueIfOperatorNotInitialized, nil
+		}
+		return reconcile.Result{}, errors.Wrapf(err, "failed to retrieve current ceph %q version", config.MonType)
+	}
+	r.clusterInfo.CephVersion = runningCephVersion
+
+	// DELETE: the CR was deleted
+	if !cephNetworkFence.GetDeletionTimestamp().IsZero() {
+		_, err = r.fence(cephNetworkFence, []string{})
+		if err != nil {
+			return reconcile.Result{}, errors.Wrapf(err, "failed to unfence ceph network fence %q", cephNetworkFence.Name)
+		}
+
+		// Remove finalizer
+		err = opcontroller.RemoveFinalizer(r.client, cephNetworkFence)
+		if err != nil {
+			return reconcile.Result{}, errors.Wrap(err, "failed to remove finalizer")
+		}
+
+		// Return and do not requeue. Successful deletion.
+		return reconcile.Result{}, nil
+	}
+
+	// validate the fence settings
+	err = cephNetworkFence.Spec.Validate()
+	if err != nil {
+		r.updateStatus(request.NamespacedName, cephv1.ConditionFailure, cephNetworkFence.Status)
+		return reconcile.Result{}, errors.Wrapf(err, "invalid ceph network fence %q", cephNetworkFence.Name)
+	}
+
+	addresses := []string{}
+	if cephNetworkFence.Spec.IsFenced() {
+		addresses, err = r.fenceAddresses(cephNetworkFence)
+		if err != nil {
+			r.updateStatus(request.NamespacedName, cephv1.ConditionFailure, cephNetworkFence.Status)
+			return reconcile.Result{}, errors.Wrapf(err, "failed to get the clients of ceph network fence %q", cephNetworkFence.Name)
+		}
+	}
+
+	// Fence the clients and unfence the clients no longer in the fence
+	status, err := r.fence(cephNetworkFence, addresses)
+	if err != nil {
+		r.updateStatus(request.NamespacedName, cephv1.ConditionFailure, status)
+		return reconcile.Result{}, errors.Wrapf(err, "failed to fence ceph network fence %q", cephNetworkFence.Name)
+	}
+
+	// Success! Let's update the status
+	r.updateStatus(request.NamespacedName, cephv1.ConditionReady, status)
+
+	// Return and do not requeue
+	logger.Debug("done reconciling")
+	return reconcile.Result{}, nil
+}
+
+// fence blocklists the addresses and removes the other addresses fenced before from the blocklist.
+// The status is updated with the fenced addresses even on failure.
+func (r *ReconcileCephNetworkFence) fence(cephNetworkFence *cephv1.CephNetworkFence, addresses []string) (*cephv1.NetworkFenceStatus, error) {
+	status := cephNetworkFence.Status.DeepCopy()
+	if status == nil {
+		status = &cephv1.NetworkFenceStatus{}
+	}
+	fenced := sets.NewString(status.FencedAddresses...)
+	wanted := sets.NewString(addresses...)
+	if len(addresses) == 0 {
+		status.NodeAddresses = nil
+	}
+
+	for _, address := range wanted.Difference(fenced).List() {
+		logger.Infof("fencing the clients of %q for ceph network fence %q", address, cephNetworkFence.Name)
+		if err := cephclient.BlocklistAdd(r.context, r.clusterInfo, address, blocklistExpiration); err != nil {
+			status.FencedAddresses = fenced.List()
+			return status, err
+		}
+		fenced.Insert(address)
+	}
+
+	for _, address := range fenced.Difference(wanted).List() {
+		logger.Infof("unfencing the clients of %q for ceph network fence %q", address, cephNetworkFence.Name)
+		if err := cephclient.BlocklistRemove(r.context, r.clusterInfo, address); err != nil {
+			status.FencedAddresses = fenced.List()
+			return status, err
+		}
+		fenced.Delete(address)
+	}
+
+	status.FencedAddresses = fenced.List()
+	return status, nil
+}
+
+// updateStatus updates an object with a given status
+func (r *ReconcileCephNetworkFence) updateStatus(name types.NamespacedName, phase cephv1.ConditionType, status *cephv1.NetworkFenceStatus) {
+	cephNetworkFence := &cephv1.CephNetworkFence{}
+	if err := r.client.Get(r.opManagerContext, name, cephNetworkFence); err != nil {
+		if kerrors.IsNotFound(err) {
+			logger.Debug("CephNetworkFence resource not found. Ignoring since object must be deleted.")
+			return
+		}
+		logger.Warningf("failed to retrieve ceph network fence %q to update status to %q. %v", name, phase, err)
+		return
+	}
+
+	if status != nil {
+		cephNetworkFence.Status = status.DeepCopy()
+	}
+	if cephNetworkFence.Status == nil {
+		cephNetworkFence.Status = &cephv1.NetworkFenceStatus{}
+	}
+	cephNetworkFence.Status.Phase = phase
+	if err := reporting.UpdateStatus(r.client, cephNetworkFence); err != nil {
+		logger.Errorf("failed to set ceph network fence %q status to %q. %v", name, phase, err)
+		return
+	}
+	logger.Debugf("ceph network fence %q status updated to %q", name, phase)
+}
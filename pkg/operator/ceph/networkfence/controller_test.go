@@ -0,0 +1,322 @@
+/*
+Copyright 2021 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package networkfence
+
+import (
+	"context"
+	"testing"
+
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	rookclient "github.com/rook/rook/pkg/client/clientset/versioned/fake"
+	"github.com/rook/rook/pkg/clusterd"
+	"github.com/rook/rook/pkg/operator/k8sutil"
+	testop "github.com/rook/rook/pkg/operator/test"
+	exectest "github.com/rook/rook/pkg/util/exec/test"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const dummyVersionsRaw = `
+{
+	"mon": {
+		"ceph version 16.2.6 (ee28fb57e47e9f88813e24bbf4c14496ca299d31) pacific (stable)": 3
+	}
+}`
+
+func newScheme(t *testing.T) *runtime.Scheme {
+	s := runtime.NewScheme()
+	assert.NoError(t, v1.AddToScheme(s))
+	assert.NoError(t, cephv1.AddToScheme(s))
+	return s
+}
+
+func newNode(name, ip string) *v1.Node {
+	return &v1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Status: v1.NodeStatus{
+			Addresses: []v1.NodeAddress{{Type: v1.NodeInternalIP, Address: ip}, {Type: v1.NodeHostName, Address: name}},
+		},
+	}
+}
+
+func TestCephNetworkFenceController(t *testing.T) {
+	ctx := context.TODO()
+	var (
+		name      = "node-1"
+		namespace = "rook-ceph"
+	)
+
+	cephNetworkFence := &cephv1.CephNetworkFence{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       name,
+			Namespace:  namespace,
+			Finalizers: []string{"cephnetworkfence.ceph.rook.io"},
+		},
+		Spec: cephv1.NetworkFenceSpec{
+			NodeName: "node-1",
+			CIDRs:    []string{"192.168.1.10"},
+		},
+	}
+	cephCluster := &cephv1.CephCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      namespace,
+			Namespace: namespace,
+		},
+		Status: cephv1.ClusterStatus{
+			Phase: cephv1.ConditionReady,
+			CephVersion: &cephv1.ClusterVersion{
+				Version: "16.2.6-0",
+			},
+			CephStatus: &cephv1.CephStatus{
+				Health: "HEALTH_OK",
+			},
+		},
+	}
+
+	blocklist := map[string]bool{}
+	watchers := `{"watchers":[{"address":"10.244.1.7:0/3519486571","client":4123,"cookie":1},{"address":"10.0.0.6:0/12","client":4124,"cookie":2}]}`
+	executor := &exectest.MockExecutor{
+		MockExecuteCommandWithOutput: func(command string, args ...string) (string, error) {
+			if args[0] == "status" && command == "rbd" {
+				assert.Equal(t, "replicapool/csi-vol-1", args[1])
+				return watchers, nil
+			}
+			if args[0] == "status" {
+				return `{"fsid":"c47cac40-9bee-4d52-823b-ccd803ba5bfe","health":{"checks":{},"status":"HEALTH_OK"},"pgmap":{"num_pgs":100,"pgs_by_state":[{"state_name":"active+clean","count":100}]}}`, nil
+			}
+			if args[0] == "versions" {
+				return dummyVersionsRaw, nil
+			}
+			if args[0] == "osd" && args[1] == "blocklist" {
+				switch args[2] {
+				case "add":
+					blocklist[args[3]] = true
+				case "rm":
+					delete(blocklist, args[3])
+				}
+			}
+			return "", nil
+		},
+	}
+	c := &clusterd.Context{
+		Executor:      executor,
+		Clientset:     testop.New(t, 1),
+		RookClientset: rookclient.NewSimpleClientset(),
+	}
+
+	// Mock clusterInfo
+	secret := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "rook-ceph-mon",
+			Namespace: namespace,
+		},
+		Data: map[string][]byte{
+			"fsid":         []byte(name),
+			"mon-secret":   []byte("monsecret"),
+			"admin-secret": []byte("adminsecret"),
+		},
+		Type: k8sutil.RookType,
+	}
+	_, err := c.Clientset.CoreV1().Secrets(namespace).Create(ctx, secret, metav1.CreateOptions{})
+	assert.NoError(t, err)
+
+	// Mock the failed node and the rbd image attached to it
+	for _, node := range []*v1.Node{newNode("node-1", "10.0.0.5"), newNode("node-2", "10.0.0.6")} {
+		_, err = c.Clientset.CoreV1().Nodes().Create(ctx, node, metav1.CreateOptions{})
+		assert.NoError(t, err)
+	}
+	pv := &v1.PersistentVolume{
+		ObjectMeta: metav1.ObjectMeta{Name: "pvc-1"},
+		Spec: v1.PersistentVolumeSpec{
+			PersistentVolumeSource: v1.PersistentVolumeSource{
+				CSI: &v1.CSIPersistentVolumeSource{
+					Driver:           "rook-ceph.rbd.csi.ceph.com",
+					VolumeAttributes: map[string]string{"clusterID": namespace, "pool": "replicapool", "imageName": "csi-vol-1"},
+				},
+			},
+		},
+	}
+	_, err = c.Clientset.CoreV1().PersistentVolumes().Create(ctx, pv, metav1.CreateOptions{})
+	assert.NoError(t, err)
+	attachment := &storagev1.VolumeAttachment{
+		ObjectMeta: metav1.ObjectMeta{Name: "csi-123"},
+		Spec: storagev1.VolumeAttachmentSpec{
+			Attacher: "rook-ceph.rbd.csi.ceph.com",
+			NodeName: "node-1",
+			Source:   storagev1.VolumeAttachmentSource{PersistentVolumeName: &pv.Name},
+		},
+	}
+	_, err = c.Clientset.StorageV1().VolumeAttachments().Create(ctx, attachment, metav1.CreateOptions{})
+	assert.NoError(t, err)
+
+	cl := fake.NewClientBuilder().WithScheme(newScheme(t)).WithRuntimeObjects(cephNetworkFence, cephCluster).Build()
+	c.Client = cl
+	r := &ReconcileCephNetworkFence{
+		client:           cl,
+		context:          c,
+		opManagerContext: ctx,
+	}
+	req := reconcile.Request{NamespacedName: types.NamespacedName{Name: name, Namespace: namespace}}
+	getFence := func() *cephv1.CephNetworkFence {
+		fence := &cephv1.CephNetworkFence{}
+		err := cl.Get(ctx, req.NamespacedName, fence)
+		assert.NoError(t, err)
+		return fence
+	}
+	updateSpec := func(update func(spec *cephv1.NetworkFenceSpec)) {
+		fence := getFence()
+		update(&fence.Spec)
+		err := cl.Update(ctx, fence)
+		assert.NoError(t, err)
+	}
+
+	t.Run("fence the node", func(t *testing.T) {
+		res, err := r.Reconcile(ctx, req)
+		assert.NoError(t, err)
+		assert.False(t, res.Requeue)
+		// the client of the other node is not fenced
+		assert.Equal(t, map[string]bool{"10.0.0.5": true, "10.244.1.7": true, "192.168.1.10": true}, blocklist)
+
+		cephNetworkFence := getFence()
+		assert.Equal(t, cephv1.ConditionReady, cephNetworkFence.Status.Phase)
+		assert.Equal(t, []string{"10.0.0.5", "10.244.1.7", "192.168.1.10"}, cephNetworkFence.Status.FencedAddresses)
+		assert.Equal(t, []string{"10.0.0.5", "10.244.1.7"}, cephNetworkFence.Status.NodeAddresses)
+	})
+
+	t.Run("the clients of the node stay fenced", func(t *testing.T) {
+		// the watches of the fenced clients expired
+		watchers = `{"watchers":[]}`
+		updateSpec(func(spec *cephv1.NetworkFenceSpec) { spec.CIDRs = nil })
+		res, err := r.Reconcile(ctx, req)
+		assert.NoError(t, err)
+		assert.False(t, res.Requeue)
+		assert.Equal(t, map[string]bool{"10.0.0.5": true, "10.244.1.7": true}, blocklist)
+	})
+
+	t.Run("unfence", func(t *testing.T) {
+		updateSpec(func(spec *cephv1.NetworkFenceSpec) { spec.FenceState = cephv1.FenceStateUnfenced })
+		res, err := r.Reconcile(ctx, req)
+		assert.NoError(t, err)
+		assert.False(t, res.Requeue)
+		assert.Empty(t, blocklist)
+
+		cephNetworkFence := getFence()
+		assert.Empty(t, cephNetworkFence.Status.FencedAddresses)
+		assert.Empty(t, cephNetworkFence.Status.NodeAddresses)
+	})
+
+	t.Run("deletion", func(t *testing.T) {
+		updateSpec(func(spec *cephv1.NetworkFenceSpec) {
+			spec.FenceState = cephv1.FenceStateFenced
+			spec.CIDRs = []string{"192.168.1.10"}
+		})
+		res, err := r.Reconcile(ctx, req)
+		assert.NoError(t, err)
+		assert.False(t, res.Requeue)
+		assert.Equal(t, 2, len(blocklist))
+
+		cephNetworkFence := getFence()
+		now := metav1.Now()
+		cephNetworkFence.DeletionTimestamp = &now
+		err = cl.Update(ctx, cephNetworkFence)
+		assert.NoError(t, err)
+		res, err = r.Reconcile(ctx, req)
+		assert.NoError(t, err)
+		assert.False(t, res.Requeue)
+		assert.Empty(t, blocklist)
+		// the finalizer is removed
+		err = cl.Get(ctx, req.NamespacedName, &cephv1.CephNetworkFence{})
+		assert.True(t, kerrors.IsNotFound(err))
+	})
+}
+
+func TestNodeFencer(t *testing.T) {
+	ctx := context.TODO()
+	namespace := "rook-ceph"
+	cephCluster := &cephv1.CephCluster{ObjectMeta: metav1.ObjectMeta{Name: namespace, Namespace: namespace, UID: "123"}}
+	external := &cephv1.CephCluster{ObjectMeta: metav1.ObjectMeta{Name: "external", Namespace: "external"}}
+	external.Spec.External.Enable = true
+	node := newNode("node-1", "10.0.0.5")
+	node.Spec.Taints = []v1.Taint{{Key: OutOfServiceTaint, Value: "nodeshutdown", Effect: v1.TaintEffectNoExecute}}
+
+	s := newScheme(t)
+	cl := fake.NewClientBuilder().WithScheme(s).WithRuntimeObjects(cephCluster, external, node).Build()
+	r := &nodeFencer{client: cl, scheme: s, opManagerContext: ctx}
+	req := reconcile.Request{NamespacedName: types.NamespacedName{Name: node.Name}}
+	fenceName := types.NamespacedName{Name: "node-node-1", Namespace: namespace}
+
+	t.Run("the out-of-service node is fenced", func(t *testing.T) {
+		_, err := r.Reconcile(ctx, req)
+		assert.NoError(t, err)
+
+		fence := &cephv1.CephNetworkFence{}
+		err = cl.Get(ctx, fenceName, fence)
+		assert.NoError(t, err)
+		assert.Equal(t, "node-1", fence.Spec.NodeName)
+		assert.Equal(t, "true", fence.Labels[nodeFenceLabel])
+		assert.Equal(t, namespace, fence.OwnerReferences[0].Name)
+
+		// the external cluster is not fenced
+		fences := &cephv1.CephNetworkFenceList{}
+		err = cl.List(ctx, fences)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, len(fences.Items))
+	})
+
+	t.Run("the node is unfenced when back in service", func(t *testing.T) {
+		err := cl.Get(ctx, req.NamespacedName, node)
+		assert.NoError(t, err)
+		node.Spec.Taints = nil
+		err = cl.Update(ctx, node)
+		assert.NoError(t, err)
+
+		_, err = r.Reconcile(ctx, req)
+		assert.NoError(t, err)
+		err = cl.Get(ctx, fenceName, &cephv1.CephNetworkFence{})
+		assert.True(t, kerrors.IsNotFound(err))
+	})
+
+	t.Run("the fences created by hand are kept", func(t *testing.T) {
+		fence := &cephv1.CephNetworkFence{ObjectMeta: metav1.ObjectMeta{Name: fenceName.Name, Namespace: namespace}}
+		err := cl.Create(ctx, fence)
+		assert.NoError(t, err)
+
+		_, err = r.Reconcile(ctx, req)
+		assert.NoError(t, err)
+		err = cl.Get(ctx, fenceName, fence)
+		assert.NoError(t, err)
+	})
+}
+
+func TestNodeTaintPredicate(t *testing.T) {
+	p := nodeTaintPredicate()
+	node := newNode("node-1", "10.0.0.5")
+	tainted := node.DeepCopy()
+	tainted.Spec.Taints = []v1.Taint{{Key: OutOfServiceTaint, Effect: v1.TaintEffectNoExecute}}
+
+	assert.True(t, p.Update(event.UpdateEvent{ObjectOld: node, ObjectNew: tainted}))
+	assert.True(t, p.Update(event.UpdateEvent{ObjectOld: tainted, ObjectNew: node}))
+	assert.False(t, p.Update(event.UpdateEvent{ObjectOld: node, ObjectNew: node.DeepCopy()}))
+}
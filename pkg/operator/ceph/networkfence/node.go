@@ -0,0 +1,211 @@
+/*
+Copyright 2021 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package networkfence
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	"github.com/rook/rook/pkg/operator/k8sutil"
+	v1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+const (
+	nodeFencerName = "ceph-node-fencer"
+
+	// OutOfServiceTaint is the taint of the nodes that are shut down and won't come back as is
+	OutOfServiceTaint = "node.kubernetes.io/out-of-service"
+
+	// the label of the fences created for the out-of-service nodes
+	nodeFenceLabel = "ceph.rook.io/node-fence"
+)
+
+// nodeFencer creates a CephNetworkFence in each cluster for the nodes tainted out-of-service, and
+// deletes it when the taint is removed
+type nodeFencer struct {
+	client           client.Client
+	scheme           *runtime.Scheme
+	opManagerContext context.Context
+}
+
+func newNodeFencer(mgr manager.Manager, opManagerContext context.Context) reconcile.Reconciler {
+	return &nodeFencer{
+		client:           mgr.GetClient(),
+		scheme:           mgr.GetScheme(),
+		opManagerContext: opManagerContext,
+	}
+}
+
+func addNodeFencer(mgr manager.Manager, r reconcile.Reconciler) error {
+	// Create a new controller
+	c, err := controller.New(nodeFencerName, mgr, controller.Options{Reconciler: r})
+	if err != nil {
+		return err
+	}
+
+	// Watch for the nodes tainted out-of-service
+	err = c.Watch(&source.Kind{Type: &v1.Node{}}, &handler.EnqueueRequestForObject{}, nodeTaintPredicate())
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// nodeTaintPredicate reconciles the nodes when they are added, including on operator start, and when
+// the out-of-service taint is added or removed
+func nodeTaintPredicate() predicate.Funcs {
+	return predicate.Funcs{
+		CreateFunc: func(e event.CreateEvent) bool {
+			_, ok := e.Object.(*v1.Node)
+			return ok
+		},
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			oldNode, ok := e.ObjectOld.(*v1.Node)
+			if !ok {
+				return false
+			}
+			newNode, ok := e.ObjectNew.(*v1.Node)
+			if !ok {
+				return false
+			}
+			return isOutOfService(oldNode) != isOutOfService(newNode)
+		},
+		// the fences of a deleted node are kept until they are deleted
+		DeleteFunc: func(e event.DeleteEvent) bool {
+			return false
+		},
+		GenericFunc: func(e event.GenericEvent) bool {
+			return false
+		},
+	}
+}
+
+func isOutOfService(node *v1.Node) bool {
+	for _, taint := range node.Spec.Taints {
+		if taint.Key == OutOfServiceTaint {
+			return true
+		}
+	}
+	return false
+}
+
+// nodeFenceName returns the name of the fence of the node
+func nodeFenceName(nodeName string) string {
+	return k8sutil.TruncateNodeName("node-%s", nodeName)
+}
+
+// Reconcile fences the node in the clusters if it is out of service and unfences it otherwise
+func (r *nodeFencer) Reconcile(context context.Context, request reconcile.Request) (reconcile.Result, error) {
+	// workaround because the rook logging mechanism is not compatible with the controller-runtime logging interface
+	err := r.reconcile(request)
+	if err != nil {
+		logger.Errorf("failed to reconcile node %q. %v", request.Name, err)
+	}
+
+	return reconcile.Result{}, err
+}
+
+func (r *nodeFencer) reconcile(request reconcile.Request) error {
+	node := &v1.Node{}
+	err := r.client.Get(r.opManagerContext, types.NamespacedName{Name: request.Name}, node)
+	if err != nil {
+		if kerrors.IsNotFound(err) {
+			logger.Debugf("node %q not found. Ignoring since object must be deleted.", request.Name)
+			return nil
+		}
+		return errors.Wrapf(err, "failed to get node %q", request.Name)
+	}
+	outOfService := isOutOfService(node)
+
+	cephClusters := &cephv1.CephClusterList{}
+	err = r.client.List(r.opManagerContext, cephClusters)
+	if err != nil {
+		return errors.Wrap(err, "failed to list ceph clusters")
+	}
+
+	for i := range cephClusters.Items {
+		cephCluster := &cephClusters.Items[i]
+		// the clients of an external cluster are fenced by its admin
+		if cephCluster.Spec.External.Enable || !cephCluster.DeletionTimestamp.IsZero() {
+			continue
+		}
+
+		name := types.NamespacedName{Name: nodeFenceName(node.Name), Namespace: cephCluster.Namespace}
+		fence := &cephv1.CephNetworkFence{}
+		err := r.client.Get(r.opManagerContext, name, fence)
+		if err != nil && !kerrors.IsNotFound(err) {
+			return errors.Wrapf(err, "failed to get ceph network fence %q", name.String())
+		}
+		exists := err == nil
+
+		if outOfService && !exists {
+			if err := r.createNodeFence(cephCluster, node, name); err != nil {
+				return err
+			}
+		}
+
+		// the fence is removed once the node is back in service
+		if !outOfService && exists && fence.Labels[nodeFenceLabel] == "true" {
+			logger.Infof("node %q is back in service, deleting ceph network fence %q", node.Name, name.String())
+			err := r.client.Delete(r.opManagerContext, fence)
+			if err != nil && !kerrors.IsNotFound(err) {
+				return errors.Wrapf(err, "failed to delete ceph network fence %q", name.String())
+			}
+		}
+	}
+
+	return nil
+}
+
+func (r *nodeFencer) createNodeFence(cephCluster *cephv1.CephCluster, node *v1.Node, name types.NamespacedName) error {
+	fence := &cephv1.CephNetworkFence{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name.Name,
+			Namespace: name.Namespace,
+			Labels:    map[string]string{nodeFenceLabel: "true"},
+		},
+		Spec: cephv1.NetworkFenceSpec{
+			NodeName:   node.Name,
+			FenceState: cephv1.FenceStateFenced,
+		},
+	}
+	err := k8sutil.NewOwnerInfo(cephCluster, r.scheme).SetControllerReference(fence)
+	if err != nil {
+		return errors.Wrapf(err, "failed to set owner reference of ceph network fence %q", name.String())
+	}
+
+	logger.Infof("node %q is out of service, creating ceph network fence %q", node.Name, name.String())
+	err = r.client.Create(r.opManagerContext, fence)
+	if err != nil && !kerrors.IsAlreadyExists(err) {
+		return errors.Wrapf(err, "failed to create ceph network fence %q", name.String())
+	}
+	return nil
+}
@@ -59,14 +59,33 @@ func getGaneshaConfigObject(n *cephv1.CephNFS, version cephver.CephVersion, name
 	return fmt.Sprintf("conf-%s", getNFSNodeID(n, name))
 }
 
+// GaneshaConfigObjects returns the RADOS config objects watched by the ganesha servers of the CephNFS
+func GaneshaConfigObjects(n *cephv1.CephNFS, version cephver.CephVersion) []string {
+	objects := []string{}
+	for i := 0; i < n.Spec.Server.Active; i++ {
+		object := getGaneshaConfigObject(n, version, k8sutil.IndexToName(i))
+		// the servers share the same config object since octopus
+		if len(objects) > 0 && objects[len(objects)-1] == object {
+			continue
+		}
+		objects = append(objects, object)
+	}
+	return objects
+}
+
 func getRadosURL(n *cephv1.CephNFS, version cephver.CephVersion, name string) string {
+	return RadosObjectURL(n, getGaneshaConfigObject(n, version, name))
+}
+
+// RadosObjectURL returns the ganesha url of an object in the RADOS pool and namespace of the CephNFS
+func RadosObjectURL(n *cephv1.CephNFS, object string) string {
 	url := fmt.Sprintf("rados://%s/", n.Spec.RADOS.Pool)
 
 	if n.Spec.RADOS.Namespace != "" {
 		url += n.Spec.RADOS.Namespace + "/"
 	}
 
-	url += getGaneshaConfigObject(n, version, name)
+	url += object
 	return url
 }
 
@@ -108,14 +127,14 @@ MDCACHE {
 
 EXPORT_DEFAULTS {
 	Attr_Expiration_Time = 0;
-}
+` + getGaneshaExportDefaultsParams(n) + `}
 
 NFSv4 {
 	Delegations = false;
 	RecoveryBackend = 'rados_cluster';
 	Minor_Versions = 1, 2;
-}
-
+` + getGaneshaNFSv4Params(n) + `}
+` + getGaneshaKrb5Config(n) + `
 RADOS_KV {
 	ceph_conf = '` + cephclient.DefaultConfigFilePath() + `';
 	userid = ` + userID + `;
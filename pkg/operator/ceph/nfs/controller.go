@@ -132,6 +132,7 @@ func add(mgr manager.Manager, r reconcile.Reconciler) error {
 func (r *ReconcileCephNFS) Reconcile(context context.Context, request reconcile.Request) (reconcile.Result, error) {
 	// workaround because the rook logging mechanism is not compatible with the controller-runtime logging interface
 	reconcileResponse, err := r.reconcile(request)
+	reconcileResponse, err = opcontroller.RequeueIfCephCommandsUnavailable(reconcileResponse, err)
 	if err != nil {
 		logger.Errorf("failed to reconcile %v", err)
 	}
@@ -252,6 +253,17 @@ func (r *ReconcileCephNFS) reconcile(request reconcile.Request) (reconcile.Resul
 		return reconcile.Result{}, errors.Wrapf(err, "invalid ceph nfs %q arguments", cephNFS.Name)
 	}
 
+	// The clients of the failed servers reclaim their state once the failed servers are restarted
+	if ingressEnabled(cephNFS) {
+		failed, err := r.reconcileGraceForFailedServers(cephNFS)
+		if err != nil {
+			return reconcile.Result{}, errors.Wrap(err, "failed to check the ceph nfs servers")
+		}
+		if len(failed) > 0 {
+			logger.Warningf("ceph nfs %q servers %v are down", cephNFS.Name, failed)
+		}
+	}
+
 	// CREATE/UPDATE
 	logger.Debug("reconciling ceph nfs deployments")
 	_, err = r.reconcileCreateCephNFS(cephNFS)
@@ -260,9 +272,21 @@ func (r *ReconcileCephNFS) reconcile(request reconcile.Request) (reconcile.Resul
 		return reconcile.Result{}, errors.Wrap(err, "failed to create ceph nfs deployments")
 	}
 
+	err = r.reconcileIngress(cephNFS)
+	if err != nil {
+		updateStatus(r.client, request.NamespacedName, k8sutil.FailedStatus)
+		return reconcile.Result{}, errors.Wrap(err, "failed to reconcile ceph nfs ingress")
+	}
+
 	// Set Ready status, we are done reconciling
 	updateStatus(r.client, request.NamespacedName, k8sutil.ReadyStatus)
 
+	// Keep checking the servers behind the ingress for failures
+	if ingressEnabled(cephNFS) {
+		logger.Debug("done reconciling ceph nfs")
+		return waitForRequeueIngressCheck, nil
+	}
+
 	// Return and do not requeue
 	logger.Debug("done reconciling ceph nfs")
 	return reconcile.Result{}, nil
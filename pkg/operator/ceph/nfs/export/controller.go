@@ -0,0 +1,527 @@
+/*
+Copyright 2021 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package export to manage the exports of a CephNFS.
+package export
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/coreos/pkg/capnslog"
+	"github.com/pkg/errors"
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	"github.com/rook/rook/pkg/clusterd"
+	cephclient "github.com/rook/rook/pkg/daemon/ceph/client"
+	"github.com/rook/rook/pkg/operator/ceph/cluster/mon"
+	"github.com/rook/rook/pkg/operator/ceph/config"
+	opcontroller "github.com/rook/rook/pkg/operator/ceph/controller"
+	"github.com/rook/rook/pkg/operator/ceph/nfs"
+	"github.com/rook/rook/pkg/operator/ceph/reporting"
+	"github.com/rook/rook/pkg/operator/k8sutil"
+	v1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+const (
+	controllerName = "ceph-nfs-export-controller"
+
+	// maxExportIDAttempts is how many export ids are tried when the export objects are created concurrently
+	maxExportIDAttempts = 10
+)
+
+var logger = capnslog.NewPackageLogger("github.com/rook/rook", controllerName)
+
+var cephNFSExportKind = reflect.TypeOf(cephv1.CephNFSExport{}).Name()
+
+// Sets the type meta for the controller main object
+var controllerTypeMeta = metav1.TypeMeta{
+	Kind:       cephNFSExportKind,
+	APIVersion: fmt.Sprintf("%s/%s", cephv1.CustomResourceGroup, cephv1.Version),
+}
+
+// ReconcileCephNFSExport reconciles a CephNFSExport object
+type ReconcileCephNFSExport struct {
+	client           client.Client
+	scheme           *runtime.Scheme
+	context          *clusterd.Context
+	clusterInfo      *cephclient.ClusterInfo
+	opManagerContext context.Context
+}
+
+// Add creates a new CephNFSExport Controller and adds it to the Manager. The Manager will set fields
+// on the Controller and Start it when the Manager is Started.
+func Add(mgr manager.Manager, context *clusterd.Context, opManagerContext context.Context, opConfig opcontroller.OperatorConfig) error {
+	return add(mgr, newReconciler(mgr, context, opManagerContext))
+}
+
+// newReconciler returns a new reconcile.Reconciler
+func newReconciler(mgr manager.Manager, context *clusterd.Context, opManagerContext context.Context) reconcile.Reconciler {
+	return &ReconcileCephNFSExport{
+		client:           mgr.GetClient(),
+		scheme:           mgr.GetScheme(),
+		context:          context,
+		opManagerContext: opManagerContext,
+	}
+}
+
+func add(mgr manager.Manager, r reconcile.Reconciler) error {
+	// Create a new controller
+	c, err := controller.New(controllerName, mgr, controller.Options{Reconciler: r})
+	if err != nil {
+		return err
+	}
+	logger.Info("successfully started")
+
+	// Watch for changes on the CephNFSExport CRD object
+	err = c.Watch(&source.Kind{Type: &cephv1.CephNFSExport{TypeMeta: controllerTypeMeta}}, &handler.EnqueueRequestForObject{}, opcontroller.WatchControllerPredicate())
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Reconcile reads that state of the cluster for a CephNFSExport object and makes changes based on
+// the state read and what is in the CephNFSExport.Spec
+// The Controller will requeue the Request to be processed again if the returned error is non-nil or
+// Result.Requeue is true, otherwise upon completion it will remove the work from the queue.
+func (r *ReconcileCephNFSExport) Reconcile(context context.Context, request reconcile.Request) (reconcile.Result, error) {
+	// workaround because the rook logging mechanism is not compatible with the controller-runtime logging interface
+	reconcileResponse, err := r.reconcile(request)
+	reconcileResponse, err = opcontroller.RequeueIfCephCommandsUnavailable(reconcileResponse, err)
+	if err != nil {
+		logger.Errorf("failed to reconcile %v", err)
+	}
+
+	return reconcileResponse, err
+}
+
+func (r *ReconcileCephNFSExport) reconcile(request reconcile.Request) (reconcile.Result, error) {
+	// Fetch the CephNFSExport instance
+	cephNFSExport := &cephv1.CephNFSExport{}
+	err := r.client.Get(r.opManagerContext, request.NamespacedName, cephNFSExport)
+	if err != nil {
+		if kerrors.IsNotFound(err) {
+			logger.Debug("cephNFSExport resource not found. Ignoring since object must be deleted.")
+			return reconcile.Result{}, nil
+		}
+		// Error reading the object - requeue the request.
+		return reconcile.Result{}, errors.Wrap(err, "failed to get cephNFSExport")
+	}
+
+	// Set a finalizer so we can do cleanup before the object goes away
+	err = opcontroller.AddFinalizerIfNotPresent(r.client, cephNFSExport)
+	if err != nil {
+		return reconcile.Result{}, errors.Wrap(err, "failed to add finalizer")
+	}
+
+	// The CR was just created, initializing status fields
+	if cephNFSExport.Status == nil {
+		r.updateStatus(request.NamespacedName, cephv1.ConditionProgressing, nil)
+	}
+
+	// Make sure a CephCluster is present otherwise do nothing
+	cephCluster, isReadyToReconcile, cephClusterExists, reconcileResponse := opcontroller.IsReadyToReconcile(r.client, r.context, request.NamespacedName, controllerName)
+	if !isReadyToReconcile {
+		// This handles the case where the Ceph Cluster is gone and we want to delete that CR
+		// We skip the deleteExport() function since everything is gone already
+		//
+		// Also, only remove the finalizer if the CephCluster is gone
+		// If not, we should wait for it to be ready
+		// This handles the case where the operator is not ready to accept Ceph command but the cluster exists
+		if !cephNFSExport.GetDeletionTimestamp().IsZero() && !cephClusterExists {
+			// Remove finalizer
+			err = opcontroller.RemoveFinalizer(r.client, cephNFSExport)
+			if err != nil {
+				return opcontroller.ImmediateRetryResult, errors.Wrap(err, "failed to remove finalizer")
+			}
+
+			// Return and do not requeue. Successful deletion.
+			return reconcile.Result{}, nil
+		}
+		return reconcileResponse, nil
+	}
+
+	// Populate clusterInfo during each reconcile
+	r.clusterInfo, _, _, err = mon.LoadClusterInfo(r.context, r.opManagerContext, request.NamespacedName.Namespace)
+	if err != nil {
+		return reconcile.Result{}, errors.Wrap(err, "failed to populate cluster info")
+	}
+	r.clusterInfo.Context = r.opManagerContext
+	r.clusterInfo.NetworkSpec = cephCluster.Spec.Network
+
+	// The CephNFS must exist before its exports are created
+	cephNFS := &cephv1.CephNFS{}
+	cephNFSName := types.NamespacedName{Name: cephNFSExport.Spec.NFSName, Namespace: request.Namespace}
+	err = r.client.Get(r.opManagerContext, cephNFSName, cephNFS)
+	if err != nil {
+		if kerrors.IsNotFound(err) {
+			// the export was deleted along with its ganesha servers, only the cephx user is left
+			if !cephNFSExport.GetDeletionTimestamp().IsZero() {
+				if err := r.deleteCephFSUser(cephNFSExport); err != nil {
+					return reconcile.Result{}, err
+				}
+				err = opcontroller.RemoveFinalizer(r.client, cephNFSExport)
+				if err != nil {
+					return reconcile.Result{}, errors.Wrap(err, "failed to remove finalizer")
+				}
+				return reconcile.Result{}, nil
+			}
+			logger.Infof("waiting for the CephNFS %q of the export %q to be created", cephNFSName.String(), request.NamespacedName.String())
+			r.updateStatus(request.NamespacedName, cephv1.ConditionFailure, nil)
+			return opcontroller.WaitForRequeueIfCephClusterNotReady, nil
+		}
+		return reconcile.Result{}, errors.Wrapf(err, "failed to get CephNFS %q", cephNFSName.String())
+	}
+
+	// The name of the ganesha config objects depends on the ceph version
+	runningCephVersion, err := cephclient.LeastUptodateDaemonVersion(r.context, r.clusterInfo, config.MonType)
+	if err != nil {
+		if strings.Contains(err.Error(), opcontroller.UninitializedCephConfigError) {
+			logger.Info(opcontroller.OperatorNotInitializedMessage)
+			return opcontroller.WaitForRequeueIfOperatorNotInitialized, nil
+		}
+		return reconcile.Result{}, errors.Wrapf(err, "failed to retrieve current ceph %q version", config.MonType)
+	}
+	r.clusterInfo.CephVersion = runningCephVersion
+
+	// DELETE: the CR was deleted
+	if !cephNFSExport.GetDeletionTimestamp().IsZero() {
+		err = r.deleteExport(cephNFSExport, cephNFS)
+		if err != nil {
+			return reconcile.Result{}, errors.Wrapf(err, "failed to delete ceph nfs export %q", cephNFSExport.Name)
+		}
+
+		// Remove finalizer
+		err = opcontroller.RemoveFinalizer(r.client, cephNFSExport)
+		if err != nil {
+			return reconcile.Result{}, errors.Wrap(err, "failed to remove finalizer")
+		}
+
+		// Return and do not requeue. Successful deletion.
+		return reconcile.Result{}, nil
+	}
+
+	// validate the export settings
+	err = cephNFSExport.Spec.Validate()
+	if err != nil {
+		r.updateStatus(request.NamespacedName, cephv1.ConditionFailure, nil)
+		return reconcile.Result{}, errors.Wrapf(err, "invalid ceph nfs export %q", cephNFSExport.Name)
+	}
+
+	// The export object is reserved and the export id is saved in the status before the export is
+	// written so it is never allocated twice
+	exportID := 0
+	if cephNFSExport.Status != nil {
+		exportID = cephNFSExport.Status.ExportID
+	}
+	if exportID == 0 {
+		exportID, err = r.allocateExportID(cephNFSExport, cephNFS)
+		if err != nil {
+			r.updateStatus(request.NamespacedName, cephv1.ConditionFailure, nil)
+			return reconcile.Result{}, errors.Wrapf(err, "failed to allocate the id of ceph nfs export %q", cephNFSExport.Name)
+		}
+		if err := r.saveExportID(request.NamespacedName, exportID); err != nil {
+			// release the export object so the id can be allocated again
+			if err := removeObject(r.context, cephNFS, exportObjectName(exportID)); err != nil {
+				logger.Errorf("failed to release the id %d of ceph nfs export %q. %v", exportID, request.NamespacedName.String(), err)
+			}
+			return reconcile.Result{}, err
+		}
+	}
+
+	// Create or Update the export
+	changed, err := r.createOrUpdateExport(cephNFSExport, cephNFS, exportID)
+	if err != nil {
+		r.updateStatus(request.NamespacedName, cephv1.ConditionFailure, nil)
+		return reconcile.Result{}, errors.Wrapf(err, "failed to create or update ceph nfs export %q", cephNFSExport.Name)
+	}
+
+	// Reload the ganesha servers when the export changed or is not served by all of them yet, and
+	// check they serve the export
+	reload := changed || cephNFSExport.Status == nil || !cephNFSExport.Status.Active
+	exportStatus, err := r.reloadExport(cephNFS, exportID, reload)
+	if err != nil {
+		r.updateStatus(request.NamespacedName, cephv1.ConditionFailure, nil)
+		return reconcile.Result{}, errors.Wrapf(err, "failed to reload ceph nfs export %q", cephNFSExport.Name)
+	}
+	if !exportStatus.Active {
+		logger.Infof("waiting for ceph nfs export %q to be active on all the ganesha servers of %q", request.NamespacedName.String(), cephNFS.Name)
+		r.updateStatus(request.NamespacedName, cephv1.ConditionProgressing, exportStatus)
+		return opcontroller.WaitForRequeueIfCephClusterNotReady, nil
+	}
+
+	// Success! Let's update the status
+	r.updateStatus(request.NamespacedName, cephv1.ConditionReady, exportStatus)
+
+	// Return and do not requeue
+	logger.Debug("done reconciling")
+	return reconcile.Result{}, nil
+}
+
+// Write the export object and include it in the ganesha config objects. It returns whether the
+// export object or the ganesha config objects were changed.
+func (r *ReconcileCephNFSExport) createOrUpdateExport(cephNFSExport *cephv1.CephNFSExport, cephNFS *cephv1.CephNFS, exportID int) (bool, error) {
+	logger.Infof("creating ceph nfs export %q with id %d in namespace %q", cephNFSExport.Name, exportID, cephNFSExport.Namespace)
+
+	fsal, err := r.fsalBlock(cephNFSExport)
+	if err != nil {
+		return false, err
+	}
+
+	object := exportObjectName(exportID)
+	current, err := readObject(r.context, cephNFS, object)
+	if err != nil {
+		return false, errors.Wrapf(err, "failed to read ceph nfs export %q", cephNFSExport.Name)
+	}
+	block := generateExportBlock(cephNFSExport, cephNFS, exportID, fsal)
+	changed := block != current
+	if changed {
+		err = writeObject(r.context, cephNFS, object, block)
+		if err != nil {
+			return false, errors.Wrapf(err, "failed to write ceph nfs export %q", cephNFSExport.Name)
+		}
+	}
+
+	// the cephx user is not used anymore once the export is switched from a CephFS path to a bucket
+	if cephNFSExport.Spec.CephFS == nil && cephFSExportBlock(current) {
+		err = cephclient.AuthDelete(r.context, r.clusterInfo, "client."+cephFSUserID(cephNFSExport))
+		if err != nil {
+			return changed, errors.Wrapf(err, "failed to delete the cephx user of ceph nfs export %q", cephNFSExport.Name)
+		}
+	}
+
+	configChanged, err := addExportToConfig(r.context, cephNFS, r.clusterInfo.CephVersion, exportID)
+	if err != nil {
+		return changed, errors.Wrapf(err, "failed to add ceph nfs export %q to the ganesha config", cephNFSExport.Name)
+	}
+
+	return changed || configChanged, nil
+}
+
+// fsalBlock returns the FSAL block of the export with the credentials to access its source
+func (r *ReconcileCephNFSExport) fsalBlock(cephNFSExport *cephv1.CephNFSExport) (string, error) {
+	if cephNFSExport.Spec.CephFS != nil {
+		userName := "client." + cephFSUserID(cephNFSExport)
+		caps := cephFSUserCaps(cephNFSExport)
+		key, err := cephclient.AuthGetOrCreateKey(r.context, r.clusterInfo, userName, caps)
+		if err != nil {
+			return "", errors.Wrapf(err, "failed to create the cephx user of ceph nfs export %q", cephNFSExport.Name)
+		}
+		// the exported path may have changed
+		err = cephclient.AuthUpdateCaps(r.context, r.clusterInfo, userName, caps)
+		if err != nil {
+			return "", errors.Wrapf(err, "failed to update the caps of the cephx user of ceph nfs export %q", cephNFSExport.Name)
+		}
+		return cephFSFSALBlock(cephNFSExport, key), nil
+	}
+
+	secretName := cephNFSExport.Spec.RGW.UserSecretName
+	secret, err := r.context.Clientset.CoreV1().Secrets(cephNFSExport.Namespace).Get(r.opManagerContext, secretName, metav1.GetOptions{})
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to get the secret %q of the object store user of ceph nfs export %q", secretName, cephNFSExport.Name)
+	}
+	accessKey, secretKey := string(secret.Data[rgwUserSecretAccess]), string(secret.Data[rgwUserSecretSecret])
+	if accessKey == "" || secretKey == "" {
+		return "", errors.Errorf("the secret %q must have the keys %q and %q", secretName, rgwUserSecretAccess, rgwUserSecretSecret)
+	}
+	return rgwFSALBlock(cephNFSExport, accessKey, secretKey), nil
+}
+
+// reloadExport reloads the config of the running ganesha servers if reload is set and returns the
+// servers serving the export
+func (r *ReconcileCephNFSExport) reloadExport(cephNFS *cephv1.CephNFS, exportID int, reload bool) (*cephv1.NFSExportStatus, error) {
+	pods, err := r.ganeshaPods(cephNFS)
+	if err != nil {
+		return nil, err
+	}
+
+	exportStatus := &cephv1.NFSExportStatus{ExportID: exportID, ActiveServers: []string{}}
+	for _, pod := range pods {
+		if reload {
+			if _, err := execInGaneshaPod(r.context, pod.Namespace, pod.Name, ganeshaDBusCommand(ganeshaAdminPath, ganeshaReloadMethod)...); err != nil {
+				logger.Warningf("failed to reload the config of ganesha server %q. %v", pod.Name, err)
+				continue
+			}
+		}
+		output, err := execInGaneshaPod(r.context, pod.Namespace, pod.Name, ganeshaDBusCommand(ganeshaExportMgrPath, ganeshaShowMethod)...)
+		if err != nil {
+			logger.Warningf("failed to list the exports of ganesha server %q. %v", pod.Name, err)
+			continue
+		}
+		if exportActive(output, exportID) {
+			exportStatus.ActiveServers = append(exportStatus.ActiveServers, pod.Labels["instance"])
+		}
+	}
+	exportStatus.Active = len(pods) > 0 && len(exportStatus.ActiveServers) == len(pods)
+
+	return exportStatus, nil
+}
+
+// ganeshaPods returns the running ganesha pods of the CephNFS
+func (r *ReconcileCephNFSExport) ganeshaPods(cephNFS *cephv1.CephNFS) ([]v1.Pod, error) {
+	selector := fmt.Sprintf("%s=%s,%s=%s", k8sutil.AppAttr, nfs.AppName, "ceph_nfs", cephNFS.Name)
+	pods, err := r.context.Clientset.CoreV1().Pods(cephNFS.Namespace).List(r.opManagerContext, metav1.ListOptions{LabelSelector: selector})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to list the ganesha pods of ceph nfs %q", cephNFS.Name)
+	}
+
+	running := []v1.Pod{}
+	for _, pod := range pods.Items {
+		if pod.Status.Phase == v1.PodRunning {
+			running = append(running, pod)
+		}
+	}
+	return running, nil
+}
+
+// Remove the export from the ganesha servers and delete its objects and cephx user
+func (r *ReconcileCephNFSExport) deleteExport(cephNFSExport *cephv1.CephNFSExport, cephNFS *cephv1.CephNFS) error {
+	logger.Infof("deleting ceph nfs export object %q", cephNFSExport.Name)
+
+	if cephNFSExport.Status != nil && cephNFSExport.Status.ExportID != 0 {
+		exportID := cephNFSExport.Status.ExportID
+		pods, err := r.ganeshaPods(cephNFS)
+		if err != nil {
+			return err
+		}
+		for _, pod := range pods {
+			// the export is not served if the server has not reloaded its config yet
+			arg := fmt.Sprintf("uint16:%d", exportID)
+			if _, err := execInGaneshaPod(r.context, pod.Namespace, pod.Name, ganeshaDBusCommand(ganeshaExportMgrPath, ganeshaRemoveMethod, arg)...); err != nil {
+				logger.Warningf("failed to remove ceph nfs export %q from ganesha server %q. %v", cephNFSExport.Name, pod.Name, err)
+			}
+		}
+
+		err = removeExportFromConfig(r.context, cephNFS, r.clusterInfo.CephVersion, exportID)
+		if err != nil {
+			return errors.Wrapf(err, "failed to remove ceph nfs export %q from the ganesha config", cephNFSExport.Name)
+		}
+		err = removeObject(r.context, cephNFS, exportObjectName(exportID))
+		if err != nil {
+			return errors.Wrapf(err, "failed to remove ceph nfs export %q", cephNFSExport.Name)
+		}
+	}
+
+	if err := r.deleteCephFSUser(cephNFSExport); err != nil {
+		return err
+	}
+
+	logger.Infof("deleted ceph nfs export %q", cephNFSExport.Name)
+	return nil
+}
+
+func (r *ReconcileCephNFSExport) deleteCephFSUser(cephNFSExport *cephv1.CephNFSExport) error {
+	if cephNFSExport.Spec.CephFS == nil {
+		return nil
+	}
+	err := cephclient.AuthDelete(r.context, r.clusterInfo, "client."+cephFSUserID(cephNFSExport))
+	if err != nil {
+		return errors.Wrapf(err, "failed to delete the cephx user of ceph nfs export %q", cephNFSExport.Name)
+	}
+	return nil
+}
+
+// allocateExportID returns a new export id, unused by the export objects and by the other exports of
+// the CephNFS, whose export object is reserved
+func (r *ReconcileCephNFSExport) allocateExportID(cephNFSExport *cephv1.CephNFSExport, cephNFS *cephv1.CephNFS) (int, error) {
+	exports := &cephv1.CephNFSExportList{}
+	err := r.client.List(r.opManagerContext, exports, client.InNamespace(cephNFSExport.Namespace))
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to list the exports of ceph nfs %q", cephNFS.Name)
+	}
+	allocatedIDs := []int{}
+	for _, export := range exports.Items {
+		if export.Name != cephNFSExport.Name && export.Spec.NFSName == cephNFS.Name && export.Status != nil && export.Status.ExportID != 0 {
+			allocatedIDs = append(allocatedIDs, export.Status.ExportID)
+		}
+	}
+
+	for i := 0; i < maxExportIDAttempts; i++ {
+		exportID, err := nextExportID(r.context, cephNFS, allocatedIDs)
+		if err != nil {
+			return 0, err
+		}
+		reserved, err := reserveExportObject(r.context, cephNFS, exportID)
+		if err != nil {
+			return 0, err
+		}
+		if reserved {
+			return exportID, nil
+		}
+		logger.Debugf("export id %d of ceph nfs %q was allocated concurrently, trying the next one", exportID, cephNFS.Name)
+		allocatedIDs = append(allocatedIDs, exportID)
+	}
+	return 0, errors.Errorf("failed to reserve an export id after %d attempts", maxExportIDAttempts)
+}
+
+// saveExportID sets the allocated export id in the status of the export
+func (r *ReconcileCephNFSExport) saveExportID(name types.NamespacedName, exportID int) error {
+	cephNFSExport := &cephv1.CephNFSExport{}
+	if err := r.client.Get(r.opManagerContext, name, cephNFSExport); err != nil {
+		return errors.Wrapf(err, "failed to retrieve ceph nfs export %q to save its id", name)
+	}
+	if cephNFSExport.Status == nil {
+		cephNFSExport.Status = &cephv1.NFSExportStatus{}
+	}
+
+	cephNFSExport.Status.ExportID = exportID
+	if err := reporting.UpdateStatus(r.client, cephNFSExport); err != nil {
+		return errors.Wrapf(err, "failed to save the id %d of ceph nfs export %q", exportID, name)
+	}
+	return nil
+}
+
+// updateStatus updates an object with a given status. The export id and active servers are only
+// updated when exportStatus is set.
+func (r *ReconcileCephNFSExport) updateStatus(name types.NamespacedName, status cephv1.ConditionType, exportStatus *cephv1.NFSExportStatus) {
+	cephNFSExport := &cephv1.CephNFSExport{}
+	if err := r.client.Get(r.opManagerContext, name, cephNFSExport); err != nil {
+		if kerrors.IsNotFound(err) {
+			logger.Debug("CephNFSExport resource not found. Ignoring since object must be deleted.")
+			return
+		}
+		logger.Warningf("failed to retrieve ceph nfs export %q to update status to %q. %v", name, status, err)
+		return
+	}
+	if cephNFSExport.Status == nil {
+		cephNFSExport.Status = &cephv1.NFSExportStatus{}
+	}
+
+	cephNFSExport.Status.Phase = status
+	if exportStatus != nil {
+		cephNFSExport.Status.ExportID = exportStatus.ExportID
+		cephNFSExport.Status.Active = exportStatus.Active
+		cephNFSExport.Status.ActiveServers = exportStatus.ActiveServers
+	}
+	if err := reporting.UpdateStatus(r.client, cephNFSExport); err != nil {
+		logger.Errorf("failed to set ceph nfs export %q status to %q. %v", name, status, err)
+		return
+	}
+	logger.Debugf("ceph nfs export %q status updated to %q", name, status)
+}
@@ -0,0 +1,356 @@
+/*
+Copyright 2021 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package export
+
+import (
+	"context"
+	"io/ioutil"
+	"strings"
+	"syscall"
+	"testing"
+
+	"github.com/pkg/errors"
+
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	rookclient "github.com/rook/rook/pkg/client/clientset/versioned/fake"
+	"github.com/rook/rook/pkg/client/clientset/versioned/scheme"
+	"github.com/rook/rook/pkg/clusterd"
+	"github.com/rook/rook/pkg/operator/k8sutil"
+	testop "github.com/rook/rook/pkg/operator/test"
+	exectest "github.com/rook/rook/pkg/util/exec/test"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	kexec "k8s.io/utils/exec"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const dummyVersionsRaw = `
+{
+	"mon": {
+		"ceph version 16.2.6 (ee28fb57e47e9f88813e24bbf4c14496ca299d31) pacific (stable)": 3
+	}
+}`
+
+func TestCephNFSExportController(t *testing.T) {
+	ctx := context.TODO()
+	var (
+		name      = "share"
+		namespace = "rook-ceph"
+	)
+
+	cephNFSExport := &cephv1.CephNFSExport{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       name,
+			Namespace:  namespace,
+			UID:        types.UID("c47cac40-9bee-4d52-823b-ccd803ba5bfe"),
+			Finalizers: []string{"cephnfsexport.ceph.rook.io"},
+		},
+		Spec: cephv1.NFSExportSpec{
+			NFSName:    "my-nfs",
+			CephFS:     &cephv1.NFSExportCephFSSpec{FilesystemName: "myfs", Path: "/volumes"},
+			PseudoPath: "/share",
+		},
+		Status: &cephv1.NFSExportStatus{Phase: ""},
+	}
+	cephCluster := &cephv1.CephCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      namespace,
+			Namespace: namespace,
+		},
+		Status: cephv1.ClusterStatus{
+			Phase: cephv1.ConditionReady,
+			CephVersion: &cephv1.ClusterVersion{
+				Version: "16.2.6-0",
+			},
+			CephStatus: &cephv1.CephStatus{
+				Health: "HEALTH_OK",
+			},
+		},
+	}
+	cephNFS := &cephv1.CephNFS{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "my-nfs",
+			Namespace: namespace,
+		},
+		Spec: cephv1.NFSGaneshaSpec{
+			RADOS:  cephv1.GaneshaRADOSSpec{Pool: "nfs-ganesha", Namespace: "nfs-ns"},
+			Server: cephv1.GaneshaServerSpec{Active: 1},
+		},
+	}
+
+	// rados objects of the ceph nfs
+	objects := map[string]string{"conf-nfs.my-nfs": "", "export-3": ""}
+	cephCommands := []string{}
+	executor := &exectest.MockExecutor{
+		MockExecuteCommandWithOutput: func(command string, args ...string) (string, error) {
+			if args[0] == "status" {
+				return `{"fsid":"c47cac40-9bee-4d52-823b-ccd803ba5bfe","health":{"checks":{},"status":"HEALTH_OK"},"pgmap":{"num_pgs":100,"pgs_by_state":[{"state_name":"active+clean","count":100}]}}`, nil
+			}
+			if args[0] == "versions" {
+				return dummyVersionsRaw, nil
+			}
+			if args[0] == "auth" {
+				cephCommands = append(cephCommands, strings.Join(args[:3], " "))
+				return `{"key":"mysecretkey"}`, nil
+			}
+			if command == "rados" {
+				// skip the pool, namespace and config flags
+				switch args[6] {
+				case "ls":
+					names := []string{}
+					for object := range objects {
+						names = append(names, object)
+					}
+					return strings.Join(names, "\n"), nil
+				case "get":
+					return objects[args[7]], nil
+				}
+			}
+			return "", nil
+		},
+		MockExecuteCommand: func(command string, args ...string) error {
+			if command == "rados" {
+				switch args[6] {
+				case "create":
+					if _, ok := objects[args[7]]; ok {
+						return &kexec.CodeExitError{Err: errors.New("object exists"), Code: int(syscall.EEXIST)}
+					}
+					objects[args[7]] = ""
+				case "put":
+					content, err := ioutil.ReadFile(args[8])
+					assert.NoError(t, err)
+					objects[args[7]] = string(content)
+				case "rm":
+					delete(objects, args[7])
+				}
+			}
+			return nil
+		},
+	}
+	c := &clusterd.Context{
+		Executor:      executor,
+		Clientset:     testop.New(t, 1),
+		RookClientset: rookclient.NewSimpleClientset(),
+	}
+
+	// Mock clusterInfo
+	secret := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "rook-ceph-mon",
+			Namespace: namespace,
+		},
+		Data: map[string][]byte{
+			"fsid":         []byte(name),
+			"mon-secret":   []byte("monsecret"),
+			"admin-secret": []byte("adminsecret"),
+		},
+		Type: k8sutil.RookType,
+	}
+	_, err := c.Clientset.CoreV1().Secrets(namespace).Create(ctx, secret, metav1.CreateOptions{})
+	assert.NoError(t, err)
+
+	// Mock the ganesha server
+	pod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "rook-ceph-nfs-my-nfs-a-123",
+			Namespace: namespace,
+			Labels:    map[string]string{k8sutil.AppAttr: "rook-ceph-nfs", "ceph_nfs": "my-nfs", "instance": "a"},
+		},
+		Status: v1.PodStatus{Phase: v1.PodRunning},
+	}
+	_, err = c.Clientset.CoreV1().Pods(namespace).Create(ctx, pod, metav1.CreateOptions{})
+	assert.NoError(t, err)
+	dbusMethods := []string{}
+	served := ""
+	execInGaneshaPod = func(context *clusterd.Context, namespace, podName string, command ...string) (string, error) {
+		assert.Equal(t, pod.Name, podName)
+		dbusMethods = append(dbusMethods, strings.Join(command[5:], " "))
+		if command[5] == ganeshaShowMethod {
+			return served, nil
+		}
+		return "", nil
+	}
+
+	s := scheme.Scheme
+	s.AddKnownTypes(cephv1.SchemeGroupVersion, &cephv1.CephNFSExport{}, &cephv1.CephNFSExportList{},
+		&cephv1.CephCluster{}, &cephv1.CephClusterList{}, &cephv1.CephNFS{}, &cephv1.CephNFSList{})
+
+	newReconcile := func(objects ...runtime.Object) *ReconcileCephNFSExport {
+		cl := fake.NewClientBuilder().WithScheme(s).WithRuntimeObjects(objects...).Build()
+		c.Client = cl
+		return &ReconcileCephNFSExport{
+			client:           cl,
+			scheme:           s,
+			context:          c,
+			opManagerContext: ctx,
+		}
+	}
+	req := reconcile.Request{NamespacedName: types.NamespacedName{Name: name, Namespace: namespace}}
+
+	t.Run("waiting for the ceph nfs", func(t *testing.T) {
+		r := newReconcile(cephNFSExport, cephCluster)
+		res, err := r.Reconcile(ctx, req)
+		assert.NoError(t, err)
+		assert.True(t, res.Requeue)
+		assert.Empty(t, dbusMethods)
+	})
+
+	t.Run("export not active yet", func(t *testing.T) {
+		r := newReconcile(cephNFSExport, cephCluster, cephNFS)
+		res, err := r.Reconcile(ctx, req)
+		assert.NoError(t, err)
+		assert.True(t, res.Requeue)
+		assert.Equal(t, []string{"auth get-or-create-key client.nfs-export.my-nfs.share", "auth caps client.nfs-export.my-nfs.share"}, cephCommands)
+		assert.Contains(t, objects["export-4"], "Export_ID = 4;")
+		assert.Contains(t, objects["export-4"], `Secret_Access_Key = "mysecretkey";`)
+		assert.Equal(t, "%url \"rados://nfs-ganesha/nfs-ns/export-4\"\n", objects["conf-nfs.my-nfs"])
+		assert.Equal(t, []string{ganeshaReloadMethod, ganeshaShowMethod}, dbusMethods)
+
+		err = r.client.Get(ctx, req.NamespacedName, cephNFSExport)
+		assert.NoError(t, err)
+		assert.Equal(t, cephv1.ConditionProgressing, cephNFSExport.Status.Phase)
+		assert.Equal(t, 4, cephNFSExport.Status.ExportID)
+		assert.False(t, cephNFSExport.Status.Active)
+	})
+
+	t.Run("success", func(t *testing.T) {
+		dbusMethods = []string{}
+		served = "method return\n   array [\n      struct {\n         uint16 4\n         string \"/volumes\"\n"
+		r := newReconcile(cephNFSExport, cephCluster, cephNFS)
+		res, err := r.Reconcile(ctx, req)
+		assert.NoError(t, err)
+		assert.False(t, res.Requeue)
+		// the export id is kept and the config is not changed
+		assert.Equal(t, "%url \"rados://nfs-ganesha/nfs-ns/export-4\"\n", objects["conf-nfs.my-nfs"])
+
+		err = r.client.Get(ctx, req.NamespacedName, cephNFSExport)
+		assert.NoError(t, err)
+		assert.Equal(t, cephv1.ConditionReady, cephNFSExport.Status.Phase)
+		assert.Equal(t, 4, cephNFSExport.Status.ExportID)
+		assert.True(t, cephNFSExport.Status.Active)
+		assert.Equal(t, []string{"a"}, cephNFSExport.Status.ActiveServers)
+	})
+
+	t.Run("unchanged", func(t *testing.T) {
+		dbusMethods = []string{}
+		r := newReconcile(cephNFSExport, cephCluster, cephNFS)
+		res, err := r.Reconcile(ctx, req)
+		assert.NoError(t, err)
+		assert.False(t, res.Requeue)
+		// the ganesha servers are not reloaded when the export did not change
+		assert.Equal(t, []string{ganeshaShowMethod}, dbusMethods)
+	})
+
+	t.Run("switch to a bucket", func(t *testing.T) {
+		dbusMethods = []string{}
+		cephCommands = []string{}
+		userSecret := &v1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "rgw-user", Namespace: namespace},
+			Data:       map[string][]byte{"AccessKey": []byte("access"), "SecretKey": []byte("secret")},
+		}
+		_, err := c.Clientset.CoreV1().Secrets(namespace).Create(ctx, userSecret, metav1.CreateOptions{})
+		assert.NoError(t, err)
+		bucketExport := cephNFSExport.DeepCopy()
+		bucketExport.Spec.CephFS = nil
+		bucketExport.Spec.RGW = &cephv1.NFSExportRGWSpec{Bucket: "bucket", UserID: "user", UserSecretName: userSecret.Name}
+		r := newReconcile(bucketExport, cephCluster, cephNFS)
+		res, err := r.Reconcile(ctx, req)
+		assert.NoError(t, err)
+		assert.False(t, res.Requeue)
+		assert.Contains(t, objects["export-4"], `Name = "RGW";`)
+		// the cephx user of the cephfs export is deleted
+		assert.Equal(t, []string{"auth del client.nfs-export.my-nfs.share"}, cephCommands)
+		assert.Equal(t, []string{ganeshaReloadMethod, ganeshaShowMethod}, dbusMethods)
+	})
+
+	t.Run("deletion", func(t *testing.T) {
+		dbusMethods = []string{}
+		cephCommands = []string{}
+		now := metav1.Now()
+		cephNFSExport.DeletionTimestamp = &now
+		r := newReconcile(cephNFSExport, cephCluster, cephNFS)
+		res, err := r.Reconcile(ctx, req)
+		assert.NoError(t, err)
+		assert.False(t, res.Requeue)
+		assert.Equal(t, []string{ganeshaRemoveMethod + " uint16:4"}, dbusMethods)
+		assert.Equal(t, map[string]string{"conf-nfs.my-nfs": "", "export-3": ""}, objects)
+		assert.Equal(t, []string{"auth del client.nfs-export.my-nfs.share"}, cephCommands)
+	})
+}
+
+func TestAllocateExportID(t *testing.T) {
+	ctx := context.TODO()
+	namespace := "rook-ceph"
+	cephNFS := &cephv1.CephNFS{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-nfs", Namespace: namespace},
+		Spec:       cephv1.NFSGaneshaSpec{RADOS: cephv1.GaneshaRADOSSpec{Pool: "nfs-ganesha", Namespace: "nfs-ns"}},
+	}
+	newExport := func(name, nfsName string, exportID int) *cephv1.CephNFSExport {
+		return &cephv1.CephNFSExport{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+			Spec:       cephv1.NFSExportSpec{NFSName: nfsName},
+			Status:     &cephv1.NFSExportStatus{ExportID: exportID},
+		}
+	}
+
+	// export-6 is created by another writer between the listing and the reservation
+	objects := map[string]string{"conf-nfs.my-nfs": "", "export-3": ""}
+	listed := map[string]string{"conf-nfs.my-nfs": "", "export-3": ""}
+	objects["export-6"] = ""
+	executor := &exectest.MockExecutor{
+		MockExecuteCommandWithOutput: func(command string, args ...string) (string, error) {
+			names := []string{}
+			for object := range listed {
+				names = append(names, object)
+			}
+			return strings.Join(names, "\n"), nil
+		},
+		MockExecuteCommand: func(command string, args ...string) error {
+			assert.Equal(t, "create", args[6])
+			if _, ok := objects[args[7]]; ok {
+				return &kexec.CodeExitError{Err: errors.New("object exists"), Code: int(syscall.EEXIST)}
+			}
+			objects[args[7]] = ""
+			return nil
+		},
+	}
+
+	s := scheme.Scheme
+	s.AddKnownTypes(cephv1.SchemeGroupVersion, &cephv1.CephNFSExport{}, &cephv1.CephNFSExportList{})
+	export := newExport("share", "my-nfs", 0)
+	cl := fake.NewClientBuilder().WithScheme(s).WithRuntimeObjects(
+		export,
+		// the id of an export is allocated before its export object is written
+		newExport("other", "my-nfs", 5),
+		newExport("other-nfs", "your-nfs", 9),
+	).Build()
+	r := &ReconcileCephNFSExport{
+		client:           cl,
+		context:          &clusterd.Context{Executor: executor},
+		opManagerContext: ctx,
+	}
+
+	exportID, err := r.allocateExportID(export, cephNFS)
+	assert.NoError(t, err)
+	assert.Equal(t, 7, exportID)
+	_, ok := objects["export-7"]
+	assert.True(t, ok)
+}
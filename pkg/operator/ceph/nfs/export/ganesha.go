@@ -0,0 +1,345 @@
+/*
+Copyright 2021 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package export
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"github.com/pkg/errors"
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	"github.com/rook/rook/pkg/clusterd"
+	cephclient "github.com/rook/rook/pkg/daemon/ceph/client"
+	"github.com/rook/rook/pkg/operator/ceph/nfs"
+	cephver "github.com/rook/rook/pkg/operator/ceph/version"
+	"github.com/rook/rook/pkg/util"
+	"github.com/rook/rook/pkg/util/exec"
+)
+
+const (
+	exportObjectPrefix = "export-"
+
+	ganeshaDBusDest      = "org.ganesha.nfsd"
+	ganeshaAdminPath     = "/org/ganesha/nfsd/admin"
+	ganeshaExportMgrPath = "/org/ganesha/nfsd/ExportMgr"
+	ganeshaReloadMethod  = "org.ganesha.nfsd.admin.reload"
+	ganeshaShowMethod    = "org.ganesha.nfsd.exportmgr.ShowExports"
+	ganeshaRemoveMethod  = "org.ganesha.nfsd.exportmgr.RemoveExport"
+	defaultExportPath    = "/"
+	cephFSUserPrefix     = "nfs-export"
+	rgwUserSecretAccess  = "AccessKey"
+	rgwUserSecretSecret  = "SecretKey"
+	ganeshaDefaultSquash = "Root_Squash"
+	// the security flavor of the exports when the ganesha servers do not use Kerberos
+	ganeshaDefaultSecType = "sys"
+)
+
+// execInGaneshaPod runs a command in the dbus sidecar of a ganesha pod. It is a variable so that
+// the unit tests can mock the remote execution.
+var execInGaneshaPod = func(context *clusterd.Context, namespace, podName string, command ...string) (string, error) {
+	stdout, stderr, err := context.RemoteExecutor.ExecWithOptions(exec.ExecOptions{
+		Command:       command,
+		Namespace:     namespace,
+		PodName:       podName,
+		ContainerName: nfs.DBusContainerName,
+		CaptureStdout: true,
+		CaptureStderr: true,
+	})
+	if err != nil {
+		return stdout, errors.Wrapf(err, "failed to run %q in pod %q. %s", strings.Join(command, " "), podName, stderr)
+	}
+	return stdout, nil
+}
+
+// ganeshaDBusCommand returns the dbus-send command calling a method of the ganesha dbus interface
+func ganeshaDBusCommand(path, method string, args ...string) []string {
+	return append([]string{"dbus-send", "--system", "--print-reply", "--dest=" + ganeshaDBusDest, path, method}, args...)
+}
+
+// exportObjectName returns the name of the RADOS object holding the export block
+func exportObjectName(exportID int) string {
+	return fmt.Sprintf("%s%d", exportObjectPrefix, exportID)
+}
+
+// exportURLLine returns the line including the export object in the ganesha config objects
+func exportURLLine(n *cephv1.CephNFS, exportID int) string {
+	return fmt.Sprintf("%%url \"%s\"", nfs.RadosObjectURL(n, exportObjectName(exportID)))
+}
+
+// cephFSUserID returns the cephx user id, without the "client." prefix, accessing the exported
+// CephFS path
+func cephFSUserID(export *cephv1.CephNFSExport) string {
+	return fmt.Sprintf("%s.%s.%s", cephFSUserPrefix, export.Spec.NFSName, export.Name)
+}
+
+// cephFSUserCaps returns the caps of the cephx user accessing the exported CephFS path
+func cephFSUserCaps(export *cephv1.CephNFSExport) []string {
+	return []string{
+		"mon", "allow r",
+		"mds", fmt.Sprintf("allow rw path=%s", exportPath(export)),
+		"osd", fmt.Sprintf("allow rw tag cephfs data=%s", export.Spec.CephFS.FilesystemName),
+	}
+}
+
+// exportPath returns the exported path in the source of the export
+func exportPath(export *cephv1.CephNFSExport) string {
+	if export.Spec.RGW != nil {
+		return export.Spec.RGW.Bucket
+	}
+	if export.Spec.CephFS.Path == "" {
+		return defaultExportPath
+	}
+	return export.Spec.CephFS.Path
+}
+
+// ganeshaSquash returns the ganesha squash setting of the export
+func ganeshaSquash(squash cephv1.NFSExportSquash) string {
+	switch squash {
+	case cephv1.NFSExportSquashNone:
+		return "No_Root_Squash"
+	case cephv1.NFSExportSquashAll:
+		return "All_Squash"
+	case cephv1.NFSExportSquashRootID:
+		return "Root_Id_Squash"
+	}
+	return ganeshaDefaultSquash
+}
+
+// generateExportBlock returns the ganesha EXPORT block of the export. The fsal block holds the
+// credentials of the user accessing the source of the export.
+func generateExportBlock(export *cephv1.CephNFSExport, n *cephv1.CephNFS, exportID int, fsal string) string {
+	spec := export.Spec
+	accessType := spec.AccessType
+	if accessType == "" {
+		accessType = cephv1.NFSExportAccessReadWrite
+	}
+	secTypes := []string{}
+	for _, flavor := range spec.SecurityFlavors {
+		secTypes = append(secTypes, string(flavor))
+	}
+	if len(secTypes) == 0 && !nfs.KerberosEnabled(n) {
+		secTypes = append(secTypes, ganeshaDefaultSecType)
+	}
+
+	var b strings.Builder
+	b.WriteString("EXPORT {\n")
+	fmt.Fprintf(&b, "\tExport_ID = %d;\n", exportID)
+	fmt.Fprintf(&b, "\tPath = %q;\n", exportPath(export))
+	fmt.Fprintf(&b, "\tPseudo = %q;\n", spec.PseudoPath)
+	if len(spec.AllowedClients) > 0 {
+		// only the allowed clients are granted access
+		fmt.Fprintf(&b, "\tAccess_Type = %q;\n", cephv1.NFSExportAccessNone)
+	} else {
+		fmt.Fprintf(&b, "\tAccess_Type = %q;\n", accessType)
+	}
+	fmt.Fprintf(&b, "\tSquash = %q;\n", ganeshaSquash(spec.Squash))
+	b.WriteString("\tProtocols = 4;\n")
+	b.WriteString("\tTransports = \"TCP\";\n")
+	if len(secTypes) > 0 {
+		// the Kerberos flavors of the ganesha servers are used otherwise
+		fmt.Fprintf(&b, "\tSecType = %s;\n", strings.Join(secTypes, ", "))
+	}
+	b.WriteString(fsal)
+	if len(spec.AllowedClients) > 0 {
+		b.WriteString("\tCLIENT {\n")
+		fmt.Fprintf(&b, "\t\tClients = %s;\n", strings.Join(spec.AllowedClients, ", "))
+		fmt.Fprintf(&b, "\t\tAccess_Type = %q;\n", accessType)
+		b.WriteString("\t}\n")
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// cephFSFSALBlock returns the FSAL block of an export of a CephFS path
+func cephFSFSALBlock(export *cephv1.CephNFSExport, key string) string {
+	return fmt.Sprintf("\tFSAL {\n\t\tName = \"CEPH\";\n\t\tUser_Id = %q;\n\t\tSecret_Access_Key = %q;\n\t\tFilesystem = %q;\n\t}\n",
+		cephFSUserID(export), key, export.Spec.CephFS.FilesystemName)
+}
+
+// cephFSExportBlock returns whether an export block exports a CephFS path
+func cephFSExportBlock(block string) bool {
+	return strings.Contains(block, "\t\tName = \"CEPH\";\n")
+}
+
+// rgwFSALBlock returns the FSAL block of an export of an object store bucket
+func rgwFSALBlock(export *cephv1.CephNFSExport, accessKey, secretKey string) string {
+	return fmt.Sprintf("\tFSAL {\n\t\tName = \"RGW\";\n\t\tUser_Id = %q;\n\t\tAccess_Key_Id = %q;\n\t\tSecret_Access_Key = %q;\n\t}\n",
+		export.Spec.RGW.UserID, accessKey, secretKey)
+}
+
+// radosArgs returns the arguments to access the RADOS pool and namespace of the CephNFS
+func radosArgs(context *clusterd.Context, n *cephv1.CephNFS, args ...string) []string {
+	return append([]string{
+		"--pool", n.Spec.RADOS.Pool,
+		"--namespace", n.Spec.RADOS.Namespace,
+		"--conf", cephclient.CephConfFilePath(context.ConfigDir, n.Namespace),
+	}, args...)
+}
+
+// nextExportID returns the lowest export id greater than the ids of the export objects of the CephNFS
+// and than the ids already allocated to other exports
+func nextExportID(context *clusterd.Context, n *cephv1.CephNFS, allocatedIDs []int) (int, error) {
+	output, err := context.Executor.ExecuteCommandWithOutput("rados", radosArgs(context, n, "ls")...)
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to list the objects of ceph nfs %q", n.Name)
+	}
+
+	exportID := 1
+	for _, id := range allocatedIDs {
+		if id >= exportID {
+			exportID = id + 1
+		}
+	}
+	for _, object := range strings.Split(output, "\n") {
+		if !strings.HasPrefix(object, exportObjectPrefix) {
+			continue
+		}
+		id, err := strconv.Atoi(strings.TrimPrefix(object, exportObjectPrefix))
+		if err != nil {
+			continue
+		}
+		if id >= exportID {
+			exportID = id + 1
+		}
+	}
+	return exportID, nil
+}
+
+// reserveExportObject creates the empty export object of an export id. The creation is exclusive,
+// false is returned when the object already exists and the id belongs to another export.
+func reserveExportObject(context *clusterd.Context, n *cephv1.CephNFS, exportID int) (bool, error) {
+	object := exportObjectName(exportID)
+	err := context.Executor.ExecuteCommand("rados", radosArgs(context, n, "create", object)...)
+	if err != nil {
+		if code, ok := exec.ExitStatus(err); ok && code == int(syscall.EEXIST) {
+			return false, nil
+		}
+		return false, errors.Wrapf(err, "failed to create rados object %q", object)
+	}
+	return true, nil
+}
+
+// readObject returns the content of a RADOS object of the CephNFS
+func readObject(context *clusterd.Context, n *cephv1.CephNFS, object string) (string, error) {
+	output, err := context.Executor.ExecuteCommandWithOutput("rados", radosArgs(context, n, "get", object, "-")...)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to read rados object %q", object)
+	}
+	return output, nil
+}
+
+// writeObject writes the content of a RADOS object of the CephNFS
+func writeObject(context *clusterd.Context, n *cephv1.CephNFS, object, content string) error {
+	file, err := util.CreateTempFile(content)
+	if err != nil {
+		return errors.Wrapf(err, "failed to create the content file of rados object %q", object)
+	}
+	defer os.Remove(file.Name())
+
+	err = context.Executor.ExecuteCommand("rados", radosArgs(context, n, "put", object, file.Name())...)
+	if err != nil {
+		return errors.Wrapf(err, "failed to write rados object %q", object)
+	}
+	return nil
+}
+
+// removeObject removes a RADOS object of the CephNFS. It succeeds if the object does not exist.
+func removeObject(context *clusterd.Context, n *cephv1.CephNFS, object string) error {
+	err := context.Executor.ExecuteCommand("rados", radosArgs(context, n, "rm", object)...)
+	if err != nil {
+		if code, ok := exec.ExitStatus(err); ok && code == int(syscall.ENOENT) {
+			return nil
+		}
+		return errors.Wrapf(err, "failed to remove rados object %q", object)
+	}
+	return nil
+}
+
+// addExportToConfig includes the export object in the ganesha config objects of the CephNFS. It
+// returns whether a config object was changed.
+func addExportToConfig(context *clusterd.Context, n *cephv1.CephNFS, version cephver.CephVersion, exportID int) (bool, error) {
+	line := exportURLLine(n, exportID)
+	return updateConfigObjects(context, n, version, func(lines []string) []string {
+		for _, l := range lines {
+			if l == line {
+				return lines
+			}
+		}
+		return append(lines, line)
+	})
+}
+
+// removeExportFromConfig removes the export object from the ganesha config objects of the CephNFS
+func removeExportFromConfig(context *clusterd.Context, n *cephv1.CephNFS, version cephver.CephVersion, exportID int) error {
+	line := exportURLLine(n, exportID)
+	_, err := updateConfigObjects(context, n, version, func(lines []string) []string {
+		updated := []string{}
+		for _, l := range lines {
+			if l != line {
+				updated = append(updated, l)
+			}
+		}
+		return updated
+	})
+	return err
+}
+
+// updateConfigObjects rewrites the ganesha config objects of the CephNFS whose lines are changed. The
+// blank lines are dropped. It returns whether a config object was changed.
+func updateConfigObjects(context *clusterd.Context, n *cephv1.CephNFS, version cephver.CephVersion, update func([]string) []string) (bool, error) {
+	changed := false
+	for _, object := range nfs.GaneshaConfigObjects(n, version) {
+		content, err := readObject(context, n, object)
+		if err != nil {
+			return changed, err
+		}
+		lines := []string{}
+		for _, line := range strings.Split(content, "\n") {
+			if strings.TrimSpace(line) != "" {
+				lines = append(lines, line)
+			}
+		}
+		updated := update(lines)
+		if reflect.DeepEqual(lines, updated) {
+			continue
+		}
+		content = strings.Join(updated, "\n")
+		if content != "" {
+			content += "\n"
+		}
+		if err := writeObject(context, n, object, content); err != nil {
+			return changed, err
+		}
+		changed = true
+	}
+	return changed, nil
+}
+
+// exportActive returns whether the export is listed by the ShowExports output of a ganesha server
+func exportActive(showExportsOutput string, exportID int) bool {
+	id := fmt.Sprintf("uint16 %d", exportID)
+	for _, line := range strings.Split(showExportsOutput, "\n") {
+		if strings.TrimSpace(line) == id {
+			return true
+		}
+	}
+	return false
+}
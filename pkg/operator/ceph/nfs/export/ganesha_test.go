@@ -0,0 +1,164 @@
+/*
+Copyright 2021 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package export
+
+import (
+	"io/ioutil"
+	"testing"
+
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	"github.com/rook/rook/pkg/clusterd"
+	cephver "github.com/rook/rook/pkg/operator/ceph/version"
+	exectest "github.com/rook/rook/pkg/util/exec/test"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestGenerateExportBlock(t *testing.T) {
+	export := &cephv1.CephNFSExport{
+		ObjectMeta: metav1.ObjectMeta{Name: "share", Namespace: "rook-ceph"},
+		Spec: cephv1.NFSExportSpec{
+			NFSName:    "my-nfs",
+			CephFS:     &cephv1.NFSExportCephFSSpec{FilesystemName: "myfs"},
+			PseudoPath: "/share",
+		},
+	}
+
+	cephNFS := &cephv1.CephNFS{ObjectMeta: metav1.ObjectMeta{Name: "my-nfs", Namespace: "rook-ceph"}}
+
+	// defaults
+	block := generateExportBlock(export, cephNFS, 1, cephFSFSALBlock(export, "key"))
+	assert.Equal(t, `EXPORT {
+	Export_ID = 1;
+	Path = "/";
+	Pseudo = "/share";
+	Access_Type = "RW";
+	Squash = "Root_Squash";
+	Protocols = 4;
+	Transports = "TCP";
+	SecType = sys;
+	FSAL {
+		Name = "CEPH";
+		User_Id = "nfs-export.my-nfs.share";
+		Secret_Access_Key = "key";
+		Filesystem = "myfs";
+	}
+}
+`, block)
+
+	// the security flavors of the kerberized servers are used by default
+	cephNFS.Spec.Security = &cephv1.NFSSecuritySpec{Kerberos: &cephv1.NFSKerberosSpec{PrincipalName: "nfs"}}
+	block = generateExportBlock(export, cephNFS, 1, cephFSFSALBlock(export, "key"))
+	assert.NotContains(t, block, "SecType")
+
+	// the access is only granted to the allowed clients
+	export.Spec.RGW = &cephv1.NFSExportRGWSpec{Bucket: "my-bucket", UserID: "my-user"}
+	export.Spec.CephFS = nil
+	export.Spec.AccessType = cephv1.NFSExportAccessReadOnly
+	export.Spec.Squash = cephv1.NFSExportSquashNone
+	export.Spec.AllowedClients = []string{"10.0.0.0/8", "192.168.1.5"}
+	export.Spec.SecurityFlavors = []cephv1.NFSSecurityFlavor{"krb5", "krb5p"}
+	block = generateExportBlock(export, cephNFS, 2, rgwFSALBlock(export, "access", "secret"))
+	assert.Equal(t, `EXPORT {
+	Export_ID = 2;
+	Path = "my-bucket";
+	Pseudo = "/share";
+	Access_Type = "None";
+	Squash = "No_Root_Squash";
+	Protocols = 4;
+	Transports = "TCP";
+	SecType = krb5, krb5p;
+	FSAL {
+		Name = "RGW";
+		User_Id = "my-user";
+		Access_Key_Id = "access";
+		Secret_Access_Key = "secret";
+	}
+	CLIENT {
+		Clients = 10.0.0.0/8, 192.168.1.5;
+		Access_Type = "RO";
+	}
+}
+`, block)
+}
+
+func TestExportActive(t *testing.T) {
+	output := `method return time=1634567890.123 sender=:1.2 -> destination=:1.3 serial=4 reply_serial=2
+   struct {
+      uint64 1634567890
+      uint32 123
+   }
+   array [
+      struct {
+         uint16 0
+         string "/"
+      }
+      struct {
+         uint16 12
+         string "/share"
+      }
+   ]`
+	assert.True(t, exportActive(output, 12))
+	assert.False(t, exportActive(output, 1))
+	assert.False(t, exportActive("", 12))
+}
+
+func TestUpdateConfigObjects(t *testing.T) {
+	n := &cephv1.CephNFS{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-nfs", Namespace: "rook-ceph"},
+		Spec: cephv1.NFSGaneshaSpec{
+			RADOS:  cephv1.GaneshaRADOSSpec{Pool: "nfs-ganesha", Namespace: "nfs-ns"},
+			Server: cephv1.GaneshaServerSpec{Active: 1},
+		},
+	}
+	object := "conf-nfs.my-nfs"
+	content := "%url \"rados://nfs-ganesha/nfs-ns/export-1\"\n\n"
+	writes := 0
+	executor := &exectest.MockExecutor{
+		MockExecuteCommandWithOutput: func(command string, args ...string) (string, error) {
+			assert.Equal(t, []string{"get", object, "-"}, args[6:])
+			return content, nil
+		},
+		MockExecuteCommand: func(command string, args ...string) error {
+			assert.Equal(t, "put", args[6])
+			data, err := ioutil.ReadFile(args[8])
+			assert.NoError(t, err)
+			content = string(data)
+			writes++
+			return nil
+		},
+	}
+	c := &clusterd.Context{Executor: executor}
+
+	// the blank lines do not accumulate when the exports are added
+	for _, id := range []int{2, 3} {
+		changed, err := addExportToConfig(c, n, cephver.Pacific, id)
+		assert.NoError(t, err)
+		assert.True(t, changed)
+	}
+	assert.Equal(t, "%url \"rados://nfs-ganesha/nfs-ns/export-1\"\n%url \"rados://nfs-ganesha/nfs-ns/export-2\"\n%url \"rados://nfs-ganesha/nfs-ns/export-3\"\n", content)
+
+	// the config is not written when it already includes the export
+	changed, err := addExportToConfig(c, n, cephver.Pacific, 2)
+	assert.NoError(t, err)
+	assert.False(t, changed)
+	assert.Equal(t, 2, writes)
+
+	err = removeExportFromConfig(c, n, cephver.Pacific, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, "%url \"rados://nfs-ganesha/nfs-ns/export-1\"\n%url \"rados://nfs-ganesha/nfs-ns/export-3\"\n", content)
+}
@@ -0,0 +1,240 @@
+/*
+Copyright 2021 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nfs
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	"github.com/rook/rook/pkg/operator/k8sutil"
+	v1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const (
+	// ingressLabel marks the ingress Services of the ganesha servers
+	ingressLabel = "ceph_nfs_ingress"
+
+	// graceStartedAnnotationPrefix records on the CephNFS when a grace period was started on behalf
+	// of a failed server, so that the grace period is only started once per failure
+	graceStartedAnnotationPrefix = "ceph.rook.io/nfs-grace-started-"
+	// graceLiftedAnnotationPrefix records on the CephNFS when the grace period of a failed server was
+	// lifted, so that the grace period is only lifted once per failure
+	graceLiftedAnnotationPrefix = "ceph.rook.io/nfs-grace-lifted-"
+)
+
+var (
+	// ingressCheckInterval is how often the ganesha servers behind the ingress are checked for failures
+	ingressCheckInterval = time.Minute
+
+	// waitForRequeueIngressCheck requeues the reconcile to check the ganesha servers behind the ingress
+	waitForRequeueIngressCheck = reconcile.Result{Requeue: true, RequeueAfter: ingressCheckInterval}
+
+	// graceLiftTimeout is how long the grace period started on behalf of a failed server lasts before
+	// it is lifted, so that a server that does not come back does not keep the whole cluster in grace
+	graceLiftTimeout = 3 * time.Minute
+)
+
+func ingressEnabled(n *cephv1.CephNFS) bool {
+	return n.Spec.Ingress != nil && n.Spec.Ingress.Enabled
+}
+
+// ingressServiceName returns the name of the Service with the virtual IP of a ganesha server
+func ingressServiceName(n *cephv1.CephNFS, name string) string {
+	return fmt.Sprintf("%s-ingress", instanceName(n, name))
+}
+
+func (r *ReconcileCephNFS) generateIngressService(nfs *cephv1.CephNFS, name string) *v1.Service {
+	labels := getLabels(nfs, name, true)
+	labels[ingressLabel] = "true"
+	// select the pod of the ganesha server only, the NFSv4 state of its clients lives on the server
+	selector := map[string]string{
+		k8sutil.AppAttr: AppName,
+		"ceph_nfs":      nfs.Name,
+		"instance":      name,
+	}
+
+	ingress := nfs.Spec.Ingress
+	serviceType := ingress.ServiceType
+	if serviceType == "" {
+		serviceType = v1.ServiceTypeLoadBalancer
+	}
+
+	svc := &v1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      ingressServiceName(nfs, name),
+			Namespace: nfs.Namespace,
+			Labels:    labels,
+		},
+		Spec: v1.ServiceSpec{
+			Type:     serviceType,
+			Selector: selector,
+			Ports: []v1.ServicePort{
+				{
+					Name:       "nfs",
+					Port:       nfsPort,
+					TargetPort: intstr.FromInt(int(nfsPort)),
+					Protocol:   v1.ProtocolTCP,
+				},
+			},
+		},
+	}
+	if serviceType == v1.ServiceTypeLoadBalancer {
+		svc.Spec.LoadBalancerIP = ingress.LoadBalancerIPs[name]
+	}
+	ingress.Annotations.ApplyToObjectMeta(&svc.ObjectMeta)
+
+	return svc
+}
+
+// Create or update the ingress Service of each active server of the CephNFS, and remove the ingress
+// Services of the removed servers, or all of them if the ingress is disabled
+func (r *ReconcileCephNFS) reconcileIngress(nfs *cephv1.CephNFS) error {
+	desired := map[string]bool{}
+	if ingressEnabled(nfs) {
+		for i := 0; i < nfs.Spec.Server.Active; i++ {
+			name := k8sutil.IndexToName(i)
+			s := r.generateIngressService(nfs, name)
+
+			// Set owner ref to the parent object
+			err := controllerutil.SetControllerReference(nfs, s, r.scheme)
+			if err != nil {
+				return errors.Wrapf(err, "failed to set owner reference to ceph nfs ingress service %q", s.Name)
+			}
+
+			svc, err := k8sutil.CreateOrUpdateService(r.context.Clientset, nfs.Namespace, s)
+			if err != nil {
+				return errors.Wrapf(err, "failed to create or update ceph nfs ingress service %q", s.Name)
+			}
+			desired[svc.Name] = true
+			logger.Debugf("ceph nfs server %q ingress service running at %s:%d", name, svc.Spec.ClusterIP, nfsPort)
+		}
+	}
+
+	selector := fmt.Sprintf("%s=%s,%s=true", "ceph_nfs", nfs.Name, ingressLabel)
+	services, err := r.context.Clientset.CoreV1().Services(nfs.Namespace).List(r.opManagerContext, metav1.ListOptions{LabelSelector: selector})
+	if err != nil {
+		return errors.Wrapf(err, "failed to list the ingress services of ceph nfs %q", nfs.Name)
+	}
+	for _, svc := range services.Items {
+		if desired[svc.Name] {
+			continue
+		}
+		if err := k8sutil.DeleteService(r.context.Clientset, nfs.Namespace, svc.Name); err != nil {
+			return errors.Wrapf(err, "failed to delete ceph nfs ingress service %q", svc.Name)
+		}
+	}
+	return nil
+}
+
+// reconcileGraceForFailedServers starts a grace period in the ganesha cluster on behalf of the servers
+// whose pod is not ready. While the cluster is in grace, the surviving servers do not hand out new
+// state that could conflict with the state of the clients of the failed servers. The clients of a
+// failed server keep using its virtual IP and reclaim their state once the server is restarted, which
+// starts a new grace period on its own. The grace period is only started once per failure. The flag of
+// a failed server in the grace database is only cleared by the server itself, so it is lifted after
+// graceLiftTimeout for the surviving servers to leave grace if the server does not come back. It
+// returns the servers that are down.
+func (r *ReconcileCephNFS) reconcileGraceForFailedServers(nfs *cephv1.CephNFS) ([]string, error) {
+	failed := []string{}
+	for i := 0; i < nfs.Spec.Server.Active; i++ {
+		id := k8sutil.IndexToName(i)
+		deployment, err := r.context.Clientset.AppsV1().Deployments(nfs.Namespace).Get(r.opManagerContext, instanceName(nfs, id), metav1.GetOptions{})
+		if err != nil {
+			if kerrors.IsNotFound(err) {
+				// the server is not created yet
+				continue
+			}
+			return failed, errors.Wrapf(err, "failed to get ceph nfs deployment %q", instanceName(nfs, id))
+		}
+		started, lifted := graceStartedAnnotationPrefix+id, graceLiftedAnnotationPrefix+id
+		startedAt, graceStarted := nfs.Annotations[started]
+		_, graceLifted := nfs.Annotations[lifted]
+		if deployment.Status.ReadyReplicas > 0 || deployment.Status.UnavailableReplicas == 0 {
+			if graceStarted || graceLifted {
+				// the server recovered, a grace period is started again on its next failure
+				logger.Infof("ganesha server %q is running again", id)
+				if err := r.updateGraceAnnotation(nfs, started, ""); err != nil {
+					return failed, err
+				}
+				if err := r.updateGraceAnnotation(nfs, lifted, ""); err != nil {
+					return failed, err
+				}
+			}
+			continue
+		}
+		failed = append(failed, id)
+
+		if !graceStarted {
+			logger.Warningf("ganesha server %q is down, starting a grace period for its clients to reclaim their state", id)
+			if err := r.runGaneshaRadosGrace(nfs, id, "start"); err != nil {
+				return failed, errors.Wrapf(err, "failed to start a grace period for ganesha server %q", id)
+			}
+			if err := r.updateGraceAnnotation(nfs, started, time.Now().UTC().Format(time.RFC3339)); err != nil {
+				return failed, err
+			}
+			continue
+		}
+		if graceLifted {
+			logger.Debugf("grace period for ganesha server %q was already lifted at %s", id, nfs.Annotations[lifted])
+			continue
+		}
+		startTime, err := time.Parse(time.RFC3339, startedAt)
+		if err == nil && time.Since(startTime) < graceLiftTimeout {
+			logger.Debugf("grace period for ganesha server %q was started at %s", id, startedAt)
+			continue
+		}
+
+		logger.Warningf("ganesha server %q is still down, lifting its grace period for the other servers to leave grace", id)
+		if err := r.runGaneshaRadosGrace(nfs, id, "lift"); err != nil {
+			return failed, errors.Wrapf(err, "failed to lift the grace period of ganesha server %q", id)
+		}
+		if err := r.updateGraceAnnotation(nfs, lifted, time.Now().UTC().Format(time.RFC3339)); err != nil {
+			return failed, err
+		}
+	}
+
+	return failed, nil
+}
+
+// updateGraceAnnotation sets the grace annotation of a server on the CephNFS, or removes it when the
+// value is empty. Only the annotation is patched so the rest of the CephNFS is left to the reconcile.
+func (r *ReconcileCephNFS) updateGraceAnnotation(nfs *cephv1.CephNFS, annotation, value string) error {
+	if _, ok := nfs.Annotations[annotation]; !ok && value == "" {
+		return nil
+	}
+	patch := client.MergeFrom(nfs.DeepCopy())
+	if value == "" {
+		delete(nfs.Annotations, annotation)
+	} else {
+		if nfs.Annotations == nil {
+			nfs.Annotations = map[string]string{}
+		}
+		nfs.Annotations[annotation] = value
+	}
+	if err := r.client.Patch(r.opManagerContext, nfs, patch); err != nil {
+		return errors.Wrapf(err, "failed to patch the annotation %q of ceph nfs %q", annotation, nfs.Name)
+	}
+	return nil
+}
@@ -0,0 +1,194 @@
+/*
+Copyright 2021 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nfs
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	"github.com/rook/rook/pkg/client/clientset/versioned/scheme"
+	"github.com/rook/rook/pkg/clusterd"
+	optest "github.com/rook/rook/pkg/operator/test"
+	exectest "github.com/rook/rook/pkg/util/exec/test"
+	"github.com/stretchr/testify/assert"
+	apps "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestIngress(t *testing.T) {
+	ctx := context.TODO()
+	nfs := &cephv1.CephNFS{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "my-nfs",
+			Namespace: "rook-ceph-test-ns",
+			UID:       "c47cac40-9bee-4d52-823b-ccd803ba5bfe",
+		},
+		TypeMeta: controllerTypeMeta,
+		Spec: cephv1.NFSGaneshaSpec{
+			RADOS:  cephv1.GaneshaRADOSSpec{Pool: "nfs-ganesha", Namespace: "nfs-ns"},
+			Server: cephv1.GaneshaServerSpec{Active: 2},
+			Ingress: &cephv1.NFSIngressSpec{
+				Enabled:         true,
+				LoadBalancerIPs: map[string]string{"a": "192.168.100.10"},
+				Annotations:     cephv1.Annotations{"metallb.universe.tf/address-pool": "nfs"},
+			},
+		},
+	}
+
+	// the grace database records the servers that need a grace period, the cluster is in grace while
+	// any server needs it
+	graceCommands := [][]string{}
+	needGrace := map[string]bool{}
+	c := &clusterd.Context{
+		Executor: &exectest.MockExecutor{
+			MockExecuteCommandWithEnv: func(env []string, command string, args ...string) error {
+				graceCommands = append(graceCommands, args[4:])
+				switch args[4] {
+				case "start":
+					needGrace[args[5]] = true
+				case "lift":
+					delete(needGrace, args[5])
+				}
+				return nil
+			},
+		},
+		Clientset: optest.New(t, 1),
+	}
+	s := scheme.Scheme
+	s.AddKnownTypes(cephv1.SchemeGroupVersion, &cephv1.CephNFS{}, &cephv1.CephNFSList{})
+	cl := fake.NewClientBuilder().WithScheme(s).WithRuntimeObjects(nfs.DeepCopy()).Build()
+	r := &ReconcileCephNFS{client: cl, context: c, scheme: s, opManagerContext: ctx}
+
+	t.Run("services", func(t *testing.T) {
+		err := r.reconcileIngress(nfs)
+		assert.NoError(t, err)
+		// each server has its own virtual IP
+		svc, err := c.Clientset.CoreV1().Services(nfs.Namespace).Get(ctx, "rook-ceph-nfs-my-nfs-a-ingress", metav1.GetOptions{})
+		assert.NoError(t, err)
+		assert.Equal(t, v1.ServiceTypeLoadBalancer, svc.Spec.Type)
+		assert.Equal(t, "192.168.100.10", svc.Spec.LoadBalancerIP)
+		assert.Equal(t, map[string]string{"app": "rook-ceph-nfs", "ceph_nfs": "my-nfs", "instance": "a"}, svc.Spec.Selector)
+		assert.Equal(t, "nfs", svc.Annotations["metallb.universe.tf/address-pool"])
+		assert.Equal(t, "my-nfs", svc.OwnerReferences[0].Name)
+		svc, err = c.Clientset.CoreV1().Services(nfs.Namespace).Get(ctx, "rook-ceph-nfs-my-nfs-b-ingress", metav1.GetOptions{})
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]string{"app": "rook-ceph-nfs", "ceph_nfs": "my-nfs", "instance": "b"}, svc.Spec.Selector)
+		assert.Empty(t, svc.Spec.LoadBalancerIP)
+
+		// the services are updated
+		nfs.Spec.Ingress.ServiceType = v1.ServiceTypeClusterIP
+		err = r.reconcileIngress(nfs)
+		assert.NoError(t, err)
+		svc, err = c.Clientset.CoreV1().Services(nfs.Namespace).Get(ctx, "rook-ceph-nfs-my-nfs-a-ingress", metav1.GetOptions{})
+		assert.NoError(t, err)
+		assert.Equal(t, v1.ServiceTypeClusterIP, svc.Spec.Type)
+		assert.Empty(t, svc.Spec.LoadBalancerIP)
+
+		// the service of a removed server is deleted
+		nfs.Spec.Server.Active = 1
+		err = r.reconcileIngress(nfs)
+		assert.NoError(t, err)
+		_, err = c.Clientset.CoreV1().Services(nfs.Namespace).Get(ctx, "rook-ceph-nfs-my-nfs-b-ingress", metav1.GetOptions{})
+		assert.Error(t, err)
+		_, err = c.Clientset.CoreV1().Services(nfs.Namespace).Get(ctx, "rook-ceph-nfs-my-nfs-a-ingress", metav1.GetOptions{})
+		assert.NoError(t, err)
+
+		// the services are removed when the ingress is disabled
+		nfs.Spec.Ingress.Enabled = false
+		err = r.reconcileIngress(nfs)
+		assert.NoError(t, err)
+		_, err = c.Clientset.CoreV1().Services(nfs.Namespace).Get(ctx, "rook-ceph-nfs-my-nfs-a-ingress", metav1.GetOptions{})
+		assert.Error(t, err)
+	})
+
+	t.Run("grace for failed servers", func(t *testing.T) {
+		// the reconcile works on the stored ceph nfs
+		err := cl.Get(ctx, types.NamespacedName{Name: nfs.Name, Namespace: nfs.Namespace}, nfs)
+		assert.NoError(t, err)
+		for id, status := range map[string]apps.DeploymentStatus{
+			"a": {Replicas: 1, ReadyReplicas: 1},
+			"b": {Replicas: 1, UnavailableReplicas: 1},
+		} {
+			d := &apps.Deployment{
+				ObjectMeta: metav1.ObjectMeta{Name: instanceName(nfs, id), Namespace: nfs.Namespace},
+				Status:     status,
+			}
+			_, err := c.Clientset.AppsV1().Deployments(nfs.Namespace).Create(ctx, d, metav1.CreateOptions{})
+			assert.NoError(t, err)
+		}
+
+		// only the annotation is patched, not the changes of the reconcile to the ceph nfs
+		nfs.Spec.Server.LogLevel = "FULL_DEBUG"
+		failed, err := r.reconcileGraceForFailedServers(nfs)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"b"}, failed)
+		assert.Equal(t, [][]string{{"start", "my-nfs.b"}}, graceCommands)
+		assert.NotEmpty(t, needGrace)
+		stored := &cephv1.CephNFS{}
+		err = cl.Get(ctx, types.NamespacedName{Name: nfs.Name, Namespace: nfs.Namespace}, stored)
+		assert.NoError(t, err)
+		assert.Contains(t, stored.Annotations, "ceph.rook.io/nfs-grace-started-b")
+		assert.NotEqual(t, "FULL_DEBUG", stored.Spec.Server.LogLevel)
+
+		// the grace period is only started once per failure
+		failed, err = r.reconcileGraceForFailedServers(nfs)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"b"}, failed)
+		assert.Len(t, graceCommands, 1)
+
+		// the grace period is lifted once when the server does not come back, so the surviving
+		// servers leave grace
+		nfs.Annotations["ceph.rook.io/nfs-grace-started-b"] = time.Now().Add(-graceLiftTimeout).UTC().Format(time.RFC3339)
+		failed, err = r.reconcileGraceForFailedServers(nfs)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"b"}, failed)
+		assert.Equal(t, [][]string{{"start", "my-nfs.b"}, {"lift", "my-nfs.b"}}, graceCommands)
+		assert.Empty(t, needGrace)
+		assert.Contains(t, nfs.Annotations, "ceph.rook.io/nfs-grace-lifted-b")
+		failed, err = r.reconcileGraceForFailedServers(nfs)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"b"}, failed)
+		assert.Len(t, graceCommands, 2)
+
+		// the annotations are removed once the server recovered
+		d, err := c.Clientset.AppsV1().Deployments(nfs.Namespace).Get(ctx, instanceName(nfs, "b"), metav1.GetOptions{})
+		assert.NoError(t, err)
+		d.Status = apps.DeploymentStatus{Replicas: 1, ReadyReplicas: 1}
+		_, err = c.Clientset.AppsV1().Deployments(nfs.Namespace).Update(ctx, d, metav1.UpdateOptions{})
+		assert.NoError(t, err)
+		failed, err = r.reconcileGraceForFailedServers(nfs)
+		assert.NoError(t, err)
+		assert.Empty(t, failed)
+		assert.Len(t, graceCommands, 2)
+		assert.NotContains(t, nfs.Annotations, "ceph.rook.io/nfs-grace-started-b")
+		assert.NotContains(t, nfs.Annotations, "ceph.rook.io/nfs-grace-lifted-b")
+
+		// a grace period is started again on the next failure
+		d.Status = apps.DeploymentStatus{Replicas: 1, UnavailableReplicas: 1}
+		_, err = c.Clientset.AppsV1().Deployments(nfs.Namespace).Update(ctx, d, metav1.UpdateOptions{})
+		assert.NoError(t, err)
+		failed, err = r.reconcileGraceForFailedServers(nfs)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"b"}, failed)
+		assert.Len(t, graceCommands, 3)
+	})
+}
@@ -174,6 +174,9 @@ func (r *ReconcileCephNFS) generateConfigMap(n *cephv1.CephNFS, name string) *v1
 	data := map[string]string{
 		"config": getGaneshaConfig(n, r.clusterInfo.CephVersion, name),
 	}
+	for file, content := range securityConfigFiles(n) {
+		data[file] = content
+	}
 	configMap := &v1.ConfigMap{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      instanceName(n, name),
@@ -269,6 +272,11 @@ func validateGanesha(context *clusterd.Context, clusterInfo *cephclient.ClusterI
 		return errors.New("at least one active server required")
 	}
 
+	// The secret and config maps of the security settings must exist before the servers are rolled out
+	if err := validateSecurity(context, clusterInfo, n); err != nil {
+		return errors.Wrap(err, "invalid security settings")
+	}
+
 	// The existence of the pool provided in n.Spec.RADOS.Pool is necessary otherwise addRADOSConfigFile() will fail
 	_, err := cephclient.GetPoolDetails(context, clusterInfo, n.Spec.RADOS.Pool)
 	if err != nil {
@@ -0,0 +1,308 @@
+/*
+Copyright 2021 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nfs
+
+import (
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	"github.com/rook/rook/pkg/clusterd"
+	cephclient "github.com/rook/rook/pkg/daemon/ceph/client"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	keytabFile           = "krb5.keytab"
+	krb5ConfFile         = "krb5.conf"
+	idmapdConfFile       = "idmapd.conf"
+	nsswitchConfFile     = "nsswitch.conf"
+	sssdConfFile         = "sssd.conf"
+	keytabPath           = "/etc/krb5.keytab"
+	krb5ConfPath         = "/etc/krb5.conf"
+	idmapdConfPath       = "/etc/idmapd.conf"
+	nsswitchConfPath     = "/etc/nsswitch.conf"
+	sssdConfPath         = "/etc/sssd/sssd.conf"
+	sssdPipesDir         = "/var/lib/sss/pipes"
+	defaultPrincipalName = "nfs"
+	sssdContainerName    = "sssd"
+	keytabVolume         = "krb5-keytab"
+	krb5ConfVolume       = "krb5-conf"
+	sssdConfVolume       = "sssd-conf"
+	sssdPipesVolume      = "sssd-pipes"
+)
+
+var (
+	// the security flavors of the exports when kerberos is enabled
+	defaultKerberosFlavors = []cephv1.NFSSecurityFlavor{"krb5", "krb5i", "krb5p"}
+
+	// the secret and config files are only readable by their owner
+	secretFileMode int32 = 0600
+)
+
+// KerberosEnabled returns whether the ganesha servers of the CephNFS use Kerberos
+func KerberosEnabled(n *cephv1.CephNFS) bool {
+	return n.Spec.Security != nil && n.Spec.Security.Kerberos != nil
+}
+
+func idMappingEnabled(n *cephv1.CephNFS) bool {
+	return n.Spec.Security != nil && n.Spec.Security.IDMapping != nil
+}
+
+func sssdEnabled(n *cephv1.CephNFS) bool {
+	return idMappingEnabled(n) && n.Spec.Security.IDMapping.SSSD != nil
+}
+
+// idMappingDomain returns the NFSv4 id mapping domain of the ganesha servers
+func idMappingDomain(n *cephv1.CephNFS) string {
+	if n.Spec.Security.IDMapping.Domain != "" {
+		return n.Spec.Security.IDMapping.Domain
+	}
+	if KerberosEnabled(n) {
+		return strings.ToLower(n.Spec.Security.Kerberos.Realm)
+	}
+	return ""
+}
+
+// validateSecurity checks the security settings and that the secret and config maps they refer to
+// exist before the ganesha servers are rolled out
+func validateSecurity(context *clusterd.Context, clusterInfo *cephclient.ClusterInfo, n *cephv1.CephNFS) error {
+	if KerberosEnabled(n) {
+		kerberos := n.Spec.Security.Kerberos
+		if kerberos.Realm == "" {
+			return errors.New("missing security.kerberos.realm")
+		}
+		if err := validateSecretKey(context, clusterInfo, n.Namespace, kerberos.KeytabSecretName, keytabFile); err != nil {
+			return errors.Wrap(err, "invalid security.kerberos.keytabSecretName")
+		}
+		if kerberos.ConfigMapName != "" {
+			if err := validateConfigMapKey(context, clusterInfo, n.Namespace, kerberos.ConfigMapName, krb5ConfFile); err != nil {
+				return errors.Wrap(err, "invalid security.kerberos.configMapName")
+			}
+		}
+	}
+
+	if idMappingEnabled(n) {
+		if idMappingDomain(n) == "" {
+			return errors.New("missing security.idMapping.domain")
+		}
+		if sssdEnabled(n) {
+			sssd := n.Spec.Security.IDMapping.SSSD
+			if sssd.Image == "" {
+				return errors.New("missing security.idMapping.sssd.image")
+			}
+			if err := validateConfigMapKey(context, clusterInfo, n.Namespace, sssd.ConfigMapName, sssdConfFile); err != nil {
+				return errors.Wrap(err, "invalid security.idMapping.sssd.configMapName")
+			}
+		}
+	}
+
+	return nil
+}
+
+func validateSecretKey(context *clusterd.Context, clusterInfo *cephclient.ClusterInfo, namespace, name, key string) error {
+	secret, err := context.Clientset.CoreV1().Secrets(namespace).Get(clusterInfo.Context, name, metav1.GetOptions{})
+	if err != nil {
+		return errors.Wrapf(err, "failed to get secret %q", name)
+	}
+	if len(secret.Data[key]) == 0 {
+		return errors.Errorf("secret %q has no %q key", name, key)
+	}
+	return nil
+}
+
+func validateConfigMapKey(context *clusterd.Context, clusterInfo *cephclient.ClusterInfo, namespace, name, key string) error {
+	configMap, err := context.Clientset.CoreV1().ConfigMaps(namespace).Get(clusterInfo.Context, name, metav1.GetOptions{})
+	if err != nil {
+		return errors.Wrapf(err, "failed to get config map %q", name)
+	}
+	if configMap.Data[key] == "" {
+		return errors.Errorf("config map %q has no %q key", name, key)
+	}
+	return nil
+}
+
+// getGaneshaKrb5Config returns the NFS_KRB5 block of the ganesha config
+func getGaneshaKrb5Config(n *cephv1.CephNFS) string {
+	if !KerberosEnabled(n) {
+		return ""
+	}
+	principal := n.Spec.Security.Kerberos.PrincipalName
+	if principal == "" {
+		principal = defaultPrincipalName
+	}
+	return `
+NFS_KRB5 {
+	PrincipalName = "` + principal + `";
+	KeytabPath = "` + keytabPath + `";
+	Active_krb5 = true;
+}
+`
+}
+
+// getGaneshaExportDefaultsParams returns the security params of the EXPORT_DEFAULTS block
+func getGaneshaExportDefaultsParams(n *cephv1.CephNFS) string {
+	if !KerberosEnabled(n) {
+		return ""
+	}
+	flavors := []string{}
+	for _, flavor := range n.Spec.Security.Kerberos.SecurityFlavors {
+		flavors = append(flavors, string(flavor))
+	}
+	if len(flavors) == 0 {
+		for _, flavor := range defaultKerberosFlavors {
+			flavors = append(flavors, string(flavor))
+		}
+	}
+	return "\tSecType = " + strings.Join(flavors, ", ") + ";\n"
+}
+
+// getGaneshaNFSv4Params returns the id mapping params of the NFSv4 block
+func getGaneshaNFSv4Params(n *cephv1.CephNFS) string {
+	if !idMappingEnabled(n) {
+		return ""
+	}
+	return "\tIdmapConf = \"" + idmapdConfPath + "\";\n"
+}
+
+// securityConfigFiles returns the config files of the security settings generated in the ganesha
+// config map
+func securityConfigFiles(n *cephv1.CephNFS) map[string]string {
+	files := map[string]string{}
+	if KerberosEnabled(n) && n.Spec.Security.Kerberos.ConfigMapName == "" {
+		files[krb5ConfFile] = `[libdefaults]
+	default_realm = ` + n.Spec.Security.Kerberos.Realm + `
+	dns_lookup_realm = false
+	dns_lookup_kdc = true
+`
+	}
+	if idMappingEnabled(n) {
+		files[idmapdConfFile] = `[General]
+Domain = ` + idMappingDomain(n) + `
+
+[Mapping]
+Nobody-User = nobody
+Nobody-Group = nobody
+
+[Translation]
+Method = nsswitch
+`
+	}
+	if sssdEnabled(n) {
+		files[nsswitchConfFile] = `passwd: files sss
+group: files sss
+`
+	}
+	return files
+}
+
+// securityConfigItems returns the items of the ganesha config map volume for the generated config files
+func securityConfigItems(n *cephv1.CephNFS) []v1.KeyToPath {
+	items := []v1.KeyToPath{}
+	for file := range securityConfigFiles(n) {
+		items = append(items, v1.KeyToPath{Key: file, Path: file})
+	}
+	// keep the pod spec stable
+	sort.Slice(items, func(i, j int) bool { return items[i].Key < items[j].Key })
+	return items
+}
+
+// securityVolumesAndMounts returns the volumes of the pod and the mounts of the ganesha container for
+// the security settings
+func securityVolumesAndMounts(n *cephv1.CephNFS) ([]v1.Volume, []v1.VolumeMount) {
+	volumes := []v1.Volume{}
+	mounts := []v1.VolumeMount{}
+
+	if KerberosEnabled(n) {
+		kerberos := n.Spec.Security.Kerberos
+		volumes = append(volumes, v1.Volume{Name: keytabVolume, VolumeSource: v1.VolumeSource{
+			Secret: &v1.SecretVolumeSource{
+				SecretName:  kerberos.KeytabSecretName,
+				Items:       []v1.KeyToPath{{Key: keytabFile, Path: keytabFile}},
+				DefaultMode: &secretFileMode,
+			},
+		}})
+		mounts = append(mounts, v1.VolumeMount{Name: keytabVolume, MountPath: keytabPath, SubPath: keytabFile, ReadOnly: true})
+
+		if kerberos.ConfigMapName != "" {
+			volumes = append(volumes, v1.Volume{Name: krb5ConfVolume, VolumeSource: v1.VolumeSource{
+				ConfigMap: &v1.ConfigMapVolumeSource{
+					LocalObjectReference: v1.LocalObjectReference{Name: kerberos.ConfigMapName},
+					Items:                []v1.KeyToPath{{Key: krb5ConfFile, Path: krb5ConfFile}},
+				},
+			}})
+			mounts = append(mounts, v1.VolumeMount{Name: krb5ConfVolume, MountPath: krb5ConfPath, SubPath: krb5ConfFile, ReadOnly: true})
+		} else {
+			mounts = append(mounts, ganeshaConfigFileMount(krb5ConfPath))
+		}
+	}
+
+	if idMappingEnabled(n) {
+		mounts = append(mounts, ganeshaConfigFileMount(idmapdConfPath))
+	}
+
+	if sssdEnabled(n) {
+		sssd := n.Spec.Security.IDMapping.SSSD
+		volumes = append(volumes,
+			v1.Volume{Name: sssdConfVolume, VolumeSource: v1.VolumeSource{
+				ConfigMap: &v1.ConfigMapVolumeSource{
+					LocalObjectReference: v1.LocalObjectReference{Name: sssd.ConfigMapName},
+					Items:                []v1.KeyToPath{{Key: sssdConfFile, Path: sssdConfFile}},
+					// sssd refuses to start if its config is readable by others
+					DefaultMode: &secretFileMode,
+				},
+			}},
+			v1.Volume{Name: sssdPipesVolume, VolumeSource: v1.VolumeSource{EmptyDir: &v1.EmptyDirVolumeSource{}}},
+		)
+		mounts = append(mounts,
+			ganeshaConfigFileMount(nsswitchConfPath),
+			v1.VolumeMount{Name: sssdPipesVolume, MountPath: sssdPipesDir},
+		)
+	}
+
+	return volumes, mounts
+}
+
+// ganeshaConfigFileMount mounts a file of the ganesha config map in the ganesha container
+func ganeshaConfigFileMount(filePath string) v1.VolumeMount {
+	return v1.VolumeMount{Name: ganeshaConfigVolume, MountPath: filePath, SubPath: path.Base(filePath), ReadOnly: true}
+}
+
+// sssdContainer returns the SSSD sidecar resolving the users and groups for the ganesha container
+func (r *ReconcileCephNFS) sssdContainer(nfs *cephv1.CephNFS) v1.Container {
+	sssd := nfs.Spec.Security.IDMapping.SSSD
+	return v1.Container{
+		Name: sssdContainerName,
+		Command: []string{
+			"sssd",
+		},
+		Args: []string{
+			"--interactive",   // run in foreground
+			"--logger=stderr", // log to stderr
+			"--config", sssdConfPath,
+		},
+		Image: sssd.Image,
+		VolumeMounts: []v1.VolumeMount{
+			{Name: sssdConfVolume, MountPath: sssdConfPath, SubPath: sssdConfFile, ReadOnly: true},
+			{Name: sssdPipesVolume, MountPath: sssdPipesDir},
+		},
+		Resources: sssd.Resources,
+	}
+}
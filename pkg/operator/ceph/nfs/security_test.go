@@ -0,0 +1,147 @@
+/*
+Copyright 2021 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nfs
+
+import (
+	"context"
+	"testing"
+
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	"github.com/rook/rook/pkg/client/clientset/versioned/scheme"
+	"github.com/rook/rook/pkg/clusterd"
+	cephclient "github.com/rook/rook/pkg/daemon/ceph/client"
+	"github.com/rook/rook/pkg/operator/ceph/config"
+	cephver "github.com/rook/rook/pkg/operator/ceph/version"
+	optest "github.com/rook/rook/pkg/operator/test"
+	exectest "github.com/rook/rook/pkg/util/exec/test"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestSecurity(t *testing.T) {
+	ctx := context.TODO()
+	nfs := &cephv1.CephNFS{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-nfs", Namespace: "rook-ceph-test-ns"},
+		Spec: cephv1.NFSGaneshaSpec{
+			RADOS:  cephv1.GaneshaRADOSSpec{Pool: "nfs-ganesha", Namespace: "nfs-ns"},
+			Server: cephv1.GaneshaServerSpec{Active: 1},
+			Security: &cephv1.NFSSecuritySpec{
+				Kerberos: &cephv1.NFSKerberosSpec{
+					Realm:            "EXAMPLE.NET",
+					KeytabSecretName: "nfs-keytab",
+				},
+				IDMapping: &cephv1.NFSIDMappingSpec{
+					SSSD: &cephv1.SSSDSpec{Image: "quay.io/example/sssd:latest", ConfigMapName: "nfs-sssd"},
+				},
+			},
+		},
+	}
+
+	clientset := optest.New(t, 1)
+	c := &clusterd.Context{Executor: &exectest.MockExecutor{}, Clientset: clientset}
+	clusterInfo := &cephclient.ClusterInfo{Context: ctx, CephVersion: cephver.Octopus}
+
+	t.Run("validate", func(t *testing.T) {
+		// the keytab secret is missing
+		err := validateSecurity(c, clusterInfo, nfs)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "keytabSecretName")
+
+		secret := &v1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "nfs-keytab", Namespace: nfs.Namespace},
+			Data:       map[string][]byte{keytabFile: []byte("keytab")},
+		}
+		_, err = clientset.CoreV1().Secrets(nfs.Namespace).Create(ctx, secret, metav1.CreateOptions{})
+		assert.NoError(t, err)
+
+		// the sssd config map is missing
+		err = validateSecurity(c, clusterInfo, nfs)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "sssd.configMapName")
+
+		cm := &v1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "nfs-sssd", Namespace: nfs.Namespace},
+			Data:       map[string]string{sssdConfFile: "[sssd]"},
+		}
+		_, err = clientset.CoreV1().ConfigMaps(nfs.Namespace).Create(ctx, cm, metav1.CreateOptions{})
+		assert.NoError(t, err)
+
+		err = validateSecurity(c, clusterInfo, nfs)
+		assert.NoError(t, err)
+	})
+
+	t.Run("ganesha config", func(t *testing.T) {
+		conf := getGaneshaConfig(nfs, cephver.Octopus, "a")
+		assert.Contains(t, conf, "\tSecType = krb5, krb5i, krb5p;\n}")
+		assert.Contains(t, conf, "\tIdmapConf = \"/etc/idmapd.conf\";\n}")
+		assert.Contains(t, conf, "NFS_KRB5 {\n\tPrincipalName = \"nfs\";\n\tKeytabPath = \"/etc/krb5.keytab\";")
+
+		files := securityConfigFiles(nfs)
+		assert.Contains(t, files[krb5ConfFile], "default_realm = EXAMPLE.NET")
+		assert.Contains(t, files[idmapdConfFile], "Domain = example.net")
+		assert.Contains(t, files[nsswitchConfFile], "passwd: files sss")
+
+		// nothing is added without security settings
+		plain := nfs.DeepCopy()
+		plain.Spec.Security = nil
+		conf = getGaneshaConfig(plain, cephver.Octopus, "a")
+		assert.NotContains(t, conf, "SecType")
+		assert.NotContains(t, conf, "NFS_KRB5")
+		assert.Empty(t, securityConfigFiles(plain))
+	})
+
+	t.Run("deployment", func(t *testing.T) {
+		r := &ReconcileCephNFS{
+			context:     c,
+			scheme:      scheme.Scheme,
+			clusterInfo: clusterInfo,
+			cephClusterSpec: &cephv1.ClusterSpec{
+				CephVersion: cephv1.CephVersionSpec{Image: "quay.io/ceph/ceph:v15"},
+			},
+		}
+		cfg := daemonConfig{
+			ID:              "a",
+			ConfigConfigMap: "rook-ceph-nfs-my-nfs-a",
+			DataPathMap:     &config.DataPathMap{ContainerDataDir: cephclient.DefaultConfigDir},
+		}
+		d, err := r.makeDeployment(nfs, cfg)
+		assert.NoError(t, err)
+
+		volumes := map[string]v1.Volume{}
+		for _, volume := range d.Spec.Template.Spec.Volumes {
+			volumes[volume.Name] = volume
+		}
+		assert.Equal(t, "nfs-keytab", volumes[keytabVolume].Secret.SecretName)
+		assert.Equal(t, "nfs-sssd", volumes[sssdConfVolume].ConfigMap.Name)
+		assert.NotNil(t, volumes[sssdPipesVolume].EmptyDir)
+
+		containers := map[string]v1.Container{}
+		for _, container := range d.Spec.Template.Spec.Containers {
+			containers[container.Name] = container
+		}
+		assert.Equal(t, "quay.io/example/sssd:latest", containers[sssdContainerName].Image)
+		mounts := map[string]string{}
+		for _, mount := range containers["nfs-ganesha"].VolumeMounts {
+			mounts[mount.MountPath] = mount.Name
+		}
+		assert.Equal(t, keytabVolume, mounts[keytabPath])
+		assert.Equal(t, ganeshaConfigVolume, mounts[krb5ConfPath])
+		assert.Equal(t, ganeshaConfigVolume, mounts[idmapdConfPath])
+		assert.Equal(t, sssdPipesVolume, mounts[sssdPipesDir])
+	})
+}
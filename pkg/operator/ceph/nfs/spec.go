@@ -37,6 +37,9 @@ const (
 	ganeshaConfigVolume = "ganesha-config"
 	nfsPort             = 2049
 	ganeshaPid          = "/var/run/ganesha/ganesha.pid"
+
+	// DBusContainerName is the name of the dbus sidecar container of the ganesha pods
+	DBusContainerName = "dbus-daemon"
 )
 
 func (r *ReconcileCephNFS) generateCephNFSService(nfs *cephv1.CephNFS, cfg daemonConfig) *v1.Service {
@@ -106,7 +109,9 @@ func (r *ReconcileCephNFS) makeDeployment(nfs *cephv1.CephNFS, cfg daemonConfig)
 
 	cephConfigVol, _ := cephConfigVolumeAndMount()
 	nfsConfigVol, _ := nfsConfigVolumeAndMount(cfg.ConfigConfigMap)
+	nfsConfigVol.ConfigMap.Items = append(nfsConfigVol.ConfigMap.Items, securityConfigItems(nfs)...)
 	dbusVol, _ := dbusVolumeAndMount()
+	securityVols, _ := securityVolumesAndMounts(nfs)
 	podSpec := v1.PodSpec{
 		InitContainers: []v1.Container{
 			r.connectionConfigInitContainer(nfs, cfg.ID),
@@ -129,6 +134,10 @@ func (r *ReconcileCephNFS) makeDeployment(nfs *cephv1.CephNFS, cfg daemonConfig)
 		HostNetwork:       r.cephClusterSpec.Network.IsHost(),
 		PriorityClassName: nfs.Spec.Server.PriorityClassName,
 	}
+	podSpec.Volumes = append(podSpec.Volumes, securityVols...)
+	if sssdEnabled(nfs) {
+		podSpec.Containers = append(podSpec.Containers, r.sssdContainer(nfs))
+	}
 	// Replace default unreachable node toleration
 	k8sutil.AddUnreachableNodeToleration(&podSpec)
 
@@ -189,6 +198,7 @@ func (r *ReconcileCephNFS) daemonContainer(nfs *cephv1.CephNFS, cfg daemonConfig
 	_, cephConfigMount := cephConfigVolumeAndMount()
 	_, nfsConfigMount := nfsConfigVolumeAndMount(cfg.ConfigConfigMap)
 	_, dbusMount := dbusVolumeAndMount()
+	_, securityMounts := securityVolumesAndMounts(nfs)
 	logLevel := "NIV_INFO" // Default log level
 	if nfs.Spec.Server.LogLevel != "" {
 		logLevel = nfs.Spec.Server.LogLevel
@@ -206,12 +216,12 @@ func (r *ReconcileCephNFS) daemonContainer(nfs *cephv1.CephNFS, cfg daemonConfig
 			"-N", logLevel, // Change Log level
 		},
 		Image: r.cephClusterSpec.CephVersion.Image,
-		VolumeMounts: []v1.VolumeMount{
+		VolumeMounts: append([]v1.VolumeMount{
 			cephConfigMount,
 			keyring.VolumeMount().Resource(instanceName(nfs, cfg.ID)),
 			nfsConfigMount,
 			dbusMount,
-		},
+		}, securityMounts...),
 		Env:             controller.DaemonEnvVars(r.cephClusterSpec.CephVersion.Image),
 		Resources:       nfs.Spec.Server.Resources,
 		SecurityContext: controller.PodSecurityContext(),
@@ -222,7 +232,7 @@ func (r *ReconcileCephNFS) dbusContainer(nfs *cephv1.CephNFS) v1.Container {
 	_, dbusMount := dbusVolumeAndMount()
 
 	return v1.Container{
-		Name: "dbus-daemon",
+		Name: DBusContainerName,
 		Command: []string{
 			"dbus-daemon",
 		},
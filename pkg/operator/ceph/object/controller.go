@@ -85,9 +85,12 @@ type ReconcileCephObjectStore struct {
 	clusterSpec         *cephv1.ClusterSpec
 	clusterInfo         *cephclient.ClusterInfo
 	objectStoreContexts map[string]*objectStoreHealth
+	usageCheckers       *opcontroller.PoolUsageCheckers
 	recorder            *k8sutil.EventReporter
 	opManagerContext    context.Context
 	opConfig            opcontroller.OperatorConfig
+	// newCephAdmin returns the admin that creates and deletes the pools, the tests replace it with a fake cluster
+	newCephAdmin func(context *clusterd.Context, clusterInfo *cephclient.ClusterInfo) cephclient.CephAdmin
 }
 
 type objectStoreHealth struct {
@@ -111,9 +114,11 @@ func newReconciler(mgr manager.Manager, context *clusterd.Context, opManagerCont
 		context:             context,
 		bktclient:           bktclient.NewForConfigOrDie(context.KubeConfig),
 		objectStoreContexts: make(map[string]*objectStoreHealth),
+		usageCheckers:       opcontroller.NewPoolUsageCheckers(),
 		recorder:            k8sutil.NewEventReporter(mgr.GetEventRecorderFor("rook-" + controllerName)),
 		opManagerContext:    opManagerContext,
 		opConfig:            opConfig,
+		newCephAdmin:        cephclient.NewCephAdmin,
 	}
 }
 
@@ -152,6 +157,7 @@ func add(mgr manager.Manager, r reconcile.Reconciler) error {
 func (r *ReconcileCephObjectStore) Reconcile(context context.Context, request reconcile.Request) (reconcile.Result, error) {
 	// workaround because the rook logging mechanism is not compatible with the controller-runtime logging interface
 	reconcileResponse, objectStore, err := r.reconcile(request)
+	reconcileResponse, err = opcontroller.RequeueIfCephCommandsUnavailable(reconcileResponse, err)
 
 	return reporting.ReportReconcileResult(logger, r.recorder, objectStore, reconcileResponse, err)
 }
@@ -252,7 +258,7 @@ func (r *ReconcileCephObjectStore) reconcile(request reconcile.Request) (reconci
 					return reconcile.Result{}, cephObjectStore, errors.Wrapf(err, "failed to check for object buckets. failed to get admin ops API context")
 				}
 
-				deps, err := CephObjectStoreDependents(r.context, r.clusterInfo, cephObjectStore, objCtx, opsCtx)
+				deps, err := CephObjectStoreDependents(r.context, r.clusterInfo, r.newCephAdmin(r.context, r.clusterInfo), cephObjectStore, objCtx, opsCtx)
 				if err != nil {
 					return reconcile.Result{}, cephObjectStore, err
 				}
@@ -265,6 +271,7 @@ func (r *ReconcileCephObjectStore) reconcile(request reconcile.Request) (reconci
 				// Cancel the context to stop monitoring the health of the object store
 				r.objectStoreContexts[cephObjectStore.Name].internalCancel()
 				r.objectStoreContexts[cephObjectStore.Name].started = false
+				r.usageCheckers.Stop(objectStoreChannelKeyName(cephObjectStore))
 
 				cfg := clusterConfig{
 					context:     r.context,
@@ -272,7 +279,7 @@ func (r *ReconcileCephObjectStore) reconcile(request reconcile.Request) (reconci
 					clusterSpec: r.clusterSpec,
 					clusterInfo: r.clusterInfo,
 				}
-				cfg.deleteStore()
+				cfg.deleteStore(r.newCephAdmin(r.context, r.clusterInfo))
 
 				// Remove object store from the map
 				delete(r.objectStoreContexts, cephObjectStore.Name)
@@ -318,6 +325,12 @@ func (r *ReconcileCephObjectStore) reconcile(request reconcile.Request) (reconci
 	}
 	r.clusterInfo.CephVersion = *desiredCephVersion
 
+	// The upgrade strategy may hold the upgrade before the rgw daemons are rolled out
+	if paused, message := opcontroller.IsUpgradePausedBefore(&cephCluster, config.RgwType); paused {
+		logger.Infof("waiting to reconcile object store %q. %s", cephObjectStore.Name, message)
+		return opcontroller.WaitForRequeueIfUpgradePaused, cephObjectStore, nil
+	}
+
 	// validate the store settings
 	if err := r.validateStore(cephObjectStore); err != nil {
 		return reconcile.Result{}, cephObjectStore, errors.Wrapf(err, "invalid object store %q arguments", cephObjectStore.Name)
@@ -424,7 +437,7 @@ func (r *ReconcileCephObjectStore) reconcileCreateObjectStore(cephObjectStore *c
 		// Reconcile Pool Creation
 		if !cephObjectStore.Spec.IsMultisite() {
 			logger.Info("reconciling object store pools")
-			err = CreatePools(objContext, r.clusterSpec, cephObjectStore.Spec.MetadataPool, cephObjectStore.Spec.DataPool)
+			err = CreatePools(objContext, r.newCephAdmin(r.context, r.clusterInfo), r.clusterSpec, cephObjectStore.Spec.MetadataPool, cephObjectStore.Spec.DataPool)
 			if err != nil {
 				return r.setFailedStatus(namespacedName, "failed to create object pools", err)
 			}
@@ -444,6 +457,9 @@ func (r *ReconcileCephObjectStore) reconcileCreateObjectStore(cephObjectStore *c
 		if err != nil {
 			return reconcile.Result{}, errors.Wrapf(err, "failed to create object store %q", cephObjectStore.Name)
 		}
+
+		// Start monitoring the usage of the pools
+		r.startPoolUsageMonitoring(cephObjectStore, objContext, namespacedName)
 	}
 
 	// Start monitoring
@@ -516,6 +532,25 @@ func (r *ReconcileCephObjectStore) reconcileMultisiteCRs(cephObjectStore *cephv1
 	return cephObjectStore.Name, cephObjectStore.Name, cephObjectStore.Name, reconcile.Result{}, nil
 }
 
+// startPoolUsageMonitoring reports the usage of the pools of the object store in its status. The pools
+// of the zone of a multisite object store are not created by the object store and are not monitored.
+func (r *ReconcileCephObjectStore) startPoolUsageMonitoring(objectstore *cephv1.CephObjectStore, objContext *Context, namespacedName types.NamespacedName) {
+	if emptyPool(objectstore.Spec.DataPool) && emptyPool(objectstore.Spec.MetadataPool) {
+		r.usageCheckers.Stop(objectStoreChannelKeyName(objectstore))
+		return
+	}
+
+	pools := []string{}
+	for _, pool := range append(metadataPools, dataPoolName) {
+		pools = append(pools, poolName(objContext.Name, pool))
+	}
+	usageChecker := opcontroller.NewPoolUsageChecker(r.context, r.clusterInfo, r.recorder, objectstore, pools,
+		objectstore.Spec.HealthCheck.Usage, func(usage []cephv1.PoolUsageStatus) error {
+			return updatePoolUsageStatus(r.client, namespacedName, usage)
+		})
+	r.usageCheckers.Start(r.opManagerContext, objectStoreChannelKeyName(objectstore), usageChecker)
+}
+
 func (r *ReconcileCephObjectStore) startMonitoring(objectstore *cephv1.CephObjectStore, objContext *Context, namespacedName types.NamespacedName) error {
 	// Start monitoring object store
 	if r.objectStoreContexts[objectstore.Name].started {
@@ -536,3 +571,7 @@ func (r *ReconcileCephObjectStore) startMonitoring(objectstore *cephv1.CephObjec
 
 	return nil
 }
+
+func objectStoreChannelKeyName(objectstore *cephv1.CephObjectStore) string {
+	return fmt.Sprintf("%s-%s", objectstore.Namespace, objectstore.Name)
+}
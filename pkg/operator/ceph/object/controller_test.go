@@ -31,6 +31,7 @@ import (
 	"github.com/rook/rook/pkg/client/clientset/versioned/scheme"
 	"github.com/rook/rook/pkg/clusterd"
 	"github.com/rook/rook/pkg/daemon/ceph/client"
+	opcontroller "github.com/rook/rook/pkg/operator/ceph/controller"
 	cephver "github.com/rook/rook/pkg/operator/ceph/version"
 	"github.com/rook/rook/pkg/operator/k8sutil"
 	"github.com/rook/rook/pkg/operator/test"
@@ -334,8 +335,10 @@ func TestCephObjectStoreController(t *testing.T) {
 			scheme:              s,
 			context:             c,
 			objectStoreContexts: make(map[string]*objectStoreHealth),
+			usageCheckers:       opcontroller.NewPoolUsageCheckers(),
 			recorder:            k8sutil.NewEventReporter(record.NewFakeRecorder(5)),
 			opManagerContext:    context.TODO(),
+			newCephAdmin:        client.NewCephAdmin,
 		}
 
 		return r
@@ -654,8 +657,10 @@ func TestCephObjectStoreControllerMultisite(t *testing.T) {
 		scheme:              s,
 		context:             c,
 		objectStoreContexts: make(map[string]*objectStoreHealth),
+		usageCheckers:       opcontroller.NewPoolUsageCheckers(),
 		recorder:            k8sutil.NewEventReporter(record.NewFakeRecorder(5)),
 		opManagerContext:    context.TODO(),
+		newCephAdmin:        client.NewCephAdmin,
 	}
 
 	_, err := r.context.Clientset.CoreV1().Secrets(namespace).Create(ctx, secret, metav1.CreateOptions{})
@@ -674,3 +679,11 @@ func TestCephObjectStoreControllerMultisite(t *testing.T) {
 	err = r.client.Get(context.TODO(), req.NamespacedName, objectStore)
 	assert.NoError(t, err)
 }
+
+func TestObjectStoreChannelKeyName(t *testing.T) {
+	// the stores of the same name in different namespaces have their own checkers
+	store := &cephv1.CephObjectStore{ObjectMeta: metav1.ObjectMeta{Name: "my-store", Namespace: "rook-ceph"}}
+	other := &cephv1.CephObjectStore{ObjectMeta: metav1.ObjectMeta{Name: "my-store", Namespace: "other-ns"}}
+	assert.Equal(t, "rook-ceph-my-store", objectStoreChannelKeyName(store))
+	assert.NotEqual(t, objectStoreChannelKeyName(store), objectStoreChannelKeyName(other))
+}
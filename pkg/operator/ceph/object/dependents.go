@@ -35,6 +35,7 @@ const bucketDependentType = "buckets in the object store (could be from ObjectBu
 func CephObjectStoreDependents(
 	clusterdCtx *clusterd.Context,
 	clusterInfo *client.ClusterInfo,
+	admin client.CephAdmin,
 	store *v1.CephObjectStore,
 	objCtx *Context,
 	opsCtx *AdminOpsContext,
@@ -46,7 +47,7 @@ func CephObjectStoreDependents(
 
 	// NOTE: we should still check for buckets when the RGW connection is external since we have no
 	// way of knowing if the bucket was created due to an ObjectBucketClaim or COSI Bucket.
-	err := getBucketDependents(deps, clusterdCtx, clusterInfo, store, objCtx, opsCtx)
+	err := getBucketDependents(deps, clusterdCtx, clusterInfo, admin, store, objCtx, opsCtx)
 	if err != nil {
 		return deps, errors.Wrapf(err, baseErrMsg)
 	}
@@ -71,13 +72,14 @@ func getBucketDependents(
 	deps *dependents.DependentList,
 	clusterdCtx *clusterd.Context,
 	clusterInfo *client.ClusterInfo,
+	admin client.CephAdmin,
 	store *v1.CephObjectStore,
 	objCtx *Context,
 	opsCtx *AdminOpsContext,
 ) error {
 	nsName := fmt.Sprintf("%s/%s", store.Namespace, store.Name)
 
-	missingPools, err := missingPools(objCtx)
+	missingPools, err := missingPools(objCtx, admin)
 	if err != nil {
 		return errors.Wrapf(err, "failed to check for object buckets")
 	}
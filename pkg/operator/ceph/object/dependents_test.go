@@ -94,6 +94,7 @@ func TestCephObjectStoreDependents(t *testing.T) {
 	}
 
 	clusterInfo := client.AdminClusterInfo(ns)
+	newCephAdmin := client.NewCephAdmin
 	// Create objectmeta with the given name in our test namespace
 	meta := func(name string) v1.ObjectMeta {
 		return v1.ObjectMeta{
@@ -117,7 +118,7 @@ func TestCephObjectStoreDependents(t *testing.T) {
 
 	t.Run("missing pools so skipping", func(t *testing.T) {
 		c = newClusterdCtx(executor)
-		deps, err := CephObjectStoreDependents(c, clusterInfo, store, NewContext(c, clusterInfo, store.Name), &AdminOpsContext{})
+		deps, err := CephObjectStoreDependents(c, clusterInfo, newCephAdmin(c, clusterInfo), store, NewContext(c, clusterInfo, store.Name), &AdminOpsContext{})
 		assert.NoError(t, err)
 		assert.True(t, deps.Empty())
 	})
@@ -145,7 +146,7 @@ func TestCephObjectStoreDependents(t *testing.T) {
 		c = newClusterdCtx(executor)
 		client, err := admin.New("rook-ceph-rgw-my-store.mycluster.svc", "53S6B9S809NUP19IJ2K3", "1bXPegzsGClvoGAiJdHQD1uOW2sQBLAZM9j9VtXR", mockClient(`[]`))
 		assert.NoError(t, err)
-		deps, err := CephObjectStoreDependents(c, clusterInfo, store, NewContext(c, clusterInfo, store.Name), &AdminOpsContext{AdminOpsClient: client})
+		deps, err := CephObjectStoreDependents(c, clusterInfo, newCephAdmin(c, clusterInfo), store, NewContext(c, clusterInfo, store.Name), &AdminOpsContext{AdminOpsClient: client})
 		assert.NoError(t, err)
 		assert.True(t, deps.Empty())
 	})
@@ -175,7 +176,7 @@ func TestCephObjectStoreDependents(t *testing.T) {
 		assert.NoError(t, err)
 		client, err := admin.New("rook-ceph-rgw-my-store.mycluster.svc", "53S6B9S809NUP19IJ2K3", "1bXPegzsGClvoGAiJdHQD1uOW2sQBLAZM9j9VtXR", mockClient(`[]`))
 		assert.NoError(t, err)
-		deps, err := CephObjectStoreDependents(c, clusterInfo, store, NewContext(c, clusterInfo, store.Name), &AdminOpsContext{AdminOpsClient: client})
+		deps, err := CephObjectStoreDependents(c, clusterInfo, newCephAdmin(c, clusterInfo), store, NewContext(c, clusterInfo, store.Name), &AdminOpsContext{AdminOpsClient: client})
 		assert.NoError(t, err)
 		assert.True(t, deps.Empty())
 	})
@@ -205,7 +206,7 @@ func TestCephObjectStoreDependents(t *testing.T) {
 		assert.NoError(t, err)
 		client, err := admin.New("rook-ceph-rgw-my-store.mycluster.svc", "53S6B9S809NUP19IJ2K3", "1bXPegzsGClvoGAiJdHQD1uOW2sQBLAZM9j9VtXR", mockClient(`[]`))
 		assert.NoError(t, err)
-		deps, err := CephObjectStoreDependents(c, clusterInfo, store, NewContext(c, clusterInfo, store.Name), &AdminOpsContext{AdminOpsClient: client})
+		deps, err := CephObjectStoreDependents(c, clusterInfo, newCephAdmin(c, clusterInfo), store, NewContext(c, clusterInfo, store.Name), &AdminOpsContext{AdminOpsClient: client})
 		assert.NoError(t, err)
 		assert.False(t, deps.Empty())
 		assert.ElementsMatch(t, []string{"u1"}, deps.OfPluralKind("CephObjectStoreUsers"))
@@ -234,7 +235,7 @@ func TestCephObjectStoreDependents(t *testing.T) {
 		c = newClusterdCtx(executor)
 		client, err := admin.New("rook-ceph-rgw-my-store.mycluster.svc", "53S6B9S809NUP19IJ2K3", "1bXPegzsGClvoGAiJdHQD1uOW2sQBLAZM9j9VtXR", mockClient(`["my-bucket"]`))
 		assert.NoError(t, err)
-		deps, err := CephObjectStoreDependents(c, clusterInfo, store, NewContext(c, clusterInfo, store.Name), &AdminOpsContext{AdminOpsClient: client})
+		deps, err := CephObjectStoreDependents(c, clusterInfo, newCephAdmin(c, clusterInfo), store, NewContext(c, clusterInfo, store.Name), &AdminOpsContext{AdminOpsClient: client})
 		assert.NoError(t, err)
 		assert.False(t, deps.Empty())
 		assert.ElementsMatch(t, []string{"my-bucket"}, deps.OfPluralKind("buckets in the object store (could be from ObjectBucketClaims or COSI Buckets)"), deps)
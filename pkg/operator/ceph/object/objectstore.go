@@ -86,7 +86,7 @@ type realmType struct {
 	Realms []string `json:"realms"`
 }
 
-func deleteRealmAndPools(objContext *Context, spec cephv1.ObjectStoreSpec) error {
+func deleteRealmAndPools(objContext *Context, admin cephclient.CephAdmin, spec cephv1.ObjectStoreSpec) error {
 	if spec.IsMultisite() {
 		// since pools for object store are created by the zone, the object store only needs to be removed from the zone
 		err := removeObjectStoreFromMultisite(objContext, spec)
@@ -97,7 +97,7 @@ func deleteRealmAndPools(objContext *Context, spec cephv1.ObjectStoreSpec) error
 		return nil
 	}
 
-	return deleteSingleSiteRealmAndPools(objContext, spec)
+	return deleteSingleSiteRealmAndPools(objContext, admin, spec)
 }
 
 func removeObjectStoreFromMultisite(objContext *Context, spec cephv1.ObjectStoreSpec) error {
@@ -156,7 +156,7 @@ func removeObjectStoreFromMultisite(objContext *Context, spec cephv1.ObjectStore
 	return nil
 }
 
-func deleteSingleSiteRealmAndPools(objContext *Context, spec cephv1.ObjectStoreSpec) error {
+func deleteSingleSiteRealmAndPools(objContext *Context, admin cephclient.CephAdmin, spec cephv1.ObjectStoreSpec) error {
 	stores, err := getObjectStores(objContext)
 	if err != nil {
 		return errors.Wrap(err, "failed to detect object stores during deletion")
@@ -178,7 +178,7 @@ func deleteSingleSiteRealmAndPools(objContext *Context, spec cephv1.ObjectStoreS
 	}
 
 	if !spec.PreservePoolsOnDelete {
-		err = deletePools(objContext, spec, lastStore)
+		err = deletePools(objContext, admin, spec, lastStore)
 		if err != nil {
 			return errors.Wrap(err, "failed to delete object store pools")
 		}
@@ -623,7 +623,7 @@ func getObjectStores(context *Context) ([]string, error) {
 	return r.Realms, nil
 }
 
-func deletePools(ctx *Context, spec cephv1.ObjectStoreSpec, lastStore bool) error {
+func deletePools(ctx *Context, admin cephclient.CephAdmin, spec cephv1.ObjectStoreSpec, lastStore bool) error {
 	if emptyPool(spec.DataPool) && emptyPool(spec.MetadataPool) {
 		logger.Info("skipping removal of pools since not specified in the object store")
 		return nil
@@ -639,7 +639,7 @@ func deletePools(ctx *Context, spec cephv1.ObjectStoreSpec, lastStore bool) erro
 		for _, pool := range pools {
 			name := poolName(ctx.Name, pool)
 			waitGroup.Go(func() error {
-				if err := cephclient.DeletePool(ctx.Context, ctx.clusterInfo, name); err != nil {
+				if err := admin.DeletePool(name); err != nil {
 					return errors.Wrapf(err, "failed to delete pool %q. ", name)
 				}
 				return nil
@@ -655,7 +655,7 @@ func deletePools(ctx *Context, spec cephv1.ObjectStoreSpec, lastStore bool) erro
 	} else {
 		for _, pool := range pools {
 			name := poolName(ctx.Name, pool)
-			if err := cephclient.DeletePool(ctx.Context, ctx.clusterInfo, name); err != nil {
+			if err := admin.DeletePool(name); err != nil {
 				logger.Warningf("failed to delete pool %q. %v", name, err)
 			}
 		}
@@ -690,12 +690,12 @@ func allObjectPools(storeName string) []string {
 	return poolsForThisStore
 }
 
-func missingPools(context *Context) ([]string, error) {
+func missingPools(context *Context, admin cephclient.CephAdmin) ([]string, error) {
 	// list pools instead of querying each pool individually. querying each individually makes it
 	// hard to determine if an error is because the pool does not exist or because of a connection
 	// issue with ceph mons (or some other underlying issue). if listing pools fails, we can be sure
 	// it is a connection issue and return an error.
-	existingPoolSummaries, err := cephclient.ListPoolSummaries(context.Context, context.clusterInfo)
+	existingPoolSummaries, err := admin.ListPools()
 	if err != nil {
 		return []string{}, errors.Wrapf(err, "failed to determine if pools are missing. failed to list pools")
 	}
@@ -714,10 +714,10 @@ func missingPools(context *Context) ([]string, error) {
 	return missingPools, nil
 }
 
-func CreatePools(context *Context, clusterSpec *cephv1.ClusterSpec, metadataPool, dataPool cephv1.PoolSpec) error {
+func CreatePools(context *Context, admin cephclient.CephAdmin, clusterSpec *cephv1.ClusterSpec, metadataPool, dataPool cephv1.PoolSpec) error {
 	if emptyPool(dataPool) && emptyPool(metadataPool) {
 		logger.Info("no pools specified for the CR, checking for their existence...")
-		missingPools, err := missingPools(context)
+		missingPools, err := missingPools(context, admin)
 		if err != nil {
 			return err
 		}
@@ -733,7 +733,7 @@ func CreatePools(context *Context, clusterSpec *cephv1.ClusterSpec, metadataPool
 		metadataPoolPGs = cephclient.DefaultPGCount
 	}
 
-	if err := createSimilarPools(context, append(metadataPools, rootPool), clusterSpec, metadataPool, metadataPoolPGs, ""); err != nil {
+	if err := createSimilarPools(context, admin, append(metadataPools, rootPool), clusterSpec, metadataPool, metadataPoolPGs, ""); err != nil {
 		return errors.Wrap(err, "failed to create metadata pools")
 	}
 
@@ -746,7 +746,7 @@ func CreatePools(context *Context, clusterSpec *cephv1.ClusterSpec, metadataPool
 		}
 	}
 
-	if err := createSimilarPools(context, []string{dataPoolName}, clusterSpec, dataPool, cephclient.DefaultPGCount, ecProfileName); err != nil {
+	if err := createSimilarPools(context, admin, []string{dataPoolName}, clusterSpec, dataPool, cephclient.DefaultPGCount, ecProfileName); err != nil {
 		return errors.Wrap(err, "failed to create data pool")
 	}
 
@@ -763,7 +763,7 @@ func configurePoolsConcurrently() bool {
 	return true
 }
 
-func createSimilarPools(ctx *Context, pools []string, clusterSpec *cephv1.ClusterSpec, poolSpec cephv1.PoolSpec, pgCount, ecProfileName string) error {
+func createSimilarPools(ctx *Context, admin cephclient.CephAdmin, pools []string, clusterSpec *cephv1.ClusterSpec, poolSpec cephv1.PoolSpec, pgCount, ecProfileName string) error {
 	// We have concurrency
 	if configurePoolsConcurrently() {
 		waitGroup, _ := errgroup.WithContext(context.TODO())
@@ -771,14 +771,14 @@ func createSimilarPools(ctx *Context, pools []string, clusterSpec *cephv1.Cluste
 			// Avoid the loop re-using the same value with a closure
 			pool := pool
 
-			waitGroup.Go(func() error { return createRGWPool(ctx, clusterSpec, poolSpec, pgCount, ecProfileName, pool) })
+			waitGroup.Go(func() error { return createRGWPool(ctx, admin, clusterSpec, poolSpec, pgCount, ecProfileName, pool) })
 		}
 		return waitGroup.Wait()
 	}
 
 	// No concurrency!
 	for _, pool := range pools {
-		err := createRGWPool(ctx, clusterSpec, poolSpec, pgCount, ecProfileName, pool)
+		err := createRGWPool(ctx, admin, clusterSpec, poolSpec, pgCount, ecProfileName, pool)
 		if err != nil {
 			return err
 		}
@@ -787,19 +787,22 @@ func createSimilarPools(ctx *Context, pools []string, clusterSpec *cephv1.Cluste
 	return nil
 }
 
-func createRGWPool(ctx *Context, clusterSpec *cephv1.ClusterSpec, poolSpec cephv1.PoolSpec, pgCount, ecProfileName, pool string) error {
+func createRGWPool(ctx *Context, admin cephclient.CephAdmin, clusterSpec *cephv1.ClusterSpec, poolSpec cephv1.PoolSpec, pgCount, ecProfileName, pool string) error {
 	// create the pool if it doesn't exist yet
 	name := poolName(ctx.Name, pool)
-	if poolDetails, err := cephclient.GetPoolDetails(ctx.Context, ctx.clusterInfo, name); err != nil {
+	if poolSpec.PlacementGroups != nil && poolSpec.PlacementGroups.PgNum > 0 {
+		pgCount = strconv.Itoa(poolSpec.PlacementGroups.PgNum)
+	}
+	if poolDetails, err := admin.GetPoolDetails(name); err != nil {
 		// If the ceph config has an EC profile, an EC pool must be created. Otherwise, it's necessary
 		// to create a replicated pool.
 		var err error
 		if poolSpec.IsErasureCoded() {
 			// An EC pool backing an object store does not need to enable EC overwrites, so the pool is
 			// created with that property disabled to avoid unnecessary performance impact.
-			err = cephclient.CreateECPoolForApp(ctx.Context, ctx.clusterInfo, name, ecProfileName, poolSpec, pgCount, AppName, false /* enableECOverwrite */)
+			err = admin.CreateECPool(name, ecProfileName, poolSpec, pgCount, AppName, false /* enableECOverwrite */)
 		} else {
-			err = cephclient.CreateReplicatedPoolForApp(ctx.Context, ctx.clusterInfo, clusterSpec, name, poolSpec, pgCount, AppName)
+			err = admin.CreateReplicatedPool(clusterSpec, name, poolSpec, pgCount, AppName)
 		}
 		if err != nil {
 			return errors.Wrapf(err, "failed to create pool %s for object store %s.", name, ctx.Name)
@@ -810,7 +813,7 @@ func createRGWPool(ctx *Context, clusterSpec *cephv1.ClusterSpec, poolSpec cephv
 			// detect if the replication is different from the pool details
 			if poolDetails.Size != poolSpec.Replicated.Size {
 				logger.Infof("pool size is changed from %d to %d", poolDetails.Size, poolSpec.Replicated.Size)
-				if err := cephclient.SetPoolReplicatedSizeProperty(ctx.Context, ctx.clusterInfo, poolDetails.Name, strconv.FormatUint(uint64(poolSpec.Replicated.Size), 10)); err != nil {
+				if err := admin.SetPoolReplicatedSize(poolDetails.Name, strconv.FormatUint(uint64(poolSpec.Replicated.Size), 10)); err != nil {
 					return errors.Wrapf(err, "failed to set size property to replicated pool %q to %d", poolDetails.Name, poolSpec.Replicated.Size)
 				}
 			}
@@ -818,7 +821,7 @@ func createRGWPool(ctx *Context, clusterSpec *cephv1.ClusterSpec, poolSpec cephv
 	}
 	// Set the pg_num_min if not the default so the autoscaler won't immediately increase the pg count
 	if pgCount != cephclient.DefaultPGCount {
-		if err := cephclient.SetPoolProperty(ctx.Context, ctx.clusterInfo, name, "pg_num_min", pgCount); err != nil {
+		if err := admin.SetPoolProperty(name, "pg_num_min", pgCount); err != nil {
 			return errors.Wrapf(err, "failed to set pg_num_min on pool %q to %q", name, pgCount)
 		}
 	}
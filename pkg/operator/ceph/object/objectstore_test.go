@@ -28,6 +28,7 @@ import (
 	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
 	"github.com/rook/rook/pkg/clusterd"
 	"github.com/rook/rook/pkg/daemon/ceph/client"
+	cephtest "github.com/rook/rook/pkg/daemon/ceph/client/test"
 	cephver "github.com/rook/rook/pkg/operator/ceph/version"
 	"github.com/rook/rook/pkg/operator/k8sutil"
 	exectest "github.com/rook/rook/pkg/util/exec/test"
@@ -188,7 +189,7 @@ func deleteStore(t *testing.T, name string, existingStores string, expectedDelet
 
 	// Delete an object store without deleting the pools
 	spec := cephv1.ObjectStoreSpec{}
-	err := deleteRealmAndPools(context, spec)
+	err := deleteRealmAndPools(context, client.NewCephAdmin(context.Context, context.clusterInfo), spec)
 	assert.Nil(t, err)
 	expectedPoolsDeleted := 0
 	assert.Equal(t, expectedPoolsDeleted, poolsDeleted)
@@ -203,7 +204,7 @@ func deleteStore(t *testing.T, name string, existingStores string, expectedDelet
 		MetadataPool: cephv1.PoolSpec{Replicated: cephv1.ReplicatedSpec{Size: 1}},
 		DataPool:     cephv1.PoolSpec{Replicated: cephv1.ReplicatedSpec{Size: 1}},
 	}
-	err = deleteRealmAndPools(context, spec)
+	err = deleteRealmAndPools(context, client.NewCephAdmin(context.Context, context.clusterInfo), spec)
 	assert.Nil(t, err)
 	expectedPoolsDeleted = 6
 	if expectedDeleteRootPool {
@@ -214,6 +215,65 @@ func deleteStore(t *testing.T, name string, existingStores string, expectedDelet
 	assert.Equal(t, true, deletedErasureCodeProfile)
 }
 
+func TestCreateAndDeletePoolsInFakeCluster(t *testing.T) {
+	ecProfileCreated := false
+	executor := &exectest.MockExecutor{
+		MockExecuteCommandWithOutput: func(command string, args ...string) (string, error) {
+			if args[0] == "config" && args[1] == "get" {
+				return "8", nil
+			}
+			if args[0] == "osd" && args[1] == "erasure-code-profile" {
+				switch args[2] {
+				case "get":
+					return `{"k":"2","m":"1","plugin":"jerasure","technique":"reed_sol_van"}`, nil
+				case "set":
+					ecProfileCreated = true
+					return "", nil
+				case "ls":
+					return `["default"]`, nil
+				}
+			}
+			return "", errors.Errorf("unexpected ceph command %q", args)
+		},
+	}
+	admin := cephtest.NewFakeCephAdmin()
+	context := &Context{Context: &clusterd.Context{Executor: executor}, Name: "myobj", clusterInfo: client.AdminClusterInfo("mycluster")}
+
+	metadataPool := cephv1.PoolSpec{Replicated: cephv1.ReplicatedSpec{Size: 3}}
+	dataPool := cephv1.PoolSpec{ErasureCoded: cephv1.ErasureCodedSpec{DataChunks: 2, CodingChunks: 1}}
+	err := CreatePools(context, admin, &cephv1.ClusterSpec{}, metadataPool, dataPool)
+	assert.NoError(t, err)
+	assert.True(t, ecProfileCreated)
+	assert.ElementsMatch(t, allObjectPools("myobj"), admin.PoolNames())
+	for _, name := range append(metadataPools, rootPool) {
+		pool := admin.Pools[poolName("myobj", name)]
+		assert.Equal(t, uint(3), pool.Size)
+		assert.Equal(t, AppName, pool.Application)
+		assert.Equal(t, "8", pool.Properties["pg_num_min"])
+	}
+	data := admin.Pools["myobj.rgw.buckets.data"]
+	assert.Equal(t, "myobj_ecprofile", data.ErasureCodeProfile)
+	assert.Equal(t, "", data.Properties["allow_ec_overwrites"])
+	assert.Equal(t, client.DefaultPGCount, data.PgCount)
+
+	// the size of the existing pools is updated
+	metadataPool.Replicated.Size = 2
+	err = CreatePools(context, admin, &cephv1.ClusterSpec{}, metadataPool, dataPool)
+	assert.NoError(t, err)
+	assert.Equal(t, uint(2), admin.Pools["myobj.rgw.meta"].Size)
+
+	// the pools must exist when they are not in the spec
+	admin.AddPool("other", 3)
+	assert.NoError(t, admin.DeletePool("myobj.rgw.log"))
+	err = CreatePools(context, admin, &cephv1.ClusterSpec{}, cephv1.PoolSpec{}, cephv1.PoolSpec{})
+	assert.Error(t, err)
+
+	// the pools of the store are deleted, but not the root pool that is shared with other stores
+	err = deletePools(context, admin, cephv1.ObjectStoreSpec{MetadataPool: metadataPool, DataPool: dataPool}, false)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{".rgw.root", "other"}, admin.PoolNames())
+}
+
 func TestGetObjectBucketProvisioner(t *testing.T) {
 	testNamespace := "test-namespace"
 	os.Setenv(k8sutil.PodNamespaceEnvVar, testNamespace)
@@ -216,7 +216,7 @@ func (c *clusterConfig) startRGWPods(realmName, zoneGroupName, zoneName string)
 
 // Delete the object store.
 // WARNING: This is a very destructive action that deletes all metadata and data pools.
-func (c *clusterConfig) deleteStore() {
+func (c *clusterConfig) deleteStore(admin cephclient.CephAdmin) {
 	logger.Infof("deleting object store %q from namespace %q", c.store.Name, c.store.Namespace)
 
 	if !c.clusterSpec.External.Enable {
@@ -241,7 +241,7 @@ func (c *clusterConfig) deleteStore() {
 
 		go disableRGWDashboard(objContext)
 
-		err = deleteRealmAndPools(objContext, c.store.Spec)
+		err = deleteRealmAndPools(objContext, admin, c.store.Spec)
 		if err != nil {
 			logger.Errorf("failed to delete the realm and pools. Error: %v", err)
 		}
@@ -107,6 +107,29 @@ func updateStatusBucket(client client.Client, name types.NamespacedName, status
 	logger.Debugf("object store %q status updated to %v", name.String(), status)
 }
 
+// updatePoolUsageStatus updates the usage of the pools of an object store
+func updatePoolUsageStatus(client client.Client, name types.NamespacedName, usage []cephv1.PoolUsageStatus) error {
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		objectStore := &cephv1.CephObjectStore{}
+		if err := client.Get(context.TODO(), name, objectStore); err != nil {
+			if kerrors.IsNotFound(err) {
+				logger.Debug("CephObjectStore resource not found. Ignoring since object must be deleted.")
+				return nil
+			}
+			return errors.Wrapf(err, "failed to retrieve object store %q to update the pool usage status", name.String())
+		}
+		if objectStore.Status == nil {
+			objectStore.Status = &cephv1.ObjectStoreStatus{}
+		}
+		objectStore.Status.PoolUsage = usage
+
+		if err := reporting.UpdateStatus(client, objectStore); err != nil {
+			return errors.Wrapf(err, "failed to set object store %q pool usage status", name.String())
+		}
+		return nil
+	})
+}
+
 func buildStatusInfo(cephObjectStore *cephv1.CephObjectStore) map[string]string {
 	m := make(map[string]string)
 
@@ -73,6 +73,8 @@ type ReconcileObjectZone struct {
 	clusterInfo      *cephclient.ClusterInfo
 	clusterSpec      *cephv1.ClusterSpec
 	opManagerContext context.Context
+	// newCephAdmin returns the admin that creates the pools, the tests replace it with a fake cluster
+	newCephAdmin func(context *clusterd.Context, clusterInfo *cephclient.ClusterInfo) cephclient.CephAdmin
 }
 
 // Add creates a new CephObjectZone Controller and adds it to the Manager. The Manager will set fields on the Controller
@@ -88,6 +90,7 @@ func newReconciler(mgr manager.Manager, context *clusterd.Context, opManagerCont
 		scheme:           mgr.GetScheme(),
 		context:          context,
 		opManagerContext: opManagerContext,
+		newCephAdmin:     cephclient.NewCephAdmin,
 	}
 }
 
@@ -115,6 +118,7 @@ func add(mgr manager.Manager, r reconcile.Reconciler) error {
 func (r *ReconcileObjectZone) Reconcile(context context.Context, request reconcile.Request) (reconcile.Result, error) {
 	// workaround because the rook logging mechanism is not compatible with the controller-runtime logging interface
 	reconcileResponse, err := r.reconcile(request)
+	reconcileResponse, err = opcontroller.RequeueIfCephCommandsUnavailable(reconcileResponse, err)
 	if err != nil {
 		logger.Errorf("failed to reconcile: %v", err)
 	}
@@ -260,7 +264,7 @@ func (r *ReconcileObjectZone) createPoolsAndZone(objContext *object.Context, zon
 	zoneGroupArg := fmt.Sprintf("--rgw-zonegroup=%s", zone.Spec.ZoneGroup)
 	zoneArg := fmt.Sprintf("--rgw-zone=%s", zone.Name)
 
-	err := object.CreatePools(objContext, r.clusterSpec, zone.Spec.MetadataPool, zone.Spec.DataPool)
+	err := object.CreatePools(objContext, r.newCephAdmin(r.context, r.clusterInfo), r.clusterSpec, zone.Spec.MetadataPool, zone.Spec.DataPool)
 	if err != nil {
 		return errors.Wrapf(err, "failed to create pools for zone %v", zone.Name)
 	}
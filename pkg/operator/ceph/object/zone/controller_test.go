@@ -184,7 +184,7 @@ func TestCephObjectZoneController(t *testing.T) {
 	// Create a ReconcileObjectZone object with the scheme and fake client.
 	clusterInfo := cephclient.AdminClusterInfo("rook")
 
-	r := &ReconcileObjectZone{client: cl, scheme: s, context: c, clusterInfo: clusterInfo}
+	r := &ReconcileObjectZone{client: cl, scheme: s, context: c, clusterInfo: clusterInfo, newCephAdmin: cephclient.NewCephAdmin}
 
 	// Mock request to simulate Reconcile() being called on an event for a
 	// watched resource .
@@ -226,7 +226,7 @@ func TestCephObjectZoneController(t *testing.T) {
 	cl = fake.NewClientBuilder().WithScheme(s).WithRuntimeObjects(object...).Build()
 
 	// Create a ReconcileObjectZone object with the scheme and fake client.
-	r = &ReconcileObjectZone{client: cl, scheme: r.scheme, context: r.context}
+	r = &ReconcileObjectZone{client: cl, scheme: r.scheme, context: r.context, newCephAdmin: cephclient.NewCephAdmin}
 	res, err = r.Reconcile(ctx, req)
 	assert.NoError(t, err)
 	assert.True(t, res.Requeue)
@@ -277,7 +277,7 @@ func TestCephObjectZoneController(t *testing.T) {
 	}
 	r.context.Executor = executor
 
-	r = &ReconcileObjectZone{client: cl, scheme: r.scheme, context: r.context}
+	r = &ReconcileObjectZone{client: cl, scheme: r.scheme, context: r.context, newCephAdmin: cephclient.NewCephAdmin}
 
 	res, err = r.Reconcile(ctx, req)
 	assert.Error(t, err)
@@ -342,7 +342,7 @@ func TestCephObjectZoneController(t *testing.T) {
 
 	cl = fake.NewClientBuilder().WithScheme(s).WithRuntimeObjects(object...).Build()
 
-	r = &ReconcileObjectZone{client: cl, scheme: s, context: c, clusterInfo: clusterInfo}
+	r = &ReconcileObjectZone{client: cl, scheme: s, context: c, clusterInfo: clusterInfo, newCephAdmin: cephclient.NewCephAdmin}
 
 	err = r.client.Get(context.TODO(), types.NamespacedName{Name: zonegroup, Namespace: namespace}, objectZoneGroup)
 	assert.NoError(t, err, objectZoneGroup)
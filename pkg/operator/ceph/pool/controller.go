@@ -34,6 +34,7 @@ import (
 	"github.com/rook/rook/pkg/operator/ceph/config"
 	opcontroller "github.com/rook/rook/pkg/operator/ceph/controller"
 	"github.com/rook/rook/pkg/operator/ceph/csi/peermap"
+	"github.com/rook/rook/pkg/operator/ceph/reporting"
 	"github.com/rook/rook/pkg/operator/k8sutil"
 	corev1 "k8s.io/api/core/v1"
 	kerrors "k8s.io/apimachinery/pkg/api/errors"
@@ -71,7 +72,12 @@ type ReconcileCephBlockPool struct {
 	context           *clusterd.Context
 	clusterInfo       *cephclient.ClusterInfo
 	blockPoolContexts map[string]*blockPoolHealth
+	migrations        map[string]*poolMigration
+	usageCheckers     *opcontroller.PoolUsageCheckers
+	recorder          *k8sutil.EventReporter
 	opManagerContext  context.Context
+	// newCephAdmin returns the admin that creates and deletes the pools, the tests replace it with a fake cluster
+	newCephAdmin func(context *clusterd.Context, clusterInfo *cephclient.ClusterInfo) cephclient.CephAdmin
 }
 
 type blockPoolHealth struct {
@@ -93,7 +99,11 @@ func newReconciler(mgr manager.Manager, context *clusterd.Context, opManagerCont
 		scheme:            mgr.GetScheme(),
 		context:           context,
 		blockPoolContexts: make(map[string]*blockPoolHealth),
+		migrations:        make(map[string]*poolMigration),
+		usageCheckers:     opcontroller.NewPoolUsageCheckers(),
+		recorder:          k8sutil.NewEventReporter(mgr.GetEventRecorderFor("rook-" + controllerName)),
 		opManagerContext:  opManagerContext,
+		newCephAdmin:      cephclient.NewCephAdmin,
 	}
 }
 
@@ -134,6 +144,7 @@ func add(mgr manager.Manager, r reconcile.Reconciler) error {
 func (r *ReconcileCephBlockPool) Reconcile(context context.Context, request reconcile.Request) (reconcile.Result, error) {
 	// workaround because the rook logging mechanism is not compatible with the controller-runtime logging interface
 	reconcileResponse, err := r.reconcile(request)
+	reconcileResponse, err = opcontroller.RequeueIfCephCommandsUnavailable(reconcileResponse, err)
 	if err != nil {
 		logger.Errorf("failed to reconcile. %v", err)
 	}
@@ -209,14 +220,25 @@ func (r *ReconcileCephBlockPool) reconcile(request reconcile.Request) (reconcile
 
 	// DELETE: the CR was deleted
 	if !cephBlockPool.GetDeletionTimestamp().IsZero() {
+		deps, err := CephBlockPoolDependents(r.opManagerContext, r.client, cephBlockPool)
+		if err != nil {
+			return reconcile.Result{}, err
+		}
+		if !deps.Empty() {
+			err := reporting.ReportDeletionBlockedDueToDependents(logger, r.client, cephBlockPool, deps)
+			return opcontroller.WaitForRequeueIfFinalizerBlocked, err
+		}
+		reporting.ReportDeletionNotBlockedDueToDependents(logger, r.client, r.recorder, cephBlockPool)
+
 		// If the ceph block pool is still in the map, we must remove it during CR deletion
 		// We must remove it first otherwise the checker will panic since the status/info will be nil
 		if blockPoolContextsExists {
 			r.cancelMirrorMonitoring(blockPoolChannelKey)
 		}
+		r.usageCheckers.Stop(blockPoolChannelKey)
 
 		logger.Infof("deleting pool %q", cephBlockPool.Name)
-		err := deletePool(r.context, clusterInfo, cephBlockPool)
+		err = deletePool(r.newCephAdmin(r.context, clusterInfo), cephBlockPool)
 		if err != nil {
 			return opcontroller.ImmediateRetryResult, errors.Wrapf(err, "failed to delete pool %q. ", cephBlockPool.Name)
 		}
@@ -252,9 +274,25 @@ func (r *ReconcileCephBlockPool) reconcile(request reconcile.Request) (reconcile
 	}
 	r.clusterInfo.CephVersion = *cephVersion
 
+	// The placement group settings of the recent versions of ceph must be supported by the daemons
+	// that are running, which are older than the image of the cluster during an upgrade
+	if pgs := cephBlockPool.Spec.PlacementGroups; pgs != nil && (pgs.PgNumMax > 0 || pgs.Bulk != nil) {
+		runningCephVersion, err := cephclient.LeastUptodateDaemonVersion(r.context, clusterInfo, config.MonType)
+		if err != nil {
+			return opcontroller.ImmediateRetryResult, errors.Wrapf(err, "failed to retrieve current ceph %q version", config.MonType)
+		}
+		if err := cephclient.ValidatePlacementGroupsVersion(runningCephVersion, pgs); err != nil {
+			updateStatus(r.client, request.NamespacedName, cephv1.ConditionFailure, nil)
+			return reconcile.Result{}, errors.Wrapf(err, "invalid placement groups of pool %q", cephBlockPool.Name)
+		}
+	}
+
 	// If the CephCluster has enabled the "pg_autoscaler" module and is running Nautilus
 	// we force the pg_autoscale_mode to "on"
 	_, propertyExists := cephBlockPool.Spec.Parameters[cephclient.PgAutoscaleModeProperty]
+	if cephBlockPool.Spec.PlacementGroups != nil && cephBlockPool.Spec.PlacementGroups.AutoscaleMode != "" {
+		propertyExists = true
+	}
 	if mgr.IsModuleInSpec(cephCluster.Spec.Mgr.Modules, mgr.PgautoscalerModuleName) &&
 		!cephVersion.IsAtLeastOctopus() &&
 		!propertyExists {
@@ -280,6 +318,34 @@ func (r *ReconcileCephBlockPool) reconcile(request reconcile.Request) (reconcile
 		return reconcile.Result{}, errors.Wrap(err, "failed to enable/disable stats collection for pool(s)")
 	}
 
+	// Run the goroutine to update the usage of the pool in the status
+	usageChecker := opcontroller.NewPoolUsageChecker(r.context, clusterInfo, r.recorder, cephBlockPool, []string{cephBlockPool.Name},
+		cephBlockPool.Spec.StatusCheck.Usage, func(usage []cephv1.PoolUsageStatus) error {
+			return updatePoolUsageStatus(r.client, request.NamespacedName, usage)
+		})
+	r.usageCheckers.Start(r.opManagerContext, blockPoolChannelKey, usageChecker)
+
+	// PLACEMENT GROUPS are changed gradually toward the target of the spec
+	placementGroupsInfo, placementGroupsDone, err := reconcilePlacementGroups(r.context, clusterInfo, cephBlockPool.Name, cephBlockPool.Spec.PlacementGroups)
+	if err != nil {
+		return opcontroller.ImmediateRetryResult, errors.Wrapf(err, "failed to reconcile the placement groups of pool %q", cephBlockPool.Name)
+	}
+
+	// MIGRATE the data of the images to the target pool
+	progressResponse := reconcile.Result{}
+	if !placementGroupsDone {
+		progressResponse = waitForPlacementGroups
+	}
+	if cephBlockPool.Spec.Migration != nil {
+		done, err := r.reconcileMigration(clusterInfo, &cephCluster.Spec, cephBlockPool)
+		if err != nil {
+			return opcontroller.ImmediateRetryResult, errors.Wrapf(err, "failed to migrate the images of pool %q", cephBlockPool.Name)
+		}
+		if !done {
+			progressResponse = waitForMigration
+		}
+	}
+
 	checker := newMirrorChecker(r.context, r.client, r.clusterInfo, request.NamespacedName, &cephBlockPool.Spec, cephBlockPool.Name)
 	// ADD PEERS
 	logger.Debug("reconciling create rbd mirror peer configuration")
@@ -318,12 +384,16 @@ func (r *ReconcileCephBlockPool) reconcile(request reconcile.Request) (reconcile
 		}
 
 		// Set Ready status, we are done reconciling
-		updateStatus(r.client, request.NamespacedName, cephv1.ConditionReady, opcontroller.GenerateStatusInfo(cephBlockPool))
+		info := opcontroller.GenerateStatusInfo(cephBlockPool)
+		for key, value := range placementGroupsInfo {
+			info[key] = value
+		}
+		updateStatus(r.client, request.NamespacedName, cephv1.ConditionReady, info)
 
 		// If not mirrored there is no Status Info field to fulfil
 	} else {
 		// Set Ready status, we are done reconciling
-		updateStatus(r.client, request.NamespacedName, cephv1.ConditionReady, nil)
+		updateStatus(r.client, request.NamespacedName, cephv1.ConditionReady, placementGroupsInfo)
 
 		// Stop monitoring the mirroring status of this pool
 		if blockPoolContextsExists && r.blockPoolContexts[blockPoolChannelKey].started {
@@ -333,13 +403,13 @@ func (r *ReconcileCephBlockPool) reconcile(request reconcile.Request) (reconcile
 		}
 	}
 
-	// Return and do not requeue
+	// Return and only requeue to check the progress of the placement groups and the migration
 	logger.Debug("done reconciling")
-	return reconcile.Result{}, nil
+	return progressResponse, nil
 }
 
 func (r *ReconcileCephBlockPool) reconcileCreatePool(clusterInfo *cephclient.ClusterInfo, cephCluster *cephv1.ClusterSpec, cephBlockPool *cephv1.CephBlockPool) (reconcile.Result, error) {
-	err := createPool(r.context, clusterInfo, cephCluster, cephBlockPool)
+	err := createPool(r.newCephAdmin(r.context, clusterInfo), cephCluster, cephBlockPool)
 	if err != nil {
 		return opcontroller.ImmediateRetryResult, errors.Wrapf(err, "failed to create pool %q.", cephBlockPool.GetName())
 	}
@@ -349,10 +419,10 @@ func (r *ReconcileCephBlockPool) reconcileCreatePool(clusterInfo *cephclient.Clu
 }
 
 // Create the pool
-func createPool(context *clusterd.Context, clusterInfo *cephclient.ClusterInfo, clusterSpec *cephv1.ClusterSpec, p *cephv1.CephBlockPool) error {
+func createPool(admin cephclient.CephAdmin, clusterSpec *cephv1.ClusterSpec, p *cephv1.CephBlockPool) error {
 	// create the pool
 	logger.Infof("creating pool %q in namespace %q", p.Name, p.Namespace)
-	if err := cephclient.CreatePoolWithProfile(context, clusterInfo, clusterSpec, p.Name, p.Spec, poolApplicationNameRBD); err != nil {
+	if err := admin.CreatePool(clusterSpec, p.Name, p.Spec, poolApplicationNameRBD); err != nil {
 		return errors.Wrapf(err, "failed to create pool %q", p.Name)
 	}
 
@@ -360,8 +430,8 @@ func createPool(context *clusterd.Context, clusterInfo *cephclient.ClusterInfo,
 }
 
 // Delete the pool
-func deletePool(context *clusterd.Context, clusterInfo *cephclient.ClusterInfo, p *cephv1.CephBlockPool) error {
-	pools, err := cephclient.ListPoolSummaries(context, clusterInfo)
+func deletePool(admin cephclient.CephAdmin, p *cephv1.CephBlockPool) error {
+	pools, err := admin.ListPools()
 	if err != nil {
 		return errors.Wrap(err, "failed to list pools")
 	}
@@ -369,7 +439,7 @@ func deletePool(context *clusterd.Context, clusterInfo *cephclient.ClusterInfo,
 	// Only delete the pool if it exists...
 	for _, pool := range pools {
 		if pool.Name == p.Name {
-			err := cephclient.DeletePool(context, clusterInfo, p.Name)
+			err := admin.DeletePool(p.Name)
 			if err != nil {
 				return errors.Wrapf(err, "failed to delete pool %q", p.Name)
 			}
@@ -28,6 +28,7 @@ import (
 	"github.com/rook/rook/pkg/clusterd"
 	"github.com/rook/rook/pkg/daemon/ceph/client"
 	cephclient "github.com/rook/rook/pkg/daemon/ceph/client"
+	cephtest "github.com/rook/rook/pkg/daemon/ceph/client/test"
 	opcontroller "github.com/rook/rook/pkg/operator/ceph/controller"
 	"github.com/rook/rook/pkg/operator/k8sutil"
 	testop "github.com/rook/rook/pkg/operator/test"
@@ -61,14 +62,14 @@ func TestCreatePool(t *testing.T) {
 	p.Spec.Replicated.RequireSafeReplicaSize = false
 
 	clusterSpec := &cephv1.ClusterSpec{Storage: cephv1.StorageScopeSpec{Config: map[string]string{cephclient.CrushRootConfigKey: "cluster-crush-root"}}}
-	err := createPool(context, clusterInfo, clusterSpec, p)
+	err := createPool(cephclient.NewCephAdmin(context, clusterInfo), clusterSpec, p)
 	assert.Nil(t, err)
 
 	// succeed with EC
 	p.Spec.Replicated.Size = 0
 	p.Spec.ErasureCoded.CodingChunks = 1
 	p.Spec.ErasureCoded.DataChunks = 2
-	err = createPool(context, clusterInfo, clusterSpec, p)
+	err = createPool(cephclient.NewCephAdmin(context, clusterInfo), clusterSpec, p)
 	assert.Nil(t, err)
 }
 
@@ -102,21 +103,59 @@ func TestDeletePool(t *testing.T) {
 
 	// delete a pool that exists
 	p := &cephv1.CephBlockPool{ObjectMeta: metav1.ObjectMeta{Name: "mypool", Namespace: clusterInfo.Namespace}}
-	err := deletePool(context, clusterInfo, p)
+	err := deletePool(cephclient.NewCephAdmin(context, clusterInfo), p)
 	assert.Nil(t, err)
 
 	// succeed even if the pool doesn't exist
 	p = &cephv1.CephBlockPool{ObjectMeta: metav1.ObjectMeta{Name: "otherpool", Namespace: clusterInfo.Namespace}}
-	err = deletePool(context, clusterInfo, p)
+	err = deletePool(cephclient.NewCephAdmin(context, clusterInfo), p)
 	assert.Nil(t, err)
 
 	// fail if images/snapshosts exist in the pool
 	failOnDelete = true
 	p = &cephv1.CephBlockPool{ObjectMeta: metav1.ObjectMeta{Name: "mypool", Namespace: clusterInfo.Namespace}}
-	err = deletePool(context, clusterInfo, p)
+	err = deletePool(cephclient.NewCephAdmin(context, clusterInfo), p)
 	assert.NotNil(t, err)
 }
 
+func TestCreateAndDeletePoolInFakeCluster(t *testing.T) {
+	admin := cephtest.NewFakeCephAdmin()
+	clusterSpec := &cephv1.ClusterSpec{}
+
+	// create a replicated pool
+	p := &cephv1.CephBlockPool{ObjectMeta: metav1.ObjectMeta{Name: "mypool", Namespace: "mycluster"}}
+	p.Spec.Replicated.Size = 3
+	assert.NoError(t, createPool(admin, clusterSpec, p))
+	assert.Equal(t, []string{"mypool"}, admin.PoolNames())
+	assert.Equal(t, uint(3), admin.Pools["mypool"].Size)
+	assert.Equal(t, poolApplicationNameRBD, admin.Pools["mypool"].Application)
+
+	// create an erasure coded pool
+	ec := &cephv1.CephBlockPool{ObjectMeta: metav1.ObjectMeta{Name: "ecpool", Namespace: "mycluster"}}
+	ec.Spec.ErasureCoded.DataChunks = 2
+	ec.Spec.ErasureCoded.CodingChunks = 1
+	assert.NoError(t, createPool(admin, clusterSpec, ec))
+	assert.Equal(t, []string{"ecpool", "mypool"}, admin.PoolNames())
+	assert.Equal(t, "ecpool_ecprofile", admin.Pools["ecpool"].ErasureCodeProfile)
+
+	// the pool is not deleted while it has images
+	admin.Pools["mypool"].Images = 1
+	assert.Error(t, deletePool(admin, p))
+	assert.Equal(t, []string{"ecpool", "mypool"}, admin.PoolNames())
+
+	admin.Pools["mypool"].Images = 0
+	assert.NoError(t, deletePool(admin, p))
+	assert.Equal(t, []string{"ecpool"}, admin.PoolNames())
+
+	// deleting a pool that does not exist succeeds
+	assert.NoError(t, deletePool(admin, p))
+
+	// the errors of the cluster are returned
+	admin.Errors["CreatePool"] = errors.New("failed to create pool")
+	assert.Error(t, createPool(admin, clusterSpec, p))
+	assert.Equal(t, []string{"ecpool"}, admin.PoolNames())
+}
+
 // TestCephBlockPoolController runs ReconcileCephBlockPool.Reconcile() against a
 // fake client that tracks a CephBlockPool object.
 func TestCephBlockPoolController(t *testing.T) {
@@ -188,7 +227,9 @@ func TestCephBlockPoolController(t *testing.T) {
 		scheme:            s,
 		context:           c,
 		blockPoolContexts: make(map[string]*blockPoolHealth),
+		usageCheckers:     opcontroller.NewPoolUsageCheckers(),
 		opManagerContext:  context.TODO(),
+		newCephAdmin:      cephclient.NewCephAdmin,
 	}
 
 	// Mock request to simulate Reconcile() being called on an event for a
@@ -243,7 +284,9 @@ func TestCephBlockPoolController(t *testing.T) {
 			scheme:            s,
 			context:           c,
 			blockPoolContexts: make(map[string]*blockPoolHealth),
+			usageCheckers:     opcontroller.NewPoolUsageCheckers(),
 			opManagerContext:  context.TODO(),
+			newCephAdmin:      cephclient.NewCephAdmin,
 		}
 
 		res, err := r.Reconcile(ctx, req)
@@ -300,7 +343,9 @@ func TestCephBlockPoolController(t *testing.T) {
 			scheme:            s,
 			context:           c,
 			blockPoolContexts: make(map[string]*blockPoolHealth),
+			usageCheckers:     opcontroller.NewPoolUsageCheckers(),
 			opManagerContext:  context.TODO(),
+			newCephAdmin:      cephclient.NewCephAdmin,
 		}
 		res, err := r.Reconcile(ctx, req)
 		assert.NoError(t, err)
@@ -371,7 +416,9 @@ func TestCephBlockPoolController(t *testing.T) {
 			scheme:            s,
 			context:           c,
 			blockPoolContexts: make(map[string]*blockPoolHealth),
+			usageCheckers:     opcontroller.NewPoolUsageCheckers(),
 			opManagerContext:  context.TODO(),
+			newCephAdmin:      cephclient.NewCephAdmin,
 		}
 
 		pool.Spec.Mirroring.Mode = "image"
@@ -412,7 +459,9 @@ func TestCephBlockPoolController(t *testing.T) {
 			scheme:            s,
 			context:           c,
 			blockPoolContexts: make(map[string]*blockPoolHealth),
+			usageCheckers:     opcontroller.NewPoolUsageCheckers(),
 			opManagerContext:  context.TODO(),
+			newCephAdmin:      cephclient.NewCephAdmin,
 		}
 
 		pool.Spec.Mirroring.Peers.SecretNames = []string{peerSecretName}
@@ -449,7 +498,9 @@ func TestCephBlockPoolController(t *testing.T) {
 			scheme:            s,
 			context:           c,
 			blockPoolContexts: make(map[string]*blockPoolHealth),
+			usageCheckers:     opcontroller.NewPoolUsageCheckers(),
 			opManagerContext:  context.TODO(),
+			newCephAdmin:      cephclient.NewCephAdmin,
 		}
 		pool.Spec.Mirroring.Enabled = false
 		pool.Spec.Mirroring.Mode = "image"
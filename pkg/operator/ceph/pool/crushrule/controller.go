@@ -0,0 +1,303 @@
+/*
+Copyright 2021 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package crushrule to manage the crush rules built from a list of steps.
+package crushrule
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/coreos/pkg/capnslog"
+	"github.com/pkg/errors"
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	"github.com/rook/rook/pkg/clusterd"
+	cephclient "github.com/rook/rook/pkg/daemon/ceph/client"
+	"github.com/rook/rook/pkg/operator/ceph/cluster/mon"
+	opcontroller "github.com/rook/rook/pkg/operator/ceph/controller"
+	"github.com/rook/rook/pkg/operator/ceph/reporting"
+	"github.com/rook/rook/pkg/operator/k8sutil"
+	"github.com/rook/rook/pkg/util/dependents"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+const (
+	controllerName = "ceph-crush-rule-controller"
+)
+
+var logger = capnslog.NewPackageLogger("github.com/rook/rook", controllerName)
+
+var crushRuleKind = reflect.TypeOf(cephv1.CephCrushRule{}).Name()
+
+// Sets the type meta for the controller main object
+var controllerTypeMeta = metav1.TypeMeta{
+	Kind:       crushRuleKind,
+	APIVersion: fmt.Sprintf("%s/%s", cephv1.CustomResourceGroup, cephv1.Version),
+}
+
+// ReconcileCephCrushRule reconciles a CephCrushRule object
+type ReconcileCephCrushRule struct {
+	client           client.Client
+	context          *clusterd.Context
+	clusterInfo      *cephclient.ClusterInfo
+	recorder         *k8sutil.EventReporter
+	opManagerContext context.Context
+}
+
+// Add creates a new CephCrushRule Controller and adds it to the Manager. The Manager will set fields
+// on the Controller and Start it when the Manager is Started.
+func Add(mgr manager.Manager, context *clusterd.Context, opManagerContext context.Context, opConfig opcontroller.OperatorConfig) error {
+	return add(mgr, newReconciler(mgr, context, opManagerContext))
+}
+
+// newReconciler returns a new reconcile.Reconciler
+func newReconciler(mgr manager.Manager, context *clusterd.Context, opManagerContext context.Context) reconcile.Reconciler {
+	return &ReconcileCephCrushRule{
+		client:           mgr.GetClient(),
+		context:          context,
+		recorder:         k8sutil.NewEventReporter(mgr.GetEventRecorderFor("rook-" + controllerName)),
+		opManagerContext: opManagerContext,
+	}
+}
+
+func add(mgr manager.Manager, r reconcile.Reconciler) error {
+	// Create a new controller
+	c, err := controller.New(controllerName, mgr, controller.Options{Reconciler: r})
+	if err != nil {
+		return err
+	}
+	logger.Info("successfully started")
+
+	// Watch for changes on the CephCrushRule CRD object
+	err = c.Watch(&source.Kind{Type: &cephv1.CephCrushRule{TypeMeta: controllerTypeMeta}}, &handler.EnqueueRequestForObject{}, opcontroller.WatchControllerPredicate())
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Reconcile reads that state of the cluster for a CephCrushRule object and makes changes based on the
+// state read and what is in the CephCrushRule.Spec
+// The Controller will requeue the Request to be processed again if the returned error is non-nil or
+// Result.Requeue is true, otherwise upon completion it will remove the work from the queue.
+func (r *ReconcileCephCrushRule) Reconcile(context context.Context, request reconcile.Request) (reconcile.Result, error) {
+	// workaround because the rook logging mechanism is not compatible with the controller-runtime logging interface
+	reconcileResponse, err := r.reconcile(request)
+	reconcileResponse, err = opcontroller.RequeueIfCephCommandsUnavailable(reconcileResponse, err)
+	if err != nil {
+		logger.Errorf("failed to reconcile %v", err)
+	}
+
+	return reconcileResponse, err
+}
+
+func (r *ReconcileCephCrushRule) reconcile(request reconcile.Request) (reconcile.Result, error) {
+	// Fetch the CephCrushRule instance
+	cephCrushRule := &cephv1.CephCrushRule{}
+	err := r.client.Get(r.opManagerContext, request.NamespacedName, cephCrushRule)
+	if err != nil {
+		if kerrors.IsNotFound(err) {
+			logger.Debug("cephCrushRule resource not found. Ignoring since object must be deleted.")
+			return reconcile.Result{}, nil
+		}
+		// Error reading the object - requeue the request.
+		return reconcile.Result{}, errors.Wrap(err, "failed to get cephCrushRule")
+	}
+
+	// Set a finalizer so we can do cleanup before the object goes away
+	err = opcontroller.AddFinalizerIfNotPresent(r.client, cephCrushRule)
+	if err != nil {
+		return reconcile.Result{}, errors.Wrap(err, "failed to add finalizer")
+	}
+
+	// The CR was just created, initializing status fields
+	if cephCrushRule.Status == nil {
+		r.updateStatus(request.NamespacedName, cephv1.ConditionProgressing, nil, "")
+	}
+
+	// Make sure a CephCluster is present otherwise do nothing
+	cephCluster, isReadyToReconcile, cephClusterExists, reconcileResponse := opcontroller.IsReadyToReconcile(r.client, r.context, request.NamespacedName, controllerName)
+	if !isReadyToReconcile {
+		// The crush map is gone with the CephCluster, only remove the finalizer
+		if !cephCrushRule.GetDeletionTimestamp().IsZero() && !cephClusterExists {
+			err = opcontroller.RemoveFinalizer(r.client, cephCrushRule)
+			if err != nil {
+				return opcontroller.ImmediateRetryResult, errors.Wrap(err, "failed to remove finalizer")
+			}
+
+			// Return and do not requeue. Successful deletion.
+			return reconcile.Result{}, nil
+		}
+		return reconcileResponse, nil
+	}
+
+	// Populate clusterInfo during each reconcile
+	r.clusterInfo, _, _, err = mon.LoadClusterInfo(r.context, r.opManagerContext, request.NamespacedName.Namespace)
+	if err != nil {
+		return reconcile.Result{}, errors.Wrap(err, "failed to populate cluster info")
+	}
+	r.clusterInfo.Context = r.opManagerContext
+	r.clusterInfo.NetworkSpec = cephCluster.Spec.Network
+
+	// DELETE: the CR was deleted
+	if !cephCrushRule.GetDeletionTimestamp().IsZero() {
+		deps, err := r.cephCrushRuleDependents(cephCrushRule)
+		if err != nil {
+			return reconcile.Result{}, err
+		}
+		if !deps.Empty() {
+			err := reporting.ReportDeletionBlockedDueToDependents(logger, r.client, cephCrushRule, deps)
+			return opcontroller.WaitForRequeueIfFinalizerBlocked, err
+		}
+		reporting.ReportDeletionNotBlockedDueToDependents(logger, r.client, r.recorder, cephCrushRule)
+
+		logger.Infof("deleting crush rule %q", cephCrushRule.Name)
+		err = cephclient.DeleteCrushRule(r.context, r.clusterInfo, cephCrushRule.Name)
+		if err != nil {
+			return reconcile.Result{}, err
+		}
+
+		// Remove finalizer
+		err = opcontroller.RemoveFinalizer(r.client, cephCrushRule)
+		if err != nil {
+			return reconcile.Result{}, errors.Wrap(err, "failed to remove finalizer")
+		}
+
+		// Return and do not requeue. Successful deletion.
+		return reconcile.Result{}, nil
+	}
+
+	// Validate the steps before compiling them in the crush map
+	err = cephCrushRule.Spec.Validate()
+	if err != nil {
+		r.updateStatus(request.NamespacedName, cephv1.ConditionFailure, nil, err.Error())
+		return reconcile.Result{}, errors.Wrapf(err, "invalid ceph crush rule %q", cephCrushRule.Name)
+	}
+
+	// Create or update the rule in the crush map
+	ruleID, err := cephclient.ApplyCrushRule(r.context, r.clusterInfo, cephCrushRule.Name, cephCrushRule.Spec)
+	if err != nil {
+		if strings.Contains(err.Error(), opcontroller.UninitializedCephConfigError) {
+			logger.Info(opcontroller.OperatorNotInitializedMessage)
+			return opcontroller.WaitForRequeueIfOperatorNotInitialized, nil
+		}
+		r.updateStatus(request.NamespacedName, cephv1.ConditionFailure, nil, err.Error())
+		return reconcile.Result{}, errors.Wrapf(err, "failed to apply ceph crush rule %q", cephCrushRule.Name)
+	}
+
+	// Success! Let's update the status
+	r.updateStatus(request.NamespacedName, cephv1.ConditionReady, &ruleID, "")
+
+	// Return and do not requeue
+	logger.Debug("done reconciling")
+	return reconcile.Result{}, nil
+}
+
+// cephCrushRuleDependents returns the pools of the cluster that use the crush rule
+func (r *ReconcileCephCrushRule) cephCrushRuleDependents(cephCrushRule *cephv1.CephCrushRule) (*dependents.DependentList, error) {
+	deps := dependents.NewDependentList()
+	namespace := client.InNamespace(cephCrushRule.Namespace)
+
+	cephBlockPools := &cephv1.CephBlockPoolList{}
+	if err := r.client.List(r.opManagerContext, cephBlockPools, namespace); err != nil {
+		return deps, errors.Wrap(err, "failed to list CephBlockPools")
+	}
+	for _, cephBlockPool := range cephBlockPools.Items {
+		if cephBlockPool.Spec.CrushRule == cephCrushRule.Name {
+			deps.Add("CephBlockPools", cephBlockPool.Name)
+		}
+	}
+
+	cephFilesystems := &cephv1.CephFilesystemList{}
+	if err := r.client.List(r.opManagerContext, cephFilesystems, namespace); err != nil {
+		return deps, errors.Wrap(err, "failed to list CephFilesystems")
+	}
+	for _, cephFilesystem := range cephFilesystems.Items {
+		pools := append([]cephv1.PoolSpec{cephFilesystem.Spec.MetadataPool}, cephFilesystem.Spec.DataPools...)
+		if usesCrushRule(pools, cephCrushRule.Name) {
+			deps.Add("CephFilesystems", cephFilesystem.Name)
+		}
+	}
+
+	cephObjectStores := &cephv1.CephObjectStoreList{}
+	if err := r.client.List(r.opManagerContext, cephObjectStores, namespace); err != nil {
+		return deps, errors.Wrap(err, "failed to list CephObjectStores")
+	}
+	for _, cephObjectStore := range cephObjectStores.Items {
+		if usesCrushRule([]cephv1.PoolSpec{cephObjectStore.Spec.MetadataPool, cephObjectStore.Spec.DataPool}, cephCrushRule.Name) {
+			deps.Add("CephObjectStores", cephObjectStore.Name)
+		}
+	}
+
+	cephObjectZones := &cephv1.CephObjectZoneList{}
+	if err := r.client.List(r.opManagerContext, cephObjectZones, namespace); err != nil {
+		return deps, errors.Wrap(err, "failed to list CephObjectZones")
+	}
+	for _, cephObjectZone := range cephObjectZones.Items {
+		if usesCrushRule([]cephv1.PoolSpec{cephObjectZone.Spec.MetadataPool, cephObjectZone.Spec.DataPool}, cephCrushRule.Name) {
+			deps.Add("CephObjectZones", cephObjectZone.Name)
+		}
+	}
+
+	return deps, nil
+}
+
+func usesCrushRule(pools []cephv1.PoolSpec, name string) bool {
+	for _, pool := range pools {
+		if pool.CrushRule == name {
+			return true
+		}
+	}
+	return false
+}
+
+// updateStatus updates an object with a given status
+func (r *ReconcileCephCrushRule) updateStatus(name types.NamespacedName, status cephv1.ConditionType, ruleID *int, message string) {
+	cephCrushRule := &cephv1.CephCrushRule{}
+	if err := r.client.Get(r.opManagerContext, name, cephCrushRule); err != nil {
+		if kerrors.IsNotFound(err) {
+			logger.Debug("CephCrushRule resource not found. Ignoring since object must be deleted.")
+			return
+		}
+		logger.Warningf("failed to retrieve ceph crush rule %q to update status to %q. %v", name, status, err)
+		return
+	}
+	if cephCrushRule.Status == nil {
+		cephCrushRule.Status = &cephv1.CrushRuleStatus{}
+	}
+
+	cephCrushRule.Status.Phase = status
+	cephCrushRule.Status.Message = message
+	if ruleID != nil {
+		cephCrushRule.Status.RuleID = ruleID
+	}
+	if err := reporting.UpdateStatus(r.client, cephCrushRule); err != nil {
+		logger.Errorf("failed to set ceph crush rule %q status to %q. %v", name, status, err)
+		return
+	}
+	logger.Debugf("ceph crush rule %q status updated to %q", name, status)
+}
@@ -0,0 +1,227 @@
+/*
+Copyright 2021 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package crushrule
+
+import (
+	"context"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	rookclient "github.com/rook/rook/pkg/client/clientset/versioned/fake"
+	"github.com/rook/rook/pkg/clusterd"
+	opcontroller "github.com/rook/rook/pkg/operator/ceph/controller"
+	"github.com/rook/rook/pkg/operator/k8sutil"
+	testop "github.com/rook/rook/pkg/operator/test"
+	exectest "github.com/rook/rook/pkg/util/exec/test"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const decompiledCrushMap = `# types
+type 0 osd
+type 1 host
+type 11 root
+
+# rules
+rule replicated_rule {
+	id 0
+	type replicated
+	min_size 1
+	max_size 10
+	step take default
+	step chooseleaf firstn 0 type host
+	step emit
+}
+`
+
+func TestCephCrushRuleController(t *testing.T) {
+	ctx := context.TODO()
+	var (
+		name      = "fast"
+		namespace = "rook-ceph"
+	)
+
+	cephCrushRule := &cephv1.CephCrushRule{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       name,
+			Namespace:  namespace,
+			Finalizers: []string{"cephcrushrule.ceph.rook.io"},
+		},
+		Spec: cephv1.CrushRuleSpec{
+			Steps: []cephv1.CrushRuleStep{
+				{Op: cephv1.CrushRuleStepTake, Item: "default", DeviceClass: "ssd"},
+				{Op: cephv1.CrushRuleStepChooseLeaf, Type: "host"},
+				{Op: cephv1.CrushRuleStepEmit},
+			},
+		},
+	}
+	cephCluster := &cephv1.CephCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      namespace,
+			Namespace: namespace,
+		},
+		Status: cephv1.ClusterStatus{
+			Phase: cephv1.ConditionReady,
+			CephVersion: &cephv1.ClusterVersion{
+				Version: "16.2.6-0",
+			},
+			CephStatus: &cephv1.CephStatus{
+				Health: "HEALTH_OK",
+			},
+		},
+	}
+	cephBlockPool := &cephv1.CephBlockPool{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "replicapool",
+			Namespace: namespace,
+		},
+		Spec: cephv1.PoolSpec{CrushRule: name},
+	}
+
+	compiled := ""
+	commands := []string{}
+	executor := &exectest.MockExecutor{
+		MockExecuteCommandWithOutput: func(command string, args ...string) (string, error) {
+			if command == "crushtool" && args[0] == "--decompile" {
+				return "", ioutil.WriteFile(args[3], []byte(decompiledCrushMap), 0600)
+			}
+			if command == "crushtool" && args[0] == "--compile" {
+				content, err := ioutil.ReadFile(args[1])
+				compiled = string(content)
+				if strings.Contains(compiled, "step take unknown") {
+					return "in rule 'fast' item 'unknown' not defined", errors.New("exit status 1")
+				}
+				return "", err
+			}
+			if args[0] == "osd" {
+				commands = append(commands, strings.Join(args[:3], " "))
+			}
+			if args[0] == "status" {
+				return `{"fsid":"c47cac40-9bee-4d52-823b-ccd803ba5bfe","health":{"checks":{},"status":"HEALTH_OK"},"pgmap":{"num_pgs":100,"pgs_by_state":[{"state_name":"active+clean","count":100}]}}`, nil
+			}
+			return "", nil
+		},
+	}
+	c := &clusterd.Context{
+		Executor:      executor,
+		Clientset:     testop.New(t, 1),
+		RookClientset: rookclient.NewSimpleClientset(),
+	}
+
+	// Mock clusterInfo
+	secret := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "rook-ceph-mon",
+			Namespace: namespace,
+		},
+		Data: map[string][]byte{
+			"fsid":         []byte(name),
+			"mon-secret":   []byte("monsecret"),
+			"admin-secret": []byte("adminsecret"),
+		},
+		Type: k8sutil.RookType,
+	}
+	_, err := c.Clientset.CoreV1().Secrets(namespace).Create(ctx, secret, metav1.CreateOptions{})
+	assert.NoError(t, err)
+
+	newReconcile := func(objects ...runtime.Object) *ReconcileCephCrushRule {
+		s := runtime.NewScheme()
+		assert.NoError(t, v1.AddToScheme(s))
+		assert.NoError(t, cephv1.AddToScheme(s))
+		cl := fake.NewClientBuilder().WithScheme(s).WithRuntimeObjects(objects...).Build()
+		c.Client = cl
+		return &ReconcileCephCrushRule{
+			client:           cl,
+			context:          c,
+			recorder:         k8sutil.NewEventReporter(record.NewFakeRecorder(5)),
+			opManagerContext: ctx,
+		}
+	}
+	req := reconcile.Request{NamespacedName: types.NamespacedName{Name: name, Namespace: namespace}}
+
+	t.Run("invalid steps", func(t *testing.T) {
+		invalid := cephCrushRule.DeepCopy()
+		invalid.Spec.Steps = invalid.Spec.Steps[:2]
+		r := newReconcile(invalid, cephCluster)
+		_, err := r.Reconcile(ctx, req)
+		assert.Error(t, err)
+		assert.Empty(t, commands)
+
+		err = r.client.Get(ctx, req.NamespacedName, invalid)
+		assert.NoError(t, err)
+		assert.Equal(t, cephv1.ConditionFailure, invalid.Status.Phase)
+		assert.Equal(t, "the rule must end with an emit step", invalid.Status.Message)
+	})
+
+	t.Run("unknown bucket", func(t *testing.T) {
+		invalid := cephCrushRule.DeepCopy()
+		invalid.Spec.Steps[0].Item = "unknown"
+		r := newReconcile(invalid, cephCluster)
+		_, err := r.Reconcile(ctx, req)
+		assert.Error(t, err)
+		assert.Equal(t, []string{"osd getcrushmap --out-file"}, commands)
+
+		err = r.client.Get(ctx, req.NamespacedName, invalid)
+		assert.NoError(t, err)
+		assert.Equal(t, cephv1.ConditionFailure, invalid.Status.Phase)
+		assert.Contains(t, invalid.Status.Message, "item 'unknown' not defined")
+	})
+
+	t.Run("success", func(t *testing.T) {
+		commands = []string{}
+		r := newReconcile(cephCrushRule, cephCluster)
+		res, err := r.Reconcile(ctx, req)
+		assert.NoError(t, err)
+		assert.False(t, res.Requeue)
+		assert.Equal(t, []string{"osd getcrushmap --out-file", "osd setcrushmap --in-file"}, commands)
+		assert.Contains(t, compiled, "rule fast {\n        id 1\n")
+
+		err = r.client.Get(ctx, req.NamespacedName, cephCrushRule)
+		assert.NoError(t, err)
+		assert.Equal(t, cephv1.ConditionReady, cephCrushRule.Status.Phase)
+		assert.Equal(t, 1, *cephCrushRule.Status.RuleID)
+		assert.Empty(t, cephCrushRule.Status.Message)
+	})
+
+	t.Run("deletion blocked by a pool", func(t *testing.T) {
+		commands = []string{}
+		now := metav1.Now()
+		cephCrushRule.DeletionTimestamp = &now
+		r := newReconcile(cephCrushRule, cephCluster, cephBlockPool)
+		res, err := r.Reconcile(ctx, req)
+		assert.Error(t, err)
+		assert.Equal(t, opcontroller.WaitForRequeueIfFinalizerBlocked, res)
+		assert.Empty(t, commands)
+	})
+
+	t.Run("deletion", func(t *testing.T) {
+		r := newReconcile(cephCrushRule, cephCluster)
+		res, err := r.Reconcile(ctx, req)
+		assert.NoError(t, err)
+		assert.False(t, res.Requeue)
+		assert.Equal(t, []string{"osd crush rule"}, commands)
+	})
+}
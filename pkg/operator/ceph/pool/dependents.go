@@ -0,0 +1,44 @@
+/*
+Copyright 2021 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pool
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	"github.com/rook/rook/pkg/util/dependents"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// CephBlockPoolDependents returns the rados namespaces created in the pool that should block its deletion
+func CephBlockPoolDependents(ctx context.Context, c client.Client, cephBlockPool *cephv1.CephBlockPool) (*dependents.DependentList, error) {
+	deps := dependents.NewDependentList()
+
+	radosNamespaces := &cephv1.CephBlockPoolRadosNamespaceList{}
+	err := c.List(ctx, radosNamespaces, client.InNamespace(cephBlockPool.Namespace))
+	if err != nil {
+		return deps, errors.Wrapf(err, "failed to list the CephBlockPoolRadosNamespaces of CephBlockPool %q", cephBlockPool.Name)
+	}
+	for _, radosNamespace := range radosNamespaces.Items {
+		if radosNamespace.Spec.BlockPoolName == cephBlockPool.Name {
+			deps.Add("CephBlockPoolRadosNamespaces", radosNamespace.Name)
+		}
+	}
+
+	return deps, nil
+}
@@ -0,0 +1,59 @@
+/*
+Copyright 2021 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pool
+
+import (
+	"context"
+	"testing"
+
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestCephBlockPoolDependents(t *testing.T) {
+	s := runtime.NewScheme()
+	assert.NoError(t, cephv1.AddToScheme(s))
+	cephBlockPool := &cephv1.CephBlockPool{ObjectMeta: metav1.ObjectMeta{Name: "replicapool", Namespace: "rook-ceph"}}
+	radosNamespace := func(name, namespace, pool string) *cephv1.CephBlockPoolRadosNamespace {
+		return &cephv1.CephBlockPoolRadosNamespace{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+			Spec:       cephv1.CephBlockPoolRadosNamespaceSpec{BlockPoolName: pool},
+		}
+	}
+
+	t.Run("no rados namespaces", func(t *testing.T) {
+		c := fake.NewClientBuilder().WithScheme(s).Build()
+		deps, err := CephBlockPoolDependents(context.TODO(), c, cephBlockPool)
+		assert.NoError(t, err)
+		assert.True(t, deps.Empty())
+	})
+
+	t.Run("rados namespaces of the pool", func(t *testing.T) {
+		c := fake.NewClientBuilder().WithScheme(s).WithRuntimeObjects(
+			radosNamespace("namespace-a", "rook-ceph", "replicapool"),
+			radosNamespace("namespace-b", "rook-ceph", "otherpool"),
+			radosNamespace("namespace-c", "other-cluster", "replicapool"),
+		).Build()
+		deps, err := CephBlockPoolDependents(context.TODO(), c, cephBlockPool)
+		assert.NoError(t, err)
+		assert.False(t, deps.Empty())
+		assert.Equal(t, []string{"namespace-a"}, deps.OfPluralKind("CephBlockPoolRadosNamespaces"))
+	})
+}
@@ -0,0 +1,321 @@
+/*
+Copyright 2021 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package failover to promote or demote the mirrored rbd images of an application.
+package failover
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/coreos/pkg/capnslog"
+	"github.com/pkg/errors"
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	"github.com/rook/rook/pkg/clusterd"
+	cephclient "github.com/rook/rook/pkg/daemon/ceph/client"
+	"github.com/rook/rook/pkg/operator/ceph/cluster/mon"
+	opcontroller "github.com/rook/rook/pkg/operator/ceph/controller"
+	"github.com/rook/rook/pkg/operator/ceph/reporting"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+const (
+	controllerName = "ceph-rbd-failover-controller"
+
+	// the split-brain of an image is reported in the description of its mirroring state
+	splitBrainDescription = "split-brain"
+)
+
+var (
+	logger = capnslog.NewPackageLogger("github.com/rook/rook", controllerName)
+
+	// the images are checked until they reach their role
+	waitForImageRole = reconcile.Result{Requeue: true, RequeueAfter: 15 * time.Second}
+	// the mirroring state of the images is refreshed once they reached their role
+	refreshImageStatus = reconcile.Result{Requeue: true, RequeueAfter: time.Minute}
+)
+
+var cephRBDFailoverKind = reflect.TypeOf(cephv1.CephRBDFailover{}).Name()
+
+// Sets the type meta for the controller main object
+var controllerTypeMeta = metav1.TypeMeta{
+	Kind:       cephRBDFailoverKind,
+	APIVersion: fmt.Sprintf("%s/%s", cephv1.CustomResourceGroup, cephv1.Version),
+}
+
+// ReconcileCephRBDFailover reconciles a CephRBDFailover object
+type ReconcileCephRBDFailover struct {
+	client           client.Client
+	scheme           *runtime.Scheme
+	context          *clusterd.Context
+	clusterInfo      *cephclient.ClusterInfo
+	opManagerContext context.Context
+}
+
+// Add creates a new CephRBDFailover Controller and adds it to the Manager. The Manager will set fields
+// on the Controller and Start it when the Manager is Started.
+func Add(mgr manager.Manager, context *clusterd.Context, opManagerContext context.Context, opConfig opcontroller.OperatorConfig) error {
+	return add(mgr, newReconciler(mgr, context, opManagerContext))
+}
+
+// newReconciler returns a new reconcile.Reconciler
+func newReconciler(mgr manager.Manager, context *clusterd.Context, opManagerContext context.Context) reconcile.Reconciler {
+	return &ReconcileCephRBDFailover{
+		client:           mgr.GetClient(),
+		scheme:           mgr.GetScheme(),
+		context:          context,
+		opManagerContext: opManagerContext,
+	}
+}
+
+func add(mgr manager.Manager, r reconcile.Reconciler) error {
+	// Create a new controller
+	c, err := controller.New(controllerName, mgr, controller.Options{Reconciler: r})
+	if err != nil {
+		return err
+	}
+	logger.Info("successfully started")
+
+	// Watch for changes on the CephRBDFailover CRD object
+	err = c.Watch(&source.Kind{Type: &cephv1.CephRBDFailover{TypeMeta: controllerTypeMeta}}, &handler.EnqueueRequestForObject{}, opcontroller.WatchControllerPredicate())
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Reconcile reads that state of the cluster for a CephRBDFailover object and makes changes based on
+// the state read and what is in the CephRBDFailover.Spec
+// The Controller will requeue the Request to be processed again if the returned error is non-nil or
+// Result.Requeue is true, otherwise upon completion it will remove the work from the queue.
+func (r *ReconcileCephRBDFailover) Reconcile(context context.Context, request reconcile.Request) (reconcile.Result, error) {
+	// workaround because the rook logging mechanism is not compatible with the controller-runtime logging interface
+	reconcileResponse, err := r.reconcile(request)
+	reconcileResponse, err = opcontroller.RequeueIfCephCommandsUnavailable(reconcileResponse, err)
+	if err != nil {
+		logger.Errorf("failed to reconcile %v", err)
+	}
+
+	return reconcileResponse, err
+}
+
+func (r *ReconcileCephRBDFailover) reconcile(request reconcile.Request) (reconcile.Result, error) {
+	// Fetch the CephRBDFailover instance
+	cephRBDFailover := &cephv1.CephRBDFailover{}
+	err := r.client.Get(r.opManagerContext, request.NamespacedName, cephRBDFailover)
+	if err != nil {
+		if kerrors.IsNotFound(err) {
+			logger.Debug("cephRBDFailover resource not found. Ignoring since object must be deleted.")
+			return reconcile.Result{}, nil
+		}
+		// Error reading the object - requeue the request.
+		return reconcile.Result{}, errors.Wrap(err, "failed to get cephRBDFailover")
+	}
+
+	// The images keep their role when the failover is deleted
+	if !cephRBDFailover.GetDeletionTimestamp().IsZero() {
+		return reconcile.Result{}, nil
+	}
+
+	// The CR was just created, initializing status fields
+	if cephRBDFailover.Status == nil {
+		r.updateStatus(request.NamespacedName, cephv1.ConditionProgressing, nil)
+	}
+
+	// Make sure a CephCluster is present otherwise do nothing
+	cephCluster, isReadyToReconcile, _, reconcileResponse := opcontroller.IsReadyToReconcile(r.client, r.context, request.NamespacedName, controllerName)
+	if !isReadyToReconcile {
+		return reconcileResponse, nil
+	}
+
+	// validate the failover settings
+	err = cephRBDFailover.Spec.Validate()
+	if err != nil {
+		r.updateStatus(request.NamespacedName, cephv1.ConditionFailure, nil)
+		return reconcile.Result{}, errors.Wrapf(err, "invalid ceph rbd failover %q", cephRBDFailover.Name)
+	}
+
+	// The images must be in a mirrored pool of the cluster
+	cephBlockPool := &cephv1.CephBlockPool{}
+	err = r.client.Get(r.opManagerContext, types.NamespacedName{Name: cephRBDFailover.Spec.Pool, Namespace: request.Namespace}, cephBlockPool)
+	if err != nil {
+		r.updateStatus(request.NamespacedName, cephv1.ConditionFailure, nil)
+		return reconcile.Result{}, errors.Wrapf(err, "failed to get ceph block pool %q of ceph rbd failover %q", cephRBDFailover.Spec.Pool, cephRBDFailover.Name)
+	}
+	if !cephBlockPool.Spec.Mirroring.Enabled {
+		r.updateStatus(request.NamespacedName, cephv1.ConditionFailure, nil)
+		return reconcile.Result{}, errors.Errorf("mirroring is not enabled on ceph block pool %q of ceph rbd failover %q", cephRBDFailover.Spec.Pool, cephRBDFailover.Name)
+	}
+
+	// Populate clusterInfo during each reconcile
+	r.clusterInfo, _, _, err = mon.LoadClusterInfo(r.context, r.opManagerContext, request.NamespacedName.Namespace)
+	if err != nil {
+		if strings.Contains(err.Error(), opcontroller.UninitializedCephConfigError) {
+			logger.Info(opcontroller.OperatorNotInitializedMessage)
+			return opcontroller.WaitForRequeueIfOperatorNotInitialized, nil
+		}
+		return reconcile.Result{}, errors.Wrap(err, "failed to populate cluster info")
+	}
+	r.clusterInfo.Context = r.opManagerContext
+	r.clusterInfo.NetworkSpec = cephCluster.Spec.Network
+
+	images, err := r.selectImages(cephRBDFailover)
+	if err != nil {
+		r.updateStatus(request.NamespacedName, cephv1.ConditionFailure, nil)
+		return reconcile.Result{}, errors.Wrapf(err, "failed to select the images of ceph rbd failover %q", cephRBDFailover.Name)
+	}
+
+	// Promote or demote the images, one image failing does not block the others
+	statuses := make([]cephv1.RBDFailoverImageStatus, 0, len(images))
+	done := true
+	for _, image := range images {
+		status := r.failoverImage(cephRBDFailover, image)
+		if status.Primary != cephRBDFailover.Spec.IsPrimary() || status.Message != "" {
+			done = false
+		}
+		statuses = append(statuses, status)
+	}
+
+	if !done {
+		r.updateStatus(request.NamespacedName, cephv1.ConditionProgressing, statuses)
+		logger.Infof("waiting for the images of ceph rbd failover %q to be %s", cephRBDFailover.Name, cephRBDFailover.Spec.Role)
+		return waitForImageRole, nil
+	}
+
+	// Success! Let's update the status
+	r.updateStatus(request.NamespacedName, cephv1.ConditionReady, statuses)
+
+	// Requeue to report the mirroring state of the images
+	logger.Debug("done reconciling")
+	return refreshImageStatus, nil
+}
+
+// failoverImage promotes or demotes an image, and resyncs a demoted image in split-brain. The status
+// reports why the image did not reach its role yet.
+func (r *ReconcileCephRBDFailover) failoverImage(cephRBDFailover *cephv1.CephRBDFailover, image rbdImage) cephv1.RBDFailoverImageStatus {
+	pool := cephRBDFailover.Spec.Pool
+	status := cephv1.RBDFailoverImageStatus{Name: image.String(), PVC: image.pvc}
+
+	primary, err := cephclient.IsImagePrimary(r.context, r.clusterInfo, pool, image.namespace, image.name)
+	if err != nil {
+		status.Message = err.Error()
+		return status
+	}
+
+	if cephRBDFailover.Spec.IsPrimary() && !primary {
+		// A planned promotion fails until the peer cluster demoted the image and the demotion is synced
+		err = cephclient.PromoteImage(r.context, r.clusterInfo, pool, image.namespace, image.name, cephRBDFailover.Spec.Force)
+		if err != nil {
+			logger.Infof("image %q is not promoted yet. %v", image, err)
+			status.Message = fmt.Sprintf("waiting for the peer cluster to demote the image. %v", err)
+		} else {
+			primary = true
+		}
+	}
+	if !cephRBDFailover.Spec.IsPrimary() && primary {
+		err = cephclient.DemoteImage(r.context, r.clusterInfo, pool, image.namespace, image.name)
+		if err != nil {
+			status.Message = err.Error()
+		} else {
+			primary = false
+		}
+	}
+	status.Primary = primary
+
+	mirrorStatus, err := cephclient.GetImageMirrorStatus(r.context, r.clusterInfo, pool, image.namespace, image.name)
+	if err != nil {
+		if status.Message == "" {
+			status.Message = err.Error()
+		}
+		return status
+	}
+	status.State = mirrorStatus.State
+	status.Description = mirrorStatus.Description
+	status.LastUpdate = mirrorStatus.LastUpdate
+
+	// The image of the former primary cluster diverged after a forced promotion on the peer cluster. The
+	// resync is only requested once until the mirroring state of the image changes.
+	if !primary && strings.Contains(mirrorStatus.Description, splitBrainDescription) {
+		previous := previousImageStatus(cephRBDFailover, status.Name)
+		if previous != nil && previous.ResyncRequested && previous.State == status.State && previous.Description == status.Description {
+			status.ResyncRequested = true
+			status.Message = "resyncing the image from the peer cluster"
+			return status
+		}
+		err = cephclient.ResyncImage(r.context, r.clusterInfo, pool, image.namespace, image.name)
+		if err != nil {
+			status.Message = err.Error()
+		} else {
+			status.ResyncRequested = true
+			status.Message = "resyncing the image from the peer cluster"
+		}
+	}
+
+	return status
+}
+
+// previousImageStatus returns the status of the image reported by the last reconcile, or nil if the
+// image was not reported yet
+func previousImageStatus(cephRBDFailover *cephv1.CephRBDFailover, name string) *cephv1.RBDFailoverImageStatus {
+	if cephRBDFailover.Status == nil {
+		return nil
+	}
+	for i := range cephRBDFailover.Status.Images {
+		if cephRBDFailover.Status.Images[i].Name == name {
+			return &cephRBDFailover.Status.Images[i]
+		}
+	}
+	return nil
+}
+
+// updateStatus updates an object with a given status
+func (r *ReconcileCephRBDFailover) updateStatus(name types.NamespacedName, phase cephv1.ConditionType, images []cephv1.RBDFailoverImageStatus) {
+	cephRBDFailover := &cephv1.CephRBDFailover{}
+	if err := r.client.Get(r.opManagerContext, name, cephRBDFailover); err != nil {
+		if kerrors.IsNotFound(err) {
+			logger.Debug("CephRBDFailover resource not found. Ignoring since object must be deleted.")
+			return
+		}
+		logger.Warningf("failed to retrieve ceph rbd failover %q to update status to %q. %v", name, phase, err)
+		return
+	}
+
+	if cephRBDFailover.Status == nil {
+		cephRBDFailover.Status = &cephv1.RBDFailoverStatus{}
+	}
+	cephRBDFailover.Status.Phase = phase
+	if images != nil {
+		cephRBDFailover.Status.Images = images
+	}
+	if err := reporting.UpdateStatus(r.client, cephRBDFailover); err != nil {
+		logger.Errorf("failed to set ceph rbd failover %q status to %q. %v", name, phase, err)
+		return
+	}
+	logger.Debugf("ceph rbd failover %q status updated to %q", name, phase)
+}
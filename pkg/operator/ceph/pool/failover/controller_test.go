@@ -0,0 +1,314 @@
+/*
+Copyright 2021 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package failover
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/pkg/errors"
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	rookclient "github.com/rook/rook/pkg/client/clientset/versioned/fake"
+	"github.com/rook/rook/pkg/clusterd"
+	"github.com/rook/rook/pkg/operator/k8sutil"
+	testop "github.com/rook/rook/pkg/operator/test"
+	exectest "github.com/rook/rook/pkg/util/exec/test"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const dummyVersionsRaw = `
+{
+	"mon": {
+		"ceph version 16.2.6 (ee28fb57e47e9f88813e24bbf4c14496ca299d31) pacific (stable)": 3
+	}
+}`
+
+func newScheme(t *testing.T) *runtime.Scheme {
+	s := runtime.NewScheme()
+	assert.NoError(t, v1.AddToScheme(s))
+	assert.NoError(t, cephv1.AddToScheme(s))
+	return s
+}
+
+// mockImage is the mirroring state of an image in the mock cluster
+type mockImage struct {
+	primary     bool
+	description string
+	// whether the peer cluster demoted the image
+	peerDemoted bool
+	resynced    bool
+}
+
+func TestCephRBDFailoverController(t *testing.T) {
+	ctx := context.TODO()
+	var (
+		name      = "app-1"
+		namespace = "rook-ceph"
+	)
+
+	cephRBDFailover := &cephv1.CephRBDFailover{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+		},
+		Spec: cephv1.RBDFailoverSpec{
+			Pool:   "replicapool",
+			Images: []string{"image-1"},
+			Role:   cephv1.RBDImageRolePrimary,
+		},
+	}
+	cephBlockPool := &cephv1.CephBlockPool{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "replicapool",
+			Namespace: namespace,
+		},
+		Spec: cephv1.PoolSpec{
+			Mirroring: cephv1.MirroringSpec{Enabled: true, Mode: "image"},
+		},
+	}
+	cephCluster := &cephv1.CephCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      namespace,
+			Namespace: namespace,
+		},
+		Status: cephv1.ClusterStatus{
+			Phase: cephv1.ConditionReady,
+			CephVersion: &cephv1.ClusterVersion{
+				Version: "16.2.6-0",
+			},
+			CephStatus: &cephv1.CephStatus{
+				Health: "HEALTH_OK",
+			},
+		},
+	}
+
+	images := map[string]*mockImage{
+		"replicapool/image-1": {},
+		"replicapool/image-2": {},
+	}
+	executor := &exectest.MockExecutor{
+		MockExecuteCommandWithOutput: func(command string, args ...string) (string, error) {
+			if command == "rbd" && args[0] == "info" {
+				image := images[args[1]]
+				return fmt.Sprintf(`{"name":"image","mirroring":{"mode":"snapshot","state":"enabled","primary":%t}}`, image.primary), nil
+			}
+			if command == "rbd" && args[0] == "mirror" && args[1] == "image" {
+				image := images[args[3]]
+				switch args[2] {
+				case "promote":
+					if !image.peerDemoted && args[4] != "--force" {
+						return "", errors.New("image is primary within a remote cluster or demotion is not propagated yet")
+					}
+					image.primary = true
+				case "demote":
+					image.primary = false
+				case "resync":
+					image.resynced = true
+				case "status":
+					return fmt.Sprintf(`{"name":"image","global_id":"1","state":"up+replaying","description":%q,"last_update":"2021-07-01 10:00:00"}`, image.description), nil
+				}
+				return "", nil
+			}
+			if args[0] == "status" {
+				return `{"fsid":"c47cac40-9bee-4d52-823b-ccd803ba5bfe","health":{"checks":{},"status":"HEALTH_OK"},"pgmap":{"num_pgs":100,"pgs_by_state":[{"state_name":"active+clean","count":100}]}}`, nil
+			}
+			if args[0] == "versions" {
+				return dummyVersionsRaw, nil
+			}
+			return "", nil
+		},
+	}
+	c := &clusterd.Context{
+		Executor:      executor,
+		Clientset:     testop.New(t, 1),
+		RookClientset: rookclient.NewSimpleClientset(),
+	}
+
+	// Mock clusterInfo
+	secret := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "rook-ceph-mon",
+			Namespace: namespace,
+		},
+		Data: map[string][]byte{
+			"fsid":         []byte(name),
+			"mon-secret":   []byte("monsecret"),
+			"admin-secret": []byte("adminsecret"),
+		},
+		Type: k8sutil.RookType,
+	}
+	_, err := c.Clientset.CoreV1().Secrets(namespace).Create(ctx, secret, metav1.CreateOptions{})
+	assert.NoError(t, err)
+
+	cl := fake.NewClientBuilder().WithScheme(newScheme(t)).WithRuntimeObjects(cephRBDFailover, cephBlockPool, cephCluster).Build()
+	c.Client = cl
+	r := &ReconcileCephRBDFailover{
+		client:           cl,
+		context:          c,
+		opManagerContext: ctx,
+	}
+	req := reconcile.Request{NamespacedName: types.NamespacedName{Name: name, Namespace: namespace}}
+	getFailover := func() *cephv1.CephRBDFailover {
+		failover := &cephv1.CephRBDFailover{}
+		err := cl.Get(ctx, req.NamespacedName, failover)
+		assert.NoError(t, err)
+		return failover
+	}
+	updateSpec := func(update func(spec *cephv1.RBDFailoverSpec)) {
+		failover := getFailover()
+		update(&failover.Spec)
+		err := cl.Update(ctx, failover)
+		assert.NoError(t, err)
+	}
+
+	t.Run("wait for the peer cluster to demote the image", func(t *testing.T) {
+		res, err := r.Reconcile(ctx, req)
+		assert.NoError(t, err)
+		assert.Equal(t, waitForImageRole, res)
+		assert.False(t, images["replicapool/image-1"].primary)
+		status := getFailover().Status
+		assert.Equal(t, cephv1.ConditionProgressing, status.Phase)
+		assert.Equal(t, 1, len(status.Images))
+		assert.Equal(t, "image-1", status.Images[0].Name)
+		assert.False(t, status.Images[0].Primary)
+		assert.Contains(t, status.Images[0].Message, "waiting for the peer cluster to demote the image")
+	})
+
+	t.Run("promote the image", func(t *testing.T) {
+		images["replicapool/image-1"].peerDemoted = true
+		res, err := r.Reconcile(ctx, req)
+		assert.NoError(t, err)
+		assert.Equal(t, refreshImageStatus, res)
+		assert.True(t, images["replicapool/image-1"].primary)
+		status := getFailover().Status
+		assert.Equal(t, cephv1.ConditionReady, status.Phase)
+		assert.True(t, status.Images[0].Primary)
+		assert.Equal(t, "up+replaying", status.Images[0].State)
+		assert.Empty(t, status.Images[0].Message)
+	})
+
+	t.Run("force the promotion", func(t *testing.T) {
+		updateSpec(func(spec *cephv1.RBDFailoverSpec) {
+			spec.Images = []string{"image-2"}
+			spec.Force = true
+		})
+		res, err := r.Reconcile(ctx, req)
+		assert.NoError(t, err)
+		assert.Equal(t, refreshImageStatus, res)
+		assert.True(t, images["replicapool/image-2"].primary)
+	})
+
+	t.Run("demote and resync the image in split-brain", func(t *testing.T) {
+		updateSpec(func(spec *cephv1.RBDFailoverSpec) {
+			spec.Role = cephv1.RBDImageRoleSecondary
+			spec.Force = false
+		})
+		images["replicapool/image-2"].description = "split-brain"
+		res, err := r.Reconcile(ctx, req)
+		assert.NoError(t, err)
+		assert.Equal(t, waitForImageRole, res)
+		assert.False(t, images["replicapool/image-2"].primary)
+		assert.True(t, images["replicapool/image-2"].resynced)
+		status := getFailover().Status
+		assert.Equal(t, cephv1.ConditionProgressing, status.Phase)
+		assert.Equal(t, "resyncing the image from the peer cluster", status.Images[0].Message)
+		assert.True(t, status.Images[0].ResyncRequested)
+
+		// the resync is not requested again while the mirroring state of the image did not change
+		images["replicapool/image-2"].resynced = false
+		res, err = r.Reconcile(ctx, req)
+		assert.NoError(t, err)
+		assert.Equal(t, waitForImageRole, res)
+		assert.False(t, images["replicapool/image-2"].resynced)
+		status = getFailover().Status
+		assert.Equal(t, "resyncing the image from the peer cluster", status.Images[0].Message)
+		assert.True(t, status.Images[0].ResyncRequested)
+
+		// the image is replaying again after the resync
+		images["replicapool/image-2"].description = "replaying"
+		res, err = r.Reconcile(ctx, req)
+		assert.NoError(t, err)
+		assert.Equal(t, refreshImageStatus, res)
+		status = getFailover().Status
+		assert.Equal(t, cephv1.ConditionReady, status.Phase)
+		assert.False(t, status.Images[0].ResyncRequested)
+	})
+
+	t.Run("select the images of the pvcs", func(t *testing.T) {
+		for i, pvName := range []string{"pvc-1", "pvc-2"} {
+			pool := "replicapool"
+			if i == 1 {
+				pool = "otherpool"
+			}
+			pv := &v1.PersistentVolume{
+				ObjectMeta: metav1.ObjectMeta{Name: pvName},
+				Spec: v1.PersistentVolumeSpec{
+					PersistentVolumeSource: v1.PersistentVolumeSource{
+						CSI: &v1.CSIPersistentVolumeSource{
+							Driver:           "rook-ceph.rbd.csi.ceph.com",
+							VolumeAttributes: map[string]string{"clusterID": namespace, "pool": pool, "imageName": "image-1"},
+						},
+					},
+				},
+			}
+			_, err = c.Clientset.CoreV1().PersistentVolumes().Create(ctx, pv, metav1.CreateOptions{})
+			assert.NoError(t, err)
+			pvc := &v1.PersistentVolumeClaim{
+				ObjectMeta: metav1.ObjectMeta{Name: pvName, Namespace: "app", Labels: map[string]string{"app": "app-1"}},
+				Spec:       v1.PersistentVolumeClaimSpec{VolumeName: pvName},
+				Status:     v1.PersistentVolumeClaimStatus{Phase: v1.ClaimBound},
+			}
+			_, err = c.Clientset.CoreV1().PersistentVolumeClaims("app").Create(ctx, pvc, metav1.CreateOptions{})
+			assert.NoError(t, err)
+		}
+
+		updateSpec(func(spec *cephv1.RBDFailoverSpec) {
+			spec.PVCSelector = &metav1.LabelSelector{MatchLabels: map[string]string{"app": "app-1"}}
+			spec.PVCNamespace = "app"
+		})
+		res, err := r.Reconcile(ctx, req)
+		assert.NoError(t, err)
+		assert.Equal(t, refreshImageStatus, res)
+		assert.False(t, images["replicapool/image-1"].primary)
+		status := getFailover().Status
+		assert.Equal(t, 2, len(status.Images))
+		// the image of the pvc in the other pool is not selected
+		assert.Equal(t, "image-1", status.Images[0].Name)
+		assert.Equal(t, "app/pvc-1", status.Images[0].PVC)
+		assert.Equal(t, "image-2", status.Images[1].Name)
+	})
+
+	t.Run("mirroring disabled on the pool", func(t *testing.T) {
+		cephBlockPool := &cephv1.CephBlockPool{}
+		err := cl.Get(ctx, types.NamespacedName{Name: "replicapool", Namespace: namespace}, cephBlockPool)
+		assert.NoError(t, err)
+		cephBlockPool.Spec.Mirroring.Enabled = false
+		err = cl.Update(ctx, cephBlockPool)
+		assert.NoError(t, err)
+
+		_, err = r.Reconcile(ctx, req)
+		assert.Error(t, err)
+		assert.Equal(t, cephv1.ConditionFailure, getFailover().Status.Phase)
+	})
+}
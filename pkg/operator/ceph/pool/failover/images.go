@@ -0,0 +1,117 @@
+/*
+Copyright 2021 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package failover
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	"github.com/rook/rook/pkg/operator/ceph/csi"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// rbdImage is an image of a failover
+type rbdImage struct {
+	name      string
+	namespace string
+	// the namespaced name of the pvc of the image, if selected by pvc
+	pvc string
+}
+
+func (i rbdImage) String() string {
+	if i.namespace != "" {
+		return i.namespace + "/" + i.name
+	}
+	return i.name
+}
+
+// selectImages returns the images of the failover by name and the images of the pvcs selected by
+// labels, sorted by name
+func (r *ReconcileCephRBDFailover) selectImages(cephRBDFailover *cephv1.CephRBDFailover) ([]rbdImage, error) {
+	spec := cephRBDFailover.Spec
+	images := map[string]rbdImage{}
+	for _, name := range spec.Images {
+		image := rbdImage{name: name, namespace: spec.RadosNamespace}
+		images[image.String()] = image
+	}
+
+	if spec.PVCSelector != nil {
+		pvcImages, err := r.pvcImages(spec.Pool, spec.PVCNamespace, spec.PVCSelector)
+		if err != nil {
+			return nil, err
+		}
+		for _, image := range pvcImages {
+			images[image.String()] = image
+		}
+	}
+
+	selected := make([]rbdImage, 0, len(images))
+	for _, image := range images {
+		selected = append(selected, image)
+	}
+	sort.Slice(selected, func(i, j int) bool { return selected[i].String() < selected[j].String() })
+	return selected, nil
+}
+
+// pvcImages returns the images of the bound pvcs with the labels, provisioned in the pool of the cluster
+func (r *ReconcileCephRBDFailover) pvcImages(pool, pvcNamespace string, pvcSelector *metav1.LabelSelector) ([]rbdImage, error) {
+	selector, err := metav1.LabelSelectorAsSelector(pvcSelector)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid pvc selector")
+	}
+
+	clusterIDs, err := csi.ClusterIDs(r.context.Clientset, r.opManagerContext, r.clusterInfo.Namespace)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get the csi cluster ids of the cluster")
+	}
+	clusterIDSet := sets.NewString(clusterIDs...)
+
+	pvcs, err := r.context.Clientset.CoreV1().PersistentVolumeClaims(pvcNamespace).List(r.opManagerContext, metav1.ListOptions{LabelSelector: selector.String()})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list pvcs")
+	}
+
+	images := []rbdImage{}
+	for _, pvc := range pvcs.Items {
+		if pvc.Status.Phase != v1.ClaimBound || pvc.Spec.VolumeName == "" {
+			logger.Debugf("skipping pvc %s/%s that is not bound", pvc.Namespace, pvc.Name)
+			continue
+		}
+		pv, err := r.context.Clientset.CoreV1().PersistentVolumes().Get(r.opManagerContext, pvc.Spec.VolumeName, metav1.GetOptions{})
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to get pv %q of pvc %s/%s", pvc.Spec.VolumeName, pvc.Namespace, pvc.Name)
+		}
+		if pv.Spec.CSI == nil || !strings.HasSuffix(pv.Spec.CSI.Driver, csi.RBDDriverNameSuffix) {
+			continue
+		}
+		attributes := pv.Spec.CSI.VolumeAttributes
+		if !clusterIDSet.Has(attributes["clusterID"]) || attributes["pool"] != pool || attributes["imageName"] == "" {
+			continue
+		}
+		images = append(images, rbdImage{
+			name:      attributes["imageName"],
+			namespace: attributes["radosNamespace"],
+			pvc:       pvc.Namespace + "/" + pvc.Name,
+		})
+	}
+
+	return images, nil
+}
@@ -0,0 +1,336 @@
+/*
+Copyright 2021 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pool
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	"github.com/rook/rook/pkg/clusterd"
+	cephclient "github.com/rook/rook/pkg/daemon/ceph/client"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const (
+	// the state of the images whose migration did not start
+	migrationPending = "pending"
+)
+
+// the progress of the migrations is checked until all the images are migrated
+var waitForMigration = reconcile.Result{Requeue: true, RequeueAfter: 30 * time.Second}
+
+// poolMigration tracks the images of a pool whose migration is executed in the background
+type poolMigration struct {
+	mutex   sync.Mutex
+	running map[string]bool
+	// the error of the last execution of the migration of an image
+	errors map[string]string
+}
+
+// migrationImage is an image whose data is moved to the target pool
+type migrationImage struct {
+	pool      string
+	namespace string
+	name      string
+}
+
+func (i migrationImage) String() string {
+	if i.namespace != "" {
+		return i.pool + "/" + i.namespace + "/" + i.name
+	}
+	return i.pool + "/" + i.name
+}
+
+func newPoolMigration() *poolMigration {
+	return &poolMigration{running: map[string]bool{}, errors: map[string]string{}}
+}
+
+// isRunning returns whether the migration of an image is executed in the background, and the error
+// of its last execution
+func (m *poolMigration) isRunning(image migrationImage) (bool, string) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	return m.running[image.String()], m.errors[image.String()]
+}
+
+func (m *poolMigration) runningCount() int {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	return len(m.running)
+}
+
+// execute copies the blocks of a prepared image in the background, the migration is committed by
+// the reconcile once executed
+func (m *poolMigration) execute(context *clusterd.Context, clusterInfo *cephclient.ClusterInfo, image migrationImage) {
+	m.mutex.Lock()
+	if m.running[image.String()] {
+		m.mutex.Unlock()
+		return
+	}
+	m.running[image.String()] = true
+	delete(m.errors, image.String())
+	m.mutex.Unlock()
+
+	go func() {
+		err := cephclient.ExecuteImageMigration(context, clusterInfo, image.pool, image.namespace, image.name)
+		m.mutex.Lock()
+		defer m.mutex.Unlock()
+		delete(m.running, image.String())
+		if err != nil {
+			logger.Errorf("failed to migrate image %q. %v", image, err)
+			m.errors[image.String()] = err.Error()
+		}
+	}()
+}
+
+// reconcileMigration creates the target pool of the migration and moves the data of the images to
+// it, a few images at a time. The new images of a replicated pool are created with their data in the
+// target pool. It returns whether all the images are migrated.
+func (r *ReconcileCephBlockPool) reconcileMigration(clusterInfo *cephclient.ClusterInfo, clusterSpec *cephv1.ClusterSpec, cephBlockPool *cephv1.CephBlockPool) (bool, error) {
+	migration := cephBlockPool.Spec.Migration
+	target := migration.TargetPoolSpec(cephBlockPool.Spec)
+	if err := r.newCephAdmin(r.context, clusterInfo).CreatePool(clusterSpec, migration.TargetPool, target, poolApplicationNameRBD); err != nil {
+		return false, errors.Wrapf(err, "failed to create migration target pool %q", migration.TargetPool)
+	}
+
+	// The images of an erasure coded pool have their metadata in the replicated pools
+	metadataPools := []string{cephBlockPool.Name}
+	if !cephBlockPool.Spec.IsReplicated() {
+		var err error
+		metadataPools, err = r.replicatedPools(cephBlockPool.Namespace, cephBlockPool.Name)
+		if err != nil {
+			return false, err
+		}
+	} else if err := cephclient.SetDefaultDataPool(r.context, clusterInfo, cephBlockPool.Name, migration.TargetPool); err != nil {
+		return false, err
+	}
+
+	key := types.NamespacedName{Name: cephBlockPool.Name, Namespace: cephBlockPool.Namespace}.String()
+	if r.migrations[key] == nil {
+		r.migrations[key] = newPoolMigration()
+	}
+	status, err := migrateImages(r.context, clusterInfo, r.migrations[key], cephBlockPool, r.radosNamespaces, metadataPools)
+	if err != nil {
+		return false, err
+	}
+
+	updateMigrationStatus(r.client, types.NamespacedName{Name: cephBlockPool.Name, Namespace: cephBlockPool.Namespace}, status)
+	return status.Phase == cephv1.ConditionReady, nil
+}
+
+// migrateImages moves the data of the images of the metadata pools whose data is in the pool, and
+// returns the progress of the migration
+func migrateImages(context *clusterd.Context, clusterInfo *cephclient.ClusterInfo, m *poolMigration, cephBlockPool *cephv1.CephBlockPool,
+	radosNamespaces func(pool string) ([]string, error), metadataPools []string) (*cephv1.PoolMigrationStatus, error) {
+	migration := cephBlockPool.Spec.Migration
+	status := &cephv1.PoolMigrationStatus{TargetPool: migration.TargetPool, Images: []cephv1.PoolMigrationImageStatus{}}
+	pending := []migrationImage{}
+	// the index of the status of the pending images
+	pendingStatus := map[string]int{}
+	failed := false
+
+	// the images in use whose workloads are scaled down by the operator to prepare their migration
+	poolKey := types.NamespacedName{Name: cephBlockPool.Name, Namespace: cephBlockPool.Namespace}.String()
+	scaleDown := map[string]bool{}
+	for _, image := range migration.ScaleDownImages {
+		scaleDown[image] = true
+	}
+	scaled, err := scaledDownWorkloads(context, clusterInfo, poolKey)
+	if err != nil {
+		return nil, err
+	}
+	inUse := map[string]bool{}
+
+	for _, pool := range metadataPools {
+		namespaces, err := radosNamespaces(pool)
+		if err != nil {
+			return nil, err
+		}
+		for _, namespace := range append([]string{""}, namespaces...) {
+			names, err := cephclient.ListImageNames(context, clusterInfo, pool, namespace)
+			if err != nil {
+				return nil, err
+			}
+			for _, name := range names {
+				image := migrationImage{pool: pool, namespace: namespace, name: name}
+				imageMigration, watchers, err := cephclient.GetImageMigration(context, clusterInfo, pool, namespace, name)
+				if err != nil {
+					return nil, err
+				}
+
+				if imageMigration == nil {
+					dataPool, err := cephclient.GetImageDataPool(context, clusterInfo, pool, namespace, name)
+					if err != nil {
+						return nil, err
+					}
+					if dataPool == migration.TargetPool {
+						status.TotalImages++
+						status.MigratedImages++
+						continue
+					}
+					// the data of the image is in another pool
+					if dataPool != cephBlockPool.Name && (dataPool != "" || pool != cephBlockPool.Name) {
+						continue
+					}
+					status.TotalImages++
+					imageStatus := cephv1.PoolMigrationImageStatus{Name: image.String(), State: migrationPending}
+					if len(watchers) > 0 {
+						// rbd cannot prepare the migration of an image in use, the operator only
+						// stops the clients of the images that opted in
+						status.BlockedImages++
+						inUse[image.String()] = true
+						if scaleDown[image.String()] {
+							pending = append(pending, image)
+						} else {
+							imageStatus.Message = "the image is in use, its clients must be stopped or the image added to scaleDownImages to start the migration"
+						}
+					} else {
+						pending = append(pending, image)
+					}
+					status.Images = append(status.Images, imageStatus)
+					pendingStatus[image.String()] = len(status.Images) - 1
+					continue
+				}
+
+				status.TotalImages++
+				imageStatus := cephv1.PoolMigrationImageStatus{Name: image.String(), State: imageMigration.State, Description: imageMigration.StateDescription}
+				switch imageMigration.State {
+				case cephclient.ImageMigrationExecuted:
+					if err := cephclient.CommitImageMigration(context, clusterInfo, pool, namespace, name); err != nil {
+						imageStatus.Message = err.Error()
+						failed = true
+						break
+					}
+					status.MigratedImages++
+					continue
+				case cephclient.ImageMigrationPrepared, cephclient.ImageMigrationExecuting:
+					running, lastError := m.isRunning(image)
+					if !running {
+						// the operator restarted or the last execution failed
+						imageStatus.Message = lastError
+						m.execute(context, clusterInfo, image)
+					}
+				default:
+					imageStatus.Message = "the migration of the image must be resolved with 'rbd migration'"
+					failed = true
+				}
+				status.Images = append(status.Images, imageStatus)
+			}
+		}
+	}
+
+	// prepare the migration of the next images, the clients of the images can start again right after.
+	// The images whose workloads are already scaled down are prepared first.
+	sort.SliceStable(pending, func(i, j int) bool {
+		return len(scaled[pending[i].String()]) > 0 && len(scaled[pending[j].String()]) == 0
+	})
+	// the images whose workloads stay scaled down until their migration is prepared
+	keepScaledDown := map[string]bool{}
+	scalingDown := 0
+	for _, image := range pending {
+		imageStatus := &status.Images[pendingStatus[image.String()]]
+		if migration.Paused {
+			imageStatus.Message = "the migration is paused"
+			continue
+		}
+		if len(scaled[image.String()]) > 0 {
+			keepScaledDown[image.String()] = true
+		}
+		if m.runningCount()+scalingDown >= migration.GetMaxConcurrentImages() {
+			imageStatus.Message = "waiting for the migration of other images"
+			continue
+		}
+		if inUse[image.String()] {
+			// the migration is prepared by a next reconcile once the clients of the image stopped. The
+			// workloads already scaled down are skipped, and their pods may be gone already.
+			if err := scaleDownImageWorkloads(context, clusterInfo, poolKey, image); err != nil && len(scaled[image.String()]) == 0 {
+				imageStatus.Message = err.Error()
+				continue
+			}
+			imageStatus.Message = "waiting for the clients of the image to stop"
+			keepScaledDown[image.String()] = true
+			scalingDown++
+			continue
+		}
+		if err := cephclient.PrepareImageMigration(context, clusterInfo, image.pool, image.namespace, image.name, migration.TargetPool); err != nil {
+			imageStatus.Message = err.Error()
+			failed = true
+			continue
+		}
+		delete(keepScaledDown, image.String())
+		imageStatus.State = cephclient.ImageMigrationPrepared
+		m.execute(context, clusterInfo, image)
+	}
+
+	// the workloads are scaled up again once the migration of their image is prepared, or when the
+	// migration of the image does not wait for them anymore
+	for image, workloads := range scaled {
+		if keepScaledDown[image] {
+			continue
+		}
+		if err := scaleUpImageWorkloads(context, clusterInfo, workloads); err != nil {
+			return nil, errors.Wrapf(err, "failed to scale up the workloads of image %q", image)
+		}
+	}
+
+	status.Phase = cephv1.ConditionProgressing
+	if failed {
+		status.Phase = cephv1.ConditionFailure
+	} else if status.MigratedImages == status.TotalImages {
+		status.Phase = cephv1.ConditionReady
+	}
+	status.LastChecked = time.Now().UTC().Format(time.RFC3339)
+	return status, nil
+}
+
+// replicatedPools returns the other replicated pools of the namespace, which may have their image data
+// in an erasure coded pool
+func (r *ReconcileCephBlockPool) replicatedPools(namespace, poolName string) ([]string, error) {
+	cephBlockPoolList := &cephv1.CephBlockPoolList{}
+	err := r.client.List(r.opManagerContext, cephBlockPoolList, client.InNamespace(namespace))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list ceph block pools")
+	}
+	pools := []string{}
+	for _, cephBlockPool := range cephBlockPoolList.Items {
+		if cephBlockPool.Name != poolName && cephBlockPool.Spec.IsReplicated() && cephBlockPool.GetDeletionTimestamp().IsZero() {
+			pools = append(pools, cephBlockPool.Name)
+		}
+	}
+	return pools, nil
+}
+
+// radosNamespaces returns the rados namespaces of a pool
+func (r *ReconcileCephBlockPool) radosNamespaces(pool string) ([]string, error) {
+	radosNamespaceList := &cephv1.CephBlockPoolRadosNamespaceList{}
+	err := r.client.List(r.opManagerContext, radosNamespaceList, client.InNamespace(r.clusterInfo.Namespace))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list ceph block pool rados namespaces")
+	}
+	namespaces := []string{}
+	for _, radosNamespace := range radosNamespaceList.Items {
+		if radosNamespace.Spec.BlockPoolName == pool {
+			namespaces = append(namespaces, radosNamespace.Name)
+		}
+	}
+	return namespaces, nil
+}
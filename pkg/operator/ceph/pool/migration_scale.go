@@ -0,0 +1,230 @@
+/*
+Copyright 2021 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pool
+
+import (
+	"strconv"
+
+	"github.com/pkg/errors"
+	"github.com/rook/rook/pkg/clusterd"
+	cephclient "github.com/rook/rook/pkg/daemon/ceph/client"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// the label of the deployments and statefulsets scaled down to prepare the migration of an image,
+	// and the annotations with the migrated pool, the image and the replicas to restore
+	migrationScaledDownLabel    = "ceph.rook.io/migration-scaled-down"
+	migrationPoolAnnotation     = "ceph.rook.io/migration-pool"
+	migrationImageAnnotation    = "ceph.rook.io/migration-image"
+	migrationReplicasAnnotation = "ceph.rook.io/migration-replicas"
+
+	deploymentKind  = "Deployment"
+	statefulSetKind = "StatefulSet"
+	replicaSetKind  = "ReplicaSet"
+)
+
+// imageWorkload is a deployment or a statefulset whose pods use an image
+type imageWorkload struct {
+	kind      string
+	namespace string
+	name      string
+}
+
+// scaledDownWorkloads returns the deployments and statefulsets scaled down to prepare the migration of
+// the images of a pool, by image
+func scaledDownWorkloads(context *clusterd.Context, clusterInfo *cephclient.ClusterInfo, poolKey string) (map[string][]imageWorkload, error) {
+	scaled := map[string][]imageWorkload{}
+	opts := metav1.ListOptions{LabelSelector: migrationScaledDownLabel}
+	deployments, err := context.Clientset.AppsV1().Deployments("").List(clusterInfo.Context, opts)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list the deployments scaled down by the migration")
+	}
+	for _, d := range deployments.Items {
+		if d.Annotations[migrationPoolAnnotation] != poolKey {
+			continue
+		}
+		image := d.Annotations[migrationImageAnnotation]
+		scaled[image] = append(scaled[image], imageWorkload{kind: deploymentKind, namespace: d.Namespace, name: d.Name})
+	}
+	statefulSets, err := context.Clientset.AppsV1().StatefulSets("").List(clusterInfo.Context, opts)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list the statefulsets scaled down by the migration")
+	}
+	for _, s := range statefulSets.Items {
+		if s.Annotations[migrationPoolAnnotation] != poolKey {
+			continue
+		}
+		image := s.Annotations[migrationImageAnnotation]
+		scaled[image] = append(scaled[image], imageWorkload{kind: statefulSetKind, namespace: s.Namespace, name: s.Name})
+	}
+	return scaled, nil
+}
+
+// imageWorkloads returns the deployments and statefulsets of the pods that use the PV of an image
+func imageWorkloads(context *clusterd.Context, clusterInfo *cephclient.ClusterInfo, image migrationImage) ([]imageWorkload, error) {
+	pvs, err := context.Clientset.CoreV1().PersistentVolumes().List(clusterInfo.Context, metav1.ListOptions{})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list the persistent volumes")
+	}
+	namespace, claim := "", ""
+	for _, pv := range pvs.Items {
+		csi := pv.Spec.CSI
+		if csi == nil || pv.Spec.ClaimRef == nil || csi.VolumeAttributes["pool"] != image.pool || csi.VolumeAttributes["imageName"] != image.name {
+			continue
+		}
+		namespace, claim = pv.Spec.ClaimRef.Namespace, pv.Spec.ClaimRef.Name
+		break
+	}
+	if claim == "" {
+		return nil, errors.Errorf("no persistent volume claim found for image %q", image)
+	}
+
+	pods, err := context.Clientset.CoreV1().Pods(namespace).List(clusterInfo.Context, metav1.ListOptions{})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to list the pods of namespace %q", namespace)
+	}
+	workloads := []imageWorkload{}
+	found := map[imageWorkload]bool{}
+	for _, pod := range pods.Items {
+		usesClaim := false
+		for _, volume := range pod.Spec.Volumes {
+			if volume.PersistentVolumeClaim != nil && volume.PersistentVolumeClaim.ClaimName == claim {
+				usesClaim = true
+			}
+		}
+		if !usesClaim {
+			continue
+		}
+
+		owner := metav1.GetControllerOf(&pod)
+		if owner == nil {
+			return nil, errors.Errorf("pod %q using image %q is not run by a deployment or a statefulset", pod.Name, image)
+		}
+		workload := imageWorkload{kind: owner.Kind, namespace: namespace, name: owner.Name}
+		if owner.Kind == replicaSetKind {
+			rs, err := context.Clientset.AppsV1().ReplicaSets(namespace).Get(clusterInfo.Context, owner.Name, metav1.GetOptions{})
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed to get replicaset %q", owner.Name)
+			}
+			owner = metav1.GetControllerOf(rs)
+			if owner == nil {
+				return nil, errors.Errorf("pod %q using image %q is not run by a deployment or a statefulset", pod.Name, image)
+			}
+			workload = imageWorkload{kind: owner.Kind, namespace: namespace, name: owner.Name}
+		}
+		if workload.kind != deploymentKind && workload.kind != statefulSetKind {
+			return nil, errors.Errorf("pod %q using image %q is not run by a deployment or a statefulset", pod.Name, image)
+		}
+		if !found[workload] {
+			found[workload] = true
+			workloads = append(workloads, workload)
+		}
+	}
+	if len(workloads) == 0 {
+		return nil, errors.Errorf("no pod found using image %q", image)
+	}
+	return workloads, nil
+}
+
+// scaleDownImageWorkloads scales down the deployments and statefulsets of the pods that use an image,
+// and records their replicas to scale them up again once the migration of the image is prepared
+func scaleDownImageWorkloads(context *clusterd.Context, clusterInfo *cephclient.ClusterInfo, poolKey string, image migrationImage) error {
+	workloads, err := imageWorkloads(context, clusterInfo, image)
+	if err != nil {
+		return err
+	}
+	for _, workload := range workloads {
+		err := updateWorkloadReplicas(context, clusterInfo, workload, func(meta *metav1.ObjectMeta, replicas **int32) {
+			if _, ok := meta.Labels[migrationScaledDownLabel]; ok {
+				return
+			}
+			previous := int32(1)
+			if *replicas != nil {
+				previous = **replicas
+			}
+			if meta.Labels == nil {
+				meta.Labels = map[string]string{}
+			}
+			if meta.Annotations == nil {
+				meta.Annotations = map[string]string{}
+			}
+			meta.Labels[migrationScaledDownLabel] = "true"
+			meta.Annotations[migrationPoolAnnotation] = poolKey
+			meta.Annotations[migrationImageAnnotation] = image.String()
+			meta.Annotations[migrationReplicasAnnotation] = strconv.Itoa(int(previous))
+			zero := int32(0)
+			*replicas = &zero
+		})
+		if err != nil {
+			return err
+		}
+		logger.Infof("scaled down %s %q to prepare the migration of image %q", workload.kind, workload.namespace+"/"+workload.name, image)
+	}
+	return nil
+}
+
+// scaleUpImageWorkloads restores the replicas of the deployments and statefulsets scaled down to
+// prepare the migration of an image
+func scaleUpImageWorkloads(context *clusterd.Context, clusterInfo *cephclient.ClusterInfo, workloads []imageWorkload) error {
+	for _, workload := range workloads {
+		err := updateWorkloadReplicas(context, clusterInfo, workload, func(meta *metav1.ObjectMeta, replicas **int32) {
+			previous, err := strconv.Atoi(meta.Annotations[migrationReplicasAnnotation])
+			if err != nil {
+				previous = 1
+			}
+			restored := int32(previous)
+			*replicas = &restored
+			delete(meta.Labels, migrationScaledDownLabel)
+			delete(meta.Annotations, migrationPoolAnnotation)
+			delete(meta.Annotations, migrationImageAnnotation)
+			delete(meta.Annotations, migrationReplicasAnnotation)
+		})
+		if err != nil {
+			return err
+		}
+		logger.Infof("scaled up %s %q", workload.kind, workload.namespace+"/"+workload.name)
+	}
+	return nil
+}
+
+// updateWorkloadReplicas updates the metadata and the replicas of a deployment or a statefulset
+func updateWorkloadReplicas(context *clusterd.Context, clusterInfo *cephclient.ClusterInfo, workload imageWorkload, update func(meta *metav1.ObjectMeta, replicas **int32)) error {
+	switch workload.kind {
+	case deploymentKind:
+		deployments := context.Clientset.AppsV1().Deployments(workload.namespace)
+		d, err := deployments.Get(clusterInfo.Context, workload.name, metav1.GetOptions{})
+		if err != nil {
+			return errors.Wrapf(err, "failed to get deployment %q", workload.name)
+		}
+		update(&d.ObjectMeta, &d.Spec.Replicas)
+		if _, err := deployments.Update(clusterInfo.Context, d, metav1.UpdateOptions{}); err != nil {
+			return errors.Wrapf(err, "failed to update deployment %q", workload.name)
+		}
+	case statefulSetKind:
+		statefulSets := context.Clientset.AppsV1().StatefulSets(workload.namespace)
+		s, err := statefulSets.Get(clusterInfo.Context, workload.name, metav1.GetOptions{})
+		if err != nil {
+			return errors.Wrapf(err, "failed to get statefulset %q", workload.name)
+		}
+		update(&s.ObjectMeta, &s.Spec.Replicas)
+		if _, err := statefulSets.Update(clusterInfo.Context, s, metav1.UpdateOptions{}); err != nil {
+			return errors.Wrapf(err, "failed to update statefulset %q", workload.name)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,243 @@
+/*
+Copyright 2021 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pool
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	"github.com/rook/rook/pkg/clusterd"
+	cephclient "github.com/rook/rook/pkg/daemon/ceph/client"
+	"github.com/rook/rook/pkg/operator/test"
+	exectest "github.com/rook/rook/pkg/util/exec/test"
+	"github.com/stretchr/testify/assert"
+	apps "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// mockMigrationImage is an image of the mock cluster
+type mockMigrationImage struct {
+	dataPool string
+	state    string
+	watched  bool
+}
+
+func TestMigrateImages(t *testing.T) {
+	var mutex sync.Mutex
+	images := map[string]*mockMigrationImage{
+		"replicapool/image-1":    {},
+		"replicapool/image-2":    {},
+		"replicapool/image-3":    {watched: true},
+		"replicapool/image-4":    {dataPool: "ecpool"},
+		"replicapool/image-5":    {dataPool: "otherpool"},
+		"replicapool/ns/image-6": {},
+	}
+	executeBlocked := make(chan struct{})
+	executor := &exectest.MockExecutor{
+		MockExecuteCommandWithOutput: func(command string, args ...string) (string, error) {
+			if command != "rbd" {
+				return "", errors.Errorf("unexpected command %q", command)
+			}
+			if args[0] == "migration" && args[1] == "execute" {
+				<-executeBlocked
+			}
+			mutex.Lock()
+			defer mutex.Unlock()
+			switch args[0] {
+			case "ls":
+				names := []string{}
+				for spec := range images {
+					name := strings.TrimPrefix(spec, args[1]+"/")
+					if name != spec && !strings.Contains(name, "/") {
+						names = append(names, name)
+					}
+				}
+				// rbd lists the images in order
+				sort.Strings(names)
+				out, _ := json.Marshal(names)
+				return string(out), nil
+			case "info":
+				return fmt.Sprintf(`{"name":"image","data_pool":%q}`, images[args[1]].dataPool), nil
+			case "status":
+				image := images[args[1]]
+				watchers := "[]"
+				if image.watched {
+					watchers = `[{"address":"10.0.0.5:0/1","client":1,"cookie":1}]`
+				}
+				if image.state == "" {
+					return fmt.Sprintf(`{"watchers":%s}`, watchers), nil
+				}
+				return fmt.Sprintf(`{"watchers":%s,"migration":{"state":%q,"state_description":""}}`, watchers, image.state), nil
+			case "migration":
+				image := images[args[2]]
+				switch args[1] {
+				case "prepare":
+					image.state = cephclient.ImageMigrationPrepared
+					image.dataPool = args[4]
+				case "execute":
+					image.state = cephclient.ImageMigrationExecuted
+				case "commit":
+					image.state = ""
+				}
+				return "", nil
+			}
+			return "", errors.Errorf("unexpected rbd command %q", args)
+		},
+	}
+	clientset := test.New(t, 1)
+	context := &clusterd.Context{Executor: executor, Clientset: clientset}
+	clusterInfo := cephclient.AdminClusterInfo("mycluster")
+
+	p := &cephv1.CephBlockPool{}
+	p.Name = "replicapool"
+	p.Namespace = "rook-ceph"
+	p.Spec.Replicated.Size = 3
+	p.Spec.Migration = &cephv1.PoolMigrationSpec{TargetPool: "ecpool", ErasureCoded: cephv1.ErasureCodedSpec{DataChunks: 4, CodingChunks: 2}}
+	radosNamespaces := func(pool string) ([]string, error) { return []string{"ns"}, nil }
+	m := newPoolMigration()
+
+	t.Run("prepare the first image", func(t *testing.T) {
+		status, err := migrateImages(context, clusterInfo, m, p, radosNamespaces, []string{"replicapool"})
+		assert.NoError(t, err)
+		assert.Equal(t, cephv1.ConditionProgressing, status.Phase)
+		// the image with another data pool is not migrated
+		assert.Equal(t, 5, status.TotalImages)
+		assert.Equal(t, 1, status.MigratedImages)
+		assert.Equal(t, 1, status.BlockedImages)
+		assert.Equal(t, 1, m.runningCount())
+		messages := map[string]string{}
+		for _, image := range status.Images {
+			messages[image.Name] = image.State + ": " + image.Message
+		}
+		assert.Equal(t, map[string]string{
+			"replicapool/image-1":    "prepared: ",
+			"replicapool/image-2":    "pending: waiting for the migration of other images",
+			"replicapool/image-3":    "pending: the image is in use, its clients must be stopped or the image added to scaleDownImages to start the migration",
+			"replicapool/ns/image-6": "pending: waiting for the migration of other images",
+		}, messages)
+	})
+
+	t.Run("commit the executed images", func(t *testing.T) {
+		close(executeBlocked)
+		assert.Eventually(t, func() bool { return m.runningCount() == 0 }, 5*time.Second, 10*time.Millisecond)
+
+		p.Spec.Migration.MaxConcurrentImages = 5
+		status, err := migrateImages(context, clusterInfo, m, p, radosNamespaces, []string{"replicapool"})
+		assert.NoError(t, err)
+		assert.Equal(t, 2, status.MigratedImages)
+		assert.Eventually(t, func() bool { return m.runningCount() == 0 }, 5*time.Second, 10*time.Millisecond)
+
+		mutex.Lock()
+		images["replicapool/image-3"].watched = false
+		mutex.Unlock()
+		status, err = migrateImages(context, clusterInfo, m, p, radosNamespaces, []string{"replicapool"})
+		assert.NoError(t, err)
+		assert.Equal(t, 4, status.MigratedImages)
+		assert.Eventually(t, func() bool { return m.runningCount() == 0 }, 5*time.Second, 10*time.Millisecond)
+
+		status, err = migrateImages(context, clusterInfo, m, p, radosNamespaces, []string{"replicapool"})
+		assert.NoError(t, err)
+		assert.Equal(t, cephv1.ConditionReady, status.Phase)
+		assert.Equal(t, 5, status.MigratedImages)
+		assert.Empty(t, status.Images)
+	})
+
+	t.Run("paused", func(t *testing.T) {
+		mutex.Lock()
+		images["replicapool/image-7"] = &mockMigrationImage{}
+		mutex.Unlock()
+		p.Spec.Migration.Paused = true
+		status, err := migrateImages(context, clusterInfo, m, p, radosNamespaces, []string{"replicapool"})
+		assert.NoError(t, err)
+		assert.Equal(t, cephv1.ConditionProgressing, status.Phase)
+		assert.Equal(t, "the migration is paused", status.Images[0].Message)
+	})
+
+	t.Run("scale down the workloads of the images in use", func(t *testing.T) {
+		ctx := clusterInfo.Context
+		mutex.Lock()
+		images["replicapool/image-8"] = &mockMigrationImage{watched: true}
+		mutex.Unlock()
+		pv := &v1.PersistentVolume{
+			ObjectMeta: metav1.ObjectMeta{Name: "pvc-1"},
+			Spec: v1.PersistentVolumeSpec{
+				PersistentVolumeSource: v1.PersistentVolumeSource{CSI: &v1.CSIPersistentVolumeSource{
+					VolumeAttributes: map[string]string{"pool": "replicapool", "imageName": "image-8"},
+				}},
+				ClaimRef: &v1.ObjectReference{Namespace: "apps", Name: "data"},
+			},
+		}
+		_, err := clientset.CoreV1().PersistentVolumes().Create(ctx, pv, metav1.CreateOptions{})
+		assert.NoError(t, err)
+		replicas := int32(3)
+		d := &apps.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "apps"}, Spec: apps.DeploymentSpec{Replicas: &replicas}}
+		_, err = clientset.AppsV1().Deployments("apps").Create(ctx, d, metav1.CreateOptions{})
+		assert.NoError(t, err)
+		controller := true
+		rs := &apps.ReplicaSet{ObjectMeta: metav1.ObjectMeta{Name: "web-1", Namespace: "apps",
+			OwnerReferences: []metav1.OwnerReference{{Kind: "Deployment", Name: "web", Controller: &controller}}}}
+		_, err = clientset.AppsV1().ReplicaSets("apps").Create(ctx, rs, metav1.CreateOptions{})
+		assert.NoError(t, err)
+		pod := &v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "web-1-a", Namespace: "apps",
+				OwnerReferences: []metav1.OwnerReference{{Kind: "ReplicaSet", Name: "web-1", Controller: &controller}}},
+			Spec: v1.PodSpec{Volumes: []v1.Volume{{Name: "data", VolumeSource: v1.VolumeSource{
+				PersistentVolumeClaim: &v1.PersistentVolumeClaimVolumeSource{ClaimName: "data"}}}}},
+		}
+		_, err = clientset.CoreV1().Pods("apps").Create(ctx, pod, metav1.CreateOptions{})
+		assert.NoError(t, err)
+
+		// the deployment of the image that opted in is scaled down
+		p.Spec.Migration.Paused = false
+		p.Spec.Migration.ScaleDownImages = []string{"replicapool/image-8"}
+		status, err := migrateImages(context, clusterInfo, m, p, radosNamespaces, []string{"replicapool"})
+		assert.NoError(t, err)
+		assert.Equal(t, 1, status.BlockedImages)
+		messages := map[string]string{}
+		for _, image := range status.Images {
+			messages[image.Name] = image.State + ": " + image.Message
+		}
+		assert.Equal(t, "pending: waiting for the clients of the image to stop", messages["replicapool/image-8"])
+		d, err = clientset.AppsV1().Deployments("apps").Get(ctx, "web", metav1.GetOptions{})
+		assert.NoError(t, err)
+		assert.Equal(t, int32(0), *d.Spec.Replicas)
+		assert.Equal(t, "3", d.Annotations[migrationReplicasAnnotation])
+		assert.Equal(t, "rook-ceph/replicapool", d.Annotations[migrationPoolAnnotation])
+
+		// the deployment is scaled up again once the migration of the image is prepared
+		mutex.Lock()
+		images["replicapool/image-8"].watched = false
+		mutex.Unlock()
+		assert.Eventually(t, func() bool { return m.runningCount() == 0 }, 5*time.Second, 10*time.Millisecond)
+		status, err = migrateImages(context, clusterInfo, m, p, radosNamespaces, []string{"replicapool"})
+		assert.NoError(t, err)
+		assert.Equal(t, 0, status.BlockedImages)
+		d, err = clientset.AppsV1().Deployments("apps").Get(ctx, "web", metav1.GetOptions{})
+		assert.NoError(t, err)
+		assert.Equal(t, int32(3), *d.Spec.Replicas)
+		assert.NotContains(t, d.Labels, migrationScaledDownLabel)
+		assert.NotContains(t, d.Annotations, migrationReplicasAnnotation)
+	})
+}
@@ -0,0 +1,98 @@
+/*
+Copyright 2021 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pool
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	"github.com/rook/rook/pkg/clusterd"
+	cephclient "github.com/rook/rook/pkg/daemon/ceph/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// the number of placement groups is checked until it reaches the target of the spec
+var waitForPlacementGroups = reconcile.Result{Requeue: true, RequeueAfter: 30 * time.Second}
+
+// reconcilePlacementGroups changes the number of placement groups of a pool toward the target of the
+// spec. A change of pg_num moves data, so the number is at most doubled or halved at a time, and the
+// next step waits until Ceph has applied the previous one. It returns the current and target numbers
+// of placement groups for the status, and whether the target is reached.
+func reconcilePlacementGroups(context *clusterd.Context, clusterInfo *cephclient.ClusterInfo, poolName string, pgs *cephv1.PlacementGroupSpec) (map[string]string, bool, error) {
+	pinned := pgs != nil && pgs.PgNum > 0
+	current, err := cephclient.GetPoolPlacementGroups(context, clusterInfo, poolName)
+	if err != nil {
+		if pinned {
+			return nil, false, errors.Wrapf(err, "failed to get the placement groups of pool %q", poolName)
+		}
+		// the placement groups are only reported in the status
+		logger.Debugf("failed to get the placement groups of pool %q. %v", poolName, err)
+		return nil, true, nil
+	}
+
+	// without a number of placement groups in the spec, the targets are the ones of the autoscaler
+	targetPgNum, targetPgpNum := current.PgNumTarget, current.PgpNumTarget
+	if pinned {
+		targetPgNum, targetPgpNum = pgs.PgNum, pgs.GetPgpNum()
+	}
+	info := map[string]string{
+		"currentPgNum":  strconv.Itoa(current.PgNum),
+		"targetPgNum":   strconv.Itoa(targetPgNum),
+		"currentPgpNum": strconv.Itoa(current.PgpNum),
+		"targetPgpNum":  strconv.Itoa(targetPgpNum),
+	}
+	if !pinned {
+		return info, true, nil
+	}
+
+	if current.PgNum != current.PgNumTarget || current.PgpNum != current.PgpNumTarget {
+		logger.Infof("waiting for the placement groups of pool %q to change from %d to %d (pgp from %d to %d)",
+			poolName, current.PgNum, current.PgNumTarget, current.PgpNum, current.PgpNumTarget)
+		return info, false, nil
+	}
+	if current.PgNum == targetPgNum && current.PgpNum == targetPgpNum {
+		return info, true, nil
+	}
+
+	pgNum := nextPlacementGroupStep(current.PgNum, targetPgNum)
+	pgpNum := nextPlacementGroupStep(current.PgpNum, targetPgpNum)
+	if pgpNum > pgNum {
+		pgpNum = pgNum
+	}
+	logger.Infof("changing the placement groups of pool %q to %d (pgp %d) toward %d (pgp %d)", poolName, pgNum, pgpNum, targetPgNum, targetPgpNum)
+	if err := cephclient.SetPoolPlacementGroups(context, clusterInfo, poolName, pgNum, pgpNum); err != nil {
+		return info, false, errors.Wrapf(err, "failed to set the placement groups of pool %q", poolName)
+	}
+	return info, false, nil
+}
+
+// nextPlacementGroupStep returns the next number of placement groups toward the target, which is at
+// most the double or the half of the current number
+func nextPlacementGroupStep(current, target int) int {
+	if current <= 0 {
+		return target
+	}
+	if target > current && target > current*2 {
+		return current * 2
+	}
+	if target < current && target < current/2 {
+		return current / 2
+	}
+	return target
+}
@@ -0,0 +1,95 @@
+/*
+Copyright 2021 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pool
+
+import (
+	"fmt"
+	"testing"
+
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	"github.com/rook/rook/pkg/clusterd"
+	cephclient "github.com/rook/rook/pkg/daemon/ceph/client"
+	exectest "github.com/rook/rook/pkg/util/exec/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNextPlacementGroupStep(t *testing.T) {
+	assert.Equal(t, 64, nextPlacementGroupStep(32, 256))
+	assert.Equal(t, 48, nextPlacementGroupStep(32, 48))
+	assert.Equal(t, 128, nextPlacementGroupStep(256, 32))
+	assert.Equal(t, 100, nextPlacementGroupStep(128, 100))
+	assert.Equal(t, 32, nextPlacementGroupStep(32, 32))
+	assert.Equal(t, 16, nextPlacementGroupStep(0, 16))
+}
+
+func TestReconcilePlacementGroups(t *testing.T) {
+	pgNum, pgpNum, pgNumTarget, pgpNumTarget := 32, 32, 32, 32
+	properties := map[string]string{}
+	executor := &exectest.MockExecutor{
+		MockExecuteCommandWithOutput: func(command string, args ...string) (string, error) {
+			if args[0] == "osd" && args[1] == "pool" && args[2] == "ls" {
+				return fmt.Sprintf(`[{"pool_name":"mypool","pg_num":%d,"pg_placement_num":%d,"pg_num_target":%d,"pg_placement_num_target":%d}]`,
+					pgNum, pgpNum, pgNumTarget, pgpNumTarget), nil
+			}
+			if args[0] == "osd" && args[1] == "pool" && args[2] == "set" {
+				properties[args[4]] = args[5]
+				return "", nil
+			}
+			return "", nil
+		},
+	}
+	context := &clusterd.Context{Executor: executor}
+	clusterInfo := cephclient.AdminClusterInfo("mycluster")
+
+	// the targets of the autoscaler are reported
+	pgNumTarget = 64
+	info, done, err := reconcilePlacementGroups(context, clusterInfo, "mypool", nil)
+	assert.NoError(t, err)
+	assert.True(t, done)
+	assert.Equal(t, map[string]string{"currentPgNum": "32", "targetPgNum": "64", "currentPgpNum": "32", "targetPgpNum": "32"}, info)
+	assert.Empty(t, properties)
+
+	// the change of the previous step is not applied yet
+	pgs := &cephv1.PlacementGroupSpec{PgNum: 256, AutoscaleMode: "off"}
+	info, done, err = reconcilePlacementGroups(context, clusterInfo, "mypool", pgs)
+	assert.NoError(t, err)
+	assert.False(t, done)
+	assert.Equal(t, "256", info["targetPgNum"])
+	assert.Empty(t, properties)
+
+	// the number of placement groups is doubled
+	pgNumTarget = 32
+	_, done, err = reconcilePlacementGroups(context, clusterInfo, "mypool", pgs)
+	assert.NoError(t, err)
+	assert.False(t, done)
+	assert.Equal(t, map[string]string{"pg_num": "64", "pgp_num": "64"}, properties)
+
+	// the target is reached
+	pgNum, pgpNum, pgNumTarget, pgpNumTarget = 256, 256, 256, 256
+	properties = map[string]string{}
+	_, done, err = reconcilePlacementGroups(context, clusterInfo, "mypool", pgs)
+	assert.NoError(t, err)
+	assert.True(t, done)
+	assert.Empty(t, properties)
+
+	// the placement groups are merged
+	pgs.PgNum, pgs.PgpNum = 100, 64
+	_, done, err = reconcilePlacementGroups(context, clusterInfo, "mypool", pgs)
+	assert.NoError(t, err)
+	assert.False(t, done)
+	assert.Equal(t, map[string]string{"pg_num": "128", "pgp_num": "128"}, properties)
+}
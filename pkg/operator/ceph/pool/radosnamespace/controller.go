@@ -0,0 +1,413 @@
+/*
+Copyright 2021 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package radosnamespace to manage rbd rados namespaces in a block pool.
+package radosnamespace
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/coreos/pkg/capnslog"
+	"github.com/pkg/errors"
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	"github.com/rook/rook/pkg/clusterd"
+	cephclient "github.com/rook/rook/pkg/daemon/ceph/client"
+	"github.com/rook/rook/pkg/operator/ceph/cluster/mon"
+	opcontroller "github.com/rook/rook/pkg/operator/ceph/controller"
+	"github.com/rook/rook/pkg/operator/ceph/csi"
+	"github.com/rook/rook/pkg/operator/ceph/reporting"
+	"github.com/rook/rook/pkg/operator/k8sutil"
+	"github.com/rook/rook/pkg/util/dependents"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+const (
+	controllerName = "ceph-blockpool-rados-namespace-controller"
+	// the key of the status info holding the clusterID to set in the csi storage classes
+	clusterIDKey = "clusterID"
+)
+
+var logger = capnslog.NewPackageLogger("github.com/rook/rook", controllerName)
+
+var radosNamespaceKind = reflect.TypeOf(cephv1.CephBlockPoolRadosNamespace{}).Name()
+
+// Sets the type meta for the controller main object
+var controllerTypeMeta = metav1.TypeMeta{
+	Kind:       radosNamespaceKind,
+	APIVersion: fmt.Sprintf("%s/%s", cephv1.CustomResourceGroup, cephv1.Version),
+}
+
+// ReconcileCephBlockPoolRadosNamespace reconciles a CephBlockPoolRadosNamespace object
+type ReconcileCephBlockPoolRadosNamespace struct {
+	client           client.Client
+	scheme           *runtime.Scheme
+	context          *clusterd.Context
+	clusterInfo      *cephclient.ClusterInfo
+	recorder         *k8sutil.EventReporter
+	opManagerContext context.Context
+}
+
+// Add creates a new CephBlockPoolRadosNamespace Controller and adds it to the Manager. The Manager
+// will set fields on the Controller and Start it when the Manager is Started.
+func Add(mgr manager.Manager, context *clusterd.Context, opManagerContext context.Context, opConfig opcontroller.OperatorConfig) error {
+	return add(mgr, newReconciler(mgr, context, opManagerContext))
+}
+
+// newReconciler returns a new reconcile.Reconciler
+func newReconciler(mgr manager.Manager, context *clusterd.Context, opManagerContext context.Context) reconcile.Reconciler {
+	return &ReconcileCephBlockPoolRadosNamespace{
+		client:           mgr.GetClient(),
+		scheme:           mgr.GetScheme(),
+		context:          context,
+		recorder:         k8sutil.NewEventReporter(mgr.GetEventRecorderFor("rook-" + controllerName)),
+		opManagerContext: opManagerContext,
+	}
+}
+
+func add(mgr manager.Manager, r reconcile.Reconciler) error {
+	// Create a new controller
+	c, err := controller.New(controllerName, mgr, controller.Options{Reconciler: r})
+	if err != nil {
+		return err
+	}
+	logger.Info("successfully started")
+
+	// Watch for changes on the CephBlockPoolRadosNamespace CRD object
+	err = c.Watch(&source.Kind{Type: &cephv1.CephBlockPoolRadosNamespace{TypeMeta: controllerTypeMeta}}, &handler.EnqueueRequestForObject{}, opcontroller.WatchControllerPredicate())
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Reconcile reads that state of the cluster for a CephBlockPoolRadosNamespace object and makes
+// changes based on the state read and what is in the CephBlockPoolRadosNamespace.Spec
+// The Controller will requeue the Request to be processed again if the returned error is non-nil or
+// Result.Requeue is true, otherwise upon completion it will remove the work from the queue.
+func (r *ReconcileCephBlockPoolRadosNamespace) Reconcile(context context.Context, request reconcile.Request) (reconcile.Result, error) {
+	// workaround because the rook logging mechanism is not compatible with the controller-runtime logging interface
+	reconcileResponse, err := r.reconcile(request)
+	reconcileResponse, err = opcontroller.RequeueIfCephCommandsUnavailable(reconcileResponse, err)
+	if err != nil {
+		logger.Errorf("failed to reconcile %v", err)
+	}
+
+	return reconcileResponse, err
+}
+
+func (r *ReconcileCephBlockPoolRadosNamespace) reconcile(request reconcile.Request) (reconcile.Result, error) {
+	// Fetch the CephBlockPoolRadosNamespace instance
+	cephRadosNamespace := &cephv1.CephBlockPoolRadosNamespace{}
+	err := r.client.Get(r.opManagerContext, request.NamespacedName, cephRadosNamespace)
+	if err != nil {
+		if kerrors.IsNotFound(err) {
+			logger.Debug("cephBlockPoolRadosNamespace resource not found. Ignoring since object must be deleted.")
+			return reconcile.Result{}, nil
+		}
+		// Error reading the object - requeue the request.
+		return reconcile.Result{}, errors.Wrap(err, "failed to get cephBlockPoolRadosNamespace")
+	}
+
+	// Set a finalizer so we can do cleanup before the object goes away
+	err = opcontroller.AddFinalizerIfNotPresent(r.client, cephRadosNamespace)
+	if err != nil {
+		return reconcile.Result{}, errors.Wrap(err, "failed to add finalizer")
+	}
+
+	// The CR was just created, initializing status fields
+	if cephRadosNamespace.Status == nil {
+		r.updateStatus(request.NamespacedName, cephv1.ConditionProgressing, nil)
+	}
+
+	// Make sure a CephCluster is present otherwise do nothing
+	cephCluster, isReadyToReconcile, cephClusterExists, reconcileResponse := opcontroller.IsReadyToReconcile(r.client, r.context, request.NamespacedName, controllerName)
+	if !isReadyToReconcile {
+		// This handles the case where the Ceph Cluster is gone and we want to delete that CR
+		// We skip the deleteRadosNamespace() function since everything is gone already
+		//
+		// Also, only remove the finalizer if the CephCluster is gone
+		// If not, we should wait for it to be ready
+		// This handles the case where the operator is not ready to accept Ceph command but the cluster exists
+		if !cephRadosNamespace.GetDeletionTimestamp().IsZero() && !cephClusterExists {
+			// Remove finalizer
+			err = opcontroller.RemoveFinalizer(r.client, cephRadosNamespace)
+			if err != nil {
+				return opcontroller.ImmediateRetryResult, errors.Wrap(err, "failed to remove finalizer")
+			}
+
+			// Return and do not requeue. Successful deletion.
+			return reconcile.Result{}, nil
+		}
+		return reconcileResponse, nil
+	}
+
+	// Populate clusterInfo during each reconcile
+	r.clusterInfo, _, _, err = mon.LoadClusterInfo(r.context, r.opManagerContext, request.NamespacedName.Namespace)
+	if err != nil {
+		return reconcile.Result{}, errors.Wrap(err, "failed to populate cluster info")
+	}
+	r.clusterInfo.Context = r.opManagerContext
+	r.clusterInfo.NetworkSpec = cephCluster.Spec.Network
+
+	// The block pool must exist before its rados namespaces are created
+	blockPool := &cephv1.CephBlockPool{}
+	blockPoolName := types.NamespacedName{Name: cephRadosNamespace.Spec.BlockPoolName, Namespace: request.Namespace}
+	err = r.client.Get(r.opManagerContext, blockPoolName, blockPool)
+	if err != nil {
+		if kerrors.IsNotFound(err) {
+			// the rados namespace was deleted along with its pool, there is nothing left to clean up
+			if !cephRadosNamespace.GetDeletionTimestamp().IsZero() {
+				if err := r.deleteCsiConfig(cephRadosNamespace); err != nil {
+					return reconcile.Result{}, err
+				}
+				err = opcontroller.RemoveFinalizer(r.client, cephRadosNamespace)
+				if err != nil {
+					return reconcile.Result{}, errors.Wrap(err, "failed to remove finalizer")
+				}
+				return reconcile.Result{}, nil
+			}
+			logger.Infof("waiting for the CephBlockPool %q of the rados namespace %q to be created", blockPoolName.String(), request.NamespacedName.String())
+			r.updateStatus(request.NamespacedName, cephv1.ConditionFailure, nil)
+			return opcontroller.WaitForRequeueIfCephClusterNotReady, nil
+		}
+		return reconcile.Result{}, errors.Wrapf(err, "failed to get CephBlockPool %q", blockPoolName.String())
+	}
+
+	// DELETE: the CR was deleted
+	if !cephRadosNamespace.GetDeletionTimestamp().IsZero() {
+		deps, err := cephRadosNamespaceDependents(r.context, r.clusterInfo, cephRadosNamespace)
+		if err != nil {
+			return reconcile.Result{}, err
+		}
+		if !deps.Empty() {
+			err := reporting.ReportDeletionBlockedDueToDependents(logger, r.client, cephRadosNamespace, deps)
+			return opcontroller.WaitForRequeueIfFinalizerBlocked, err
+		}
+		reporting.ReportDeletionNotBlockedDueToDependents(logger, r.client, r.recorder, cephRadosNamespace)
+
+		err = r.deleteRadosNamespace(cephRadosNamespace)
+		if err != nil {
+			return reconcile.Result{}, errors.Wrapf(err, "failed to delete ceph blockpool rados namespace %q", cephRadosNamespace.Name)
+		}
+
+		// Remove finalizer
+		err = opcontroller.RemoveFinalizer(r.client, cephRadosNamespace)
+		if err != nil {
+			return reconcile.Result{}, errors.Wrap(err, "failed to remove finalizer")
+		}
+
+		// Return and do not requeue. Successful deletion.
+		return reconcile.Result{}, nil
+	}
+
+	// Create or Update the rados namespace
+	err = r.createOrUpdateRadosNamespace(cephRadosNamespace)
+	if err != nil {
+		if strings.Contains(err.Error(), opcontroller.UninitializedCephConfigError) {
+			logger.Info(opcontroller.OperatorNotInitializedMessage)
+			return opcontroller.WaitForRequeueIfOperatorNotInitialized, nil
+		}
+		r.updateStatus(request.NamespacedName, cephv1.ConditionFailure, nil)
+		return reconcile.Result{}, errors.Wrapf(err, "failed to create or update ceph blockpool rados namespace %q", cephRadosNamespace.Name)
+	}
+
+	// Configure the csi driver with the clusterID of the rados namespace
+	clusterID := buildClusterID(cephRadosNamespace)
+	err = csi.SaveRadosNamespaceConfig(r.context.Clientset, r.clusterInfo, clusterID, cephRadosNamespace.Name)
+	if err != nil {
+		r.updateStatus(request.NamespacedName, cephv1.ConditionFailure, nil)
+		return reconcile.Result{}, errors.Wrapf(err, "failed to save the csi config of ceph blockpool rados namespace %q", cephRadosNamespace.Name)
+	}
+
+	// Create the client restricted to the rados namespace, or delete it if it is no longer wanted
+	if cephRadosNamespace.Spec.CreateClient {
+		err = r.createOrUpdateClient(cephRadosNamespace)
+		if err != nil {
+			r.updateStatus(request.NamespacedName, cephv1.ConditionFailure, nil)
+			return reconcile.Result{}, errors.Wrapf(err, "failed to create the client of ceph blockpool rados namespace %q", cephRadosNamespace.Name)
+		}
+	} else {
+		err = r.deleteClient(cephRadosNamespace)
+		if err != nil {
+			r.updateStatus(request.NamespacedName, cephv1.ConditionFailure, nil)
+			return reconcile.Result{}, errors.Wrapf(err, "failed to delete the client of ceph blockpool rados namespace %q", cephRadosNamespace.Name)
+		}
+	}
+
+	// Success! Let's update the status
+	r.updateStatus(request.NamespacedName, cephv1.ConditionReady, map[string]string{clusterIDKey: clusterID})
+
+	// Return and do not requeue
+	logger.Debug("done reconciling")
+	return reconcile.Result{}, nil
+}
+
+// Create the rados namespace
+func (r *ReconcileCephBlockPoolRadosNamespace) createOrUpdateRadosNamespace(cephRadosNamespace *cephv1.CephBlockPoolRadosNamespace) error {
+	logger.Infof("creating ceph blockpool rados namespace %q in namespace %q", cephRadosNamespace.Name, cephRadosNamespace.Namespace)
+
+	err := cephclient.CreateRadosNamespace(r.context, r.clusterInfo, cephRadosNamespace.Spec.BlockPoolName, cephRadosNamespace.Name)
+	if err != nil {
+		return errors.Wrapf(err, "failed to create ceph blockpool rados namespace %q", cephRadosNamespace.Name)
+	}
+
+	return nil
+}
+
+// Create or update the CephClient whose caps only allow access to the rados namespace
+func (r *ReconcileCephBlockPoolRadosNamespace) createOrUpdateClient(cephRadosNamespace *cephv1.CephBlockPoolRadosNamespace) error {
+	cephClient := &cephv1.CephClient{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      cephRadosNamespace.Name,
+			Namespace: cephRadosNamespace.Namespace,
+		},
+	}
+	op, err := controllerutil.CreateOrUpdate(r.opManagerContext, r.client, cephClient, func() error {
+		cephClient.Spec.Caps = generateClientCaps(cephRadosNamespace)
+		return controllerutil.SetControllerReference(cephRadosNamespace, cephClient, r.scheme)
+	})
+	if err != nil {
+		return errors.Wrapf(err, "failed to create or update ceph client %q", cephClient.Name)
+	}
+	logger.Debugf("ceph client %q for rados namespace %q %s", cephClient.Name, cephRadosNamespace.Name, op)
+
+	return nil
+}
+
+// Delete the CephClient created for the rados namespace and its Secret. A CephClient with the same name
+// that was not created for the rados namespace is left untouched.
+func (r *ReconcileCephBlockPoolRadosNamespace) deleteClient(cephRadosNamespace *cephv1.CephBlockPoolRadosNamespace) error {
+	cephClient := &cephv1.CephClient{}
+	name := types.NamespacedName{Name: cephRadosNamespace.Name, Namespace: cephRadosNamespace.Namespace}
+	err := r.client.Get(r.opManagerContext, name, cephClient)
+	if err != nil {
+		if kerrors.IsNotFound(err) {
+			return nil
+		}
+		return errors.Wrapf(err, "failed to get ceph client %q", name.String())
+	}
+	if !metav1.IsControlledBy(cephClient, cephRadosNamespace) {
+		return nil
+	}
+
+	logger.Infof("deleting ceph client %q of rados namespace %q", cephClient.Name, cephRadosNamespace.Name)
+	err = r.client.Delete(r.opManagerContext, cephClient)
+	if err != nil && !kerrors.IsNotFound(err) {
+		return errors.Wrapf(err, "failed to delete ceph client %q", name.String())
+	}
+
+	// The Secret is deleted with its owner CephClient, delete it right away so the key is not left behind
+	// if the garbage collection is delayed
+	if cephClient.Status != nil && cephClient.Status.Info["secretName"] != "" {
+		secretName := cephClient.Status.Info["secretName"]
+		err = r.context.Clientset.CoreV1().Secrets(cephClient.Namespace).Delete(r.opManagerContext, secretName, metav1.DeleteOptions{})
+		if err != nil && !kerrors.IsNotFound(err) {
+			return errors.Wrapf(err, "failed to delete the secret %q of ceph client %q", secretName, name.String())
+		}
+	}
+
+	return nil
+}
+
+// Delete the rados namespace
+func (r *ReconcileCephBlockPoolRadosNamespace) deleteRadosNamespace(cephRadosNamespace *cephv1.CephBlockPoolRadosNamespace) error {
+	logger.Infof("deleting ceph blockpool rados namespace object %q", cephRadosNamespace.Name)
+	if err := r.deleteCsiConfig(cephRadosNamespace); err != nil {
+		return err
+	}
+	if err := cephclient.DeleteRadosNamespace(r.context, r.clusterInfo, cephRadosNamespace.Spec.BlockPoolName, cephRadosNamespace.Name); err != nil {
+		return errors.Wrapf(err, "failed to delete ceph blockpool rados namespace %q", cephRadosNamespace.Name)
+	}
+
+	logger.Infof("deleted ceph blockpool rados namespace %q", cephRadosNamespace.Name)
+	return nil
+}
+
+func (r *ReconcileCephBlockPoolRadosNamespace) deleteCsiConfig(cephRadosNamespace *cephv1.CephBlockPoolRadosNamespace) error {
+	err := csi.DeleteClusterConfigEntry(r.context.Clientset, r.opManagerContext, buildClusterID(cephRadosNamespace))
+	if err != nil {
+		return errors.Wrapf(err, "failed to remove the csi config of ceph blockpool rados namespace %q", cephRadosNamespace.Name)
+	}
+	return nil
+}
+
+// cephRadosNamespaceDependents returns the rbd images that prevent the rados namespace from being deleted
+func cephRadosNamespaceDependents(context *clusterd.Context, clusterInfo *cephclient.ClusterInfo, cephRadosNamespace *cephv1.CephBlockPoolRadosNamespace) (*dependents.DependentList, error) {
+	deps := dependents.NewDependentList()
+
+	images, err := cephclient.ListImagesInRadosNamespace(context, clusterInfo, cephRadosNamespace.Spec.BlockPoolName, cephRadosNamespace.Name)
+	if err != nil {
+		return deps, errors.Wrapf(err, "failed to list the images of ceph blockpool rados namespace %q", cephRadosNamespace.Name)
+	}
+	for _, image := range images {
+		deps.Add("rbd images", image.Name)
+	}
+
+	return deps, nil
+}
+
+// buildClusterID returns the clusterID to set in the csi storage classes of the rados namespace
+func buildClusterID(cephRadosNamespace *cephv1.CephBlockPoolRadosNamespace) string {
+	return k8sutil.Hash(fmt.Sprintf("%s-%s-block-%s", cephRadosNamespace.Namespace, cephRadosNamespace.Spec.BlockPoolName, cephRadosNamespace.Name))
+}
+
+// generateClientCaps returns the caps of a client restricted to the rbd images of the rados namespace
+func generateClientCaps(cephRadosNamespace *cephv1.CephBlockPoolRadosNamespace) map[string]string {
+	return map[string]string{
+		"mon": "profile rbd",
+		"osd": fmt.Sprintf("profile rbd pool=%s namespace=%s", cephRadosNamespace.Spec.BlockPoolName, cephRadosNamespace.Name),
+	}
+}
+
+// updateStatus updates an object with a given status
+func (r *ReconcileCephBlockPoolRadosNamespace) updateStatus(name types.NamespacedName, status cephv1.ConditionType, info map[string]string) {
+	cephRadosNamespace := &cephv1.CephBlockPoolRadosNamespace{}
+	if err := r.client.Get(r.opManagerContext, name, cephRadosNamespace); err != nil {
+		if kerrors.IsNotFound(err) {
+			logger.Debug("CephBlockPoolRadosNamespace resource not found. Ignoring since object must be deleted.")
+			return
+		}
+		logger.Warningf("failed to retrieve ceph blockpool rados namespace %q to update status to %q. %v", name, status, err)
+		return
+	}
+	if cephRadosNamespace.Status == nil {
+		cephRadosNamespace.Status = &cephv1.CephBlockPoolRadosNamespaceStatus{}
+	}
+
+	cephRadosNamespace.Status.Phase = status
+	if info != nil {
+		cephRadosNamespace.Status.Info = info
+	}
+	if err := reporting.UpdateStatus(r.client, cephRadosNamespace); err != nil {
+		logger.Errorf("failed to set ceph blockpool rados namespace %q status to %q. %v", name, status, err)
+		return
+	}
+	logger.Debugf("ceph blockpool rados namespace %q status updated to %q", name, status)
+}
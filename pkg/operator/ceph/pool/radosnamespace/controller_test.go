@@ -0,0 +1,232 @@
+/*
+Copyright 2021 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package radosnamespace
+
+import (
+	"context"
+	"testing"
+
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	rookclient "github.com/rook/rook/pkg/client/clientset/versioned/fake"
+	"github.com/rook/rook/pkg/client/clientset/versioned/scheme"
+	"github.com/rook/rook/pkg/clusterd"
+	opcontroller "github.com/rook/rook/pkg/operator/ceph/controller"
+	"github.com/rook/rook/pkg/operator/k8sutil"
+	testop "github.com/rook/rook/pkg/operator/test"
+	exectest "github.com/rook/rook/pkg/util/exec/test"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func TestCephBlockPoolRadosNamespaceController(t *testing.T) {
+	ctx := context.TODO()
+	var (
+		name      = "tenant-a"
+		namespace = "rook-ceph"
+	)
+
+	cephRadosNamespace := &cephv1.CephBlockPoolRadosNamespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       name,
+			Namespace:  namespace,
+			UID:        types.UID("c47cac40-9bee-4d52-823b-ccd803ba5bfe"),
+			Finalizers: []string{"cephblockpoolradosnamespace.ceph.rook.io"},
+		},
+		Spec: cephv1.CephBlockPoolRadosNamespaceSpec{
+			BlockPoolName: "replicapool",
+			CreateClient:  true,
+		},
+		Status: &cephv1.CephBlockPoolRadosNamespaceStatus{Phase: ""},
+	}
+	cephCluster := &cephv1.CephCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      namespace,
+			Namespace: namespace,
+		},
+		Status: cephv1.ClusterStatus{
+			Phase: cephv1.ConditionReady,
+			CephVersion: &cephv1.ClusterVersion{
+				Version: "16.2.6-0",
+			},
+			CephStatus: &cephv1.CephStatus{
+				Health: "HEALTH_OK",
+			},
+		},
+	}
+	blockPool := &cephv1.CephBlockPool{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "replicapool",
+			Namespace: namespace,
+		},
+	}
+
+	rbdCommands := [][]string{}
+	images := `[]`
+	executor := &exectest.MockExecutor{
+		MockExecuteCommandWithOutput: func(command string, args ...string) (string, error) {
+			if args[0] == "status" {
+				return `{"fsid":"c47cac40-9bee-4d52-823b-ccd803ba5bfe","health":{"checks":{},"status":"HEALTH_OK"},"pgmap":{"num_pgs":100,"pgs_by_state":[{"state_name":"active+clean","count":100}]}}`, nil
+			}
+			if command == "rbd" {
+				rbdCommands = append(rbdCommands, args[:3])
+				if args[0] == "ls" {
+					return images, nil
+				}
+			}
+			return "", nil
+		},
+	}
+	c := &clusterd.Context{
+		Executor:      executor,
+		Clientset:     testop.New(t, 1),
+		RookClientset: rookclient.NewSimpleClientset(),
+	}
+
+	// Mock clusterInfo
+	secret := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "rook-ceph-mon",
+			Namespace: namespace,
+		},
+		Data: map[string][]byte{
+			"fsid":         []byte(name),
+			"mon-secret":   []byte("monsecret"),
+			"admin-secret": []byte("adminsecret"),
+		},
+		Type: k8sutil.RookType,
+	}
+	_, err := c.Clientset.CoreV1().Secrets(namespace).Create(ctx, secret, metav1.CreateOptions{})
+	assert.NoError(t, err)
+
+	s := scheme.Scheme
+	s.AddKnownTypes(cephv1.SchemeGroupVersion, &cephv1.CephBlockPoolRadosNamespace{}, &cephv1.CephBlockPoolRadosNamespaceList{},
+		&cephv1.CephCluster{}, &cephv1.CephClusterList{}, &cephv1.CephBlockPool{}, &cephv1.CephBlockPoolList{},
+		&cephv1.CephClient{}, &cephv1.CephClientList{})
+
+	newReconcile := func(objects ...runtime.Object) *ReconcileCephBlockPoolRadosNamespace {
+		cl := fake.NewClientBuilder().WithScheme(s).WithRuntimeObjects(objects...).Build()
+		c.Client = cl
+		return &ReconcileCephBlockPoolRadosNamespace{
+			client:           cl,
+			scheme:           s,
+			context:          c,
+			recorder:         k8sutil.NewEventReporter(record.NewFakeRecorder(5)),
+			opManagerContext: ctx,
+		}
+	}
+	req := reconcile.Request{NamespacedName: types.NamespacedName{Name: name, Namespace: namespace}}
+
+	t.Run("waiting for the block pool", func(t *testing.T) {
+		r := newReconcile(cephRadosNamespace, cephCluster)
+		res, err := r.Reconcile(ctx, req)
+		assert.NoError(t, err)
+		assert.True(t, res.Requeue)
+		assert.Empty(t, rbdCommands)
+	})
+
+	t.Run("success", func(t *testing.T) {
+		r := newReconcile(cephRadosNamespace, cephCluster, blockPool)
+		res, err := r.Reconcile(ctx, req)
+		assert.NoError(t, err)
+		assert.False(t, res.Requeue)
+		assert.Equal(t, [][]string{{"namespace", "create", "replicapool/tenant-a"}}, rbdCommands)
+
+		err = r.client.Get(ctx, req.NamespacedName, cephRadosNamespace)
+		assert.NoError(t, err)
+		assert.Equal(t, cephv1.ConditionReady, cephRadosNamespace.Status.Phase)
+		assert.Equal(t, buildClusterID(cephRadosNamespace), cephRadosNamespace.Status.Info["clusterID"])
+
+		cephClient := &cephv1.CephClient{}
+		err = r.client.Get(ctx, req.NamespacedName, cephClient)
+		assert.NoError(t, err)
+		assert.Equal(t, "profile rbd pool=replicapool namespace=tenant-a", cephClient.Spec.Caps["osd"])
+		assert.Equal(t, name, cephClient.OwnerReferences[0].Name)
+	})
+
+	t.Run("client no longer created", func(t *testing.T) {
+		cephClient := &cephv1.CephClient{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+			Status:     &cephv1.CephClientStatus{Info: map[string]string{"secretName": "rook-ceph-client-tenant-a"}},
+		}
+		assert.NoError(t, controllerutil.SetControllerReference(cephRadosNamespace, cephClient, s))
+		clientSecret := &v1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "rook-ceph-client-tenant-a", Namespace: namespace}}
+		_, err := c.Clientset.CoreV1().Secrets(namespace).Create(ctx, clientSecret, metav1.CreateOptions{})
+		assert.NoError(t, err)
+
+		cephRadosNamespace.Spec.CreateClient = false
+		r := newReconcile(cephRadosNamespace, cephCluster, blockPool, cephClient)
+		res, err := r.Reconcile(ctx, req)
+		assert.NoError(t, err)
+		assert.False(t, res.Requeue)
+
+		err = r.client.Get(ctx, req.NamespacedName, &cephv1.CephClient{})
+		assert.True(t, kerrors.IsNotFound(err))
+		_, err = c.Clientset.CoreV1().Secrets(namespace).Get(ctx, clientSecret.Name, metav1.GetOptions{})
+		assert.True(t, kerrors.IsNotFound(err))
+
+		// a client with the same name that was not created for the rados namespace is kept
+		other := &cephv1.CephClient{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace}}
+		r = newReconcile(cephRadosNamespace, cephCluster, blockPool, other)
+		_, err = r.Reconcile(ctx, req)
+		assert.NoError(t, err)
+		err = r.client.Get(ctx, req.NamespacedName, &cephv1.CephClient{})
+		assert.NoError(t, err)
+	})
+
+	t.Run("deletion blocked by images", func(t *testing.T) {
+		rbdCommands = [][]string{}
+		images = `[{"image":"csi-vol-1","size":1048576,"format":2}]`
+		now := metav1.Now()
+		cephRadosNamespace.DeletionTimestamp = &now
+		r := newReconcile(cephRadosNamespace, cephCluster, blockPool)
+		res, err := r.Reconcile(ctx, req)
+		assert.Error(t, err)
+		assert.Equal(t, opcontroller.WaitForRequeueIfFinalizerBlocked, res)
+		assert.Equal(t, [][]string{{"ls", "-l", "replicapool/tenant-a"}}, rbdCommands)
+	})
+
+	t.Run("deletion", func(t *testing.T) {
+		rbdCommands = [][]string{}
+		images = `[]`
+		r := newReconcile(cephRadosNamespace, cephCluster, blockPool)
+		res, err := r.Reconcile(ctx, req)
+		assert.NoError(t, err)
+		assert.False(t, res.Requeue)
+		assert.Equal(t, [][]string{{"ls", "-l", "replicapool/tenant-a"}, {"namespace", "remove", "replicapool/tenant-a"}}, rbdCommands)
+	})
+}
+
+func TestBuildClusterID(t *testing.T) {
+	cephRadosNamespace := &cephv1.CephBlockPoolRadosNamespace{
+		ObjectMeta: metav1.ObjectMeta{Name: "tenant-a", Namespace: "rook-ceph"},
+		Spec:       cephv1.CephBlockPoolRadosNamespaceSpec{BlockPoolName: "replicapool"},
+	}
+	clusterID := buildClusterID(cephRadosNamespace)
+	assert.Equal(t, k8sutil.Hash("rook-ceph-replicapool-block-tenant-a"), clusterID)
+
+	// the clusterID is unique per pool
+	cephRadosNamespace.Spec.BlockPoolName = "otherpool"
+	assert.NotEqual(t, clusterID, buildClusterID(cephRadosNamespace))
+}
@@ -21,6 +21,7 @@ import (
 	"context"
 	"time"
 
+	"github.com/pkg/errors"
 	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
 	"github.com/rook/rook/pkg/operator/ceph/reporting"
 	kerrors "k8s.io/apimachinery/pkg/api/errors"
@@ -54,6 +55,57 @@ func updateStatus(client client.Client, poolName types.NamespacedName, status ce
 	logger.Debugf("pool %q status updated to %q", poolName, status)
 }
 
+// updateMigrationStatus updates the migration status of a pool CR
+func updateMigrationStatus(client client.Client, poolName types.NamespacedName, migrationStatus *cephv1.PoolMigrationStatus) {
+	pool := &cephv1.CephBlockPool{}
+	err := client.Get(context.TODO(), poolName, pool)
+	if err != nil {
+		if kerrors.IsNotFound(err) {
+			logger.Debug("CephBlockPool resource not found. Ignoring since object must be deleted.")
+			return
+		}
+		logger.Warningf("failed to retrieve pool %q to update the migration status. %v", poolName, err)
+		return
+	}
+
+	if pool.Status == nil {
+		pool.Status = &cephv1.CephBlockPoolStatus{}
+	}
+	pool.Status.Migration = migrationStatus
+	if err := reporting.UpdateStatus(client, pool); err != nil {
+		logger.Warningf("failed to set pool %q migration status. %v", pool.Name, err)
+		return
+	}
+	logger.Debugf("pool %q migration status updated to %q", poolName, migrationStatus.Phase)
+}
+
+// updatePoolUsageStatus updates the usage of a pool CR
+func updatePoolUsageStatus(client client.Client, poolName types.NamespacedName, usage []cephv1.PoolUsageStatus) error {
+	pool := &cephv1.CephBlockPool{}
+	err := client.Get(context.TODO(), poolName, pool)
+	if err != nil {
+		if kerrors.IsNotFound(err) {
+			logger.Debug("CephBlockPool resource not found. Ignoring since object must be deleted.")
+			return nil
+		}
+		return errors.Wrapf(err, "failed to retrieve pool %q to update the usage status", poolName)
+	}
+
+	if pool.Status == nil {
+		pool.Status = &cephv1.CephBlockPoolStatus{}
+	}
+	// the pool is not reported until it is created
+	pool.Status.Usage = nil
+	if len(usage) > 0 {
+		pool.Status.Usage = &usage[0]
+	}
+	if err := reporting.UpdateStatus(client, pool); err != nil {
+		return errors.Wrapf(err, "failed to set pool %q usage status", pool.Name)
+	}
+	logger.Debugf("pool %q usage status updated", poolName)
+	return nil
+}
+
 // updateStatusBucket updates an object with a given status
 func (c *mirrorChecker) updateStatusMirroring(mirrorStatus *cephv1.PoolMirroringStatusSummarySpec, mirrorInfo *cephv1.PoolMirroringInfo, snapSchedStatus []cephv1.SnapshotSchedulesSpec, details string) {
 	blockPool := &cephv1.CephBlockPool{}
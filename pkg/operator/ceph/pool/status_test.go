@@ -18,10 +18,15 @@ limitations under the License.
 package pool
 
 import (
+	"context"
 	"testing"
 
 	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
 	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 )
 
 func TestToCustomResourceStatus(t *testing.T) {
@@ -58,3 +63,24 @@ func TestToCustomResourceStatus(t *testing.T) {
 		assert.NotEmpty(t, newSnapshotScheduleStatus)
 	}
 }
+
+func TestUpdatePoolUsageStatus(t *testing.T) {
+	pool := &cephv1.CephBlockPool{ObjectMeta: metav1.ObjectMeta{Name: "replicapool", Namespace: "rook-ceph"}}
+	s := runtime.NewScheme()
+	assert.NoError(t, cephv1.AddToScheme(s))
+	cl := fake.NewClientBuilder().WithScheme(s).WithRuntimeObjects(pool).Build()
+	poolName := types.NamespacedName{Name: pool.Name, Namespace: pool.Namespace}
+
+	// the pool is not created yet
+	assert.NoError(t, updatePoolUsageStatus(cl, poolName, []cephv1.PoolUsageStatus{}))
+	assert.NoError(t, cl.Get(context.TODO(), poolName, pool))
+	assert.Nil(t, pool.Status.Usage)
+
+	usage := []cephv1.PoolUsageStatus{{Name: "replicapool", StoredBytes: 100, PercentUsed: 10}}
+	assert.NoError(t, updatePoolUsageStatus(cl, poolName, usage))
+	assert.NoError(t, cl.Get(context.TODO(), poolName, pool))
+	assert.Equal(t, uint64(100), pool.Status.Usage.StoredBytes)
+
+	// the pool CR was deleted
+	assert.NoError(t, updatePoolUsageStatus(cl, types.NamespacedName{Name: "other", Namespace: "rook-ceph"}, usage))
+}
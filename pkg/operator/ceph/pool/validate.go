@@ -36,9 +36,34 @@ func ValidatePool(context *clusterd.Context, clusterInfo *client.ClusterInfo, cl
 	if err := ValidatePoolSpec(context, clusterInfo, clusterSpec, &p.Spec); err != nil {
 		return err
 	}
+	if p.Spec.Migration != nil {
+		if err := validateMigration(context, clusterInfo, clusterSpec, p); err != nil {
+			return errors.Wrap(err, "invalid migration")
+		}
+	}
 	return nil
 }
 
+// validateMigration validates the migration of the images of a pool to the target pool
+func validateMigration(context *clusterd.Context, clusterInfo *client.ClusterInfo, clusterSpec *cephv1.ClusterSpec, p *cephv1.CephBlockPool) error {
+	migration := p.Spec.Migration
+	if migration.TargetPool == "" {
+		return errors.New("missing target pool")
+	}
+	if migration.TargetPool == p.Name {
+		return errors.New("the target pool must be another pool")
+	}
+	// the migration of the images removes their mirroring settings
+	if p.Spec.Mirroring.Enabled {
+		return errors.New("the images of a mirrored pool cannot be migrated")
+	}
+	target := migration.TargetPoolSpec(p.Spec)
+	if !target.IsReplicated() && !target.IsErasureCoded() {
+		return errors.New("the target pool must be replicated or erasure coded")
+	}
+	return ValidatePoolSpec(context, clusterInfo, clusterSpec, &target)
+}
+
 // ValidatePoolSpec validates the Ceph block pool spec CR
 func ValidatePoolSpec(context *clusterd.Context, clusterInfo *cephclient.ClusterInfo, clusterSpec *cephv1.ClusterSpec, p *cephv1.PoolSpec) error {
 
@@ -53,6 +78,10 @@ func ValidatePoolSpec(context *clusterd.Context, clusterInfo *cephclient.Cluster
 		return errors.New("both replication and erasure code settings cannot be specified")
 	}
 
+	if err := p.ValidatePlacementGroups(); err != nil {
+		return errors.Wrap(err, "invalid placement groups")
+	}
+
 	if p.FailureDomain != "" && p.Replicated.SubFailureDomain != "" {
 		if p.FailureDomain == p.Replicated.SubFailureDomain {
 			return errors.New("failure and subfailure domain cannot be identical")
@@ -71,9 +100,19 @@ func ValidatePoolSpec(context *clusterd.Context, clusterInfo *cephclient.Cluster
 		}
 	}
 
+	// the placement of the pool is defined by the CephCrushRule instead of the failure domain
+	if p.CrushRule != "" {
+		if p.FailureDomain != "" || p.DeviceClass != "" || p.CrushRoot != "" || p.Replicated.SubFailureDomain != "" || p.IsHybridStoragePool() {
+			return errors.New("the crush rule cannot be specified with the failure domain, device class, crush root or hybrid storage")
+		}
+		if clusterSpec.IsStretchCluster() {
+			return errors.New("the pools of a stretch cluster cannot specify a crush rule")
+		}
+	}
+
 	var crush client.CrushMap
 	var err error
-	if p.FailureDomain != "" || p.CrushRoot != "" {
+	if p.FailureDomain != "" || p.CrushRoot != "" || p.CrushRule != "" {
 		crush, err = client.GetCrushMap(context, clusterInfo)
 		if err != nil {
 			return errors.Wrap(err, "failed to get crush map")
@@ -108,6 +147,20 @@ func ValidatePoolSpec(context *clusterd.Context, clusterInfo *cephclient.Cluster
 		}
 	}
 
+	// validate the crush rule if specified, the rule is created by its CephCrushRule
+	if p.CrushRule != "" {
+		ruleType, found := client.GetCrushRuleType(crush, p.CrushRule)
+		if !found {
+			return errors.Errorf("crush rule %q not found", p.CrushRule)
+		}
+		if p.IsErasureCoded() && ruleType != cephv1.CrushRuleTypeErasure {
+			return errors.Errorf("crush rule %q of an erasure coded pool must be an erasure rule", p.CrushRule)
+		}
+		if p.IsReplicated() && ruleType != cephv1.CrushRuleTypeReplicated {
+			return errors.Errorf("crush rule %q of a replicated pool must be a replicated rule", p.CrushRule)
+		}
+	}
+
 	// validate the crush subdomain if specified
 	if p.Replicated.SubFailureDomain != "" {
 		found := false
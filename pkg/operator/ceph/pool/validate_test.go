@@ -225,6 +225,45 @@ func TestValidateCrushProperties(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestValidateCrushRule(t *testing.T) {
+	executor := &exectest.MockExecutor{}
+	context := &clusterd.Context{Executor: executor}
+	clusterInfo := cephclient.AdminClusterInfo("mycluster")
+	executor.MockExecuteCommandWithOutput = func(command string, args ...string) (string, error) {
+		logger.Infof("Command: %s %v", command, args)
+		if args[1] == "crush" && args[2] == "dump" {
+			return `{"types":[{"type_id": 0,"name": "osd"}],"buckets":[{"id": -1,"name":"default"}],"rules":[{"rule_id": 1,"rule_name":"fast","type":1},{"rule_id": 2,"rule_name":"ec","type":3}]}`, nil
+		}
+		return "", errors.Errorf("unexpected ceph command %q", args)
+	}
+	clusterSpec := &cephv1.ClusterSpec{}
+
+	// succeed with a replicated rule that exists
+	p := &cephv1.CephBlockPool{
+		ObjectMeta: metav1.ObjectMeta{Name: "mypool", Namespace: clusterInfo.Namespace},
+		Spec: cephv1.PoolSpec{
+			CrushRule:  "fast",
+			Replicated: cephv1.ReplicatedSpec{Size: 3},
+		},
+	}
+	assert.NoError(t, ValidatePool(context, clusterInfo, clusterSpec, p))
+
+	// fail with a rule that doesn't exist
+	p.Spec.CrushRule = "doesntexist"
+	assert.Error(t, ValidatePool(context, clusterInfo, clusterSpec, p))
+
+	// fail with a rule of another type
+	p.Spec.CrushRule = "ec"
+	assert.Error(t, ValidatePool(context, clusterInfo, clusterSpec, p))
+	p.Spec.Replicated.Size = 0
+	p.Spec.ErasureCoded = cephv1.ErasureCodedSpec{DataChunks: 2, CodingChunks: 1}
+	assert.NoError(t, ValidatePool(context, clusterInfo, clusterSpec, p))
+
+	// fail with a failure domain
+	p.Spec.FailureDomain = "osd"
+	assert.Error(t, ValidatePool(context, clusterInfo, clusterSpec, p))
+}
+
 func TestValidateDeviceClasses(t *testing.T) {
 	testcases := []struct {
 		name                       string
@@ -0,0 +1,187 @@
+/*
+Copyright 2021 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package exec
+
+import (
+	"runtime"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const (
+	// maxCommandPrefixWords is the max number of words of the prefix of a command, such as "osd pool create"
+	maxCommandPrefixWords = 3
+	rookPackagePrefix     = "github.com/rook/rook/pkg/"
+	// CommandResultTimeout is the result of the commands that timed out
+	CommandResultTimeout = "timeout"
+	// CommandResultError is the result of the commands that failed without an exit code
+	CommandResultError = "error"
+	// radosTimeoutError is the error of the ceph CLI when the mons do not answer before the connect timeout
+	radosTimeoutError = "RADOS timed out"
+)
+
+var (
+	// SlowCommandThreshold is the duration after which a command is logged as slow
+	SlowCommandThreshold = 5 * time.Second
+
+	// the packages that run the commands for their callers, so they are never the caller of a command
+	commandRunnerPackages = []string{"util/", "daemon/ceph/client"}
+
+	commandDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "rook",
+		Subsystem: "ceph",
+		Name:      "command_duration_seconds",
+		Help:      "Duration of the commands run by the operator by command prefix, caller and result",
+		Buckets:   []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 15, 30, 60},
+	}, []string{"command", "prefix", "caller", "result"})
+)
+
+func init() {
+	metrics.Registry.MustRegister(commandDuration)
+}
+
+// CommandAudit is the audit record of a command run by the operator
+type CommandAudit struct {
+	// Command is the tool that runs the command, such as "ceph" or "radosgw-admin"
+	Command string
+	// Prefix is the command without its arguments and flags, such as "osd pool create"
+	Prefix string
+	// Caller is the package of the controller that runs the command, such as "operator/ceph/pool"
+	Caller string
+	// Remote is whether the command runs in the command proxy container
+	Remote bool
+	// Duration is how long the command ran
+	Duration time.Duration
+	// ExitCode is the exit code of the command, or -1 when the command failed without an exit code
+	ExitCode int
+	// Result is the exit code of the command, or "timeout" when the command timed out, or "error" when
+	// the command failed without an exit code
+	Result string
+	// Err is the error of the command
+	Err error
+
+	start time.Time
+}
+
+// StartCommandAudit starts the audit of a command. The audit must be finished with the result of the command.
+func StartCommandAudit(remote bool, command string, args ...string) *CommandAudit {
+	return &CommandAudit{
+		Command: command,
+		Prefix:  CommandPrefix(command, args),
+		Caller:  commandCaller(),
+		Remote:  remote,
+		start:   time.Now(),
+	}
+}
+
+// Finish records the duration and the result of the command from its error and output
+func (a *CommandAudit) Finish(output string, err error) {
+	a.Duration = time.Since(a.start)
+	a.Err = err
+	a.ExitCode, a.Result = commandResult(output, err)
+
+	commandDuration.WithLabelValues(a.Command, a.Prefix, a.Caller, a.Result).Observe(a.Duration.Seconds())
+	if a.Duration >= SlowCommandThreshold {
+		logger.Warningf("slow command %q %q by %q took %s. result: %s", a.Command, a.Prefix, a.Caller, a.Duration.Round(time.Millisecond), a.Result)
+	} else {
+		logger.Debugf("command %q %q by %q took %s. result: %s", a.Command, a.Prefix, a.Caller, a.Duration.Round(time.Millisecond), a.Result)
+	}
+}
+
+// CommandPrefix returns the first words of the command that are known command words of the tool, so
+// the commands can be grouped without unbounded label values. The names such as pool or filesystem
+// names end the prefix, and the commands of the tools without known words have no prefix.
+func CommandPrefix(tool string, args []string) string {
+	words := commandWords[tool]
+	prefix := []string{}
+	for _, arg := range args {
+		if len(prefix) == maxCommandPrefixWords || !words[arg] {
+			break
+		}
+		prefix = append(prefix, arg)
+	}
+	return strings.Join(prefix, " ")
+}
+
+// commandResult returns the exit code of the command and the result label of the metrics
+func commandResult(output string, err error) (int, string) {
+	if err == nil {
+		return 0, "0"
+	}
+	code, ok := ExitStatus(err)
+	if !ok {
+		code = -1
+	}
+	// the "timeout" command that runs the remote commands exits with 124 when it times out, and
+	// the ceph CLI exits with 1 and "[errno 110] RADOS timed out" when the mons do not answer
+	if (ok && (code == 124 || code == int(syscall.ETIMEDOUT))) || strings.Contains(output, radosTimeoutError) {
+		return code, CommandResultTimeout
+	}
+	if ok {
+		return code, strconv.Itoa(code)
+	}
+	if strings.Contains(err.Error(), "timeout waiting for") {
+		return code, CommandResultTimeout
+	}
+	return code, CommandResultError
+}
+
+// commandCaller returns the package of the first function in the call stack that is not part of the
+// packages that run the commands, such as "operator/ceph/pool"
+func commandCaller() string {
+	pcs := make([]uintptr, 32)
+	n := runtime.Callers(3, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+	for {
+		frame, more := frames.Next()
+		if pkg := rookPackage(frame.Function); pkg != "" && !isCommandRunnerPackage(pkg) {
+			return pkg
+		}
+		if !more {
+			return "unknown"
+		}
+	}
+}
+
+// rookPackage returns the rook package of the function relative to the "pkg" dir, or an empty string
+// when the function is not part of rook
+func rookPackage(function string) string {
+	if !strings.HasPrefix(function, rookPackagePrefix) {
+		return ""
+	}
+	pkg := strings.TrimPrefix(function, rookPackagePrefix)
+	// the package name ends at the first dot after the last slash, such as "pool.(*ReconcileCephBlockPool).reconcile"
+	lastSlash := strings.LastIndex(pkg, "/")
+	if dot := strings.Index(pkg[lastSlash+1:], "."); dot >= 0 {
+		pkg = pkg[:lastSlash+1+dot]
+	}
+	return pkg
+}
+
+func isCommandRunnerPackage(pkg string) bool {
+	for _, runner := range commandRunnerPackages {
+		if strings.HasPrefix(pkg, runner) {
+			return true
+		}
+	}
+	return false
+}
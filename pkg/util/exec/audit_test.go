@@ -0,0 +1,126 @@
+/*
+Copyright 2021 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package exec
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+	kexec "k8s.io/utils/exec"
+)
+
+// commandCount returns the number of commands recorded in the histogram with the labels
+func commandCount(t *testing.T, labels ...string) uint64 {
+	metric := &dto.Metric{}
+	assert.NoError(t, commandDuration.WithLabelValues(labels...).(prometheus.Metric).Write(metric))
+	return metric.GetHistogram().GetSampleCount()
+}
+
+func TestCommandPrefix(t *testing.T) {
+	assert.Equal(t, "status", CommandPrefix("ceph", []string{"status"}))
+	assert.Equal(t, "osd pool create", CommandPrefix("ceph", []string{"osd", "pool", "create", "replicapool", "8"}))
+	assert.Equal(t, "osd pool get", CommandPrefix("ceph", []string{"osd", "pool", "get", "replicapool", "all", "--format", "json"}))
+	assert.Equal(t, "config get", CommandPrefix("ceph", []string{"config", "get", "mon.", "rgw_rados_pool_pg_num_min"}))
+	assert.Equal(t, "osd out", CommandPrefix("ceph", []string{"osd", "out", "1"}))
+	assert.Equal(t, "auth get-or-create-key", CommandPrefix("ceph", []string{"auth", "get-or-create-key", "client.csi-rbd-node"}))
+	// the names are not part of the prefix
+	assert.Equal(t, "fs get", CommandPrefix("ceph", []string{"fs", "get", "myfs"}))
+	assert.Equal(t, "pool stats", CommandPrefix("rbd", []string{"pool", "stats", "replicapool"}))
+	assert.Equal(t, "realm list", CommandPrefix("radosgw-admin", []string{"realm", "list", "--rgw-realm=store"}))
+	// the words are known per tool
+	assert.Equal(t, "", CommandPrefix("radosgw-admin", []string{"osd", "pool", "create"}))
+	assert.Equal(t, "", CommandPrefix("crushtool", []string{"decompile"}))
+	assert.Equal(t, "", CommandPrefix("ceph", []string{"--version"}))
+	assert.Equal(t, "", CommandPrefix("ceph", nil))
+}
+
+func TestCommandResult(t *testing.T) {
+	code, result := commandResult("", nil)
+	assert.Equal(t, 0, code)
+	assert.Equal(t, "0", result)
+
+	code, result = commandResult("", &kexec.CodeExitError{Err: errors.New("not found"), Code: 2})
+	assert.Equal(t, 2, code)
+	assert.Equal(t, "2", result)
+
+	code, result = commandResult("", &kexec.CodeExitError{Err: errors.New("timed out"), Code: 124})
+	assert.Equal(t, 124, code)
+	assert.Equal(t, CommandResultTimeout, result)
+
+	// the ceph CLI cannot connect to the mons
+	code, result = commandResult("[errno 110] RADOS timed out (error connecting to the cluster)", &kexec.CodeExitError{Err: errors.New("exit status 1"), Code: 1})
+	assert.Equal(t, 1, code)
+	assert.Equal(t, CommandResultTimeout, result)
+
+	// a mon command returns ETIMEDOUT
+	code, result = commandResult("", &kexec.CodeExitError{Err: errors.New("timed out"), Code: 110})
+	assert.Equal(t, 110, code)
+	assert.Equal(t, CommandResultTimeout, result)
+
+	// other failures that mention a timeout are not timeouts
+	code, result = commandResult("the snapshot timed out", &kexec.CodeExitError{Err: errors.New("exit status 1"), Code: 1})
+	assert.Equal(t, 1, code)
+	assert.Equal(t, "1", result)
+
+	code, result = commandResult("", errors.New("timeout waiting for the command ceph to return"))
+	assert.Equal(t, -1, code)
+	assert.Equal(t, CommandResultTimeout, result)
+
+	code, result = commandResult("[errno 110] RADOS timed out (error connecting to the cluster)", errors.New("exit status 1"))
+	assert.Equal(t, -1, code)
+	assert.Equal(t, CommandResultTimeout, result)
+
+	code, result = commandResult("", errors.New("failed"))
+	assert.Equal(t, -1, code)
+	assert.Equal(t, CommandResultError, result)
+}
+
+func TestRookPackage(t *testing.T) {
+	assert.Equal(t, "operator/ceph/pool", rookPackage("github.com/rook/rook/pkg/operator/ceph/pool.(*ReconcileCephBlockPool).reconcile"))
+	assert.Equal(t, "operator/ceph/cluster/mon", rookPackage("github.com/rook/rook/pkg/operator/ceph/cluster/mon.(*Cluster).startMons.func1"))
+	assert.Equal(t, "daemon/ceph/client", rookPackage("github.com/rook/rook/pkg/daemon/ceph/client.Status"))
+	assert.Equal(t, "", rookPackage("sigs.k8s.io/controller-runtime/pkg/internal/controller.(*Controller).reconcileHandler"))
+
+	assert.True(t, isCommandRunnerPackage("util/exec"))
+	assert.True(t, isCommandRunnerPackage("daemon/ceph/client"))
+	assert.False(t, isCommandRunnerPackage("operator/ceph/pool"))
+}
+
+func TestCommandAudit(t *testing.T) {
+	labels := []string{"ceph", "osd pool create", "unknown", "0"}
+	before := commandCount(t, labels...)
+
+	audit := StartCommandAudit(false, "ceph", "osd", "pool", "create", "replicapool")
+	assert.Equal(t, "osd pool create", audit.Prefix)
+	// the test is not part of a controller
+	assert.Equal(t, "unknown", audit.Caller)
+
+	audit.Finish("", nil)
+	assert.Equal(t, 0, audit.ExitCode)
+	assert.Equal(t, "0", audit.Result)
+	assert.Equal(t, before+1, commandCount(t, labels...))
+
+	// the failed commands are recorded with their exit code
+	audit = StartCommandAudit(true, "ceph", "osd", "pool", "create", "replicapool")
+	audit.Finish("", &kexec.CodeExitError{Err: errors.New("failed"), Code: 22})
+	assert.True(t, audit.Remote)
+	assert.Equal(t, 22, audit.ExitCode)
+	assert.Equal(t, uint64(1), commandCount(t, "ceph", "osd pool create", "unknown", "22"))
+}
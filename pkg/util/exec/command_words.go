@@ -0,0 +1,68 @@
+/*
+Copyright 2021 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package exec
+
+// commandWords are the words of the commands run by the operator for each tool. Only these words are
+// part of the prefix of a command, so the number of prefixes is bounded whatever the names of the
+// pools, filesystems or users passed as arguments.
+var commandWords = map[string]map[string]bool{
+	"ceph": wordSet(
+		// top level commands
+		"auth", "balancer", "config", "config-key", "crash", "dashboard", "df", "fs", "health", "mds",
+		"mgr", "mon", "nfs", "osd", "quorum_status", "status", "tell", "version", "versions",
+		// subcommands of the osd commands
+		"application", "blocklist", "blacklist", "class", "crush", "dump", "erasure-code-profile",
+		"find", "getcrushmap", "ls", "ls-osd", "lspools", "ok-to-stop", "out", "in", "perf", "pool",
+		"primary-affinity", "purge", "range", "require-osd-release", "rule", "safe-to-destroy",
+		"set-group", "set-require-min-compat-client", "setcrushmap", "tree", "unset-group",
+		"add-bucket", "create-replicated", "create-erasure", "get-device-class", "move", "detail",
+		"get-quota", "set-quota",
+		// subcommands of the fs and mgr commands
+		"add_data_pool", "daemon", "fail", "flag", "getpath", "import", "mirror", "module", "new",
+		"peer_bootstrap", "peer_remove", "pin", "resize", "retention", "snap-schedule", "snapshot",
+		"stat", "subvolume", "subvolumegroup",
+		// subcommands of the auth, health and mon commands
+		"caps", "commit-pending", "del", "get-key", "get-or-create", "get-or-create-key",
+		"get-or-create-pending", "enable_stretch_mode", "mute", "unmute", "archive", "mode",
+		// subcommands of the dashboard commands
+		"ac-role-add-scope-perms", "ac-role-create", "ac-role-del-scope-perms", "ac-role-delete",
+		"ac-role-show", "ac-user-create", "ac-user-delete", "ac-user-set-info", "ac-user-set-password",
+		"ac-user-set-roles", "ac-user-show", "create-self-signed-cert", "get-rgw-api-access-key",
+		"reset-rgw-api-access-key", "reset-rgw-api-secret-key", "set-login-credentials",
+		"set-rgw-api-access-key", "set-rgw-api-secret-key", "setup", "sso", "saml2",
+		// verbs shared by the commands
+		"add", "create", "delete", "disable", "enable", "export", "get", "remove", "rm", "set",
+	),
+	"rbd": wordSet(
+		"abort", "add", "bootstrap", "commit", "create", "demote", "disable", "du", "enable", "execute",
+		"image", "import", "info", "init", "ls", "migration", "mirror", "namespace", "peer", "pool",
+		"prepare", "promote", "remove", "resync", "rm", "schedule", "snapshot", "stats", "status", "trash",
+	),
+	"radosgw-admin": wordSet(
+		"bucket", "caps", "create", "default", "delete", "get", "info", "link", "list", "modify",
+		"period", "pull", "quota", "realm", "rm", "set", "stats", "unlink", "update", "user", "zone",
+		"zonegroup",
+	),
+}
+
+func wordSet(words ...string) map[string]bool {
+	set := map[string]bool{}
+	for _, word := range words {
+		set[word] = true
+	}
+	return set
+}
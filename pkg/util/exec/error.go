@@ -21,6 +21,8 @@ import (
 	"fmt"
 	"os/exec"
 	"syscall"
+
+	kexec "k8s.io/utils/exec"
 )
 
 // CephCLIError is Ceph CLI Error type
@@ -44,6 +46,10 @@ func ExitStatus(err error) (int, bool) {
 
 	case *CephCLIError:
 		return ExitStatus(e.err)
+
+	// the errors of the ceph commands that run with librados
+	case *kexec.CodeExitError:
+		return e.ExitStatus(), true
 	}
 	return 0, false
 }
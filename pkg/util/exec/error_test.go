@@ -21,6 +21,8 @@ import (
 	"os"
 	"os/exec"
 	"testing"
+
+	kexec "k8s.io/utils/exec"
 )
 
 func TestExitStatus(t *testing.T) {
@@ -40,6 +42,7 @@ func TestExitStatus(t *testing.T) {
 		{"error type is ExitError", args{err: e}, 0, true},
 		{"error type is CephCLIError and contains ExitError ", args{err: c}, 0, true},
 		{"error type is CephCLIError and does not contain ExitError", args{err: &CephCLIError{err: errors.New("foo")}}, 0, false},
+		{"error type is CodeExitError", args{err: &kexec.CodeExitError{Err: errors.New("foo"), Code: 2}}, 2, true},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -707,11 +707,17 @@ func (h *CephInstaller) UninstallRookFromMultipleNS(manifests ...CephManifests)
 			}
 		} else {
 			h.k8shelper.PrintResources(namespace, "cephblockpools.ceph.rook.io")
+			h.k8shelper.PrintResources(namespace, "cephblockpoolradosnamespaces.ceph.rook.io")
 			h.k8shelper.PrintResources(namespace, "cephclients.ceph.rook.io")
 			h.k8shelper.PrintResources(namespace, "cephclusters.ceph.rook.io")
+			h.k8shelper.PrintResources(namespace, "cephcrushrules.ceph.rook.io")
 			h.k8shelper.PrintResources(namespace, "cephfilesystemmirrors.ceph.rook.io")
 			h.k8shelper.PrintResources(namespace, "cephfilesystems.ceph.rook.io")
+			h.k8shelper.PrintResources(namespace, "cephfilesystemsubvolumegroups.ceph.rook.io")
 			h.k8shelper.PrintResources(namespace, "cephnfses.ceph.rook.io")
+			h.k8shelper.PrintResources(namespace, "cephnfsexports.ceph.rook.io")
+			h.k8shelper.PrintResources(namespace, "cephnetworkfences.ceph.rook.io")
+			h.k8shelper.PrintResources(namespace, "cephrbdfailovers.ceph.rook.io")
 			h.k8shelper.PrintResources(namespace, "cephobjectrealms.ceph.rook.io")
 			h.k8shelper.PrintResources(namespace, "cephobjectstores.ceph.rook.io")
 			h.k8shelper.PrintResources(namespace, "cephobjectstoreusers.ceph.rook.io")